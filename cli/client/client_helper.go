@@ -144,6 +144,8 @@ func parseType(target interface{}) ResourceType {
 		return Storagebackendclaim
 	case xuanwuV1.StorageBackendContent:
 		return StoragebackendclaimContent
+	case corev1.PersistentVolume:
+		return PersistentVolume
 	default:
 		return ""
 	}
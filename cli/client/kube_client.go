@@ -34,6 +34,7 @@ const (
 	Secret                     ResourceType = "secret"
 	Storagebackendclaim        ResourceType = "storagebackendclaim"
 	StoragebackendclaimContent ResourceType = "storagebackendcontent"
+	PersistentVolume           ResourceType = "persistentvolume"
 
 	Create = "create" // used to create resource
 	Delete = "delete" // used to delete resource
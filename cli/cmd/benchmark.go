@@ -0,0 +1,76 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package command
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"huawei-csi-driver/cli/cmd/options"
+	"huawei-csi-driver/cli/config"
+	"huawei-csi-driver/cli/helper"
+	"huawei-csi-driver/cli/resources"
+)
+
+const defaultBenchmarkDuration = 60 * time.Second
+
+func init() {
+	options.NewFlagsOptions(benchmarkCmd).
+		WithNameSpace(false).
+		WithVolume().
+		WithDuration(defaultBenchmarkDuration).
+		WithOutPutFormat().
+		WithParent(RootCmd)
+}
+
+var (
+	benchmarkExample = helper.Examples(`
+		# Report the IOPS, throughput and latency of a volume in the next 60 seconds
+		oceanctl benchmark --volume <pv-name>
+
+		# Report the performance of a volume in the next 5 minutes, and export the raw samples as csv
+		oceanctl benchmark --volume <pv-name> --duration 5m -o csv > samples.csv`)
+)
+
+var benchmarkCmd = &cobra.Command{
+	Use:     "benchmark",
+	Short:   "Report the performance of a volume on Ocean Storage, such as verifying its QoS takes effect",
+	Example: benchmarkExample,
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runBenchmark()
+	},
+}
+
+func runBenchmark() error {
+	res := resources.NewResourceBuilder().
+		NamespaceParam(config.Namespace).
+		DefaultNamespace().
+		Output(config.OutputFormat).
+		Build()
+
+	validator := resources.NewValidatorBuilder(res).
+		ValidateBenchmarkOutputFormat().
+		ValidateBenchmarkDuration(config.Duration).
+		Build()
+	if err := validator.Validate(); err != nil {
+		return helper.PrintlnError(err)
+	}
+
+	return resources.NewBenchmark(res).Run(config.Volume, config.Duration)
+}
@@ -0,0 +1,74 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package command
+
+import (
+	"github.com/spf13/cobra"
+
+	"huawei-csi-driver/cli/client"
+	"huawei-csi-driver/cli/cmd/options"
+	"huawei-csi-driver/cli/config"
+	"huawei-csi-driver/cli/helper"
+	"huawei-csi-driver/cli/resources"
+)
+
+func init() {
+	options.NewFlagsOptions(checkCmd).
+		WithNameSpace(false).
+		WithOutPutFormat().
+		WithParent(RootCmd)
+}
+
+var (
+	checkExample = helper.Examples(`
+		# Check the compatibility of the storage of all backends in default(huawei-csi) namespace
+		oceanctl check
+
+		# Check the compatibility of the storage of specified backends in specified namespace
+		oceanctl check <name...> -n <namespace>
+
+		# Check the compatibility of the storage of a backend with JSON output format
+		oceanctl check <name> -o json`)
+)
+
+var checkCmd = &cobra.Command{
+	Use:   "check [<name>...]",
+	Short: "Check the compatibility of the storage of one or more backends with the driver",
+	Long: "Log in the storage of the backends, gather the product, the version, the licensed features, " +
+		"the NFS service settings and the protocol endpoints, and evaluate them against the compatibility " +
+		"matrix of the driver, the result of each backend is PASS, WARN or FAIL",
+	Example: checkExample,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runCheck(args)
+	},
+}
+
+func runCheck(backendNames []string) error {
+	res := resources.NewResourceBuilder().
+		ResourceNames(string(client.Storagebackendclaim), backendNames...).
+		NamespaceParam(config.Namespace).
+		DefaultNamespace().
+		Output(config.OutputFormat).
+		Build()
+
+	validator := resources.NewValidatorBuilder(res).ValidateOutputFormat().Build()
+	if err := validator.Validate(); err != nil {
+		return helper.PrintlnError(err)
+	}
+
+	return resources.NewBackend(res).Check()
+}
@@ -0,0 +1,76 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package command
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"huawei-csi-driver/cli/cmd/options"
+	"huawei-csi-driver/cli/config"
+	"huawei-csi-driver/cli/helper"
+	"huawei-csi-driver/cli/resources"
+)
+
+func init() {
+	options.NewFlagsOptions(dTreeAccessLogCmd).
+		WithNameSpace(false).
+		WithVolume().
+		WithStartTime().
+		WithOutPutFormat().
+		WithParent(RootCmd)
+}
+
+var (
+	dTreeAccessLogExample = helper.Examples(`
+		# Display the NFS access records of a DTree volume since the specified time in default(huawei-csi) namespace
+		oceanctl dtree-access-log --volume <pv-name> --start 2023-06-01T08:00:00Z
+
+		# Display the NFS access records of a DTree volume in the last 24 hours in json format
+		oceanctl dtree-access-log --volume <pv-name> --start 24h -n namespace -o json`)
+)
+
+var dTreeAccessLogCmd = &cobra.Command{
+	Use:     "dtree-access-log",
+	Short:   "Display the NFS access records of a DTree volume on Ocean Storage",
+	Example: dTreeAccessLogExample,
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runDTreeAccessLog()
+	},
+}
+
+func runDTreeAccessLog() error {
+	startTime, err := resources.ParseStartTime(config.StartTime, time.Now())
+	if err != nil {
+		return helper.PrintlnError(err)
+	}
+
+	res := resources.NewResourceBuilder().
+		NamespaceParam(config.Namespace).
+		DefaultNamespace().
+		Output(config.OutputFormat).
+		Build()
+
+	validator := resources.NewValidatorBuilder(res).ValidateOutputFormat().Build()
+	if err = validator.Validate(); err != nil {
+		return helper.PrintlnError(err)
+	}
+
+	return resources.NewDTreeAccessLog(res).Show(config.Volume, startTime)
+}
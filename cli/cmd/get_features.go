@@ -0,0 +1,71 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package command
+
+import (
+	"github.com/spf13/cobra"
+
+	"huawei-csi-driver/cli/client"
+	"huawei-csi-driver/cli/cmd/options"
+	"huawei-csi-driver/cli/config"
+	"huawei-csi-driver/cli/helper"
+	"huawei-csi-driver/cli/resources"
+)
+
+func init() {
+	options.NewFlagsOptions(getFeaturesCmd).
+		WithNameSpace(false).
+		WithOutPutFormat().
+		WithParent(getCmd)
+}
+
+var (
+	getFeaturesExample = helper.Examples(`
+		# List the feature matrix of all backends in default(huawei-csi) namespace
+		oceanctl get features
+
+		# List the feature matrix of specified backends in specified namespace
+		oceanctl get features <name...> -n <namespace>
+
+		# Get the feature matrix of a single backend with JSON output format
+		oceanctl get features <name> -o json`)
+)
+
+var getFeaturesCmd = &cobra.Command{
+	Use:     "features [<name>...]",
+	Short:   "Get the features supported by one or more backends through the driver",
+	Example: getFeaturesExample,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runGetFeatures(args)
+	},
+}
+
+func runGetFeatures(backendNames []string) error {
+	res := resources.NewResourceBuilder().
+		ResourceNames(string(client.Storagebackendclaim), backendNames...).
+		NamespaceParam(config.Namespace).
+		DefaultNamespace().
+		Output(config.OutputFormat).
+		Build()
+
+	validator := resources.NewValidatorBuilder(res).ValidateOutputFormat().Build()
+	if err := validator.Validate(); err != nil {
+		return helper.PrintlnError(err)
+	}
+
+	return resources.NewBackend(res).GetFeatures()
+}
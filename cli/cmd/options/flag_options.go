@@ -18,6 +18,8 @@
 package options
 
 import (
+	"time"
+
 	"github.com/spf13/cobra"
 
 	"huawei-csi-driver/cli/config"
@@ -140,3 +142,33 @@ func (b *FlagsOptions) WithLogDir() *FlagsOptions {
 		"directory for printing log files.")
 	return b
 }
+
+// WithTimeout This function will add a timeout flag
+func (b *FlagsOptions) WithTimeout(defaultTimeout time.Duration) *FlagsOptions {
+	b.cmd.PersistentFlags().DurationVarP(&config.Timeout, "timeout", "", defaultTimeout, "Specify the "+
+		"maximum time to wait for the operation to complete, such as 30m.")
+	return b
+}
+
+// WithVolume This function will add a volume flag
+func (b *FlagsOptions) WithVolume() *FlagsOptions {
+	b.cmd.PersistentFlags().StringVarP(&config.Volume, "volume", "", "", "Specify the name of the "+
+		"PersistentVolume.")
+	b.markPersistentFlagRequired("volume")
+	return b
+}
+
+// WithDuration This function will add a duration flag
+func (b *FlagsOptions) WithDuration(defaultDuration time.Duration) *FlagsOptions {
+	b.cmd.PersistentFlags().DurationVarP(&config.Duration, "duration", "", defaultDuration, "Specify the "+
+		"duration of the operation, such as 60s.")
+	return b
+}
+
+// WithStartTime This function will add a start flag
+func (b *FlagsOptions) WithStartTime() *FlagsOptions {
+	b.cmd.PersistentFlags().StringVarP(&config.StartTime, "start", "", "", "Specify the start time, "+
+		"either in RFC3339 format such as 2023-06-01T08:00:00Z, or a duration before now such as 24h.")
+	b.markPersistentFlagRequired("start")
+	return b
+}
@@ -0,0 +1,81 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package command
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"huawei-csi-driver/cli/cmd/options"
+	"huawei-csi-driver/cli/config"
+	"huawei-csi-driver/cli/helper"
+	"huawei-csi-driver/cli/resources"
+)
+
+const defaultReclaimTimeout = 30 * time.Minute
+
+func init() {
+	options.NewFlagsOptions(reclaimCmd).
+		WithNameSpace(false).
+		WithBackend(false).
+		WithTimeout(defaultReclaimTimeout).
+		WithParent(RootCmd)
+}
+
+var (
+	reclaimExample = helper.Examples(`
+		# Reclaim the space of the thin LUNs of the specified PersistentVolumes
+		oceanctl reclaim pv <pv-name>...
+
+		# Reclaim the space of the thin LUNs of all PersistentVolumes provisioned by the specified backend
+		# in default(huawei-csi) namespace, and give up if the reclamation does not complete in 1 hour
+		oceanctl reclaim pv -b <backend> --timeout 1h`)
+)
+
+var reclaimCmd = &cobra.Command{
+	Use:   "reclaim pv (<name>... | -b <backend>)",
+	Short: "Reclaim the space of the thin LUNs of PersistentVolumes on Ocean Storage",
+	Long: "Discard the unused blocks of the filesystems of PersistentVolumes on the nodes where they are " +
+		"mounted, so that the storage reclaims the space of the deleted data in the thin LUNs.",
+	Example:   reclaimExample,
+	ValidArgs: []string{resources.SpaceReclaimPV},
+	Args:      cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runReclaim(args[0], args[1:])
+	},
+}
+
+func runReclaim(objectType string, pvNames []string) error {
+	res := resources.NewResourceBuilder().
+		ResourceNames(objectType, pvNames...).
+		NamespaceParam(config.Namespace).
+		DefaultNamespace().
+		BoundBackend(config.Backend).
+		Build()
+
+	validator := resources.NewValidatorBuilder(res).
+		ValidateSpaceReclaimType().
+		ValidateSpaceReclaimTarget().
+		ValidateSpaceReclaimTimeout(config.Timeout).
+		Build()
+	if err := validator.Validate(); err != nil {
+		return helper.PrintlnError(err)
+	}
+
+	return resources.NewSpaceReclaim(res).Reclaim(config.Timeout)
+}
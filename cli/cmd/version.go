@@ -18,20 +18,58 @@ package command
 
 import (
 	"fmt"
+	"sort"
+
 	"github.com/spf13/cobra"
 
+	"huawei-csi-driver/cli/cmd/options"
 	"huawei-csi-driver/cli/config"
+	"huawei-csi-driver/cli/resources"
 )
 
 func init() {
-	RootCmd.AddCommand(versionCmd)
+	options.NewFlagsOptions(versionCmd).
+		WithNameSpace(false).
+		WithParent(RootCmd)
 }
 
 var versionCmd = &cobra.Command{
 	Use:   "version",
-	Short: "Print the version of oceanctl",
+	Short: "Print the version of oceanctl and the huawei-csi driver running in the cluster",
 
 	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Printf("Oceanctl Version: %s\n", config.CliVersion)
+		runVersion()
 	},
 }
+
+func runVersion() {
+	fmt.Printf("Oceanctl Version: %s\n", config.CliVersion)
+
+	namespace := config.Namespace
+	if namespace == "" {
+		namespace = config.DefaultNamespace
+	}
+
+	info, err := resources.GetControllerPluginInfo(namespace)
+	if err != nil {
+		fmt.Printf("Warning: get the version of huawei-csi driver failed, error: %v\n", err)
+		return
+	}
+
+	fmt.Printf("Driver Name: %s\n", info.Name)
+	fmt.Printf("Driver Version: %s\n", info.VendorVersion)
+	if len(info.Manifest) == 0 {
+		return
+	}
+
+	keys := make([]string, 0, len(info.Manifest))
+	for key := range info.Manifest {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	fmt.Println("Driver Manifest:")
+	for _, key := range keys {
+		fmt.Printf("  %s: %s\n", key, info.Manifest[key])
+	}
+}
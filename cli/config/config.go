@@ -18,6 +18,8 @@
 package config
 
 import (
+	"time"
+
 	"huawei-csi-driver/cli/client"
 )
 
@@ -103,4 +105,16 @@ var (
 
 	// LogDir the value of log-dir flag, set by options.WithLogDir()
 	LogDir string
+
+	// Timeout the value of timeout flag, set by options.WithTimeout()
+	Timeout time.Duration
+
+	// Volume the value of volume flag, set by options.WithVolume()
+	Volume string
+
+	// StartTime the value of start flag, set by options.WithStartTime()
+	StartTime string
+
+	// Duration the value of duration flag, set by options.WithDuration()
+	Duration time.Duration
 )
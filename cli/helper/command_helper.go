@@ -130,7 +130,7 @@ func GetSelectedNumber(tips string, maxValue int) (int, error) {
 func BashExecReturnStdOut(ctx context.Context, cli string, args []string) ([]byte, error) {
 	command := fmt.Sprintf("%s %s", cli, strings.Join(args, " "))
 	LogInfof(ctx, "bash exec command: %v", command)
-	cmd := exec.Command("/bin/bash", "-c", command)
+	cmd := exec.CommandContext(ctx, "/bin/bash", "-c", command)
 	stdout, err := cmd.CombinedOutput()
 	if err != nil {
 		return []byte{}, errors.New(string(stdout))
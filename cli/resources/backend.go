@@ -95,6 +95,54 @@ func (b *Backend) Get() error {
 	return nil
 }
 
+// GetFeatures query the feature matrix of backends resolved from the capabilities reported by the storage
+func (b *Backend) GetFeatures() error {
+	storageBackendClaimClient := client.NewCommonCallHandler[xuanwuV1.StorageBackendClaim](config.Client)
+	claims, err := storageBackendClaimClient.QueryList(b.resource.namespace, b.resource.names...)
+	if err != nil {
+		return helper.LogErrorf("query sbc resource failed, error: %v", err)
+	}
+
+	if len(claims) == 0 && len(b.resource.names) == 0 {
+		helper.PrintNoResourceBackend(b.resource.namespace)
+		return nil
+	}
+
+	var contentNames []string
+	for _, claim := range claims {
+		if claim.Status != nil {
+			contentNames = append(contentNames, claim.Status.BoundContentName)
+		}
+	}
+
+	contentMapping := make(map[string]xuanwuV1.StorageBackendContent)
+	if len(contentNames) != 0 {
+		storageBackendContentClient := client.NewCommonCallHandler[xuanwuV1.StorageBackendContent](config.Client)
+		contentList, err := storageBackendContentClient.QueryList(b.resource.namespace, contentNames...)
+		if err != nil {
+			return helper.LogErrorf("query sbct resource failed, error: %v", err)
+		}
+
+		for _, content := range contentList {
+			contentMapping[content.Name] = content
+		}
+	}
+
+	featureShows := helper.MapTo(claims, func(claim xuanwuV1.StorageBackendClaim) BackendFeatureShow {
+		var content *xuanwuV1.StorageBackendContent
+		if claim.Status != nil {
+			if boundContent, ok := contentMapping[claim.Status.BoundContentName]; ok {
+				content = &boundContent
+			}
+		}
+		return NewBackendFeatureShow(claim, content)
+	})
+
+	printFunc := helper.GetPrintFunc[BackendFeatureShow](b.resource.output)
+	helper.PrintBackend(featureShows, getNotFoundBackends(claims, b.resource.names), printFunc)
+	return nil
+}
+
 // Delete backend resource
 func (b *Backend) Delete() error {
 	storageBackendClaimClient := client.NewCommonCallHandler[xuanwuV1.StorageBackendClaim](config.Client)
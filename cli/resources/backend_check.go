@@ -0,0 +1,248 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package resources
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+
+	"huawei-csi-driver/cli/client"
+	"huawei-csi-driver/cli/config"
+	"huawei-csi-driver/cli/helper"
+	xuanwuV1 "huawei-csi-driver/client/apis/xuanwu/v1"
+	storageClient "huawei-csi-driver/storage/oceanstor/client"
+	"huawei-csi-driver/utils"
+)
+
+// BackendCheckReport the compatibility report of a backend displayed in json or yaml format
+type BackendCheckReport struct {
+	Name     string                 `json:"name"`
+	Storage  string                 `json:"storage"`
+	Product  string                 `json:"product"`
+	Version  string                 `json:"version"`
+	Result   string                 `json:"result"`
+	Findings []CompatibilityFinding `json:"findings"`
+}
+
+// BackendCheckShow the content echoed by executing the oceanctl check
+type BackendCheckShow struct {
+	Name     string `show:"NAME"`
+	Product  string `show:"PRODUCT"`
+	Version  string `show:"VERSION"`
+	Result   string `show:"RESULT"`
+	Findings string `show:"FINDINGS"`
+}
+
+// Check evaluates the compatibility of the storage of the backends with the driver
+func (b *Backend) Check() error {
+	storageBackendClaimClient := client.NewCommonCallHandler[xuanwuV1.StorageBackendClaim](config.Client)
+	claims, err := storageBackendClaimClient.QueryList(b.resource.namespace, b.resource.names...)
+	if err != nil {
+		return helper.LogErrorf("query sbc resource failed, error: %v", err)
+	}
+
+	if len(claims) == 0 && len(b.resource.names) == 0 {
+		helper.PrintNoResourceBackend(b.resource.namespace)
+		return nil
+	}
+
+	reports := helper.MapTo(claims, b.checkBackend)
+	notFound := getNotFoundBackends(claims, b.resource.names)
+	if b.resource.output == "json" || b.resource.output == "yaml" {
+		helper.PrintBackend(reports, notFound, helper.GetPrintFunc[BackendCheckReport](b.resource.output))
+		return nil
+	}
+
+	shows := helper.MapTo(reports, func(report BackendCheckReport) BackendCheckShow {
+		messages := helper.MapTo(report.Findings, func(finding CompatibilityFinding) string {
+			return fmt.Sprintf("[%s] %s", finding.Level, finding.Message)
+		})
+		return BackendCheckShow{
+			Name:     report.Name,
+			Product:  report.Product,
+			Version:  report.Version,
+			Result:   report.Result,
+			Findings: strings.Join(messages, "; "),
+		}
+	})
+	helper.PrintBackend(shows, notFound, helper.PrintWithTable[BackendCheckShow])
+	return nil
+}
+
+func (b *Backend) checkBackend(claim xuanwuV1.StorageBackendClaim) BackendCheckReport {
+	report := BackendCheckReport{Name: claim.Name}
+	fail := func(format string, args ...interface{}) BackendCheckReport {
+		report.Findings = append(report.Findings,
+			CompatibilityFinding{Level: CompatibilityFail, Message: fmt.Sprintf(format, args...)})
+		report.Result = CompatibilityFail
+		return report
+	}
+
+	backendConfig, err := fetchClaimBackendConfig(b.resource.namespace, claim)
+	if err != nil {
+		return fail("fetch backend config failed, error: %v", err)
+	}
+
+	report.Storage = backendConfig.Storage
+	if !strings.HasPrefix(backendConfig.Storage, oceanstorPrefix) {
+		report.Findings = []CompatibilityFinding{{Level: CompatibilityWarn,
+			Message: fmt.Sprintf("the compatibility check does not support the storage %s", backendConfig.Storage)}}
+		report.Result = CompatibilityWarn
+		return report
+	}
+
+	ctx := context.Background()
+	cli, err := loginStandaloneStorage(ctx, b.resource.namespace, claim, backendConfig)
+	if err != nil {
+		return fail("login storage failed, error: %v", err)
+	}
+	defer cli.Logout(ctx)
+
+	facts, err := gatherStorageFacts(ctx, cli, backendConfig)
+	if err != nil {
+		return fail("gather storage information failed, error: %v", err)
+	}
+
+	report.Product, report.Version = facts.Product, facts.Version
+	report.Findings = evaluateCompatibility(facts, compatibilityMatrix)
+	report.Result = compatibilityResult(report.Findings)
+	return report
+}
+
+// gatherStorageFacts queries the product, the version, the licensed features, the NFS service settings
+// and the protocol endpoints of the storage of the backend
+func gatherStorageFacts(ctx context.Context, cli *storageClient.BaseClient,
+	backendConfig *BackendConfiguration) (storageFacts, error) {
+	facts := storageFacts{
+		StorageType: backendConfig.Storage,
+		Protocol:    backendConfig.Parameters.Protocol,
+		HyperMetro:  backendConfig.MetroBackend != "" || backendConfig.MetrovStorePairID != "",
+		Portals:     getConfiguredPortals(backendConfig.Parameters.Portals),
+	}
+
+	system, err := cli.GetSystem(ctx)
+	if err != nil {
+		return facts, err
+	}
+
+	facts.Product, err = utils.GetProductVersion(system)
+	if err != nil {
+		return facts, err
+	}
+	facts.Version = cli.GetFirmwareVersion()
+
+	facts.Features, err = cli.GetLicenseFeature(ctx)
+	if err != nil {
+		return facts, err
+	}
+
+	if facts.StorageType == oceanstorNas || facts.StorageType == oceanstorDTree {
+		facts.NFSService, err = cli.GetNFSServiceSetting(ctx)
+		if err != nil {
+			return facts, err
+		}
+	}
+
+	facts.MissingPortals, err = getMissingPortals(ctx, cli, facts.Protocol, facts.Portals)
+	return facts, err
+}
+
+func getConfiguredPortals(portals interface{}) []string {
+	list, ok := portals.([]interface{})
+	if !ok {
+		return nil
+	}
+
+	var result []string
+	for _, portal := range list {
+		if value, ok := portal.(string); ok {
+			result = append(result, value)
+		}
+	}
+	return result
+}
+
+// getMissingPortals returns the configured portals which are not the iSCSI target portals or the logical ports
+// of the storage, the portals which are not IP addresses are not checked
+func getMissingPortals(ctx context.Context, cli *storageClient.BaseClient, protocol string,
+	portals []string) ([]string, error) {
+	if len(portals) == 0 {
+		return nil, nil
+	}
+
+	if protocol == "iscsi" {
+		targetIPs, err := getISCSITargetIPs(ctx, cli)
+		if err != nil {
+			return nil, err
+		}
+
+		var missing []string
+		for _, portal := range portals {
+			if ip := net.ParseIP(portal); ip != nil && !targetIPs[ip.String()] {
+				missing = append(missing, portal)
+			}
+		}
+		return missing, nil
+	}
+
+	var missing []string
+	for _, portal := range portals {
+		if net.ParseIP(portal) == nil {
+			continue
+		}
+
+		logicalPort, err := cli.GetRoCEPortalByIP(ctx, portal)
+		if err != nil {
+			return nil, err
+		}
+		if logicalPort == nil {
+			missing = append(missing, portal)
+		}
+	}
+	return missing, nil
+}
+
+// getISCSITargetIPs returns the IPs of the iSCSI target ports, which are the last part of the target IQNs
+func getISCSITargetIPs(ctx context.Context, cli *storageClient.BaseClient) (map[string]bool, error) {
+	ports, err := cli.GetIscsiTgtPort(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	targetIPs := make(map[string]bool)
+	for _, i := range ports {
+		port, ok := i.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		portID, ok := port["ID"].(string)
+		if !ok {
+			continue
+		}
+
+		_, iqn, found := strings.Cut(strings.Split(portID, ",")[0], "+")
+		splitIqn := strings.Split(iqn, ":")
+		if !found || len(splitIqn) < 6 {
+			continue
+		}
+		targetIPs[splitIqn[5]] = true
+	}
+	return targetIPs, nil
+}
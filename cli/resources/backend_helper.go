@@ -18,8 +18,11 @@ package resources
 
 import (
 	"bytes"
+	"context"
+	"crypto/x509"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"os"
 	"reflect"
@@ -31,8 +34,12 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	k8string "k8s.io/utils/strings"
 
+	"huawei-csi-driver/cli/client"
+	"huawei-csi-driver/cli/config"
 	"huawei-csi-driver/cli/helper"
 	xuanwuv1 "huawei-csi-driver/client/apis/xuanwu/v1"
+	pkgUtils "huawei-csi-driver/pkg/utils"
+	storageClient "huawei-csi-driver/storage/oceanstor/client"
 )
 
 const (
@@ -48,6 +55,12 @@ const (
 	KindStorageBackendClaim = "StorageBackendClaim"
 	// YamlSeparator defines the separator of yaml file
 	YamlSeparator = "---"
+
+	oceanstorSan    = "oceanstor-san"
+	oceanstorPrefix = "oceanstor"
+
+	featureUnknown = "unknown"
+	featureNone    = "none"
 )
 
 // BackendConfiguration backend config
@@ -56,6 +69,8 @@ type BackendConfiguration struct {
 	NameSpace           string                   `json:"namespace,omitempty" yaml:"namespace"`
 	Storage             string                   `json:"storage,omitempty" yaml:"storage"`
 	VstoreName          string                   `json:"vstoreName,omitempty" yaml:"vstoreName"`
+	AuthDomain          string                   `json:"authDomain,omitempty" yaml:"authDomain"`
+	AuthDomainType      string                   `json:"authDomainType,omitempty" yaml:"authDomainType"`
 	AccountName         string                   `json:"accountName,omitempty" yaml:"accountName"`
 	Urls                []string                 `json:"urls,omitempty" yaml:"urls"`
 	Pools               []string                 `json:"pools,omitempty" yaml:"pools"`
@@ -63,13 +78,23 @@ type BackendConfiguration struct {
 	MetroBackend        string                   `json:"metroBackend,omitempty" yaml:"metroBackend"`
 	SupportedTopologies []map[string]interface{} `json:"supportedTopologies,omitempty" yaml:"supportedTopologies"`
 	MaxClientThreads    string                   `json:"maxClientThreads,omitempty" yaml:"maxClientThreads"`
+	DefaultParameters   map[string]string        `json:"defaultParameters,omitempty" yaml:"defaultParameters"`
+	MaxSnapshotThreads  int                      `json:"maxSnapshotThreads,omitempty" yaml:"maxSnapshotThreads"`
+	ReadOnly            bool                     `json:"readOnly,omitempty" yaml:"readOnly"`
+	RecordRequests      bool                     `json:"recordRequests,omitempty" yaml:"recordRequests"`
 	Configured          bool                     `json:"-" yaml:"configured"`
 	Provisioner         string                   `json:"provisioner,omitempty" yaml:"provisioner"`
 	Parameters          struct {
-		Protocol   string                            `json:"protocol,omitempty" yaml:"protocol"`
-		ParentName string                            `json:"parentname,omitempty" yaml:"parentname"`
-		Portals    interface{}                       `json:"portals,omitempty" yaml:"portals"`
-		Alua       map[string]map[string]interface{} `json:"ALUA,omitempty" yaml:"ALUA"`
+		Protocol           string                            `json:"protocol,omitempty" yaml:"protocol"`
+		ParentName         string                            `json:"parentname,omitempty" yaml:"parentname"`
+		Portals            interface{}                       `json:"portals,omitempty" yaml:"portals"`
+		Alua               map[string]map[string]interface{} `json:"ALUA,omitempty" yaml:"ALUA"`
+		QosPolicyLimit     int64                             `json:"qosPolicyLimit,omitempty" yaml:"qosPolicyLimit"`
+		NfsSharePathPrefix string                            `json:"nfsSharePathPrefix,omitempty" yaml:"nfsSharePathPrefix"`
+		AutoExpandPool     string                            `json:"autoExpandPool,omitempty" yaml:"autoExpandPool"`
+		PriorityClassToQoS map[string]string                 `json:"priorityClassToQoS,omitempty" yaml:"priorityClassToQoS"`
+		MinPortals         int64                             `json:"minPortals,omitempty" yaml:"minPortals"`
+		PoolTags           map[string]string                 `json:"poolTags,omitempty" yaml:"poolTags"`
 	} `json:"parameters,omitempty" yaml:"parameters"`
 }
 
@@ -99,6 +124,18 @@ type BackendShow struct {
 	Url         string `show:"Url"`
 }
 
+// BackendFeatureShow the content echoed by executing the oceanctl get features
+type BackendFeatureShow struct {
+	Namespace   string `show:"NAMESPACE"`
+	Name        string `show:"NAME"`
+	Metro       string `show:"METRO"`
+	Replication string `show:"REPLICATION"`
+	NVMe        string `show:"NVME"`
+	ThinClone   string `show:"THINCLONE"`
+	Label       string `show:"LABEL"`
+	NFS         string `show:"NFS"`
+}
+
 // BackendConfigShow the content echoed by executing the oceanctl create backend
 type BackendConfigShow struct {
 	Number     string `show:"NUMBER"`
@@ -110,12 +147,13 @@ type BackendConfigShow struct {
 
 // StorageBackendClaimConfig used to create a storageBackendClaim object
 type StorageBackendClaimConfig struct {
-	Name             string
-	Namespace        string
-	ConfigmapMeta    string
-	SecretMeta       string
-	MaxClientThreads string
-	Provisioner      string
+	Name              string
+	Namespace         string
+	ConfigmapMeta     string
+	SecretMeta        string
+	MaxClientThreads  string
+	Provisioner       string
+	DefaultParameters map[string]string
 }
 
 // SecretConfig used to create a secret object
@@ -162,6 +200,43 @@ func (b *BackendShowWide) ShowWithClaimOption(claim xuanwuv1.StorageBackendClaim
 	return b
 }
 
+// NewBackendFeatureShow resolves the feature matrix of the backend from the capabilities reported by the
+// storage, the features of the backend whose capabilities are not reported yet are shown as unknown
+func NewBackendFeatureShow(claim xuanwuv1.StorageBackendClaim,
+	content *xuanwuv1.StorageBackendContent) BackendFeatureShow {
+	show := BackendFeatureShow{Namespace: claim.Namespace, Name: claim.Name, Metro: featureUnknown,
+		Replication: featureUnknown, NVMe: featureUnknown, ThinClone: featureUnknown, Label: featureUnknown,
+		NFS: featureUnknown}
+	if claim.Status != nil && claim.Status.Protocol != "" {
+		show.NVMe = strconv.FormatBool(claim.Status.Protocol == "roce" || claim.Status.Protocol == "fc-nvme")
+	}
+
+	if content == nil || content.Status == nil || content.Status.Capabilities == nil {
+		return show
+	}
+
+	capabilities := content.Status.Capabilities
+	show.Metro = strconv.FormatBool(capabilities["SupportMetro"] || capabilities["SupportMetroNAS"])
+	show.Replication = strconv.FormatBool(capabilities["SupportReplication"])
+	show.ThinClone = strconv.FormatBool(capabilities["SupportClone"] && capabilities["SupportThin"])
+	show.Label = strconv.FormatBool(capabilities["SupportLabel"])
+
+	var nfsVersions []string
+	for _, version := range []struct{ capability, name string }{
+		{"SupportNFS3", "3"}, {"SupportNFS4", "4"}, {"SupportNFS41", "4.1"},
+	} {
+		if capabilities[version.capability] {
+			nfsVersions = append(nfsVersions, version.name)
+		}
+	}
+	show.NFS = strings.Join(nfsVersions, ",")
+	if show.NFS == "" {
+		show.NFS = featureNone
+	}
+
+	return show
+}
+
 // ToBackendShow convert BackendShowWide to BackendShow
 func (b *BackendShowWide) ToBackendShow() BackendShow {
 	return BackendShow{
@@ -179,12 +254,13 @@ func (b *BackendShowWide) ToBackendShow() BackendShow {
 // ToStorageBackendClaimConfig covert backend to StorageBackendClaimConfig
 func (b *BackendConfiguration) ToStorageBackendClaimConfig() StorageBackendClaimConfig {
 	return StorageBackendClaimConfig{
-		Name:             b.Name,
-		Namespace:        b.NameSpace,
-		ConfigmapMeta:    k8string.JoinQualifiedName(b.NameSpace, b.Name),
-		SecretMeta:       k8string.JoinQualifiedName(b.NameSpace, b.Name),
-		MaxClientThreads: b.MaxClientThreads,
-		Provisioner:      b.Provisioner,
+		Name:              b.Name,
+		Namespace:         b.NameSpace,
+		ConfigmapMeta:     k8string.JoinQualifiedName(b.NameSpace, b.Name),
+		SecretMeta:        k8string.JoinQualifiedName(b.NameSpace, b.Name),
+		MaxClientThreads:  b.MaxClientThreads,
+		Provisioner:       b.Provisioner,
+		DefaultParameters: b.DefaultParameters,
 	}
 }
 
@@ -272,10 +348,11 @@ func (c *StorageBackendClaimConfig) ToStorageBackendClaim() xuanwuv1.StorageBack
 			Namespace: c.Namespace,
 		},
 		Spec: xuanwuv1.StorageBackendClaimSpec{
-			Provider:         c.Provisioner,
-			ConfigMapMeta:    c.ConfigmapMeta,
-			SecretMeta:       c.SecretMeta,
-			MaxClientThreads: c.MaxClientThreads,
+			Provider:          c.Provisioner,
+			ConfigMapMeta:     c.ConfigmapMeta,
+			SecretMeta:        c.SecretMeta,
+			MaxClientThreads:  c.MaxClientThreads,
+			DefaultParameters: c.DefaultParameters,
 		},
 	}
 }
@@ -397,3 +474,76 @@ func LoadBackendsFromYaml(filename string) (map[string]*BackendConfiguration, er
 	}
 	return backends, nil
 }
+
+// fetchClaimBackendConfig returns the backend configuration of the storage backend claim
+func fetchClaimBackendConfig(namespace string, claim xuanwuv1.StorageBackendClaim) (*BackendConfiguration, error) {
+	_, configmapName := k8string.SplitQualifiedName(claim.Spec.ConfigMapMeta)
+	backendConfigs, err := FetchBackendConfig(namespace, configmapName)
+	if err != nil {
+		return nil, err
+	}
+
+	backendConfig, ok := backendConfigs[claim.Name]
+	if !ok {
+		return nil, fmt.Errorf("backend %s is not configured", claim.Name)
+	}
+
+	return backendConfig, nil
+}
+
+// loginStandaloneStorage logs in the OceanStor storage of the backend with the account of the backend secret
+func loginStandaloneStorage(ctx context.Context, namespace string, claim xuanwuv1.StorageBackendClaim,
+	backendConfig *BackendConfiguration) (*storageClient.BaseClient, error) {
+	_, secretName := k8string.SplitQualifiedName(claim.Spec.SecretMeta)
+	secretClient := client.NewCommonCallHandler[corev1.Secret](config.Client)
+	secret, err := secretClient.QueryByName(namespace, secretName)
+	if err != nil {
+		return nil, err
+	}
+
+	rootCAs, err := loadBackendCertPool(namespace, claim)
+	if err != nil {
+		return nil, err
+	}
+
+	cli, err := storageClient.NewStandaloneClient(ctx, &storageClient.NewClientConfig{
+		Urls:       backendConfig.Urls,
+		User:       string(secret.Data["user"]),
+		VstoreName: backendConfig.VstoreName,
+		AuthDomain: backendConfig.AuthDomain,
+		UseCert:    claim.Spec.UseCert,
+		RootCAs:    rootCAs,
+	}, string(secret.Data["password"]))
+	if err != nil {
+		return nil, err
+	}
+
+	return cli, cli.Login(ctx)
+}
+
+// loadBackendCertPool returns the cert pool of the certificate bound to the backend as the driver verifies
+// the storage with, nil is returned if the backend does not use the certificate
+func loadBackendCertPool(namespace string, claim xuanwuv1.StorageBackendClaim) (*x509.CertPool, error) {
+	if !claim.Spec.UseCert {
+		return nil, nil
+	}
+
+	_, certSecretName := k8string.SplitQualifiedName(claim.Spec.CertSecret)
+	if certSecretName == "" {
+		return nil, fmt.Errorf("backend %s uses the certificate, but no certificate is bound to it", claim.Name)
+	}
+
+	secretClient := client.NewCommonCallHandler[corev1.Secret](config.Client)
+	secret, err := secretClient.QueryByName(namespace, certSecretName)
+	if err != nil {
+		return nil, err
+	}
+
+	certPool, err := pkgUtils.NewCertPool(secret.Data["tls.crt"])
+	if err != nil {
+		return nil, fmt.Errorf("load certificate %s of backend %s failed, error: %v",
+			certSecretName, claim.Name, err)
+	}
+
+	return certPool, nil
+}
@@ -0,0 +1,240 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package resources
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"huawei-csi-driver/cli/client"
+	"huawei-csi-driver/cli/config"
+	"huawei-csi-driver/cli/helper"
+	xuanwuV1 "huawei-csi-driver/client/apis/xuanwu/v1"
+	storageClient "huawei-csi-driver/storage/oceanstor/client"
+	"huawei-csi-driver/utils"
+)
+
+const (
+	// BenchmarkSampleInterval is the interval of sampling the performance data of the volume
+	BenchmarkSampleInterval = 5 * time.Second
+	// BenchmarkOutputCSV is the output format which prints the raw samples in csv
+	BenchmarkOutputCSV = "csv"
+
+	benchmarkPercentile = 0.95
+)
+
+// BenchmarkSample is a performance data sample of the volume
+type BenchmarkSample struct {
+	Time time.Time
+	storageClient.LunPerformanceData
+}
+
+// BenchmarkReportShow the statistics of a performance metric displayed to the user
+type BenchmarkReportShow struct {
+	Metric string `show:"METRIC" json:"metric"`
+	Min    string `show:"MIN" json:"min"`
+	Max    string `show:"MAX" json:"max"`
+	Avg    string `show:"AVG" json:"avg"`
+	P95    string `show:"P95" json:"p95"`
+}
+
+// Benchmark is used to sample the performance data of a volume on the storage and report its statistics
+type Benchmark struct {
+	// resource of request
+	resource *Resource
+}
+
+// NewBenchmark initialize a Benchmark instance
+func NewBenchmark(resource *Resource) *Benchmark {
+	return &Benchmark{resource: resource}
+}
+
+// Run samples the performance data of the volume during the duration and prints the report
+func (b *Benchmark) Run(volume string, duration time.Duration) error {
+	pvClient := client.NewCommonCallHandler[corev1.PersistentVolume](config.Client)
+	pv, err := pvClient.QueryByName(b.resource.namespace, volume)
+	if err != nil {
+		return err
+	}
+
+	if pv.Name == "" {
+		return helper.PrintlnError(fmt.Errorf("volume %s not found", volume))
+	}
+
+	if pv.Spec.CSI == nil {
+		return helper.PrintlnError(fmt.Errorf("volume %s is not provisioned by CSI", volume))
+	}
+	backendName, lunName := utils.SplitVolumeId(pv.Spec.CSI.VolumeHandle)
+
+	storageClaimClient := client.NewCommonCallHandler[xuanwuV1.StorageBackendClaim](config.Client)
+	claim, err := storageClaimClient.QueryByName(b.resource.namespace, backendName)
+	if err != nil {
+		return err
+	}
+
+	if claim.Name == "" {
+		helper.PrintNotFoundBackend(backendName)
+		return nil
+	}
+
+	backendConfig, err := fetchClaimBackendConfig(b.resource.namespace, claim)
+	if err != nil {
+		return helper.LogErrorf("fetch backend config failed, error: %v", err)
+	}
+
+	if backendConfig.Storage != oceanstorSan {
+		return helper.PrintlnError(fmt.Errorf("benchmark only supports %s, but the storage of backend %s is %s",
+			oceanstorSan, backendName, backendConfig.Storage))
+	}
+
+	ctx := context.Background()
+	cli, err := loginStandaloneStorage(ctx, b.resource.namespace, claim, backendConfig)
+	if err != nil {
+		return helper.LogErrorf("login storage failed, error: %v", err)
+	}
+	defer cli.Logout(ctx)
+
+	lun, err := cli.GetLunByName(ctx, lunName)
+	if err != nil {
+		return helper.LogErrorf("get lun failed, error: %v", err)
+	}
+	if lun == nil {
+		return helper.PrintlnError(fmt.Errorf("lun %s of volume %s does not exist on the storage", lunName, volume))
+	}
+
+	lunID, ok := lun["ID"].(string)
+	if !ok {
+		return helper.PrintlnError(fmt.Errorf("convert ID of lun %s to string failed, data: %v", lunName, lun))
+	}
+
+	fmt.Fprintf(os.Stderr, "Sampling the performance of volume %s every %s for %s...\n", volume,
+		BenchmarkSampleInterval, duration)
+	samples, err := sampleLunPerformance(ctx, cli, lunID, duration)
+	if err != nil {
+		return helper.LogErrorf("get lun performance data failed, error: %v", err)
+	}
+
+	report := NewBenchmarkReport(samples)
+	if b.resource.output != BenchmarkOutputCSV {
+		helper.GetPrintFunc[BenchmarkReportShow](b.resource.output)(report)
+		return nil
+	}
+
+	// the report is printed to stderr, so that the csv samples on stdout can be redirected to a file
+	if err = writeBenchmarkSamples(samples); err != nil {
+		return helper.LogErrorf("write benchmark samples failed, error: %v", err)
+	}
+	for _, show := range report {
+		fmt.Fprintf(os.Stderr, "%s: min %s, max %s, avg %s, p95 %s\n", show.Metric, show.Min, show.Max,
+			show.Avg, show.P95)
+	}
+	return nil
+}
+
+func sampleLunPerformance(ctx context.Context, cli *storageClient.BaseClient, lunID string,
+	duration time.Duration) ([]BenchmarkSample, error) {
+	ticker := time.NewTicker(BenchmarkSampleInterval)
+	defer ticker.Stop()
+
+	var samples []BenchmarkSample
+	deadline := time.Now().Add(duration)
+	for now := range ticker.C {
+		data, err := cli.GetLunPerformanceData(ctx, lunID)
+		if err != nil {
+			return nil, err
+		}
+		samples = append(samples, BenchmarkSample{Time: now, LunPerformanceData: *data})
+
+		if !now.Add(BenchmarkSampleInterval).Before(deadline) {
+			break
+		}
+	}
+
+	return samples, nil
+}
+
+func writeBenchmarkSamples(samples []BenchmarkSample) error {
+	writer := csv.NewWriter(os.Stdout)
+	if err := writer.Write([]string{"time", "iops", "throughput(MB/s)", "latency(ms)"}); err != nil {
+		return err
+	}
+
+	for _, sample := range samples {
+		err := writer.Write([]string{
+			sample.Time.Format(time.RFC3339),
+			strconv.FormatFloat(sample.IOPS, 'f', 2, 64),
+			strconv.FormatFloat(sample.Bandwidth, 'f', 2, 64),
+			strconv.FormatFloat(sample.Latency, 'f', 2, 64),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// NewBenchmarkReport returns the min, max, avg and p95 of the iops, throughput and latency of the samples
+func NewBenchmarkReport(samples []BenchmarkSample) []BenchmarkReportShow {
+	metrics := []struct {
+		name  string
+		value func(BenchmarkSample) float64
+	}{
+		{"IOPS", func(s BenchmarkSample) float64 { return s.IOPS }},
+		{"Throughput(MB/s)", func(s BenchmarkSample) float64 { return s.Bandwidth }},
+		{"Latency(ms)", func(s BenchmarkSample) float64 { return s.Latency }},
+	}
+
+	report := make([]BenchmarkReportShow, 0, len(metrics))
+	for _, metric := range metrics {
+		report = append(report, newBenchmarkReportShow(metric.name, helper.MapTo(samples, metric.value)))
+	}
+	return report
+}
+
+func newBenchmarkReportShow(metric string, values []float64) BenchmarkReportShow {
+	show := BenchmarkReportShow{Metric: metric}
+	if len(values) == 0 {
+		return show
+	}
+
+	sorted := append([]float64{}, values...)
+	sort.Float64s(sorted)
+
+	var sum float64
+	for _, value := range sorted {
+		sum += value
+	}
+
+	// nearest-rank percentile
+	rank := int(math.Ceil(benchmarkPercentile*float64(len(sorted)))) - 1
+	format := func(value float64) string { return strconv.FormatFloat(value, 'f', 2, 64) }
+	show.Min = format(sorted[0])
+	show.Max = format(sorted[len(sorted)-1])
+	show.Avg = format(sum / float64(len(sorted)))
+	show.P95 = format(sorted[rank])
+	return show
+}
@@ -0,0 +1,42 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package resources
+
+import (
+	"reflect"
+	"testing"
+
+	storageClient "huawei-csi-driver/storage/oceanstor/client"
+)
+
+func TestNewBenchmarkReport(t *testing.T) {
+	var samples []BenchmarkSample
+	for i := 1; i <= 20; i++ {
+		samples = append(samples, BenchmarkSample{LunPerformanceData: storageClient.LunPerformanceData{
+			IOPS: float64(i * 100), Bandwidth: float64(i), Latency: 0.5,
+		}})
+	}
+
+	want := []BenchmarkReportShow{
+		{Metric: "IOPS", Min: "100.00", Max: "2000.00", Avg: "1050.00", P95: "1900.00"},
+		{Metric: "Throughput(MB/s)", Min: "1.00", Max: "20.00", Avg: "10.50", P95: "19.00"},
+		{Metric: "Latency(ms)", Min: "0.50", Max: "0.50", Avg: "0.50", P95: "0.50"},
+	}
+	if got := NewBenchmarkReport(samples); !reflect.DeepEqual(got, want) {
+		t.Errorf("NewBenchmarkReport() = %v, want %v", got, want)
+	}
+}
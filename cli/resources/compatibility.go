@@ -0,0 +1,222 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package resources
+
+import (
+	"fmt"
+	"strings"
+
+	"huawei-csi-driver/pkg/constants"
+	"huawei-csi-driver/utils"
+)
+
+const (
+	// CompatibilityPass means the storage is compatible with the driver
+	CompatibilityPass = "PASS"
+	// CompatibilityWarn means some functions of the driver are limited on the storage
+	CompatibilityWarn = "WARN"
+	// CompatibilityFail means the backend is not able to work with the driver
+	CompatibilityFail = "FAIL"
+
+	oceanstorNas = "oceanstor-nas"
+)
+
+// featureRequirement is a licensed feature the driver relies on
+type featureRequirement struct {
+	// the alternative licensed features, any of them meets the requirement
+	Features []string
+	// the storage types the requirement applies to
+	StorageTypes []string
+	// the requirement only applies to the hyperMetro backends
+	HyperMetro bool
+	Level      string
+	Impact     string
+}
+
+// knownIssue is a known issue of the firmware versions of a product family
+type knownIssue struct {
+	// the issue applies to the firmware versions lower than it, empty means all versions
+	VersionBelow string
+	// the storage types and protocols the issue applies to, empty means all
+	StorageTypes []string
+	Protocols    []string
+	Level        string
+	Description  string
+}
+
+// compatibilityEntry is the compatibility of a product family with the driver
+type compatibilityEntry struct {
+	Requirements []featureRequirement
+	KnownIssues  []knownIssue
+}
+
+// storageFacts is the information of the storage the compatibility is evaluated against
+type storageFacts struct {
+	StorageType string
+	Protocol    string
+	HyperMetro  bool
+	Product     string
+	Version     string
+	Features    map[string]int
+	// the NFS versions enabled by the NFS service, nil for the block storage
+	NFSService map[string]bool
+	// the configured portals and those of them which are not found on the storage
+	Portals        []string
+	MissingPortals []string
+}
+
+// CompatibilityFinding is a result of evaluating the compatibility of a backend
+type CompatibilityFinding struct {
+	Level   string `json:"level"`
+	Message string `json:"message"`
+}
+
+var commonRequirements = []featureRequirement{
+	{
+		Features:     []string{"SmartThin"},
+		StorageTypes: []string{oceanstorSan, oceanstorNas, oceanstorDTree},
+		Level:        CompatibilityWarn,
+		Impact:       "the thin volumes can not be provisioned",
+	},
+	{
+		Features:     []string{"HyperMetro"},
+		StorageTypes: []string{oceanstorSan},
+		HyperMetro:   true,
+		Level:        CompatibilityFail,
+		Impact:       "the hyperMetro volumes can not be provisioned",
+	},
+	{
+		Features:     []string{"HyperMetroNAS"},
+		StorageTypes: []string{oceanstorNas},
+		HyperMetro:   true,
+		Level:        CompatibilityFail,
+		Impact:       "the hyperMetro volumes can not be provisioned",
+	},
+	{
+		Features:     []string{"HyperClone", "HyperCopy"},
+		StorageTypes: []string{oceanstorSan, oceanstorNas},
+		Level:        CompatibilityWarn,
+		Impact:       "the volumes can not be cloned or restored from snapshots",
+	},
+}
+
+// compatibilityMatrix is the compatibility of the product families supported by the driver,
+// a new firmware issue is added to the known issues of its product family
+var compatibilityMatrix = map[string]compatibilityEntry{
+	constants.OceanStorDoradoV6: {
+		Requirements: commonRequirements,
+		KnownIssues: []knownIssue{
+			{
+				VersionBelow: constants.DoradoV615,
+				Level:        CompatibilityWarn,
+				Description:  "the capacity of the vStore and its hyperMetro vStore pair are not reported",
+			},
+			{
+				VersionBelow: constants.MinVersionSupportLabel,
+				StorageTypes: []string{oceanstorNas},
+				Protocols:    []string{"nfs+"},
+				Level:        CompatibilityFail,
+				Description:  "the nfs+ protocol is not supported",
+			},
+			{
+				VersionBelow: constants.MinVersionSupportLabel,
+				Level:        CompatibilityWarn,
+				Description:  "the volumes can not be labeled on the storage",
+			},
+		},
+	},
+	constants.OceanStorDoradoV3: {Requirements: commonRequirements},
+	constants.OceanStorV5:       {Requirements: commonRequirements},
+	constants.OceanStorV3:       {Requirements: commonRequirements},
+}
+
+// evaluateCompatibility evaluates the facts of the storage against the compatibility matrix
+func evaluateCompatibility(facts storageFacts, matrix map[string]compatibilityEntry) []CompatibilityFinding {
+	entry, ok := matrix[facts.Product]
+	if !ok {
+		return []CompatibilityFinding{{Level: CompatibilityFail,
+			Message: fmt.Sprintf("product family %s is not supported", facts.Product)}}
+	}
+
+	var findings []CompatibilityFinding
+	for _, requirement := range entry.Requirements {
+		if !utils.IsContain(facts.StorageType, requirement.StorageTypes) ||
+			(requirement.HyperMetro && !facts.HyperMetro) || isAnyFeatureLicensed(facts.Features, requirement.Features) {
+			continue
+		}
+		findings = append(findings, CompatibilityFinding{Level: requirement.Level,
+			Message: fmt.Sprintf("feature %s is not licensed, %s", strings.Join(requirement.Features, " or "),
+				requirement.Impact)})
+	}
+
+	if facts.NFSService != nil && !facts.NFSService["SupportNFS3"] && !facts.NFSService["SupportNFS4"] &&
+		!facts.NFSService["SupportNFS41"] {
+		findings = append(findings, CompatibilityFinding{Level: CompatibilityFail,
+			Message: "no NFS version is enabled by the NFS service"})
+	}
+
+	if len(facts.MissingPortals) != 0 {
+		level := CompatibilityWarn
+		if len(facts.MissingPortals) == len(facts.Portals) {
+			level = CompatibilityFail
+		}
+		findings = append(findings, CompatibilityFinding{Level: level,
+			Message: fmt.Sprintf("portals %v are not found on the storage", facts.MissingPortals)})
+	}
+
+	for _, issue := range entry.KnownIssues {
+		if issue.matches(facts) {
+			findings = append(findings, CompatibilityFinding{Level: issue.Level,
+				Message: fmt.Sprintf("known issue of version %s: %s", facts.Version, issue.Description)})
+		}
+	}
+	return findings
+}
+
+func (issue knownIssue) matches(facts storageFacts) bool {
+	// the versions are compared as the driver does, the unknown version is not regarded as affected
+	if issue.VersionBelow != "" && (facts.Version == "" || facts.Version >= issue.VersionBelow) {
+		return false
+	}
+	if len(issue.StorageTypes) != 0 && !utils.IsContain(facts.StorageType, issue.StorageTypes) {
+		return false
+	}
+	return len(issue.Protocols) == 0 || utils.IsContain(facts.Protocol, issue.Protocols)
+}
+
+func isAnyFeatureLicensed(licensed map[string]int, features []string) bool {
+	for _, feature := range features {
+		if utils.IsSupportFeature(licensed, feature) {
+			return true
+		}
+	}
+	return false
+}
+
+// compatibilityResult returns the most severe level of the findings
+func compatibilityResult(findings []CompatibilityFinding) string {
+	result := CompatibilityPass
+	for _, finding := range findings {
+		if finding.Level == CompatibilityFail {
+			return CompatibilityFail
+		}
+		if finding.Level == CompatibilityWarn {
+			result = CompatibilityWarn
+		}
+	}
+	return result
+}
@@ -0,0 +1,131 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package resources
+
+import (
+	"reflect"
+	"testing"
+
+	"huawei-csi-driver/pkg/constants"
+)
+
+func TestCompatibilityMatrix(t *testing.T) {
+	levels := map[string]bool{CompatibilityWarn: true, CompatibilityFail: true}
+	for product, entry := range compatibilityMatrix {
+		for _, requirement := range entry.Requirements {
+			if len(requirement.Features) == 0 || len(requirement.StorageTypes) == 0 || !levels[requirement.Level] {
+				t.Errorf("product %s has invalid feature requirement %+v", product, requirement)
+			}
+		}
+		for _, issue := range entry.KnownIssues {
+			if issue.Description == "" || !levels[issue.Level] {
+				t.Errorf("product %s has invalid known issue %+v", product, issue)
+			}
+		}
+	}
+}
+
+func TestEvaluateCompatibility(t *testing.T) {
+	licensed := map[string]int{"SmartThin": 1, "HyperClone": 1, "HyperMetro": 2}
+	cases := []struct {
+		name       string
+		facts      storageFacts
+		wantResult string
+		wantCount  int
+	}{
+		{
+			name: "compatible san",
+			facts: storageFacts{StorageType: oceanstorSan, Protocol: "iscsi", HyperMetro: true,
+				Product: constants.OceanStorDoradoV6, Version: "6.1.7", Features: licensed,
+				Portals: []string{"192.168.1.1"}},
+			wantResult: CompatibilityPass,
+		},
+		{
+			name:       "unsupported product",
+			facts:      storageFacts{StorageType: oceanstorSan, Product: "Unknown"},
+			wantResult: CompatibilityFail,
+			wantCount:  1,
+		},
+		{
+			name: "feature not licensed",
+			facts: storageFacts{StorageType: oceanstorSan, Product: constants.OceanStorV5,
+				Features: map[string]int{"SmartThin": 1, "HyperCopy": 0}},
+			wantResult: CompatibilityWarn,
+			wantCount:  1,
+		},
+		{
+			name: "metro feature not licensed",
+			facts: storageFacts{StorageType: oceanstorNas, HyperMetro: true, Product: constants.OceanStorV5,
+				Features: licensed, NFSService: map[string]bool{"SupportNFS3": true}},
+			wantResult: CompatibilityFail,
+			wantCount:  1,
+		},
+		{
+			name: "nfs service disabled",
+			facts: storageFacts{StorageType: oceanstorDTree, Product: constants.OceanStorDoradoV6, Version: "6.1.7",
+				Features: licensed, NFSService: map[string]bool{"SupportNFS3": false}},
+			wantResult: CompatibilityFail,
+			wantCount:  1,
+		},
+		{
+			name: "some portals missing",
+			facts: storageFacts{StorageType: oceanstorSan, Product: constants.OceanStorV3, Features: licensed,
+				Portals: []string{"192.168.1.1", "192.168.1.2"}, MissingPortals: []string{"192.168.1.2"}},
+			wantResult: CompatibilityWarn,
+			wantCount:  1,
+		},
+		{
+			name: "all portals missing",
+			facts: storageFacts{StorageType: oceanstorSan, Product: constants.OceanStorV3, Features: licensed,
+				Portals: []string{"192.168.1.1"}, MissingPortals: []string{"192.168.1.1"}},
+			wantResult: CompatibilityFail,
+			wantCount:  1,
+		},
+		{
+			name: "known issues of old version",
+			facts: storageFacts{StorageType: oceanstorNas, Protocol: "nfs+", Product: constants.OceanStorDoradoV6,
+				Version: "6.1.3", Features: licensed, NFSService: map[string]bool{"SupportNFS41": true}},
+			wantResult: CompatibilityFail,
+			wantCount:  3,
+		},
+		{
+			name: "unknown version",
+			facts: storageFacts{StorageType: oceanstorSan, Product: constants.OceanStorDoradoV6,
+				Features: licensed},
+			wantResult: CompatibilityPass,
+		},
+	}
+
+	for _, c := range cases {
+		findings := evaluateCompatibility(c.facts, compatibilityMatrix)
+		if result := compatibilityResult(findings); result != c.wantResult || len(findings) != c.wantCount {
+			t.Errorf("%s: evaluateCompatibility() = %s %v, want %s with %d findings",
+				c.name, result, findings, c.wantResult, c.wantCount)
+		}
+	}
+}
+
+func TestGetConfiguredPortals(t *testing.T) {
+	got := getConfiguredPortals([]interface{}{"192.168.1.1", 1, "192.168.1.2"})
+	if want := []string{"192.168.1.1", "192.168.1.2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("getConfiguredPortals() = %v, want %v", got, want)
+	}
+
+	if got = getConfiguredPortals(map[string]interface{}{}); got != nil {
+		t.Errorf("getConfiguredPortals() = %v, want nil", got)
+	}
+}
@@ -0,0 +1,163 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package resources
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"huawei-csi-driver/cli/client"
+	"huawei-csi-driver/cli/config"
+	"huawei-csi-driver/cli/helper"
+	xuanwuV1 "huawei-csi-driver/client/apis/xuanwu/v1"
+	"huawei-csi-driver/pkg/constants"
+	storageClient "huawei-csi-driver/storage/oceanstor/client"
+	"huawei-csi-driver/utils"
+)
+
+const (
+	oceanstorDTree = "oceanstor-dtree"
+
+	// dTreeParentNameAttribute is the volume attribute of the filesystem which the DTree volume is created under
+	dTreeParentNameAttribute = "dTreeParentName"
+)
+
+// DTreeAccessLogShow the content of a DTree access record displayed to the user
+type DTreeAccessLogShow struct {
+	ClientIP  string `show:"CLIENT IP" json:"clientIP"`
+	Operation string `show:"OPERATION" json:"operation"`
+	FilePath  string `show:"FILE PATH" json:"filePath"`
+	Time      string `show:"TIME" json:"time"`
+}
+
+// DTreeAccessLog is used to query the NFS access records of a DTree volume on the storage
+type DTreeAccessLog struct {
+	// resource of request
+	resource *Resource
+}
+
+// NewDTreeAccessLog initialize a DTreeAccessLog instance
+func NewDTreeAccessLog(resource *Resource) *DTreeAccessLog {
+	return &DTreeAccessLog{resource: resource}
+}
+
+// Show displays the NFS access records of the DTree volume since the start time
+func (d *DTreeAccessLog) Show(volume string, startTime time.Time) error {
+	pvClient := client.NewCommonCallHandler[corev1.PersistentVolume](config.Client)
+	pv, err := pvClient.QueryByName(d.resource.namespace, volume)
+	if err != nil {
+		return err
+	}
+
+	if pv.Name == "" {
+		return helper.PrintlnError(fmt.Errorf("volume %s not found", volume))
+	}
+
+	if pv.Spec.CSI == nil {
+		return helper.PrintlnError(fmt.Errorf("volume %s is not provisioned by CSI", volume))
+	}
+	backendName, dTreeName := utils.SplitVolumeId(pv.Spec.CSI.VolumeHandle)
+
+	storageClaimClient := client.NewCommonCallHandler[xuanwuV1.StorageBackendClaim](config.Client)
+	claim, err := storageClaimClient.QueryByName(d.resource.namespace, backendName)
+	if err != nil {
+		return err
+	}
+
+	if claim.Name == "" {
+		helper.PrintNotFoundBackend(backendName)
+		return nil
+	}
+
+	backendConfig, err := fetchClaimBackendConfig(d.resource.namespace, claim)
+	if err != nil {
+		return helper.LogErrorf("fetch backend config failed, error: %v", err)
+	}
+
+	if backendConfig.Storage != oceanstorDTree {
+		return helper.PrintlnError(fmt.Errorf("volume %s is not a DTree volume, the storage of backend %s is %s",
+			volume, backendName, backendConfig.Storage))
+	}
+
+	ctx := context.Background()
+	cli, err := loginStandaloneStorage(ctx, d.resource.namespace, claim, backendConfig)
+	if err != nil {
+		return helper.LogErrorf("login storage failed, error: %v", err)
+	}
+	defer cli.Logout(ctx)
+
+	records, err := cli.GetNFSAccessLog(ctx, getDTreeParentName(&pv, backendConfig.Parameters.ParentName),
+		"/"+dTreeName, startTime.Unix())
+	if errors.Is(err, storageClient.ErrNFSAuditLogDisabled) {
+		return helper.PrintlnError(fmt.Errorf("the access log of volume %s is unavailable, enable the NFS "+
+			"audit logging on the storage of backend %s first", volume, backendName))
+	}
+	if err != nil {
+		return helper.LogErrorf("get nfs access log failed, error: %v", err)
+	}
+
+	if len(records) == 0 {
+		helper.PrintResult(fmt.Sprintf("No access records of volume %s found since %s.\n", volume,
+			startTime.Format(time.RFC3339)))
+		return nil
+	}
+
+	shows := helper.MapTo(records, func(record storageClient.NFSAccessRecord) DTreeAccessLogShow {
+		return DTreeAccessLogShow{
+			ClientIP:  record.ClientIP,
+			Operation: record.Operation,
+			FilePath:  record.FilePath,
+			Time:      record.Time.Format(time.RFC3339),
+		}
+	})
+	helper.GetPrintFunc[DTreeAccessLogShow](d.resource.output)(shows)
+	return nil
+}
+
+// getDTreeParentName returns the filesystem which the DTree volume is under, the parent may be set by the
+// StorageClass or changed by moving the volume, the parent of the backend is used if it is not recorded on the PV
+func getDTreeParentName(pv *corev1.PersistentVolume, backendParent string) string {
+	if parent := pv.Annotations[constants.DTreeParentNameAnnotation]; parent != "" {
+		return parent
+	}
+
+	if pv.Spec.CSI != nil {
+		if parent := pv.Spec.CSI.VolumeAttributes[dTreeParentNameAttribute]; parent != "" {
+			return parent
+		}
+	}
+	return backendParent
+}
+
+// ParseStartTime parses the start time in RFC3339 format or as a duration before now
+func ParseStartTime(value string, now time.Time) (time.Time, error) {
+	if startTime, err := time.Parse(time.RFC3339, value); err == nil {
+		return startTime, nil
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration < 0 {
+		return time.Time{}, fmt.Errorf("invalid start time %s, it must be in RFC3339 format such as "+
+			"2023-06-01T08:00:00Z, or a duration before now such as 24h", value)
+	}
+
+	return now.Add(-duration), nil
+}
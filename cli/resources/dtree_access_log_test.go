@@ -0,0 +1,54 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package resources
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"huawei-csi-driver/pkg/constants"
+)
+
+func TestGetDTreeParentName(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		attributes  map[string]string
+		want        string
+	}{
+		{"BackendParent", nil, nil, "backend-fs"},
+		{"StorageClassParent", nil, map[string]string{dTreeParentNameAttribute: "sc-fs"}, "sc-fs"},
+		{"MovedParent", map[string]string{constants.DTreeParentNameAnnotation: "moved-fs"},
+			map[string]string{dTreeParentNameAttribute: "sc-fs"}, "moved-fs"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pv := &corev1.PersistentVolume{
+				ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations},
+				Spec: corev1.PersistentVolumeSpec{PersistentVolumeSource: corev1.PersistentVolumeSource{
+					CSI: &corev1.CSIPersistentVolumeSource{VolumeAttributes: tt.attributes},
+				}},
+			}
+			if got := getDTreeParentName(pv, "backend-fs"); got != tt.want {
+				t.Errorf("getDTreeParentName() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
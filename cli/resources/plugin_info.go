@@ -0,0 +1,80 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package resources
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	coreV1 "k8s.io/api/core/v1"
+
+	"huawei-csi-driver/cli/client"
+	"huawei-csi-driver/cli/config"
+)
+
+const (
+	controllerPodPrefix = "huawei-csi-controller"
+	pluginInfoCommand   = "/huawei-csi plugin-info"
+)
+
+// DriverPluginInfo is the plugin information reported by the running huawei-csi controller
+type DriverPluginInfo struct {
+	Name          string            `json:"name"`
+	VendorVersion string            `json:"vendor_version"`
+	Manifest      map[string]string `json:"manifest"`
+}
+
+// GetControllerPluginInfo queries the plugin information from a running controller pod in the namespace
+func GetControllerPluginInfo(namespace string) (*DriverPluginInfo, error) {
+	ctx := context.Background()
+	podList, err := client.NewCommonCallHandler[coreV1.PodList](config.Client).
+		GetObject(ctx, namespace, client.IgnoreNode)
+	if err != nil {
+		return nil, fmt.Errorf("query pods in namespace %s failed, error: %v", namespace, err)
+	}
+
+	podName, err := findRunningControllerPod(podList.Items)
+	if err != nil {
+		return nil, fmt.Errorf("%v in namespace %s", err, namespace)
+	}
+
+	out, err := config.Client.ExecCmdInSpecifiedContainer(ctx, namespace, csiFlagContainer,
+		pluginInfoCommand, podName)
+	if err != nil {
+		return nil, fmt.Errorf("query plugin info from pod %s failed, error: %v", podName, err)
+	}
+
+	var info DriverPluginInfo
+	if err := json.Unmarshal(out, &info); err != nil {
+		return nil, fmt.Errorf("unmarshal plugin info %s failed, error: %v", string(out), err)
+	}
+
+	return &info, nil
+}
+
+func findRunningControllerPod(pods []coreV1.Pod) (string, error) {
+	for _, pod := range pods {
+		if strings.HasPrefix(pod.Name, controllerPodPrefix) && pod.Status.Phase == coreV1.PodRunning {
+			return pod.Name, nil
+		}
+	}
+
+	return "", errors.New("no running huawei-csi controller pod found")
+}
@@ -0,0 +1,232 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package resources
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"huawei-csi-driver/cli/client"
+	"huawei-csi-driver/cli/config"
+	"huawei-csi-driver/cli/helper"
+	xuanwuV1 "huawei-csi-driver/client/apis/xuanwu/v1"
+	"huawei-csi-driver/utils"
+	"huawei-csi-driver/utils/log"
+)
+
+const (
+	// SpaceReclaimPV is the PersistentVolume type of space reclaim
+	SpaceReclaimPV = "pv"
+
+	nodePodPrefix   = "huawei-csi-node"
+	lunWWNAttribute = "lunWWN"
+
+	// spaceReclaimScript discards the unused blocks of the filesystems of the PersistentVolumes mounted on the
+	// node. The filesystem is found by the mount point of the pod, which is named by kubelet after the
+	// PersistentVolume, and is discarded once even if it is mounted by several pods.
+	spaceReclaimScript = `for pv in %s; do ` +
+		`path=$(grep -m1 " [^ ]*/volumes/kubernetes.io~csi/$pv/mount " /proc/mounts | cut -d" " -f2); ` +
+		`if [ -n "$path" ]; then out=$(fstrim -v "$path") || exit 1; echo "$pv ${out##*: }"; fi; done`
+	spaceReclaimCommand = "timeout %d sh -c '%s'"
+)
+
+// SpaceReclaim is used to reclaim the space of the thin LUNs of PersistentVolumes. The unused blocks of the
+// filesystems are discarded on the nodes, and the storage reclaims the space of the discarded blocks of the
+// thin LUNs, so that neither the storage is logged in nor its credentials are read by the tool.
+type SpaceReclaim struct {
+	// resource of request
+	resource *Resource
+}
+
+// NewSpaceReclaim initialize a SpaceReclaim instance
+func NewSpaceReclaim(resource *Resource) *SpaceReclaim {
+	return &SpaceReclaim{resource: resource}
+}
+
+// Reclaim discards the unused blocks of the PersistentVolumes on each node, and reports the progress until
+// it completes or the timeout expires
+func (s *SpaceReclaim) Reclaim(timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	pvNames, err := s.getReclaimablePVNames()
+	if err != nil {
+		return helper.PrintlnError(err)
+	}
+
+	if len(pvNames) == 0 {
+		fmt.Printf("No PersistentVolume of backend %s needs space reclaim\n", s.resource.backend)
+		return nil
+	}
+
+	nodePods, err := getRunningNodePods(ctx, s.resource.namespace)
+	if err != nil {
+		return helper.LogErrorf("get huawei-csi node pods failed, error: %v", err)
+	}
+
+	reclaimed := make(map[string]bool)
+	var failedNodes []string
+	for idx, pod := range nodePods {
+		out, err := s.reclaimOnNode(ctx, pod.Name, pvNames)
+		if ctx.Err() != nil {
+			return helper.PrintlnError(fmt.Errorf("space reclaim does not complete in %s", timeout))
+		}
+
+		if err != nil {
+			log.Errorf("reclaim space on node %s failed, error: %v", pod.Spec.NodeName, err)
+			failedNodes = append(failedNodes, pod.Spec.NodeName)
+		}
+
+		for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+			pvName, result, found := strings.Cut(line, " ")
+			if !found {
+				continue
+			}
+			reclaimed[pvName] = true
+			helper.PrintOperateResult(SpaceReclaimPV, fmt.Sprintf("space reclaimed on node %s, %s",
+				pod.Spec.NodeName, result), pvName)
+		}
+		fmt.Printf("space reclaim progress: %d/%d nodes\n", idx+1, len(nodePods))
+	}
+
+	for _, pvName := range pvNames {
+		if !reclaimed[pvName] {
+			helper.PrintOperateResult(SpaceReclaimPV, "skipped, it is not mounted on any node", pvName)
+		}
+	}
+
+	if len(failedNodes) != 0 {
+		return helper.PrintlnError(fmt.Errorf("reclaim space on nodes %v failed, see the log for details",
+			failedNodes))
+	}
+	return nil
+}
+
+func (s *SpaceReclaim) reclaimOnNode(ctx context.Context, podName string, pvNames []string) (string, error) {
+	deadline, _ := ctx.Deadline()
+	remaining := int(time.Until(deadline).Seconds())
+	if remaining <= 0 {
+		return "", context.DeadlineExceeded
+	}
+
+	cmd := fmt.Sprintf(spaceReclaimCommand, remaining,
+		fmt.Sprintf(spaceReclaimScript, strings.Join(pvNames, " ")))
+	out, err := config.Client.ExecCmdInSpecifiedContainer(ctx, s.resource.namespace, csiFlagContainer, cmd,
+		podName)
+	return string(out), err
+}
+
+// getReclaimablePVNames returns the names of the requested PersistentVolumes, or the PersistentVolumes of the
+// requested backend, whose space is able to be reclaimed
+func (s *SpaceReclaim) getReclaimablePVNames() ([]string, error) {
+	pvClient := client.NewCommonCallHandler[corev1.PersistentVolume](config.Client)
+	if s.resource.backend == "" {
+		for _, name := range s.resource.names {
+			pv, err := pvClient.QueryByName(s.resource.namespace, name)
+			if err != nil {
+				return nil, err
+			}
+
+			if pv.Name == "" {
+				return nil, fmt.Errorf("volume %s not found", name)
+			}
+
+			if err = checkSpaceReclaimable(&pv); err != nil {
+				return nil, err
+			}
+		}
+		return s.resource.names, nil
+	}
+
+	storageClaimClient := client.NewCommonCallHandler[xuanwuV1.StorageBackendClaim](config.Client)
+	claim, err := storageClaimClient.QueryByName(s.resource.namespace, s.resource.backend)
+	if err != nil {
+		return nil, err
+	}
+
+	if claim.Name == "" {
+		return nil, fmt.Errorf("backend %s not found", s.resource.backend)
+	}
+
+	pvs, err := pvClient.QueryList(s.resource.namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	var pvNames []string
+	for idx := range pvs {
+		pv := &pvs[idx]
+		if pv.Spec.CSI == nil {
+			continue
+		}
+
+		if backendName, _ := utils.SplitVolumeId(pv.Spec.CSI.VolumeHandle); backendName != s.resource.backend {
+			continue
+		}
+
+		if err = checkSpaceReclaimable(pv); err != nil {
+			log.Infof("skip space reclaim of volume %s, reason: %v", pv.Name, err)
+			continue
+		}
+		pvNames = append(pvNames, pv.Name)
+	}
+
+	return pvNames, nil
+}
+
+// checkSpaceReclaimable checks whether the volume is a filesystem on a LUN, the raw block volumes are discarded
+// by the applications, and the space of the file systems on the storage is reclaimed by the storage itself
+func checkSpaceReclaimable(pv *corev1.PersistentVolume) error {
+	if pv.Spec.CSI == nil {
+		return fmt.Errorf("volume %s is not provisioned by CSI", pv.Name)
+	}
+
+	if _, ok := pv.Spec.CSI.VolumeAttributes[lunWWNAttribute]; !ok {
+		return fmt.Errorf("volume %s is not a LUN", pv.Name)
+	}
+
+	if pv.Spec.VolumeMode != nil && *pv.Spec.VolumeMode == corev1.PersistentVolumeBlock {
+		return fmt.Errorf("volume %s is a raw block volume", pv.Name)
+	}
+
+	return nil
+}
+
+func getRunningNodePods(ctx context.Context, namespace string) ([]corev1.Pod, error) {
+	podList, err := client.NewCommonCallHandler[corev1.PodList](config.Client).
+		GetObject(ctx, namespace, client.IgnoreNode)
+	if err != nil {
+		return nil, err
+	}
+
+	var nodePods []corev1.Pod
+	for _, pod := range podList.Items {
+		if strings.HasPrefix(pod.Name, nodePodPrefix) && pod.Status.Phase == corev1.PodRunning {
+			nodePods = append(nodePods, pod)
+		}
+	}
+
+	if len(nodePods) == 0 {
+		return nil, errors.New("no running huawei-csi node pod found")
+	}
+	return nodePods, nil
+}
@@ -0,0 +1,167 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package resources
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"huawei-csi-driver/cli/client"
+	"huawei-csi-driver/cli/config"
+	xuanwuV1 "huawei-csi-driver/client/apis/xuanwu/v1"
+	"huawei-csi-driver/utils/log"
+)
+
+const logName = "resources.log"
+
+func TestMain(m *testing.M) {
+	log.MockInitLogging(logName)
+	defer log.MockStopLogging(logName)
+
+	m.Run()
+}
+
+type fakeReclaimKubeClient struct {
+	client.KubernetesClient
+	pvs      []corev1.PersistentVolume
+	pods     []corev1.Pod
+	mounted  map[string]string
+	deadline bool
+	execPods []string
+}
+
+func (f *fakeReclaimKubeClient) GetResource(names []string, namespace, outputType string,
+	resourceType client.ResourceType) ([]byte, error) {
+	if resourceType == client.Storagebackendclaim {
+		return json.Marshal(xuanwuV1.StorageBackendClaim{ObjectMeta: metav1.ObjectMeta{Name: names[0]}})
+	}
+
+	if len(names) == 0 {
+		return json.Marshal(client.ListResult[corev1.PersistentVolume]{Items: f.pvs})
+	}
+	for _, pv := range f.pvs {
+		if pv.Name == names[0] {
+			return json.Marshal(pv)
+		}
+	}
+	return nil, nil
+}
+
+func (f *fakeReclaimKubeClient) GetObject(ctx context.Context, objectType client.ObjectType, namespace,
+	nodeName string, outputType client.OutputType, data interface{}, objectName ...string) error {
+	*data.(*corev1.PodList) = corev1.PodList{Items: f.pods}
+	return nil
+}
+
+func (f *fakeReclaimKubeClient) ExecCmdInSpecifiedContainer(ctx context.Context, namespace, containerName,
+	cmd string, podName ...string) ([]byte, error) {
+	_, f.deadline = ctx.Deadline()
+	f.execPods = append(f.execPods, podName[0])
+	var out []string
+	for pv, node := range f.mounted {
+		if strings.HasPrefix(podName[0], nodePodPrefix+"-"+node) && strings.Contains(cmd, pv) {
+			out = append(out, fmt.Sprintf("%s 1024 bytes trimmed", pv))
+		}
+	}
+	return []byte(strings.Join(out, "\n")), nil
+}
+
+func newReclaimPV(name, handle string, attributes map[string]string,
+	mode corev1.PersistentVolumeMode) corev1.PersistentVolume {
+	return corev1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: corev1.PersistentVolumeSpec{
+			VolumeMode: &mode,
+			PersistentVolumeSource: corev1.PersistentVolumeSource{CSI: &corev1.CSIPersistentVolumeSource{
+				VolumeHandle: handle, VolumeAttributes: attributes,
+			}},
+		},
+	}
+}
+
+func newReclaimNodePod(name string, phase corev1.PodPhase) corev1.Pod {
+	return corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name}, Status: corev1.PodStatus{Phase: phase}}
+}
+
+func TestCheckSpaceReclaimable(t *testing.T) {
+	lun := map[string]string{lunWWNAttribute: "wwn"}
+	cases := []struct {
+		name    string
+		pv      corev1.PersistentVolume
+		wantErr bool
+	}{
+		{"FilesystemLun", newReclaimPV("pv", "backend.lun", lun, corev1.PersistentVolumeFilesystem), false},
+		{"BlockLun", newReclaimPV("pv", "backend.lun", lun, corev1.PersistentVolumeBlock), true},
+		{"Filesystem", newReclaimPV("pv", "backend.fs", map[string]string{},
+			corev1.PersistentVolumeFilesystem), true},
+		{"NotCSI", corev1.PersistentVolume{ObjectMeta: metav1.ObjectMeta{Name: "pv"}}, true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if err := checkSpaceReclaimable(&c.pv); (err != nil) != c.wantErr {
+				t.Errorf("checkSpaceReclaimable() error = %v, wantErr %v", err, c.wantErr)
+			}
+		})
+	}
+}
+
+func TestSpaceReclaimOfBackend(t *testing.T) {
+	lun := map[string]string{lunWWNAttribute: "wwn"}
+	fakeClient := &fakeReclaimKubeClient{
+		pvs: []corev1.PersistentVolume{
+			newReclaimPV("pv-1", "backend-a.lun-1", lun, corev1.PersistentVolumeFilesystem),
+			newReclaimPV("pv-2", "backend-a.lun-2", lun, corev1.PersistentVolumeBlock),
+			newReclaimPV("pv-3", "backend-b.lun-3", lun, corev1.PersistentVolumeFilesystem),
+			newReclaimPV("pv-4", "backend-a.lun-4", lun, corev1.PersistentVolumeFilesystem),
+		},
+		pods: []corev1.Pod{
+			newReclaimNodePod("huawei-csi-controller-0", corev1.PodRunning),
+			newReclaimNodePod("huawei-csi-node-a", corev1.PodRunning),
+			newReclaimNodePod("huawei-csi-node-b", corev1.PodPending),
+		},
+		mounted: map[string]string{"pv-1": "a"},
+	}
+	temp := config.Client
+	defer func() { config.Client = temp }()
+	config.Client = fakeClient
+
+	res := NewResourceBuilder().ResourceNames(SpaceReclaimPV).NamespaceParam("huawei-csi").
+		BoundBackend("backend-a").Build()
+	reclaim := NewSpaceReclaim(res)
+	pvNames, err := reclaim.getReclaimablePVNames()
+	if err != nil || !reflect.DeepEqual(pvNames, []string{"pv-1", "pv-4"}) {
+		t.Fatalf("getReclaimablePVNames() = %v, error = %v, want [pv-1 pv-4]", pvNames, err)
+	}
+
+	if err = reclaim.Reclaim(time.Minute); err != nil {
+		t.Fatalf("Reclaim() error = %v", err)
+	}
+
+	if !reflect.DeepEqual(fakeClient.execPods, []string{"huawei-csi-node-a"}) || !fakeClient.deadline {
+		t.Errorf("Reclaim() executed on pods %v with deadline %v, want [huawei-csi-node-a] with deadline",
+			fakeClient.execPods, fakeClient.deadline)
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"k8s.io/utils/strings/slices"
 
@@ -114,3 +115,58 @@ func (b *ValidatorBuilder) ValidateBackend() *ValidatorBuilder {
 	}
 	return b
 }
+
+// ValidateSpaceReclaimType used to validate the object type of space reclaim. For example, the following operations
+// are illegal
+// oceanctl reclaim lun <name>
+func (b *ValidatorBuilder) ValidateSpaceReclaimType() *ValidatorBuilder {
+	for _, resourceType := range b.resource.resources {
+		if resourceType != SpaceReclaimPV {
+			b.errs = append(b.errs, fmt.Errorf("unsupported space reclaim type %s, allowed type is: %s",
+				resourceType, SpaceReclaimPV))
+		}
+	}
+	return b
+}
+
+// ValidateSpaceReclaimTarget used to validate the target of space reclaim, either the names of PersistentVolumes
+// or the backend is required, but not both. For example, the following operations are illegal
+// oceanctl reclaim pv
+// oceanctl reclaim pv <pv-name> -b <backend>
+func (b *ValidatorBuilder) ValidateSpaceReclaimTarget() *ValidatorBuilder {
+	if len(b.resource.names) == 0 && b.resource.backend == "" {
+		b.errs = append(b.errs, errors.New("either the names of PersistentVolumes or the backend is required"))
+	}
+	if len(b.resource.names) != 0 && b.resource.backend != "" {
+		b.errs = append(b.errs, errors.New("the names of PersistentVolumes and the backend can not be "+
+			"specified at the same time"))
+	}
+	return b
+}
+
+// ValidateSpaceReclaimTimeout used to validate the timeout of space reclaim, which must be positive
+func (b *ValidatorBuilder) ValidateSpaceReclaimTimeout(timeout time.Duration) *ValidatorBuilder {
+	if timeout <= 0 {
+		b.errs = append(b.errs, fmt.Errorf("the timeout %v of space reclaim must be positive", timeout))
+	}
+	return b
+}
+
+// ValidateBenchmarkOutputFormat used to validate the output format of benchmark, which supports csv in addition to
+// the common output formats. For example, the following operations are illegal
+// oceanctl benchmark --volume <pv-name> -o xml
+func (b *ValidatorBuilder) ValidateBenchmarkOutputFormat() *ValidatorBuilder {
+	if b.resource.output == BenchmarkOutputCSV {
+		return b
+	}
+	return b.ValidateOutputFormat()
+}
+
+// ValidateBenchmarkDuration used to validate the duration of benchmark is not shorter than the sample interval
+func (b *ValidatorBuilder) ValidateBenchmarkDuration(duration time.Duration) *ValidatorBuilder {
+	if duration < BenchmarkSampleInterval {
+		b.errs = append(b.errs, fmt.Errorf("the duration %s is shorter than the sample interval %s",
+			duration, BenchmarkSampleInterval))
+	}
+	return b
+}
@@ -53,6 +53,8 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&StorageBackendContentList{},
 		&ResourceTopology{},
 		&ResourceTopologyList{},
+		&VolumeMigration{},
+		&VolumeMigrationList{},
 	)
 	v1.AddToGroupVersion(scheme, SchemeGroupVersion)
 	return nil
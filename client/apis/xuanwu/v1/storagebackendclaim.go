@@ -39,6 +39,11 @@ type StorageBackendClaimSpec struct {
 	// +optional
 	Parameters map[string]string `json:"parameters,omitempty" protobuf:"bytes,8,opt,name=parameters"`
 
+	// DefaultParameters are merged into the StorageClass parameters of the volumes created on this backend,
+	// the StorageClass parameters win on conflict
+	// +optional
+	DefaultParameters map[string]string `json:"defaultParameters,omitempty" protobuf:"bytes,10,opt,name=defaultParameters"`
+
 	// UseCert is used to decide whether to use the certificate
 	// +kubebuilder:default=false
 	// +optional
@@ -70,6 +75,10 @@ type StorageBackendClaimStatus struct {
 	// +optional
 	MaxClientThreads string `json:"maxClientThreads,omitempty" protobuf:"bytes,8,opt,name=maxClientThreads"`
 
+	// DefaultParameters are the default StorageClass parameters synced to the content
+	// +optional
+	DefaultParameters map[string]string `json:"defaultParameters,omitempty" protobuf:"bytes,10,opt,name=defaultParameters"`
+
 	// BoundContentName is the binding reference
 	BoundContentName string `json:"boundContentName,omitempty" protobuf:"bytes,2,opt,name=boundContentName"`
 
@@ -87,6 +96,10 @@ type StorageBackendClaimStatus struct {
 
 	// CertSecret is the name of the secret that holds the certificate
 	CertSecret string `json:"certSecret,omitempty" protobuf:"bytes,9,opt,name=certSecret"`
+
+	// Conditions are the latest observations of the backend, such as the result of syncing its configuration
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty" protobuf:"bytes,10,rep,name=conditions"`
 }
 
 // StorageBackendPhase defines the phase of StorageBackend
@@ -101,6 +114,12 @@ const (
 	BackendUnavailable StorageBackendPhase = "Unavailable"
 )
 
+const (
+	// ConfigSyncFailed is the condition type which means the latest backend configuration
+	// failed to be applied, and the previous configuration is still in use
+	ConfigSyncFailed = "ConfigSyncFailed"
+)
+
 // +genclient
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 // +kubebuilder:object:root=true
@@ -45,6 +45,11 @@ type StorageBackendContentSpec struct {
 	// +optional
 	Parameters map[string]string `json:"parameters,omitempty" protobuf:"bytes,8,opt,name=parameters"`
 
+	// DefaultParameters are merged into the StorageClass parameters of the volumes created on this backend,
+	// the StorageClass parameters win on conflict
+	// +optional
+	DefaultParameters map[string]string `json:"defaultParameters,omitempty" protobuf:"bytes,10,opt,name=defaultParameters"`
+
 	// UseCert is used to decide whether to use the certificate
 	// +kubebuilder:default=false
 	// +optional
@@ -98,8 +103,28 @@ type StorageBackendContentStatus struct {
 
 	// CertSecret is the name of the secret that holds the certificate
 	CertSecret string `json:"certSecret,omitempty" protobuf:"bytes,9,opt,name=certSecret"`
+
+	// ReadyPools is the number of the healthy pools and all pools, format is <ready>/<total>
+	ReadyPools string `json:"readyPools,omitempty" protobuf:"bytes,10,opt,name=readyPools"`
+
+	// Conditions are the latest observations of the backend, such as whether enough pools are healthy
+	Conditions []metav1.Condition `json:"conditions,omitempty" protobuf:"bytes,11,rep,name=conditions"`
 }
 
+const (
+	// BackendReady is the condition type which means enough pools of the backend are healthy,
+	// see the min-pool-health-ratio of the driver
+	BackendReady = "Ready"
+
+	// ReadyPoolsSpecification is the key of the backend stats specifications which carries the ready pools from
+	// the provider to the sidecar, the sidecar moves it into the status instead of the specification
+	ReadyPoolsSpecification = "ReadyPools"
+	// BackendReadySpecification is the key which carries the status of the Ready condition, True or False
+	BackendReadySpecification = "BackendReady"
+	// BackendReadyMessageSpecification is the key which carries the message of the Ready condition
+	BackendReadyMessageSpecification = "BackendReadyMessage"
+)
+
 // CapacityType type for capacity
 type CapacityType string
 
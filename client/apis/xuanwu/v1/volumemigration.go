@@ -0,0 +1,118 @@
+/*
+Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+  http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+// VolumeMigrationPhase defines the VolumeMigrationPhase type
+type VolumeMigrationPhase string
+
+const (
+	// VolumeMigrationPending indicates that the migration is accepted and not started yet
+	VolumeMigrationPending VolumeMigrationPhase = "Pending"
+	// VolumeMigrationCreating indicates that the target volume is being created on the target backend
+	VolumeMigrationCreating VolumeMigrationPhase = "Creating"
+	// VolumeMigrationReplicating indicates that the data is being copied from the source to the target volume
+	VolumeMigrationReplicating VolumeMigrationPhase = "Replicating"
+	// VolumeMigrationSynced indicates that the initial copy is completed and the migration waits for cutover
+	VolumeMigrationSynced VolumeMigrationPhase = "Synced"
+	// VolumeMigrationCuttingOver indicates that the final sync is applied and the PV is switched to the target
+	VolumeMigrationCuttingOver VolumeMigrationPhase = "CuttingOver"
+	// VolumeMigrationCompleted indicates that the PV uses the target volume
+	VolumeMigrationCompleted VolumeMigrationPhase = "Completed"
+	// VolumeMigrationRollingBack indicates that the target volume and the copy are being removed
+	VolumeMigrationRollingBack VolumeMigrationPhase = "RollingBack"
+	// VolumeMigrationFailed indicates that the migration can not be started, see the message for the reason
+	VolumeMigrationFailed VolumeMigrationPhase = "Failed"
+)
+
+// VolumeMigrationSpec defines the fields in Spec
+type VolumeMigrationSpec struct {
+	// PersistentVolumeName is the name of the PV to migrate
+	// +kubebuilder:validation:Required
+	PersistentVolumeName string `json:"persistentVolumeName" protobuf:"bytes,1,name=persistentVolumeName"`
+
+	// TargetBackend is the name of the backend which the volume is migrated to
+	// +kubebuilder:validation:Required
+	TargetBackend string `json:"targetBackend" protobuf:"bytes,2,name=targetBackend"`
+
+	// TargetPool is the storage pool of the target backend, any pool of the backend is used if it is empty
+	// +kubebuilder:validation:Optional
+	TargetPool string `json:"targetPool,omitempty" protobuf:"bytes,3,opt,name=targetPool"`
+
+	// Cutover approves switching the PV to the target volume once the volume is synced,
+	// the workload using the PV must be scaled down until the migration is completed
+	// +kubebuilder:validation:Optional
+	Cutover bool `json:"cutover,omitempty" protobuf:"varint,4,opt,name=cutover"`
+}
+
+// VolumeMigrationStatus status of volume migration
+type VolumeMigrationStatus struct {
+	// Phase is the current phase of the migration
+	Phase VolumeMigrationPhase `json:"phase,omitempty" protobuf:"bytes,1,opt,name=phase"`
+
+	// Message is the detail of the current phase, such as the reason why the migration is waiting
+	Message string `json:"message,omitempty" protobuf:"bytes,2,opt,name=message"`
+
+	// SourceVolumeHandle is the volume handle of the PV before the migration
+	SourceVolumeHandle string `json:"sourceVolumeHandle,omitempty" protobuf:"bytes,3,opt,name=sourceVolumeHandle"`
+
+	// TargetVolumeHandle is the volume handle of the target volume
+	TargetVolumeHandle string `json:"targetVolumeHandle,omitempty" protobuf:"bytes,4,opt,name=targetVolumeHandle"`
+
+	// TargetVolumeAttributes is the volume context of the target volume, which replaces the one of the PV
+	TargetVolumeAttributes map[string]string `json:"targetVolumeAttributes,omitempty" protobuf:"bytes,5,opt,name=targetVolumeAttributes"`
+
+	// ReplicationPairID is the ID of the remote replication pair on the source storage
+	ReplicationPairID string `json:"replicationPairID,omitempty" protobuf:"bytes,6,opt,name=replicationPairID"`
+
+	// Progress is the progress of the current synchronization in percent
+	Progress int `json:"progress,omitempty" protobuf:"varint,7,opt,name=progress"`
+
+	// FinalSyncStarted indicates that the incremental sync after the workload is scaled down is started
+	FinalSyncStarted bool `json:"finalSyncStarted,omitempty" protobuf:"varint,8,opt,name=finalSyncStarted"`
+
+	// TargetPersistentVolume is the manifest of the PV which replaces the source PV, it is kept to resume
+	// the cutover after the source PV is deleted
+	TargetPersistentVolume string `json:"targetPersistentVolume,omitempty" protobuf:"bytes,9,opt,name=targetPersistentVolume"`
+}
+
+// VolumeMigration is the Schema for the VolumeMigrations API
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster,shortName="vmig"
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="PV",type=string,JSONPath=`.spec.persistentVolumeName`
+// +kubebuilder:printcolumn:name="TargetBackend",type=string,JSONPath=`.spec.targetBackend`
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Progress",type=integer,JSONPath=`.status.progress`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+type VolumeMigration struct {
+	metaV1.TypeMeta   `json:",inline"`
+	metaV1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              VolumeMigrationSpec   `json:"spec,omitempty"`
+	Status            VolumeMigrationStatus `json:"status,omitempty"`
+}
+
+// VolumeMigrationList contains a list of VolumeMigration
+// +kubebuilder:object:root=true
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type VolumeMigrationList struct {
+	metaV1.TypeMeta `json:",inline"`
+	metaV1.ListMeta `json:"metadata,omitempty"`
+	Items           []VolumeMigration `json:"items"`
+}
@@ -19,6 +19,7 @@
 package v1
 
 import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -174,7 +175,7 @@ func (in *StorageBackendClaim) DeepCopyInto(out *StorageBackendClaim) {
 	if in.Status != nil {
 		in, out := &in.Status, &out.Status
 		*out = new(StorageBackendClaimStatus)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 	return
 }
@@ -240,6 +241,13 @@ func (in *StorageBackendClaimSpec) DeepCopyInto(out *StorageBackendClaimSpec) {
 			(*out)[key] = val
 		}
 	}
+	if in.DefaultParameters != nil {
+		in, out := &in.DefaultParameters, &out.DefaultParameters
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 	return
 }
 
@@ -256,6 +264,20 @@ func (in *StorageBackendClaimSpec) DeepCopy() *StorageBackendClaimSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *StorageBackendClaimStatus) DeepCopyInto(out *StorageBackendClaimStatus) {
 	*out = *in
+	if in.DefaultParameters != nil {
+		in, out := &in.DefaultParameters, &out.DefaultParameters
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -344,6 +366,13 @@ func (in *StorageBackendContentSpec) DeepCopyInto(out *StorageBackendContentSpec
 			(*out)[key] = val
 		}
 	}
+	if in.DefaultParameters != nil {
+		in, out := &in.DefaultParameters, &out.DefaultParameters
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 	return
 }
 
@@ -388,6 +417,13 @@ func (in *StorageBackendContentStatus) DeepCopyInto(out *StorageBackendContentSt
 			(*out)[key] = val
 		}
 	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -418,3 +454,103 @@ func (in *Tag) DeepCopy() *Tag {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VolumeMigration) DeepCopyInto(out *VolumeMigration) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeMigration.
+func (in *VolumeMigration) DeepCopy() *VolumeMigration {
+	if in == nil {
+		return nil
+	}
+	out := new(VolumeMigration)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *VolumeMigration) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VolumeMigrationList) DeepCopyInto(out *VolumeMigrationList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]VolumeMigration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeMigrationList.
+func (in *VolumeMigrationList) DeepCopy() *VolumeMigrationList {
+	if in == nil {
+		return nil
+	}
+	out := new(VolumeMigrationList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *VolumeMigrationList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VolumeMigrationSpec) DeepCopyInto(out *VolumeMigrationSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeMigrationSpec.
+func (in *VolumeMigrationSpec) DeepCopy() *VolumeMigrationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(VolumeMigrationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VolumeMigrationStatus) DeepCopyInto(out *VolumeMigrationStatus) {
+	*out = *in
+	if in.TargetVolumeAttributes != nil {
+		in, out := &in.TargetVolumeAttributes, &out.TargetVolumeAttributes
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeMigrationStatus.
+func (in *VolumeMigrationStatus) DeepCopy() *VolumeMigrationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(VolumeMigrationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -25,6 +25,7 @@ import (
 
 	"github.com/sirupsen/logrus"
 	"k8s.io/api/core/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/scheme"
@@ -64,13 +65,12 @@ func main() {
 	}
 
 	ctx := context.Background()
-	k8sClient, storageBackendClient, err := utils.GetK8SAndSBCClient(ctx)
+	kubeClient, storageBackendClient, err := utils.GetK8SAndSBCClient(ctx)
 	if err != nil {
 		return
 	}
-
 	// start the webhook
-	recorder := initRecorder(k8sClient)
+	recorder := initRecorder(kubeClient)
 	webHook := initWebhookController(recorder)
 	webHookCfg, admissionWebhooks := webhook.GetStorageWebHookCfg()
 	if err = webHook.Start(ctx, webHookCfg, admissionWebhooks); err != nil {
@@ -81,7 +81,7 @@ func main() {
 	signalChan := make(chan os.Signal, 1)
 	defer close(signalChan)
 
-	startWithLeaderElectionOnCondition(ctx, k8sClient, storageBackendClient, recorder, signalChan)
+	startWithLeaderElectionOnCondition(ctx, kubeClient, storageBackendClient, recorder, signalChan)
 
 	signal.Notify(signalChan, syscall.SIGINT, syscall.SIGILL, syscall.SIGKILL, syscall.SIGTERM)
 	stopSignal := <-signalChan
@@ -102,6 +102,7 @@ func initRecorder(client kubernetes.Interface) record.EventRecorder {
 
 func runController(
 	ctx context.Context,
+	k8sClient kubernetes.Interface,
 	storageBackendClient *clientSet.Clientset,
 	eventRecorder record.EventRecorder, ch chan os.Signal) {
 
@@ -123,6 +124,8 @@ func runController(
 		return
 	}
 
+	utils.AuditRBACPermissions(ctx, k8sClient, eventRecorder, requiredPermissions())
+
 	factory := backendInformers.NewSharedInformerFactory(storageBackendClient, app.GetGlobalConfig().ReSyncPeriod)
 	ctrl := controller.NewBackendController(controller.BackendControllerRequest{
 		ClientSet:       storageBackendClient,
@@ -146,6 +149,18 @@ func runController(
 	run(ctx)
 }
 
+func requiredPermissions() []utils.ResourcePermission {
+	var permissions []utils.ResourcePermission
+	for _, verb := range []string{"list", "watch", "update"} {
+		permissions = append(permissions, utils.ResourcePermission{
+			Group: "xuanwu.huawei.io", Resource: "storagebackendcontents", Verb: verb})
+	}
+
+	return append(permissions,
+		utils.ResourcePermission{Resource: "secrets", Verb: "get", Namespace: app.GetGlobalConfig().Namespace},
+		utils.ResourcePermission{Resource: "events", Verb: "create", Namespace: metaV1.NamespaceDefault})
+}
+
 func ensureCRDExist(ctx context.Context, client *clientSet.Clientset) error {
 	exist := func() (bool, error) {
 		_, err := utils.ListClaim(ctx, client, "")
@@ -179,16 +194,22 @@ func startWithLeaderElectionOnCondition(ctx context.Context, k8sClient *kubernet
 	storageBackendClient *clientSet.Clientset, recorder record.EventRecorder, ch chan os.Signal) {
 	if !app.GetGlobalConfig().EnableLeaderElection {
 		log.AddContext(ctx).Infoln("Start controller without leader election.")
-		go runController(ctx, storageBackendClient, recorder, ch)
+		go runController(ctx, k8sClient, storageBackendClient, recorder, ch)
 	} else {
 		leaderElection := utils.LeaderElectionConf{
 			LeaderName:    leaderLockObjectName,
 			LeaseDuration: app.GetGlobalConfig().LeaderLeaseDuration,
 			RenewDeadline: app.GetGlobalConfig().LeaderRenewDeadline,
 			RetryPeriod:   app.GetGlobalConfig().LeaderRetryPeriod,
+			LockType:      app.GetGlobalConfig().LeaderLockType,
+			LockNamespace: app.GetGlobalConfig().LeaderLockNamespace,
+		}
+		run := func(ctx context.Context, storageBackendClient *clientSet.Clientset,
+			recorder record.EventRecorder, ch chan os.Signal) {
+			runController(ctx, k8sClient, storageBackendClient, recorder, ch)
 		}
 		go utils.RunWithLeaderElection(ctx, leaderElection,
 			k8sClient, storageBackendClient, recorder,
-			runController, ch)
+			run, ch)
 	}
 }
@@ -20,12 +20,14 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"sync/atomic"
 	"syscall"
 	"time"
 
 	"github.com/kubernetes-csi/csi-lib-utils/metrics"
 	"github.com/sirupsen/logrus"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/keepalive"
 	"k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/kubernetes"
@@ -42,6 +44,7 @@ import (
 	"huawei-csi-driver/pkg/sidecar/controller"
 	storageBackend "huawei-csi-driver/pkg/storage-backend/handle"
 	"huawei-csi-driver/pkg/utils"
+	"huawei-csi-driver/utils/health"
 	"huawei-csi-driver/utils/log"
 )
 
@@ -55,6 +58,10 @@ const (
 var (
 	connect      *grpc.ClientConn
 	providerName string
+
+	// controllerStarted is set when the sidecar becomes the leader and starts the controllers
+	controllerStarted int32
+	informerSynced    = health.NewGate("informer sync")
 )
 
 func main() {
@@ -84,6 +91,7 @@ func main() {
 	// init the recorder
 	recorder := initRecorder(k8sClient)
 	connect, providerName = initProvider()
+	startHealthServer(ctx)
 
 	signalChan := make(chan os.Signal, 1)
 	defer close(signalChan)
@@ -96,6 +104,8 @@ func main() {
 			LeaseDuration: app.GetGlobalConfig().LeaderLeaseDuration,
 			RenewDeadline: app.GetGlobalConfig().LeaderRenewDeadline,
 			RetryPeriod:   app.GetGlobalConfig().LeaderRetryPeriod,
+			LockType:      app.GetGlobalConfig().LeaderLockType,
+			LockNamespace: app.GetGlobalConfig().LeaderLockNamespace,
 		}
 		go utils.RunWithLeaderElection(ctx, leaderElection, k8sClient, storageBackendClient, recorder,
 			runController, signalChan)
@@ -147,11 +157,28 @@ func runController(
 		ReSyncPeriod:    time.Second * time.Duration(app.GetGlobalConfig().BackendUpdateInterval),
 		EventRecorder:   eventRecorder})
 
+	dynamicClient, err := utils.GetDynamicClient(ctx)
+	if err != nil {
+		log.AddContext(ctx).Errorf("Get dynamic client failed, error: %v", err)
+		ch <- syscall.SIGINT
+		return
+	}
+
+	snapshotTTLCtrl := controller.NewSnapshotTTLController(controller.SnapshotTTLControllerRequest{
+		ProviderName:    providerName,
+		DynamicClient:   dynamicClient,
+		DiscoveryClient: storageBackendClient.Discovery(),
+		TimeOut:         app.GetGlobalConfig().Timeout,
+	})
+
 	run := func(ctx context.Context) {
 		// run...
 		stopCh := make(chan struct{})
+		atomic.StoreInt32(&controllerStarted, 1)
 		factory.Start(stopCh)
+		go waitInformerSynced(factory, stopCh)
 		go ctrl.Run(ctx, app.GetGlobalConfig().WorkerThreads, stopCh)
+		go snapshotTTLCtrl.Run(ctx, app.GetGlobalConfig().WorkerThreads, stopCh)
 
 		// Stop the controller when stop signals are received
 		utils.WaitExitSignal(ctx, "controller")
@@ -167,7 +194,13 @@ func initProvider() (*grpc.ClientConn, string) {
 	defer cancel()
 
 	metricsManager := metrics.NewCSIMetricsManager("" /* driverName */)
-	conn, err := connection.Connect(ctx, app.GetGlobalConfig().DrEndpoint, metricsManager)
+	keepaliveParams := keepalive.ClientParameters{
+		Time:                app.GetGlobalConfig().ProviderKeepaliveTime,
+		Timeout:             app.GetGlobalConfig().ProviderKeepaliveTimeout,
+		PermitWithoutStream: true,
+	}
+	conn, err := connection.Connect(ctx, app.GetGlobalConfig().DrEndpoint, metricsManager,
+		grpc.WithKeepaliveParams(keepaliveParams))
 	if err != nil {
 		log.AddContext(ctx).Fatalf("Failed to connect to DR CSI provider: %v", err)
 	}
@@ -181,6 +214,49 @@ func initProvider() (*grpc.ClientConn, string) {
 	return conn, name
 }
 
+// startHealthServer serves the liveness probe which checks whether the DR-CSI provider is reachable,
+// and the readiness probe which checks whether the informers are synced
+func startHealthServer(ctx context.Context) {
+	address := app.GetGlobalConfig().HealthAddress
+	if address == "" {
+		return
+	}
+
+	server := health.NewServer()
+	server.AddLivenessCheck("provider", checkProviderHealth)
+	server.AddReadinessCheck("informer", checkInformerSynced)
+	go server.Serve(ctx, address)
+}
+
+func checkProviderHealth(ctx context.Context) error {
+	if _, err := rpc.GetProviderName(ctx, connect); err != nil {
+		return fmt.Errorf("DR-CSI provider is unreachable, connection state: %s, error: %v",
+			connect.GetState(), err)
+	}
+
+	return nil
+}
+
+func checkInformerSynced(ctx context.Context) error {
+	// the standby sidecar does not run the informers until it becomes the leader
+	if atomic.LoadInt32(&controllerStarted) == 0 {
+		return nil
+	}
+
+	return informerSynced.Check(ctx)
+}
+
+func waitInformerSynced(factory backendInformers.SharedInformerFactory, stopCh <-chan struct{}) {
+	for informer, synced := range factory.WaitForCacheSync(stopCh) {
+		if !synced {
+			log.Warningf("Informer of %v is not synced before stopping", informer)
+			return
+		}
+	}
+
+	informerSynced.Open()
+}
+
 func ensureCRDExist(ctx context.Context, client *clientSet.Clientset) error {
 	exist := func() (bool, error) {
 		_, err := utils.ListContent(ctx, client)
@@ -68,6 +68,8 @@ const (
 	HWUltraPath = "HW-UltraPath"
 	// HWUltraPathNVMe HW-UltraPath-NVMe name string
 	HWUltraPathNVMe = "HW-UltraPath-NVMe"
+	// NativeNVMe is the native nvme multipath of the kernel
+	NativeNVMe = "native"
 	// UnsupportedMultiPathType multi-path type not supported
 	UnsupportedMultiPathType = "UnsupportedMultiPathType"
 
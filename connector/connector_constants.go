@@ -26,4 +26,6 @@ const (
 	Extend = "extend"
 	// VolumeDeviceNotFound field is used for find no volume device
 	VolumeDeviceNotFound = "volume device not found"
+	// LastSuccessfulPortal field is the iSCSI portal which the volume was connected by last time
+	LastSuccessfulPortal = "lastSuccessfulPortal"
 )
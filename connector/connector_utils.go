@@ -450,7 +450,7 @@ func getSessionIdByDevice(devPath string) (string, error) {
 // WatchDMDevice is an aggregate drive letter monitor.
 func WatchDMDevice(ctx context.Context, lunWWN string, expectPathNumber int) (DMDeviceInfo, error) {
 	log.AddContext(ctx).Infof("Watch DM Disk Generation. lunWWN: %s,expectPathNumber: %d", lunWWN, expectPathNumber)
-	var timeout = time.After(time.Second * time.Duration(app.GetGlobalConfig().ScanVolumeTimeout))
+	var timeout = time.After(GetScanVolumeTimeout(ctx))
 	var dm DMDeviceInfo
 	var err = errors.New(VolumeNotFound)
 	for {
@@ -1259,7 +1259,7 @@ var IsDeviceAvailable = func(ctx context.Context, device, lunWWN string) (bool,
 
 // DisConnectVolume delete all devices which match to lunWWN
 func DisConnectVolume(ctx context.Context, tgtLunWWN string, f func(context.Context, string) error) error {
-	return utils.WaitUntil(func() (bool, error) {
+	err := utils.WaitUntil(func() (bool, error) {
 		err := f(ctx, tgtLunWWN)
 		if err != nil {
 			if err.Error() == "FindNoDevice" {
@@ -1269,6 +1269,13 @@ func DisConnectVolume(ctx context.Context, tgtLunWWN string, f func(context.Cont
 		}
 		return false, nil
 	}, DisconnectVolumeTimeOut, DisconnectVolumeTimeInterval)
+
+	// the maps of the paths removed by a partially failed disconnection are not flushed
+	if cleanupOrphanedMultipathMapsEnabled() {
+		CleanupOrphanedMultipathMaps(ctx, tgtLunWWN)
+	}
+
+	return err
 }
 
 // CheckConnectSuccess is to check the sd device available
@@ -1329,7 +1336,7 @@ func VerifyDeviceAvailableOfDM(ctx context.Context, tgtLunWWN string, expectPath
 	start := time.Now()
 	dm, err := WatchDMDevice(ctx, tgtLunWWN, expectPathNumber)
 	log.AddContext(ctx).Infof("WatchDMDevice-%s:%-36s%-8d%-20s%v",
-		time.Second*time.Duration(app.GetGlobalConfig().ScanVolumeTimeout),
+		GetScanVolumeTimeout(ctx),
 		tgtLunWWN, expectPathNumber, time.Now().Sub(start), err)
 	if err == nil {
 		var dev string
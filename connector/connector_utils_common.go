@@ -46,6 +46,10 @@ var GetPhysicalDevices = func(ctx context.Context, device string, deviceType int
 var GetNVMePhysicalDevices = func(ctx context.Context, device string, deviceType int) ([]string, error) {
 	switch deviceType {
 	case NotUseMultipath:
+		// the multipath device of the native nvme multipath is not distinguished from a single device
+		if pathDevices := getNativeNVMePathDevices(device); len(pathDevices) != 0 {
+			return pathDevices, nil
+		}
 		return []string{device}, nil
 	case UseUltraPathNVMe:
 		return GetNVMeDeviceFromUltraPathNVMe(ctx, device)
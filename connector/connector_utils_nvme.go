@@ -32,6 +32,16 @@ import (
 	"huawei-csi-driver/utils/log"
 )
 
+const nativeNVMeMultipathParam = "/sys/module/nvme_core/parameters/multipath"
+
+var (
+	// sysBlockPath is the sysfs directory of the block devices
+	sysBlockPath = "/sys/block"
+
+	nativeNVMeHeadDevice = regexp.MustCompile(`^nvme[0-9]+n[0-9]+$`)
+	nativeNVMePathDevice = regexp.MustCompile(`^nvme[0-9]+c([0-9]+)n[0-9]+$`)
+)
+
 // DoScanNVMeDevice used to scan device by command nvme ns-rescan
 var DoScanNVMeDevice = func(ctx context.Context, devicePort string) error {
 	output, err := utils.ExecShellCmd(ctx, "nvme ns-rescan /dev/%s", devicePort)
@@ -151,3 +161,76 @@ func getNVMeWWN(ctx context.Context, devicePort, device string) (string, error)
 
 	return "", errors.New("uuid is not exist")
 }
+
+// VerifyNativeNVMeMultipath checks whether the native nvme multipath of the kernel is enabled on the node
+func VerifyNativeNVMeMultipath(ctx context.Context) error {
+	data, err := ioutil.ReadFile(nativeNVMeMultipathParam)
+	if err != nil {
+		return utils.Errorf(ctx, "Read %s failed, the nvme_core module may not be loaded. error: %v",
+			nativeNVMeMultipathParam, err)
+	}
+
+	if strings.TrimSpace(string(data)) != "Y" {
+		return utils.Errorf(ctx, "The native nvme multipath is disabled, %s is %s, please set the kernel "+
+			"parameter nvme_core.multipath=Y", nativeNVMeMultipathParam, strings.TrimSpace(string(data)))
+	}
+
+	return nil
+}
+
+// GetNativeNVMeDevice gets the multipath device of the native nvme multipath by the lun GUID,
+// the empty name is returned if the device is not found
+var GetNativeNVMeDevice = func(ctx context.Context, tgtLunGUID string) (string, error) {
+	entries, err := ioutil.ReadDir(sysBlockPath)
+	if err != nil {
+		return "", utils.Errorf(ctx, "Read dir %s failed. error: %v", sysBlockPath, err)
+	}
+
+	for _, entry := range entries {
+		if !nativeNVMeHeadDevice.MatchString(entry.Name()) {
+			continue
+		}
+
+		wwidFile := path.Join(sysBlockPath, entry.Name(), "wwid")
+		data, err := ioutil.ReadFile(wwidFile)
+		if err != nil {
+			log.AddContext(ctx).Warningf("Read NVMe wwid file:%s failed. error:%v", wwidFile, err)
+			continue
+		}
+
+		if strings.Contains(string(data), tgtLunGUID) {
+			return entry.Name(), nil
+		}
+	}
+
+	return "", nil
+}
+
+// getNativeNVMePathDevices gets the path devices of the multipath device of the native nvme multipath,
+// such as nvme0c1n1 of nvme0n1, nil is returned if the device is not a native nvme multipath device
+func getNativeNVMePathDevices(device string) []string {
+	entries, err := ioutil.ReadDir(path.Join(sysBlockPath, device, "multipath"))
+	if err != nil {
+		return nil
+	}
+
+	var pathDevices []string
+	for _, entry := range entries {
+		if nativeNVMePathDevice.MatchString(entry.Name()) {
+			pathDevices = append(pathDevices, entry.Name())
+		}
+	}
+
+	return pathDevices
+}
+
+// GetNativeNVMePathController gets the controller of the path device of the native nvme multipath,
+// such as nvme1 of nvme0c1n1, the empty name is returned if the device is not a path device
+func GetNativeNVMePathController(device string) string {
+	match := nativeNVMePathDevice.FindStringSubmatch(device)
+	if len(match) != 2 {
+		return ""
+	}
+
+	return "nvme" + match[1]
+}
@@ -0,0 +1,72 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package connector
+
+import (
+	"context"
+	"os"
+	"path"
+	"testing"
+)
+
+func TestGetNativeNVMeDevice(t *testing.T) {
+	root := t.TempDir()
+	sysBlockPath = root
+	defer func() { sysBlockPath = "/sys/block" }()
+
+	devices := map[string]string{
+		"nvme0n1":   "uuid.guid-1",
+		"nvme0c1n1": "uuid.guid-2",
+		"nvme1n1":   "uuid.guid-2",
+	}
+	for device, wwid := range devices {
+		if err := os.MkdirAll(path.Join(root, device), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path.Join(root, device, "wwid"), []byte(wwid+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, pathDevice := range []string{"nvme0c1n1", "nvme0c2n1"} {
+		if err := os.MkdirAll(path.Join(root, "nvme1n1", "multipath", pathDevice), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cases := []struct {
+		name string
+		guid string
+		want string
+	}{
+		{"HeadDevice", "guid-1", "nvme0n1"},
+		{"PathDeviceSkipped", "guid-2", "nvme1n1"},
+		{"NotFound", "guid-3", ""},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := GetNativeNVMeDevice(context.Background(), c.guid)
+			if err != nil || got != c.want {
+				t.Errorf("GetNativeNVMeDevice() = %v, %v, want %v", got, err, c.want)
+			}
+		})
+	}
+
+	paths, err := GetNVMePhysicalDevices(context.Background(), "nvme1n1", NotUseMultipath)
+	if err != nil || len(paths) != 2 || GetNativeNVMePathController(paths[1]) != "nvme2" {
+		t.Errorf("GetNVMePhysicalDevices() = %v, %v, want the path devices of nvme1n1", paths, err)
+	}
+}
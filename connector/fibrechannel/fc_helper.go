@@ -29,6 +29,7 @@ import (
 
 	"huawei-csi-driver/connector"
 	connutils "huawei-csi-driver/connector/utils"
+	"huawei-csi-driver/csi/app"
 	"huawei-csi-driver/utils"
 	"huawei-csi-driver/utils/log"
 )
@@ -138,6 +139,10 @@ func tryConnectVolume(ctx context.Context, connMap map[string]interface{}) (stri
 		return "", errors.New(connector.VolumeNotFound)
 	}
 
+	if conn.volumeUseMultiPath && conn.multiPathType == connector.DMMultiPath {
+		waitPathDiscovery(ctx, hbas, hostDevice, conn)
+	}
+
 	return checkPathAvailable(ctx, *conn, devInfo)
 }
 
@@ -460,6 +465,58 @@ func waitDeviceDiscovery(ctx context.Context,
 	return info, err
 }
 
+// waitPathDiscovery waits for the paths which are not discovered yet, the new paths after the zone changes
+// do not appear until the fabric discovery of the HBA is completed. The found paths are used after the timeout.
+func waitPathDiscovery(ctx context.Context, hbas []map[string]string, hostDevices []string, conn *connectorInfo) {
+	timeout := time.Second * time.Duration(app.GetGlobalConfig().FCPathDiscoveryTimeout)
+	interval := time.Second * time.Duration(app.GetGlobalConfig().FCPathDiscoveryInterval)
+
+	found := countDiscoveredPaths(ctx, hostDevices)
+	if found >= conn.pathCount || timeout <= 0 {
+		return
+	}
+
+	log.AddContext(ctx).Infof("Found %d of %d paths of volume %s, wait for the path discovery",
+		found, conn.pathCount, conn.tgtLunWWN)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	deadline := time.After(timeout)
+	for {
+		select {
+		case <-deadline:
+			log.AddContext(ctx).Warningf("Only %d of %d paths of volume %s are found after %v",
+				found, conn.pathCount, conn.tgtLunWWN, timeout)
+			return
+		case <-ticker.C:
+			rescanSCSIBus(ctx)
+			rescanHosts(ctx, hbas, conn)
+			found = countDiscoveredPaths(ctx, hostDevices)
+			if found >= conn.pathCount {
+				log.AddContext(ctx).Infof("All %d paths of volume %s are found", found, conn.tgtLunWWN)
+				return
+			}
+		}
+	}
+}
+
+func countDiscoveredPaths(ctx context.Context, hostDevices []string) int {
+	var count int
+	for _, dev := range hostDevices {
+		if exist, _ := utils.PathExist(dev); exist && checkValidDevice(ctx, dev) {
+			count++
+		}
+	}
+
+	return count
+}
+
+func rescanSCSIBus(ctx context.Context) {
+	output, err := utils.ExecShellCmd(ctx, "rescan-scsi-bus.sh")
+	if err != nil {
+		log.AddContext(ctx).Warningf("rescan scsi bus error: %s", output)
+	}
+}
+
 func getHBAChannelSCSITargetLun(ctx context.Context, hba map[string]string, targets []target) ([][]string, []string) {
 	hostDevice := hba["host_device"]
 	if hostDevice != "" && len(hostDevice) > 4 {
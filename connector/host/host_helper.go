@@ -29,6 +29,7 @@ import (
 	apiErrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	"huawei-csi-driver/connector"
 	"huawei-csi-driver/csi/app"
 	"huawei-csi-driver/proto"
 	"huawei-csi-driver/utils"
@@ -51,6 +52,14 @@ type NodeHostInfo struct {
 	FCInitiators []string `json:"fCInitiators"`
 	// RoCEInitiator the initiator of RoCE protocol
 	RoCEInitiator string `json:"roCEInitiator"`
+	// NodeName the name of the kubernetes node of the host
+	NodeName string `json:"nodeName,omitempty"`
+	// NvmeMultiPathType the nvme multipath type configured on the host
+	NvmeMultiPathType string `json:"nvmeMultiPathType,omitempty"`
+	// KernelVersion the kernel version of the host
+	KernelVersion string `json:"kernelVersion,omitempty"`
+	// UltraPathVersion the version of the UltraPath installed on the host, only queried for UltraPath-NVMe
+	UltraPathVersion string `json:"ultraPathVersion,omitempty"`
 }
 
 // NewNodeHostInfo instantiates this node host info.
@@ -78,12 +87,41 @@ func NewNodeHostInfo(ctx context.Context) (*NodeHostInfo, error) {
 		log.AddContext(ctx).Infof("get RoCE initiator error: [%v]", err)
 	}
 
-	return &NodeHostInfo{
-		HostName:       strings.Trim(hostName, " "),
-		IscsiInitiator: iscsiInitiator,
-		FCInitiators:   fcInitiators,
-		RoCEInitiator:  roCEInitiator,
-	}, nil
+	hostInfo := &NodeHostInfo{
+		HostName:          strings.Trim(hostName, " "),
+		IscsiInitiator:    iscsiInitiator,
+		FCInitiators:      fcInitiators,
+		RoCEInitiator:     roCEInitiator,
+		NodeName:          app.GetGlobalConfig().NodeName,
+		NvmeMultiPathType: app.GetGlobalConfig().NvmeMultiPathType,
+	}
+	setMultiPathVersions(ctx, hostInfo)
+	return hostInfo, nil
+}
+
+// setMultiPathVersions sets the kernel and UltraPath versions, which the controller checks before expanding
+// the attached volumes online
+func setMultiPathVersions(ctx context.Context, hostInfo *NodeHostInfo) {
+	kernelVersion, err := utils.ExecShellCmd(ctx, "uname -r")
+	if err != nil {
+		log.AddContext(ctx).Infof("get kernel version error: [%v]", err)
+		return
+	}
+	hostInfo.KernelVersion = strings.TrimSpace(kernelVersion)
+
+	if hostInfo.NvmeMultiPathType != connector.HWUltraPathNVMe {
+		return
+	}
+
+	output, err := utils.ExecShellCmd(ctx, "upadmin show version | grep -w 'Software Version'")
+	if err != nil {
+		log.AddContext(ctx).Infof("get UltraPath version error: [%v]", err)
+		return
+	}
+
+	if _, version, found := strings.Cut(output, ":"); found {
+		hostInfo.UltraPathVersion = strings.TrimSpace(version)
+	}
 }
 
 // SaveNodeHostInfoToSecret save the current node host information to secret.
@@ -163,6 +201,29 @@ func GetNodeHostInfosFromSecret(ctx context.Context, hostName string) (*NodeHost
 	return nil, nil
 }
 
+// GetNodeHostInfoByNodeName get the host information of the kubernetes node from secret,
+// nil is returned if the node has not saved its information
+func GetNodeHostInfoByNodeName(ctx context.Context, nodeName string) (*NodeHostInfo, error) {
+	k8sUtils := app.GetGlobalConfig().K8sUtils
+	secret, err := k8sUtils.GetSecret(ctx, hostInfoSecretName, app.GetGlobalConfig().Namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	for hostName, secretData := range secret.Data {
+		hostInfo := &NodeHostInfo{}
+		if err = json.Unmarshal(secretData, hostInfo); err != nil {
+			log.AddContext(ctx).Warningf("json unmarshal host info of %s error: %v", hostName, err)
+			continue
+		}
+
+		if hostInfo.NodeName == nodeName {
+			return hostInfo, nil
+		}
+	}
+	return nil, nil
+}
+
 // makeNodeHostInfoSecret make node host info secret
 func makeNodeHostInfoSecret() *corev1.Secret {
 	return &corev1.Secret{
@@ -64,10 +64,19 @@ func TestMain(m *testing.M) {
 }
 func TestNewNodeHostInfo(t *testing.T) {
 	want := &NodeHostInfo{
-		HostName:       "test_hostname",
-		IscsiInitiator: "test_iscsi_initiator",
-		RoCEInitiator:  "test_roce_initiator",
-		FCInitiators:   nil,
+		HostName:          "test_hostname",
+		IscsiInitiator:    "test_iscsi_initiator",
+		RoCEInitiator:     "test_roce_initiator",
+		FCInitiators:      nil,
+		NodeName:          app.GetGlobalConfig().NodeName,
+		NvmeMultiPathType: app.GetGlobalConfig().NvmeMultiPathType,
+		KernelVersion:     "4.18.0-80.el8.x86_64",
+		UltraPathVersion:  "31.2.0",
+	}
+	commandOutputs := map[string]string{
+		"hostname | xargs echo -n": want.HostName,
+		"uname -r":                 want.KernelVersion + "\n",
+		"upadmin show version | grep -w 'Software Version'": "Software Version   : " + want.UltraPathVersion + "\n",
 	}
 	getISCSIInitiator := gomonkey.ApplyFunc(proto.GetISCSIInitiator, func(_ context.Context) (string, error) {
 		return want.IscsiInitiator, errors.New("no iscsi initiator")
@@ -85,7 +94,10 @@ func TestNewNodeHostInfo(t *testing.T) {
 	defer getRoCEInitiator.Reset()
 
 	convey.Convey("TestNewNodeHostInfoSuccessful", t, func() {
-		execShellCmd := gostub.StubFunc(&utils.ExecShellCmd, want.HostName, nil)
+		execShellCmd := gostub.Stub(&utils.ExecShellCmd,
+			func(_ context.Context, format string, _ ...interface{}) (string, error) {
+				return commandOutputs[format], nil
+			})
 		defer execShellCmd.Reset()
 		nodeHostInfo, err := NewNodeHostInfo(context.Background())
 		if !reflect.DeepEqual(nodeHostInfo, want) {
@@ -308,6 +320,31 @@ func TestGetNodeHostInfosFromSecret(t *testing.T) {
 	})
 }
 
+func TestGetNodeHostInfoByNodeName(t *testing.T) {
+	nodeInfo := *testNodeInfo
+	nodeInfo.NodeName = "test_node"
+	secretData, err := json.Marshal(&nodeInfo)
+	if err != nil {
+		t.Errorf("TestGetNodeHostInfoByNodeName() json marshal %v", err)
+	}
+	getSecret := gomonkey.ApplyMethod(reflect.TypeOf(testK8sUtils), "GetSecret",
+		func(_ *k8sutils.KubeClient, ctx context.Context, secretName, namespace string) (*corev1.Secret, error) {
+			return &corev1.Secret{Data: map[string][]byte{nodeInfo.HostName: secretData}}, nil
+		})
+	defer getSecret.Reset()
+
+	convey.Convey("TestGetNodeHostInfoByNodeNameSuccess", t, func() {
+		hostInfo, err := GetNodeHostInfoByNodeName(context.Background(), "test_node")
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(hostInfo, convey.ShouldResemble, &nodeInfo)
+	})
+	convey.Convey("TestGetNodeHostInfoByNodeNameNotSaved", t, func() {
+		hostInfo, err := GetNodeHostInfoByNodeName(context.Background(), "other_node")
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(hostInfo, convey.ShouldBeNil)
+	})
+}
+
 func TestMakeNodeHostInfoSecret(t *testing.T) {
 	want := &corev1.Secret{
 		TypeMeta: metav1.TypeMeta{
@@ -20,4 +20,6 @@ package iscsi
 const (
 	lengthOfHCTL                  = 4
 	deviceScanAttemptsDefault int = 3
+
+	sessionStateLoggedIn = "LOGGED_IN"
 )
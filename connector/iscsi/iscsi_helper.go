@@ -56,9 +56,10 @@ type connectorInfo struct {
 	tgtIQNs     []string
 	tgtHostLUNs []string
 
-	tgtChapInfo        chapInfo
-	volumeUseMultiPath bool
-	multiPathType      string
+	tgtChapInfo          chapInfo
+	volumeUseMultiPath   bool
+	multiPathType        string
+	lastSuccessfulPortal string
 }
 
 type singleConnectorInfo struct {
@@ -74,6 +75,7 @@ type shareData struct {
 	stoppedThreads   int64
 	foundDevices     []string
 	justAddedDevices []string
+	foundPortals     []string
 }
 
 type scanRequest struct {
@@ -130,6 +132,7 @@ func parseISCSIInfo(ctx context.Context,
 		log.AddContext(ctx).Infoln("key authMethod does not exist in connectionProperties")
 	}
 
+	info.lastSuccessfulPortal, _ = connectionProperties[connector.LastSuccessfulPortal].(string)
 	info.volumeUseMultiPath, info.multiPathType, err = connutils.GetMultiPathInfo(connectionProperties)
 
 	return info, err
@@ -254,6 +257,28 @@ func getAllISCSISession(ctx context.Context) [][]string {
 	return iSCSIInfo
 }
 
+// getLoggedInSession returns the existing session of the portal if it is still logged in, so that the
+// rediscovery is skipped when the volume is staged again
+func getLoggedInSession(ctx context.Context, tgtPortal, targetIQN string) string {
+	for _, s := range getAllISCSISession(ctx) {
+		if s[0] != "tcp:" || !strings.EqualFold(tgtPortal, s[2]) || targetIQN != s[4] {
+			continue
+		}
+
+		state, err := ioutil.ReadFile(fmt.Sprintf("/sys/class/iscsi_session/session%s/state", s[1]))
+		if err == nil && strings.TrimSpace(string(state)) == sessionStateLoggedIn {
+			log.AddContext(ctx).Infof("Reuse the logged in iSCSI session %s of portal %s", s[1], tgtPortal)
+			return s[1]
+		}
+
+		log.AddContext(ctx).Warningf("iSCSI session %s of portal %s is not logged in, state: %s, error: %v",
+			s[1], tgtPortal, strings.TrimSpace(string(state)), err)
+		return ""
+	}
+
+	return ""
+}
+
 func singleConnectISCSIPortal(ctx context.Context, tgtPortal, targetIQN string, tgtChapInfo chapInfo) (string, bool) {
 	key := fmt.Sprintf("%s::%s", tgtPortal, targetIQN)
 	res, _ := singleGroup.Do(key, func() (connectResult, error) {
@@ -444,8 +469,16 @@ func connectVol(ctx context.Context,
 	conn connectorInfo,
 	iSCSIShareData *shareData) {
 	var device string
+	var session string
+	var manualScan bool
+	if tgt.tgtPortal == conn.lastSuccessfulPortal {
+		session = getLoggedInSession(ctx, tgt.tgtPortal, tgt.tgtIQN)
+	}
+
+	if session == "" {
+		session, manualScan = singleConnectISCSIPortal(ctx, tgt.tgtPortal, tgt.tgtIQN, conn.tgtChapInfo)
+	}
 
-	session, manualScan := singleConnectISCSIPortal(ctx, tgt.tgtPortal, tgt.tgtIQN, conn.tgtChapInfo)
 	if session != "" {
 		var numRescans, secondNextScan int
 		var hostChannelTargetLun []string
@@ -472,6 +505,7 @@ func connectVol(ctx context.Context,
 		} else {
 			iSCSIShareData.foundDevices = append(iSCSIShareData.foundDevices, device)
 			iSCSIShareData.justAddedDevices = append(iSCSIShareData.justAddedDevices, device)
+			iSCSIShareData.foundPortals = append(iSCSIShareData.foundPortals, tgt.tgtPortal)
 		}
 	} else {
 		log.AddContext(ctx).Warningf("build iSCSI session %s error", tgt.tgtPortal)
@@ -507,28 +541,63 @@ func constructISCSIInfo(ctx context.Context, conn connectorInfo) []singleConnect
 	return iSCSIInfoList
 }
 
+// preferPortal moves the given portal to the front of the connector infos, so that it is connected first
+func preferPortal(constructInfos []singleConnectorInfo, portal string) []singleConnectorInfo {
+	for i, info := range constructInfos {
+		if i > 0 && info.tgtPortal == portal {
+			preferred := append([]singleConnectorInfo{info}, constructInfos[:i]...)
+			return append(preferred, constructInfos[i+1:]...)
+		}
+	}
+
+	return constructInfos
+}
+
 func tryConnectVolume(ctx context.Context, connMap map[string]interface{}) (string, error) {
 	conn, err := parseISCSIInfo(ctx, connMap)
 	if err != nil {
 		return "", err
 	}
 
-	constructInfos := constructISCSIInfo(ctx, conn)
-	lenIndex := len(constructInfos)
-	if !conn.volumeUseMultiPath {
-		lenIndex = 1
+	constructInfos := preferPortal(constructISCSIInfo(ctx, conn), conn.lastSuccessfulPortal)
+	if conn.volumeUseMultiPath {
+		devPath, foundPortals, err := connectPortals(ctx, conn, constructInfos)
+		if err == nil && len(foundPortals) != 0 && !utils.IsContain(conn.lastSuccessfulPortal, foundPortals) {
+			connMap[connector.LastSuccessfulPortal] = foundPortals[0]
+		}
+		return devPath, err
 	}
 
+	// The single path volume is connected by one portal, the other portals are tried only if it fails.
+	err = errors.New(connector.VolumeNotFound)
+	for i, info := range constructInfos {
+		var devPath string
+		devPath, _, err = connectPortals(ctx, conn, constructInfos[i:i+1])
+		if err == nil {
+			connMap[connector.LastSuccessfulPortal] = info.tgtPortal
+			return devPath, nil
+		}
+
+		log.AddContext(ctx).Warningf("Connect volume %s by portal %s failed, try the next portal, error: %v",
+			conn.tgtLunWWN, info.tgtPortal, err)
+	}
+
+	return "", err
+}
+
+func connectPortals(ctx context.Context, conn connectorInfo,
+	constructInfos []singleConnectorInfo) (string, []string, error) {
 	var wait sync.WaitGroup
-	iSCSIShareData := connectVolume(ctx, &wait, constructInfos[:lenIndex], conn)
-	diskName, err := findDevice(ctx, conn, iSCSIShareData, lenIndex)
+	iSCSIShareData := connectVolume(ctx, &wait, constructInfos, conn)
+	diskName, err := findDevice(ctx, conn, iSCSIShareData, len(constructInfos))
 	if err != nil {
 		log.AddContext(ctx).Errorf("failed to find a disk. %v", err)
 	}
 	iSCSIShareData.stopConnecting = true
 	wait.Wait()
 
-	return checkDeviceAvailable(ctx, conn, iSCSIShareData, diskName, int(iSCSIShareData.numLogin))
+	devPath, err := checkDeviceAvailable(ctx, conn, iSCSIShareData, diskName, int(iSCSIShareData.numLogin))
+	return devPath, iSCSIShareData.foundPortals, err
 }
 
 func catchConnectError(ctx context.Context) {
@@ -0,0 +1,159 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package connector
+
+import (
+	"context"
+	"strings"
+
+	"huawei-csi-driver/connector/utils/lock"
+	"huawei-csi-driver/csi/app"
+	"huawei-csi-driver/utils"
+	"huawei-csi-driver/utils/log"
+	"huawei-csi-driver/utils/metrics"
+)
+
+const (
+	// multipathMapColumns is the column number of the output of "multipathd show maps": name, sysfs and uuid
+	multipathMapColumns = 3
+
+	// scsiMultipathUUIDPrefix is the prefix of the uuid of the multipath maps of the SCSI devices, which is
+	// followed by the WWN of the LUN
+	scsiMultipathUUIDPrefix = "3"
+
+	// naaRegisteredExtended is the NAA type of the WWNs of the LUNs, which begins the WWN
+	naaRegisteredExtended = "6"
+
+	// wwnVendorLength is the length of the NAA type and the IEEE company id at the head of a NAA 6 WWN,
+	// which identifies the vendor of the LUN
+	wwnVendorLength = 7
+)
+
+type multipathMap struct {
+	name string
+	dm   string
+	wwn  string
+}
+
+// cleanupOrphanedMultipathMapsEnabled reports whether the orphaned DM-multipath maps are cleaned up on detach
+func cleanupOrphanedMultipathMapsEnabled() bool {
+	return app.GetGlobalConfig().CleanupOrphanedMultipathMaps && app.GetGlobalConfig().VolumeUseMultiPath &&
+		app.GetGlobalConfig().ScsiMultiPathType == DMMultiPath
+}
+
+// getWWNVendor returns the NAA type and the IEEE company id of the WWN, empty if the WWN is not a NAA 6 WWN
+func getWWNVendor(wwn string) string {
+	if len(wwn) < wwnVendorLength || !strings.HasPrefix(wwn, naaRegisteredExtended) {
+		return ""
+	}
+
+	return strings.ToLower(wwn[:wwnVendorLength])
+}
+
+// CleanupOrphanedMultipathMaps flushes the DM-multipath maps whose underlying paths are all gone. They are left
+// behind when the disconnection of a volume partially fails, and collide with the maps of later attached volumes.
+// Only the maps of the LUNs of the same vendor as the disconnected LUN are cleaned, each under the lock of its LUN,
+// so that the maps of the other vendors and the maps of the LUNs being connected are kept.
+// It returns the number of the cleaned maps.
+func CleanupOrphanedMultipathMaps(ctx context.Context, tgtLunWWN string) int {
+	vendor := getWWNVendor(tgtLunWWN)
+	if vendor == "" {
+		log.AddContext(ctx).Infof("LUN %s is not identified by a NAA WWN, skip cleaning up the orphaned maps",
+			tgtLunWWN)
+		return 0
+	}
+
+	output, err := utils.ExecShellCmd(ctx, "multipathd show maps")
+	if err != nil {
+		log.AddContext(ctx).Warningf("Query the multipath maps failed, skip cleaning up the orphaned maps, "+
+			"error: %v", err)
+		return 0
+	}
+
+	var cleaned int
+	for _, m := range getOrphanedMultipathMaps(ctx, output, vendor) {
+		if cleanupOrphanedMultipathMap(ctx, m, tgtLunWWN) {
+			cleaned++
+		}
+	}
+
+	metrics.OrphanedMultipathMapsCleaned.Add(float64(cleaned))
+	return cleaned
+}
+
+// cleanupOrphanedMultipathMap flushes the map under the lock of its LUN, the lock of the disconnected LUN is
+// already held by the caller. The map is skipped if its LUN is being connected or disconnected, or it has got
+// any path before the lock is taken.
+func cleanupOrphanedMultipathMap(ctx context.Context, m multipathMap, heldWWN string) bool {
+	if !strings.EqualFold(m.wwn, heldWWN) {
+		locked, err := lock.TryLock(ctx, m.wwn)
+		if err != nil || !locked {
+			log.AddContext(ctx).Infof("LUN %s of multipath map %s is in use, skip cleaning it up, error: %v",
+				m.wwn, m.name, err)
+			return false
+		}
+
+		defer func() {
+			if err := lock.Unlock(ctx, m.wwn); err != nil {
+				log.AddContext(ctx).Errorf("Release the lock of LUN %s error: %v", m.wwn, err)
+			}
+		}()
+	}
+
+	devices, err := getDeviceFromDM(m.dm)
+	if err != nil || len(devices) != 0 {
+		log.AddContext(ctx).Infof("Multipath map %s has devices %v, skip cleaning it up, error: %v",
+			m.name, devices, err)
+		return false
+	}
+
+	if err = FlushDMDevice(ctx, m.dm); err != nil {
+		log.AddContext(ctx).Warningf("Clean up the orphaned multipath map %s failed, error: %v", m.dm, err)
+		return false
+	}
+
+	log.AddContext(ctx).Infof("Cleaned up the orphaned multipath map %s of LUN %s", m.dm, m.wwn)
+	return true
+}
+
+// getOrphanedMultipathMaps returns the maps of the LUNs of the vendor without any slave device
+func getOrphanedMultipathMaps(ctx context.Context, output, vendor string) []multipathMap {
+	var orphaned []multipathMap
+	for _, line := range strings.Split(output, "\n") {
+		column := strings.Fields(line)
+		if len(column) != multipathMapColumns || !strings.HasPrefix(column[1], "dm-") {
+			continue
+		}
+
+		wwn := strings.TrimPrefix(strings.ToLower(column[2]), scsiMultipathUUIDPrefix)
+		if len(wwn) == len(column[2]) || !strings.HasPrefix(wwn, vendor) {
+			continue
+		}
+
+		devices, err := getDeviceFromDM(column[1])
+		if err != nil {
+			log.AddContext(ctx).Warningf("Get the devices of multipath map %s failed, error: %v", column[0], err)
+			continue
+		}
+
+		if len(devices) == 0 {
+			orphaned = append(orphaned, multipathMap{name: column[0], dm: column[1], wwn: wwn})
+		}
+	}
+
+	return orphaned
+}
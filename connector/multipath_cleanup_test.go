@@ -0,0 +1,73 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package connector
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestGetOrphanedMultipathMaps(t *testing.T) {
+	slaves := map[string][]string{
+		"dm-0": {"sda", "sdb"},
+		"dm-1": nil,
+		"dm-2": {"sdc"},
+		"dm-3": nil,
+		"dm-4": nil,
+		"dm-5": nil,
+	}
+	original := getDeviceFromDM
+	defer func() { getDeviceFromDM = original }()
+	getDeviceFromDM = func(dm string) ([]string, error) {
+		return slaves[dm], nil
+	}
+
+	output := "name    sysfs uuid\n" +
+		"mpatha  dm-0  36888603000000001\n" +
+		"mpathb  dm-1  36888603000000002\n" +
+		"mpathc  dm-2  36888603000000003\n" +
+		"mpathd  dm-3  36888603000000004\n" +
+		"mpathe  dm-4  3600a098000000005\n" +
+		"mpathf  dm-5  eui.6888603000000006\n"
+	got := getOrphanedMultipathMaps(context.Background(), output, getWWNVendor("6888603000000009"))
+	want := []multipathMap{
+		{name: "mpathb", dm: "dm-1", wwn: "6888603000000002"},
+		{name: "mpathd", dm: "dm-3", wwn: "6888603000000004"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("getOrphanedMultipathMaps() = %v, want %v", got, want)
+	}
+}
+
+func TestGetWWNVendor(t *testing.T) {
+	tests := []struct {
+		wwn  string
+		want string
+	}{
+		{wwn: "6888603000000009", want: "6888603"},
+		{wwn: "6888603", want: "6888603"},
+		{wwn: "688860", want: ""},
+		{wwn: "eui.6888603000000009", want: ""},
+	}
+
+	for _, tt := range tests {
+		if got := getWWNVendor(tt.wwn); got != tt.want {
+			t.Errorf("getWWNVendor(%s) = %v, want %v", tt.wwn, got, tt.want)
+		}
+	}
+}
@@ -105,7 +105,9 @@ func tryConnectVolume(ctx context.Context, connMap map[string]interface{}) (stri
 func getVirtualDevice(ctx context.Context, conn connectorInfo, channels []string) (string, error) {
 	var virtualDevice string
 	var err error
-	if conn.volumeUseMultiPath {
+	if conn.volumeUseMultiPath && conn.multiPathType == connector.NativeNVMe {
+		virtualDevice, err = getVirtualDeviceUseNativeMultipath(ctx, conn)
+	} else if conn.volumeUseMultiPath {
 		virtualDevice, err = getVirtualDeviceUseMultipath(ctx, conn)
 	} else {
 		virtualDevice, err = connector.GetNVMeDevice(ctx, channels[0], conn.tgtLunGUID)
@@ -145,6 +147,22 @@ func getVirtualDeviceUseMultipath(ctx context.Context, conn connectorInfo) (stri
 	return virtualDevice, nil
 }
 
+func getVirtualDeviceUseNativeMultipath(ctx context.Context, conn connectorInfo) (string, error) {
+	for i := 0; i < 5; i++ {
+		virtualDevice, err := connector.GetNativeNVMeDevice(ctx, conn.tgtLunGUID)
+		if err != nil {
+			return "", err
+		}
+		if virtualDevice != "" {
+			return virtualDevice, nil
+		}
+
+		time.Sleep(time.Second)
+	}
+	log.AddContext(ctx).Warningln("Get native nvme multipath device failed.")
+	return "", nil
+}
+
 func getAllChannel(ctx context.Context, conn connectorInfo) ([]string, error) {
 	nvmeConnectInfo, err := connector.GetSubSysInfo(ctx)
 	if err != nil {
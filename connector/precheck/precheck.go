@@ -0,0 +1,311 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+// Package precheck checks whether a host is ready to connect the volumes of the protocols of the backends,
+// such as the initiators, multipath configuration, kernel modules, udev rules and sysctl parameters
+package precheck
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// the status of a check
+const (
+	StatusPass = "pass"
+	StatusFail = "fail"
+	StatusSkip = "skip"
+)
+
+// the names of the checks, which are used to skip them
+const (
+	CheckISCSIInitiator     = "iscsi-initiator"
+	CheckMultipathBlacklist = "multipath-blacklist"
+	CheckKernelModules      = "kernel-modules"
+	CheckUdevRules          = "udev-rules"
+	CheckSysctl             = "sysctl"
+)
+
+const (
+	protocolISCSI  = "iscsi"
+	protocolFC     = "fc"
+	protocolRoCE   = "roce"
+	protocolFCNVMe = "fc-nvme"
+	protocolNFS    = "nfs"
+
+	iscsiInitiatorFile = "etc/iscsi/initiatorname.iscsi"
+	multipathConfFile  = "etc/multipath.conf"
+	rpFilterFile       = "proc/sys/net/ipv4/conf/all/rp_filter"
+	moduleDir          = "sys/module"
+
+	// rpFilterStrict drops the replies of the paths in the same subnet which are routed by another interface
+	rpFilterStrict = "1"
+)
+
+// the rule directories which the udev rules of dm-multipath are installed in by the distributions
+var udevRuleDirs = []string{"etc/udev/rules.d", "lib/udev/rules.d", "usr/lib/udev/rules.d"}
+
+// the kernel modules required by each protocol
+var protocolModules = map[string][]string{
+	protocolISCSI:  {"iscsi_tcp", "dm_multipath"},
+	protocolFC:     {"scsi_transport_fc", "dm_multipath"},
+	protocolRoCE:   {"nvme_fabrics", "nvme_rdma"},
+	protocolFCNVMe: {"nvme_fabrics", "nvme_fc"},
+	protocolNFS:    {"nfs"},
+}
+
+// the blacklist entries which match all the devices, including the luns of the storage
+var blacklistAllPattern = regexp.MustCompile(`^\s*(devnode|wwid)\s+"?(\*|\.\*)"?\s*$`)
+
+// Result is the result of a check
+type Result struct {
+	Name        string `json:"name"`
+	Status      string `json:"status"`
+	Message     string `json:"message,omitempty"`
+	Remediation string `json:"remediation,omitempty"`
+}
+
+// Report is the results of all the checks of a host
+type Report struct {
+	Node      string   `json:"node,omitempty"`
+	Protocols []string `json:"protocols"`
+	Passed    bool     `json:"passed"`
+	Results   []Result `json:"results"`
+}
+
+// Options are the options of the checks
+type Options struct {
+	// HostRoot is the directory which the root of the host is mounted on
+	HostRoot string
+	// Protocols are the protocols of the backends which the host connects
+	Protocols []string
+	// Skip are the names of the checks which are skipped
+	Skip []string
+	// Initiators are the iscsi initiators of the other hosts by their host names, which the initiator of this
+	// host must differ from
+	Initiators map[string]string
+	// HostName is the name of this host, which is excluded from the initiators of the other hosts
+	HostName string
+}
+
+type check struct {
+	name      string
+	protocols []string
+	run       func(opts *Options) Result
+}
+
+var checks = []check{
+	{CheckISCSIInitiator, []string{protocolISCSI}, checkISCSIInitiator},
+	{CheckMultipathBlacklist, []string{protocolISCSI, protocolFC}, checkMultipathBlacklist},
+	{CheckKernelModules, nil, checkKernelModules},
+	{CheckUdevRules, []string{protocolISCSI, protocolFC}, checkUdevRules},
+	{CheckSysctl, []string{protocolISCSI, protocolRoCE}, checkSysctl},
+}
+
+// Run runs the checks applicable to the protocols, the report is passed if none of the checks fails
+func Run(opts *Options) *Report {
+	report := &Report{Node: opts.HostName, Protocols: opts.Protocols, Passed: true}
+	for _, c := range checks {
+		result := Result{Name: c.name, Status: StatusSkip}
+		switch {
+		case contains(opts.Skip, c.name):
+			result.Message = "skipped by the flag"
+		case !appliesTo(c.protocols, opts.Protocols):
+			result.Message = "not required by the protocols"
+		default:
+			result = c.run(opts)
+			result.Name = c.name
+		}
+
+		if result.Status == StatusFail {
+			report.Passed = false
+		}
+		report.Results = append(report.Results, result)
+	}
+
+	return report
+}
+
+// IsValidCheck returns whether the name is one of the checks
+func IsValidCheck(name string) bool {
+	for _, c := range checks {
+		if c.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func checkISCSIInitiator(opts *Options) Result {
+	content, err := os.ReadFile(hostPath(opts, iscsiInitiatorFile))
+	if err != nil {
+		return fail("read the iscsi initiator failed: "+err.Error(),
+			"install the iscsi initiator utils and generate the initiator name by iscsi-iname")
+	}
+
+	var initiator string
+	for _, line := range strings.Split(string(content), "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "InitiatorName=") {
+			initiator = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), "InitiatorName="))
+		}
+	}
+	if initiator == "" {
+		return fail("no InitiatorName is configured in /"+iscsiInitiatorFile,
+			"generate the initiator name by iscsi-iname and restart iscsid")
+	}
+
+	for host, other := range opts.Initiators {
+		if host != opts.HostName && other == initiator {
+			return fail("the iscsi initiator "+initiator+" is also used by host "+host,
+				"generate a unique initiator name by iscsi-iname, which often happens to the cloned hosts, "+
+					"and restart iscsid")
+		}
+	}
+
+	return pass("the iscsi initiator is " + initiator)
+}
+
+func checkMultipathBlacklist(opts *Options) Result {
+	content, err := os.ReadFile(hostPath(opts, multipathConfFile))
+	if err != nil {
+		return fail("read the multipath configuration failed: "+err.Error(),
+			"install device-mapper-multipath and generate /"+multipathConfFile+" by mpathconf --enable")
+	}
+
+	blacklist, exceptions := parseMultipathSection(string(content), "blacklist"),
+		parseMultipathSection(string(content), "blacklist_exceptions")
+	for _, line := range blacklist {
+		if blacklistAllPattern.MatchString(line) && len(exceptions) == 0 {
+			return fail("the multipath blacklist \""+strings.TrimSpace(line)+"\" excludes all the devices",
+				"add the luns of the storage to the blacklist_exceptions, or narrow the blacklist")
+		}
+		if strings.Contains(strings.ToUpper(line), "HUAWEI") {
+			return fail("the multipath blacklist excludes the HUAWEI devices",
+				"remove the HUAWEI device from the blacklist of /"+multipathConfFile)
+		}
+	}
+
+	return pass("the multipath blacklist does not exclude the luns of the storage")
+}
+
+// parseMultipathSection returns the lines in the top level section of the multipath configuration
+func parseMultipathSection(content, section string) []string {
+	var lines []string
+	depth, inSection := 0, false
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "#") || trimmed == "" {
+			continue
+		}
+
+		if depth == 0 {
+			fields := strings.Fields(trimmed)
+			inSection = len(fields) > 0 && fields[0] == section
+		} else if inSection {
+			lines = append(lines, trimmed)
+		}
+		depth += strings.Count(trimmed, "{") - strings.Count(trimmed, "}")
+	}
+	return lines
+}
+
+func checkKernelModules(opts *Options) Result {
+	var missing []string
+	for _, module := range requiredModules(opts.Protocols) {
+		if _, err := os.Stat(hostPath(opts, filepath.Join(moduleDir, module))); err != nil {
+			missing = append(missing, module)
+		}
+	}
+
+	if len(missing) > 0 {
+		return fail("the kernel modules "+strings.Join(missing, ",")+" are not loaded",
+			"load the modules by modprobe and add them to /etc/modules-load.d to load them on boot")
+	}
+	return pass("the required kernel modules are loaded")
+}
+
+func requiredModules(protocols []string) []string {
+	var modules []string
+	for _, protocol := range protocols {
+		for _, module := range protocolModules[protocol] {
+			if !contains(modules, module) {
+				modules = append(modules, module)
+			}
+		}
+	}
+	return modules
+}
+
+func checkUdevRules(opts *Options) Result {
+	for _, dir := range udevRuleDirs {
+		rules, err := filepath.Glob(filepath.Join(hostPath(opts, dir), "*multipath*.rules"))
+		if err == nil && len(rules) > 0 {
+			return pass("the multipath udev rules are installed: " + strings.Join(rules, ","))
+		}
+	}
+
+	return fail("no multipath udev rules are found in "+strings.Join(udevRuleDirs, ","),
+		"reinstall device-mapper-multipath, which installs the udev rules creating the multipath devices")
+}
+
+func checkSysctl(opts *Options) Result {
+	content, err := os.ReadFile(hostPath(opts, rpFilterFile))
+	if err != nil {
+		return fail("read net.ipv4.conf.all.rp_filter failed: "+err.Error(), "check whether /proc is mounted")
+	}
+
+	if strings.TrimSpace(string(content)) == rpFilterStrict {
+		return fail("net.ipv4.conf.all.rp_filter is 1, which drops the replies of the paths in the same subnet",
+			"set net.ipv4.conf.all.rp_filter to 0 or 2 in /etc/sysctl.conf and run sysctl -p")
+	}
+	return pass("net.ipv4.conf.all.rp_filter is " + strings.TrimSpace(string(content)))
+}
+
+func hostPath(opts *Options, path string) string {
+	return filepath.Join(opts.HostRoot, path)
+}
+
+func appliesTo(checkProtocols, protocols []string) bool {
+	if checkProtocols == nil {
+		return true
+	}
+
+	for _, protocol := range protocols {
+		if contains(checkProtocols, protocol) {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func pass(message string) Result {
+	return Result{Status: StatusPass, Message: message}
+}
+
+func fail(message, remediation string) Result {
+	return Result{Status: StatusFail, Message: message, Remediation: remediation}
+}
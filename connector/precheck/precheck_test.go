@@ -0,0 +1,103 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package precheck
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeHostFile(t *testing.T, root, path, content string) {
+	fullPath := filepath.Join(root, path)
+	if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func newReadyHost(t *testing.T) string {
+	root := t.TempDir()
+	writeHostFile(t, root, iscsiInitiatorFile, "InitiatorName=iqn.1994-05.com.redhat:node-1\n")
+	writeHostFile(t, root, multipathConfFile, "defaults {\n\tuser_friendly_names yes\n}\n"+
+		"blacklist {\n\tdevnode \"^sda$\"\n}\n")
+	writeHostFile(t, root, "lib/udev/rules.d/62-multipath.rules", "")
+	writeHostFile(t, root, rpFilterFile, "2\n")
+	for _, module := range []string{"iscsi_tcp", "dm_multipath", "scsi_transport_fc"} {
+		if err := os.MkdirAll(filepath.Join(root, moduleDir, module), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name       string
+		prepare    func(t *testing.T, root string)
+		opts       Options
+		expectPass bool
+		failed     string
+	}{
+		{"ReadyHost", nil, Options{Protocols: []string{"iscsi", "fc"}}, true, ""},
+		{"DuplicateInitiator", nil, Options{Protocols: []string{"iscsi"}, HostName: "node-1",
+			Initiators: map[string]string{"node-1": "iqn.1994-05.com.redhat:node-1",
+				"node-2": "iqn.1994-05.com.redhat:node-1"}}, false, CheckISCSIInitiator},
+		{"BlacklistAll", func(t *testing.T, root string) {
+			writeHostFile(t, root, multipathConfFile, "blacklist {\n\twwid \".*\"\n}\n")
+		}, Options{Protocols: []string{"fc"}}, false, CheckMultipathBlacklist},
+		{"BlacklistAllWithExceptions", func(t *testing.T, root string) {
+			writeHostFile(t, root, multipathConfFile, "blacklist {\n\twwid \".*\"\n}\n"+
+				"blacklist_exceptions {\n\tdevice {\n\t\tvendor \"HUAWEI\"\n\t}\n}\n")
+		}, Options{Protocols: []string{"fc"}}, true, ""},
+		{"MissingModule", nil, Options{Protocols: []string{"roce"}}, false, CheckKernelModules},
+		{"SkippedMissingModule", nil, Options{Protocols: []string{"roce"}, Skip: []string{CheckKernelModules}},
+			true, ""},
+		{"StrictRpFilter", func(t *testing.T, root string) {
+			writeHostFile(t, root, rpFilterFile, "1\n")
+		}, Options{Protocols: []string{"iscsi"}}, false, CheckSysctl},
+		{"StrictRpFilterNotRequiredByFC", func(t *testing.T, root string) {
+			writeHostFile(t, root, rpFilterFile, "1\n")
+		}, Options{Protocols: []string{"fc"}}, true, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := newReadyHost(t)
+			if tt.prepare != nil {
+				tt.prepare(t, root)
+			}
+			opts := tt.opts
+			opts.HostRoot = root
+
+			report := Run(&opts)
+			if report.Passed != tt.expectPass {
+				t.Fatalf("Run() passed = %v, want %v, results: %+v", report.Passed, tt.expectPass, report.Results)
+			}
+			for _, result := range report.Results {
+				if result.Status == StatusFail && result.Name != tt.failed {
+					t.Errorf("Run() check %s fails: %s", result.Name, result.Message)
+				}
+				if result.Status == StatusFail && result.Remediation == "" {
+					t.Errorf("Run() check %s fails without remediation", result.Name)
+				}
+			}
+		})
+	}
+}
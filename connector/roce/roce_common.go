@@ -55,6 +55,10 @@ func getSessionPort(ctx context.Context, device string, deviceType int) (string,
 }
 
 func getSessionPortByDevice(ctx context.Context, devPath string) (string, error) {
+	if controller := connector.GetNativeNVMePathController(devPath); controller != "" {
+		return controller, nil
+	}
+
 	splitS := strings.Split(devPath, "n")
 	if len(splitS) != intNumThree {
 		return "", utils.Errorf(ctx, "device %s is not valid", devPath)
@@ -293,8 +293,12 @@ func tryConnectVolume(ctx context.Context, connMap map[string]interface{}) (stri
 
 func scanDevice(ctx context.Context, conn connectorInfo, nvmeShareData *shareData) string {
 	var mPath string
-	if conn.volumeUseMultiPath {
-		mPath = scanUpNVMeMultiPath(ctx, conn, nvmeShareData)
+	if conn.volumeUseMultiPath && conn.multiPathType == connector.NativeNVMe {
+		mPath = scanNVMeMultiPath(ctx, conn, nvmeShareData, connector.GetNativeNVMeDevice)
+	} else if conn.volumeUseMultiPath {
+		mPath = scanNVMeMultiPath(ctx, conn, nvmeShareData, func(ctx context.Context, guid string) (string, error) {
+			return connector.GetDevNameByLunWWN(ctx, connector.UltraPathNVMeCommand, guid)
+		})
 	} else {
 		scanSingle(ctx, nvmeShareData)
 	}
@@ -302,8 +306,9 @@ func scanDevice(ctx context.Context, conn connectorInfo, nvmeShareData *shareDat
 	return mPath
 }
 
-func scanUpNVMeMultiPath(ctx context.Context, conn connectorInfo, nvmeShareData *shareData) string {
-	log.AddContext(ctx).Infof("Enter function:scanUpNVMeMultiPath. connectorInfo:%#v", conn)
+func scanNVMeMultiPath(ctx context.Context, conn connectorInfo, nvmeShareData *shareData,
+	getDevice func(ctx context.Context, tgtLunGUID string) (string, error)) string {
+	log.AddContext(ctx).Infof("Enter function:scanNVMeMultiPath. connectorInfo:%#v", conn)
 	var device string
 	var err error
 	var timeout int64
@@ -312,14 +317,14 @@ func scanUpNVMeMultiPath(ctx context.Context, conn connectorInfo, nvmeShareData
 		isThreadNotFinishedOrDeviceNotObtained(device, allThread, nvmeShareData) {
 		if timeout == 0 && len(nvmeShareData.foundDevices) != 0 && nvmeShareData.stoppedThreads == allThread {
 			log.AddContext(ctx).Infof("All connection threads finished, "+
-				"giving %d seconds for ultrapath* to appear.", connectTimeOut)
+				"giving %d seconds for the multipath device to appear.", connectTimeOut)
 			timeout = time.Now().Unix() + connectTimeOut
 		} else if timeout != 0 && time.Now().Unix() > timeout {
-			log.AddContext(ctx).Infof("scanUpNVMeMultiPath time out. device:%s", device)
+			log.AddContext(ctx).Infof("scanNVMeMultiPath time out. device:%s", device)
 			break
 		}
 
-		device, err = connector.GetDevNameByLunWWN(ctx, connector.UltraPathNVMeCommand, conn.tgtLunGUID)
+		device, err = getDevice(ctx, conn.tgtLunGUID)
 		if err != nil {
 			log.AddContext(ctx).Warningf("get disk name by wwn failed. error:%v", err)
 		}
@@ -342,6 +347,11 @@ func verifyDevice(ctx context.Context,
 	nvmeShareData *shareData,
 	mPath string) (string, error) {
 	log.AddContext(ctx).Infof("Enter function:verifyDevice, mPath:%s", mPath)
+	// the path devices of the native nvme multipath are hidden, only the multipath device is found
+	if conn.volumeUseMultiPath && conn.multiPathType == connector.NativeNVMe {
+		return verifyNativeNVMeDevice(ctx, conn, mPath)
+	}
+
 	if nvmeShareData.foundDevices == nil {
 		return "", utils.Errorf(ctx, connector.VolumeDeviceNotFound)
 	}
@@ -376,6 +386,23 @@ func verifyDevice(ctx context.Context,
 	return "", errors.New(connector.VolumeDeviceNotFound)
 }
 
+func verifyNativeNVMeDevice(ctx context.Context, conn connectorInfo, mPath string) (string, error) {
+	if mPath == "" {
+		log.AddContext(ctx).Errorln("no native nvme multipath device was created")
+		return "", errors.New(connector.VolumeDeviceNotFound)
+	}
+
+	device := fmt.Sprintf("/dev/%s", mPath)
+	err := connector.VerifySingleDevice(ctx, device, conn.tgtLunGUID,
+		connector.VolumeDeviceNotFound, tryDisConnectVolume)
+	if err != nil {
+		log.AddContext(ctx).Errorf("Verify native nvme multipath device:%s failed. error:%v", device, err)
+		return "", err
+	}
+
+	return device, nil
+}
+
 func getSubSysPaths(ctx context.Context,
 	nvmeConnectInfo map[string]interface{},
 	targetNqn string) []interface{} {
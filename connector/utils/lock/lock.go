@@ -120,3 +120,29 @@ func SyncUnlock(ctx context.Context, lockName, operationType string) error {
 	log.AddContext(ctx).Infof("It took %s to release %s lock for %s.", time.Since(startTime), operationType, lockName)
 	return nil
 }
+
+// TryLock takes the lock of the disk without waiting for it and without a semaphore, false is returned if the lock
+// is held by the connection, disconnection or expansion of the disk
+func TryLock(ctx context.Context, lockName string) (bool, error) {
+	err := createLockDir(filepath.Dir(lockDir))
+	if err != nil {
+		return false, fmt.Errorf("create dir failed, reason: %s", err)
+	}
+
+	filePath := fmt.Sprintf("%s%s%s", lockDir, lockNamePrefix, lockName)
+	lockMutex.Lock()
+	defer lockMutex.Unlock()
+	if isFileExist(filePath) {
+		return false, nil
+	}
+
+	if err = createLockFile(ctx, filePath, lockName); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Unlock releases the lock taken by TryLock
+func Unlock(ctx context.Context, lockName string) error {
+	return deleteLockFile(ctx, lockDir, lockName)
+}
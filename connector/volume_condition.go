@@ -0,0 +1,111 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package connector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const scsiDeviceStateRunning = "running"
+
+// VolumeCondition is the health of a staged volume, a healthy volume may still carry a message,
+// such as the volume is degraded
+type VolumeCondition struct {
+	Abnormal bool
+	Message  string
+}
+
+// GetDMVolumeCondition checks the paths of the dm-multipath device, the volume is abnormal without any
+// active path and degraded with fewer active paths than minPaths. 0 minPaths means not checking degraded.
+func GetDMVolumeCondition(dm string, minPaths int) VolumeCondition {
+	devices, err := getDeviceFromDM(dm)
+	if err != nil {
+		return VolumeCondition{Abnormal: true, Message: fmt.Sprintf("get paths of %s failed, error: %v", dm, err)}
+	}
+
+	var active int
+	for _, device := range devices {
+		state, err := os.ReadFile(fmt.Sprintf("/sys/block/%s/device/state", device))
+		if err == nil && strings.TrimSpace(string(state)) == scsiDeviceStateRunning {
+			active++
+		}
+	}
+
+	return dmVolumeCondition(dm, active, len(devices), minPaths)
+}
+
+func dmVolumeCondition(dm string, active, total, minPaths int) VolumeCondition {
+	if active == 0 {
+		return VolumeCondition{Abnormal: true, Message: fmt.Sprintf("%s has no active path of %d paths", dm, total)}
+	}
+
+	if active < minPaths {
+		return VolumeCondition{Message: fmt.Sprintf("%s is degraded, %d of %d paths are active, expect at least %d",
+			dm, active, total, minPaths)}
+	}
+
+	return VolumeCondition{Message: fmt.Sprintf("%s has %d active paths", dm, active)}
+}
+
+// GetUltraPathVolumeCondition checks the vLUN status of the UltraPath disk
+func GetUltraPathVolumeCondition(ctx context.Context, upType, diskName string) VolumeCondition {
+	status, err := getDiskStatusByName(ctx, upType, diskName)
+	if err != nil {
+		return VolumeCondition{Abnormal: true, Message: err.Error()}
+	}
+
+	return ultraPathVolumeCondition(diskName, status)
+}
+
+func ultraPathVolumeCondition(diskName, status string) VolumeCondition {
+	switch status {
+	case diskStatusNormal, diskStatusDegraded:
+		return VolumeCondition{Message: fmt.Sprintf("vLUN %s is %s", diskName, status)}
+	default:
+		return VolumeCondition{Abnormal: true, Message: fmt.Sprintf("vLUN %s is %s", diskName, status)}
+	}
+}
+
+// GetNFSVolumeCondition probes the mount point by statfs, the volume is abnormal when the mount is stale
+// or the statfs does not return within the timeout
+func GetNFSVolumeCondition(mountPath string, timeout time.Duration) VolumeCondition {
+	result := make(chan error, 1)
+	go func() {
+		var stat syscall.Statfs_t
+		result <- syscall.Statfs(mountPath, &stat)
+	}()
+
+	select {
+	case err := <-result:
+		if errors.Is(err, syscall.ESTALE) {
+			return VolumeCondition{Abnormal: true, Message: fmt.Sprintf("NFS mount %s is stale", mountPath)}
+		} else if err != nil {
+			return VolumeCondition{Abnormal: true,
+				Message: fmt.Sprintf("statfs NFS mount %s failed, error: %v", mountPath, err)}
+		}
+		return VolumeCondition{Message: fmt.Sprintf("NFS mount %s is accessible", mountPath)}
+	case <-time.After(timeout):
+		return VolumeCondition{Abnormal: true,
+			Message: fmt.Sprintf("NFS mount %s does not respond within %s", mountPath, timeout)}
+	}
+}
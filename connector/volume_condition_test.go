@@ -0,0 +1,67 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package connector
+
+import (
+	"testing"
+)
+
+func TestDMVolumeCondition(t *testing.T) {
+	cases := []struct {
+		name         string
+		active       int
+		total        int
+		minPaths     int
+		wantAbnormal bool
+	}{
+		{"AllPathsActive", 4, 4, 2, false},
+		{"Degraded", 1, 4, 2, false},
+		{"NoActivePath", 0, 4, 2, true},
+		{"NoMinPaths", 1, 1, 0, false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			condition := dmVolumeCondition("dm-0", c.active, c.total, c.minPaths)
+			if condition.Abnormal != c.wantAbnormal {
+				t.Errorf("dmVolumeCondition() abnormal = %v, want %v, message: %s",
+					condition.Abnormal, c.wantAbnormal, condition.Message)
+			}
+		})
+	}
+}
+
+func TestUltraPathVolumeCondition(t *testing.T) {
+	cases := []struct {
+		name         string
+		status       string
+		wantAbnormal bool
+	}{
+		{"Normal", diskStatusNormal, false},
+		{"Degraded", diskStatusDegraded, false},
+		{"Fault", "Fault", true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			condition := ultraPathVolumeCondition("sdb", c.status)
+			if condition.Abnormal != c.wantAbnormal {
+				t.Errorf("ultraPathVolumeCondition() abnormal = %v, want %v", condition.Abnormal, c.wantAbnormal)
+			}
+		})
+	}
+}
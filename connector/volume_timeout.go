@@ -0,0 +1,91 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package connector
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"huawei-csi-driver/csi/app"
+)
+
+const (
+	// ScanVolumeTimeoutKey is the StorageClass parameter and the volume attribute overriding the
+	// scan-volume-timeout of the node plugin for a single volume, in seconds
+	ScanVolumeTimeoutKey = "scanVolumeTimeout"
+	// DeviceCleanupTimeoutKey is the StorageClass parameter and the volume attribute overriding the
+	// deviceCleanupTimeout of the node plugin for a single volume, in seconds
+	DeviceCleanupTimeoutKey = "deviceCleanupTimeout"
+
+	// MaxScanVolumeTimeout is the upper bound of the scan volume timeout, same as the global one
+	MaxScanVolumeTimeout = 600
+	// MaxDeviceCleanupTimeout is the upper bound of the per-volume device cleanup timeout
+	MaxDeviceCleanupTimeout = 600
+)
+
+type volumeTimeoutsKey struct{}
+
+// volumeTimeouts are the connector timeouts overridden by the volume, 0 means not overridden
+type volumeTimeouts struct {
+	scanVolume    int
+	deviceCleanup int
+}
+
+// ParseTimeoutOverride parses the per-volume timeout in seconds, the timeout must be in range [1, max]
+func ParseTimeoutOverride(key, value string, max int) (int, error) {
+	timeout, err := strconv.Atoi(value)
+	if err != nil || timeout < 1 || timeout > max {
+		return 0, fmt.Errorf("%s: [%s] must be an integer in range [1, %d]", key, value, max)
+	}
+
+	return timeout, nil
+}
+
+// WithVolumeTimeouts returns a context carrying the connector timeouts overridden by the volume attributes,
+// the invalid overrides are ignored so that the global timeouts are used
+func WithVolumeTimeouts(ctx context.Context, attributes map[string]string) context.Context {
+	var timeouts volumeTimeouts
+	if value, ok := attributes[ScanVolumeTimeoutKey]; ok {
+		timeouts.scanVolume, _ = ParseTimeoutOverride(ScanVolumeTimeoutKey, value, MaxScanVolumeTimeout)
+	}
+	if value, ok := attributes[DeviceCleanupTimeoutKey]; ok {
+		timeouts.deviceCleanup, _ = ParseTimeoutOverride(DeviceCleanupTimeoutKey, value, MaxDeviceCleanupTimeout)
+	}
+
+	if timeouts == (volumeTimeouts{}) {
+		return ctx
+	}
+	return context.WithValue(ctx, volumeTimeoutsKey{}, timeouts)
+}
+
+// GetScanVolumeTimeout returns the timeout of waiting for the multipath aggregation of the volume
+func GetScanVolumeTimeout(ctx context.Context) time.Duration {
+	if timeouts, ok := ctx.Value(volumeTimeoutsKey{}).(volumeTimeouts); ok && timeouts.scanVolume > 0 {
+		return time.Second * time.Duration(timeouts.scanVolume)
+	}
+	return time.Second * time.Duration(app.GetGlobalConfig().ScanVolumeTimeout)
+}
+
+// GetDeviceCleanupTimeout returns the timeout of cleaning up the stale devices of the volume
+func GetDeviceCleanupTimeout(ctx context.Context) time.Duration {
+	if timeouts, ok := ctx.Value(volumeTimeoutsKey{}).(volumeTimeouts); ok && timeouts.deviceCleanup > 0 {
+		return time.Second * time.Duration(timeouts.deviceCleanup)
+	}
+	return time.Second * time.Duration(app.GetGlobalConfig().DeviceCleanupTimeout)
+}
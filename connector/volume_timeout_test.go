@@ -0,0 +1,56 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package connector
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestWithVolumeTimeouts(t *testing.T) {
+	tests := []struct {
+		name              string
+		attributes        map[string]string
+		wantScanVolume    time.Duration
+		wantDeviceCleanup time.Duration
+	}{
+		{"ScanVolume", map[string]string{ScanVolumeTimeoutKey: "30"}, 30 * time.Second, 0},
+		{"DeviceCleanup", map[string]string{DeviceCleanupTimeoutKey: "480"}, 0, 480 * time.Second},
+		{"Both", map[string]string{ScanVolumeTimeoutKey: "600", DeviceCleanupTimeoutKey: "1"},
+			600 * time.Second, time.Second},
+		{"ExceedMax", map[string]string{ScanVolumeTimeoutKey: "601"}, 0, 0},
+		{"Invalid", map[string]string{ScanVolumeTimeoutKey: "abc", DeviceCleanupTimeoutKey: "0"}, 0, 0},
+		{"None", nil, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := WithVolumeTimeouts(context.Background(), tt.attributes)
+			timeouts, _ := ctx.Value(volumeTimeoutsKey{}).(volumeTimeouts)
+			if got := time.Duration(timeouts.scanVolume) * time.Second; got != tt.wantScanVolume {
+				t.Errorf("WithVolumeTimeouts() scan volume timeout = %v, want %v", got, tt.wantScanVolume)
+			}
+			if got := time.Duration(timeouts.deviceCleanup) * time.Second; got != tt.wantDeviceCleanup {
+				t.Errorf("WithVolumeTimeouts() device cleanup timeout = %v, want %v", got, tt.wantDeviceCleanup)
+			}
+			if tt.wantScanVolume > 0 && GetScanVolumeTimeout(ctx) != tt.wantScanVolume {
+				t.Errorf("GetScanVolumeTimeout() = %v, want %v", GetScanVolumeTimeout(ctx), tt.wantScanVolume)
+			}
+		})
+	}
+}
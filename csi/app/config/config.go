@@ -38,6 +38,58 @@ type serviceConfig struct {
 	Controller           bool
 	EnableLeaderElection bool
 	EnableLabel          bool
+	// detach all volumes of the node when the node is deleted
+	EnableNodeDeletionDetach bool
+	// delete the nfs shares of the released volumes whose reclaim policy is Retain
+	EnableRetainedShareCleanup bool
+	// skip the snapshot space pre-check before creating snapshots
+	SkipSnapshotSpaceCheck bool
+	// merge the default parameters of the namespace of the PVC into the StorageClass parameters
+	EnableNamespaceDefaultParameters bool
+	// the period of waiting for the partially set manage annotations to be completed, 0 means not waiting
+	ManageAnnotationsGracePeriod time.Duration
+	// reject staging volumes when the node plugin is incompatible with the controller plugin
+	StrictVersionCheck bool
+	// the number of active paths below which a multipath volume is reported as degraded
+	VolumeConditionMinPaths int
+	// the configmap of backends to watch, format is <namespace>/<name>
+	BackendConfigConfigmap string
+	// the controller capabilities disabled by the policy of this deployment
+	DisableSnapshot bool
+	DisableClone    bool
+	DisableExpand   bool
+	// the period of collecting the simultaneous lun creations into one batch request, 0 means disabled
+	LunBatchCreateWindow time.Duration
+	// the policy of selecting one of the pools with equal free capacity
+	PoolTieBreaker string
+	// the policy of the operations when the storage is in maintenance
+	MaintenancePolicy string
+	// inject the W3C Trace Context headers into the requests to the storage
+	EnableTracing bool
+	// the URL of the OTLP/HTTP collector which the spans are exported to
+	TracingEndpoint string
+	// the comma separated node environments which do not support expanding the attached fc-nvme volumes
+	UnsupportedOnlineExpansion string
+	// record the last exchanges with the storage and dump them when an operation fails
+	EnableRequestRecording bool
+	RequestRecordingSize   int
+	// the max period of waiting for the automatic expansion of a storage pool
+	PoolExpandTimeout time.Duration
+	// the age of the snapshots which are tiered to the object storage, 0 means disabled
+	TierSnapshotsAfter time.Duration
+	// the ratio of the healthy pools below which the backend is not ready
+	MinPoolHealthRatio float64
+	// the interval of the drift detection between the PVs and the volumes on the storage, 0 means disabled
+	DriftDetectionInterval time.Duration
+	// the max number of the PVs compared in each drift detection
+	DriftDetectionSampleSize int
+	// the max number of the volumes queried from the storage per second and concurrently from each backend
+	DriftDetectionQPS                float64
+	DriftDetectionBackendConcurrency int
+	// publish the free capacity of the pools by the CSIStorageCapacity objects
+	EnableStorageCapacity bool
+	// restrict the volumes of each backend to the nodes whose labels do not deny the backend
+	EnableBackendTopology bool
 
 	Endpoint         string
 	DrEndpoint       string
@@ -47,16 +99,27 @@ type serviceConfig struct {
 	KubeletRootDir   string
 	VolumeNamePrefix string
 
+	// the keep-alive of the gRPC connection from the storage-backend-sidecar to the DR-CSI provider
+	ProviderKeepaliveTime    time.Duration
+	ProviderKeepaliveTimeout time.Duration
+
 	MaxVolumesPerNode int
 	WebHookPort       int
 	// address of webhook server
 	WebHookAddress        string
 	WorkerThreads         int
 	BackendUpdateInterval int
+	// address of metrics server, empty means the metrics are not served
+	MetricsAddress string
+	// address of liveness probe server, empty means the liveness probe is not served
+	HealthAddress string
 
 	LeaderLeaseDuration time.Duration
 	LeaderRenewDeadline time.Duration
 	LeaderRetryPeriod   time.Duration
+	// type and namespace of the resource lock used for leader election
+	LeaderLockType      string
+	LeaderLockNamespace string
 	ReSyncPeriod        time.Duration
 	Timeout             time.Duration
 
@@ -73,6 +136,12 @@ type connectorConfig struct {
 	ConnectorThreads     int
 	AllPathOnline        bool
 	ExecCommandTimeout   int
+
+	FCPathDiscoveryTimeout  int
+	FCPathDiscoveryInterval int
+
+	// flush the DM-multipath maps whose paths are all gone on detach
+	CleanupOrphanedMultipathMaps bool
 }
 
 type k8sConfig struct {
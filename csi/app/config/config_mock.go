@@ -48,9 +48,16 @@ func mockLoggingConfig() loggingConfig {
 
 func mockServiceConfig() serviceConfig {
 	return serviceConfig{
-		Controller:           false,
-		EnableLeaderElection: false,
-		EnableLabel:          false,
+		Controller:                 false,
+		EnableLeaderElection:       false,
+		EnableLabel:                false,
+		EnableNodeDeletionDetach:   false,
+		EnableRetainedShareCleanup: false,
+		SkipSnapshotSpaceCheck:     false,
+		BackendConfigConfigmap:     "",
+		DisableSnapshot:            false,
+		DisableClone:               false,
+		DisableExpand:              false,
 
 		Endpoint:         "",
 		DrEndpoint:       "",
@@ -63,6 +70,7 @@ func mockServiceConfig() serviceConfig {
 		MaxVolumesPerNode:           0,
 		WebHookPort:                 0,
 		WebHookAddress:              "",
+		MetricsAddress:              "",
 		WorkerThreads:               0,
 		BackendUpdateInterval:       0,
 		KubeletVolumeDevicesDirName: "",
@@ -78,6 +86,9 @@ func mockConnectorConfig() connectorConfig {
 		ScanVolumeTimeout:    5,
 		ConnectorThreads:     5,
 		AllPathOnline:        true,
+
+		FCPathDiscoveryTimeout:  0,
+		FCPathDiscoveryInterval: 1,
 	}
 }
 
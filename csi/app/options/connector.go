@@ -28,11 +28,15 @@ const (
 	dmMultiPath     = "DM-multipath"
 	hwUltraPath     = "HW-UltraPath"
 	hwUltraPathNVMe = "HW-UltraPath-NVMe"
+	nativeNVMe      = "native"
 
 	defaultCleanupTimeout    = 240
 	defaultScanVolumeTimeout = 3
 	defaultConnectorThreads  = 4
 
+	defaultFCPathDiscoveryTimeout  = 30
+	defaultFCPathDiscoveryInterval = 5
+
 	minThreads = 1
 	maxThreads = 10
 )
@@ -46,6 +50,12 @@ type connectorOptions struct {
 	connectorThreads     int
 	allPathOnline        bool
 	execCommandTimeout   int
+
+	fcPathDiscoveryTimeout  int
+	fcPathDiscoveryInterval int
+
+	// flush the DM-multipath maps whose paths are all gone on detach
+	cleanupOrphanedMultipathMaps bool
 }
 
 // NewConnectorOptions returns connector configurations
@@ -58,6 +68,11 @@ func NewConnectorOptions() *connectorOptions {
 		scanVolumeTimeout:    defaultScanVolumeTimeout,
 		connectorThreads:     defaultConnectorThreads,
 		allPathOnline:        false,
+
+		fcPathDiscoveryTimeout:  defaultFCPathDiscoveryTimeout,
+		fcPathDiscoveryInterval: defaultFCPathDiscoveryInterval,
+
+		cleanupOrphanedMultipathMaps: true,
 	}
 }
 
@@ -71,7 +86,8 @@ func (opt *connectorOptions) AddFlags(ff *flag.FlagSet) {
 		"Multipath software for fc/iscsi block volumes")
 	ff.StringVar(&opt.nvmeMultiPathType, "nvme-multipath-type",
 		hwUltraPathNVMe,
-		"Multipath software for roce/fc-nvme block volumes")
+		"Multipath software for roce/fc-nvme block volumes, HW-UltraPath-NVMe or native which is the "+
+			"native nvme multipath of the kernel")
 	ff.IntVar(&opt.deviceCleanupTimeout, "deviceCleanupTimeout",
 		240,
 		"Timeout interval in seconds for stale device cleanup")
@@ -87,6 +103,15 @@ func (opt *connectorOptions) AddFlags(ff *flag.FlagSet) {
 	ff.IntVar(&opt.execCommandTimeout, "exec-command-timeout",
 		30,
 		"The timeout for running command on host")
+	ff.IntVar(&opt.fcPathDiscoveryTimeout, "fc-path-discovery-timeout",
+		defaultFCPathDiscoveryTimeout,
+		"The timeout in seconds for waiting for the FC paths which are not discovered yet, 0 means not waiting")
+	ff.IntVar(&opt.fcPathDiscoveryInterval, "fc-path-discovery-interval",
+		defaultFCPathDiscoveryInterval,
+		"The interval in seconds of rescanning the scsi bus when waiting for the FC paths")
+	ff.BoolVar(&opt.cleanupOrphanedMultipathMaps, "cleanup-orphaned-multipath-maps",
+		true,
+		"Whether to flush the DM-multipath maps whose paths are all gone when detaching volumes")
 }
 
 // ApplyFlags assign the connector flags
@@ -99,6 +124,9 @@ func (opt *connectorOptions) ApplyFlags(cfg *config.Config) {
 	cfg.ConnectorThreads = opt.connectorThreads
 	cfg.AllPathOnline = opt.allPathOnline
 	cfg.ExecCommandTimeout = opt.execCommandTimeout
+	cfg.FCPathDiscoveryTimeout = opt.fcPathDiscoveryTimeout
+	cfg.FCPathDiscoveryInterval = opt.fcPathDiscoveryInterval
+	cfg.CleanupOrphanedMultipathMaps = opt.cleanupOrphanedMultipathMaps
 }
 
 // ValidateFlags validate the connector flags
@@ -119,6 +147,11 @@ func (opt *connectorOptions) ValidateFlags() []error {
 		errs = append(errs, err)
 	}
 
+	err = opt.validateFCPathDiscovery()
+	if err != nil {
+		errs = append(errs, err)
+	}
+
 	return errs
 }
 
@@ -133,7 +166,7 @@ func (opt *connectorOptions) validateScsiMultiPathType() error {
 
 func (opt *connectorOptions) validateNvmeMultiPathType() error {
 	switch opt.nvmeMultiPathType {
-	case hwUltraPathNVMe:
+	case hwUltraPathNVMe, nativeNVMe:
 		return nil
 	default:
 		return fmt.Errorf("the nvme-multipath-type=%v configuration is incorrect", opt.nvmeMultiPathType)
@@ -148,6 +181,19 @@ func (opt *connectorOptions) validateScanVolumeTimeout() error {
 	return nil
 }
 
+func (opt *connectorOptions) validateFCPathDiscovery() error {
+	if opt.fcPathDiscoveryTimeout < 0 || opt.fcPathDiscoveryTimeout > 600 {
+		return fmt.Errorf("the value of fc-path-discovery-timeout ranges from 0 to 600, current is: %d",
+			opt.fcPathDiscoveryTimeout)
+	}
+
+	if opt.fcPathDiscoveryInterval < 1 || opt.fcPathDiscoveryInterval > 600 {
+		return fmt.Errorf("the value of fc-path-discovery-interval ranges from 1 to 600, current is: %d",
+			opt.fcPathDiscoveryInterval)
+	}
+	return nil
+}
+
 func (opt *connectorOptions) validateConnectorThreads() error {
 	if opt.connectorThreads < minThreads || opt.connectorThreads > maxThreads {
 		return fmt.Errorf("the connector-threads %d should be %d~%d",
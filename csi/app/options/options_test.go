@@ -23,6 +23,7 @@ import (
 	"os"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/agiledragon/gomonkey/v2"
 	"github.com/sirupsen/logrus"
@@ -89,6 +90,11 @@ func compareConnectorOptions(envCfg *config.Config) error {
 		deviceCleanupTimeout: defaultCleanupTimeout,
 		scanVolumeTimeout:    defaultScanVolumeTimeout,
 		connectorThreads:     defaultConnectorThreads,
+
+		fcPathDiscoveryTimeout:  defaultFCPathDiscoveryTimeout,
+		fcPathDiscoveryInterval: defaultFCPathDiscoveryInterval,
+
+		cleanupOrphanedMultipathMaps: envCfg.CleanupOrphanedMultipathMaps,
 	}
 
 	if !reflect.DeepEqual(expectConnectorOptions, actuallyConnectorOptions) {
@@ -97,3 +103,62 @@ func compareConnectorOptions(envCfg *config.Config) error {
 	}
 	return nil
 }
+
+func TestValidateLeaderLockType(t *testing.T) {
+	tests := []struct {
+		lockType string
+		wantErr  bool
+	}{
+		{lockType: "leases"},
+		{lockType: "configmapsleases"},
+		{lockType: "endpointsleases"},
+		{lockType: "configmaps", wantErr: true},
+		{lockType: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		opt := &serviceOptions{leaderLockType: tt.lockType}
+		if err := opt.validateLeaderLockType(); (err != nil) != tt.wantErr {
+			t.Errorf("validateLeaderLockType(%q) error = %v, wantErr %v", tt.lockType, err, tt.wantErr)
+		}
+	}
+}
+
+func TestValidateMaintenancePolicy(t *testing.T) {
+	tests := []struct {
+		policy  string
+		wantErr bool
+	}{
+		{policy: "ignore"},
+		{policy: "pause"},
+		{policy: "wait", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		opt := &serviceOptions{maintenancePolicy: tt.policy}
+		if err := opt.validateMaintenancePolicy(); (err != nil) != tt.wantErr {
+			t.Errorf("validateMaintenancePolicy(%q) error = %v, wantErr %v", tt.policy, err, tt.wantErr)
+		}
+	}
+}
+
+func TestValidateProviderKeepalive(t *testing.T) {
+	tests := []struct {
+		time    time.Duration
+		timeout time.Duration
+		wantErr bool
+	}{
+		{time: 30 * time.Second, timeout: 10 * time.Second},
+		{time: 10 * time.Second, timeout: time.Second},
+		{time: 5 * time.Second, timeout: 10 * time.Second, wantErr: true},
+		{time: 30 * time.Second, timeout: 0, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		opt := &serviceOptions{providerKeepaliveTime: tt.time, providerKeepaliveTimeout: tt.timeout}
+		if err := opt.validateProviderKeepalive(); (err != nil) != tt.wantErr {
+			t.Errorf("validateProviderKeepalive(%v, %v) error = %v, wantErr %v",
+				tt.time, tt.timeout, err, tt.wantErr)
+		}
+	}
+}
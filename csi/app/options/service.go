@@ -19,18 +19,72 @@ package options
 
 import (
 	"flag"
+	"fmt"
 	"os"
+	"strings"
 	"time"
 
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+
 	"huawei-csi-driver/csi/app/config"
 	"huawei-csi-driver/pkg/constants"
 )
 
+// gRPC clients do not ping more often than every 10s
+const minProviderKeepaliveTime = 10 * time.Second
+
 // serviceOptions include service's configuration
 type serviceOptions struct {
 	controller           bool
 	enableLeaderElection bool
 	enableLabel          bool
+	// enable detaching all volumes of a deleted node
+	enableNodeDeletionDetach bool
+	// delete the nfs shares of the released volumes whose reclaim policy is Retain
+	enableRetainedShareCleanup bool
+	// skip the snapshot space pre-check before creating snapshots
+	skipSnapshotSpaceCheck bool
+	// merge the default parameters of the namespace of the PVC into the StorageClass parameters
+	enableNamespaceDefaultParameters bool
+	// the period of waiting for the partially set manage annotations to be completed
+	manageAnnotationsGracePeriod time.Duration
+	// reject staging volumes when the node plugin is incompatible with the controller plugin
+	strictVersionCheck bool
+	// the number of active paths below which a multipath volume is reported as degraded
+	volumeConditionMinPaths int
+	// the configmap of backends to watch
+	backendConfigConfigmap string
+	// the controller capabilities disabled by the policy of this deployment
+	disableSnapshot bool
+	disableClone    bool
+	disableExpand   bool
+	// the period of collecting the simultaneous lun creations into one batch request
+	lunBatchCreateWindow time.Duration
+	// the policy of selecting one of the pools with equal free capacity
+	poolTieBreaker string
+	// the policy of the operations when the storage is in maintenance
+	maintenancePolicy string
+	// inject the W3C Trace Context headers into the requests to the storage
+	enableTracing         bool
+	tracingEndpoint       string
+	enableStorageCapacity bool
+	enableBackendTopology bool
+	// record the last exchanges with the storage and dump them when an operation fails
+	enableRequestRecording bool
+	requestRecordingSize   int
+	// the max period of waiting for the automatic expansion of a storage pool
+	poolExpandTimeout time.Duration
+	// the age of the snapshots which are tiered to the object storage
+	tierSnapshotsAfter time.Duration
+	// the ratio of the healthy pools below which the backend is not ready
+	minPoolHealthRatio float64
+	// the node environments which are known to not support expanding the attached fc-nvme volumes
+	unsupportedOnlineExpansion string
+	// the drift detection between the PVs and the volumes on the storage
+	driftDetectionInterval           time.Duration
+	driftDetectionSampleSize         int
+	driftDetectionQPS                float64
+	driftDetectionBackendConcurrency int
 
 	driverName       string
 	endpoint         string
@@ -40,15 +94,23 @@ type serviceOptions struct {
 	kubeletRootDir   string
 	volumeNamePrefix string
 
+	// the keep-alive of the gRPC connection from the storage-backend-sidecar to the DR-CSI provider
+	providerKeepaliveTime    time.Duration
+	providerKeepaliveTimeout time.Duration
+
 	maxVolumesPerNode     int
 	webHookPort           int
 	webHookAddress        string
+	metricsAddress        string
+	healthAddress         string
 	backendUpdateInterval int
 	workerThreads         int
 
 	leaderLeaseDuration time.Duration
 	leaderRenewDeadline time.Duration
 	leaderRetryPeriod   time.Duration
+	leaderLockType      string
+	leaderLockNamespace string
 	reSyncPeriod        time.Duration
 	timeout             time.Duration
 
@@ -67,6 +129,12 @@ func (opt *serviceOptions) AddFlags(ff *flag.FlagSet) {
 	ff.StringVar(&opt.drEndpoint, "dr-endpoint",
 		"/var/lib/kubelet/plugins/huawei.csi.driver/dr-csi.sock",
 		"DR CSI endpoint")
+	ff.DurationVar(&opt.providerKeepaliveTime, "provider-keepalive-time", 30*time.Second,
+		"The idle time after which the storage-backend-sidecar pings the DR-CSI provider to keep the connection "+
+			"alive, at least 10s")
+	ff.DurationVar(&opt.providerKeepaliveTimeout, "provider-keepalive-timeout", 10*time.Second,
+		"The time the storage-backend-sidecar waits for the ping ack of the DR-CSI provider before closing "+
+			"the connection")
 	ff.BoolVar(&opt.controller, "controller",
 		false, "Run as a controller service")
 	ff.StringVar(&opt.driverName, "driver-name",
@@ -85,12 +153,97 @@ func (opt *serviceOptions) AddFlags(ff *flag.FlagSet) {
 		"Prefix to apply to the name of a created volume.")
 	ff.IntVar(&opt.maxVolumesPerNode, "max-volumes-per-node", 0,
 		"The number of volumes that controller can publish to the node")
+	ff.IntVar(&opt.volumeConditionMinPaths, "volume-condition-min-paths", 0,
+		"The number of active paths below which a DM-multipath volume is reported as degraded in its volume "+
+			"condition, 0 means only the volume without any active path is reported")
 	ff.IntVar(&opt.webHookPort, "web-hook-port", 0,
 		"The port of webhook server")
 	ff.StringVar(&opt.webHookAddress, "web-hook-address", "",
 		"The Address of webhook server")
+	ff.StringVar(&opt.metricsAddress, "metrics-address", "",
+		"The address to serve the prometheus metrics at /metrics, empty means the metrics are not served")
+	ff.StringVar(&opt.healthAddress, "health-address", "",
+		"The address to serve the liveness probe at /healthz and the readiness probe at /readyz, "+
+			"empty means the probes are not served")
 	ff.BoolVar(&opt.enableLabel, "enable-label", false,
 		"csi enable label")
+	ff.BoolVar(&opt.enableNodeDeletionDetach, "enable-node-deletion-detach", false,
+		"Detach all volumes of the node and clean up its host on the storage when the node is deleted")
+	ff.BoolVar(&opt.enableRetainedShareCleanup, "enable-retained-share-cleanup", false,
+		"Delete the nfs share of the filesystem when its PV is released with the Retain reclaim policy, "+
+			"the filesystem and its data are kept, and the share is restored when the PV is bound again")
+	ff.BoolVar(&opt.skipSnapshotSpaceCheck, "skip-snapshot-space-check", false,
+		"Skip checking whether the snapshot space is exhausted before creating snapshots")
+	ff.BoolVar(&opt.enableNamespaceDefaultParameters, "enable-namespace-default-parameters", false,
+		"Merge the data of the configmaps labeled "+constants.NamespaceDefaultParametersLabel+"=true in the "+
+			"namespace of the PVC into the StorageClass parameters, the StorageClass wins on conflict")
+	ff.DurationVar(&opt.manageAnnotationsGracePeriod, "manage-annotations-grace-period", 0,
+		"The period since the PVC is created during which only one of the manage annotations being set is "+
+			"returned as a retryable error instead of a misconfiguration, 0 means failing immediately")
+	ff.BoolVar(&opt.strictVersionCheck, "strict-version-check", false,
+		"Reject staging volumes when the major version of the node plugin differs from the controller plugin "+
+			"by more than one")
+	ff.StringVar(&opt.backendConfigConfigmap, "backend-config-configmap", "",
+		"The configmap of backends which is watched to re-initialize the backends when it changes, "+
+			"format is <namespace>/<name>, the namespace of CSI is used if it is omitted")
+	ff.BoolVar(&opt.disableSnapshot, "disable-snapshot", false,
+		"Disable creating snapshots, and reject the VolumeSnapshotClasses of this driver at admission")
+	ff.BoolVar(&opt.disableClone, "disable-clone", false,
+		"Disable cloning volumes")
+	ff.BoolVar(&opt.disableExpand, "disable-expand", false,
+		"Disable expanding volumes, and reject the StorageClasses of this driver which allow volume expansion")
+	ff.DurationVar(&opt.lunBatchCreateWindow, "lun-batch-create-window", 0,
+		"The period of collecting the simultaneous lun creations of an OceanStor Dorado V6 backend into one "+
+			"batch request to the storage, such as 50ms, 0 means creating the luns one by one. The batch request "+
+			"is experimental as it is not described by the documented REST API of the storage")
+	ff.StringVar(&opt.poolTieBreaker, "pool-tie-breaker", constants.PoolTieBreakerLeastRecentlyUsed,
+		"The policy of selecting one of the storage pools with equal free capacity, lru selects the least "+
+			"recently selected pool, lexical selects the first pool by backend and pool name")
+	ff.StringVar(&opt.maintenancePolicy, "maintenance-policy", constants.MaintenancePolicyIgnore,
+		"The policy of the operations when the storage is in maintenance such as upgrading, ignore keeps "+
+			"running them, pause rejects creating, deleting, expanding and snapshotting the volumes until "+
+			"the maintenance ends")
+	ff.BoolVar(&opt.enableStorageCapacity, "enable-storage-capacity", false,
+		"Publish the free capacity of each storage pool by the CSIStorageCapacity objects in the namespace of "+
+			"the driver, so that the scheduler checks the capacity of the WaitForFirstConsumer volumes")
+	ff.BoolVar(&opt.enableBackendTopology, "enable-backend-topology", false,
+		"Label the nodes with the topology of each backend which is not denied by the csi.huawei.com/deny-backend, "+
+			"csi.huawei.com/allow-backend and csi.huawei.com/deny-protocol labels of the node, and require "+
+			"the label in the accessible topology of the volumes of the backend")
+	ff.BoolVar(&opt.enableTracing, "enable-tracing", false,
+		"Inject the W3C Trace Context headers traceparent and tracestate into the requests to the OceanStor "+
+			"storage, so that they can be correlated with the audit logs of the storage, and export the spans of "+
+			"the requests to the OTLP collector")
+	ff.StringVar(&opt.tracingEndpoint, "tracing-endpoint", "",
+		"The URL of the OTLP/HTTP collector which the spans are exported to when the tracing is enabled, such as "+
+			"http://otel-collector:4318, empty means the OTEL_EXPORTER_OTLP_* environment variables are used")
+	ff.BoolVar(&opt.enableRequestRecording, "enable-request-recording", false,
+		"Record the last requests to the OceanStor storage and their responses with the credentials masked, "+
+			"and dump them to the log file dir when an operation fails")
+	ff.IntVar(&opt.requestRecordingSize, "request-recording-size", 100,
+		"The number of the last requests recorded for each OceanStor backend")
+	ff.DurationVar(&opt.poolExpandTimeout, "pool-expand-timeout", 10*time.Minute,
+		"The max period of waiting for the automatic expansion of a storage pool of the backend whose "+
+			"autoExpandPool is true")
+	ff.DurationVar(&opt.tierSnapshotsAfter, "tier-snapshots-after", 0,
+		"The age after which the snapshots of the OceanStor SAN backends are tiered to the object storage, "+
+			"such as 720h, 0 means the snapshots are not tiered")
+	ff.Float64Var(&opt.minPoolHealthRatio, "min-pool-health-ratio", 0.5,
+		"The ratio of the healthy pools to all pools of a backend below which the Ready condition of its "+
+			"StorageBackendContent is set to false, between 0 and 1")
+	ff.StringVar(&opt.unsupportedOnlineExpansion, "unsupported-online-expansion", "",
+		"The comma separated node environments which do not support expanding the attached fc-nvme volumes, "+
+			"each is <nvme multipath type>:<kernel version prefix>[:<UltraPath version prefix>], such as "+
+			"HW-UltraPath-NVMe:4.18.0-80:31.0, the expansion of the volumes attached to these nodes is rejected")
+	ff.DurationVar(&opt.driftDetectionInterval, "drift-detection-interval", 0,
+		"The interval of comparing a sample of the PVs with their volumes on the storage, "+
+			"0 means the drift is not detected")
+	ff.IntVar(&opt.driftDetectionSampleSize, "drift-detection-sample-size", 50,
+		"The max number of the PVs compared with their volumes in each drift detection")
+	ff.Float64Var(&opt.driftDetectionQPS, "drift-detection-qps", 1,
+		"The max number of the volumes queried from the storage per second by the drift detection")
+	ff.IntVar(&opt.driftDetectionBackendConcurrency, "drift-detection-backend-concurrency", 2,
+		"The max number of the concurrent volume queries to each backend by the drift detection")
 	ff.BoolVar(&opt.enableLeaderElection, "enable-leader-election", false,
 		"backend enable leader election")
 	ff.DurationVar(&opt.leaderLeaseDuration, "leader-lease-duration", 8*time.Second,
@@ -99,6 +252,11 @@ func (opt *serviceOptions) AddFlags(ff *flag.FlagSet) {
 		"backend leader renew deadline")
 	ff.DurationVar(&opt.leaderRetryPeriod, "leader-retry-period", 2*time.Second,
 		"backend leader retry period")
+	ff.StringVar(&opt.leaderLockType, "leader-lock-type", resourcelock.ConfigMapsLeasesResourceLock,
+		"The type of the resource lock used for leader election, one of leases, configmapsleases and "+
+			"endpointsleases")
+	ff.StringVar(&opt.leaderLockNamespace, "leader-lock-namespace", "",
+		"The namespace of the resource lock used for leader election, empty means the namespace of the CSI")
 	ff.DurationVar(&opt.reSyncPeriod, "re-sync-period", 2*time.Minute, "reSync interval of the controller")
 	ff.IntVar(&opt.workerThreads, "worker-threads", 10, "number of worker threads.")
 	ff.DurationVar(&opt.timeout, "timeout", 1*time.Minute, "timeout for any RPCs")
@@ -110,7 +268,36 @@ func (opt *serviceOptions) AddFlags(ff *flag.FlagSet) {
 func (opt *serviceOptions) ApplyFlags(cfg *config.Config) {
 	cfg.Endpoint = opt.endpoint
 	cfg.DrEndpoint = opt.drEndpoint
+	cfg.ProviderKeepaliveTime = opt.providerKeepaliveTime
+	cfg.ProviderKeepaliveTimeout = opt.providerKeepaliveTimeout
 	cfg.EnableLabel = opt.enableLabel
+	cfg.EnableNodeDeletionDetach = opt.enableNodeDeletionDetach
+	cfg.EnableRetainedShareCleanup = opt.enableRetainedShareCleanup
+	cfg.SkipSnapshotSpaceCheck = opt.skipSnapshotSpaceCheck
+	cfg.EnableNamespaceDefaultParameters = opt.enableNamespaceDefaultParameters
+	cfg.ManageAnnotationsGracePeriod = opt.manageAnnotationsGracePeriod
+	cfg.StrictVersionCheck = opt.strictVersionCheck
+	cfg.BackendConfigConfigmap = opt.backendConfigConfigmap
+	cfg.DisableSnapshot = opt.disableSnapshot
+	cfg.DisableClone = opt.disableClone
+	cfg.DisableExpand = opt.disableExpand
+	cfg.LunBatchCreateWindow = opt.lunBatchCreateWindow
+	cfg.PoolTieBreaker = opt.poolTieBreaker
+	cfg.MaintenancePolicy = opt.maintenancePolicy
+	cfg.EnableTracing = opt.enableTracing
+	cfg.TracingEndpoint = opt.tracingEndpoint
+	cfg.EnableStorageCapacity = opt.enableStorageCapacity
+	cfg.EnableBackendTopology = opt.enableBackendTopology
+	cfg.EnableRequestRecording = opt.enableRequestRecording
+	cfg.RequestRecordingSize = opt.requestRecordingSize
+	cfg.PoolExpandTimeout = opt.poolExpandTimeout
+	cfg.TierSnapshotsAfter = opt.tierSnapshotsAfter
+	cfg.MinPoolHealthRatio = opt.minPoolHealthRatio
+	cfg.UnsupportedOnlineExpansion = opt.unsupportedOnlineExpansion
+	cfg.DriftDetectionInterval = opt.driftDetectionInterval
+	cfg.DriftDetectionSampleSize = opt.driftDetectionSampleSize
+	cfg.DriftDetectionQPS = opt.driftDetectionQPS
+	cfg.DriftDetectionBackendConcurrency = opt.driftDetectionBackendConcurrency
 	cfg.Controller = opt.controller
 	cfg.DriverName = opt.driverName
 	cfg.BackendUpdateInterval = opt.backendUpdateInterval
@@ -119,12 +306,17 @@ func (opt *serviceOptions) ApplyFlags(cfg *config.Config) {
 	cfg.KubeletRootDir = opt.kubeletRootDir
 	cfg.VolumeNamePrefix = opt.volumeNamePrefix
 	cfg.MaxVolumesPerNode = opt.maxVolumesPerNode
+	cfg.VolumeConditionMinPaths = opt.volumeConditionMinPaths
 	cfg.WebHookPort = opt.webHookPort
 	cfg.WebHookAddress = opt.webHookAddress
+	cfg.MetricsAddress = opt.metricsAddress
+	cfg.HealthAddress = opt.healthAddress
 	cfg.EnableLeaderElection = opt.enableLeaderElection
 	cfg.LeaderRetryPeriod = opt.leaderRetryPeriod
 	cfg.LeaderLeaseDuration = opt.leaderLeaseDuration
 	cfg.LeaderRenewDeadline = opt.leaderRenewDeadline
+	cfg.LeaderLockType = opt.leaderLockType
+	cfg.LeaderLockNamespace = opt.leaderLockNamespace
 	cfg.ReSyncPeriod = opt.reSyncPeriod
 	cfg.WorkerThreads = opt.workerThreads
 	cfg.Timeout = opt.timeout
@@ -133,5 +325,100 @@ func (opt *serviceOptions) ApplyFlags(cfg *config.Config) {
 
 // ValidateFlags validate the service flags
 func (opt *serviceOptions) ValidateFlags() []error {
+	errs := make([]error, 0)
+	err := opt.validatePoolTieBreaker()
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	err = opt.validateLeaderLockType()
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	err = opt.validateMaintenancePolicy()
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	if opt.minPoolHealthRatio < 0 || opt.minPoolHealthRatio > 1 {
+		errs = append(errs, fmt.Errorf("the min-pool-health-ratio=%v configuration is incorrect, "+
+			"it must be between 0 and 1", opt.minPoolHealthRatio))
+	}
+
+	err = opt.validateProviderKeepalive()
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	err = opt.validateUnsupportedOnlineExpansion()
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	if opt.driftDetectionInterval > 0 && (opt.driftDetectionSampleSize < 1 || opt.driftDetectionQPS <= 0 ||
+		opt.driftDetectionBackendConcurrency < 1) {
+		errs = append(errs, fmt.Errorf("the drift-detection-sample-size=%d, drift-detection-qps=%v and "+
+			"drift-detection-backend-concurrency=%d configurations are incorrect, they must be positive",
+			opt.driftDetectionSampleSize, opt.driftDetectionQPS, opt.driftDetectionBackendConcurrency))
+	}
+
+	return errs
+}
+
+func (opt *serviceOptions) validateLeaderLockType() error {
+	switch opt.leaderLockType {
+	case resourcelock.LeasesResourceLock, resourcelock.ConfigMapsLeasesResourceLock,
+		resourcelock.EndpointsLeasesResourceLock:
+		return nil
+	default:
+		return fmt.Errorf("the leader-lock-type=%v configuration is incorrect, it must be one of %s, %s and %s",
+			opt.leaderLockType, resourcelock.LeasesResourceLock, resourcelock.ConfigMapsLeasesResourceLock,
+			resourcelock.EndpointsLeasesResourceLock)
+	}
+}
+
+func (opt *serviceOptions) validateMaintenancePolicy() error {
+	switch opt.maintenancePolicy {
+	case constants.MaintenancePolicyIgnore, constants.MaintenancePolicyPause:
+		return nil
+	default:
+		return fmt.Errorf("the maintenance-policy=%v configuration is incorrect", opt.maintenancePolicy)
+	}
+}
+
+func (opt *serviceOptions) validateProviderKeepalive() error {
+	if opt.providerKeepaliveTime < minProviderKeepaliveTime || opt.providerKeepaliveTimeout <= 0 {
+		return fmt.Errorf("the provider-keepalive-time=%v and provider-keepalive-timeout=%v configurations "+
+			"are incorrect, the time must be at least %v and the timeout must be positive",
+			opt.providerKeepaliveTime, opt.providerKeepaliveTimeout, minProviderKeepaliveTime)
+	}
+
+	return nil
+}
+
+func (opt *serviceOptions) validateUnsupportedOnlineExpansion() error {
+	if opt.unsupportedOnlineExpansion == "" {
+		return nil
+	}
+
+	for _, entry := range strings.Split(opt.unsupportedOnlineExpansion, ",") {
+		fields := strings.Split(strings.TrimSpace(entry), ":")
+		if len(fields) < 2 || len(fields) > 3 || fields[0] == "" {
+			return fmt.Errorf("the unsupported-online-expansion=%v configuration is incorrect, each entry must "+
+				"be <nvme multipath type>:<kernel version prefix>[:<UltraPath version prefix>]",
+				opt.unsupportedOnlineExpansion)
+		}
+	}
+
 	return nil
 }
+
+func (opt *serviceOptions) validatePoolTieBreaker() error {
+	switch opt.poolTieBreaker {
+	case constants.PoolTieBreakerLeastRecentlyUsed, constants.PoolTieBreakerLexical:
+		return nil
+	default:
+		return fmt.Errorf("the pool-tie-breaker=%v configuration is incorrect", opt.poolTieBreaker)
+	}
+}
@@ -26,11 +26,14 @@ import (
 	"strconv"
 	"strings"
 
+	"k8s.io/apimachinery/pkg/util/validation"
+
 	v1 "huawei-csi-driver/client/apis/xuanwu/v1"
 	"huawei-csi-driver/csi/app"
 	"huawei-csi-driver/csi/backend/cache"
 	"huawei-csi-driver/csi/backend/model"
 	"huawei-csi-driver/csi/backend/plugin"
+	"huawei-csi-driver/pkg/constants"
 	pkgUtils "huawei-csi-driver/pkg/utils"
 	fsUtils "huawei-csi-driver/storage/fusionstorage/utils"
 	"huawei-csi-driver/utils"
@@ -43,6 +46,12 @@ const (
 	Topology = "topology"
 	// supported topology key in CSI plugin configuration
 	supportedTopologiesKey = "supportedTopologies"
+	// the max concurrent snapshot operations key in CSI plugin configuration
+	maxSnapshotThreadsKey = "maxSnapshotThreads"
+	// the read-only mode key in CSI plugin configuration
+	readOnlyKey = "readOnly"
+	// the PriorityClass to qos mapping key in the parameters of CSI plugin configuration
+	priorityClassToQoSKey = "priorityClassToQoS"
 	// NoAvailablePool message of no available poll error
 	NoAvailablePool = "no storage pool meets the requirements"
 )
@@ -68,6 +77,9 @@ var (
 		{"sourceVolumeName", filterBySupportClone},
 		{"sourceSnapshotName", filterBySupportClone},
 		{"nfsProtocol", filterByNFSProtocol},
+		{"encrypted", filterByEncryption},
+		{"deduplication", filterByDeduplication},
+		{"poolTags", filterByPoolTags},
 	}
 
 	// SecondaryFilterFuncs secondary filters' function map
@@ -77,6 +89,8 @@ var (
 		{"qos", filterByQos},
 		{"replication", filterByReplication},
 		{"applicationType", filterByApplicationType},
+		{"encrypted", filterByEncryption},
+		{"deduplication", filterByDeduplication},
 	}
 )
 
@@ -167,6 +181,10 @@ func BuildBackend(ctx context.Context, content v1.StorageBackendContent) (*model
 		return nil, err
 	}
 
+	if app.GetGlobalConfig().EnableBackendTopology {
+		addBackendTopology(ctx, bk, app.GetGlobalConfig().DriverName)
+	}
+
 	err = bk.Plugin.Init(ctx, config, bk.Parameters, true)
 	if err != nil {
 		return nil, err
@@ -208,6 +226,7 @@ func NewBackend(backendName string, config map[string]interface{}) (*model.Backe
 	replicaBackend, _ := config["replicaBackend"].(string)
 	metroBackend, _ := config["metroBackend"].(string)
 	accountName, _ := config["accountName"].(string)
+	readOnly, _ := config[readOnlyKey].(bool)
 
 	// while config hyperMetro, the metroBackend must config, hyperMetroDomain or metrovStorePairID should be config
 	if ((metroDomain != "" || metrovStorePairID != "") && metroBackend == "") ||
@@ -215,6 +234,15 @@ func NewBackend(backendName string, config map[string]interface{}) (*model.Backe
 		return nil, fmt.Errorf("hyperMetro configuration in backend %s is incorrect", backendName)
 	}
 
+	var maxSnapshotThreads int
+	if value, exist := config[maxSnapshotThreadsKey]; exist {
+		maxSnapshotThreads, err = utils.TransToInt(value)
+		if err != nil || maxSnapshotThreads < 0 {
+			return nil, fmt.Errorf("%s [%v] in backend %s is invalid", maxSnapshotThreadsKey, value,
+				backendName)
+		}
+	}
+
 	return &model.Backend{
 		Name:                backendName,
 		Storage:             storage,
@@ -227,6 +255,11 @@ func NewBackend(backendName string, config map[string]interface{}) (*model.Backe
 		ReplicaBackendName:  replicaBackend,
 		MetroBackendName:    metroBackend,
 		AccountName:         accountName,
+		SnapshotLimiter:     model.NewSnapshotLimiter(backendName, maxSnapshotThreads),
+		PoolHealth:          model.NewPoolHealthAggregator(),
+		CapacityTrend:       model.NewCapacityTrendRecorder(),
+		Maintenance:         model.NewMaintenanceState(),
+		ReadOnly:            readOnly,
 	}, nil
 }
 
@@ -304,6 +337,28 @@ func addProtocolTopology(backend *model.Backend, driverName string) error {
 	return nil
 }
 
+// addBackendTopology requires the backend label in each supported topology, so that the volumes of the backend
+// are only accessible by the nodes which are labeled as allowed to use the backend. The backend without any
+// supported topology gets the backend segment alone.
+func addBackendTopology(ctx context.Context, backend *model.Backend, driverName string) {
+	backendTopologyKey := k8sutils.BackendTopologyPrefix + backend.Name
+	// the nodes are not labeled with the invalid label key, see getBackendNodeLabelChanges
+	if len(validation.IsQualifiedName(backendTopologyKey)) != 0 {
+		log.AddContext(ctx).Warningf("Backend name %s is not valid in the label key %s, the volumes of the backend are not "+
+			"restricted by the node labels", backend.Name, backendTopologyKey)
+		return
+	}
+
+	if len(backend.SupportedTopologies) == 0 {
+		backend.SupportedTopologies = []map[string]string{{backendTopologyKey: driverName}}
+		return
+	}
+
+	for _, supportedTopology := range backend.SupportedTopologies {
+		supportedTopology[backendTopologyKey] = driverName
+	}
+}
+
 // GetMetroDomain get metro domain of backend
 func GetMetroDomain(backendName string) string {
 	bk, exists := cache.BackendCacheProvider.Load(backendName)
@@ -331,27 +386,43 @@ func GetAccountName(backendName string) string {
 	return bk.AccountName
 }
 
-// FilterStoragePool filter storage pool by capability, topology and capacity.
-func FilterStoragePool(ctx context.Context, requestSize int64, parameters map[string]interface{},
-	candidatePools []*model.StoragePool, filterFuncs [][]interface{}) ([]*model.StoragePool, error) {
-	// filter the storage pools by capability
-	filterPools, err := FilterByCapability(ctx, parameters, candidatePools, filterFuncs)
-	if err != nil {
-		return nil, fmt.Errorf("failed to select pool, the capability filter failed, error: %v."+
-			" please check your storage class", err)
+// GetDefaultParameters returns the default StorageClass parameters of the backend
+func GetDefaultParameters(backendName string) map[string]string {
+	bk, exists := cache.BackendCacheProvider.Load(backendName)
+	if !exists {
+		return nil
 	}
+	return bk.DefaultParameters
+}
 
-	// filter the storage by topology
-	filterPools, err = FilterByTopology(parameters, filterPools)
-	if err != nil {
-		return nil, err
+// GetPriorityClassQoS returns the qos parameters which the backend maps the PriorityClasses to,
+// the key is the PriorityClass name and the value is the qos parameter in JSON
+func GetPriorityClassQoS(backendName string) map[string]string {
+	bk, exists := cache.BackendCacheProvider.Load(backendName)
+	if !exists {
+		return nil
 	}
 
-	allocType, _ := parameters["allocType"].(string)
-	// filter the storage pool by capacity
-	filterPools = FilterByCapacity(requestSize, allocType, filterPools)
-	if len(filterPools) == 0 {
-		return nil, fmt.Errorf("failed to select pool, the capacity filter failed, capacity: %d", requestSize)
+	mapping, ok := bk.Parameters[priorityClassToQoSKey].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	qos := make(map[string]string, len(mapping))
+	for priorityClass, value := range mapping {
+		if str, ok := value.(string); ok && str != "" {
+			qos[priorityClass] = str
+		}
+	}
+	return qos
+}
+
+// FilterStoragePool filter storage pool by capability, topology and capacity.
+func FilterStoragePool(ctx context.Context, requestSize int64, parameters map[string]interface{},
+	candidatePools []*model.StoragePool, filterFuncs [][]interface{}) ([]*model.StoragePool, error) {
+	filterPools, err := FilterPools(ctx, requestSize, parameters, candidatePools, filterFuncs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select pool, error: %v. please check your storage class", err)
 	}
 
 	return filterPools, nil
@@ -404,18 +475,28 @@ func SelectRemotePool(ctx context.Context, requestSize int64, parameters map[str
 	return remotePool, err
 }
 
-// WeightSinglePools select the optimal storage pool based on the free capacity.
+// WeightSinglePools select the optimal storage pool based on the free capacity, the pools with equal
+// free capacity are selected by the configured tie breaker.
 func WeightSinglePools(
 	ctx context.Context,
 	requestSize int64,
 	parameters map[string]interface{},
 	filterPools []*model.StoragePool) (*model.StoragePool, error) {
+	// avoid the pools already holding the volumes of the same placement group
+	group := GetPlacementGroup(parameters)
+	spreadPools := spreadByPlacementGroup(group, filterPools)
+	if len(spreadPools) < len(filterPools) {
+		log.AddContext(ctx).Infof("Placement group %s keeps %d of %d storage pools",
+			group, len(spreadPools), len(filterPools))
+	}
+
 	// weight the storage pool by free capacity
 	var selectPool *model.StoragePool
-	selectPool = weightByFreeCapacity(filterPools)
+	selectPool = weightByFreeCapacity(spreadPools)
 	if selectPool == nil {
 		return nil, fmt.Errorf("cannot select a storage pool for volume (%d, %v)", requestSize, parameters)
 	}
+	poolSelections.record(selectPool)
 
 	log.AddContext(ctx).Infof("Select storage pool %s:%s for volume (%d, %v)",
 		selectPool.Parent, selectPool.Name, requestSize, parameters)
@@ -448,8 +529,8 @@ func updateSelectPool(requestSize int64, parameters map[string]interface{}, sele
 	allocType, _ := parameters["allocType"].(string)
 	// when the allocType is thin, do not change the FreeCapacity.
 	if allocType == "thick" {
-		freeCapacity := utils.ParseIntWithDefault(selectPool.Capacities["FreeCapacity"], 10, 64, 0)
-		selectPool.Capacities["FreeCapacity"] = strconv.FormatInt(int64(rune(freeCapacity-requestSize)), 10)
+		freeCapacity := utils.ParseIntWithDefault(selectPool.GetCapacity("FreeCapacity"), 10, 64, 0)
+		selectPool.SetCapacity("FreeCapacity", strconv.FormatInt(int64(rune(freeCapacity-requestSize)), 10))
 	}
 }
 
@@ -629,12 +710,12 @@ func FilterByTopology(parameters map[string]interface{}, candidatePools []*model
 		return nil, errors.New("AccessibleTopology type is expected in topology parameters")
 	}
 
-	if len(topology.RequisiteTopologies) == 0 {
-		return candidatePools, nil
+	filterPools, err := filterByTopology(parameters, candidatePools)
+	if err != nil {
+		return nil, err
 	}
 
-	filterPools := filterPoolsOnTopology(candidatePools, topology.RequisiteTopologies)
-	if len(filterPools) == 0 {
+	if len(filterPools) == 0 && len(topology.RequisiteTopologies) != 0 {
 		// filter out candidate pools info
 		logCandidatePool := make([]string, 0)
 		for _, pool := range candidatePools {
@@ -643,6 +724,28 @@ func FilterByTopology(parameters map[string]interface{}, candidatePools []*model
 		return nil, fmt.Errorf("no pool support by requisite topologies [%v] from candidate pools [%v]",
 			topology.RequisiteTopologies, logCandidatePool)
 	}
+	return filterPools, nil
+}
+
+// filterByTopology returns the pools which support any of the requisite topologies sorted by the preferred
+// topologies, the empty result is not an error
+func filterByTopology(parameters map[string]interface{}, candidatePools []*model.StoragePool) (
+	[]*model.StoragePool, error) {
+	iTopology, topologyAvailable := parameters[Topology]
+	if !topologyAvailable {
+		return candidatePools, nil
+	}
+
+	topology, ok := iTopology.(AccessibleTopology)
+	if !ok {
+		return nil, errors.New("AccessibleTopology type is expected in topology parameters")
+	}
+
+	if len(topology.RequisiteTopologies) == 0 {
+		return candidatePools, nil
+	}
+
+	filterPools := filterPoolsOnTopology(candidatePools, topology.RequisiteTopologies)
 	return sortPoolsByPreferredTopologies(filterPools, topology.PreferredTopologies), nil
 }
 
@@ -668,12 +771,16 @@ func isTopologySupportedByBackend(backend *model.Backend, topology map[string]st
 		}
 
 		for k, v := range topology {
+			// the node labels of the other backends do not restrict this backend
+			if strings.HasPrefix(k, k8sutils.BackendTopologyPrefix) {
+				continue
+			}
 			if sup, ok := supported[k]; !ok || (sup != v) {
 				eachFound = false
 				break
 			}
 		}
-		if eachFound {
+		if eachFound && checkBackendSupport(supported, topology) {
 			requisiteFound = true
 			break
 		}
@@ -707,6 +814,29 @@ func checkProtocolSupport(supportedTopology, protocols map[string]string) bool {
 	return false
 }
 
+// checkBackendSupport returns whether the topology has the backend label required by the supported topology,
+// the topology without any backend label is not restricted, as the topology keys reported by the node plugin
+// may not include the backend labels added after the node plugin registered
+func checkBackendSupport(supportedTopology, topology map[string]string) bool {
+	hasBackendLabel := false
+	for key := range topology {
+		if strings.HasPrefix(key, k8sutils.BackendTopologyPrefix) {
+			hasBackendLabel = true
+			break
+		}
+	}
+	if !hasBackendLabel {
+		return true
+	}
+
+	for key, value := range supportedTopology {
+		if strings.HasPrefix(key, k8sutils.BackendTopologyPrefix) && topology[key] != value {
+			return false
+		}
+	}
+	return true
+}
+
 // filterPoolsOnTopology returns a subset of the provided pools that can support any of the requisiteTopologies.
 func filterPoolsOnTopology(candidatePools []*model.StoragePool,
 	requisiteTopologies []map[string]string) []*model.StoragePool {
@@ -790,28 +920,18 @@ func sortPoolsByPreferredTopologies(candidatePools []*model.StoragePool,
 // FilterByCapability filter backend by capability
 func FilterByCapability(ctx context.Context, parameters map[string]interface{}, candidatePools []*model.StoragePool,
 	filterFuncs [][]interface{}) ([]*model.StoragePool, error) {
+	summary := NewPoolFilterSummary(len(candidatePools))
+	filterPools, err := filterByCapability(ctx, parameters, candidatePools, filterFuncs, summary)
+	if err != nil {
+		return nil, fmt.Errorf("Filter pool by capability failed, paramters: [%v], error: [%v].", parameters, err)
+	}
 
-	var err error
-	for _, i := range filterFuncs {
-		key, filter := i[0].(string), i[1].(func(context.Context, string, []*model.StoragePool) ([]*model.StoragePool,
-			error))
-		value, _ := parameters[key].(string)
-		candidatePools, err = filter(ctx, value, candidatePools)
-		if err != nil {
-			msg := fmt.Sprintf("Filter pool by capability failed, filter field: [%s], fileter function: [%s], "+
-				"paramters: [%v], error: [%v].",
-				value, runtime.FuncForPC(reflect.ValueOf(filter).Pointer()).Name(), parameters, err)
-			return nil, errors.New(msg)
-		}
-		if len(candidatePools) == 0 {
-			msg := fmt.Sprintf("%s. Please check the storage class. The final filter field: %s, "+
-				"filter function: %s, parameters %v.", NoAvailablePool, value,
-				runtime.FuncForPC(reflect.ValueOf(filter).Pointer()).Name(), parameters)
-			return nil, errors.New(msg)
-		}
+	if len(filterPools) == 0 {
+		return nil, fmt.Errorf("%s. Please check the storage class. %s, parameters %v.",
+			NoAvailablePool, summary, parameters)
 	}
 
-	return candidatePools, nil
+	return filterPools, nil
 }
 
 func filterByNFSProtocol(ctx context.Context, nfsProtocol string, candidatePools []*model.StoragePool) (
@@ -848,46 +968,112 @@ func filterBySupportClone(ctx context.Context, cloneSource string, candidatePool
 	return filterPools, nil
 }
 
-// FilterByCapacity filter backend by capacity
-func FilterByCapacity(requestSize int64, allocType string, candidatePools []*model.StoragePool) []*model.StoragePool {
+// filterByEncryption filters the pools which support array-side encryption if the encrypted volume is requested
+func filterByEncryption(ctx context.Context, encrypted string, candidatePools []*model.StoragePool) (
+	[]*model.StoragePool, error) {
+	if encrypted == "" || !utils.StrToBool(ctx, encrypted) {
+		return candidatePools, nil
+	}
+
 	var filterPools []*model.StoragePool
 	for _, pool := range candidatePools {
-		supportThin, thinExist := pool.Capabilities["SupportThin"]
-		if !thinExist {
-			log.Warningf("convert supportThin to bool failed, data: %v", pool.Capabilities["SupportThin"])
+		if pool.Capabilities[string(constants.SupportEncryption)] {
+			filterPools = append(filterPools, pool)
 		}
-		supportThick, thickExist := pool.Capabilities["SupportThick"]
-		if !thickExist {
-			log.Warningf("convert supportThick to bool failed, data: %v", pool.Capabilities["SupportThick"])
+	}
+	return filterPools, nil
+}
+
+// filterByDeduplication filters the pools which support toggling the deduplication per filesystem if the
+// deduplicated volume is requested
+func filterByDeduplication(ctx context.Context, deduplication string, candidatePools []*model.StoragePool) (
+	[]*model.StoragePool, error) {
+	if deduplication == "" || !utils.StrToBool(ctx, deduplication) {
+		return candidatePools, nil
+	}
+
+	var filterPools []*model.StoragePool
+	for _, pool := range candidatePools {
+		if pool.Capabilities[string(constants.SupportPerFsDedup)] {
+			filterPools = append(filterPools, pool)
 		}
-		if (allocType == "thin" || allocType == "") && thinExist && supportThin {
+	}
+	return filterPools, nil
+}
+
+// filterByPoolTags filters the pools whose tags configured by the poolTags of their backend include all the
+// requested tags, the tags are matched once for each backend, and the pools of the backends which fail to query are skipped
+func filterByPoolTags(ctx context.Context, poolTags string, candidatePools []*model.StoragePool) (
+	[]*model.StoragePool, error) {
+	if poolTags == "" {
+		return candidatePools, nil
+	}
+
+	taggedPools := make(map[string]map[string]bool)
+	var filterPools []*model.StoragePool
+	for _, pool := range candidatePools {
+		names, queried := taggedPools[pool.Parent]
+		if !queried {
+			names = getTaggedPoolNames(ctx, pool, poolTags)
+			taggedPools[pool.Parent] = names
+		}
+
+		if names[pool.Name] {
 			filterPools = append(filterPools, pool)
-		} else if allocType == "thick" && thickExist && supportThick {
-			freeCapacity := utils.ParseIntWithDefault(pool.GetCapacities()["FreeCapacity"], 10, 64, 0)
-			if requestSize <= freeCapacity {
-				filterPools = append(filterPools, pool)
-			}
 		}
 	}
 
-	return filterPools
+	return filterPools, nil
+}
+
+func getTaggedPoolNames(ctx context.Context, pool *model.StoragePool, poolTags string) map[string]bool {
+	selector, ok := pool.Plugin.(plugin.PoolTagSelector)
+	if !ok {
+		log.AddContext(ctx).Debugf("Backend %s does not support pool tags", pool.Parent)
+		return nil
+	}
+
+	poolNames, err := selector.SelectPoolsByTags(ctx, poolTags)
+	if err != nil {
+		log.AddContext(ctx).Warningf("Select pools of backend %s by tags %s failed, error: %v",
+			pool.Parent, poolTags, err)
+		return nil
+	}
+
+	names := make(map[string]bool, len(poolNames))
+	for _, name := range poolNames {
+		names[name] = true
+	}
+	return names
+}
+
+// FilterByCapacity filter backend by capacity
+func FilterByCapacity(requestSize int64, allocType string, candidatePools []*model.StoragePool) []*model.StoragePool {
+	return filterByCapacity(requestSize, allocType, candidatePools, nil)
 }
 
 func weightByFreeCapacity(candidatePools []*model.StoragePool) *model.StoragePool {
-	var selectPool *model.StoragePool
+	var maxPools []*model.StoragePool
+	var maxCapacity int64
 
 	for _, pool := range candidatePools {
-		if selectPool == nil {
-			selectPool = pool
-		} else {
-			selectCapacity := utils.ParseIntWithDefault(selectPool.GetCapacities()["FreeCapacity"], 10, 64, 0)
-			curFreeCapacity := utils.ParseIntWithDefault(pool.GetCapacities()["FreeCapacity"], 10, 64, 0)
-			if selectCapacity < curFreeCapacity {
-				selectPool = pool
-			}
+		curFreeCapacity := utils.ParseIntWithDefault(pool.GetCapacities()["FreeCapacity"], 10, 64, 0)
+		if len(maxPools) == 0 || maxCapacity < curFreeCapacity {
+			maxPools = []*model.StoragePool{pool}
+			maxCapacity = curFreeCapacity
+		} else if maxCapacity == curFreeCapacity {
+			maxPools = append(maxPools, pool)
 		}
 	}
-	return selectPool
+
+	switch len(maxPools) {
+	case 0:
+		return nil
+	case 1:
+		return maxPools[0]
+	default:
+		return breakPoolTie(maxPools, getPoolTieBreaker())
+	}
 }
 
 func filterByApplicationType(ctx context.Context, appType string, candidatePools []*model.StoragePool) (
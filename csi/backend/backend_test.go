@@ -29,6 +29,7 @@ import (
 	cfg "huawei-csi-driver/csi/app/config"
 	"huawei-csi-driver/csi/backend/cache"
 	"huawei-csi-driver/csi/backend/model"
+	"huawei-csi-driver/pkg/constants"
 	"huawei-csi-driver/utils/log"
 )
 
@@ -437,6 +438,25 @@ func TestFilterByMetroParentNotExist(t *testing.T) {
 	}
 }
 
+func TestFilterByMetroWithoutMetroBackend(t *testing.T) {
+	load := gomonkey.ApplyMethod(reflect.TypeOf(&cache.BackendCache{}), "Load",
+		func(_ *cache.BackendCache, backendName string) (model.Backend, bool) {
+			return model.Backend{Name: "testBackend1"}, true
+		})
+	defer load.Reset()
+
+	candidatePools := []*model.StoragePool{
+		{
+			Name:         "pool1",
+			Capabilities: map[string]bool{"SupportMetro": true},
+			Parent:       "testBackend1"}}
+
+	got, _ := filterByMetro(ctx, "true", candidatePools)
+	if len(got) != 0 {
+		t.Errorf("test filterByMetro faild. got: %v, expect no pool", got)
+	}
+}
+
 func TestFilterByReplicationNormal(t *testing.T) {
 	load := gomonkey.ApplyMethod(reflect.TypeOf(&cache.BackendCache{}), "Load",
 		func(_ *cache.BackendCache, backendName string) (model.Backend, bool) {
@@ -622,6 +642,30 @@ func TestFilterBySupportClone(t *testing.T) {
 	}
 }
 
+func TestFilterByEncryption(t *testing.T) {
+	pools := []*model.StoragePool{
+		{Capabilities: map[string]bool{"SupportEncryption": true}},
+		{Capabilities: map[string]bool{"SupportEncryption": false}},
+		{Capabilities: map[string]bool{}}}
+	tests := []struct {
+		name      string
+		encrypted string
+		expect    int64
+	}{
+		{"Encrypted", "true", 1},
+		{"NotEncrypted", "false", 3},
+		{"EncryptedEmpty", "", 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, _ := filterByEncryption(ctx, tt.encrypted, pools); int64(len(got)) != tt.expect {
+				t.Errorf("test filterByEncryption faild. got: %v expect: %v", len(got), tt.expect)
+			}
+		})
+	}
+}
+
 func TestFilterByCapacity(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -705,6 +749,40 @@ func TestWeightByFreeCapacity(t *testing.T) {
 	}
 }
 
+func TestBreakPoolTie(t *testing.T) {
+	poolA := &model.StoragePool{Parent: "backend", Name: "pool-a"}
+	poolB := &model.StoragePool{Parent: "backend", Name: "pool-b"}
+	poolC := &model.StoragePool{Parent: "backend", Name: "pool-c"}
+
+	tests := []struct {
+		name       string
+		tieBreaker string
+		selected   []*model.StoragePool
+		expect     *model.StoragePool
+	}{
+		{"LexicalIgnoresSelections", constants.PoolTieBreakerLexical, []*model.StoragePool{poolA}, poolA},
+		{"LRUNeverSelectedFirst", constants.PoolTieBreakerLeastRecentlyUsed,
+			[]*model.StoragePool{poolA, poolB}, poolC},
+		{"LRULeastRecentlySelected", constants.PoolTieBreakerLeastRecentlyUsed,
+			[]*model.StoragePool{poolA, poolB, poolC, poolB, poolA}, poolC},
+		{"LRUNoSelections", constants.PoolTieBreakerLeastRecentlyUsed, nil, poolA},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			poolSelections = &poolSelectionRecorder{selected: make(map[string]uint64)}
+			for _, pool := range tt.selected {
+				poolSelections.record(pool)
+			}
+
+			got := breakPoolTie([]*model.StoragePool{poolC, poolB, poolA}, tt.tieBreaker)
+			if got != tt.expect {
+				t.Errorf("test breakPoolTie failed. got: %s expect: %s", got.Name, tt.expect.Name)
+			}
+		})
+	}
+}
+
 func TestFilterByApplicationType(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -869,3 +947,29 @@ func TestValidateBackend(t *testing.T) {
 		t.Errorf("test validateBackend error %v", err)
 	}
 }
+
+func TestAddBackendTopology(t *testing.T) {
+	tests := []struct {
+		name        string
+		backendName string
+		topologies  []map[string]string
+		want        []map[string]string
+	}{
+		{"WithoutSupportedTopologies", "san", nil,
+			[]map[string]string{{"topology.kubernetes.io/backend.san": "csi.huawei.com"}}},
+		{"WithSupportedTopologies", "san", []map[string]string{{"topology.kubernetes.io/protocol.fc": "csi.huawei.com"}},
+			[]map[string]string{{"topology.kubernetes.io/protocol.fc": "csi.huawei.com",
+				"topology.kubernetes.io/backend.san": "csi.huawei.com"}}},
+		{"InvalidLabelKey", "san/fc", nil, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bk := &model.Backend{Name: tt.backendName, SupportedTopologies: tt.topologies}
+			addBackendTopology(context.Background(), bk, "csi.huawei.com")
+			if !reflect.DeepEqual(bk.SupportedTopologies, tt.want) {
+				t.Errorf("addBackendTopology() = %v, want %v", bk.SupportedTopologies, tt.want)
+			}
+		})
+	}
+}
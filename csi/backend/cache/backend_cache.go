@@ -142,7 +142,7 @@ func (b *BackendCache) PrintCacheContent(ctx context.Context) {
 		log.AddContext(ctx).Debugf("backend: %s,  values: %+v", bk.Name, bk)
 		for _, pool := range bk.Pools {
 			log.AddContext(ctx).Debugf("backend: %s,  poolName: %s, Capabilities: %+v, Capacities: %+v",
-				bk.Name, pool.Name, pool.Capabilities, pool.Capacities)
+				bk.Name, pool.Name, pool.Capabilities, pool.GetCapacities())
 		}
 	}
 }
@@ -19,19 +19,26 @@ package handler
 
 import (
 	"context"
+	"time"
+
 	"huawei-csi-driver/client/apis/xuanwu/v1"
 	"huawei-csi-driver/csi/backend"
 	"huawei-csi-driver/csi/backend/cache"
 	"huawei-csi-driver/csi/backend/model"
+	"huawei-csi-driver/csi/backend/plugin"
 	"huawei-csi-driver/utils/log"
 )
 
+// replacedPluginDrainTimeout bounds the wait for the in-flight operations of a replaced plugin before its logout
+const replacedPluginDrainTimeout = 5 * time.Minute
+
 // BackendCacheWrapperInterface wrapping interface of the backend cache,
 // which is used to provide combined operation cache interfaces.
 type BackendCacheWrapperInterface interface {
 	cache.BackendCacheInterface
 	AddBackendToCache(ctx context.Context, sbct v1.StorageBackendContent) (*model.Backend, error)
 	UpdateCacheBackend(ctx context.Context, name string, sbct v1.StorageBackendContent)
+	ReplaceCacheBackend(ctx context.Context, bk model.Backend, sbct v1.StorageBackendContent)
 	UpdateCacheBackendMetro(ctx context.Context)
 	UpdateCacheBackendStatus(ctx context.Context, name string, online bool)
 	LoadCacheStoragePools(ctx context.Context) []*model.StoragePool
@@ -75,9 +82,42 @@ func (b *CacheWrapper) UpdateCacheBackend(ctx context.Context, name string, sbct
 	b.updateCacheBackend(ctx, bk, sbct)
 }
 
+// ReplaceCacheBackend replaces the cached backend with the re-initialized one,
+// the new operations use the new plugin while the plugin of the previous one is logged out
+// after its in-flight operations are completed
+func (b *CacheWrapper) ReplaceCacheBackend(ctx context.Context, bk model.Backend, sbct v1.StorageBackendContent) {
+	oldBackend, exists := b.Load(bk.Name)
+	// the capacity trend of the pools is kept across the replacement of the plugin
+	if exists && oldBackend.CapacityTrend != nil {
+		bk.CapacityTrend = oldBackend.CapacityTrend
+	}
+	b.updateCacheBackend(ctx, bk, sbct)
+
+	if exists && oldBackend.Plugin != nil {
+		go logoutReplacedPlugin(ctx, oldBackend.Name, oldBackend.Plugin)
+	}
+}
+
+// logoutReplacedPlugin logs out the replaced plugin once its in-flight operations are completed,
+// or when they are not completed in replacedPluginDrainTimeout
+func logoutReplacedPlugin(ctx context.Context, name string, replaced plugin.Plugin) {
+	if tracker, ok := replaced.(plugin.OperationTracker); ok {
+		drainCtx, cancel := context.WithTimeout(context.Background(), replacedPluginDrainTimeout)
+		defer cancel()
+		if err := tracker.WaitOperations(drainCtx); err != nil {
+			log.AddContext(ctx).Warningf("in-flight operations of the replaced plugin of backend %s are not "+
+				"completed in %s, log it out anyway", name, replacedPluginDrainTimeout)
+		}
+	}
+
+	replaced.Logout(context.Background())
+	log.AddContext(ctx).Infof("the replaced plugin of backend %s is logged out", name)
+}
+
 func (b *CacheWrapper) updateCacheBackend(ctx context.Context, bk model.Backend, sbct v1.StorageBackendContent) {
 
 	bk.UpdatePools(ctx, &sbct)
+	bk.DefaultParameters = sbct.Spec.DefaultParameters
 	bk.SetAvailable(ctx, true)
 	b.Store(ctx, bk.Name, bk)
 
@@ -121,12 +161,13 @@ func (b *CacheWrapper) UpdateCacheBackendMetro(ctx context.Context) {
 	}
 }
 
-// LoadCacheStoragePools load all cached storage pools
+// LoadCacheStoragePools load all cached storage pools which volumes can be created on,
+// the pools of the read-only backends and the backends paused by maintenance are excluded
 func (b *CacheWrapper) LoadCacheStoragePools(ctx context.Context) []*model.StoragePool {
 	var candidatePools []*model.StoragePool
 	backends := b.List(ctx)
 	for _, bk := range backends {
-		if bk.Available {
+		if bk.Available && !bk.ReadOnly && !bk.IsPausedByMaintenance() {
 			candidatePools = append(candidatePools, bk.Pools...)
 		}
 	}
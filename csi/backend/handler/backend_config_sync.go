@@ -0,0 +1,166 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package handler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"huawei-csi-driver/client/apis/xuanwu/v1"
+	"huawei-csi-driver/csi/app"
+	"huawei-csi-driver/csi/backend"
+	"huawei-csi-driver/csi/backend/model"
+	"huawei-csi-driver/csi/backend/plugin"
+	pkgUtils "huawei-csi-driver/pkg/utils"
+	"huawei-csi-driver/utils/log"
+)
+
+const (
+	configSyncedReason     = "ConfigSynced"
+	configInitFailedReason = "InitFailed"
+)
+
+// ReloadBackendsByConfigmap re-initializes the registered backends which are configured by the configmap.
+// If a backend fails to be re-initialized, its previous plugin is kept active and
+// the ConfigSyncFailed condition is set on its StorageBackendClaim.
+func (b *BackendRegister) ReloadBackendsByConfigmap(ctx context.Context, configmapMeta string) {
+	contents, err := b.fetchHandler.FetchAllBackends(ctx)
+	if err != nil {
+		log.AddContext(ctx).Errorf("reload backends of configmap %s failed, error: %v", configmapMeta, err)
+		return
+	}
+
+	for _, content := range contents {
+		if content.Spec.ConfigmapMeta != configmapMeta {
+			continue
+		}
+
+		_, name, err := pkgUtils.SplitMetaNamespaceKey(content.Spec.BackendClaim)
+		if err != nil {
+			log.AddContext(ctx).Errorf("get backend name failed, error: %v", err)
+			continue
+		}
+
+		// the backends which are not registered will be initialized with the latest configuration when registering
+		if _, exists := b.cacheHandler.Load(name); !exists {
+			continue
+		}
+
+		err = b.reloadBackend(ctx, content)
+		if err != nil {
+			log.AddContext(ctx).Errorf("reload backend %s failed, keep the previous configuration, error: %v",
+				name, err)
+		} else {
+			log.AddContext(ctx).Infof("backend %s is reloaded by configmap %s", name, configmapMeta)
+		}
+
+		updateConfigSyncCondition(ctx, content.Spec.BackendClaim, err)
+	}
+}
+
+func (b *BackendRegister) reloadBackend(ctx context.Context, content v1.StorageBackendContent) error {
+	newBackend, err := backend.BuildBackend(ctx, content)
+	if err != nil {
+		return err
+	}
+
+	if err = b.checkSharePathPrefixChange(ctx, *newBackend); err != nil {
+		newBackend.Plugin.Logout(ctx)
+		return err
+	}
+
+	b.cacheHandler.ReplaceCacheBackend(ctx, *newBackend, content)
+	return nil
+}
+
+// checkSharePathPrefixChange rejects the change of the nfsSharePathPrefix of an oceanstor-nas backend
+// which already has volumes, the shares of those volumes are created under the previous prefix
+func (b *BackendRegister) checkSharePathPrefixChange(ctx context.Context, newBackend model.Backend) error {
+	if newBackend.Storage != "oceanstor-nas" {
+		return nil
+	}
+
+	oldBackend, exists := b.cacheHandler.Load(newBackend.Name)
+	if !exists {
+		return nil
+	}
+
+	oldPrefix, err := plugin.GetNfsSharePathPrefix(oldBackend.Parameters)
+	if err != nil {
+		return err
+	}
+	newPrefix, err := plugin.GetNfsSharePathPrefix(newBackend.Parameters)
+	if err != nil {
+		return err
+	}
+	if oldPrefix == newPrefix {
+		return nil
+	}
+
+	pvs, err := app.GetGlobalConfig().K8sUtils.ListDriverPersistentVolumes(ctx, app.GetGlobalConfig().DriverName)
+	if err != nil {
+		return fmt.Errorf("list persistent volumes to check the nfsSharePathPrefix change failed: %w", err)
+	}
+	for _, pv := range pvs {
+		if strings.HasPrefix(pv.Spec.CSI.VolumeHandle, newBackend.Name+".") {
+			return fmt.Errorf("nfsSharePathPrefix of backend %s can not be changed from %q to %q, "+
+				"since the backend already has volumes", newBackend.Name, oldPrefix, newPrefix)
+		}
+	}
+	return nil
+}
+
+// updateConfigSyncCondition sets the ConfigSyncFailed condition of the claim by the result of the reload
+func updateConfigSyncCondition(ctx context.Context, claimMeta string, syncErr error) {
+	claim, err := pkgUtils.GetClaimByMeta(ctx, claimMeta)
+	if err != nil || claim.Status == nil {
+		log.AddContext(ctx).Warningf("get claim %s failed, skip updating its condition, error: %v",
+			claimMeta, err)
+		return
+	}
+
+	condition := metaV1.Condition{
+		Type:               v1.ConfigSyncFailed,
+		Status:             metaV1.ConditionFalse,
+		Reason:             configSyncedReason,
+		Message:            "the latest configuration is applied",
+		ObservedGeneration: claim.Generation,
+	}
+	if syncErr != nil {
+		condition.Status = metaV1.ConditionTrue
+		condition.Reason = configInitFailedReason
+		condition.Message = syncErr.Error()
+	}
+
+	existing := meta.FindStatusCondition(claim.Status.Conditions, v1.ConfigSyncFailed)
+	if existing == nil && syncErr == nil {
+		return
+	}
+
+	if existing != nil && existing.Status == condition.Status && existing.Message == condition.Message {
+		return
+	}
+
+	meta.SetStatusCondition(&claim.Status.Conditions, condition)
+	if _, err = pkgUtils.UpdateClaimStatus(ctx, app.GetGlobalConfig().BackendUtils, claim); err != nil {
+		log.AddContext(ctx).Warningf("update condition of claim %s failed, error: %v", claimMeta, err)
+	}
+}
@@ -0,0 +1,195 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package handler
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/agiledragon/gomonkey/v2"
+	"github.com/prashantv/gostub"
+	coreV1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1 "huawei-csi-driver/client/apis/xuanwu/v1"
+	"huawei-csi-driver/csi/app"
+	"huawei-csi-driver/csi/app/config"
+	"huawei-csi-driver/csi/backend"
+	"huawei-csi-driver/csi/backend/model"
+	clientSet "huawei-csi-driver/pkg/client/clientset/versioned"
+	pkgUtils "huawei-csi-driver/pkg/utils"
+	"huawei-csi-driver/utils/k8sutils"
+)
+
+type fakeSharePathK8sUtils struct {
+	k8sutils.Interface
+	pvs []coreV1.PersistentVolume
+}
+
+func (f *fakeSharePathK8sUtils) ListDriverPersistentVolumes(context.Context, string) (
+	[]coreV1.PersistentVolume, error) {
+	return f.pvs, nil
+}
+
+func TestBackendRegister_CheckSharePathPrefixChange(t *testing.T) {
+	newPV := func(volumeHandle string) coreV1.PersistentVolume {
+		return coreV1.PersistentVolume{Spec: coreV1.PersistentVolumeSpec{PersistentVolumeSource: coreV1.
+			PersistentVolumeSource{CSI: &coreV1.CSIPersistentVolumeSource{VolumeHandle: volumeHandle}}}}
+	}
+	newBackend := func(prefix string) model.Backend {
+		parameters := map[string]interface{}{}
+		if prefix != "" {
+			parameters["nfsSharePathPrefix"] = prefix
+		}
+		return model.Backend{Name: "nas", Storage: "oceanstor-nas", Parameters: parameters}
+	}
+
+	tests := []struct {
+		name      string
+		oldPrefix string
+		newPrefix string
+		pvs       []coreV1.PersistentVolume
+		wantErr   bool
+	}{
+		{"Unchanged", "/exports", "/exports/", []coreV1.PersistentVolume{newPV("nas.pvc-1")}, false},
+		{"ChangedWithoutVolumes", "", "/exports", []coreV1.PersistentVolume{newPV("nas-2.pvc-1")}, false},
+		{"EnabledWithVolumes", "", "/exports", []coreV1.PersistentVolume{newPV("nas.pvc-1")}, true},
+		{"ChangedWithVolumes", "/exports", "/k8s", []coreV1.PersistentVolume{newPV("nas.pvc-1")}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			instance := NewBackendRegister()
+			instance.cacheHandler.Store(context.Background(), "nas", newBackend(tt.oldPrefix))
+			defer instance.cacheHandler.Delete(context.Background(), "nas")
+
+			cfg := config.MockCompletedConfig()
+			cfg.K8sUtils = &fakeSharePathK8sUtils{pvs: tt.pvs}
+			stubs := gostub.StubFunc(&app.GetGlobalConfig, cfg)
+			defer stubs.Reset()
+
+			err := instance.checkSharePathPrefixChange(context.Background(), newBackend(tt.newPrefix))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("checkSharePathPrefixChange() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBackendRegister_ReloadBackendsByConfigmapFailed(t *testing.T) {
+	// arrange
+	instance := NewBackendRegister()
+	var updatedClaim *v1.StorageBackendClaim
+
+	// mock
+	patches := gomonkey.ApplyMethod(reflect.TypeOf(instance.fetchHandler), "FetchAllBackends",
+		func(*BackendFetcher, context.Context) ([]v1.StorageBackendContent, error) {
+			return []v1.StorageBackendContent{
+				{
+					Spec: v1.StorageBackendContentSpec{
+						BackendClaim:  "ns/test",
+						ConfigmapMeta: "ns/test-configmap",
+					},
+					Status: &v1.StorageBackendContentStatus{Online: true},
+				},
+				{
+					Spec: v1.StorageBackendContentSpec{
+						BackendClaim:  "ns/other",
+						ConfigmapMeta: "ns/other-configmap",
+					},
+					Status: &v1.StorageBackendContentStatus{Online: true},
+				},
+			}, nil
+		}).ApplyMethod(reflect.TypeOf(instance.cacheHandler), "Load",
+		func(*CacheWrapper, string) (model.Backend, bool) {
+			return model.Backend{}, true
+		}).ApplyMethod(reflect.TypeOf(instance.cacheHandler), "ReplaceCacheBackend",
+		func(*CacheWrapper, context.Context, model.Backend, v1.StorageBackendContent) {
+			t.Errorf("ReplaceCacheBackend should not be called when the backend fails to be initialized")
+		}).ApplyFunc(backend.BuildBackend,
+		func(context.Context, v1.StorageBackendContent) (*model.Backend, error) {
+			return nil, errors.New("login failed")
+		}).ApplyFunc(pkgUtils.GetClaimByMeta,
+		func(context.Context, string) (*v1.StorageBackendClaim, error) {
+			return &v1.StorageBackendClaim{Status: &v1.StorageBackendClaimStatus{}}, nil
+		}).ApplyFunc(pkgUtils.UpdateClaimStatus,
+		func(_ context.Context, _ clientSet.Interface, claim *v1.StorageBackendClaim) (
+			*v1.StorageBackendClaim, error) {
+			updatedClaim = claim
+			return claim, nil
+		})
+	defer patches.Reset()
+
+	// action
+	instance.ReloadBackendsByConfigmap(context.Background(), "ns/test-configmap")
+
+	// assert
+	if updatedClaim == nil {
+		t.Fatal("ReloadBackendsByConfigmap want claim status updated, but not")
+	}
+
+	if !meta.IsStatusConditionTrue(updatedClaim.Status.Conditions, v1.ConfigSyncFailed) {
+		t.Errorf("ReloadBackendsByConfigmap want condition %s true, but got %v",
+			v1.ConfigSyncFailed, updatedClaim.Status.Conditions)
+	}
+}
+
+func TestUpdateConfigSyncConditionSkipWhenNeverFailed(t *testing.T) {
+	// mock
+	patches := gomonkey.ApplyFunc(pkgUtils.GetClaimByMeta,
+		func(context.Context, string) (*v1.StorageBackendClaim, error) {
+			return &v1.StorageBackendClaim{Status: &v1.StorageBackendClaimStatus{}}, nil
+		}).ApplyFunc(pkgUtils.UpdateClaimStatus,
+		func(context.Context, clientSet.Interface, *v1.StorageBackendClaim) (*v1.StorageBackendClaim, error) {
+			t.Errorf("UpdateClaimStatus should not be called when the config never failed to sync")
+			return nil, nil
+		})
+	defer patches.Reset()
+
+	// action
+	updateConfigSyncCondition(context.Background(), "ns/test", nil)
+}
+
+func TestUpdateConfigSyncConditionRecovered(t *testing.T) {
+	// arrange
+	claim := &v1.StorageBackendClaim{Status: &v1.StorageBackendClaimStatus{
+		Conditions: []metav1.Condition{{Type: v1.ConfigSyncFailed, Status: metav1.ConditionTrue}},
+	}}
+
+	// mock
+	patches := gomonkey.ApplyFunc(pkgUtils.GetClaimByMeta,
+		func(context.Context, string) (*v1.StorageBackendClaim, error) {
+			return claim, nil
+		}).ApplyFunc(pkgUtils.UpdateClaimStatus,
+		func(_ context.Context, _ clientSet.Interface, claim *v1.StorageBackendClaim) (
+			*v1.StorageBackendClaim, error) {
+			return claim, nil
+		})
+	defer patches.Reset()
+
+	// action
+	updateConfigSyncCondition(context.Background(), "ns/test", nil)
+
+	// assert
+	if !meta.IsStatusConditionFalse(claim.Status.Conditions, v1.ConfigSyncFailed) {
+		t.Errorf("updateConfigSyncCondition want condition %s false, but got %v",
+			v1.ConfigSyncFailed, claim.Status.Conditions)
+	}
+}
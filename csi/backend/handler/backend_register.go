@@ -33,6 +33,7 @@ type BackendRegisterInterface interface {
 	LoadOrRegisterOneBackend(ctx context.Context, name string) (*model.Backend, error)
 	RemoveRegisteredOneBackend(ctx context.Context, name string)
 	UpdateOrRegisterOneBackend(ctx context.Context, sbct *v1.StorageBackendContent) error
+	ReloadBackendsByConfigmap(ctx context.Context, configmapMeta string)
 }
 
 // BackendRegister backend register
@@ -19,19 +19,27 @@ package handler
 import (
 	"context"
 	"fmt"
+	"strconv"
 
+	"huawei-csi-driver/csi/app"
 	"huawei-csi-driver/csi/backend"
 	"huawei-csi-driver/csi/backend/model"
+	"huawei-csi-driver/csi/backend/plugin"
+	pkgUtils "huawei-csi-driver/pkg/utils"
 	"huawei-csi-driver/utils"
 	"huawei-csi-driver/utils/log"
 )
 
+const autoExpandPoolKey = "autoExpandPool"
+
 // BackendSelectInterface all backend select operation set
 type BackendSelectInterface interface {
 	SelectBackend(context.Context, string) (*model.Backend, error)
 	SelectPoolPair(context.Context, int64, map[string]interface{}) (*model.SelectPoolPair, error)
 	SelectLocalPool(context.Context, int64, map[string]interface{}) ([]*model.StoragePool, error)
 	SelectRemotePool(context.Context, int64, string, map[string]interface{}) (*model.StoragePool, error)
+	SelectExpandablePool(context.Context, map[string]interface{}) (*model.StoragePool, error)
+	IsBackendOffline(context.Context, string) bool
 }
 
 // BackendSelector backend selector
@@ -131,17 +139,65 @@ func (b *BackendSelector) SelectRemotePool(ctx context.Context, requestSize int6
 	return backend.WeightSinglePools(ctx, requestSize, parameters, remotePools)
 }
 
-func filterPool(ctx context.Context, requestSize int64, candidatePools []*model.StoragePool,
-	parameters map[string]interface{}, filters [][]interface{}) ([]*model.StoragePool, error) {
-	var err error
-	if candidatePools, err = backend.FilterByCapability(ctx, parameters, candidatePools, filters); err != nil {
-		return nil, err
+// SelectExpandablePool selects the pool with the most free capacity among the pools which meet the requirements
+// except the free capacity and whose backend allows the automatic pool expansion, nil if there is no such pool
+func (b *BackendSelector) SelectExpandablePool(ctx context.Context,
+	parameters map[string]interface{}) (*model.StoragePool, error) {
+	candidatePools := b.cacheHandler.LoadCacheStoragePools(ctx)
+	if len(candidatePools) == 0 {
+		return nil, nil
 	}
 
-	if candidatePools, err = backend.FilterByTopology(parameters, candidatePools); err != nil {
+	// the size 0 keeps the pools regardless of their free capacity
+	pools, err := filterPool(ctx, 0, candidatePools, parameters, backend.PrimaryFilterFuncs)
+	if err != nil {
 		return nil, err
 	}
 
-	allocType, _ := parameters["allocType"].(string)
-	return backend.FilterByCapacity(requestSize, allocType, candidatePools), nil
+	var selectPool *model.StoragePool
+	var maxFreeCapacity int64
+	for _, pool := range pools {
+		if _, ok := pool.Plugin.(plugin.PoolExpander); !ok || !b.isAutoExpandPool(pool.Parent) {
+			continue
+		}
+
+		freeCapacity := utils.ParseIntWithDefault(pool.GetCapacities()["FreeCapacity"], 10, 64, 0)
+		if selectPool == nil || freeCapacity > maxFreeCapacity {
+			selectPool, maxFreeCapacity = pool, freeCapacity
+		}
+	}
+
+	return selectPool, nil
+}
+
+func (b *BackendSelector) isAutoExpandPool(backendName string) bool {
+	bk, exists := b.cacheHandler.Load(backendName)
+	if !exists {
+		return false
+	}
+
+	autoExpandPool, err := strconv.ParseBool(fmt.Sprintf("%v", bk.Parameters[autoExpandPoolKey]))
+	return err == nil && autoExpandPool
+}
+
+// IsBackendOffline checks whether the backend is configured but offline, the backend which is not configured
+// is not offline
+func (b *BackendSelector) IsBackendOffline(ctx context.Context, name string) bool {
+	if bk, exists := b.cacheHandler.Load(name); exists {
+		return !bk.Available
+	}
+
+	claimNameMeta := pkgUtils.MakeMetaWithNamespace(app.GetGlobalConfig().Namespace, name)
+	content, err := pkgUtils.GetContentByClaimMeta(ctx, claimNameMeta)
+	if err != nil {
+		log.AddContext(ctx).Debugf("Get storageBackendContent of backend %s failed, error: %v", name, err)
+		return false
+	}
+
+	return content.Status == nil || !content.Status.Online
+}
+
+func filterPool(ctx context.Context, requestSize int64, candidatePools []*model.StoragePool,
+	parameters map[string]interface{}, filters [][]interface{}) ([]*model.StoragePool, error) {
+	return backend.FilterPools(ctx, requestSize, parameters, candidatePools, filters)
 }
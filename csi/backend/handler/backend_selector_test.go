@@ -74,9 +74,9 @@ func TestBackendSelector_SelectLocalPool_CapabilityFailed(t *testing.T) {
 	patches := gomonkey.ApplyMethod(reflect.TypeOf(instance.cacheHandler), "LoadCacheStoragePools",
 		func(*CacheWrapper, context.Context) []*model.StoragePool {
 			return []*model.StoragePool{{Name: "pool-1"}}
-		}).ApplyFunc(backend.FilterByCapability, func(_ context.Context, _ map[string]interface{},
-		pool []*model.StoragePool, _ [][]interface{}) ([]*model.StoragePool, error) {
-		return pool, errors.New("capability filter failed")
+		}).ApplyFunc(backend.FilterPools, func(_ context.Context, _ int64, _ map[string]interface{},
+		_ []*model.StoragePool, _ [][]interface{}) ([]*model.StoragePool, error) {
+		return nil, errors.New("capability filter failed")
 	})
 	defer patches.Reset()
 
@@ -96,11 +96,8 @@ func TestBackendSelector_SelectLocalPool_TopologyFailed(t *testing.T) {
 	patches := gomonkey.ApplyMethod(reflect.TypeOf(instance.cacheHandler), "LoadCacheStoragePools",
 		func(*CacheWrapper, context.Context) []*model.StoragePool {
 			return []*model.StoragePool{{Name: "pool-1"}}
-		}).ApplyFunc(backend.FilterByCapability, func(_ context.Context, _ map[string]interface{},
-		pool []*model.StoragePool, _ [][]interface{}) ([]*model.StoragePool, error) {
-		return pool, nil
-	}).ApplyFunc(backend.FilterByTopology, func(_ map[string]interface{},
-		_ []*model.StoragePool) ([]*model.StoragePool, error) {
+		}).ApplyFunc(backend.FilterPools, func(_ context.Context, _ int64, _ map[string]interface{},
+		_ []*model.StoragePool, _ [][]interface{}) ([]*model.StoragePool, error) {
 		return nil, errors.New("topology filter failed")
 	})
 	defer patches.Reset()
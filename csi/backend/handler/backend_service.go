@@ -19,7 +19,10 @@ package handler
 import (
 	"context"
 	"strconv"
+	"time"
 
+	"huawei-csi-driver/csi/app"
+	"huawei-csi-driver/csi/backend/model"
 	"huawei-csi-driver/lib/drcsi"
 	pkgUtils "huawei-csi-driver/pkg/utils"
 	"huawei-csi-driver/utils/log"
@@ -67,6 +70,8 @@ func (s *StorageHandler) GetBackendDetails(ctx context.Context, name string) (St
 		return StorageBackendDetails{}, err
 	}
 
+	updateMaintenanceState(ctx, bk)
+
 	var poolNames []string
 	for _, pool := range bk.Pools {
 		poolNames = append(poolNames, pool.Name)
@@ -78,6 +83,28 @@ func (s *StorageHandler) GetBackendDetails(ctx context.Context, name string) (St
 		return StorageBackendDetails{}, err
 	}
 
+	backendSpecifications := pkgUtils.ConvertToMapValueX[string](ctx, specifications)
+	if bk.PoolHealth != nil {
+		bk.PoolHealth.Aggregate(poolNames, poolCapabilities)
+		for key, value := range poolHealthSpecifications(bk.PoolHealth, app.GetGlobalConfig().MinPoolHealthRatio) {
+			backendSpecifications[key] = value
+		}
+	}
+
+	if bk.CapacityTrend != nil {
+		bk.CapacityTrend.Record(time.Now(), poolNames, poolCapabilities)
+	}
+
+	return StorageBackendDetails{
+		Capabilities:   pkgUtils.ConvertToMapValueX[bool](ctx, capabilities),
+		Specifications: backendSpecifications,
+		Pools:          convertPoolCapacities(ctx, bk, poolCapabilities),
+	}, nil
+}
+
+// convertPoolCapacities converts the pool capabilities of the backend to the capacities of the drcsi pools
+func convertPoolCapacities(ctx context.Context, bk *model.Backend,
+	poolCapabilities map[string]interface{}) []*drcsi.Pool {
 	poolCapabilityMap := pkgUtils.ConvertToMapValueX[map[string]interface{}](ctx, poolCapabilities)
 	poolCapacities := make([]*drcsi.Pool, 0)
 	for _, pool := range bk.Pools {
@@ -91,9 +118,5 @@ func (s *StorageHandler) GetBackendDetails(ctx context.Context, name string) (St
 			Capacities: capacities,
 		})
 	}
-	return StorageBackendDetails{
-		Capabilities:   pkgUtils.ConvertToMapValueX[bool](ctx, capabilities),
-		Specifications: pkgUtils.ConvertToMapValueX[string](ctx, specifications),
-		Pools:          poolCapacities,
-	}, nil
+	return poolCapacities
 }
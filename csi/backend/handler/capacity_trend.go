@@ -0,0 +1,48 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"huawei-csi-driver/csi/backend/model"
+	"huawei-csi-driver/utils/log"
+)
+
+// CapacityTrendPath is the path serving the capacity trends of the storage pools
+const CapacityTrendPath = "/capacity-trends"
+
+// CapacityTrendHandler serves the capacity trends of the pools of each cached backend in json,
+// which is keyed by the backend name and then the pool name
+func CapacityTrendHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		trends := make(map[string]map[string]model.PoolCapacityTrend)
+		for _, bk := range NewCacheWrapper().List(ctx) {
+			if bk.CapacityTrend == nil {
+				continue
+			}
+			trends[bk.Name] = bk.CapacityTrend.Trends()
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(trends); err != nil {
+			log.AddContext(ctx).Warningf("write capacity trends failed, error: %v", err)
+		}
+	})
+}
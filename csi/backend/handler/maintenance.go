@@ -0,0 +1,51 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package handler
+
+import (
+	"context"
+	"time"
+
+	"huawei-csi-driver/csi/backend/model"
+	"huawei-csi-driver/csi/backend/plugin"
+	"huawei-csi-driver/utils/log"
+)
+
+// updateMaintenanceState queries whether the storage of the backend is in maintenance, the previous state is kept
+// if the query fails
+func updateMaintenanceState(ctx context.Context, bk *model.Backend) {
+	checker, ok := bk.Plugin.(plugin.MaintenanceChecker)
+	if !ok || bk.Maintenance == nil {
+		return
+	}
+
+	inMaintenance, err := checker.IsInMaintenance(ctx)
+	if err != nil {
+		log.AddContext(ctx).Warningf("query maintenance state of backend %s failed, error: %v", bk.Name, err)
+		return
+	}
+
+	if !bk.Maintenance.Update(inMaintenance, time.Now()) {
+		return
+	}
+
+	if inMaintenance {
+		log.AddContext(ctx).Warningf("The storage of backend %s enters maintenance", bk.Name)
+	} else {
+		log.AddContext(ctx).Infof("The storage of backend %s leaves maintenance", bk.Name)
+	}
+}
@@ -0,0 +1,45 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package handler
+
+import (
+	"fmt"
+
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"huawei-csi-driver/client/apis/xuanwu/v1"
+	"huawei-csi-driver/csi/backend/model"
+)
+
+// poolHealthSpecifications returns the ready pools and the Ready condition of the backend as the specifications
+// of its stats. The StorageBackendContent status is only written by the sidecar, which records them when it syncs
+// the stats, so that the provider and the sidecar don't overwrite each other.
+func poolHealthSpecifications(health *model.PoolHealthAggregator, minRatio float64) map[string]string {
+	readyPools := health.ReadyPools()
+	ready := metaV1.ConditionTrue
+	message := fmt.Sprintf("%s pools are healthy", readyPools)
+	if health.Score() < minRatio {
+		ready = metaV1.ConditionFalse
+		message = fmt.Sprintf("only %s pools are healthy, below the min pool health ratio %v", readyPools, minRatio)
+	}
+
+	return map[string]string{
+		v1.ReadyPoolsSpecification:          readyPools,
+		v1.BackendReadySpecification:        string(ready),
+		v1.BackendReadyMessageSpecification: message,
+	}
+}
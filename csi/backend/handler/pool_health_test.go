@@ -0,0 +1,54 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package handler
+
+import (
+	"reflect"
+	"testing"
+
+	v1 "huawei-csi-driver/client/apis/xuanwu/v1"
+	"huawei-csi-driver/csi/backend/model"
+)
+
+func TestPoolHealthSpecifications(t *testing.T) {
+	poolNames := []string{"pool1", "pool2", "pool3", "pool4"}
+	cases := []struct {
+		name         string
+		capabilities map[string]interface{}
+		want         map[string]string
+	}{
+		{"all pools healthy", map[string]interface{}{"pool1": nil, "pool2": nil, "pool3": nil, "pool4": nil},
+			map[string]string{v1.ReadyPoolsSpecification: "4/4", v1.BackendReadySpecification: "True",
+				v1.BackendReadyMessageSpecification: "4/4 pools are healthy"}},
+		{"half pools healthy", map[string]interface{}{"pool1": nil, "pool3": nil},
+			map[string]string{v1.ReadyPoolsSpecification: "2/4", v1.BackendReadySpecification: "True",
+				v1.BackendReadyMessageSpecification: "2/4 pools are healthy"}},
+		{"too few pools healthy", map[string]interface{}{"pool2": nil},
+			map[string]string{v1.ReadyPoolsSpecification: "1/4", v1.BackendReadySpecification: "False",
+				v1.BackendReadyMessageSpecification: "only 1/4 pools are healthy, below the min pool health " +
+					"ratio 0.5"}},
+	}
+
+	for _, c := range cases {
+		health := model.NewPoolHealthAggregator()
+		health.Aggregate(poolNames, c.capabilities)
+
+		if got := poolHealthSpecifications(health, 0.5); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%s: poolHealthSpecifications() = %v, want %v", c.name, got, c.want)
+		}
+	}
+}
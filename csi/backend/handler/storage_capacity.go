@@ -0,0 +1,215 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package handler
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+
+	storageV1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"huawei-csi-driver/csi/app"
+	"huawei-csi-driver/csi/backend/cache"
+	"huawei-csi-driver/csi/backend/model"
+	"huawei-csi-driver/lib/drcsi"
+	"huawei-csi-driver/utils/log"
+)
+
+const (
+	storageCapacityNamePrefix   = "huawei-csi-"
+	storageCapacityManagedByKey = "app.kubernetes.io/managed-by"
+	storageCapacityBackendKey   = "csi.huawei.com/backend"
+	storageCapacityPoolKey      = "csi.huawei.com/pool"
+)
+
+// PublishStorageCapacities publishes the free capacity of the pools of all the cached backends by the
+// CSIStorageCapacity objects, it writes the objects of the whole cluster and is run by the leader replica only.
+// The objects of the backends which are no longer cached are deleted.
+func PublishStorageCapacities(ctx context.Context) {
+	cachedBackends := make(map[string]bool)
+	defer func() { deleteRemovedBackendCapacities(ctx, cachedBackends) }()
+
+	for _, bk := range cache.BackendCacheProvider.List(ctx) {
+		cachedBackends[bk.Name] = true
+		var poolNames []string
+		for _, pool := range bk.Pools {
+			poolNames = append(poolNames, pool.Name)
+		}
+
+		poolCapabilities, err := bk.Plugin.UpdatePoolCapabilities(ctx, poolNames)
+		if err != nil {
+			log.AddContext(ctx).Warningf("update pool capabilities of backend %s failed, skip publishing its "+
+				"capacities, error: %v", bk.Name, err)
+			continue
+		}
+
+		publishStorageCapacities(ctx, &bk, convertPoolCapacities(ctx, &bk, poolCapabilities))
+	}
+}
+
+// publishStorageCapacities creates or updates a CSIStorageCapacity object with the free capacity of each pool
+// of the backend for each StorageClass able to select the pool and each topology segment supported by the
+// backend, so that the scheduler is able to check the capacity of the WaitForFirstConsumer volumes.
+// The objects of the backend without supportedTopologies match all the nodes.
+// The objects of the pools, StorageClasses or topologies which no longer exist are deleted.
+func publishStorageCapacities(ctx context.Context, bk *model.Backend, pools []*drcsi.Pool) {
+
+	k8sUtils := app.GetGlobalConfig().K8sUtils
+	namespace := app.GetGlobalConfig().Namespace
+	storageClasses, err := k8sUtils.ListStorageClassesByProvisioner(ctx, app.GetGlobalConfig().DriverName)
+	if err != nil {
+		log.AddContext(ctx).Warningf("list storage classes failed, skip publishing the capacities of "+
+			"backend %s, error: %v", bk.Name, err)
+		return
+	}
+
+	existing, err := k8sUtils.ListStorageCapacities(ctx, namespace,
+		storageCapacityManagedByKey+"="+app.GetGlobalConfig().DriverName)
+	if err != nil {
+		log.AddContext(ctx).Warningf("list storage capacities failed, skip publishing the capacities of "+
+			"backend %s, error: %v", bk.Name, err)
+		return
+	}
+
+	desired := buildStorageCapacities(bk, pools, storageClasses, namespace, app.GetGlobalConfig().DriverName)
+	for i := range existing {
+		capacity := &existing[i]
+		if capacity.Annotations[storageCapacityBackendKey] != bk.Name {
+			continue
+		}
+
+		expected, ok := desired[capacity.Name]
+		if !ok {
+			if err = k8sUtils.DeleteStorageCapacity(ctx, capacity); err != nil {
+				log.AddContext(ctx).Warningf("delete storage capacity %s failed, error: %v", capacity.Name, err)
+			}
+			continue
+		}
+
+		delete(desired, capacity.Name)
+		if capacity.Capacity != nil && capacity.Capacity.Cmp(*expected.Capacity) == 0 {
+			continue
+		}
+
+		capacity.Capacity = expected.Capacity
+		if _, err = k8sUtils.UpdateStorageCapacity(ctx, capacity); err != nil {
+			log.AddContext(ctx).Warningf("update storage capacity %s failed, error: %v", capacity.Name, err)
+		}
+	}
+
+	for _, capacity := range desired {
+		if _, err = k8sUtils.CreateStorageCapacity(ctx, capacity); err != nil {
+			log.AddContext(ctx).Warningf("create storage capacity %s failed, error: %v", capacity.Name, err)
+		}
+	}
+}
+
+// deleteRemovedBackendCapacities deletes the CSIStorageCapacity objects of the backends which are no longer cached,
+// so that the scheduler doesn't trust the capacities of the removed backends
+func deleteRemovedBackendCapacities(ctx context.Context, cachedBackends map[string]bool) {
+	k8sUtils := app.GetGlobalConfig().K8sUtils
+	existing, err := k8sUtils.ListStorageCapacities(ctx, app.GetGlobalConfig().Namespace,
+		storageCapacityManagedByKey+"="+app.GetGlobalConfig().DriverName)
+	if err != nil {
+		log.AddContext(ctx).Warningf("list storage capacities failed, skip deleting the capacities of the "+
+			"removed backends, error: %v", err)
+		return
+	}
+
+	for _, capacity := range getRemovedBackendCapacities(existing, cachedBackends) {
+		if err = k8sUtils.DeleteStorageCapacity(ctx, capacity); err != nil {
+			log.AddContext(ctx).Warningf("delete storage capacity %s of removed backend %s failed, error: %v",
+				capacity.Name, capacity.Annotations[storageCapacityBackendKey], err)
+		}
+	}
+}
+
+// getRemovedBackendCapacities returns the objects whose backend annotation names a backend which is not cached
+func getRemovedBackendCapacities(existing []storageV1.CSIStorageCapacity,
+	cachedBackends map[string]bool) []*storageV1.CSIStorageCapacity {
+	var removed []*storageV1.CSIStorageCapacity
+	for i := range existing {
+		if !cachedBackends[existing[i].Annotations[storageCapacityBackendKey]] {
+			removed = append(removed, &existing[i])
+		}
+	}
+	return removed
+}
+
+// buildStorageCapacities returns the CSIStorageCapacity objects of the pools of the backend by their names,
+// a StorageClass is able to select a pool when its backend and pool parameters are empty or match the pool
+func buildStorageCapacities(bk *model.Backend, pools []*drcsi.Pool, storageClasses []storageV1.StorageClass,
+	namespace, driverName string) map[string]*storageV1.CSIStorageCapacity {
+	capacities := make(map[string]*storageV1.CSIStorageCapacity)
+	for _, storageClass := range storageClasses {
+		if backend := storageClass.Parameters["backend"]; backend != "" && backend != bk.Name {
+			continue
+		}
+
+		for _, pool := range pools {
+			if poolName := storageClass.Parameters["pool"]; poolName != "" && poolName != pool.Name {
+				continue
+			}
+
+			freeCapacity, err := strconv.ParseInt(pool.Capacities["FreeCapacity"], 10, 64)
+			if err != nil {
+				continue
+			}
+
+			for _, topology := range getCapacityTopologies(bk) {
+				name := storageCapacityName(bk.Name, pool.Name, storageClass.Name, topology)
+				capacities[name] = &storageV1.CSIStorageCapacity{
+					ObjectMeta: metaV1.ObjectMeta{
+						Name:      name,
+						Namespace: namespace,
+						Labels:    map[string]string{storageCapacityManagedByKey: driverName},
+						Annotations: map[string]string{
+							storageCapacityBackendKey: bk.Name,
+							storageCapacityPoolKey:    pool.Name,
+						},
+					},
+					NodeTopology:     &metaV1.LabelSelector{MatchLabels: topology},
+					StorageClassName: storageClass.Name,
+					Capacity:         resource.NewQuantity(freeCapacity, resource.BinarySI),
+				}
+			}
+		}
+	}
+
+	return capacities
+}
+
+// getCapacityTopologies returns the topology segments of the CSIStorageCapacity objects of the backend,
+// the backend without supportedTopologies is accessible from all the nodes, whose only segment is empty
+func getCapacityTopologies(bk *model.Backend) []map[string]string {
+	if len(bk.SupportedTopologies) == 0 {
+		return []map[string]string{{}}
+	}
+	return bk.SupportedTopologies
+}
+
+// storageCapacityName returns a stable name of the CSIStorageCapacity object, the names of the backend, pool
+// and StorageClass are hashed as they may be too long or contain the characters not allowed by a name
+func storageCapacityName(backend, pool, storageClass string, topology map[string]string) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s/%s/%s/%v", backend, pool, storageClass, topology)))
+	return storageCapacityNamePrefix + hex.EncodeToString(sum[:])[:16]
+}
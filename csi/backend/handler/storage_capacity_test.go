@@ -0,0 +1,118 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package handler
+
+import (
+	"testing"
+
+	storageV1 "k8s.io/api/storage/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"huawei-csi-driver/csi/backend/model"
+	"huawei-csi-driver/lib/drcsi"
+)
+
+func TestBuildStorageCapacities(t *testing.T) {
+	newStorageClass := func(name string, parameters map[string]string) storageV1.StorageClass {
+		return storageV1.StorageClass{ObjectMeta: metaV1.ObjectMeta{Name: name}, Parameters: parameters}
+	}
+	bk := &model.Backend{Name: "backend-a", SupportedTopologies: []map[string]string{
+		{"topology.kubernetes.io/zone": "zone-1"}, {"topology.kubernetes.io/zone": "zone-2"}}}
+	pools := []*drcsi.Pool{
+		{Name: "pool-1", Capacities: map[string]string{"FreeCapacity": "1073741824"}},
+		{Name: "pool-2", Capacities: map[string]string{"FreeCapacity": "2147483648"}},
+		{Name: "pool-3", Capacities: map[string]string{}},
+	}
+
+	tests := []struct {
+		name           string
+		storageClasses []storageV1.StorageClass
+		expectCount    int
+	}{
+		{"AnyBackend", []storageV1.StorageClass{newStorageClass("sc", nil)}, 4},
+		{"MatchedBackend",
+			[]storageV1.StorageClass{newStorageClass("sc", map[string]string{"backend": "backend-a"})}, 4},
+		{"OtherBackend",
+			[]storageV1.StorageClass{newStorageClass("sc", map[string]string{"backend": "backend-b"})}, 0},
+		{"MatchedPool", []storageV1.StorageClass{newStorageClass("sc", map[string]string{"pool": "pool-2"})}, 2},
+		{"MultipleStorageClasses",
+			[]storageV1.StorageClass{newStorageClass("sc-1", nil), newStorageClass("sc-2", nil)}, 8},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			capacities := buildStorageCapacities(bk, pools, tt.storageClasses, "huawei-csi", "csi.huawei.com")
+			if len(capacities) != tt.expectCount {
+				t.Fatalf("buildStorageCapacities() returns %d objects, want %d", len(capacities), tt.expectCount)
+			}
+			for name, capacity := range capacities {
+				if capacity.Name != name || capacity.Namespace != "huawei-csi" || capacity.NodeTopology == nil {
+					t.Errorf("buildStorageCapacities() returns unexpected object %+v", capacity)
+				}
+				if capacity.Annotations[storageCapacityPoolKey] == "pool-2" &&
+					capacity.Capacity.Value() != 2147483648 {
+					t.Errorf("capacity of pool-2 = %s, want 2Gi", capacity.Capacity.String())
+				}
+			}
+		})
+	}
+}
+
+func TestBuildStorageCapacitiesWithoutTopologies(t *testing.T) {
+	bk := &model.Backend{Name: "backend-a"}
+	pools := []*drcsi.Pool{{Name: "pool-1", Capacities: map[string]string{"FreeCapacity": "1073741824"}}}
+	storageClasses := []storageV1.StorageClass{{ObjectMeta: metaV1.ObjectMeta{Name: "sc"}}}
+
+	capacities := buildStorageCapacities(bk, pools, storageClasses, "huawei-csi", "csi.huawei.com")
+	if len(capacities) != 1 {
+		t.Fatalf("buildStorageCapacities() returns %d objects, want 1", len(capacities))
+	}
+	for _, capacity := range capacities {
+		if capacity.NodeTopology == nil || len(capacity.NodeTopology.MatchLabels) != 0 {
+			t.Errorf("nodeTopology = %v, want the selector matching all the nodes", capacity.NodeTopology)
+		}
+	}
+}
+
+func TestStorageCapacityNameIsStable(t *testing.T) {
+	topology := map[string]string{"topology.kubernetes.io/zone": "zone-1", "topology.kubernetes.io/region": "r"}
+	name := storageCapacityName("backend-a", "pool-1", "sc", topology)
+	if name != storageCapacityName("backend-a", "pool-1", "sc", map[string]string{
+		"topology.kubernetes.io/region": "r", "topology.kubernetes.io/zone": "zone-1"}) {
+		t.Errorf("storageCapacityName() is not stable for the same topology")
+	}
+	if name == storageCapacityName("backend-a", "pool-1", "sc", map[string]string{"topology.kubernetes.io/zone": "z"}) {
+		t.Errorf("storageCapacityName() is the same for different topologies")
+	}
+}
+
+func TestGetRemovedBackendCapacities(t *testing.T) {
+	newCapacity := func(name, backend string) storageV1.CSIStorageCapacity {
+		return storageV1.CSIStorageCapacity{ObjectMeta: metaV1.ObjectMeta{Name: name,
+			Annotations: map[string]string{storageCapacityBackendKey: backend}}}
+	}
+	existing := []storageV1.CSIStorageCapacity{
+		newCapacity("huawei-csi-1", "backend-a"),
+		newCapacity("huawei-csi-2", "backend-removed"),
+		newCapacity("huawei-csi-3", "backend-b"),
+	}
+
+	removed := getRemovedBackendCapacities(existing, map[string]bool{"backend-a": true, "backend-b": true})
+	if len(removed) != 1 || removed[0].Name != "huawei-csi-2" {
+		t.Errorf("getRemovedBackendCapacities() = %v, want only the capacity of the removed backend", removed)
+	}
+}
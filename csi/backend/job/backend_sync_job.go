@@ -18,6 +18,10 @@
 package job
 
 import (
+	coreV1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"huawei-csi-driver/csi/app"
 	"huawei-csi-driver/csi/backend/handler"
 	pkgUtils "huawei-csi-driver/pkg/utils"
 	"huawei-csi-driver/utils"
@@ -37,3 +41,26 @@ func RunSyncBackendTaskInBackground() {
 	backendSyncInterface.FetchAndRegisterAllBackend(ctx)
 	log.AddContext(ctx).Infoln("End to sync Backend")
 }
+
+// WatchBackendConfigmapInBackground re-initializes the backends configured by the configmap when it changes
+func WatchBackendConfigmapInBackground(configmapMeta string) {
+	ctx := utils.NewContextWithRequestID()
+	namespace, name, err := pkgUtils.SplitMetaNamespaceKey(configmapMeta)
+	if err != nil {
+		log.AddContext(ctx).Errorf("invalid backend configmap %s, error: %v", configmapMeta, err)
+		return
+	}
+
+	if namespace == "" {
+		namespace = app.GetGlobalConfig().Namespace
+	}
+	configmapMeta = pkgUtils.MakeMetaWithNamespace(namespace, name)
+
+	log.AddContext(ctx).Infof("start watching backend configmap %s", configmapMeta)
+	register := handler.NewBackendRegister()
+	app.GetGlobalConfig().K8sUtils.WatchConfigmap(ctx, namespace, name, func(configmap *coreV1.ConfigMap) {
+		reloadCtx := utils.NewContextWithRequestID()
+		log.AddContext(reloadCtx).Infof("backend configmap %s is changed, reload the backends", configmapMeta)
+		register.ReloadBackendsByConfigmap(reloadCtx, configmapMeta)
+	}, wait.NeverStop)
+}
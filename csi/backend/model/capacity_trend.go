@@ -0,0 +1,138 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package model
+
+import (
+	"sync"
+	"time"
+
+	xuanwuV1 "huawei-csi-driver/client/apis/xuanwu/v1"
+)
+
+// capacityTrendSize is the number of the latest capacity samples kept for each pool
+const capacityTrendSize = 360
+
+// CapacitySample is the capacity of a pool at a point in time, in bytes
+type CapacitySample struct {
+	Time  time.Time `json:"time"`
+	Free  int64     `json:"free"`
+	Used  int64     `json:"used"`
+	Total int64     `json:"total"`
+}
+
+// PoolCapacityTrend is the recent capacity samples of a pool and the projection of them
+type PoolCapacityTrend struct {
+	Samples []CapacitySample `json:"samples"`
+	// FillRate is the bytes consumed per hour from the oldest to the latest sample, negative if freed
+	FillRate int64 `json:"fillRateBytesPerHour"`
+	// FullTime is the projected time when the pool is full at the fill rate, nil if the pool is not filling
+	FullTime *time.Time `json:"projectedFullTime,omitempty"`
+}
+
+// CapacityTrendRecorder records the capacity samples of the pools of a backend after their capabilities are
+// updated, only the latest samples of each pool are kept in memory
+type CapacityTrendRecorder struct {
+	mutex   sync.RWMutex
+	samples map[string]*capacityRing
+}
+
+type capacityRing struct {
+	samples []CapacitySample
+	next    int
+}
+
+func (r *capacityRing) add(sample CapacitySample) {
+	if len(r.samples) < capacityTrendSize {
+		r.samples = append(r.samples, sample)
+		return
+	}
+
+	r.samples[r.next] = sample
+	r.next = (r.next + 1) % capacityTrendSize
+}
+
+// ordered returns the samples from the oldest to the latest
+func (r *capacityRing) ordered() []CapacitySample {
+	samples := make([]CapacitySample, 0, len(r.samples))
+	samples = append(samples, r.samples[r.next:]...)
+	return append(samples, r.samples[:r.next]...)
+}
+
+// NewCapacityTrendRecorder returns the recorder of a backend
+func NewCapacityTrendRecorder() *CapacityTrendRecorder {
+	return &CapacityTrendRecorder{samples: make(map[string]*capacityRing)}
+}
+
+// Record records the capacity of the pools by their updated capabilities, the pools without capabilities
+// are skipped
+func (r *CapacityTrendRecorder) Record(now time.Time, poolNames []string, poolCapabilities map[string]interface{}) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, name := range poolNames {
+		capabilities, ok := poolCapabilities[name].(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		ring, exist := r.samples[name]
+		if !exist {
+			ring = &capacityRing{}
+			r.samples[name] = ring
+		}
+
+		free, _ := capabilities[string(xuanwuV1.FreeCapacity)].(int64)
+		used, _ := capabilities[string(xuanwuV1.UsedCapacity)].(int64)
+		total, _ := capabilities[string(xuanwuV1.TotalCapacity)].(int64)
+		ring.add(CapacitySample{Time: now, Free: free, Used: used, Total: total})
+	}
+}
+
+// Trends returns the capacity trends of the recorded pools
+func (r *CapacityTrendRecorder) Trends() map[string]PoolCapacityTrend {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	trends := make(map[string]PoolCapacityTrend, len(r.samples))
+	for name, ring := range r.samples {
+		trends[name] = newPoolCapacityTrend(ring.ordered())
+	}
+	return trends
+}
+
+// newPoolCapacityTrend projects the fill rate by the consumed free capacity from the oldest to the latest sample,
+// and the pool is projected to be full when the latest free capacity is consumed at the fill rate
+func newPoolCapacityTrend(samples []CapacitySample) PoolCapacityTrend {
+	trend := PoolCapacityTrend{Samples: samples}
+	if len(samples) < 2 {
+		return trend
+	}
+
+	oldest, latest := samples[0], samples[len(samples)-1]
+	elapsed := latest.Time.Sub(oldest.Time)
+	if elapsed <= 0 {
+		return trend
+	}
+
+	trend.FillRate = int64(float64(oldest.Free-latest.Free) / elapsed.Hours())
+	if trend.FillRate > 0 {
+		hoursToFull := float64(latest.Free) / float64(trend.FillRate)
+		fullTime := latest.Time.Add(time.Duration(hoursToFull * float64(time.Hour)))
+		trend.FullTime = &fullTime
+	}
+	return trend
+}
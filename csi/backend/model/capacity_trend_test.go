@@ -0,0 +1,88 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func poolCapacity(free, total int64) map[string]interface{} {
+	return map[string]interface{}{
+		"FreeCapacity":  free,
+		"UsedCapacity":  total - free,
+		"TotalCapacity": total,
+	}
+}
+
+func TestCapacityTrendRecorderRecord(t *testing.T) {
+	recorder := NewCapacityTrendRecorder()
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < capacityTrendSize+2; i++ {
+		recorder.Record(start.Add(time.Duration(i)*time.Hour), []string{"pool1", "pool2"}, map[string]interface{}{
+			"pool1": poolCapacity(int64(10000-i*10), 10000),
+		})
+	}
+
+	trends := recorder.Trends()
+	if _, exist := trends["pool2"]; exist {
+		t.Errorf("Trends() got pool2 without capabilities, want skipped")
+	}
+
+	samples := trends["pool1"].Samples
+	if len(samples) != capacityTrendSize {
+		t.Fatalf("Trends() got %d samples, want %d", len(samples), capacityTrendSize)
+	}
+	if !samples[0].Time.Equal(start.Add(2 * time.Hour)) {
+		t.Errorf("Trends() oldest sample at %v, want %v", samples[0].Time, start.Add(2*time.Hour))
+	}
+	if samples[len(samples)-1].Free != 10000-(capacityTrendSize+1)*10 {
+		t.Errorf("Trends() latest free = %d, want %d", samples[len(samples)-1].Free,
+			10000-(capacityTrendSize+1)*10)
+	}
+}
+
+func TestNewPoolCapacityTrend(t *testing.T) {
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	fullTime := start.Add(4 * time.Hour)
+	tests := []struct {
+		name     string
+		samples  []CapacitySample
+		fillRate int64
+		fullTime *time.Time
+	}{
+		{name: "single sample", samples: []CapacitySample{{Time: start, Free: 100}}},
+		{name: "filling", fillRate: 50, fullTime: &fullTime, samples: []CapacitySample{
+			{Time: start, Free: 200}, {Time: start.Add(2 * time.Hour), Free: 100}}},
+		{name: "freeing", fillRate: -50, samples: []CapacitySample{
+			{Time: start, Free: 100}, {Time: start.Add(2 * time.Hour), Free: 200}}},
+		{name: "same time", samples: []CapacitySample{{Time: start, Free: 200}, {Time: start, Free: 100}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trend := newPoolCapacityTrend(tt.samples)
+			if trend.FillRate != tt.fillRate {
+				t.Errorf("newPoolCapacityTrend() fill rate = %d, want %d", trend.FillRate, tt.fillRate)
+			}
+			if (trend.FullTime == nil) != (tt.fullTime == nil) ||
+				(tt.fullTime != nil && !trend.FullTime.Equal(*tt.fullTime)) {
+				t.Errorf("newPoolCapacityTrend() full time = %v, want %v", trend.FullTime, tt.fullTime)
+			}
+		})
+	}
+}
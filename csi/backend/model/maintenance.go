@@ -0,0 +1,56 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package model
+
+import (
+	"sync"
+	"time"
+)
+
+// MaintenanceState records whether the storage of a backend is in maintenance, such as upgrading,
+// which is queried when the backend is refreshed
+type MaintenanceState struct {
+	mutex         sync.RWMutex
+	inMaintenance bool
+	since         time.Time
+}
+
+// NewMaintenanceState returns the maintenance state of a backend
+func NewMaintenanceState() *MaintenanceState {
+	return &MaintenanceState{}
+}
+
+// Update records the queried maintenance state, returns whether the state is changed
+func (m *MaintenanceState) Update(inMaintenance bool, now time.Time) bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.inMaintenance == inMaintenance {
+		return false
+	}
+
+	m.inMaintenance, m.since = inMaintenance, now
+	return true
+}
+
+// InMaintenance returns whether the storage is in maintenance and since when
+func (m *MaintenanceState) InMaintenance() (bool, time.Time) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	return m.inMaintenance, m.since
+}
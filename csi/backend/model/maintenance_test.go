@@ -0,0 +1,51 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMaintenanceStateUpdate(t *testing.T) {
+	start := time.Now()
+	steps := []struct {
+		name          string
+		inMaintenance bool
+		now           time.Time
+		wantChanged   bool
+		wantSince     time.Time
+	}{
+		{"StayNormal", false, start, false, time.Time{}},
+		{"EnterMaintenance", true, start.Add(time.Minute), true, start.Add(time.Minute)},
+		{"StayInMaintenance", true, start.Add(2 * time.Minute), false, start.Add(time.Minute)},
+		{"LeaveMaintenance", false, start.Add(3 * time.Minute), true, start.Add(3 * time.Minute)},
+	}
+
+	state := NewMaintenanceState()
+	for _, step := range steps {
+		if changed := state.Update(step.inMaintenance, step.now); changed != step.wantChanged {
+			t.Errorf("%s: Update() = %v, want %v", step.name, changed, step.wantChanged)
+		}
+
+		inMaintenance, since := state.InMaintenance()
+		if inMaintenance != step.inMaintenance || !since.Equal(step.wantSince) {
+			t.Errorf("%s: InMaintenance() = %v, %v, want %v, %v", step.name, inMaintenance, since,
+				step.inMaintenance, step.wantSince)
+		}
+	}
+}
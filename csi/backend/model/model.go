@@ -22,7 +22,9 @@ import (
 	"huawei-csi-driver/utils/log"
 
 	xuanwuV1 "huawei-csi-driver/client/apis/xuanwu/v1"
+	"huawei-csi-driver/csi/app"
 	"huawei-csi-driver/csi/backend/plugin"
+	"huawei-csi-driver/pkg/constants"
 )
 
 // StorageBackendTuple contains sbc and sbct
@@ -41,6 +43,16 @@ type Backend struct {
 	Parameters          map[string]interface{}
 	SupportedTopologies []map[string]string
 	AccountName         string
+	SnapshotLimiter     *SnapshotLimiter
+	PoolHealth          *PoolHealthAggregator
+	CapacityTrend       *CapacityTrendRecorder
+	// the default StorageClass parameters of the volumes created on this backend
+	DefaultParameters map[string]string
+	// ReadOnly rejects creating, deleting, expanding and snapshotting the volumes of this backend,
+	// while attaching and detaching them are still allowed
+	ReadOnly bool
+	// Maintenance is the maintenance state of the storage queried when the backend is refreshed
+	Maintenance *MaintenanceState
 
 	MetroDomain       string
 	MetrovStorePairID string
@@ -59,6 +71,17 @@ func (b *Backend) SetAvailable(ctx context.Context, available bool) {
 	b.Available = available
 }
 
+// IsPausedByMaintenance returns whether the operations modifying the volumes of the backend are paused,
+// which happens when the storage is in maintenance and the maintenance policy is pause
+func (b *Backend) IsPausedByMaintenance() bool {
+	if b.Maintenance == nil || app.GetGlobalConfig().MaintenancePolicy != constants.MaintenancePolicyPause {
+		return false
+	}
+
+	inMaintenance, _ := b.Maintenance.InMaintenance()
+	return inMaintenance
+}
+
 // UpdatePools update Backend pools
 func (b *Backend) UpdatePools(ctx context.Context, sbct *xuanwuV1.StorageBackendContent) {
 	for _, pool := range b.Pools {
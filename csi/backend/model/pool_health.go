@@ -0,0 +1,68 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package model
+
+import (
+	"fmt"
+	"sync"
+)
+
+// PoolHealthAggregator aggregates the health of the pools of a backend after their capabilities are updated,
+// a pool is healthy if the storage returns its capabilities
+type PoolHealthAggregator struct {
+	mutex      sync.RWMutex
+	readyPools int
+	totalPools int
+}
+
+// NewPoolHealthAggregator returns the aggregator of a backend
+func NewPoolHealthAggregator() *PoolHealthAggregator {
+	return &PoolHealthAggregator{}
+}
+
+// Aggregate records the health of the pools by their updated capabilities
+func (a *PoolHealthAggregator) Aggregate(poolNames []string, poolCapabilities map[string]interface{}) {
+	var readyPools int
+	for _, name := range poolNames {
+		if _, exist := poolCapabilities[name]; exist {
+			readyPools++
+		}
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.readyPools, a.totalPools = readyPools, len(poolNames)
+}
+
+// Score returns the ratio of the healthy pools to all pools, the backend without pools is regarded as healthy
+func (a *PoolHealthAggregator) Score() float64 {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	if a.totalPools == 0 {
+		return 1
+	}
+	return float64(a.readyPools) / float64(a.totalPools)
+}
+
+// ReadyPools returns the healthy pools and all pools in the format of <ready>/<total>
+func (a *PoolHealthAggregator) ReadyPools() string {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	return fmt.Sprintf("%d/%d", a.readyPools, a.totalPools)
+}
@@ -0,0 +1,75 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package model
+
+import (
+	"context"
+
+	"huawei-csi-driver/utils"
+	"huawei-csi-driver/utils/metrics"
+)
+
+const (
+	// SnapshotOperationCreate is the operation label of creating snapshots
+	SnapshotOperationCreate = "create"
+	// SnapshotOperationDelete is the operation label of deleting snapshots
+	SnapshotOperationDelete = "delete"
+)
+
+// SnapshotLimiter limits the concurrent snapshot operations of a backend, so that a burst of backups
+// does not overwhelm the snapshot engine of the storage. A nil limiter means no limit.
+type SnapshotLimiter struct {
+	backend   string
+	semaphore *utils.Semaphore
+}
+
+// NewSnapshotLimiter returns the limiter of the backend, or nil if the limit is not positive
+func NewSnapshotLimiter(backend string, limit int) *SnapshotLimiter {
+	if limit <= 0 {
+		return nil
+	}
+
+	return &SnapshotLimiter{
+		backend:   backend,
+		semaphore: utils.NewSemaphore(limit),
+	}
+}
+
+// Acquire blocks until the operation is allowed to run or the context is done.
+// The returned function must be called to release the permit once the operation is finished.
+func (l *SnapshotLimiter) Acquire(ctx context.Context, operation string) (func(), error) {
+	if l == nil {
+		return func() {}, nil
+	}
+
+	queueDepth := metrics.SnapshotOperationQueueDepth.WithLabelValues(l.backend, operation)
+	queueDepth.Inc()
+	defer queueDepth.Dec()
+
+	select {
+	case l.semaphore.GetChannel() <- 0:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	inFlight := metrics.SnapshotOperationInFlight.WithLabelValues(l.backend, operation)
+	inFlight.Inc()
+	return func() {
+		inFlight.Dec()
+		l.semaphore.Release()
+	}, nil
+}
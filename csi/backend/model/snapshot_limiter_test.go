@@ -0,0 +1,57 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package model
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestSnapshotLimiterAcquire(t *testing.T) {
+	limiter := NewSnapshotLimiter("backend1", 1)
+	release, err := limiter.Acquire(context.Background(), SnapshotOperationCreate)
+	if err != nil {
+		t.Fatalf("Acquire() error = %v, want nil", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err = limiter.Acquire(ctx, SnapshotOperationDelete); err == nil {
+		t.Errorf("Acquire() want error when the limit is reached, but got nil")
+	}
+
+	release()
+	release, err = limiter.Acquire(context.Background(), SnapshotOperationDelete)
+	if err != nil {
+		t.Fatalf("Acquire() error = %v after the permit is released, want nil", err)
+	}
+	release()
+}
+
+func TestSnapshotLimiterUnlimited(t *testing.T) {
+	limiter := NewSnapshotLimiter("backend1", 0)
+	if limiter != nil {
+		t.Fatalf("NewSnapshotLimiter() = %v, want nil", limiter)
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, err := limiter.Acquire(context.Background(), SnapshotOperationCreate); err != nil {
+			t.Errorf("Acquire() error = %v, want nil", err)
+		}
+	}
+}
@@ -19,6 +19,7 @@ package model
 
 import (
 	"context"
+	"sync"
 
 	xuanwuV1 "huawei-csi-driver/client/apis/xuanwu/v1"
 	"huawei-csi-driver/csi/backend/plugin"
@@ -33,6 +34,9 @@ type StoragePool struct {
 	Capabilities map[string]bool
 	Capacities   map[string]string
 	Plugin       plugin.Plugin
+
+	// capacityMutex guards Capacities, which is updated by the backend resync and the volume creations
+	capacityMutex sync.RWMutex
 }
 
 func (p *StoragePool) setCapacity(k string, v string) {
@@ -42,9 +46,33 @@ func (p *StoragePool) setCapacity(k string, v string) {
 	p.Capacities[k] = v
 }
 
-// GetCapacities used to get capacities
+// SetCapacity used to set a capacity of the pool
+func (p *StoragePool) SetCapacity(k string, v string) {
+	p.capacityMutex.Lock()
+	defer p.capacityMutex.Unlock()
+	p.setCapacity(k, v)
+}
+
+// GetCapacity used to get a capacity of the pool
+func (p *StoragePool) GetCapacity(k string) string {
+	p.capacityMutex.RLock()
+	defer p.capacityMutex.RUnlock()
+	return p.Capacities[k]
+}
+
+// GetCapacities used to get a copy of the capacities
 func (p *StoragePool) GetCapacities() map[string]string {
-	return p.Capacities
+	p.capacityMutex.RLock()
+	defer p.capacityMutex.RUnlock()
+	if p.Capacities == nil {
+		return nil
+	}
+
+	capacities := make(map[string]string, len(p.Capacities))
+	for k, v := range p.Capacities {
+		capacities[k] = v
+	}
+	return capacities
 }
 
 func (p *StoragePool) setCapability(k string, v bool) {
@@ -99,6 +127,8 @@ func (p *StoragePool) UpdateCapacities(ctx context.Context, capacities map[strin
 		return
 	}
 
+	p.capacityMutex.Lock()
+	defer p.capacityMutex.Unlock()
 	for key, val := range capacities {
 		curVal, exist := p.Capacities[key]
 		if !exist {
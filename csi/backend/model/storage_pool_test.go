@@ -20,6 +20,8 @@ package model
 import (
 	"context"
 	"reflect"
+	"strconv"
+	"sync"
 	"testing"
 
 	"github.com/prashantv/gostub"
@@ -121,6 +123,33 @@ func TestStoragePool_ChangeCapacities(t *testing.T) {
 	}
 }
 
+// TestStoragePool_SetCapacityConcurrently test
+func TestStoragePool_SetCapacityConcurrently(t *testing.T) {
+	// arrange
+	ctx := context.Background()
+	pool := &StoragePool{Name: "pool1", Parent: "backend1"}
+	var wg sync.WaitGroup
+
+	// act
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			pool.SetCapacity(string(xuanwuv1.FreeCapacity), strconv.Itoa(i))
+			pool.UpdateCapacities(ctx, map[string]string{string(xuanwuv1.UsedCapacity): strconv.Itoa(i)})
+			_ = pool.GetCapacity(string(xuanwuv1.FreeCapacity))
+		}(i)
+	}
+	wg.Wait()
+	capacities := pool.GetCapacities()
+	capacities[string(xuanwuv1.TotalCapacity)] = "1"
+
+	// assert
+	if _, exist := pool.GetCapacities()[string(xuanwuv1.TotalCapacity)]; exist {
+		t.Errorf("TestStoragePool_SetCapacityConcurrently failed, the returned capacities are not a copy")
+	}
+}
+
 // TestStoragePool_UpdatePoolCapabilities test
 func TestStoragePool_UpdatePoolCapabilities(t *testing.T) {
 	// arrange
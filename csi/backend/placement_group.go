@@ -0,0 +1,181 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package backend
+
+import (
+	"sync"
+
+	"huawei-csi-driver/csi/backend/model"
+)
+
+const placementGroupKey = "placementGroup"
+
+// placement is where a volume of a placement group is created, the controller is empty when the owning
+// controller of the volume is not chosen by the driver
+type placement struct {
+	group      string
+	pool       string
+	controller string
+}
+
+// placementGroupRecorder tracks in memory how many volumes of each placement group are placed on each pool and
+// each controller. The volumes are recorded once they are created and forgotten once they are deleted, the
+// records are rebuilt from the PVs when the controller restarts.
+type placementGroupRecorder struct {
+	mutex       sync.Mutex
+	volumes     map[string]placement
+	pools       map[string]map[string]int
+	controllers map[string]map[string]int
+}
+
+var placementGroups = newPlacementGroupRecorder()
+
+func newPlacementGroupRecorder() *placementGroupRecorder {
+	return &placementGroupRecorder{
+		volumes:     make(map[string]placement),
+		pools:       make(map[string]map[string]int),
+		controllers: make(map[string]map[string]int),
+	}
+}
+
+func controllerKey(backendName, controller string) string {
+	return backendName + ":" + controller
+}
+
+func addCount(counts map[string]map[string]int, group, key string, delta int) {
+	if counts[group] == nil {
+		counts[group] = make(map[string]int)
+	}
+	counts[group][key] += delta
+	if counts[group][key] <= 0 {
+		delete(counts[group], key)
+	}
+	if len(counts[group]) == 0 {
+		delete(counts, group)
+	}
+}
+
+func (r *placementGroupRecorder) record(volumeID, group, backendName, poolName, controller string) {
+	if group == "" {
+		return
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.forgetLocked(volumeID)
+	p := placement{group: group, pool: backendName + ":" + poolName}
+	addCount(r.pools, group, p.pool, 1)
+	if controller != "" {
+		p.controller = controllerKey(backendName, controller)
+		addCount(r.controllers, group, p.controller, 1)
+	}
+	r.volumes[volumeID] = p
+}
+
+func (r *placementGroupRecorder) forget(volumeID string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.forgetLocked(volumeID)
+}
+
+func (r *placementGroupRecorder) forgetLocked(volumeID string) {
+	p, exist := r.volumes[volumeID]
+	if !exist {
+		return
+	}
+
+	addCount(r.pools, p.group, p.pool, -1)
+	if p.controller != "" {
+		addCount(r.controllers, p.group, p.controller, -1)
+	}
+	delete(r.volumes, volumeID)
+}
+
+func (r *placementGroupRecorder) countPool(group string, pool *model.StoragePool) int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return r.pools[group][poolKey(pool)]
+}
+
+func (r *placementGroupRecorder) countController(group, backendName, controller string) int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return r.controllers[group][controllerKey(backendName, controller)]
+}
+
+// RecordPlacement records the created volume of the placement group on the pool and the owning controller of
+// the backend, so that the next volumes of the group are spread to the other pools and controllers
+func RecordPlacement(volumeID, group, backendName, poolName, controller string) {
+	placementGroups.record(volumeID, group, backendName, poolName, controller)
+}
+
+// ForgetPlacement removes the deleted volume from the placement group it belongs to
+func ForgetPlacement(volumeID string) {
+	placementGroups.forget(volumeID)
+}
+
+// spreadByPlacementGroup keeps the pools holding the fewest volumes of the placement group, so that the volumes
+// sharing a group avoid the same pool as long as another pool is available. It is a soft anti-affinity, all the
+// pools are kept when each of them already holds a volume of the group.
+func spreadByPlacementGroup(group string, pools []*model.StoragePool) []*model.StoragePool {
+	if group == "" || len(pools) <= 1 {
+		return pools
+	}
+
+	var spreadPools []*model.StoragePool
+	minCount := -1
+	for _, pool := range pools {
+		count := placementGroups.countPool(group, pool)
+		if minCount == -1 || count < minCount {
+			minCount = count
+			spreadPools = spreadPools[:0]
+		}
+		if count == minCount {
+			spreadPools = append(spreadPools, pool)
+		}
+	}
+
+	return spreadPools
+}
+
+// SpreadControllerByPlacementGroup returns the controller of the backend owning the fewest volumes of the
+// placement group, the first one is returned among the controllers owning as many volumes
+func SpreadControllerByPlacementGroup(group, backendName string, controllers []string) string {
+	if group == "" || len(controllers) == 0 {
+		return ""
+	}
+
+	spreadController := controllers[0]
+	minCount := placementGroups.countController(group, backendName, spreadController)
+	for _, controller := range controllers[1:] {
+		if count := placementGroups.countController(group, backendName, controller); count < minCount {
+			minCount = count
+			spreadController = controller
+		}
+	}
+	return spreadController
+}
+
+// GetPlacementGroup returns the placement group of the volume requested by the StorageClass
+func GetPlacementGroup(parameters map[string]interface{}) string {
+	group, _ := parameters[placementGroupKey].(string)
+	return group
+}
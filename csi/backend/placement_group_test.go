@@ -0,0 +1,98 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package backend
+
+import (
+	"context"
+	"testing"
+
+	"huawei-csi-driver/csi/backend/model"
+)
+
+func TestWeightSinglePoolsSpreadsPlacementGroup(t *testing.T) {
+	placementGroups = newPlacementGroupRecorder()
+	newPool := func(name, freeCapacity string) *model.StoragePool {
+		return &model.StoragePool{Name: name, Parent: "backend-" + name,
+			Capacities: map[string]string{"FreeCapacity": freeCapacity}}
+	}
+	pools := []*model.StoragePool{newPool("pool-a", "4096"), newPool("pool-b", "2048"), newPool("pool-c", "1024")}
+
+	tests := []struct {
+		name       string
+		parameters map[string]interface{}
+		record     bool
+		forget     string
+		expectPool string
+	}{
+		{"FirstOfGroup", map[string]interface{}{"placementGroup": "web"}, true, "", "pool-a"},
+		{"SecondOfGroup", map[string]interface{}{"placementGroup": "web"}, true, "", "pool-b"},
+		{"FailedThirdOfGroup", map[string]interface{}{"placementGroup": "web"}, false, "", "pool-c"},
+		{"RetriedThirdOfGroup", map[string]interface{}{"placementGroup": "web"}, true, "", "pool-c"},
+		{"FourthOfGroup", map[string]interface{}{"placementGroup": "web"}, true, "", "pool-a"},
+		{"AfterDeletedOfGroup", map[string]interface{}{"placementGroup": "web"}, false, "SecondOfGroup", "pool-b"},
+		{"FirstOfOtherGroup", map[string]interface{}{"placementGroup": "db"}, false, "", "pool-a"},
+		{"WithoutGroup", map[string]interface{}{}, false, "", "pool-a"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ForgetPlacement(tt.forget)
+			pool, err := WeightSinglePools(context.Background(), 0, tt.parameters, pools)
+			if err != nil {
+				t.Fatalf("WeightSinglePools() error = %v", err)
+			}
+			if pool.Name != tt.expectPool {
+				t.Errorf("WeightSinglePools() = %s, want %s", pool.Name, tt.expectPool)
+			}
+			if tt.record {
+				RecordPlacement(tt.name, GetPlacementGroup(tt.parameters), pool.Parent, pool.Name, "")
+			}
+		})
+	}
+}
+
+func TestSpreadControllerByPlacementGroup(t *testing.T) {
+	placementGroups = newPlacementGroupRecorder()
+	RecordPlacement("backend.pvc-1", "web", "backend", "pool", "0A")
+	RecordPlacement("backend.pvc-2", "web", "backend", "pool", "0B")
+	RecordPlacement("backend.pvc-3", "web", "backend", "pool", "0A")
+	RecordPlacement("other.pvc-4", "web", "other", "pool", "0B")
+
+	tests := []struct {
+		name        string
+		group       string
+		backendName string
+		forget      string
+		expect      string
+	}{
+		{"FewestVolumesOfGroup", "web", "backend", "", "0B"},
+		{"OtherBackend", "web", "other", "", "0A"},
+		{"OtherGroup", "db", "backend", "", "0A"},
+		{"WithoutGroup", "", "backend", "", ""},
+		{"AfterDeleted", "web", "backend", "backend.pvc-1", "0A"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ForgetPlacement(tt.forget)
+			controller := SpreadControllerByPlacementGroup(tt.group, tt.backendName, []string{"0A", "0B"})
+			if controller != tt.expect {
+				t.Errorf("SpreadControllerByPlacementGroup() = %s, want %s", controller, tt.expect)
+			}
+		})
+	}
+}
@@ -90,7 +90,7 @@ func (p *FusionStorageNasPlugin) CreateVolume(ctx context.Context, name string,
 			size, fileCapacityUnit)
 	}
 
-	params, err := p.getParams(name, parameters)
+	params, err := p.getParams(ctx, name, parameters)
 	if err != nil {
 		return nil, err
 	}
@@ -125,6 +125,24 @@ func (p *FusionStorageNasPlugin) DeleteVolume(ctx context.Context, name string)
 	return nas.Delete(ctx, name)
 }
 
+// DeleteNFSShare used to delete the nfs share of the volume and keep the filesystem
+func (p *FusionStorageNasPlugin) DeleteNFSShare(ctx context.Context, name string) error {
+	nas := volume.NewNAS(p.cli)
+	return nas.DeleteShare(ctx, name)
+}
+
+// RestoreNFSShare used to recreate the deleted nfs share of the volume
+func (p *FusionStorageNasPlugin) RestoreNFSShare(ctx context.Context, name string,
+	parameters map[string]interface{}) error {
+	params, err := p.getParams(ctx, name, parameters)
+	if err != nil {
+		return err
+	}
+
+	nas := volume.NewNAS(p.cli)
+	return nas.RestoreShare(ctx, params)
+}
+
 // UpdateBackendCapabilities to update the backend capabilities, such as thin, thick, qos and etc.
 func (p *FusionStorageNasPlugin) UpdateBackendCapabilities(ctx context.Context) (map[string]interface{},
 	map[string]interface{}, error) {
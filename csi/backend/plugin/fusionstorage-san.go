@@ -114,11 +114,11 @@ func (p *FusionStorageSanPlugin) Init(ctx context.Context, config map[string]int
 	return nil
 }
 
-func (p *FusionStorageSanPlugin) getParams(name string,
+func (p *FusionStorageSanPlugin) getParams(ctx context.Context, name string,
 	parameters map[string]interface{}) (map[string]interface{}, error) {
 	params := map[string]interface{}{
 		"name":        name,
-		"description": parameters["description"].(string),
+		"description": getFusionStorageDescription(ctx, parameters),
 		"capacity":    utils.RoundUpSize(parameters["size"].(int64), CAPACITY_UNIT),
 	}
 
@@ -153,7 +153,7 @@ func (p *FusionStorageSanPlugin) CreateVolume(ctx context.Context, name string,
 		return nil, errors.New(msg)
 	}
 
-	params, err := p.getParams(name, parameters)
+	params, err := p.getParams(ctx, name, parameters)
 	if err != nil {
 		return nil, err
 	}
@@ -222,7 +222,7 @@ func (p *FusionStorageSanPlugin) DetachVolume(ctx context.Context,
 
 func (p *FusionStorageSanPlugin) mutexReleaseClient(ctx context.Context,
 	plugin *FusionStorageSanPlugin,
-	cli *client.Client) {
+	cli client.BaseClientInterface) {
 	plugin.clientMutex.Lock()
 	defer plugin.clientMutex.Unlock()
 	plugin.clientCount--
@@ -232,7 +232,7 @@ func (p *FusionStorageSanPlugin) mutexReleaseClient(ctx context.Context,
 	}
 }
 
-func (p *FusionStorageSanPlugin) releaseClient(ctx context.Context, cli *client.Client) {
+func (p *FusionStorageSanPlugin) releaseClient(ctx context.Context, cli client.BaseClientInterface) {
 	if p.storageOnline {
 		p.mutexReleaseClient(ctx, p, cli)
 	}
@@ -0,0 +1,199 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package plugin
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+
+	"huawei-csi-driver/storage/fusionstorage/client/mock"
+)
+
+var errFusionStorageQuery = errors.New("query storage failed")
+
+func newMockFusionStorageSanPlugin(t *testing.T) (*FusionStorageSanPlugin, *mock.MockFusionStorageClient) {
+	cli := mock.NewMockFusionStorageClient(gomock.NewController(t))
+	return &FusionStorageSanPlugin{FusionStoragePlugin: FusionStoragePlugin{cli: cli}}, cli
+}
+
+func TestFusionStorageSanPlugin_CreateVolume(t *testing.T) {
+	tests := []struct {
+		name    string
+		size    int64
+		mock    func(cli *mock.MockFusionStorageClient)
+		wantErr bool
+	}{
+		{"CapacityNotMultipleOfUnit", CAPACITY_UNIT + 512, func(cli *mock.MockFusionStorageClient) {}, true},
+		{"GetPoolFailed", 10 * CAPACITY_UNIT, func(cli *mock.MockFusionStorageClient) {
+			cli.EXPECT().GetPoolByName(gomock.Any(), "pool1").Return(nil, errFusionStorageQuery)
+		}, true},
+		{"Success", 10 * CAPACITY_UNIT, func(cli *mock.MockFusionStorageClient) {
+			cli.EXPECT().GetPoolByName(gomock.Any(), "pool1").Return(map[string]interface{}{"poolId": 1.0}, nil)
+			cli.EXPECT().GetVolumeByName(gomock.Any(), "pvc-1").Return(nil, nil)
+			cli.EXPECT().CreateVolume(gomock.Any(), gomock.Any()).Return(nil)
+		}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, cli := newMockFusionStorageSanPlugin(t)
+			tt.mock(cli)
+
+			vol, err := p.CreateVolume(ctx, "pvc-1", map[string]interface{}{
+				"size":        tt.size,
+				"description": "created by csi",
+				"storagepool": "pool1",
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CreateVolume() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && vol.GetVolumeName() != "pvc-1" {
+				t.Errorf("CreateVolume() volume name = %s, want pvc-1", vol.GetVolumeName())
+			}
+		})
+	}
+}
+
+func TestFusionStorageSanPlugin_DeleteVolume(t *testing.T) {
+	tests := []struct {
+		name    string
+		mock    func(cli *mock.MockFusionStorageClient)
+		wantErr bool
+	}{
+		{"GetVolumeFailed", func(cli *mock.MockFusionStorageClient) {
+			cli.EXPECT().GetVolumeByName(gomock.Any(), "pvc-1").Return(nil, errFusionStorageQuery)
+		}, true},
+		{"NotExist", func(cli *mock.MockFusionStorageClient) {
+			cli.EXPECT().GetVolumeByName(gomock.Any(), "pvc-1").Return(nil, nil)
+		}, false},
+		{"Success", func(cli *mock.MockFusionStorageClient) {
+			cli.EXPECT().GetVolumeByName(gomock.Any(), "pvc-1").
+				Return(map[string]interface{}{"volName": "pvc-1"}, nil)
+			cli.EXPECT().GetQoSNameByVolume(gomock.Any(), "pvc-1").Return("", nil)
+			cli.EXPECT().DeleteVolume(gomock.Any(), "pvc-1").Return(nil)
+		}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, cli := newMockFusionStorageSanPlugin(t)
+			tt.mock(cli)
+
+			if err := p.DeleteVolume(ctx, "pvc-1"); (err != nil) != tt.wantErr {
+				t.Errorf("DeleteVolume() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFusionStorageSanPlugin_ExpandVolume(t *testing.T) {
+	tests := []struct {
+		name         string
+		size         int64
+		mock         func(cli *mock.MockFusionStorageClient)
+		wantAttached bool
+		wantErr      bool
+	}{
+		{"CapacityNotMultipleOfUnit", 20*CAPACITY_UNIT + 512, func(cli *mock.MockFusionStorageClient) {},
+			false, true},
+		{"GetVolumeFailed", 20 * CAPACITY_UNIT, func(cli *mock.MockFusionStorageClient) {
+			cli.EXPECT().GetVolumeByName(gomock.Any(), "pvc-1").Return(nil, errFusionStorageQuery)
+		}, false, true},
+		{"Success", 20 * CAPACITY_UNIT, func(cli *mock.MockFusionStorageClient) {
+			cli.EXPECT().GetVolumeByName(gomock.Any(), "pvc-1").Return(map[string]interface{}{
+				"volName": "pvc-1", "volType": 0.0, "volSize": 10.0, "poolId": 1.0}, nil)
+			cli.EXPECT().GetPoolById(gomock.Any(), int64(1)).Return(map[string]interface{}{"poolId": 1.0}, nil)
+			cli.EXPECT().ExtendVolume(gomock.Any(), "pvc-1", int64(20)).Return(nil)
+		}, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, cli := newMockFusionStorageSanPlugin(t)
+			tt.mock(cli)
+
+			attached, err := p.ExpandVolume(ctx, "pvc-1", tt.size)
+			if (err != nil) != tt.wantErr || attached != tt.wantAttached {
+				t.Errorf("ExpandVolume() = %v, %v, want %v, wantErr %v", attached, err, tt.wantAttached, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFusionStorageSanPlugin_CreateSnapshot(t *testing.T) {
+	tests := []struct {
+		name    string
+		mock    func(cli *mock.MockFusionStorageClient)
+		wantErr bool
+	}{
+		{"GetVolumeFailed", func(cli *mock.MockFusionStorageClient) {
+			cli.EXPECT().GetVolumeByName(gomock.Any(), "pvc-1").Return(nil, errFusionStorageQuery)
+		}, true},
+		{"Success", func(cli *mock.MockFusionStorageClient) {
+			cli.EXPECT().GetVolumeByName(gomock.Any(), "pvc-1").Return(map[string]interface{}{"volId": 1.0}, nil)
+			cli.EXPECT().GetSnapshotByName(gomock.Any(), "snapshot-1").Return(nil, nil)
+			cli.EXPECT().CreateSnapshot(gomock.Any(), "snapshot-1", "pvc-1").Return(nil)
+			cli.EXPECT().GetSnapshotByName(gomock.Any(), "snapshot-1").Return(map[string]interface{}{
+				"createTime": "1700000000", "snapshotSize": 10.0}, nil)
+		}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, cli := newMockFusionStorageSanPlugin(t)
+			tt.mock(cli)
+
+			snapshot, err := p.CreateSnapshot(ctx, "pvc-1", "snapshot-1")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CreateSnapshot() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && snapshot["SizeBytes"] != 10*CAPACITY_UNIT {
+				t.Errorf("CreateSnapshot() SizeBytes = %v, want %d", snapshot["SizeBytes"], 10*CAPACITY_UNIT)
+			}
+		})
+	}
+}
+
+func TestFusionStorageSanPlugin_DeleteSnapshot(t *testing.T) {
+	tests := []struct {
+		name    string
+		mock    func(cli *mock.MockFusionStorageClient)
+		wantErr bool
+	}{
+		{"GetSnapshotFailed", func(cli *mock.MockFusionStorageClient) {
+			cli.EXPECT().GetSnapshotByName(gomock.Any(), "snapshot-1").Return(nil, errFusionStorageQuery)
+		}, true},
+		{"Success", func(cli *mock.MockFusionStorageClient) {
+			cli.EXPECT().GetSnapshotByName(gomock.Any(), "snapshot-1").
+				Return(map[string]interface{}{"snapshotName": "snapshot-1"}, nil)
+			cli.EXPECT().DeleteSnapshot(gomock.Any(), "snapshot-1").Return(nil)
+		}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, cli := newMockFusionStorageSanPlugin(t)
+			tt.mock(cli)
+
+			if err := p.DeleteSnapshot(ctx, "", "snapshot-1"); (err != nil) != tt.wantErr {
+				t.Errorf("DeleteSnapshot() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -48,7 +48,7 @@ const (
 // FusionStoragePlugin defines the plugin for Fusion storage
 type FusionStoragePlugin struct {
 	basePlugin
-	cli *client.Client
+	cli client.BaseClientInterface
 }
 
 func (p *FusionStoragePlugin) init(ctx context.Context, config map[string]interface{}, keepLogin bool) error {
@@ -77,11 +77,11 @@ func (p *FusionStoragePlugin) init(ctx context.Context, config map[string]interf
 	return nil
 }
 
-func (p *FusionStoragePlugin) getParams(name string,
+func (p *FusionStoragePlugin) getParams(ctx context.Context, name string,
 	parameters map[string]interface{}) (map[string]interface{}, error) {
 	params := map[string]interface{}{
 		"name":        name,
-		"description": parameters["description"].(string),
+		"description": getFusionStorageDescription(ctx, parameters),
 		"capacity":    utils.RoundUpSize(parameters["size"].(int64), CAPACITY_UNIT),
 	}
 
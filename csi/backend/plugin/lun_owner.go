@@ -0,0 +1,123 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync/atomic"
+
+	"huawei-csi-driver/storage/oceanstor/client"
+	"huawei-csi-driver/utils"
+)
+
+// lunOwnerAutoBalance means that the owning controllers of the luns are chosen in turn
+const lunOwnerAutoBalance = "auto-balance"
+
+// lunOwnerBalancer chooses the owning controllers of the luns created by the backend in turn
+type lunOwnerBalancer struct {
+	next uint64
+}
+
+func (b *lunOwnerBalancer) choose(controllers []string) string {
+	n := atomic.AddUint64(&b.next, 1) - 1
+	return controllers[n%uint64(len(controllers))]
+}
+
+// getControllerIDs returns the sorted IDs of the controllers of the storage
+func getControllerIDs(ctx context.Context, cli client.BaseClientInterface) ([]string, error) {
+	controllers, err := cli.GetAllControllers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []string
+	for _, controller := range controllers {
+		if id, _ := utils.ToStringWithFlag(controller["ID"]); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// GetOwnerControllers returns the controllers of the storage which can own the luns, the Dorado storage chooses
+// the owning controllers of the luns itself
+func (p *OceanstorSanPlugin) GetOwnerControllers(ctx context.Context) ([]string, error) {
+	if p.product == "Dorado" || p.product == "DoradoV6" {
+		return nil, nil
+	}
+	return getControllerIDs(ctx, p.cli)
+}
+
+// resolveLunOwner validates the owning controller requested by the StorageClass against the controllers of
+// the storage, and the auto-balance owner is resolved to the next controller of the backend
+func (p *OceanstorSanPlugin) resolveLunOwner(ctx context.Context, owner string) (string, error) {
+	if owner == "" {
+		return "", nil
+	}
+
+	if p.product == "Dorado" || p.product == "DoradoV6" {
+		return "", fmt.Errorf("the owning controller %s of lun is not allowed by %s storage", owner, p.product)
+	}
+
+	controllers, err := getControllerIDs(ctx, p.cli)
+	if err != nil {
+		return "", fmt.Errorf("get controllers of storage failed, error: %v", err)
+	}
+	if len(controllers) == 0 {
+		return "", fmt.Errorf("no controller of storage is found for the owning controller %s", owner)
+	}
+
+	if owner == lunOwnerAutoBalance {
+		return p.ownerBalancer.choose(controllers), nil
+	}
+
+	if !utils.IsContain(owner, controllers) {
+		return "", fmt.Errorf("the owning controller %s is not one of the controllers %v", owner, controllers)
+	}
+	return owner, nil
+}
+
+// resolveLunOwners resolves the localOwner and remoteOwner parameters to the owning controllers of the luns on
+// both sides of the hyperMetro pair
+func (p *OceanstorSanPlugin) resolveLunOwners(ctx context.Context, parameters, params map[string]interface{}) error {
+	localOwner, _ := parameters["localOwner"].(string)
+	remoteOwner, _ := parameters["remoteOwner"].(string)
+
+	owner, err := p.resolveLunOwner(ctx, localOwner)
+	if err != nil {
+		return utils.Errorf(ctx, "invalid parameter localOwner: %v", err)
+	}
+	params["localowner"] = owner
+
+	if remoteOwner == "" {
+		return nil
+	}
+
+	if hyperMetro, _ := params["hypermetro"].(bool); !hyperMetro || p.metroRemotePlugin == nil {
+		return utils.Errorf(ctx, "parameter remoteOwner is only allowed for the hyperMetro volume")
+	}
+
+	owner, err = p.metroRemotePlugin.resolveLunOwner(ctx, remoteOwner)
+	if err != nil {
+		return utils.Errorf(ctx, "invalid parameter remoteOwner: %v", err)
+	}
+	params["remoteowner"] = owner
+	return nil
+}
@@ -0,0 +1,95 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package plugin
+
+import (
+	"context"
+	"testing"
+
+	"huawei-csi-driver/storage/oceanstor/client"
+)
+
+type fakeControllerClient struct {
+	client.BaseClientInterface
+
+	controllers []map[string]interface{}
+}
+
+func (f *fakeControllerClient) GetAllControllers(ctx context.Context) ([]map[string]interface{}, error) {
+	return f.controllers, nil
+}
+
+func newLunOwnerPlugin(product string) *OceanstorSanPlugin {
+	p := &OceanstorSanPlugin{}
+	p.product = product
+	p.cli = &fakeControllerClient{controllers: []map[string]interface{}{{"ID": "0B"}, {"ID": "0A"}}}
+	return p
+}
+
+func TestResolveLunOwner(t *testing.T) {
+	tests := []struct {
+		name    string
+		product string
+		owner   string
+		want    string
+		wantErr bool
+	}{
+		{"NotSet", "V5", "", "", false},
+		{"Controller", "V5", "0B", "0B", false},
+		{"UnknownController", "V5", "1A", "", true},
+		{"AutoBalance", "V5", lunOwnerAutoBalance, "0A", false},
+		{"NotAllowed", "DoradoV6", "0A", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := newLunOwnerPlugin(tt.product).resolveLunOwner(context.Background(), tt.owner)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("resolveLunOwner() = %s, error = %v, want %s, wantErr %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestResolveLunOwnersAutoBalance(t *testing.T) {
+	p := newLunOwnerPlugin("V5")
+	p.metroRemotePlugin = newLunOwnerPlugin("V5")
+
+	var got []string
+	for i := 0; i < 3; i++ {
+		params := map[string]interface{}{"hypermetro": true}
+		parameters := map[string]interface{}{"localOwner": lunOwnerAutoBalance, "remoteOwner": "0B"}
+		if err := p.resolveLunOwners(context.Background(), parameters, params); err != nil {
+			t.Fatalf("resolveLunOwners() error = %v", err)
+		}
+		got = append(got, params["localowner"].(string)+"/"+params["remoteowner"].(string))
+	}
+
+	want := []string{"0A/0B", "0B/0B", "0A/0B"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("resolveLunOwners() = %v, want %v", got, want)
+			break
+		}
+	}
+
+	err := p.resolveLunOwners(context.Background(), map[string]interface{}{"remoteOwner": "0A"},
+		map[string]interface{}{})
+	if err == nil {
+		t.Errorf("resolveLunOwners() expects error for the remoteOwner of the volume without hyperMetro")
+	}
+}
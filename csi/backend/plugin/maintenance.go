@@ -0,0 +1,52 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package plugin
+
+import (
+	"context"
+	"fmt"
+
+	"huawei-csi-driver/utils/log"
+)
+
+// the running status of the storage system during which the results of the operations are unpredictable
+const (
+	systemRunningStatusPoweringOn  = "12"
+	systemRunningStatusPoweringOff = "47"
+	systemRunningStatusUpgrading   = "51"
+)
+
+// IsInMaintenance checks whether the storage is in maintenance by the running status of the system
+func (p *OceanstorPlugin) IsInMaintenance(ctx context.Context) (bool, error) {
+	system, err := p.cli.GetSystem(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	status, ok := system["RUNNINGSTATUS"].(string)
+	if !ok {
+		return false, fmt.Errorf("convert RUNNINGSTATUS to string failed, data: %v", system["RUNNINGSTATUS"])
+	}
+
+	switch status {
+	case systemRunningStatusPoweringOn, systemRunningStatusPoweringOff, systemRunningStatusUpgrading:
+		log.AddContext(ctx).Debugf("The running status of the storage %s is %s", p.cli.GetDeviceSN(), status)
+		return true, nil
+	default:
+		return false, nil
+	}
+}
@@ -0,0 +1,69 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package plugin
+
+import (
+	"huawei-csi-driver/utils/log"
+)
+
+// MetroState is the online state of the HyperMetro storage pair, which decides the attacher path
+type MetroState int
+
+const (
+	// MetroStateBothOnline means both storages are online, the luns are attached by the metro attacher
+	MetroStateBothOnline MetroState = iota
+	// MetroStateLocalOnly means only the local storage is online, the luns are attached by the local attacher
+	MetroStateLocalOnly
+	// MetroStateRemoteOnly means only the remote storage is online, the luns are attached by the remote attacher
+	MetroStateRemoteOnly
+	// MetroStateBothOffline means both storages are offline, the luns can not be attached
+	MetroStateBothOffline
+)
+
+var metroStateNames = map[MetroState]string{
+	MetroStateBothOnline:  "BothOnline",
+	MetroStateLocalOnly:   "LocalOnly",
+	MetroStateRemoteOnly:  "RemoteOnly",
+	MetroStateBothOffline: "BothOffline",
+}
+
+func (s MetroState) String() string {
+	if name, exist := metroStateNames[s]; exist {
+		return name
+	}
+	return "Unknown"
+}
+
+// transition returns the next state of the HyperMetro storage pair by the online status of the storages
+func transition(current MetroState, localOnline, remoteOnline bool) MetroState {
+	var next MetroState
+	switch {
+	case localOnline && remoteOnline:
+		next = MetroStateBothOnline
+	case localOnline:
+		next = MetroStateLocalOnly
+	case remoteOnline:
+		next = MetroStateRemoteOnly
+	default:
+		next = MetroStateBothOffline
+	}
+
+	if next != current {
+		log.Infof("HyperMetro state changes from %s to %s", current, next)
+	}
+	return next
+}
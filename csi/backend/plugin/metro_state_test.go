@@ -0,0 +1,61 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package plugin
+
+import (
+	"testing"
+)
+
+func TestMetroStateTransition(t *testing.T) {
+	local := &OceanstorSanPlugin{}
+	remote := &OceanstorSanPlugin{}
+	local.metroRemotePlugin = remote
+
+	tests := []struct {
+		name         string
+		localOnline  bool
+		remoteOnline bool
+		wantState    MetroState
+		wantPlugin   *OceanstorSanPlugin
+		wantMetro    bool
+		wantErr      bool
+	}{
+		{"BothOnline", true, true, MetroStateBothOnline, local, true, false},
+		{"LocalOnly", true, false, MetroStateLocalOnly, local, false, false},
+		{"RemoteOnly", false, true, MetroStateRemoteOnly, remote, false, false},
+		{"BothOffline", false, false, MetroStateBothOffline, nil, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, current := range []MetroState{MetroStateBothOnline, MetroStateLocalOnly,
+				MetroStateRemoteOnly, MetroStateBothOffline} {
+				if got := transition(current, tt.localOnline, tt.remoteOnline); got != tt.wantState {
+					t.Errorf("transition(%s) = %s, want %s", current, got, tt.wantState)
+				}
+			}
+
+			local.storageOnline, remote.storageOnline = tt.localOnline, tt.remoteOnline
+			state := local.updateMetroState()
+			plugin, useMetro, err := local.selectMetroPath(state)
+			if plugin != tt.wantPlugin || useMetro != tt.wantMetro || (err != nil) != tt.wantErr {
+				t.Errorf("selectMetroPath(%s) = %p, %v, %v, want %p, %v, error %v", state, plugin, useMetro,
+					err, tt.wantPlugin, tt.wantMetro, tt.wantErr)
+			}
+		})
+	}
+}
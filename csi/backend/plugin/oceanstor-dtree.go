@@ -20,6 +20,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 
 	v1 "huawei-csi-driver/client/apis/xuanwu/v1"
 	"huawei-csi-driver/pkg/constants"
@@ -35,6 +36,9 @@ const (
 	DTreeStorage = "oceanstor-dtree"
 )
 
+// dualProtocolFeatures are the license features required to share the DTree by both NFS and CIFS
+var dualProtocolFeatures = []string{"NFS", "CIFS"}
+
 // OceanstorDTreePlugin implements storage Plugin interface
 type OceanstorDTreePlugin struct {
 	OceanstorPlugin
@@ -99,15 +103,16 @@ func (p *OceanstorDTreePlugin) CreateVolume(ctx context.Context, name string, pa
 		return nil, errors.New(msg)
 	}
 
+	parentName := p.parentName
 	parameters["vstoreId"] = p.vStoreId
-	parameters["parentname"] = p.parentName
+	parameters["parentname"] = parentName
 	params := p.getParams(ctx, name, parameters)
 
 	volObj, err := p.getDTreeObj().Create(ctx, params)
 	if err != nil {
 		return nil, err
 	}
-	volObj.SetDTreeParentName(p.parentName)
+	volObj.SetDTreeParentName(parentName)
 
 	return volObj, nil
 }
@@ -128,7 +133,7 @@ func (p *OceanstorDTreePlugin) DeleteDTreeVolume(ctx context.Context, params map
 		return errors.New("empty parameters")
 	}
 	params["vstoreid"] = p.vStoreId
-	params["parentname"] = p.parentName
+	params["parentname"] = p.getParentName(params)
 
 	return p.getDTreeObj().Delete(ctx, params)
 
@@ -151,17 +156,81 @@ func (p *OceanstorDTreePlugin) ExpandDTreeVolume(ctx context.Context, params map
 		return false, errors.New(msg)
 	}
 
-	parentName, _ := utils.ToStringWithFlag(params["parentname"])
-	err := dTree.Expand(ctx, parentName, dTreeName, p.vStoreId, 0, spaceHardQuota)
+	// the volumes created without the soft quota are expanded with the hard quota only
+	percent, _ := utils.ToStringWithFlag(params["spacesoftquotapercent"])
+	spaceSoftQuota, err := volume.GetSpaceSoftQuota(spaceHardQuota, percent)
+	if err != nil {
+		log.AddContext(ctx).Errorf("expand dTree volume failed, error: %v", err)
+		return false, err
+	}
+
+	parentName := p.getParentName(params)
+	err = dTree.Expand(ctx, parentName, dTreeName, p.vStoreId, spaceSoftQuota, spaceHardQuota)
 	if err != nil {
 		log.AddContext(ctx).Errorf("expand dTree volume failed, ")
 		return false, err
 	}
 	log.AddContext(ctx).Infof("expand dTree volume success, parentName: %v, dTreeName: %v,"+
-		" vStoreId: %v, spaceHardQuota: %v", params, dTreeName, p.vStoreId, spaceHardQuota)
+		" vStoreId: %v, spaceHardQuota: %v, spaceSoftQuota: %v", parentName, dTreeName, p.vStoreId,
+		spaceHardQuota, spaceSoftQuota)
 	return false, nil
 }
 
+// MigrateVolume moves the DTree volume to another parent filesystem without data copy, the parent of the
+// plugin is not changed, the caller records the new parent of the volume and passes it to the later operations
+func (p *OceanstorDTreePlugin) MigrateVolume(ctx context.Context, name, srcParent, dstParent string) error {
+	if srcParent == dstParent {
+		return nil
+	}
+
+	fs, err := p.cli.GetFileSystemByName(ctx, dstParent)
+	if err != nil {
+		return pkgUtils.Errorf(ctx, "get target filesystem %s error: %v", dstParent, err)
+	}
+	if fs == nil {
+		return pkgUtils.Errorf(ctx, "target filesystem %s does not exist", dstParent)
+	}
+
+	// the dTree is already under the target when the previous move succeeded but its record failed
+	dTree, err := p.cli.GetDTreeByName(ctx, "", dstParent, p.vStoreId, name)
+	if err != nil {
+		return pkgUtils.Errorf(ctx, "get dTree %s under %s error: %v", name, dstParent, err)
+	}
+	if dTree != nil {
+		log.AddContext(ctx).Infof("dTree %s is already under %s", name, dstParent)
+		return nil
+	}
+
+	if err = p.cli.MoveDTree(ctx, srcParent, dstParent, name); err != nil {
+		log.AddContext(ctx).Errorf("move dTree %s from %s to %s failed, error: %v", name, srcParent, dstParent, err)
+		if strings.Contains(err.Error(), client.UrlNotFound) {
+			return fmt.Errorf("the storage does not support moving dTree %s to another filesystem: %w",
+				name, err)
+		}
+		return err
+	}
+
+	// the new parent is recorded only if the dTree is found under it
+	dTree, err = p.cli.GetDTreeByName(ctx, "", dstParent, p.vStoreId, name)
+	if err != nil {
+		return pkgUtils.Errorf(ctx, "get dTree %s under %s error: %v", name, dstParent, err)
+	}
+	if dTree == nil {
+		return pkgUtils.Errorf(ctx, "dTree %s is not found under %s after the move", name, dstParent)
+	}
+
+	log.AddContext(ctx).Infof("move dTree %s from %s to %s success", name, srcParent, dstParent)
+	return nil
+}
+
+// getParentName returns the parent filesystem of the volume given by the caller, or the parent of the backend
+func (p *OceanstorDTreePlugin) getParentName(params map[string]interface{}) string {
+	if parentName, _ := utils.ToStringWithFlag(params["parentname"]); parentName != "" {
+		return parentName
+	}
+	return p.parentName
+}
+
 // DeleteVolume used to delete volume
 func (p *OceanstorDTreePlugin) DeleteVolume(ctx context.Context, name string) error {
 	return errors.New("not implement")
@@ -264,6 +333,8 @@ func (p *OceanstorDTreePlugin) UpdateBackendCapabilities(ctx context.Context) (m
 	capabilities[string(constants.SupportClone)] = false
 	capabilities[string(constants.SupportApplicationType)] = false
 	capabilities[string(constants.SupportQoS)] = false
+	capabilities[string(constants.SupportEncryption)] = false
+	capabilities[string(constants.SupportPerFsDedup)] = false
 
 	err = p.updateSmartThin(capabilities)
 	if err != nil {
@@ -321,6 +392,29 @@ func (p *OceanstorDTreePlugin) updateNFS4Capability(ctx context.Context, capabil
 	return nil
 }
 
+// GetMissingLicenses returns the NFS and CIFS licenses which are not present on the storage for the
+// dual-protocol DTree, the volume is still created so that the storage reports the exact error if the CIFS share
+// is rejected
+func (p *OceanstorDTreePlugin) GetMissingLicenses(ctx context.Context, parameters map[string]interface{}) (
+	[]string, error) {
+	if dualProtocol, _ := parameters["dualProtocol"].(string); !utils.StrToBool(ctx, dualProtocol) {
+		return nil, nil
+	}
+
+	features, err := p.cli.GetLicenseFeature(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var missing []string
+	for _, feature := range dualProtocolFeatures {
+		if !utils.IsSupportFeature(features, feature) {
+			missing = append(missing, feature)
+		}
+	}
+	return missing, nil
+}
+
 // updateSmartThin for fileSystem on dorado storage, only Thin is supported
 func (p *OceanstorDTreePlugin) updateSmartThin(capabilities map[string]interface{}) error {
 	if capabilities == nil {
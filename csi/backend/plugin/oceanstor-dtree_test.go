@@ -0,0 +1,83 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package plugin
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"huawei-csi-driver/storage/oceanstor/client"
+)
+
+type fakeMoveDTreeClient struct {
+	client.BaseClientInterface
+	parents map[string]string
+	moveErr error
+	moves   int
+}
+
+func (c *fakeMoveDTreeClient) GetFileSystemByName(_ context.Context, name string) (map[string]interface{}, error) {
+	return map[string]interface{}{"NAME": name}, nil
+}
+
+func (c *fakeMoveDTreeClient) GetDTreeByName(_ context.Context, _, parentName, _, name string) (
+	map[string]interface{}, error) {
+	if c.parents[name] != parentName {
+		return nil, nil
+	}
+	return map[string]interface{}{"NAME": name}, nil
+}
+
+func (c *fakeMoveDTreeClient) MoveDTree(_ context.Context, _, dstParent, dTreeName string) error {
+	c.moves++
+	if c.moveErr != nil {
+		return c.moveErr
+	}
+	c.parents[dTreeName] = dstParent
+	return nil
+}
+
+func TestOceanstorDTreePluginMigrateVolume(t *testing.T) {
+	tests := []struct {
+		name      string
+		parent    string
+		moveErr   error
+		wantMoves int
+		wantErr   bool
+	}{
+		{"Moved", "fs1", nil, 1, false},
+		{"AlreadyMoved", "fs2", nil, 0, false},
+		{"MoveFailed", "fs1", errors.New("move failed"), 1, true},
+		{"MoveNotSupported", "fs1", errors.New(client.UrlNotFound), 1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cli := &fakeMoveDTreeClient{parents: map[string]string{"dtree": tt.parent}, moveErr: tt.moveErr}
+			p := &OceanstorDTreePlugin{OceanstorPlugin: OceanstorPlugin{cli: cli}}
+
+			err := p.MigrateVolume(context.Background(), "dtree", "fs1", "fs2")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("MigrateVolume() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if cli.moves != tt.wantMoves {
+				t.Errorf("MigrateVolume() moves = %d, want %d", cli.moves, tt.wantMoves)
+			}
+		})
+	}
+}
@@ -51,9 +51,10 @@ var supportConsistentSnapshotsVersions = []string{"6.1.6"}
 // OceanstorNasPlugin implements storage Plugin interface
 type OceanstorNasPlugin struct {
 	OceanstorPlugin
-	portals       []string
-	vStorePairID  string
-	metroDomainID string
+	portals         []string
+	vStorePairID    string
+	metroDomainID   string
+	sharePathPrefix string
 
 	nasHyperMetro       volume.NASHyperMetro
 	metroRemotePlugin   *OceanstorNasPlugin
@@ -86,6 +87,11 @@ func (p *OceanstorNasPlugin) Init(ctx context.Context, config map[string]interfa
 		return err
 	}
 
+	p.sharePathPrefix, err = GetNfsSharePathPrefix(parameters)
+	if err != nil {
+		return err
+	}
+
 	err = p.init(ctx, config, keepLogin)
 	if err != nil {
 		log.AddContext(ctx).Errorf("init oceanstor nas failed, config: %+v, parameters: %+v err: %v",
@@ -133,7 +139,7 @@ func (p *OceanstorNasPlugin) getNasObj() *volume.NAS {
 		replicaRemoteCli = p.replicaRemotePlugin.cli
 	}
 
-	return volume.NewNAS(p.cli, metroRemoteCli, replicaRemoteCli, p.product, p.nasHyperMetro)
+	return volume.NewNAS(p.cli, metroRemoteCli, replicaRemoteCli, p.product, p.nasHyperMetro, p.sharePathPrefix)
 }
 
 // CreateVolume used to create volume
@@ -179,6 +185,26 @@ func (p *OceanstorNasPlugin) DeleteVolume(ctx context.Context, name string) erro
 	return nas.Delete(ctx, name)
 }
 
+// DeleteNFSShare used to delete the nfs share of the volume and keep the filesystem
+func (p *OceanstorNasPlugin) DeleteNFSShare(ctx context.Context, name string) error {
+	nas := p.getNasObj()
+	return nas.DeleteShare(ctx, name)
+}
+
+// RestoreNFSShare used to recreate the deleted nfs share of the volume
+func (p *OceanstorNasPlugin) RestoreNFSShare(ctx context.Context, name string,
+	parameters map[string]interface{}) error {
+	params := p.getParams(ctx, name, parameters)
+	nas := p.getNasObj()
+	return nas.RestoreShare(ctx, params)
+}
+
+// UpdateNFSShareClientACL used to update the clients which are allowed to access the nfs share of the volume
+func (p *OceanstorNasPlugin) UpdateNFSShareClientACL(ctx context.Context, name string, clients []string) error {
+	nas := p.getNasObj()
+	return nas.UpdateShareClientACL(ctx, name, clients)
+}
+
 // ExpandVolume used to expand volume
 func (p *OceanstorNasPlugin) ExpandVolume(ctx context.Context, name string, size int64) (bool, error) {
 	if !utils.IsCapacityAvailable(size, SectorSize) {
@@ -452,6 +478,10 @@ func (p *OceanstorNasPlugin) verifyOceanstorNasParam(ctx context.Context, config
 		return pkgUtils.Errorf(ctx, "check nas parameter failed, err: %v", err)
 	}
 
+	if _, err = GetNfsSharePathPrefix(parameters); err != nil {
+		return pkgUtils.Errorf(ctx, "check nas parameter failed, err: %v", err)
+	}
+
 	return nil
 }
 
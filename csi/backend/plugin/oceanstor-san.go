@@ -26,6 +26,7 @@ import (
 	"sync"
 
 	xuanwuV1 "huawei-csi-driver/client/apis/xuanwu/v1"
+	"huawei-csi-driver/csi/app"
 	"huawei-csi-driver/pkg/constants"
 	"huawei-csi-driver/proto"
 	"huawei-csi-driver/storage/oceanstor/attacher"
@@ -48,11 +49,22 @@ type OceanstorSanPlugin struct {
 	portals  []string
 	alua     map[string]interface{}
 
+	hostPolicy attacher.HostPolicy
+
+	lunBatchCreator *client.LunBatchCreator
+	ownerBalancer   lunOwnerBalancer
+
 	replicaRemotePlugin *OceanstorSanPlugin
 	metroRemotePlugin   *OceanstorSanPlugin
 	storageOnline       bool
 	clientCount         int
 	clientMutex         sync.Mutex
+	// logouts tracks the logouts sent outside the client mutex, the next login waits for them so that
+	// the new session isn't logged out by a late logout
+	logouts sync.WaitGroup
+
+	metroState      MetroState
+	metroStateMutex sync.Mutex
 }
 
 type handlerRequest struct {
@@ -83,6 +95,12 @@ func (p *OceanstorSanPlugin) Init(ctx context.Context, config map[string]interfa
 
 	p.alua, _ = parameters["ALUA"].(map[string]interface{})
 
+	p.hostPolicy.NamePrefix, _ = parameters["hostNamePrefix"].(string)
+	if len(p.hostPolicy.NamePrefix) > attacher.MaxHostNamePrefixLength {
+		return fmt.Errorf("hostNamePrefix %s must not be longer than %d characters",
+			p.hostPolicy.NamePrefix, attacher.MaxHostNamePrefixLength)
+	}
+
 	if protocol == "iscsi" || protocol == "roce" {
 		portals, exist := parameters["portals"].([]interface{})
 		if !exist {
@@ -94,6 +112,10 @@ func (p *OceanstorSanPlugin) Init(ctx context.Context, config map[string]interfa
 			return err
 		}
 
+		if err = checkMinPortals(parameters, IPs); err != nil {
+			return err
+		}
+
 		p.portals = IPs
 	}
 
@@ -111,6 +133,10 @@ func (p *OceanstorSanPlugin) Init(ctx context.Context, config map[string]interfa
 	p.protocol = protocol
 	p.storageOnline = true
 
+	if window := app.GetGlobalConfig().LunBatchCreateWindow; window > 0 && p.product == "DoradoV6" {
+		p.lunBatchCreator = client.NewLunBatchCreator(p.cli, window)
+	}
+
 	return nil
 }
 
@@ -125,7 +151,19 @@ func (p *OceanstorSanPlugin) getSanObj() *volume.SAN {
 		replicaRemoteCli = p.replicaRemotePlugin.cli
 	}
 
-	return volume.NewSAN(p.cli, metroRemoteCli, replicaRemoteCli, p.product)
+	san := volume.NewSAN(p.cli, metroRemoteCli, replicaRemoteCli, p.product)
+	if p.lunBatchCreator != nil {
+		san.WithLunBatchCreator(p.lunBatchCreator)
+	}
+	return san
+}
+
+// Logout is to stop the lun batch creator and logout the storage session
+func (p *OceanstorSanPlugin) Logout(ctx context.Context) {
+	if p.lunBatchCreator != nil {
+		p.lunBatchCreator.Stop()
+	}
+	p.OceanstorPlugin.Logout(ctx)
 }
 
 // CreateVolume used to create volume
@@ -140,8 +178,11 @@ func (p *OceanstorSanPlugin) CreateVolume(ctx context.Context,
 	}
 
 	params := p.getParams(ctx, name, parameters)
-	san := p.getSanObj()
+	if err := p.resolveLunOwners(ctx, parameters, params); err != nil {
+		return nil, err
+	}
 
+	san := p.getSanObj()
 	volObl, err := san.Create(ctx, params)
 	if err != nil {
 		return nil, err
@@ -149,6 +190,30 @@ func (p *OceanstorSanPlugin) CreateVolume(ctx context.Context,
 	return volObl, nil
 }
 
+// RemoveWriteProtect used to make the write-protected LUN writable again
+func (p *OceanstorSanPlugin) RemoveWriteProtect(ctx context.Context, name string) error {
+	lunName := p.cli.MakeLunName(name)
+	lun, err := p.cli.GetLunByName(ctx, lunName)
+	if err != nil {
+		log.AddContext(ctx).Errorf("Get LUN %s error: %v", lunName, err)
+		return err
+	}
+	if lun == nil {
+		log.AddContext(ctx).Errorf("LUN %s to remove write protection does not exist", lunName)
+		return fmt.Errorf("%w: lun %s to remove write protection does not exist", constants.ErrVolumeNotFound,
+			lunName)
+	}
+
+	lunID, _ := lun["ID"].(string)
+	if err = p.cli.ClearLunWriteProtect(ctx, lunID); err != nil {
+		log.AddContext(ctx).Errorf("Clear write protection of LUN %s error: %v", lunName, err)
+		return err
+	}
+
+	log.AddContext(ctx).Infof("Write protection of LUN %s is removed", lunName)
+	return nil
+}
+
 // QueryVolume used to query volume
 func (p *OceanstorSanPlugin) QueryVolume(ctx context.Context, name string, params map[string]interface{}) (
 	utils.Volume, error) {
@@ -219,9 +284,9 @@ func (p *OceanstorSanPlugin) metroHandler(ctx context.Context, req handlerReques
 		}
 	}
 
-	localAttacher := attacher.NewAttacher(p.product, req.localCli, p.protocol, "csi", p.portals, p.alua)
+	localAttacher := attacher.NewAttacher(p.product, req.localCli, p.protocol, "csi", p.portals, p.alua, p.hostPolicy)
 	remoteAttacher := attacher.NewAttacher(p.metroRemotePlugin.product, req.metroCli, p.metroRemotePlugin.protocol,
-		"csi", p.metroRemotePlugin.portals, p.metroRemotePlugin.alua)
+		"csi", p.metroRemotePlugin.portals, p.metroRemotePlugin.alua, p.metroRemotePlugin.hostPolicy)
 
 	metroAttacher := attacher.NewMetroAttacher(localAttacher, remoteAttacher, p.protocol)
 	lunName, ok := req.lun["NAME"].(string)
@@ -237,7 +302,7 @@ func (p *OceanstorSanPlugin) commonHandler(ctx context.Context,
 	plugin *OceanstorSanPlugin, lun, parameters map[string]interface{},
 	method string) ([]reflect.Value, error) {
 	commonAttacher := attacher.NewAttacher(plugin.product, plugin.cli, plugin.protocol, "csi",
-		plugin.portals, plugin.alua)
+		plugin.portals, plugin.alua, plugin.hostPolicy)
 
 	lunName, ok := lun["NAME"].(string)
 	if !ok {
@@ -248,26 +313,50 @@ func (p *OceanstorSanPlugin) commonHandler(ctx context.Context,
 }
 
 func (p *OceanstorSanPlugin) handler(ctx context.Context, req handlerRequest) ([]reflect.Value, error) {
-	var out []reflect.Value
-	var err error
-
 	if !p.isHyperMetro(ctx, req.lun) {
 		return p.commonHandler(ctx, p, req.lun, req.parameters, req.method)
 	}
 
-	if p.storageOnline && p.metroRemotePlugin != nil && p.metroRemotePlugin.storageOnline {
-		out, err = p.metroHandler(ctx, req)
-	} else if p.storageOnline {
-		log.AddContext(ctx).Warningf("the lun %s is hyperMetro, but just the local storage is online",
-			req.lun["NAME"].(string))
-		out, err = p.commonHandler(ctx, p, req.lun, req.parameters, req.method)
-	} else if p.metroRemotePlugin != nil && p.metroRemotePlugin.storageOnline {
-		log.AddContext(ctx).Warningf("the lun %s is hyperMetro, but just the remote storage is online",
-			req.lun["NAME"].(string))
-		out, err = p.commonHandler(ctx, p.metroRemotePlugin, req.lun, req.parameters, req.method)
+	state := p.updateMetroState()
+	if state != MetroStateBothOnline {
+		log.AddContext(ctx).Warningf("the lun %v is hyperMetro, but the HyperMetro state is %s",
+			req.lun["NAME"], state)
+	}
+
+	plugin, useMetro, err := p.selectMetroPath(state)
+	if err != nil {
+		return nil, err
+	}
+
+	if useMetro {
+		return p.metroHandler(ctx, req)
 	}
+	return p.commonHandler(ctx, plugin, req.lun, req.parameters, req.method)
+}
+
+// updateMetroState transits the HyperMetro state by the current online status of the storages
+func (p *OceanstorSanPlugin) updateMetroState() MetroState {
+	p.metroStateMutex.Lock()
+	defer p.metroStateMutex.Unlock()
 
-	return out, err
+	remoteOnline := p.metroRemotePlugin != nil && p.metroRemotePlugin.storageOnline
+	p.metroState = transition(p.metroState, p.storageOnline, remoteOnline)
+	return p.metroState
+}
+
+// selectMetroPath returns the plugin whose attacher is used in the HyperMetro state,
+// useMetro means the metro attacher of both storages is used
+func (p *OceanstorSanPlugin) selectMetroPath(state MetroState) (*OceanstorSanPlugin, bool, error) {
+	switch state {
+	case MetroStateBothOnline:
+		return p, true, nil
+	case MetroStateLocalOnly:
+		return p, false, nil
+	case MetroStateRemoteOnly:
+		return p.metroRemotePlugin, false, nil
+	default:
+		return nil, false, errors.New("the local and remote storages of the hyperMetro lun are both offline")
+	}
 }
 
 // AttachVolume attach volume to node,return storage mapping info.
@@ -289,14 +378,20 @@ func (p *OceanstorSanPlugin) AttachVolume(ctx context.Context, name string,
 		return nil, err
 	}
 	if lun == nil {
-		return nil, utils.Errorf(ctx, "Get empty lun info, lunName: %v", lunName)
+		log.AddContext(ctx).Errorf("Lun %s to attach does not exist", lunName)
+		return nil, fmt.Errorf("%w: lun %s to attach does not exist", constants.ErrVolumeNotFound, lunName)
+	}
+
+	if err = checkReplicationPairs(ctx, localCli, lun); err != nil {
+		return nil, err
 	}
 
 	var out []reflect.Value
 	out, err = p.handler(ctx, handlerRequest{localCli: localCli, metroCli: metroCli,
 		lun: lun, parameters: parameters, method: "ControllerAttach"})
 	if err != nil {
-		return nil, utils.Errorf(ctx, "Storage connect for volume %s error: %v", lunName, err)
+		log.AddContext(ctx).Errorf("Storage connect for volume %s error: %v", lunName, err)
+		return nil, fmt.Errorf("storage connect for volume %s error: %w", lunName, err)
 	}
 
 	if len(out) != reflectResultLength {
@@ -355,15 +450,81 @@ func (p *OceanstorSanPlugin) DetachVolume(ctx context.Context, name string, para
 	return nil
 }
 
+// IsVolumeMapped checks whether the lun is still mapped to the node on the online storages,
+// the lun of HyperMetro must be mapped on both storages if both are online
+func (p *OceanstorSanPlugin) IsVolumeMapped(ctx context.Context, name string,
+	parameters map[string]interface{}) (bool, error) {
+	lunName := p.cli.MakeLunName(name)
+	lun, err := p.getLunInfo(ctx, p.cli, p.getMetroRemoteCli(), lunName)
+	if err != nil || lun == nil {
+		return false, err
+	}
+
+	var plugins []*OceanstorSanPlugin
+	if p.storageOnline {
+		plugins = append(plugins, p)
+	}
+	if p.isHyperMetro(ctx, lun) && p.metroRemotePlugin != nil && p.metroRemotePlugin.storageOnline {
+		plugins = append(plugins, p.metroRemotePlugin)
+	}
+
+	for _, plugin := range plugins {
+		checker := attacher.NewAttacher(plugin.product, plugin.cli, plugin.protocol, "csi", plugin.portals,
+			plugin.alua, plugin.hostPolicy)
+		mapped, err := checker.IsMapped(ctx, lunName, parameters)
+		if err != nil || !mapped {
+			return false, err
+		}
+	}
+
+	return len(plugins) != 0, nil
+}
+
+func (p *OceanstorSanPlugin) getMetroRemoteCli() client.BaseClientInterface {
+	if p.metroRemotePlugin == nil {
+		return nil
+	}
+	return p.metroRemotePlugin.cli
+}
+
+// DeleteHost used to delete the host of the node and its mapping objects on the storage
+func (p *OceanstorSanPlugin) DeleteHost(ctx context.Context, parameters map[string]interface{}) error {
+	if p.storageOnline {
+		localAttacher := attacher.NewAttacher(p.product, p.cli, p.protocol, "csi", p.portals, p.alua, p.hostPolicy)
+		if err := localAttacher.ControllerDeleteHost(ctx, parameters); err != nil {
+			return err
+		}
+	}
+
+	if p.metroRemotePlugin != nil && p.metroRemotePlugin.storageOnline {
+		remotePlugin := p.metroRemotePlugin
+		remoteAttacher := attacher.NewAttacher(remotePlugin.product, remotePlugin.cli, remotePlugin.protocol, "csi",
+			remotePlugin.portals, remotePlugin.alua, remotePlugin.hostPolicy)
+		if err := remoteAttacher.ControllerDeleteHost(ctx, parameters); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (p *OceanstorSanPlugin) mutexReleaseClient(ctx context.Context,
 	plugin *OceanstorSanPlugin,
 	cli client.BaseClientInterface) {
 	plugin.clientMutex.Lock()
-	defer plugin.clientMutex.Unlock()
 	plugin.clientCount--
-	if plugin.clientCount == 0 {
-		cli.Logout(ctx)
+	logout := plugin.clientCount == 0
+	if logout {
 		plugin.storageOnline = false
+		plugin.logouts.Add(1)
+	}
+	plugin.clientMutex.Unlock()
+
+	// the logout is sent without holding the client mutex, so that an unreachable storage doesn't block
+	// the other requests which get or release the client
+	if logout {
+		defer plugin.logouts.Done()
+		cli.Logout(ctx)
 	}
 }
 
@@ -469,6 +630,7 @@ func (p *OceanstorSanPlugin) mutexGetClient(ctx context.Context) (client.BaseCli
 	defer p.clientMutex.Unlock()
 	var err error
 	if !p.storageOnline || p.clientCount == 0 {
+		p.logouts.Wait()
 		err = p.cli.Login(ctx)
 		p.storageOnline = err == nil
 		if err == nil {
@@ -525,6 +687,8 @@ func (p *OceanstorSanPlugin) UpdateBackendCapabilities(ctx context.Context) (map
 	p.storageOnline = true
 	p.updateHyperMetroCapability(capabilities)
 	p.updateReplicaCapability(capabilities)
+	// deduplication can only be toggled on the filesystems
+	capabilities[string(constants.SupportPerFsDedup)] = false
 	return capabilities, specifications, nil
 }
 
@@ -599,10 +763,15 @@ func (p *OceanstorSanPlugin) verifyOceanstorSanParam(ctx context.Context, config
 			return errors.New(msg)
 		}
 
-		_, err := proto.VerifyIscsiPortals(ctx, portals)
+		IPs, err := proto.VerifyIscsiPortals(ctx, portals)
 		if err != nil {
 			return err
 		}
+
+		if err = checkMinPortals(parameters, IPs); err != nil {
+			log.AddContext(ctx).Errorf("Verify portals: [%v] failed. %v", parameters["portals"], err)
+			return err
+		}
 	}
 
 	return nil
@@ -0,0 +1,116 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package plugin
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"huawei-csi-driver/pkg/constants"
+	"huawei-csi-driver/storage/oceanstor/client"
+)
+
+func TestMutexReleaseClientWithHangingStorage(t *testing.T) {
+	hang := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-hang
+	}))
+	defer server.Close()
+	defer close(hang)
+
+	cli, err := client.NewClient(ctx, &client.NewClientConfig{Urls: []string{server.URL}})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	cli.Url = server.URL
+
+	p := &OceanstorSanPlugin{storageOnline: true, clientCount: 1}
+	released := make(chan struct{})
+	go func() {
+		p.mutexReleaseClient(ctx, p, cli)
+		close(released)
+	}()
+
+	// the other requests can get the client mutex while the logout hangs
+	time.Sleep(200 * time.Millisecond)
+	locked := make(chan struct{})
+	go func() {
+		p.clientMutex.Lock()
+		defer p.clientMutex.Unlock()
+		close(locked)
+	}()
+
+	select {
+	case <-locked:
+	case <-time.After(time.Second):
+		t.Errorf("mutexReleaseClient() holds the client mutex during the logout")
+	}
+
+	select {
+	case <-released:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("mutexReleaseClient() is not completed in 5 seconds")
+	}
+
+	if p.storageOnline {
+		t.Errorf("mutexReleaseClient() storageOnline = true, want false")
+	}
+}
+
+type fakeMissingLunClient struct {
+	client.BaseClientInterface
+	lun map[string]interface{}
+	err error
+}
+
+func (c *fakeMissingLunClient) MakeLunName(name string) string {
+	return name
+}
+
+func (c *fakeMissingLunClient) GetLunByName(context.Context, string) (map[string]interface{}, error) {
+	return c.lun, c.err
+}
+
+func TestOceanstorSanPluginAttachMissingVolume(t *testing.T) {
+	tests := []struct {
+		name         string
+		err          error
+		wantNotFound bool
+	}{
+		{"LunNotExist", nil, true},
+		{"QueryFailed", errors.New("storage is busy"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &OceanstorSanPlugin{OceanstorPlugin: OceanstorPlugin{cli: &fakeMissingLunClient{err: tt.err}},
+				storageOnline: true}
+
+			_, err := p.AttachVolume(context.Background(), "pvc_1", map[string]interface{}{})
+			if err == nil {
+				t.Fatalf("AttachVolume() error = nil, want error")
+			}
+			if errors.Is(err, constants.ErrVolumeNotFound) != tt.wantNotFound {
+				t.Errorf("AttachVolume() error = %v, want volume not found %t", err, tt.wantNotFound)
+			}
+		})
+	}
+}
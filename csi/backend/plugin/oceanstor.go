@@ -20,8 +20,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
 
 	xuanwuV1 "huawei-csi-driver/client/apis/xuanwu/v1"
 	"huawei-csi-driver/csi/app"
@@ -42,6 +47,20 @@ const (
 	ProtocolNfs = "nfs"
 	// ProtocolNfsPlus defines protocol type nfs+
 	ProtocolNfsPlus = "nfs+"
+
+	// requestRecordDumpDir is the sub directory of the log file dir to which the request records are dumped
+	requestRecordDumpDir = "request-records"
+
+	poolExpandWaitInterval = 10 * time.Second
+	gigabyte               = 1024 * 1024 * 1024
+
+	// authDomainTypeLDAP and authDomainTypeAD are the types of the auth domain of the user, only the LDAP domain
+	// users are allowed to log in
+	authDomainTypeLDAP = "ldap"
+	authDomainTypeAD   = "ad"
+
+	// poolQueryConcurrency is the max number of the concurrent pool queries of a backend
+	poolQueryConcurrency = 4
 )
 
 // OceanstorPlugin provides oceanstor plugin base operations
@@ -53,6 +72,14 @@ type OceanstorPlugin struct {
 	cli          client.BaseClientInterface
 	product      string
 	capabilities map[string]interface{}
+
+	// the number of qos policies which the storage supports, 0 means not checked before creating volumes
+	qosPolicyLimit int64
+
+	// syncedPools are the pools queried by the last update of the pool capabilities,
+	// so that the pools are selected by tags without querying the storage
+	syncedPools      map[string]interface{}
+	syncedPoolsMutex sync.RWMutex
 }
 
 func (p *OceanstorPlugin) init(ctx context.Context, config map[string]interface{}, keepLogin bool) error {
@@ -61,6 +88,11 @@ func (p *OceanstorPlugin) init(ctx context.Context, config map[string]interface{
 		return err
 	}
 
+	p.qosPolicyLimit, err = getQosPolicyLimit(config)
+	if err != nil {
+		return err
+	}
+
 	cli, err := client.NewClient(ctx, backendClientConfig)
 	if err != nil {
 		return err
@@ -71,6 +103,11 @@ func (p *OceanstorPlugin) init(ctx context.Context, config map[string]interface{
 		return err
 	}
 
+	if err = checkAuthDomain(ctx, cli, backendClientConfig.AuthDomain); err != nil {
+		cli.Logout(ctx)
+		return err
+	}
+
 	system, err := cli.GetSystem(ctx)
 	if err != nil {
 		log.AddContext(ctx).Errorf("get system info error: %v", err)
@@ -104,6 +141,41 @@ func (p *OceanstorPlugin) init(ctx context.Context, config map[string]interface{
 	return nil
 }
 
+// checkAuthDomain checks whether the auth domain of the user is configured on the storage,
+// the local user has no auth domain to check
+func checkAuthDomain(ctx context.Context, cli client.BaseClientInterface, authDomain string) error {
+	if authDomain == "" {
+		return nil
+	}
+
+	domain, err := cli.GetDomainByName(ctx, authDomain)
+	if err != nil {
+		log.AddContext(ctx).Errorf("get auth domain %s error: %v", authDomain, err)
+		return err
+	}
+	if domain == nil {
+		return pkgUtils.Errorf(ctx, "auth domain %s of the user does not exist on the storage", authDomain)
+	}
+
+	return nil
+}
+
+// checkAuthDomainType rejects the AD domain users, the login of the storage only takes the local user and the
+// LDAP user scopes
+func checkAuthDomainType(config map[string]interface{}) error {
+	authDomainType, _ := config["authDomainType"].(string)
+	switch authDomainType {
+	case "", authDomainTypeLDAP:
+		return nil
+	case authDomainTypeAD:
+		return errors.New("authDomainType ad is not supported, the AD domain users cannot log in to the " +
+			"storage, use a user of an LDAP domain or a local user of the storage")
+	default:
+		return fmt.Errorf("authDomainType %s is invalid, only %s is supported", authDomainType,
+			authDomainTypeLDAP)
+	}
+}
+
 func (p *OceanstorPlugin) formatInitParam(config map[string]interface{}) (res *client.NewClientConfig, err error) {
 	res = &client.NewClientConfig{}
 
@@ -136,14 +208,64 @@ func (p *OceanstorPlugin) formatInitParam(config map[string]interface{}) (res *c
 		return
 	}
 	res.VstoreName, _ = config["vstoreName"].(string)
+	res.AuthDomain, _ = config["authDomain"].(string)
+	if err = checkAuthDomainType(config); err != nil {
+		return
+	}
 	res.ParallelNum, _ = config["maxClientThreads"].(string)
 
 	res.UseCert, _ = config["useCert"].(bool)
 	res.CertSecretMeta, _ = config["certSecret"].(string)
+	res.Tracing = app.GetGlobalConfig().EnableTracing
+	res.RecordSize, res.RecordDumpDir = requestRecordConfig(config)
+	res.PoolTags, err = getPoolTags(config)
 
 	return
 }
 
+// getPoolTags returns the tags of the pools configured by the poolTags of the backend parameters, which maps
+// each pool name to its comma-separated key=value tags, e.g. {"pool1": "team=finance,tier=gold"}
+func getPoolTags(config map[string]interface{}) (map[string]map[string]string, error) {
+	parameters, _ := config["parameters"].(map[string]interface{})
+	configTags, exist := parameters["poolTags"]
+	if !exist {
+		return nil, nil
+	}
+
+	poolsTags, ok := configTags.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("poolTags %v must map the pool names to their tags", configTags)
+	}
+
+	result := make(map[string]map[string]string, len(poolsTags))
+	for poolName, value := range poolsTags {
+		tags, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("the poolTags of pool %s must be comma-separated key=value pairs", poolName)
+		}
+
+		poolTags, err := client.ParsePoolTags(tags)
+		if err != nil {
+			return nil, fmt.Errorf("the poolTags of pool %s are invalid, %v", poolName, err)
+		}
+		result[poolName] = poolTags
+	}
+
+	return result, nil
+}
+
+// requestRecordConfig returns the size of the request recorder and its dump directory, the size is 0 unless
+// the recording mode is enabled by the flag or by the recordRequests of the backend
+func requestRecordConfig(config map[string]interface{}) (int, string) {
+	recordRequests, _ := config["recordRequests"].(bool)
+	if !app.GetGlobalConfig().EnableRequestRecording && !recordRequests {
+		return 0, ""
+	}
+
+	return app.GetGlobalConfig().RequestRecordingSize,
+		filepath.Join(app.GetGlobalConfig().LogFileDir, requestRecordDumpDir)
+}
+
 func (p *OceanstorPlugin) updateBackendCapabilities(ctx context.Context) (map[string]interface{}, error) {
 	features, err := p.cli.GetLicenseFeature(ctx)
 	if err != nil {
@@ -161,6 +283,9 @@ func (p *OceanstorPlugin) updateBackendCapabilities(ctx context.Context) (map[st
 	supportReplication := utils.IsSupportFeature(features, "HyperReplication")
 	supportClone := utils.IsSupportFeature(features, "HyperClone") || utils.IsSupportFeature(features, "HyperCopy")
 	supportApplicationType := p.product == "DoradoV6"
+	supportEncryption := utils.IsSupportFeature(features, "HyperEncryption")
+	supportPerFsDedup := utils.IsSupportFeature(features, "SmartDedupe (for FileSystem)") ||
+		utils.IsSupportFeature(features, "SmartDedupe")
 
 	supportLabel := app.GetGlobalConfig().EnableLabel &&
 		p.cli.GetStorageVersion() >= constants.MinVersionSupportLabel &&
@@ -178,6 +303,8 @@ func (p *OceanstorPlugin) updateBackendCapabilities(ctx context.Context) (map[st
 		"SupportClone":           supportClone,
 		"SupportMetroNAS":        supportMetroNAS,
 		"SupportLabel":           supportLabel,
+		"SupportEncryption":      supportEncryption,
+		"SupportPerFsDedup":      supportPerFsDedup,
 	}
 
 	return capabilities, nil
@@ -207,13 +334,97 @@ func (p *OceanstorPlugin) updateBackendSpecifications(ctx context.Context) (map[
 		return nil, err
 	}
 
+	inventory, err := p.GetStorageInventory()
+	if err != nil {
+		return nil, err
+	}
+
 	specifications := map[string]interface{}{
-		"LocalDeviceSN":   p.cli.GetDeviceSN(),
+		"LocalDeviceSN":   inventory.SerialNumber,
 		"RemoteDevicesSN": devicesSN,
+		"StorageModel":    inventory.Model,
+		"FirmwareVersion": inventory.FirmwareVersion,
 	}
 	return specifications, nil
 }
 
+// GetStorageInventory returns the identity of the storage cached by the login and the system query of init
+func (p *OceanstorPlugin) GetStorageInventory() (StorageInventory, error) {
+	if p.cli == nil {
+		return StorageInventory{}, errors.New("the client of the backend is not initialized")
+	}
+
+	return StorageInventory{
+		SerialNumber:    p.cli.GetDeviceSN(),
+		Model:           p.cli.GetStorageModel(),
+		FirmwareVersion: p.cli.GetFirmwareVersion(),
+	}, nil
+}
+
+// ExpandStoragePool adds the additional capacity rounded up to GB to the pool from the spare disk resources, and
+// waits until the free capacity of the pool grows by the additional capacity
+func (p *OceanstorPlugin) ExpandStoragePool(ctx context.Context, poolName string, additionalCapacity int64) (
+	int64, int64, error) {
+	pool, err := p.cli.GetPoolByName(ctx, poolName)
+	if err != nil {
+		return 0, 0, err
+	}
+	if pool == nil {
+		return 0, 0, fmt.Errorf("pool %s does not exist", poolName)
+	}
+
+	poolID, ok := pool["ID"].(string)
+	if !ok {
+		return 0, 0, fmt.Errorf("convert ID of pool %s to string failed, data: %v", poolName, pool["ID"])
+	}
+
+	oldFree := getPoolFreeCapacity(pool)
+	additionalGB := (additionalCapacity + gigabyte - 1) / gigabyte
+	if err = p.cli.ExpandStoragePool(ctx, poolID, additionalGB); err != nil {
+		return 0, 0, err
+	}
+
+	log.AddContext(ctx).Infof("Expanding pool %s by %dGB, wait for the expansion to complete", poolName, additionalGB)
+	waitCtx, cancel := context.WithTimeout(ctx, app.GetGlobalConfig().PoolExpandTimeout)
+	defer cancel()
+
+	newFree := oldFree
+	err = wait.PollImmediateUntil(poolExpandWaitInterval, func() (bool, error) {
+		pool, err := p.cli.GetPoolByName(ctx, poolName)
+		if err != nil {
+			return false, err
+		}
+		if pool == nil {
+			return false, fmt.Errorf("pool %s does not exist", poolName)
+		}
+
+		newFree = getPoolFreeCapacity(pool)
+		return newFree >= oldFree+additionalCapacity, nil
+	}, waitCtx.Done())
+	if ctx.Err() != nil {
+		return oldFree, newFree, fmt.Errorf("wait for the expansion of pool %s stopped, error: %w", poolName,
+			ctx.Err())
+	}
+	if err != nil {
+		return oldFree, newFree, fmt.Errorf("wait for the expansion of pool %s failed, error: %v", poolName, err)
+	}
+
+	return oldFree, newFree, nil
+}
+
+func getPoolFreeCapacity(pool map[string]interface{}) int64 {
+	freeStr, _ := pool["USERFREECAPACITY"].(string)
+	return utils.ParseIntWithDefault(freeStr, 10, 64, 0) * 512
+}
+
+// DumpRequestRecords dumps the recorded exchanges of the client with the storage
+func (p *OceanstorPlugin) DumpRequestRecords(ctx context.Context) (string, error) {
+	if p.cli == nil {
+		return "", nil
+	}
+	return p.cli.DumpRequestRecords(ctx)
+}
+
 // updateVStorePair update vStore pair info
 func (p *OceanstorPlugin) updateVStorePair(ctx context.Context, specifications map[string]interface{}) {
 	if specifications == nil {
@@ -284,7 +495,7 @@ func (p *OceanstorPlugin) getParams(ctx context.Context, name string,
 	parameters map[string]interface{}) map[string]interface{} {
 	params := map[string]interface{}{
 		"name":        name,
-		"description": parameters["description"].(string),
+		"description": getDescription(parameters),
 		"capacity":    utils.RoundUpSize(parameters["size"].(int64), 512),
 		"vstoreId":    "0",
 	}
@@ -313,6 +524,10 @@ func processBoolParams(ctx context.Context, source, target map[string]interface{
 	for _, i := range []string{
 		"replication",
 		"hyperMetro",
+		"encrypted",
+		"deduplication",
+		"writeProtect",
+		"dualProtocol",
 	} {
 		if v, exist := source[i].(string); exist && v != "" {
 			target[strings.ToLower(i)] = utils.StrToBool(ctx, v)
@@ -352,6 +567,9 @@ func toLowerParams(source, target map[string]interface{}) {
 		"accesskrb5i",
 		"accesskrb5p",
 		"fileSystemMode",
+		"spaceSoftQuotaPercent",
+		"cifsAuthClient",
+		"cifsPermission",
 	} {
 		if v, exist := source[key]; exist && v != "" {
 			target[strings.ToLower(key)] = v
@@ -361,13 +579,15 @@ func toLowerParams(source, target map[string]interface{}) {
 
 func (p *OceanstorPlugin) updatePoolCapabilities(ctx context.Context, poolNames []string,
 	vStoreQuotaMap map[string]interface{}, usageType string) (map[string]interface{}, error) {
-	pools, err := p.cli.GetAllPools(ctx)
+	pools, err := p.getPoolsByNames(ctx, poolNames)
 	if err != nil {
-		log.AddContext(ctx).Errorf("Get all pools error: %v", err)
 		return nil, err
 	}
 
 	log.AddContext(ctx).Debugf("Get pools: %v", pools)
+	p.syncedPoolsMutex.Lock()
+	p.syncedPools = pools
+	p.syncedPoolsMutex.Unlock()
 
 	var validPools []map[string]interface{}
 	for _, name := range poolNames {
@@ -388,6 +608,66 @@ func (p *OceanstorPlugin) updatePoolCapabilities(ctx context.Context, poolNames
 	return capabilities, nil
 }
 
+// getPoolsByNames lists all pools with one request and filters them by poolNames,
+// and falls back to query the pools one by one if the storage does not support the list endpoint.
+func (p *OceanstorPlugin) getPoolsByNames(ctx context.Context, poolNames []string) (map[string]interface{}, error) {
+	allPools, err := p.cli.GetAllPools(ctx)
+	if err != nil && strings.Contains(err.Error(), client.UrlNotFound) {
+		log.AddContext(ctx).Warningf("Get all pools error: %v, try to get pools one by one", err)
+		return p.getPoolsOneByOne(ctx, poolNames)
+	}
+	if err != nil {
+		log.AddContext(ctx).Errorf("Get all pools error: %v", err)
+		return nil, err
+	}
+
+	pools := make(map[string]interface{}, len(poolNames))
+	for _, name := range poolNames {
+		if pool, exist := allPools[name]; exist {
+			pools[name] = pool
+		}
+	}
+
+	return pools, nil
+}
+
+// getPoolsOneByOne queries the pools by their names, at most poolQueryConcurrency queries run at the same time
+func (p *OceanstorPlugin) getPoolsOneByOne(ctx context.Context, poolNames []string) (map[string]interface{}, error) {
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+	var lastErr error
+	pools := make(map[string]interface{}, len(poolNames))
+	semaphore := make(chan struct{}, poolQueryConcurrency)
+	for _, name := range poolNames {
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func(name string) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+			pool, err := p.cli.GetPoolByName(ctx, name)
+
+			mutex.Lock()
+			defer mutex.Unlock()
+			if err != nil {
+				log.AddContext(ctx).Errorf("Get pool %s error: %v", name, err)
+				lastErr = err
+				return
+			}
+
+			if pool != nil {
+				pools[name] = pool
+			}
+		}(name)
+	}
+	wg.Wait()
+
+	if lastErr != nil {
+		return nil, lastErr
+	}
+
+	return pools, nil
+}
+
 func (p *OceanstorPlugin) analyzePoolsCapacity(ctx context.Context, pools []map[string]interface{},
 	vStoreQuotaMap map[string]interface{}) map[string]interface{} {
 	capabilities := make(map[string]interface{})
@@ -444,6 +724,43 @@ func (p *OceanstorPlugin) SupportQoSParameters(ctx context.Context, qosConfig st
 	return smartx.CheckQoSParameterSupport(ctx, p.product, qosConfig)
 }
 
+// SelectPoolsByTags returns the names of the pools whose tags configured by the poolTags of the backend include
+// all the requested tags, the pools of the last capabilities update are used, the storage is queried only before
+// the first update
+func (p *OceanstorPlugin) SelectPoolsByTags(ctx context.Context, poolTags string) ([]string, error) {
+	tags, err := client.ParsePoolTags(poolTags)
+	if err != nil {
+		return nil, err
+	}
+
+	p.syncedPoolsMutex.RLock()
+	pools := p.syncedPools
+	p.syncedPoolsMutex.RUnlock()
+
+	var poolNames []string
+	if pools != nil {
+		for name := range pools {
+			if p.cli.MatchPoolTags(name, tags) {
+				poolNames = append(poolNames, name)
+			}
+		}
+		return poolNames, nil
+	}
+
+	matchedPools, err := p.cli.GetPoolByTags(ctx, tags)
+	if err != nil {
+		log.AddContext(ctx).Errorf("Get pools by tags %v error: %v", tags, err)
+		return nil, err
+	}
+
+	for _, pool := range matchedPools {
+		if name, ok := pool["NAME"].(string); ok {
+			poolNames = append(poolNames, name)
+		}
+	}
+	return poolNames, nil
+}
+
 // Logout is to logout the storage session
 func (p *OceanstorPlugin) Logout(ctx context.Context) {
 	if p.cli != nil {
@@ -513,10 +830,13 @@ func (p *OceanstorPlugin) getNewClientConfig(ctx context.Context, param map[stri
 	}
 
 	data.VstoreName, _ = param["vstoreName"].(string)
+	data.AuthDomain, _ = param["authDomain"].(string)
 	data.ParallelNum, _ = param["maxClientThreads"].(string)
 
 	data.UseCert, _ = param["useCert"].(bool)
 	data.CertSecretMeta, _ = param["certSecret"].(string)
+	data.Tracing = app.GetGlobalConfig().EnableTracing
+	data.RecordSize, data.RecordDumpDir = requestRecordConfig(param)
 
 	return data, nil
 }
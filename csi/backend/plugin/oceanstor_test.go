@@ -0,0 +1,250 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync/atomic"
+	"testing"
+
+	xuanwuV1 "huawei-csi-driver/client/apis/xuanwu/v1"
+	"huawei-csi-driver/storage/oceanstor/client"
+)
+
+type fakePoolClient struct {
+	client.BaseClientInterface
+
+	pools              map[string]interface{}
+	poolTags           map[string]map[string]string
+	listErr            error
+	listCallCount      int32
+	getByNameCallCount int32
+}
+
+func (f *fakePoolClient) GetAllPools(ctx context.Context) (map[string]interface{}, error) {
+	atomic.AddInt32(&f.listCallCount, 1)
+	if f.listErr != nil {
+		return nil, f.listErr
+	}
+	return f.pools, nil
+}
+
+func (f *fakePoolClient) GetPoolByName(ctx context.Context, name string) (map[string]interface{}, error) {
+	atomic.AddInt32(&f.getByNameCallCount, 1)
+	pool, ok := f.pools[name].(map[string]interface{})
+	if !ok {
+		return nil, nil
+	}
+	return pool, nil
+}
+
+func (f *fakePoolClient) GetPoolByTags(ctx context.Context, tags map[string]string) (
+	[]map[string]interface{}, error) {
+	pools, err := f.GetAllPools(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var matchedPools []map[string]interface{}
+	for name, pool := range pools {
+		if f.MatchPoolTags(name, tags) {
+			matchedPools = append(matchedPools, pool.(map[string]interface{}))
+		}
+	}
+	return matchedPools, nil
+}
+
+func (f *fakePoolClient) MatchPoolTags(poolName string, tags map[string]string) bool {
+	return (&client.BaseClient{PoolTags: f.poolTags}).MatchPoolTags(poolName, tags)
+}
+
+func newFakePoolClient(poolCount int) (*fakePoolClient, []string) {
+	var poolNames []string
+	pools := make(map[string]interface{})
+	for i := 0; i < poolCount; i++ {
+		name := fmt.Sprintf("pool-%d", i)
+		poolNames = append(poolNames, name)
+		pools[name] = map[string]interface{}{
+			"NAME":              name,
+			"USAGETYPE":         "1",
+			"USERFREECAPACITY":  "1024",
+			"USERTOTALCAPACITY": "2048",
+		}
+	}
+	return &fakePoolClient{pools: pools}, poolNames
+}
+
+func TestUpdatePoolCapabilitiesListOnce(t *testing.T) {
+	fakeCli, poolNames := newFakePoolClient(50)
+	p := &OceanstorPlugin{cli: fakeCli}
+
+	capabilities, err := p.updatePoolCapabilities(ctx, poolNames, map[string]interface{}{}, "1")
+	if err != nil {
+		t.Fatalf("updatePoolCapabilities failed, error: %v", err)
+	}
+
+	if fakeCli.listCallCount != 1 || fakeCli.getByNameCallCount != 0 {
+		t.Errorf("want exactly one list call and no get call, got list: %d, get: %d",
+			fakeCli.listCallCount, fakeCli.getByNameCallCount)
+	}
+
+	if len(capabilities) != len(poolNames) {
+		t.Errorf("want %d pool capabilities, got %d", len(poolNames), len(capabilities))
+	}
+
+	want := map[string]interface{}{
+		string(xuanwuV1.FreeCapacity):  int64(1024 * 512),
+		string(xuanwuV1.TotalCapacity): int64(2048 * 512),
+		string(xuanwuV1.UsedCapacity):  int64(1024),
+	}
+	if !reflect.DeepEqual(capabilities["pool-0"], want) {
+		t.Errorf("want capability %v, got %v", want, capabilities["pool-0"])
+	}
+}
+
+func TestUpdatePoolCapabilitiesFallbackToGetByName(t *testing.T) {
+	fakeCli, poolNames := newFakePoolClient(5)
+	fakeCli.listErr = fmt.Errorf("Get all pools info error: %s", client.UrlNotFound)
+	p := &OceanstorPlugin{cli: fakeCli}
+
+	capabilities, err := p.updatePoolCapabilities(ctx, append(poolNames, "not-exist"),
+		map[string]interface{}{}, "1")
+	if err != nil {
+		t.Fatalf("updatePoolCapabilities failed, error: %v", err)
+	}
+
+	if fakeCli.getByNameCallCount != int32(len(poolNames)+1) {
+		t.Errorf("want %d get calls, got %d", len(poolNames)+1, fakeCli.getByNameCallCount)
+	}
+
+	if len(capabilities) != len(poolNames) {
+		t.Errorf("want %d pool capabilities, got %d", len(poolNames), len(capabilities))
+	}
+}
+
+func TestUpdatePoolCapabilitiesNoFallbackOnListError(t *testing.T) {
+	fakeCli, poolNames := newFakePoolClient(5)
+	fakeCli.listErr = errors.New("connection refused")
+	p := &OceanstorPlugin{cli: fakeCli}
+
+	if _, err := p.updatePoolCapabilities(ctx, poolNames, map[string]interface{}{}, "1"); err == nil {
+		t.Fatal("updatePoolCapabilities() error = nil, want the error of listing the pools")
+	}
+
+	if fakeCli.getByNameCallCount != 0 {
+		t.Errorf("want no get call, got %d", fakeCli.getByNameCallCount)
+	}
+}
+
+func TestSelectPoolsByTagsUsesSyncedPools(t *testing.T) {
+	fakeCli, poolNames := newFakePoolClient(3)
+	fakeCli.poolTags = map[string]map[string]string{"pool-1": {"team": "finance", "tier": "gold"}}
+	p := &OceanstorPlugin{cli: fakeCli}
+
+	if _, err := p.updatePoolCapabilities(ctx, poolNames, map[string]interface{}{}, "1"); err != nil {
+		t.Fatalf("updatePoolCapabilities failed, error: %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		names, err := p.SelectPoolsByTags(ctx, "tier=gold")
+		if err != nil {
+			t.Fatalf("SelectPoolsByTags failed, error: %v", err)
+		}
+		if !reflect.DeepEqual(names, []string{"pool-1"}) {
+			t.Errorf("want pools [pool-1], got %v", names)
+		}
+	}
+
+	if fakeCli.listCallCount != 1 {
+		t.Errorf("want the pools listed once by the capabilities update, got %d list calls",
+			fakeCli.listCallCount)
+	}
+}
+
+func TestSelectPoolsByTagsBeforeSync(t *testing.T) {
+	fakeCli, _ := newFakePoolClient(3)
+	// the tags of the pools which don't exist on the storage are ignored
+	fakeCli.poolTags = map[string]map[string]string{"pool-2": {"tier": "gold"}, "pool-removed": {"tier": "gold"}}
+	p := &OceanstorPlugin{cli: fakeCli}
+
+	names, err := p.SelectPoolsByTags(ctx, "tier=gold")
+	if err != nil {
+		t.Fatalf("SelectPoolsByTags failed, error: %v", err)
+	}
+	if !reflect.DeepEqual(names, []string{"pool-2"}) {
+		t.Errorf("want pools [pool-2], got %v", names)
+	}
+}
+
+func TestGetPoolTags(t *testing.T) {
+	tests := []struct {
+		name     string
+		poolTags interface{}
+		want     map[string]map[string]string
+		wantErr  bool
+	}{
+		{"NotConfigured", nil, nil, false},
+		{"Configured", map[string]interface{}{"pool1": "team=finance,tier=gold"},
+			map[string]map[string]string{"pool1": {"team": "finance", "tier": "gold"}}, false},
+		{"InvalidTags", map[string]interface{}{"pool1": "gold"}, nil, true},
+		{"NotMap", "team=finance", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parameters := map[string]interface{}{}
+			if tt.poolTags != nil {
+				parameters["poolTags"] = tt.poolTags
+			}
+			got, err := getPoolTags(map[string]interface{}{"parameters": parameters})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("getPoolTags() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("getPoolTags() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckAuthDomainType(t *testing.T) {
+	tests := []struct {
+		name           string
+		authDomainType interface{}
+		wantErr        bool
+	}{
+		{"DefaultLDAP", nil, false},
+		{"LDAP", "ldap", false},
+		{"ADRejected", "ad", true},
+		{"Invalid", "kerberos", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := map[string]interface{}{"authDomain": "example.com"}
+			if tt.authDomainType != nil {
+				config["authDomainType"] = tt.authDomainType
+			}
+			if err := checkAuthDomainType(config); (err != nil) != tt.wantErr {
+				t.Errorf("checkAuthDomainType() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
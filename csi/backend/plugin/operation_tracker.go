@@ -0,0 +1,72 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package plugin
+
+import (
+	"context"
+	"sync"
+)
+
+// operationTracker counts the in-flight operations of a plugin
+type operationTracker struct {
+	mutex sync.Mutex
+	count int
+	// idle is closed when the last in-flight operation is completed
+	idle chan struct{}
+}
+
+// BeginOperation registers an in-flight operation and returns the function which completes it
+func (t *operationTracker) BeginOperation() func() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if t.count == 0 {
+		t.idle = make(chan struct{})
+	}
+	t.count++
+
+	var once sync.Once
+	return func() {
+		once.Do(t.endOperation)
+	}
+}
+
+func (t *operationTracker) endOperation() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.count--
+	if t.count == 0 {
+		close(t.idle)
+	}
+}
+
+// WaitOperations waits until the in-flight operations are completed or the context is done
+func (t *operationTracker) WaitOperations(ctx context.Context) error {
+	t.mutex.Lock()
+	if t.count == 0 {
+		t.mutex.Unlock()
+		return nil
+	}
+	idle := t.idle
+	t.mutex.Unlock()
+
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
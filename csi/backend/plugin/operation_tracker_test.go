@@ -0,0 +1,63 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package plugin
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestOperationTracker_WaitOperations(t *testing.T) {
+	tracker := &operationTracker{}
+	if err := tracker.WaitOperations(ctx); err != nil {
+		t.Fatalf("WaitOperations() without operations error = %v", err)
+	}
+
+	end1 := tracker.BeginOperation()
+	end2 := tracker.BeginOperation()
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	if err := tracker.WaitOperations(timeoutCtx); err == nil {
+		t.Fatalf("WaitOperations() returns before the in-flight operations are completed")
+	}
+
+	done := make(chan error)
+	go func() {
+		done <- tracker.WaitOperations(ctx)
+	}()
+
+	end1()
+	// completing an operation twice doesn't complete the other one
+	end1()
+	select {
+	case <-done:
+		t.Fatalf("WaitOperations() returns before all the in-flight operations are completed")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	end2()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("WaitOperations() error = %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Errorf("WaitOperations() doesn't return after the in-flight operations are completed")
+	}
+}
@@ -18,6 +18,7 @@ package plugin
 
 import (
 	"context"
+	"time"
 
 	// init the nfs connector
 	_ "huawei-csi-driver/connector/nfs"
@@ -54,6 +55,178 @@ type SmartXQoSQuery interface {
 	SupportQoSParameters(ctx context.Context, qos string) error
 }
 
+// NFSShareClientACL provides the nfs share client access control operations
+type NFSShareClientACL interface {
+	// UpdateNFSShareClientACL updates the clients which are allowed to access the nfs share of the volume
+	UpdateNFSShareClientACL(ctx context.Context, name string, clients []string) error
+}
+
+// LicenseChecker provides the check of the licenses required by the volume parameters
+type LicenseChecker interface {
+	// GetMissingLicenses returns the license features which are required by the volume parameters but not
+	// present on the storage
+	GetMissingLicenses(ctx context.Context, parameters map[string]interface{}) ([]string, error)
+}
+
+// LunOwnerController provides the controllers which are allowed to own the luns created by the plugin
+type LunOwnerController interface {
+	// GetOwnerControllers returns the controllers which can be requested as the owner of the luns, nothing is
+	// returned when the storage does not allow the owner to be requested
+	GetOwnerControllers(ctx context.Context) ([]string, error)
+}
+
+// NFSShareDeleter provides the deletion of the nfs share of a volume and its restoration
+type NFSShareDeleter interface {
+	// DeleteNFSShare deletes the nfs share of the volume and keeps the filesystem and its data
+	DeleteNFSShare(ctx context.Context, name string) error
+	// RestoreNFSShare recreates the deleted nfs share of the volume with the share parameters of the
+	// StorageClass, such as authClient, allSquash and rootSquash
+	RestoreNFSShare(ctx context.Context, name string, parameters map[string]interface{}) error
+}
+
+// MappingChecker provides the light check of the mapping of a volume to a node,
+// which doesn't create or change anything on the storage
+type MappingChecker interface {
+	// IsVolumeMapped checks whether the volume is still mapped to the node
+	IsVolumeMapped(ctx context.Context, name string, parameters map[string]interface{}) (bool, error)
+}
+
+// HostCleaner provides the cleanup of the host objects created for a node on the storage
+type HostCleaner interface {
+	// DeleteHost deletes the host of the node and its mapping objects if no volume is mapped to it
+	DeleteHost(ctx context.Context, parameters map[string]interface{}) error
+}
+
+// SnapshotSpaceChecker provides the pre-check of the space which a new snapshot requires
+type SnapshotSpaceChecker interface {
+	// CheckSnapshotSpace returns the error wrapping ErrSnapshotSpaceExhausted if the snapshot space is exhausted,
+	// the space is not checked if the snapshot of the volume already exists
+	CheckSnapshotSpace(ctx context.Context, name, snapshotName string) error
+}
+
+// QosBudgetChecker provides the pre-check of the qos policies which a new volume requires
+type QosBudgetChecker interface {
+	// CheckQosBudget returns the error wrapping ErrQosBudgetExhausted if no more qos policy can be created
+	CheckQosBudget(ctx context.Context) error
+}
+
+// VolumeMigrator provides the move of a volume to another parent on the same storage without data copy
+type VolumeMigrator interface {
+	// MigrateVolume moves the volume from the source parent to the target parent
+	MigrateVolume(ctx context.Context, name, srcParent, dstParent string) error
+}
+
+// WriteProtectRemover provides the removal of the write protection of a volume
+type WriteProtectRemover interface {
+	// RemoveWriteProtect makes the write-protected volume writable again
+	RemoveWriteProtect(ctx context.Context, name string) error
+}
+
+// ReplicaCopier provides the copy of a volume to a volume on another storage by remote replication
+type ReplicaCopier interface {
+	// GetStorageSN returns the serial number of the storage
+	GetStorageSN(ctx context.Context) (string, error)
+	// GetVolumeID returns the ID of the volume on the storage
+	GetVolumeID(ctx context.Context, name string) (string, error)
+	// CreateReplicaCopy starts copying the volume to the volume of the remote storage, returns the pair ID
+	CreateReplicaCopy(ctx context.Context, name, remoteSN, remoteID string) (string, error)
+	// SyncReplicaCopy starts the incremental copy of the pair
+	SyncReplicaCopy(ctx context.Context, pairID string) error
+	// GetReplicaCopyStatus returns whether the copy is synchronized and its progress in percent
+	GetReplicaCopyStatus(ctx context.Context, pairID string) (bool, int, error)
+	// DeleteReplicaCopy stops the copy and deletes the pair
+	DeleteReplicaCopy(ctx context.Context, pairID string) error
+}
+
+// StorageInventory is the identity of the storage which is cached when the backend logs in
+type StorageInventory struct {
+	SerialNumber    string
+	Model           string
+	FirmwareVersion string
+}
+
+// InventoryProvider provides the identity of the storage without querying the storage
+type InventoryProvider interface {
+	// GetStorageInventory returns the serial number, the model and the firmware version of the storage
+	GetStorageInventory() (StorageInventory, error)
+}
+
+// PoolTagSelector provides the selection of storage pools by the tags configured in the backend
+type PoolTagSelector interface {
+	// SelectPoolsByTags returns the names of the pools whose tags include all the comma-separated key=value tags
+	SelectPoolsByTags(ctx context.Context, poolTags string) ([]string, error)
+}
+
+// MaintenanceChecker provides the query of the maintenance state of the storage, such as upgrading
+type MaintenanceChecker interface {
+	// IsInMaintenance checks whether the storage is in maintenance
+	IsInMaintenance(ctx context.Context) (bool, error)
+}
+
+// PoolExpander provides the expansion of a storage pool from the spare disk resources of the storage
+type PoolExpander interface {
+	// ExpandStoragePool adds at least the additional capacity to the pool and waits until the expansion completes,
+	// returns the free capacity of the pool before and after the expansion. The wait stops when the context is
+	// done, and the returned error wraps the error of the context.
+	ExpandStoragePool(ctx context.Context, poolName string, additionalCapacity int64) (int64, int64, error)
+}
+
+// SnapshotTierer provides the tiering of the snapshot data to the object storage
+type SnapshotTierer interface {
+	// GetSnapshotsCreatedBefore returns the names of the snapshots created before the given time by their IDs
+	GetSnapshotsCreatedBefore(ctx context.Context, before time.Time) (map[string]string, error)
+	// TierSnapshotToCloud submits the tiering of the snapshot data to the object storage
+	TierSnapshotToCloud(ctx context.Context, snapshotName string) error
+	// IsSnapshotTiered returns whether the submitted tiering of the snapshot is completed
+	IsSnapshotTiered(ctx context.Context, snapshotName string) (bool, error)
+}
+
+// RequestRecordDumper provides the dump of the recorded exchanges with the storage for the failed operations
+type RequestRecordDumper interface {
+	// DumpRequestRecords writes the recorded exchanges to a file and returns its path, empty if nothing is recorded
+	DumpRequestRecords(ctx context.Context) (string, error)
+}
+
+// OperationTracker tracks the in-flight operations of the plugin,
+// so that its storage client is logged out only after they are completed when the plugin is replaced
+type OperationTracker interface {
+	// BeginOperation registers an in-flight operation and returns the function which completes it
+	BeginOperation() func()
+	// WaitOperations waits until the in-flight operations are completed or the context is done
+	WaitOperations(ctx context.Context) error
+}
+
+// VolumeState is the state of the volume on the storage
+type VolumeState struct {
+	StoragePool string
+	// Capacity is in bytes
+	Capacity int64
+	// QoSPolicyID is empty if the volume is not controlled by any SmartQoS policy
+	QoSPolicyID string
+	// QoS is the values of the SmartQoS policy by the keys of the qos parameter of the StorageClass,
+	// the LATENCY is in milliseconds
+	QoS        map[string]string
+	HyperMetro bool
+}
+
+// VolumeInspector provides the state of the volumes on the storage
+type VolumeInspector interface {
+	// InspectVolume returns the state of the volume on the storage, nil is returned if the volume does not exist
+	InspectVolume(ctx context.Context, name string) (*VolumeState, error)
+}
+
+// SnapshotChecker provides the check of the existence of the snapshots on the storage
+type SnapshotChecker interface {
+	// SnapshotExists checks whether the snapshot of the parent volume exists on the storage
+	SnapshotExists(ctx context.Context, parentID, snapshotName string) (bool, error)
+}
+
+// VolumeChecker provides the check of the existence of the volumes on the storage
+type VolumeChecker interface {
+	// VolumeExists checks whether the lun or the filesystem of the volume exists on the storage
+	VolumeExists(ctx context.Context, name string) (bool, error)
+}
+
 var (
 	plugins = map[string]Plugin{}
 )
@@ -78,6 +251,7 @@ func GetPlugin(storageType string) Plugin {
 }
 
 type basePlugin struct {
+	operationTracker
 }
 
 func (p *basePlugin) AttachVolume(context.Context, string, map[string]interface{}) (map[string]interface{}, error) {
@@ -17,13 +17,123 @@
 package plugin
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
 
 	pkgUtils "huawei-csi-driver/pkg/utils"
+	"huawei-csi-driver/utils/log"
 )
 
+const (
+	// DefaultDescription is the description of the volumes whose StorageClass does not set one
+	DefaultDescription = "Created from Kubernetes CSI"
+
+	// fusionStorageMaxDescriptionLength is the description length limit of the FusionStorage volumes,
+	// which is shorter than the one of OceanStor
+	fusionStorageMaxDescriptionLength = 127
+)
+
+var nfsSharePathPrefixRegexp = regexp.MustCompile(`^/[A-Za-z0-9_/]*$`)
+
+// getDescription returns the description parameter, or DefaultDescription if it is not set
+func getDescription(parameters map[string]interface{}) string {
+	description, exist := parameters["description"].(string)
+	if !exist {
+		return DefaultDescription
+	}
+
+	return description
+}
+
+// getFusionStorageDescription returns the description parameter truncated to the FusionStorage length limit
+func getFusionStorageDescription(ctx context.Context, parameters map[string]interface{}) string {
+	description := getDescription(parameters)
+	if len(description) <= fusionStorageMaxDescriptionLength {
+		return description
+	}
+
+	truncated := description[:fusionStorageMaxDescriptionLength]
+	for !utf8.ValidString(truncated) {
+		truncated = truncated[:len(truncated)-1]
+	}
+	log.AddContext(ctx).Warningf("The description [%s] exceeds the FusionStorage length limit %d, "+
+		"truncate it to [%s]", description, fusionStorageMaxDescriptionLength, truncated)
+	return truncated
+}
+
+// getNonNegativeInteger parses the integer parameter of the backend, which is a number or a string in the
+// configuration, 0 is returned if the parameter is not set
+func getNonNegativeInteger(parameters map[string]interface{}, key string) (int64, error) {
+	value, exist := parameters[key]
+	if !exist {
+		return 0, nil
+	}
+
+	var result int64
+	var err error
+	switch v := value.(type) {
+	case float64:
+		result = int64(v)
+		if float64(result) != v {
+			err = errors.New("not an integer")
+		}
+	case string:
+		result, err = strconv.ParseInt(v, 10, 64)
+	default:
+		err = fmt.Errorf("unsupported type %T", value)
+	}
+
+	if err != nil || result < 0 {
+		return 0, fmt.Errorf("%s %v must be a non-negative integer", key, value)
+	}
+
+	return result, nil
+}
+
+// checkMinPortals rejects the backend configured with fewer distinct portals than the minPortals parameter,
+// which is the minimum for the path redundancy. It is not checked if minPortals is not set.
+func checkMinPortals(parameters map[string]interface{}, portals []string) error {
+	minPortals, err := getNonNegativeInteger(parameters, "minPortals")
+	if err != nil {
+		return err
+	}
+
+	distinct := make(map[string]struct{}, len(portals))
+	for _, portal := range portals {
+		distinct[portal] = struct{}{}
+	}
+
+	if int64(len(distinct)) < minPortals {
+		return fmt.Errorf("%d distinct portals are configured, but at least %d portals are required by "+
+			"minPortals for the path redundancy", len(distinct), minPortals)
+	}
+
+	return nil
+}
+
+// GetNfsSharePathPrefix returns the nfsSharePathPrefix parameter of the oceanstor-nas backend without the
+// trailing slash, which is prepended to the paths of the nfs shares created by the driver
+func GetNfsSharePathPrefix(parameters map[string]interface{}) (string, error) {
+	value, exist := parameters["nfsSharePathPrefix"]
+	if !exist {
+		return "", nil
+	}
+
+	prefix, ok := value.(string)
+	if !ok || !nfsSharePathPrefixRegexp.MatchString(prefix) {
+		return "", fmt.Errorf("nfsSharePathPrefix %v must start with / and contain only letters, digits, "+
+			"underscores and slashes", value)
+	}
+
+	return strings.TrimRight(prefix, "/"), nil
+}
+
 // verifyProtocolAndPortals verifyProtocolAndPortals
 func verifyProtocolAndPortals(parameters map[string]interface{}) (string, []string, error) {
 	protocol, exist := parameters["protocol"].(string)
@@ -0,0 +1,132 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package plugin
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGetNfsSharePathPrefix(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   interface{}
+		want    string
+		wantErr bool
+	}{
+		{"NotConfigured", nil, "", false},
+		{"Prefix", "/exports/k8s", "/exports/k8s", false},
+		{"TrailingSlash", "/exports/k8s_1/", "/exports/k8s_1", false},
+		{"Root", "/", "", false},
+		{"Relative", "exports/k8s", "", true},
+		{"InvalidCharacter", "/exports/k8s-1", "", true},
+		{"NotString", 1.0, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parameters := map[string]interface{}{}
+			if tt.value != nil {
+				parameters["nfsSharePathPrefix"] = tt.value
+			}
+
+			got, err := GetNfsSharePathPrefix(parameters)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("GetNfsSharePathPrefix() = %s, %v, want %s, wantErr %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckMinPortals(t *testing.T) {
+	tests := []struct {
+		name       string
+		minPortals interface{}
+		portals    []string
+		wantErr    bool
+	}{
+		{"NotConfigured", nil, []string{"192.168.1.1"}, false},
+		{"Enough", 2.0, []string{"192.168.1.1", "192.168.1.2"}, false},
+		{"EnoughInString", "2", []string{"192.168.1.1", "192.168.1.2", "192.168.1.3"}, false},
+		{"TooFew", 2.0, []string{"192.168.1.1"}, true},
+		{"Duplicated", 2.0, []string{"192.168.1.1", "192.168.1.1"}, true},
+		{"Invalid", "two", []string{"192.168.1.1", "192.168.1.2"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parameters := map[string]interface{}{}
+			if tt.minPortals != nil {
+				parameters["minPortals"] = tt.minPortals
+			}
+
+			if err := checkMinPortals(parameters, tt.portals); (err != nil) != tt.wantErr {
+				t.Errorf("checkMinPortals() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGetFusionStorageDescription(t *testing.T) {
+	long := strings.Repeat("a", fusionStorageMaxDescriptionLength+10)
+	multiByte := strings.Repeat("a", fusionStorageMaxDescriptionLength-1) + "描述"
+	tests := []struct {
+		name  string
+		value interface{}
+		want  string
+	}{
+		{"NotConfigured", nil, DefaultDescription},
+		{"NotString", 1.0, DefaultDescription},
+		{"Short", "created by csi", "created by csi"},
+		{"TooLong", long, long[:fusionStorageMaxDescriptionLength]},
+		{"MultiByteRune", multiByte, multiByte[:fusionStorageMaxDescriptionLength-1]},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parameters := map[string]interface{}{}
+			if tt.value != nil {
+				parameters["description"] = tt.value
+			}
+
+			if got := getFusionStorageDescription(ctx, parameters); got != tt.want {
+				t.Errorf("getFusionStorageDescription() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetParamsWithoutDescription(t *testing.T) {
+	parameters := map[string]interface{}{"size": int64(10 * CAPACITY_UNIT)}
+
+	sanParams, err := (&FusionStorageSanPlugin{}).getParams(ctx, "pvc-1", parameters)
+	if err != nil || sanParams["description"] != DefaultDescription {
+		t.Errorf("FusionStorageSanPlugin.getParams() = %v, %v, want description %s",
+			sanParams, err, DefaultDescription)
+	}
+
+	nasParams, err := (&FusionStoragePlugin{}).getParams(ctx, "pvc-1", parameters)
+	if err != nil || nasParams["description"] != DefaultDescription {
+		t.Errorf("FusionStoragePlugin.getParams() = %v, %v, want description %s",
+			nasParams, err, DefaultDescription)
+	}
+
+	oceanstorParams := (&OceanstorPlugin{}).getParams(ctx, "pvc-1", parameters)
+	if oceanstorParams["description"] != DefaultDescription {
+		t.Errorf("OceanstorPlugin.getParams() = %v, want description %s", oceanstorParams, DefaultDescription)
+	}
+}
@@ -0,0 +1,53 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrQosBudgetExhausted means that the storage does not have enough qos policies for a new volume
+var ErrQosBudgetExhausted = errors.New("qos policy budget exhausted")
+
+// getQosPolicyLimit parses the qosPolicyLimit parameter of the backend, which is the number of qos policies
+// supported by the storage specification
+func getQosPolicyLimit(config map[string]interface{}) (int64, error) {
+	parameters, _ := config["parameters"].(map[string]interface{})
+	return getNonNegativeInteger(parameters, "qosPolicyLimit")
+}
+
+// CheckQosBudget checks whether the storage is able to create the qos policy of a new volume
+func (p *OceanstorPlugin) CheckQosBudget(ctx context.Context) error {
+	if p.qosPolicyLimit == 0 {
+		return nil
+	}
+
+	count, err := p.cli.GetQosCount(ctx, p.vStoreId)
+	if err != nil {
+		return err
+	}
+
+	if count >= p.qosPolicyLimit {
+		return fmt.Errorf("%w: %d of %d qos policies are used on the storage, please delete unused qos "+
+			"volumes or increase the qosPolicyLimit parameter of the backend if the storage supports more",
+			ErrQosBudgetExhausted, count, p.qosPolicyLimit)
+	}
+
+	return nil
+}
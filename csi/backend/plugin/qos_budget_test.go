@@ -0,0 +1,86 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package plugin
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"huawei-csi-driver/storage/oceanstor/client"
+)
+
+type fakeQosClient struct {
+	client.BaseClientInterface
+
+	count int64
+}
+
+func (f *fakeQosClient) GetQosCount(ctx context.Context, vStoreID string) (int64, error) {
+	return f.count, nil
+}
+
+func TestGetQosPolicyLimit(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  map[string]interface{}
+		want    int64
+		wantErr bool
+	}{
+		{"NotConfigured", map[string]interface{}{"parameters": map[string]interface{}{}}, 0, false},
+		{"Number", map[string]interface{}{"parameters": map[string]interface{}{"qosPolicyLimit": 1e6}},
+			1000000, false},
+		{"String", map[string]interface{}{"parameters": map[string]interface{}{"qosPolicyLimit": "512"}},
+			512, false},
+		{"Fraction", map[string]interface{}{"parameters": map[string]interface{}{"qosPolicyLimit": 1.5}},
+			0, true},
+		{"Negative", map[string]interface{}{"parameters": map[string]interface{}{"qosPolicyLimit": "-1"}},
+			0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := getQosPolicyLimit(tt.config)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("getQosPolicyLimit() = %d, %v, want %d, wantErr %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckQosBudget(t *testing.T) {
+	tests := []struct {
+		name    string
+		limit   int64
+		count   int64
+		wantErr error
+	}{
+		{"NotChecked", 0, 1024, nil},
+		{"BudgetAvailable", 512, 511, nil},
+		{"BudgetExhausted", 512, 512, ErrQosBudgetExhausted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &OceanstorPlugin{qosPolicyLimit: tt.limit}
+			p.cli = &fakeQosClient{count: tt.count}
+			if err := p.CheckQosBudget(context.TODO()); !errors.Is(err, tt.wantErr) {
+				t.Errorf("CheckQosBudget() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
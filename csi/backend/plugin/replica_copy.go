@@ -0,0 +1,187 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	pkgUtils "huawei-csi-driver/pkg/utils"
+	"huawei-csi-driver/utils"
+	"huawei-csi-driver/utils/log"
+)
+
+const (
+	replicaCopyResourceTypeLun       = 11
+	replicaCopyRunningStatusNormal   = "1"
+	replicaCopyRunningStatusSyncing  = "23"
+	replicaCopyRemoteDeviceHealthy   = "1"
+	replicaCopyRemoteDeviceLinkUp    = "10"
+	replicaCopyReplicationModelAsync = 2
+	replicaCopySynchronizeTypeManual = 1
+	replicaCopySpeedHighest          = 4
+)
+
+// GetStorageSN returns the serial number of the storage
+func (p *OceanstorSanPlugin) GetStorageSN(ctx context.Context) (string, error) {
+	system, err := p.cli.GetSystem(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	sn, ok := system["ID"].(string)
+	if !ok || sn == "" {
+		return "", pkgUtils.Errorf(ctx, "convert system ID to string failed, data: %v", system["ID"])
+	}
+	return sn, nil
+}
+
+// GetVolumeID returns the ID of the lun on the storage
+func (p *OceanstorSanPlugin) GetVolumeID(ctx context.Context, name string) (string, error) {
+	return p.getLunID(ctx, name)
+}
+
+// CreateReplicaCopy creates the remote replication pair from the lun to the lun of the remote storage and
+// starts the initial synchronization, the existing pair between the two luns is reused
+func (p *OceanstorSanPlugin) CreateReplicaCopy(ctx context.Context, name, remoteSN, remoteLunID string) (
+	string, error) {
+	lunID, err := p.getLunID(ctx, name)
+	if err != nil {
+		return "", err
+	}
+
+	pairs, err := p.cli.GetReplicationPairByResID(ctx, lunID, replicaCopyResourceTypeLun)
+	if err != nil {
+		return "", err
+	}
+	for _, pair := range pairs {
+		if pair["REMOTERESID"] == remoteLunID {
+			pairID, ok := pair["ID"].(string)
+			if !ok {
+				return "", pkgUtils.Errorf(ctx, "convert pairID to string failed, data: %v", pair["ID"])
+			}
+			log.AddContext(ctx).Infof("Replication pair %s from lun %s to %s already exists", pairID, name, remoteSN)
+			return pairID, nil
+		}
+	}
+
+	remoteDevice, err := p.cli.GetRemoteDeviceBySN(ctx, remoteSN)
+	if err != nil {
+		return "", err
+	}
+	if remoteDevice == nil {
+		return "", fmt.Errorf("storage %s is not a remote device of the storage of lun %s", remoteSN, name)
+	}
+	if remoteDevice["HEALTHSTATUS"] != replicaCopyRemoteDeviceHealthy ||
+		remoteDevice["RUNNINGSTATUS"] != replicaCopyRemoteDeviceLinkUp {
+		return "", fmt.Errorf("remote device %s status is not normal", remoteSN)
+	}
+
+	pair, err := p.cli.CreateReplicationPair(ctx, map[string]interface{}{
+		"LOCALRESID":       lunID,
+		"LOCALRESTYPE":     replicaCopyResourceTypeLun,
+		"REMOTEDEVICEID":   remoteDevice["ID"],
+		"REMOTERESID":      remoteLunID,
+		"REPLICATIONMODEL": replicaCopyReplicationModelAsync,
+		"SYNCHRONIZETYPE":  replicaCopySynchronizeTypeManual,
+		"SPEED":            replicaCopySpeedHighest,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	pairID, ok := pair["ID"].(string)
+	if !ok {
+		return "", pkgUtils.Errorf(ctx, "convert pairID to string failed, data: %v", pair["ID"])
+	}
+
+	if err = p.cli.SyncReplicationPair(ctx, pairID); err != nil {
+		log.AddContext(ctx).Errorf("Sync replication pair %s error: %v", pairID, err)
+		if deleteErr := p.cli.DeleteReplicationPair(ctx, pairID); deleteErr != nil {
+			log.AddContext(ctx).Warningf("Delete replication pair %s error: %v", pairID, deleteErr)
+		}
+		return "", err
+	}
+
+	log.AddContext(ctx).Infof("Replication pair %s from lun %s to %s is created", pairID, name, remoteSN)
+	return pairID, nil
+}
+
+// SyncReplicaCopy starts the incremental synchronization of the replication pair
+func (p *OceanstorSanPlugin) SyncReplicaCopy(ctx context.Context, pairID string) error {
+	return p.cli.SyncReplicationPair(ctx, pairID)
+}
+
+// GetReplicaCopyStatus returns whether the replication pair is synchronized and the progress of the
+// running synchronization
+func (p *OceanstorSanPlugin) GetReplicaCopyStatus(ctx context.Context, pairID string) (bool, int, error) {
+	pair, err := p.cli.GetReplicationPairByID(ctx, pairID)
+	if err != nil {
+		return false, 0, err
+	}
+
+	status, _ := pair["RUNNINGSTATUS"].(string)
+	if status == replicaCopyRunningStatusNormal {
+		log.AddContext(ctx).Debugf("ReplicationPair task %s of the storage is synchronized", pairID)
+		return true, 100, nil
+	}
+	if status != replicaCopyRunningStatusSyncing {
+		return false, 0, utils.NewArrayTaskError("ReplicationPair", pairID,
+			fmt.Sprintf("replication pair is in unexpected running status %s", status))
+	}
+
+	progress, _ := pair["REPLICATIONPROGRESS"].(string)
+	percent, err := strconv.Atoi(progress)
+	if err != nil {
+		log.AddContext(ctx).Warningf("Parse progress %s of replication pair %s error: %v", progress, pairID, err)
+	}
+	return false, percent, nil
+}
+
+// DeleteReplicaCopy splits and deletes the replication pair, the deleted pair is ignored
+func (p *OceanstorSanPlugin) DeleteReplicaCopy(ctx context.Context, pairID string) error {
+	pair, err := p.cli.GetReplicationPairByID(ctx, pairID)
+	if err != nil {
+		log.AddContext(ctx).Warningf("Get replication pair %s error: %v", pairID, err)
+	}
+
+	status, _ := pair["RUNNINGSTATUS"].(string)
+	if status == replicaCopyRunningStatusNormal || status == replicaCopyRunningStatusSyncing {
+		if err = p.cli.SplitReplicationPair(ctx, pairID); err != nil {
+			return err
+		}
+	}
+
+	return p.cli.DeleteReplicationPair(ctx, pairID)
+}
+
+func (p *OceanstorSanPlugin) getLunID(ctx context.Context, name string) (string, error) {
+	lun, err := p.cli.GetLunByName(ctx, name)
+	if err != nil {
+		return "", err
+	}
+	if lun == nil {
+		return "", fmt.Errorf("lun %s does not exist", name)
+	}
+
+	lunID, ok := lun["ID"].(string)
+	if !ok {
+		return "", pkgUtils.Errorf(ctx, "convert lunID to string failed, data: %v", lun["ID"])
+	}
+	return lunID, nil
+}
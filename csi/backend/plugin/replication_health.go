@@ -0,0 +1,96 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"huawei-csi-driver/pkg/constants"
+	"huawei-csi-driver/storage/oceanstor/client"
+	"huawei-csi-driver/utils/log"
+)
+
+// the running status of the replication pair in which the data of the secondary lun may be inconsistent
+const (
+	replicationPairRunningStatusInvalid = "35"
+	replicationPairRunningStatusError   = "94"
+
+	// the resource type of lun used to query the replication pairs of the lun
+	replicationPairResourceTypeLun = 11
+)
+
+// checkReplicationPairs returns an error wrapping ErrReplicationPairUnhealthy if any remote replication pair
+// of the lun is in error or invalid state, so that the lun is not attached before the replication is repaired
+func checkReplicationPairs(ctx context.Context, cli client.BaseClientInterface, lun map[string]interface{}) error {
+	if cli == nil || !isRemoteReplicationLun(lun) {
+		return nil
+	}
+
+	pairIDs, err := getReplicationPairIDs(ctx, cli, lun)
+	if err != nil {
+		return err
+	}
+
+	for _, pairID := range pairIDs {
+		pair, err := cli.GetReplicationPairByID(ctx, pairID)
+		if err != nil {
+			return err
+		}
+
+		status, _ := pair["RUNNINGSTATUS"].(string)
+		if status == replicationPairRunningStatusError || status == replicationPairRunningStatusInvalid {
+			log.AddContext(ctx).Errorf("Replication pair %s of lun %v is in running status %s",
+				pairID, lun["NAME"], status)
+			return fmt.Errorf("%w: replication pair %s of lun %v is in running status %s, please check the "+
+				"replication status on the storage and repair the pair before attaching the volume",
+				constants.ErrReplicationPairUnhealthy, pairID, lun["NAME"], status)
+		}
+	}
+
+	return nil
+}
+
+func isRemoteReplicationLun(lun map[string]interface{}) bool {
+	return hasRSSObject(lun, "RemoteReplication")
+}
+
+// getReplicationPairIDs gets the ids of the replication pairs of the lun, the pairs are queried by the lun id
+// if the lun does not report them
+func getReplicationPairIDs(ctx context.Context, cli client.BaseClientInterface,
+	lun map[string]interface{}) ([]string, error) {
+	var pairIDs []string
+	if idsStr, ok := lun["REMOTEREPLICATIONIDS"].(string); ok && idsStr != "" {
+		if err := json.Unmarshal([]byte(idsStr), &pairIDs); err == nil {
+			return pairIDs, nil
+		}
+	}
+
+	lunID, _ := lun["ID"].(string)
+	pairs, err := cli.GetReplicationPairByResID(ctx, lunID, replicationPairResourceTypeLun)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, pair := range pairs {
+		if pairID, ok := pair["ID"].(string); ok {
+			pairIDs = append(pairIDs, pairID)
+		}
+	}
+	return pairIDs, nil
+}
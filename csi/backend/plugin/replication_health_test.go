@@ -0,0 +1,83 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package plugin
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"huawei-csi-driver/pkg/constants"
+	"huawei-csi-driver/storage/oceanstor/client"
+)
+
+type fakeReplicationClient struct {
+	client.BaseClientInterface
+
+	pairs map[string]map[string]interface{}
+}
+
+func (c *fakeReplicationClient) GetReplicationPairByID(ctx context.Context,
+	pairID string) (map[string]interface{}, error) {
+	return c.pairs[pairID], nil
+}
+
+func (c *fakeReplicationClient) GetReplicationPairByResID(ctx context.Context, resID string,
+	resType int) ([]map[string]interface{}, error) {
+	var pairs []map[string]interface{}
+	for _, pair := range c.pairs {
+		pairs = append(pairs, pair)
+	}
+	return pairs, nil
+}
+
+func TestCheckReplicationPairs(t *testing.T) {
+	replicationLun := func(pairIDs string) map[string]interface{} {
+		return map[string]interface{}{"ID": "1", "NAME": "pvc-1",
+			"HASRSSOBJECT": `{"RemoteReplication":"TRUE"}`, "REMOTEREPLICATIONIDS": pairIDs}
+	}
+	newClient := func(status string) *fakeReplicationClient {
+		return &fakeReplicationClient{pairs: map[string]map[string]interface{}{
+			"pair-1": {"ID": "pair-1", "RUNNINGSTATUS": status}}}
+	}
+
+	tests := []struct {
+		name      string
+		lun       map[string]interface{}
+		cli       *fakeReplicationClient
+		expectErr bool
+	}{
+		{"NotReplicated", map[string]interface{}{"HASRSSOBJECT": `{"RemoteReplication":"FALSE"}`},
+			newClient(replicationPairRunningStatusError), false},
+		{"NormalPair", replicationLun(`["pair-1"]`), newClient("1"), false},
+		{"ErrorPair", replicationLun(`["pair-1"]`), newClient(replicationPairRunningStatusError), true},
+		{"InvalidPair", replicationLun(`["pair-1"]`), newClient(replicationPairRunningStatusInvalid), true},
+		{"PairIDsQueriedByLun", replicationLun(""), newClient(replicationPairRunningStatusError), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkReplicationPairs(context.Background(), tt.cli, tt.lun)
+			if (err != nil) != tt.expectErr {
+				t.Fatalf("checkReplicationPairs() error = %v, expectErr %v", err, tt.expectErr)
+			}
+			if tt.expectErr && !errors.Is(err, constants.ErrReplicationPairUnhealthy) {
+				t.Errorf("checkReplicationPairs() error = %v, want ErrReplicationPairUnhealthy", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package plugin
+
+import (
+	"context"
+
+	"huawei-csi-driver/utils"
+)
+
+// SnapshotExists checks whether the snapshot of the lun exists, the snapshot names of luns are unique
+func (p *OceanstorSanPlugin) SnapshotExists(ctx context.Context, _, snapshotName string) (bool, error) {
+	snapshot, err := p.cli.GetLunSnapshotByName(ctx, utils.GetSnapshotName(snapshotName))
+	if err != nil {
+		return false, err
+	}
+
+	return snapshot != nil, nil
+}
+
+// SnapshotExists checks whether the snapshot of the filesystem exists
+func (p *OceanstorNasPlugin) SnapshotExists(ctx context.Context, parentID, snapshotName string) (bool, error) {
+	snapshot, err := p.cli.GetFSSnapshotByName(ctx, parentID, utils.GetFSSnapshotName(snapshotName))
+	if err != nil {
+		return false, err
+	}
+
+	return snapshot != nil, nil
+}
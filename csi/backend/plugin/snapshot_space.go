@@ -0,0 +1,127 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// ErrSnapshotSpaceExhausted means that the storage does not have enough space for a new snapshot
+var ErrSnapshotSpaceExhausted = errors.New("snapshot space exhausted")
+
+// CheckSnapshotSpace checks whether the reserved snapshot space of the filesystem is exhausted
+func (p *OceanstorNasPlugin) CheckSnapshotSpace(ctx context.Context, fsName, snapshotName string) error {
+	fs, err := p.cli.GetFileSystemByName(ctx, fsName)
+	if err != nil {
+		return err
+	}
+	if fs == nil {
+		return fmt.Errorf("filesystem %s does not exist", fsName)
+	}
+
+	fsID, ok := fs["ID"].(string)
+	if !ok {
+		return fmt.Errorf("convert ID of filesystem %s to string failed, data: %v", fsName, fs["ID"])
+	}
+	if exist, err := p.SnapshotExists(ctx, fsID, snapshotName); err != nil || exist {
+		return err
+	}
+
+	reservePercent, err := getCapacityField(fs, "SNAPSHOTRESERVEPER")
+	if err != nil || reservePercent == 0 {
+		// the snapshots consume the space of the filesystem when there is no reserved snapshot space
+		return err
+	}
+
+	capacity, err := getCapacityField(fs, "CAPACITY")
+	if err != nil {
+		return err
+	}
+
+	used, err := getCapacityField(fs, "SNAPSHOTUSECAPACITY")
+	if err != nil {
+		return err
+	}
+
+	limit := capacity * reservePercent / 100
+	if used >= limit {
+		return fmt.Errorf("%w: the reserved snapshot space of filesystem %s is full, used %d bytes, "+
+			"limit %d bytes, please increase the reservedSnapshotSpaceRatio parameter of the StorageClass "+
+			"or delete unused snapshots", ErrSnapshotSpaceExhausted, fsName, used*SectorSize, limit*SectorSize)
+	}
+
+	return nil
+}
+
+// CheckSnapshotSpace checks whether the free capacity of the pool is enough for the copy-on-write
+// data of a new snapshot, the allocated capacity of the lun is used as the estimated reserve
+func (p *OceanstorSanPlugin) CheckSnapshotSpace(ctx context.Context, lunName, snapshotName string) error {
+	if exist, err := p.SnapshotExists(ctx, "", snapshotName); err != nil || exist {
+		return err
+	}
+
+	lun, err := p.cli.GetLunByName(ctx, lunName)
+	if err != nil {
+		return err
+	}
+	if lun == nil {
+		return fmt.Errorf("lun %s does not exist", lunName)
+	}
+
+	poolName, ok := lun["PARENTNAME"].(string)
+	if !ok {
+		return fmt.Errorf("convert PARENTNAME of lun %s to string failed, data: %v", lunName, lun["PARENTNAME"])
+	}
+
+	pool, err := p.cli.GetPoolByName(ctx, poolName)
+	if err != nil {
+		return err
+	}
+	if pool == nil {
+		return fmt.Errorf("pool %s of lun %s does not exist", poolName, lunName)
+	}
+
+	reserve, err := getCapacityField(lun, "ALLOCCAPACITY")
+	if err != nil {
+		return err
+	}
+
+	free, err := getCapacityField(pool, "USERFREECAPACITY")
+	if err != nil {
+		return err
+	}
+
+	if free < reserve {
+		return fmt.Errorf("%w: the free capacity of pool %s is %d bytes, less than the estimated snapshot "+
+			"reserve %d bytes of lun %s", ErrSnapshotSpaceExhausted, poolName, free*SectorSize,
+			reserve*SectorSize, lunName)
+	}
+
+	return nil
+}
+
+func getCapacityField(object map[string]interface{}, key string) (int64, error) {
+	value, ok := object[key].(string)
+	if !ok {
+		return 0, fmt.Errorf("convert %s to string failed, data: %v", key, object[key])
+	}
+
+	return strconv.ParseInt(value, 10, 64)
+}
@@ -0,0 +1,70 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package plugin
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"huawei-csi-driver/storage/oceanstor/client"
+)
+
+type fakeFileSystemClient struct {
+	client.BaseClientInterface
+
+	fileSystem map[string]interface{}
+	snapshot   map[string]interface{}
+}
+
+func (f *fakeFileSystemClient) GetFileSystemByName(ctx context.Context,
+	name string) (map[string]interface{}, error) {
+	return f.fileSystem, nil
+}
+
+func (f *fakeFileSystemClient) GetFSSnapshotByName(ctx context.Context,
+	parentID, snapshotName string) (map[string]interface{}, error) {
+	return f.snapshot, nil
+}
+
+func TestCheckNasSnapshotSpace(t *testing.T) {
+	tests := []struct {
+		name       string
+		fileSystem map[string]interface{}
+		snapshot   map[string]interface{}
+		wantErr    error
+	}{
+		{"NoReserve", map[string]interface{}{"ID": "1", "SNAPSHOTRESERVEPER": "0", "CAPACITY": "2048",
+			"SNAPSHOTUSECAPACITY": "100"}, nil, nil},
+		{"ReserveAvailable", map[string]interface{}{"ID": "1", "SNAPSHOTRESERVEPER": "20", "CAPACITY": "2048",
+			"SNAPSHOTUSECAPACITY": "100"}, nil, nil},
+		{"ReserveExhausted", map[string]interface{}{"ID": "1", "SNAPSHOTRESERVEPER": "20", "CAPACITY": "2048",
+			"SNAPSHOTUSECAPACITY": "409"}, nil, ErrSnapshotSpaceExhausted},
+		{"SnapshotExists", map[string]interface{}{"ID": "1", "SNAPSHOTRESERVEPER": "20", "CAPACITY": "2048",
+			"SNAPSHOTUSECAPACITY": "409"}, map[string]interface{}{"ID": "2"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &OceanstorNasPlugin{}
+			p.cli = &fakeFileSystemClient{fileSystem: tt.fileSystem, snapshot: tt.snapshot}
+			if err := p.CheckSnapshotSpace(context.TODO(), "fs", "snapshot"); !errors.Is(err, tt.wantErr) {
+				t.Errorf("CheckSnapshotSpace() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
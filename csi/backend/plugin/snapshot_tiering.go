@@ -0,0 +1,64 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package plugin
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"huawei-csi-driver/utils"
+	"huawei-csi-driver/utils/log"
+)
+
+// GetSnapshotsCreatedBefore returns the names of the lun snapshots created by CSI before the given time by their IDs
+func (p *OceanstorSanPlugin) GetSnapshotsCreatedBefore(ctx context.Context, before time.Time) (map[string]string,
+	error) {
+	snapshots, err := p.cli.GetCSILunSnapshots(ctx)
+	if err != nil {
+		log.AddContext(ctx).Errorf("Get lun snapshots error: %v", err)
+		return nil, err
+	}
+
+	names := make(map[string]string)
+	for _, snapshot := range snapshots {
+		id, _ := snapshot["ID"].(string)
+		name, _ := snapshot["NAME"].(string)
+		timestamp, _ := snapshot["TIMESTAMP"].(string)
+		created, err := strconv.ParseInt(timestamp, 10, 64)
+		if id == "" || name == "" || err != nil {
+			log.AddContext(ctx).Warningf("Skip the lun snapshot with invalid ID, NAME or TIMESTAMP, data: %v",
+				snapshot)
+			continue
+		}
+
+		if time.Unix(created, 0).Before(before) {
+			names[id] = name
+		}
+	}
+	return names, nil
+}
+
+// TierSnapshotToCloud tiers the data of the lun snapshot to the object storage
+func (p *OceanstorSanPlugin) TierSnapshotToCloud(ctx context.Context, snapshotName string) error {
+	return p.cli.TierSnapshotToCloud(ctx, utils.GetSnapshotName(snapshotName))
+}
+
+// IsSnapshotTiered returns whether the submitted tiering of the lun snapshot is completed
+func (p *OceanstorSanPlugin) IsSnapshotTiered(ctx context.Context, snapshotName string) (bool, error) {
+	return p.cli.IsSnapshotTieredToCloud(ctx, utils.GetSnapshotName(snapshotName))
+}
@@ -0,0 +1,63 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package plugin
+
+import (
+	"context"
+
+	"huawei-csi-driver/utils"
+)
+
+// VolumeExists checks whether the lun of the volume exists
+func (p *OceanstorSanPlugin) VolumeExists(ctx context.Context, name string) (bool, error) {
+	lun, err := p.cli.GetLunByName(ctx, p.cli.MakeLunName(name))
+	if err != nil {
+		return false, err
+	}
+
+	return lun != nil, nil
+}
+
+// VolumeExists checks whether the filesystem of the volume exists
+func (p *OceanstorNasPlugin) VolumeExists(ctx context.Context, name string) (bool, error) {
+	fs, err := p.cli.GetFileSystemByName(ctx, utils.GetFileSystemName(name))
+	if err != nil {
+		return false, err
+	}
+
+	return fs != nil, nil
+}
+
+// VolumeExists checks whether the volume exists
+func (p *FusionStorageSanPlugin) VolumeExists(ctx context.Context, name string) (bool, error) {
+	vol, err := p.cli.GetVolumeByName(ctx, name)
+	if err != nil {
+		return false, err
+	}
+
+	return vol != nil, nil
+}
+
+// VolumeExists checks whether the filesystem of the volume exists
+func (p *FusionStorageNasPlugin) VolumeExists(ctx context.Context, name string) (bool, error) {
+	fs, err := p.cli.GetFileSystemByName(ctx, name)
+	if err != nil {
+		return false, err
+	}
+
+	return fs != nil, nil
+}
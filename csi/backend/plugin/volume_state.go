@@ -0,0 +1,120 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"huawei-csi-driver/pkg/constants"
+	"huawei-csi-driver/utils"
+)
+
+// qosLatencyUnit is the unit of the LATENCY of the SmartQoS policy of the OceanStor Dorado V6 storage in
+// microseconds, while the qos parameter of the StorageClass is in milliseconds
+const qosLatencyUnit = 1000
+
+// InspectVolume returns the state of the lun of the volume
+func (p *OceanstorSanPlugin) InspectVolume(ctx context.Context, name string) (*VolumeState, error) {
+	lunName := p.cli.MakeLunName(name)
+	lun, err := p.cli.GetLunByName(ctx, lunName)
+	if err != nil || lun == nil {
+		return nil, err
+	}
+
+	state, err := getVolumeState(lun)
+	if err != nil {
+		return nil, err
+	}
+	return state, p.inspectQoS(ctx, state)
+}
+
+// InspectVolume returns the state of the filesystem of the volume
+func (p *OceanstorNasPlugin) InspectVolume(ctx context.Context, name string) (*VolumeState, error) {
+	fs, err := p.cli.GetFileSystemByName(ctx, utils.GetFileSystemName(name))
+	if err != nil || fs == nil {
+		return nil, err
+	}
+
+	state, err := getVolumeState(fs)
+	if err != nil {
+		return nil, err
+	}
+	return state, p.inspectQoS(ctx, state)
+}
+
+// inspectQoS fills the values of the SmartQoS policy controlling the volume
+func (p *OceanstorPlugin) inspectQoS(ctx context.Context, state *VolumeState) error {
+	if state.QoSPolicyID == "" {
+		return nil
+	}
+
+	qos, err := p.cli.GetQosByID(ctx, state.QoSPolicyID, p.vStoreId)
+	if err != nil {
+		return fmt.Errorf("get qos %s error: %v", state.QoSPolicyID, err)
+	}
+
+	state.QoS = make(map[string]string, len(qos))
+	for key, value := range qos {
+		state.QoS[key] = utils.ToStringSafe(value)
+	}
+
+	if latency, ok := state.QoS["LATENCY"]; ok && p.product == constants.OceanStorDoradoV6 {
+		if microseconds, err := strconv.ParseInt(latency, 10, 64); err == nil {
+			state.QoS["LATENCY"] = strconv.FormatInt(microseconds/qosLatencyUnit, 10)
+		}
+	}
+	return nil
+}
+
+// getVolumeState returns the state of the lun or the filesystem, they have the same fields
+func getVolumeState(object map[string]interface{}) (*VolumeState, error) {
+	capacity, err := getCapacityField(object, "CAPACITY")
+	if err != nil {
+		return nil, err
+	}
+
+	pool, ok := object["PARENTNAME"].(string)
+	if !ok {
+		return nil, fmt.Errorf("convert PARENTNAME of %v to string failed, data: %v", object["NAME"],
+			object["PARENTNAME"])
+	}
+
+	return &VolumeState{
+		StoragePool: pool,
+		Capacity:    capacity * SectorSize,
+		HyperMetro:  hasRSSObject(object, "HyperMetro"),
+		QoSPolicyID: utils.ToStringSafe(object["IOCLASSID"]),
+	}, nil
+}
+
+// hasRSSObject returns whether the lun or the filesystem is a member of the given RSS feature,
+// such as HyperMetro and RemoteReplication
+func hasRSSObject(object map[string]interface{}, feature string) bool {
+	rssStr, ok := object["HASRSSOBJECT"].(string)
+	if !ok {
+		return false
+	}
+
+	var rss map[string]string
+	if err := json.Unmarshal([]byte(rssStr), &rss); err != nil {
+		return false
+	}
+	return rss[feature] == "TRUE"
+}
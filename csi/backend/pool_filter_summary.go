@@ -0,0 +1,217 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package backend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"huawei-csi-driver/csi/backend/model"
+	"huawei-csi-driver/utils"
+	"huawei-csi-driver/utils/log"
+)
+
+// capabilityFilterReasons is the reasons of the filters whose parameter values are not readable in the summary
+var capabilityFilterReasons = map[string]string{
+	"qos":                "SupportQoS",
+	"hyperMetro":         "SupportMetro",
+	"replication":        "SupportReplication",
+	"applicationType":    "SupportApplicationType",
+	"storageQuota":       "SupportQuota",
+	"sourceVolumeName":   "SupportClone",
+	"sourceSnapshotName": "SupportClone",
+	"encrypted":          "SupportEncryption",
+	"deduplication":      "SupportPerFsDedup",
+}
+
+const capacityFilterReason = "capacity<"
+
+// ErrInsufficientCapacity is wrapped by the error of filtering pools if the last pools meeting the requirements
+// are eliminated by their free capacity. Only the thick volumes are filtered by the free capacity, the thin pools
+// are allowed to be overcommitted, so the filter never returns this error for the thin volumes
+var ErrInsufficientCapacity = errors.New("insufficient free capacity")
+
+type filterStage struct {
+	reason   string
+	filtered int
+}
+
+// PoolFilterSummary records how many candidate pools are eliminated by each filter stage and why
+type PoolFilterSummary struct {
+	total  int
+	stages []filterStage
+}
+
+// NewPoolFilterSummary returns a summary of filtering the given number of candidate pools
+func NewPoolFilterSummary(total int) *PoolFilterSummary {
+	return &PoolFilterSummary{total: total}
+}
+
+// Record records the pools eliminated by the filter stage, the stage which eliminates nothing is skipped
+func (s *PoolFilterSummary) Record(reason string, before, after int) {
+	if s == nil || before <= after {
+		return
+	}
+
+	for i := range s.stages {
+		if s.stages[i].reason == reason {
+			s.stages[i].filtered += before - after
+			return
+		}
+	}
+	s.stages = append(s.stages, filterStage{reason: reason, filtered: before - after})
+}
+
+// String returns the compact summary, e.g. "12 pools: 8 filtered by volumeType=lun, 3 by capacity<100Gi"
+func (s *PoolFilterSummary) String() string {
+	if s == nil {
+		return ""
+	}
+
+	if len(s.stages) == 0 {
+		return fmt.Sprintf("%d pools: none filtered", s.total)
+	}
+
+	stages := make([]string, 0, len(s.stages))
+	for i, stage := range s.stages {
+		if i == 0 {
+			stages = append(stages, fmt.Sprintf("%d filtered by %s", stage.filtered, stage.reason))
+		} else {
+			stages = append(stages, fmt.Sprintf("%d by %s", stage.filtered, stage.reason))
+		}
+	}
+
+	return fmt.Sprintf("%d pools: %s", s.total, strings.Join(stages, ", "))
+}
+
+// eliminatedBy checks whether the last pools are eliminated by the filter stage whose reason has the prefix
+func (s *PoolFilterSummary) eliminatedBy(reasonPrefix string) bool {
+	if s == nil || len(s.stages) == 0 {
+		return false
+	}
+
+	return strings.HasPrefix(s.stages[len(s.stages)-1].reason, reasonPrefix)
+}
+
+func capabilityFilterReason(key, value string) string {
+	if reason, exist := capabilityFilterReasons[key]; exist {
+		return reason
+	}
+
+	return fmt.Sprintf("%s=%s", key, value)
+}
+
+// FilterPools filters the storage pools by capability, topology and capacity, the returned error contains the
+// summary of the pools eliminated by each filter stage if no pool meets the requirements
+func FilterPools(ctx context.Context, requestSize int64, parameters map[string]interface{},
+	candidatePools []*model.StoragePool, filterFuncs [][]interface{}) ([]*model.StoragePool, error) {
+	summary := NewPoolFilterSummary(len(candidatePools))
+	filterPools, err := filterByCapability(ctx, parameters, candidatePools, filterFuncs, summary)
+	if err != nil {
+		return nil, err
+	}
+
+	before := len(filterPools)
+	filterPools, err = filterByTopology(parameters, filterPools)
+	if err != nil {
+		return nil, err
+	}
+	summary.Record(Topology, before, len(filterPools))
+
+	allocType, _ := parameters["allocType"].(string)
+	filterPools = filterByCapacity(requestSize, allocType, filterPools, summary)
+
+	log.AddContext(ctx).Infof("Filter storage pools for volume of size %d: %s", requestSize, summary)
+	if len(filterPools) == 0 && summary.eliminatedBy(capacityFilterReason) {
+		return nil, fmt.Errorf("%s, %s: %w", NoAvailablePool, summary, ErrInsufficientCapacity)
+	} else if len(filterPools) == 0 {
+		return nil, fmt.Errorf("%s, %s", NoAvailablePool, summary)
+	}
+
+	return filterPools, nil
+}
+
+// filterByCapability filters the storage pools by the filter functions until no pool is left
+func filterByCapability(ctx context.Context, parameters map[string]interface{},
+	candidatePools []*model.StoragePool, filterFuncs [][]interface{},
+	summary *PoolFilterSummary) ([]*model.StoragePool, error) {
+	var err error
+	for _, i := range filterFuncs {
+		key, filter := i[0].(string), i[1].(func(context.Context, string, []*model.StoragePool) ([]*model.StoragePool,
+			error))
+		value, _ := parameters[key].(string)
+		before := len(candidatePools)
+		candidatePools, err = filter(ctx, value, candidatePools)
+		reason := capabilityFilterReason(key, value)
+		if err != nil {
+			// the filters return error if they eliminate all the pools, e.g. the qos filter
+			summary.Record(reason, before, 0)
+			return nil, fmt.Errorf("filter pool by %s failed, %s, error: %v", reason, summary, err)
+		}
+
+		summary.Record(reason, before, len(candidatePools))
+		if len(candidatePools) == 0 {
+			break
+		}
+	}
+
+	return candidatePools, nil
+}
+
+// filterByCapacity filters the storage pools by the thin or thick support and the free capacity
+func filterByCapacity(requestSize int64, allocType string, candidatePools []*model.StoragePool,
+	summary *PoolFilterSummary) []*model.StoragePool {
+	supportKey := "SupportThin"
+	if allocType == "thick" {
+		supportKey = "SupportThick"
+	} else if allocType != "" && allocType != "thin" {
+		summary.Record("allocType="+allocType, len(candidatePools), 0)
+		return nil
+	}
+
+	var supportedPools []*model.StoragePool
+	for _, pool := range candidatePools {
+		support, exist := pool.Capabilities[supportKey]
+		if !exist {
+			log.Warningf("convert %s to bool failed, data: %v", supportKey, pool.Capabilities[supportKey])
+		}
+		if exist && support {
+			supportedPools = append(supportedPools, pool)
+		}
+	}
+	summary.Record(supportKey, len(candidatePools), len(supportedPools))
+
+	if allocType != "thick" {
+		return supportedPools
+	}
+
+	var filterPools []*model.StoragePool
+	for _, pool := range supportedPools {
+		freeCapacity := utils.ParseIntWithDefault(pool.GetCapacities()["FreeCapacity"], 10, 64, 0)
+		if requestSize <= freeCapacity {
+			filterPools = append(filterPools, pool)
+		}
+	}
+	summary.Record(capacityFilterReason+resource.NewQuantity(requestSize, resource.BinarySI).String(),
+		len(supportedPools), len(filterPools))
+
+	return filterPools
+}
@@ -0,0 +1,120 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package backend
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"huawei-csi-driver/csi/backend/model"
+)
+
+func TestFilterPoolsSummary(t *testing.T) {
+	newPools := func(count int, storage string, capabilities map[string]bool, freeCapacity string) []*model.StoragePool {
+		var pools []*model.StoragePool
+		for i := 0; i < count; i++ {
+			pools = append(pools, &model.StoragePool{
+				Storage:      storage,
+				Capabilities: capabilities,
+				Capacities:   map[string]string{"FreeCapacity": freeCapacity},
+			})
+		}
+		return pools
+	}
+	filterFuncs := [][]interface{}{
+		{"volumeType", filterByVolumeType},
+		{"qos", filterByQos},
+	}
+	requestSize := int64(100 * 1024 * 1024 * 1024)
+
+	tests := []struct {
+		name       string
+		parameters map[string]interface{}
+		pools      []*model.StoragePool
+		expectErr  string
+	}{
+		{"FilteredByVolumeTypeAndCapacity",
+			map[string]interface{}{"volumeType": "lun", "allocType": "thick"},
+			append(newPools(8, "oceanstor-nas", map[string]bool{"SupportThin": true}, "0"),
+				newPools(4, "oceanstor-san", map[string]bool{"SupportThick": true}, "1024")...),
+			"12 pools: 8 filtered by volumeType=lun, 4 by capacity<100Gi"},
+		{"FilteredBySupportThick",
+			map[string]interface{}{"volumeType": "lun", "allocType": "thick"},
+			append(newPools(1, "oceanstor-san", map[string]bool{"SupportThick": false}, "0"),
+				newPools(2, "oceanstor-san", map[string]bool{"SupportThick": true}, "1024")...),
+			"3 pools: 1 filtered by SupportThick, 2 by capacity<100Gi"},
+		{"FilteredBySupportQoS",
+			map[string]interface{}{"volumeType": "lun", "qos": `{"MAXIOPS": 1000}`},
+			append(newPools(1, "oceanstor-nas", map[string]bool{"SupportThin": true}, "0"),
+				newPools(2, "oceanstor-san", map[string]bool{"SupportQoS": false}, "0")...),
+			"3 pools: 1 filtered by volumeType=lun, 2 by SupportQoS"},
+		{"NoneFiltered",
+			map[string]interface{}{"volumeType": "lun"},
+			newPools(2, "oceanstor-san", map[string]bool{"SupportThin": true}, "0"),
+			""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pools, err := FilterPools(ctx, requestSize, tt.parameters, tt.pools, filterFuncs)
+			if tt.expectErr == "" {
+				if err != nil || len(pools) != len(tt.pools) {
+					t.Errorf("test FilterPools failed, got %d pools, error: %v", len(pools), err)
+				}
+				return
+			}
+
+			if err == nil || !strings.Contains(err.Error(), tt.expectErr) {
+				t.Errorf("test FilterPools failed, expect error contains %q, got: %v", tt.expectErr, err)
+			}
+		})
+	}
+}
+
+func TestPoolFilterSummaryString(t *testing.T) {
+	summary := NewPoolFilterSummary(3)
+	if got := summary.String(); got != "3 pools: none filtered" {
+		t.Errorf("test PoolFilterSummary failed, got: %s", got)
+	}
+
+	summary.Record("SupportClone", 3, 2)
+	summary.Record(Topology, 2, 2)
+	summary.Record("SupportClone", 2, 1)
+	if got := summary.String(); got != "3 pools: 2 filtered by SupportClone" {
+		t.Errorf("test PoolFilterSummary failed, got: %s", got)
+	}
+}
+
+func TestFilterPoolsInsufficientCapacity(t *testing.T) {
+	pools := []*model.StoragePool{{
+		Storage:      "oceanstor-san",
+		Capabilities: map[string]bool{"SupportThin": true, "SupportThick": true},
+		Capacities:   map[string]string{"FreeCapacity": "0"},
+	}}
+
+	_, err := FilterPools(ctx, 1024, map[string]interface{}{"allocType": "thick"}, pools, nil)
+	if !errors.Is(err, ErrInsufficientCapacity) {
+		t.Errorf("test FilterPools of thick volume failed, expect ErrInsufficientCapacity, got: %v", err)
+	}
+
+	// the thin pools are allowed to be overcommitted, so the full pool is still selected
+	filterPools, err := FilterPools(ctx, 1024, map[string]interface{}{"allocType": "thin"}, pools, nil)
+	if err != nil || len(filterPools) != 1 {
+		t.Errorf("test FilterPools of thin volume failed, got %d pools, error: %v", len(filterPools), err)
+	}
+}
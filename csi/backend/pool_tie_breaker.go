@@ -0,0 +1,83 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package backend
+
+import (
+	"sync"
+
+	"huawei-csi-driver/csi/app"
+	"huawei-csi-driver/csi/backend/model"
+	"huawei-csi-driver/pkg/constants"
+)
+
+// poolSelectionRecorder tracks in memory the order in which the pools are selected, it is reset when the
+// controller restarts, which only affects the first selections of the pools with equal free capacity
+type poolSelectionRecorder struct {
+	mutex    sync.Mutex
+	sequence uint64
+	selected map[string]uint64
+}
+
+var poolSelections = &poolSelectionRecorder{selected: make(map[string]uint64)}
+
+func poolKey(pool *model.StoragePool) string {
+	return pool.Parent + ":" + pool.Name
+}
+
+func (r *poolSelectionRecorder) record(pool *model.StoragePool) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.sequence++
+	r.selected[poolKey(pool)] = r.sequence
+}
+
+// lastSelected returns the sequence of the last selection of the pool, 0 means never selected
+func (r *poolSelectionRecorder) lastSelected(pool *model.StoragePool) uint64 {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return r.selected[poolKey(pool)]
+}
+
+// breakPoolTie selects one of the pools with equal free capacity by the configured tie breaker, the pools
+// which are selected equally long ago are ordered by backend and pool name, so that the result is deterministic
+func breakPoolTie(pools []*model.StoragePool, tieBreaker string) *model.StoragePool {
+	var selectPool *model.StoragePool
+	for _, pool := range pools {
+		if selectPool == nil || poolPrecedes(pool, selectPool, tieBreaker) {
+			selectPool = pool
+		}
+	}
+
+	return selectPool
+}
+
+func poolPrecedes(pool, other *model.StoragePool, tieBreaker string) bool {
+	if tieBreaker != constants.PoolTieBreakerLexical {
+		poolSelected, otherSelected := poolSelections.lastSelected(pool), poolSelections.lastSelected(other)
+		if poolSelected != otherSelected {
+			return poolSelected < otherSelected
+		}
+	}
+
+	return poolKey(pool) < poolKey(other)
+}
+
+func getPoolTieBreaker() string {
+	return app.GetGlobalConfig().PoolTieBreaker
+}
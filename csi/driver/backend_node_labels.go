@@ -0,0 +1,219 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package driver
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	coreV1 "k8s.io/api/core/v1"
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"huawei-csi-driver/csi/backend/cache"
+	"huawei-csi-driver/csi/backend/model"
+	"huawei-csi-driver/utils/k8sutils"
+	"huawei-csi-driver/utils/log"
+)
+
+const (
+	// the node labels restricting the backends which the node uses, the value is a list of the backend names
+	// or protocols separated by underscores, such as csi.huawei.com/deny-protocol=fc_roce
+	denyBackendLabel  = "csi.huawei.com/deny-backend"
+	allowBackendLabel = "csi.huawei.com/allow-backend"
+	denyProtocolLabel = "csi.huawei.com/deny-protocol"
+
+	nodeLabelValueSeparator = "_"
+
+	// backendChangeCheckInterval is the interval of checking whether the cached backends change, the nodes are
+	// labeled again for the changed backends
+	backendChangeCheckInterval = 10 * time.Second
+)
+
+func labelValues(labels map[string]string, key string) []string {
+	value, exist := labels[key]
+	if !exist || value == "" {
+		return nil
+	}
+	return strings.Split(value, nodeLabelValueSeparator)
+}
+
+func containsValue(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// checkBackendByNodeLabels returns the error explaining the label if the node labels deny the backend
+func checkBackendByNodeLabels(labels map[string]string, backendName, protocol string) error {
+	if containsValue(labelValues(labels, denyBackendLabel), backendName) {
+		return fmt.Errorf("backend %s is denied by the node label %s=%s", backendName, denyBackendLabel,
+			labels[denyBackendLabel])
+	}
+
+	if allowed := labelValues(labels, allowBackendLabel); len(allowed) > 0 && !containsValue(allowed, backendName) {
+		return fmt.Errorf("backend %s is not allowed by the node label %s=%s", backendName, allowBackendLabel,
+			labels[allowBackendLabel])
+	}
+
+	if protocol != "" && containsValue(labelValues(labels, denyProtocolLabel), protocol) {
+		return fmt.Errorf("protocol %s of backend %s is denied by the node label %s=%s", protocol, backendName,
+			denyProtocolLabel, labels[denyProtocolLabel])
+	}
+
+	return nil
+}
+
+func getBackendProtocol(bk *model.Backend) string {
+	protocol, _ := bk.Parameters["protocol"].(string)
+	return protocol
+}
+
+// checkNodeAllowsBackend rejects publishing the volumes of a backend to the node whose labels deny the backend,
+// such as the pre-bound PVs which are not scheduled by the topology. The labels are read on each publish, and
+// the node name is resolved by the host name of the node id.
+func (d *Driver) checkNodeAllowsBackend(ctx context.Context, bk *model.Backend,
+	parameters map[string]interface{}) error {
+	nodeName, _ := parameters["HostName"].(string)
+	if d.k8sUtils == nil || nodeName == "" {
+		return nil
+	}
+
+	labels, err := d.k8sUtils.GetNodeLabels(ctx, nodeName)
+	if err != nil {
+		if !apiErrors.IsNotFound(err) {
+			log.AddContext(ctx).Warningf("Get labels of node %s failed, skip checking the backend labels, "+
+				"error: %v", nodeName, err)
+		}
+		return nil
+	}
+
+	if err = checkBackendByNodeLabels(labels, bk.Name, getBackendProtocol(bk)); err != nil {
+		return fmt.Errorf("node %s cannot use the volume: %w", nodeName, err)
+	}
+	return nil
+}
+
+// WatchBackendNodeLabels keeps the backend topology labels of the nodes, a node is labeled with the backend
+// topology label of each cached backend which is not denied by its labels, so that the scheduler only places
+// the volumes of the backend on the labeled nodes. All the nodes are labeled again when the backends change.
+func (d *Driver) WatchBackendNodeLabels(ctx context.Context, stopCh <-chan struct{}) {
+	log.AddContext(ctx).Infoln("Start to watch the backend labels of nodes")
+	go d.watchBackendChanges(ctx, stopCh)
+	d.k8sUtils.WatchNodes(ctx, func(node *coreV1.Node) {
+		d.reconcileBackendNodeLabels(ctx, node)
+	}, stopCh)
+}
+
+// watchBackendChanges labels all the nodes again when a backend is registered, removed, or its protocol changes,
+// the nodes are labeled by the node watch when it starts
+func (d *Driver) watchBackendChanges(ctx context.Context, stopCh <-chan struct{}) {
+	lastBackends := getBackendLabelKeys(cache.BackendCacheProvider.List(ctx))
+	wait.Until(func() {
+		backends := getBackendLabelKeys(cache.BackendCacheProvider.List(ctx))
+		if reflect.DeepEqual(backends, lastBackends) {
+			return
+		}
+
+		log.AddContext(ctx).Infof("Backends change from %v to %v, update the backend labels of nodes",
+			lastBackends, backends)
+		nodes, err := d.k8sUtils.ListNodes(ctx)
+		if err != nil {
+			log.AddContext(ctx).Warningf("List nodes to update the backend labels failed, error: %v", err)
+			return
+		}
+		for i := range nodes {
+			d.reconcileBackendNodeLabels(ctx, &nodes[i])
+		}
+		lastBackends = backends
+	}, backendChangeCheckInterval, stopCh)
+}
+
+// getBackendLabelKeys returns the sorted names and protocols of the backends, which decide the backend labels
+func getBackendLabelKeys(backends []model.Backend) []string {
+	keys := make([]string, 0, len(backends))
+	for _, bk := range backends {
+		keys = append(keys, bk.Name+"/"+getBackendProtocol(&bk))
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// getBackendTopologySegment returns the backend segment of the supported topologies of the backend, nil is
+// returned if the backend topology is not enabled
+func getBackendTopologySegment(supportedTopologies []map[string]string) map[string]string {
+	for _, topology := range supportedTopologies {
+		for key, value := range topology {
+			if strings.HasPrefix(key, k8sutils.BackendTopologyPrefix) {
+				return map[string]string{key: value}
+			}
+		}
+	}
+	return nil
+}
+
+func (d *Driver) reconcileBackendNodeLabels(ctx context.Context, node *coreV1.Node) {
+	labels, removed := getBackendNodeLabelChanges(node.Labels, cache.BackendCacheProvider.List(ctx), d.name)
+	if len(labels) == 0 && len(removed) == 0 {
+		return
+	}
+
+	log.AddContext(ctx).Infof("Update backend labels of node %s, added: %v, removed: %v",
+		node.Name, labels, removed)
+	if err := d.k8sUtils.UpdateNodeLabels(ctx, node.Name, labels, removed); err != nil {
+		log.AddContext(ctx).Warningf("Update backend labels of node %s failed, error: %v", node.Name, err)
+	}
+}
+
+// getBackendNodeLabelChanges returns the backend topology labels to add to and remove from the node
+func getBackendNodeLabelChanges(nodeLabels map[string]string, backends []model.Backend,
+	driverName string) (map[string]string, []string) {
+	desired := make(map[string]string)
+	for _, bk := range backends {
+		key := k8sutils.BackendTopologyPrefix + bk.Name
+		if len(validation.IsQualifiedName(key)) != 0 {
+			continue
+		}
+		if checkBackendByNodeLabels(nodeLabels, bk.Name, getBackendProtocol(&bk)) == nil {
+			desired[key] = driverName
+		}
+	}
+
+	added := make(map[string]string)
+	for key, value := range desired {
+		if nodeLabels[key] != value {
+			added[key] = value
+		}
+	}
+
+	var removed []string
+	for key := range nodeLabels {
+		if _, exist := desired[key]; !exist && strings.HasPrefix(key, k8sutils.BackendTopologyPrefix) {
+			removed = append(removed, key)
+		}
+	}
+
+	return added, removed
+}
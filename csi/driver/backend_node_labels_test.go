@@ -0,0 +1,110 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package driver
+
+import (
+	"reflect"
+	"testing"
+
+	"huawei-csi-driver/csi/backend/model"
+)
+
+func TestCheckBackendByNodeLabels(t *testing.T) {
+	tests := []struct {
+		name      string
+		labels    map[string]string
+		backend   string
+		protocol  string
+		expectErr bool
+	}{
+		{"NoLabels", nil, "backend-a", "fc", false},
+		{"DeniedBackend", map[string]string{denyBackendLabel: "backend-b_backend-a"}, "backend-a", "iscsi", true},
+		{"OtherDeniedBackend", map[string]string{denyBackendLabel: "backend-b"}, "backend-a", "iscsi", false},
+		{"AllowedBackend", map[string]string{allowBackendLabel: "backend-a"}, "backend-a", "iscsi", false},
+		{"NotAllowedBackend", map[string]string{allowBackendLabel: "backend-b"}, "backend-a", "iscsi", true},
+		{"DeniedProtocol", map[string]string{denyProtocolLabel: "roce_fc"}, "backend-a", "fc", true},
+		{"OtherDeniedProtocol", map[string]string{denyProtocolLabel: "fc"}, "backend-a", "iscsi", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkBackendByNodeLabels(tt.labels, tt.backend, tt.protocol)
+			if (err != nil) != tt.expectErr {
+				t.Errorf("checkBackendByNodeLabels() error = %v, expectErr %v", err, tt.expectErr)
+			}
+		})
+	}
+}
+
+func TestGetBackendNodeLabelChanges(t *testing.T) {
+	backends := []model.Backend{
+		{Name: "san-fc", Parameters: map[string]interface{}{"protocol": "fc"}},
+		{Name: "san-iscsi", Parameters: map[string]interface{}{"protocol": "iscsi"}},
+	}
+	nodeLabels := map[string]string{
+		denyProtocolLabel:                            "fc",
+		"topology.kubernetes.io/backend.san-fc":      "csi.huawei.com",
+		"topology.kubernetes.io/backend.deleted":     "csi.huawei.com",
+		"topology.kubernetes.io/protocol.iscsi":      "csi.huawei.com",
+		"topology.kubernetes.io/backend.san-iscsi-x": "",
+	}
+
+	added, removed := getBackendNodeLabelChanges(nodeLabels, backends, "csi.huawei.com")
+	expectAdded := map[string]string{"topology.kubernetes.io/backend.san-iscsi": "csi.huawei.com"}
+	if !reflect.DeepEqual(added, expectAdded) {
+		t.Errorf("getBackendNodeLabelChanges() added = %v, want %v", added, expectAdded)
+	}
+	if len(removed) != 3 {
+		t.Errorf("getBackendNodeLabelChanges() removed = %v, want the labels of san-fc, deleted and san-iscsi-x",
+			removed)
+	}
+}
+
+func TestGetBackendTopologySegment(t *testing.T) {
+	tests := []struct {
+		name       string
+		topologies []map[string]string
+		want       map[string]string
+	}{
+		{"BackendTopology", []map[string]string{{"topology.kubernetes.io/protocol.fc": "csi.huawei.com",
+			"topology.kubernetes.io/backend.san": "csi.huawei.com"}},
+			map[string]string{"topology.kubernetes.io/backend.san": "csi.huawei.com"}},
+		{"BackendTopologyDisabled", []map[string]string{{"topology.kubernetes.io/protocol.fc": "csi.huawei.com"}},
+			nil},
+		{"NoTopology", nil, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := getBackendTopologySegment(tt.topologies); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("getBackendTopologySegment() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetBackendLabelKeys(t *testing.T) {
+	backends := []model.Backend{
+		{Name: "san-iscsi", Parameters: map[string]interface{}{"protocol": "iscsi"}},
+		{Name: "san-fc", Parameters: map[string]interface{}{"protocol": "fc"}},
+	}
+
+	want := []string{"san-fc/fc", "san-iscsi/iscsi"}
+	if got := getBackendLabelKeys(backends); !reflect.DeepEqual(got, want) {
+		t.Errorf("getBackendLabelKeys() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,77 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package driver
+
+import (
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"huawei-csi-driver/csi/app"
+	"huawei-csi-driver/pkg/constants"
+)
+
+// controllerCapabilities is all the controller capabilities which the driver supports
+var controllerCapabilities = []csi.ControllerServiceCapability_RPC_Type{
+	csi.ControllerServiceCapability_RPC_CREATE_DELETE_VOLUME,
+	csi.ControllerServiceCapability_RPC_PUBLISH_UNPUBLISH_VOLUME,
+	csi.ControllerServiceCapability_RPC_EXPAND_VOLUME,
+	csi.ControllerServiceCapability_RPC_CREATE_DELETE_SNAPSHOT,
+	csi.ControllerServiceCapability_RPC_CLONE_VOLUME,
+	csi.ControllerServiceCapability_RPC_GET_VOLUME,
+}
+
+// isCapabilityDisabled checks whether the capability is disabled by the flags of this deployment
+func isCapabilityDisabled(capability csi.ControllerServiceCapability_RPC_Type) bool {
+	switch capability {
+	case csi.ControllerServiceCapability_RPC_CREATE_DELETE_SNAPSHOT:
+		return app.GetGlobalConfig().DisableSnapshot
+	case csi.ControllerServiceCapability_RPC_CLONE_VOLUME:
+		return app.GetGlobalConfig().DisableClone
+	case csi.ControllerServiceCapability_RPC_EXPAND_VOLUME:
+		return app.GetGlobalConfig().DisableExpand
+	default:
+		return false
+	}
+}
+
+// checkCapabilityEnabled returns the FailedPrecondition error if the capability is disabled
+func checkCapabilityEnabled(capability csi.ControllerServiceCapability_RPC_Type) error {
+	if isCapabilityDisabled(capability) {
+		return status.Errorf(codes.FailedPrecondition, constants.CapabilityDisabledFormat, capability)
+	}
+
+	return nil
+}
+
+// getEnabledControllerCapabilities returns the controller capabilities which are not disabled
+func getEnabledControllerCapabilities() []*csi.ControllerServiceCapability {
+	var capabilities []*csi.ControllerServiceCapability
+	for _, capability := range controllerCapabilities {
+		if isCapabilityDisabled(capability) {
+			continue
+		}
+
+		capabilities = append(capabilities, &csi.ControllerServiceCapability{
+			Type: &csi.ControllerServiceCapability_Rpc{
+				Rpc: &csi.ControllerServiceCapability_RPC{Type: capability},
+			},
+		})
+	}
+
+	return capabilities
+}
@@ -21,6 +21,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/container-storage-interface/spec/lib/go/csi"
 	"github.com/golang/protobuf/ptypes/timestamp"
@@ -28,6 +29,8 @@ import (
 	"google.golang.org/grpc/status"
 
 	"huawei-csi-driver/csi/app"
+	"huawei-csi-driver/csi/backend"
+	"huawei-csi-driver/csi/backend/model"
 	"huawei-csi-driver/csi/backend/plugin"
 	pkgUtils "huawei-csi-driver/pkg/utils"
 	"huawei-csi-driver/utils"
@@ -61,7 +64,7 @@ func (d *Driver) CreateVolume(ctx context.Context, req *csi.CreateVolumeRequest)
 		msg := fmt.Sprintf("The annotation with PVC %s is incorrect, both VolumeName [%s] and BackendName [%s] "+
 			"should configure.", req.GetName(), volumeName, backendName)
 		log.AddContext(ctx).Errorln(msg)
-		return nil, status.Error(codes.FailedPrecondition, msg)
+		return nil, status.Error(getPartialManageAnnotationsCode(ctx, req.GetName()), msg)
 	} else if volumeOk && backendOk {
 		// manage Volume
 		return d.manageVolume(ctx, req, volumeName, backendName)
@@ -83,20 +86,42 @@ func (d *Driver) DeleteVolume(ctx context.Context, req *csi.DeleteVolumeRequest)
 		return &csi.DeleteVolumeResponse{}, nil
 	}
 
+	if err = checkBackendWritable(ctx, bk, "deleting volume"); err != nil {
+		return nil, d.newVolumeResourceNames(ctx, volumeId, bk).statusError(codes.FailedPrecondition, err)
+	}
+
+	// the PVC of the volume to delete usually does not exist any more, so no event is recorded
+	if err = d.checkBackendMaintenance(ctx, bk, "", "deleting volume"); err != nil {
+		return nil, d.newVolumeResourceNames(ctx, volumeId, bk).statusError(codes.Unavailable, err)
+	}
+
+	defer beginPluginOperation(bk.Plugin)()
+
+	// Reset the nfs share clients which are restricted by the PVC annotation to all clients,
+	// so that the share is left as created by default if the volume is retained on the storage.
+	if clientACL, ok := bk.Plugin.(plugin.NFSShareClientACL); ok && d.hasNfsClientACL(ctx, volName) {
+		if err = clientACL.UpdateNFSShareClientACL(ctx, volName, []string{nfsAllClients}); err != nil {
+			log.AddContext(ctx).Warningf("Reset nfs share clients of volume %s error: %v", volumeId, err)
+		}
+	}
+
 	if bk.Storage == plugin.DTreeStorage {
 		err = bk.Plugin.DeleteDTreeVolume(ctx, map[string]interface{}{
-			"parentname": bk.Parameters["parentname"],
+			"parentname": d.getDTreeParentName(ctx, volumeId, bk),
 			"name":       volName,
 		})
-	} else {
+	} else if err = d.deleteInitialSnapshot(ctx, bk, volumeId); err == nil {
 		err = bk.Plugin.DeleteVolume(ctx, volName)
 	}
 
 	if err != nil {
 		log.AddContext(ctx).Errorf("Delete volume %s error: %v", volumeId, err)
-		return nil, status.Error(codes.Internal, err.Error())
+		dumpRequestRecords(ctx, bk.Plugin)
+		return nil, d.newVolumeResourceNames(ctx, volumeId, bk).statusError(codes.Internal, err)
 	}
 
+	volumePublishCache.deleteVolume(volumeId)
+	backend.ForgetPlacement(volumeId)
 	log.AddContext(ctx).Infof("Volume %s is deleted", volumeId)
 
 	// Delete the topology after the volume is successfully deleted.
@@ -116,6 +141,11 @@ func (d *Driver) ControllerExpandVolume(ctx context.Context, req *csi.Controller
 	}
 
 	log.AddContext(ctx).Infof("Start to controller expand volume %s", volumeId)
+	if err := checkCapabilityEnabled(csi.ControllerServiceCapability_RPC_EXPAND_VOLUME); err != nil {
+		log.AddContext(ctx).Errorf("Expand volume %s error: %v", volumeId, err)
+		return nil, err
+	}
+
 	if req.GetCapacityRange() == nil {
 		return nil, status.Error(codes.InvalidArgument, "no capacity range provided")
 	}
@@ -134,28 +164,69 @@ func (d *Driver) ControllerExpandVolume(ctx context.Context, req *csi.Controller
 		return nil, status.Error(codes.Internal, msg)
 	}
 
+	if err = checkBackendWritable(ctx, backend, "expanding volume"); err != nil {
+		return nil, d.newVolumeResourceNames(ctx, volumeId, backend).statusError(codes.FailedPrecondition, err)
+	}
+
+	if err = d.checkBackendMaintenance(ctx, backend, volName, "expanding volume"); err != nil {
+		return nil, d.newVolumeResourceNames(ctx, volumeId, backend).statusError(codes.Unavailable, err)
+	}
+
 	if support, err := isSupportExpandVolume(ctx, req, backend); !support {
 		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
 
+	names := d.newVolumeResourceNames(ctx, volumeId, backend)
+	capacity, err := getExpandCapacity(backend.Storage, minSize, maxSize)
+	if err != nil {
+		log.AddContext(ctx).Errorf("Expand volume %s error: %v", volumeId, err)
+		return nil, names.statusError(codes.OutOfRange, err)
+	}
+
+	if err := d.checkExpansionSizeBounds(ctx, volumeId, minSize); err != nil {
+		return nil, err
+	}
+
+	if backend.Parameters["protocol"] == protocolFcNvme {
+		if err := d.checkOnlineExpansion(ctx, volumeId); err != nil {
+			log.AddContext(ctx).Errorln(err)
+			return nil, names.statusError(codes.FailedPrecondition, err)
+		}
+	}
+
+	defer beginPluginOperation(backend.Plugin)()
+
+	if maxSize > 0 && backend.Storage != plugin.DTreeStorage {
+		capacity, err = checkExpandingCapacity(ctx, backend.Plugin, volName, capacity, maxSize)
+		if err != nil {
+			log.AddContext(ctx).Errorf("Expand volume %s error: %v", volumeId, err)
+			return nil, names.statusError(codes.OutOfRange, err)
+		}
+	}
+
 	var nodeExpansionRequired bool
 	if backend.Storage == plugin.DTreeStorage {
-		nodeExpansionRequired, err = backend.Plugin.ExpandDTreeVolume(ctx, map[string]interface{}{
+		expandParams := map[string]interface{}{
 			"name":           volName,
-			"parentname":     backend.Parameters["parentname"],
-			"spacehardquota": minSize,
-		})
+			"parentname":     d.getDTreeParentName(ctx, volumeId, backend),
+			"spacehardquota": capacity,
+		}
+		if percent := d.getSpaceSoftQuotaPercent(ctx, volumeId); percent != "" {
+			expandParams["spacesoftquotapercent"] = percent
+		}
+		nodeExpansionRequired, err = backend.Plugin.ExpandDTreeVolume(ctx, expandParams)
 	} else {
-		nodeExpansionRequired, err = backend.Plugin.ExpandVolume(ctx, volName, minSize)
+		nodeExpansionRequired, err = backend.Plugin.ExpandVolume(ctx, volName, capacity)
 	}
 	if err != nil {
 		log.AddContext(ctx).Errorf("Expand volume %s error: %v", volumeId, err)
-		return nil, status.Error(codes.Internal, err.Error())
+		dumpRequestRecords(ctx, backend.Plugin)
+		return nil, names.statusError(storageErrorCode(err), err)
 	}
 
-	log.AddContext(ctx).Infof("Volume %s is expanded to %d, nodeExpansionRequired %t", volName, minSize, nodeExpansionRequired)
+	log.AddContext(ctx).Infof("Volume %s is expanded to %d, nodeExpansionRequired %t", volName, capacity, nodeExpansionRequired)
 	return &csi.ControllerExpandVolumeResponse{
-		CapacityBytes:         minSize,
+		CapacityBytes:         capacity,
 		NodeExpansionRequired: nodeExpansionRequired,
 	}, nil
 }
@@ -184,10 +255,33 @@ func (d *Driver) ControllerPublishVolume(ctx context.Context, req *csi.Controlle
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
+	if err = d.checkNodeAllowsBackend(ctx, backend, parameters); err != nil {
+		log.AddContext(ctx).Errorf("controller publish volume %s to node %s error: %v", volName, nodeId, err)
+		return nil, d.newVolumeResourceNames(ctx, volumeId, backend).statusError(codes.FailedPrecondition, err)
+	}
+
+	if err = d.checkVolumeMigrationFence(ctx, volumeId); err != nil {
+		log.AddContext(ctx).Errorf("controller publish volume %s to node %s error: %v", volName, nodeId, err)
+		return nil, d.newVolumeResourceNames(ctx, volumeId, backend).statusError(codes.FailedPrecondition, err)
+	}
+
+	if publishContext, exist := loadCachedPublishContext(ctx, req, backend, parameters); exist {
+		log.AddContext(ctx).Infof("Volume %s is already controller published to node %s, return the cached "+
+			"publish context", volumeId, nodeId)
+		return &csi.ControllerPublishVolumeResponse{PublishContext: publishContext}, nil
+	}
+
+	if err = d.checkBackendMaintenance(ctx, backend, volName, "publishing volume"); err != nil {
+		return nil, d.newVolumeResourceNames(ctx, volumeId, backend).statusError(codes.Unavailable, err)
+	}
+
+	defer beginPluginOperation(backend.Plugin)()
+
 	mappingInfo, err := backend.Plugin.AttachVolume(ctx, volName, parameters)
 	if err != nil {
 		log.AddContext(ctx).Errorf("controller publish volume %s to node %s error: %v", volName, nodeId, err)
-		return nil, status.Error(codes.Internal, err.Error())
+		dumpRequestRecords(ctx, backend.Plugin)
+		return nil, d.newVolumeResourceNames(ctx, volumeId, backend).statusError(storageErrorCode(err), err)
 	}
 
 	publishInfo, err := json.Marshal(mappingInfo)
@@ -196,13 +290,20 @@ func (d *Driver) ControllerPublishVolume(ctx context.Context, req *csi.Controlle
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
-	log.AddContext(ctx).Infof("Volume %s is controller published to node %s", volumeId, nodeId)
-	return &csi.ControllerPublishVolumeResponse{
-		PublishContext: map[string]string{
-			"publishInfo":    string(publishInfo),
-			"filesystemMode": getBackendFilesystemMode(ctx, backend, volName),
-		},
-	}, nil
+	targetPorts, hostGroupName := getPublishedPaths(mappingInfo)
+	log.AddContext(ctx).Infof("Volume %s is controller published to node %s, target ports: %v, host group: %s",
+		volumeId, nodeId, targetPorts, hostGroupName)
+	publishContext := map[string]string{
+		"publishInfo":    string(publishInfo),
+		"filesystemMode": getBackendFilesystemMode(ctx, backend, volName),
+		"targetPorts":    strings.Join(targetPorts, ","),
+		"hostGroupName":  hostGroupName,
+	}
+	if backend.Storage == plugin.DTreeStorage {
+		publishContext[dTreeParentNameAttribute] = d.getDTreeParentName(ctx, volumeId, backend)
+	}
+	volumePublishCache.store(req, publishContext)
+	return &csi.ControllerPublishVolumeResponse{PublishContext: publishContext}, nil
 }
 
 // ControllerUnpublishVolume used to controller unpublish volume
@@ -230,12 +331,22 @@ func (d *Driver) ControllerUnpublishVolume(ctx context.Context, req *csi.Control
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
+	if err = d.checkBackendMaintenance(ctx, backend, volName, "unpublishing volume"); err != nil {
+		return nil, d.newVolumeResourceNames(ctx, volumeId, backend).statusError(codes.Unavailable, err)
+	}
+
+	d.setRecordedHosts(ctx, volumeId, parameters)
+
+	defer beginPluginOperation(backend.Plugin)()
+
 	err = backend.Plugin.DetachVolume(ctx, volName, parameters)
 	if err != nil {
 		log.AddContext(ctx).Errorf("Unpublish volume %s from node %s error: %v", volName, nodeInfo, err)
-		return nil, status.Error(codes.Internal, err.Error())
+		dumpRequestRecords(ctx, backend.Plugin)
+		return nil, d.newVolumeResourceNames(ctx, volumeId, backend).statusError(codes.Internal, err)
 	}
 
+	volumePublishCache.delete(volumeId, nodeInfo)
 	log.AddContext(ctx).Infof("Volume %s is controller unpublished from node %s", volumeId, nodeInfo)
 	return &csi.ControllerUnpublishVolumeResponse{}, nil
 }
@@ -262,43 +373,7 @@ func (d *Driver) ControllerGetCapabilities(ctx context.Context, req *csi.Control
 	*csi.ControllerGetCapabilitiesResponse, error) {
 
 	return &csi.ControllerGetCapabilitiesResponse{
-		Capabilities: []*csi.ControllerServiceCapability{
-			{
-				Type: &csi.ControllerServiceCapability_Rpc{
-					Rpc: &csi.ControllerServiceCapability_RPC{
-						Type: csi.ControllerServiceCapability_RPC_CREATE_DELETE_VOLUME,
-					},
-				},
-			},
-			{
-				Type: &csi.ControllerServiceCapability_Rpc{
-					Rpc: &csi.ControllerServiceCapability_RPC{
-						Type: csi.ControllerServiceCapability_RPC_PUBLISH_UNPUBLISH_VOLUME,
-					},
-				},
-			},
-			{
-				Type: &csi.ControllerServiceCapability_Rpc{
-					Rpc: &csi.ControllerServiceCapability_RPC{
-						Type: csi.ControllerServiceCapability_RPC_EXPAND_VOLUME,
-					},
-				},
-			},
-			{
-				Type: &csi.ControllerServiceCapability_Rpc{
-					Rpc: &csi.ControllerServiceCapability_RPC{
-						Type: csi.ControllerServiceCapability_RPC_CREATE_DELETE_SNAPSHOT,
-					},
-				},
-			},
-			{
-				Type: &csi.ControllerServiceCapability_Rpc{
-					Rpc: &csi.ControllerServiceCapability_RPC{
-						Type: csi.ControllerServiceCapability_RPC_CLONE_VOLUME,
-					},
-				},
-			},
-		},
+		Capabilities: getEnabledControllerCapabilities(),
 	}, nil
 }
 
@@ -317,6 +392,20 @@ func (d *Driver) CreateSnapshot(ctx context.Context, req *csi.CreateSnapshotRequ
 	}
 	log.AddContext(ctx).Infof("Start to Create snapshot %s for volume %s", snapshotName, volumeId)
 
+	if err := checkSnapshotParameters(ctx, req.GetParameters()); err != nil {
+		return nil, err
+	}
+
+	// the existing snapshots are still allowed to be deleted when creating snapshots is disabled
+	if err := checkCapabilityEnabled(csi.ControllerServiceCapability_RPC_CREATE_DELETE_SNAPSHOT); err != nil {
+		log.AddContext(ctx).Errorf("Create snapshot %s error: %v", snapshotName, err)
+		return nil, err
+	}
+
+	if err := d.checkSnapshotSource(ctx, volumeId, req.GetParameters()); err != nil {
+		return nil, err
+	}
+
 	backendName, volName := utils.SplitVolumeId(volumeId)
 	backend, err := d.backendSelector.SelectBackend(ctx, backendName)
 	if backend == nil {
@@ -325,10 +414,53 @@ func (d *Driver) CreateSnapshot(ctx context.Context, req *csi.CreateSnapshotRequ
 		return nil, status.Error(codes.Internal, msg)
 	}
 
-	snapshot, err := backend.Plugin.CreateSnapshot(ctx, volName, snapshotName)
+	names := d.newVolumeResourceNames(ctx, volumeId, backend)
+	names.snapshot = snapshotName
+	if err = checkBackendWritable(ctx, backend, "creating snapshot"); err != nil {
+		return nil, names.statusError(codes.FailedPrecondition, err)
+	}
+
+	if err = d.checkBackendMaintenance(ctx, backend, volName, "creating snapshot"); err != nil {
+		return nil, names.statusError(codes.Unavailable, err)
+	}
+
+	site, _ := getSnapshotSite(req.GetParameters())
+	if site != snapshotSiteLocal && backend.MetroBackend == nil {
+		return nil, names.statusError(codes.FailedPrecondition, fmt.Errorf("backend %s has no hyperMetro "+
+			"remote backend, the VolumeSnapshotClass parameter \"%s\": [%s] is not supported",
+			backendName, snapshotSiteKey, site))
+	}
+
+	release, err := backend.SnapshotLimiter.Acquire(ctx, model.SnapshotOperationCreate)
 	if err != nil {
+		log.AddContext(ctx).Errorf("Wait for creating snapshot %s error: %v", snapshotName, err)
+		return nil, names.statusError(codes.DeadlineExceeded, err)
+	}
+	defer release()
+	defer beginPluginOperation(backend.Plugin)()
+
+	sitePlugin := backend.Plugin
+	if site == snapshotSiteRemote {
+		sitePlugin = backend.MetroBackend.Plugin
+	}
+	if err = checkSnapshotSpace(ctx, sitePlugin, volName, snapshotName); err != nil {
 		log.AddContext(ctx).Errorf("Create snapshot %s error: %v", snapshotName, err)
-		return nil, status.Error(codes.Internal, err.Error())
+		return nil, names.statusError(codes.ResourceExhausted, err)
+	}
+
+	var snapshot map[string]interface{}
+	switch site {
+	case snapshotSiteBoth:
+		snapshot, err = createMetroSnapshot(ctx, backend, volName, snapshotName)
+	case snapshotSiteRemote:
+		snapshot, err = createRemoteSnapshot(ctx, backend, volName, snapshotName)
+	default:
+		snapshot, err = backend.Plugin.CreateSnapshot(ctx, volName, snapshotName)
+	}
+	if err != nil {
+		log.AddContext(ctx).Errorf("Create snapshot %s error: %v", snapshotName, err)
+		dumpRequestRecords(ctx, backend.Plugin)
+		return nil, names.statusError(storageErrorCode(err), err)
 	}
 
 	log.AddContext(ctx).Infof("Finish to Create snapshot %s for volume %s", snapshotName, volumeId)
@@ -361,10 +493,36 @@ func (d *Driver) DeleteSnapshot(ctx context.Context, req *csi.DeleteSnapshotRequ
 		return &csi.DeleteSnapshotResponse{}, nil
 	}
 
-	err = backend.Plugin.DeleteSnapshot(ctx, snapshotParentId, snapshotName)
+	if err = checkBackendWritable(ctx, backend, "deleting snapshot"); err != nil {
+		return nil, newSnapshotResourceNames(snapshotId).statusError(codes.FailedPrecondition, err)
+	}
+
+	if err = d.checkBackendMaintenance(ctx, backend, "", "deleting snapshot"); err != nil {
+		return nil, newSnapshotResourceNames(snapshotId).statusError(codes.Unavailable, err)
+	}
+
+	release, err := backend.SnapshotLimiter.Acquire(ctx, model.SnapshotOperationDelete)
+	if err != nil {
+		log.AddContext(ctx).Errorf("Wait for deleting snapshot %s error: %v", snapshotName, err)
+		return nil, newSnapshotResourceNames(snapshotId).statusError(codes.DeadlineExceeded, err)
+	}
+	defer release()
+	defer beginPluginOperation(backend.Plugin)()
+
+	localParentId, remoteParentId := utils.SplitMetroSnapshotParentId(snapshotParentId)
+	if err = deleteMetroSnapshot(ctx, backend, remoteParentId, snapshotName); err != nil {
+		log.AddContext(ctx).Errorf("Delete remote snapshot %s error: %v", snapshotName, err)
+		return nil, newSnapshotResourceNames(snapshotId).statusError(codes.Internal, err)
+	}
+
+	// the local parent ID is empty if the snapshot is only taken on the remote site
+	if localParentId != "" || remoteParentId == "" {
+		err = backend.Plugin.DeleteSnapshot(ctx, localParentId, snapshotName)
+	}
 	if err != nil {
 		log.AddContext(ctx).Errorf("Delete snapshot %s error: %v", snapshotName, err)
-		return nil, status.Error(codes.Internal, err.Error())
+		dumpRequestRecords(ctx, backend.Plugin)
+		return nil, newSnapshotResourceNames(snapshotId).statusError(codes.Internal, err)
 	}
 
 	log.AddContext(ctx).Infof("Finish to Delete snapshot %s", snapshotId)
@@ -376,8 +534,47 @@ func (d *Driver) ListSnapshots(ctx context.Context, req *csi.ListSnapshotsReques
 	return nil, status.Error(codes.Unimplemented, "")
 }
 
-// ControllerGetVolume is to get volume info, but unimplemented
+// ControllerGetVolume returns the backend and the storage serial number of the volume, which are resolved
+// from the backend cache for the volumes created before the serial number is stamped into the attributes
 func (d *Driver) ControllerGetVolume(ctx context.Context, req *csi.ControllerGetVolumeRequest) (
 	*csi.ControllerGetVolumeResponse, error) {
-	return nil, status.Error(codes.Unimplemented, "")
+	volumeId := req.GetVolumeId()
+	backendName, volName := utils.SplitVolumeId(volumeId)
+	if volName == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "volume id %s is invalid", volumeId)
+	}
+
+	bk, err := d.backendSelector.SelectBackend(ctx, backendName)
+	if err != nil {
+		log.AddContext(ctx).Errorf("Select backend %s of volume %s error: %v", backendName, volumeId, err)
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	if bk == nil {
+		return nil, status.Errorf(codes.NotFound, "backend %s of volume %s does not exist", backendName, volumeId)
+	}
+
+	if checker, ok := bk.Plugin.(plugin.VolumeChecker); ok {
+		exist, err := checker.VolumeExists(ctx, volName)
+		if err != nil {
+			log.AddContext(ctx).Errorf("Check the existence of volume %s error: %v", volumeId, err)
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		if !exist {
+			return nil, status.Errorf(codes.NotFound, "volume %s does not exist on backend %s", volumeId, backendName)
+		}
+	}
+
+	attributes := map[string]string{
+		"backend": backendName,
+		"name":    volName,
+	}
+	addStorageSN(ctx, attributes, bk.Plugin)
+
+	return &csi.ControllerGetVolumeResponse{
+		Volume: &csi.Volume{
+			VolumeId:      volumeId,
+			VolumeContext: attributes,
+		},
+		Status: &csi.ControllerGetVolumeResponse_VolumeStatus{},
+	}, nil
 }
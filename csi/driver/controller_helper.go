@@ -18,25 +18,35 @@ package driver
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/container-storage-interface/spec/lib/go/csi"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	coreV1 "k8s.io/api/core/v1"
 
 	"huawei-csi-driver/cli/helper"
+	xuanwuv1 "huawei-csi-driver/client/apis/xuanwu/v1"
+	"huawei-csi-driver/connector"
+	"huawei-csi-driver/connector/nvme"
 	"huawei-csi-driver/csi/app"
 	"huawei-csi-driver/csi/backend"
 	"huawei-csi-driver/csi/backend/handler"
 	"huawei-csi-driver/csi/backend/model"
 	"huawei-csi-driver/csi/backend/plugin"
+	"huawei-csi-driver/csi/manage"
 	"huawei-csi-driver/pkg/constants"
 	pkgUtils "huawei-csi-driver/pkg/utils"
+	storageClient "huawei-csi-driver/storage/oceanstor/client"
 	"huawei-csi-driver/utils"
+	"huawei-csi-driver/utils/k8sutils"
 	"huawei-csi-driver/utils/log"
 )
 
@@ -53,6 +63,24 @@ const (
 	volumeTypeDTree      = "dtree"
 	volumeTypeFileSystem = "fs"
 	volumeTypeLun        = "lun"
+
+	poolSelectionFailedReason = "PoolSelectionFailed"
+	arrayTaskFailedReason     = "ArrayTaskFailed"
+	backendMaintenanceReason  = "BackendInMaintenance"
+	licenseMissingReason      = "LicenseMissing"
+
+	encryptedKey = "encrypted"
+
+	// storageSNKey is the volume attribute of the serial number of the storage which the volume is on
+	storageSNKey = "storageSN"
+	// storagePoolAttribute is the volume attribute of the storage pool which the volume is created in
+	storagePoolAttribute = "storagePool"
+
+	requireDetachedSourceKey = "requireDetachedSource"
+
+	// spaceSoftQuotaPercentKey is the StorageClass parameter and the volume attribute of the soft quota of the
+	// DTree volume, in percentage of the hard quota
+	spaceSoftQuotaPercentKey = "spaceSoftQuotaPercent"
 )
 
 var (
@@ -68,6 +96,19 @@ var (
 	annManageBackendName = "/manageBackendName"
 	annFileSystemMode    = "/fileSystemMode"
 	annVolumeName        = "/volumeName"
+
+	nfsAllowedClientsKey = "nfsAllowedClients"
+	// nfsAllClients is the nfs share client which allows all clients to access the share
+	nfsAllClients = "*"
+
+	// expandAllocationUnit is the capacity granularity of the storage when expanding a volume,
+	// the storage which is not listed here does not round the capacity
+	expandAllocationUnit = map[string]int64{
+		"oceanstor-san":     plugin.SectorSize,
+		"oceanstor-nas":     plugin.SectorSize,
+		plugin.DTreeStorage: plugin.SectorSize,
+		"fusionstorage-san": plugin.CAPACITY_UNIT,
+	}
 )
 
 func addNFSProtocol(ctx context.Context, mountFlag string, parameters map[string]interface{}) error {
@@ -109,6 +150,241 @@ func processNFSProtocol(ctx context.Context, req *csi.CreateVolumeRequest,
 	return nil
 }
 
+// getExpandCapacity returns the capacity which the volume will have after the storage rounds the required
+// bytes up to its granularity, and the error if the rounded capacity exceeds the limit bytes
+func getExpandCapacity(storage string, requiredBytes, limitBytes int64) (int64, error) {
+	capacity := requiredBytes
+	if unit, exist := expandAllocationUnit[storage]; exist {
+		capacity = utils.RoundUpSize(requiredBytes, unit) * unit
+	}
+
+	if limitBytes > 0 && capacity > limitBytes {
+		return 0, fmt.Errorf("the capacity %d rounded up from requiredBytes %d by the %s granularity "+
+			"exceeds limitBytes %d", capacity, requiredBytes, storage, limitBytes)
+	}
+
+	return capacity, nil
+}
+
+// checkExpandingCapacity queries the current capacity of the volume before expanding it, and returns the
+// capacity the volume will have after the expansion, or the error if it exceeds the limit bytes, e.g. when the
+// volume is already larger than the limit. The failure of querying is ignored and the expected capacity is returned.
+func checkExpandingCapacity(ctx context.Context, bk plugin.Plugin, volName string,
+	expectCapacity, limitBytes int64) (int64, error) {
+	vol, err := bk.QueryVolume(ctx, volName, map[string]interface{}{
+		"description": "Query from Huawei Storage",
+		"size":        int64(0),
+	})
+	if err != nil {
+		log.AddContext(ctx).Warningf("Query volume %s failed, skip the limit check, error: %v", volName, err)
+		return expectCapacity, nil
+	}
+
+	currentCapacity, err := vol.GetSize()
+	if err != nil {
+		log.AddContext(ctx).Warningf("Get size of volume %s failed, skip the limit check, error: %v",
+			volName, err)
+		return expectCapacity, nil
+	}
+
+	if currentCapacity <= expectCapacity {
+		return expectCapacity, nil
+	}
+	if currentCapacity > limitBytes {
+		return 0, fmt.Errorf("the current capacity %d of the volume exceeds limitBytes %d",
+			currentCapacity, limitBytes)
+	}
+	return currentCapacity, nil
+}
+
+// beginPluginOperation registers an in-flight operation on the plugin, so that a plugin replaced by
+// re-initialization isn't logged out during the operation, and returns the function which completes it
+func beginPluginOperation(bk plugin.Plugin) func() {
+	tracker, ok := bk.(plugin.OperationTracker)
+	if !ok {
+		return func() {}
+	}
+	return tracker.BeginOperation()
+}
+
+// dumpRequestRecords dumps the recorded exchanges with the storage of the failed operation, so that they can be
+// found by the request ID of the error log
+func dumpRequestRecords(ctx context.Context, bk plugin.Plugin) {
+	dumper, ok := bk.(plugin.RequestRecordDumper)
+	if !ok {
+		return
+	}
+
+	path, err := dumper.DumpRequestRecords(ctx)
+	if err != nil {
+		log.AddContext(ctx).Warningf("Dump the request records error: %v", err)
+		return
+	}
+	if path != "" {
+		log.AddContext(ctx).Errorf("The request records of the failed operation are dumped to %s", path)
+	}
+}
+
+// checkSnapshotSpace returns the error if the snapshot space of the volume is exhausted, the failure of
+// the pre-check itself is ignored and left to the storage. The existing snapshot passes the check, so that
+// the retries of creating it stay idempotent.
+func checkSnapshotSpace(ctx context.Context, bk plugin.Plugin, volName, snapshotName string) error {
+	checker, ok := bk.(plugin.SnapshotSpaceChecker)
+	if !ok || app.GetGlobalConfig().SkipSnapshotSpaceCheck {
+		return nil
+	}
+
+	err := checker.CheckSnapshotSpace(ctx, volName, snapshotName)
+	if errors.Is(err, plugin.ErrSnapshotSpaceExhausted) {
+		return err
+	}
+
+	if err != nil {
+		log.AddContext(ctx).Warningf("Check snapshot space of volume %s failed, error: %v", volName, err)
+	}
+	return nil
+}
+
+// checkQosBudget returns the error if the storage is not able to create the qos policy of the volume,
+// the failure of the pre-check itself is ignored and left to the storage. The existing volume passes the check,
+// so that the retries of creating it stay idempotent.
+func checkQosBudget(ctx context.Context, bk plugin.Plugin, volName string, parameters map[string]interface{}) error {
+	checker, ok := bk.(plugin.QosBudgetChecker)
+	if qos, _ := parameters["qos"].(string); !ok || qos == "" {
+		return nil
+	}
+
+	if inspector, ok := bk.(plugin.VolumeInspector); ok {
+		state, err := inspector.InspectVolume(ctx, volName)
+		if err != nil {
+			log.AddContext(ctx).Warningf("Inspect volume %s failed, error: %v", volName, err)
+		} else if state != nil {
+			return nil
+		}
+	}
+
+	err := checker.CheckQosBudget(ctx)
+	if errors.Is(err, plugin.ErrQosBudgetExhausted) {
+		return err
+	}
+
+	if err != nil {
+		log.AddContext(ctx).Warningf("Check qos budget of the storage failed, error: %v", err)
+	}
+	return nil
+}
+
+// checkBackendWritable returns the error if the backend is read-only, which rejects the operations modifying
+// its volumes and snapshots
+func checkBackendWritable(ctx context.Context, bk *model.Backend, operation string) error {
+	if !bk.ReadOnly {
+		return nil
+	}
+
+	err := fmt.Errorf("backend %s is read-only, %s is not allowed", bk.Name, operation)
+	log.AddContext(ctx).Errorln(err)
+	return err
+}
+
+// checkBackendMaintenance returns the error if the operations modifying the volumes of the backend are paused
+// by the maintenance of the storage, the sidecars retry the rejected operation until the maintenance ends.
+// The event is recorded on the PVC of the volume if the volume name is given.
+func (d *Driver) checkBackendMaintenance(ctx context.Context, bk *model.Backend, volumeName, operation string) error {
+	if !bk.IsPausedByMaintenance() {
+		return nil
+	}
+
+	_, since := bk.Maintenance.InMaintenance()
+	err := fmt.Errorf("the storage of backend %s is in maintenance since %s, %s is paused until the maintenance "+
+		"ends", bk.Name, since.Format(time.RFC3339), operation)
+	log.AddContext(ctx).Warningln(err)
+	if volumeName != "" {
+		d.recordPVCEvent(ctx, volumeName, coreV1.EventTypeWarning, backendMaintenanceReason, err.Error())
+	}
+	return err
+}
+
+// checkBackendMetroPeer returns the error if the hyperMetro volume is requested on the backend whose metro peer
+// is not configured
+func checkBackendMetroPeer(ctx context.Context, bk *model.Backend, parameters map[string]interface{}) error {
+	hyperMetro, _ := parameters["hyperMetro"].(string)
+	if hyperMetro == "" || !utils.StrToBool(ctx, hyperMetro) || bk.MetroBackend != nil {
+		return nil
+	}
+
+	err := fmt.Errorf("backend %s has no hyperMetro peer configured, set metroBackend of the backend "+
+		"of StorageBackendClaim %s to create hyperMetro volumes on it", bk.Name,
+		pkgUtils.MakeMetaWithNamespace(app.GetGlobalConfig().Namespace, bk.Name))
+	log.AddContext(ctx).Errorln(err)
+	return err
+}
+
+// checkRequestedBackend rejects creating the volume on the backend requested by the StorageClass if the backend
+// is read-only or is not able to provide the requested features, the backends of these kinds are never selected
+// for the volumes without a requested backend
+func (d *Driver) checkRequestedBackend(ctx context.Context, req *csi.CreateVolumeRequest,
+	parameters map[string]interface{}) error {
+	backendName, _ := parameters["backend"].(string)
+	if backendName == "" {
+		return nil
+	}
+
+	bk, err := d.backendSelector.SelectBackend(ctx, helper.GetBackendName(backendName))
+	if bk == nil || err != nil {
+		return checkBackendExists(ctx, helper.GetBackendName(backendName))
+	}
+
+	names := resourceNames{backend: bk.Name, volume: req.GetName()}
+	if err = checkBackendWritable(ctx, bk, "creating volume"); err != nil {
+		return names.statusError(codes.FailedPrecondition, err)
+	}
+
+	if err = d.checkBackendMaintenance(ctx, bk, req.GetName(), "creating volume"); err != nil {
+		return names.statusError(codes.Unavailable, err)
+	}
+
+	if err = checkBackendMetroPeer(ctx, bk, parameters); err != nil {
+		return names.statusError(codes.FailedPrecondition, err)
+	}
+	return nil
+}
+
+// checkBackendExists returns a FailedPrecondition error when the backend requested by the StorageClass
+// is not configured at all, so that a misconfigured StorageClass is reported clearly.
+// The other failures of loading the backend are left to the pool selection.
+func checkBackendExists(ctx context.Context, backendName string) error {
+	claims, err := pkgUtils.ListClaim(ctx, app.GetGlobalConfig().BackendUtils, app.GetGlobalConfig().Namespace)
+	if err != nil || claims == nil {
+		log.AddContext(ctx).Warningf("List backends failed, skip the check of backend %s, error: %v",
+			backendName, err)
+		return nil
+	}
+
+	available, exists := findBackend(backendName, claims.Items)
+	if exists {
+		return nil
+	}
+
+	msg := fmt.Sprintf("backend %s specified in the StorageClass does not exist, available backends: [%s]",
+		backendName, strings.Join(available, ", "))
+	log.AddContext(ctx).Errorln(msg)
+	return status.Error(codes.FailedPrecondition, msg)
+}
+
+// findBackend returns the sorted names of the configured backends and whether the named backend is one of them
+func findBackend(backendName string, claims []xuanwuv1.StorageBackendClaim) ([]string, bool) {
+	var available []string
+	for _, claim := range claims {
+		if claim.Name == backendName {
+			return nil, true
+		}
+		available = append(available, claim.Name)
+	}
+
+	sort.Strings(available)
+	return available, false
+}
+
 func isSupportExpandVolume(ctx context.Context, req *csi.ControllerExpandVolumeRequest, b *model.Backend) (
 	bool, error) {
 	if b.Storage == "fusionstorage-nas" || b.Storage == "oceanstor-nas" || b.Storage == "oceanstor-dtree" {
@@ -221,9 +497,18 @@ func processVolumeContentSource(ctx context.Context, req *csi.CreateVolumeReques
 
 	if contentSnapshot := contentSource.GetSnapshot(); contentSnapshot != nil {
 		sourceSnapshotId := contentSnapshot.GetSnapshotId()
+		if err := checkCrossNamespaceSnapshot(ctx, req.GetName(), sourceSnapshotId); err != nil {
+			return err
+		}
+
 		sourceBackendName, snapshotParentId, sourceSnapshotName := utils.SplitSnapshotId(sourceSnapshotId)
+		// the site of the snapshot taken on the hyperMetro sites is selected after the parameters are processed
+		snapshotParentId, remoteSnapshotParentId := utils.SplitMetroSnapshotParentId(snapshotParentId)
 		parameters["sourceSnapshotName"] = sourceSnapshotName
 		parameters["snapshotParentId"] = snapshotParentId
+		if remoteSnapshotParentId != "" {
+			parameters[remoteSnapshotParentIdKey] = remoteSnapshotParentId
+		}
 		parameters["backend"] = sourceBackendName
 		log.AddContext(ctx).Infof("Start to create volume from snapshot %s, param: %+v",
 			sourceSnapshotName, parameters)
@@ -241,17 +526,72 @@ func processVolumeContentSource(ctx context.Context, req *csi.CreateVolumeReques
 	return nil
 }
 
+// checkCrossNamespaceSnapshot checks whether the PVC is allowed to use the VolumeSnapshot of another namespace
+// as the data source. The namespace is specified by the data source ref of the PVC since Kubernetes 1.26,
+// and it must be granted by a ReferenceGrant in the namespace of the VolumeSnapshot.
+func checkCrossNamespaceSnapshot(ctx context.Context, pvName, snapshotId string) error {
+	k8sUtils := app.GetGlobalConfig().K8sUtils
+	pvcNamespace, dataSourceRef, err := k8sUtils.GetVolumeClaimDataSourceRef(ctx, pvName)
+	if err != nil {
+		log.AddContext(ctx).Errorf("Get data source ref of volume %s failed, error: %v", pvName, err)
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	if dataSourceRef == nil || dataSourceRef.Namespace == nil || *dataSourceRef.Namespace == "" ||
+		*dataSourceRef.Namespace == pvcNamespace || dataSourceRef.Kind != k8sutils.VolumeSnapshotKind {
+		return nil
+	}
+
+	snapshotNamespace, snapshotName := *dataSourceRef.Namespace, dataSourceRef.Name
+	snapshotHandle, err := k8sUtils.GetVolumeSnapshotHandle(ctx, snapshotNamespace, snapshotName)
+	if err != nil {
+		log.AddContext(ctx).Errorf("Get VolumeSnapshot %s/%s failed, error: %v", snapshotNamespace, snapshotName, err)
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	if snapshotHandle != snapshotId {
+		msg := fmt.Sprintf("VolumeSnapshot %s/%s is not bound to snapshot %s", snapshotNamespace, snapshotName,
+			snapshotId)
+		log.AddContext(ctx).Errorln(msg)
+		return status.Error(codes.InvalidArgument, msg)
+	}
+
+	granted, err := k8sUtils.IsVolumeSnapshotReferenceGranted(ctx, pvcNamespace, snapshotNamespace, snapshotName)
+	if err != nil {
+		log.AddContext(ctx).Errorf("Check ReferenceGrant of VolumeSnapshot %s/%s failed, error: %v",
+			snapshotNamespace, snapshotName, err)
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	if !granted {
+		msg := fmt.Sprintf("no ReferenceGrant in namespace %s allows the PVCs of namespace %s to use "+
+			"VolumeSnapshot %s as the data source", snapshotNamespace, pvcNamespace, snapshotName)
+		log.AddContext(ctx).Errorln(msg)
+		return status.Error(codes.PermissionDenied, msg)
+	}
+
+	log.AddContext(ctx).Infof("Volume %s of namespace %s is created from VolumeSnapshot %s/%s",
+		pvName, pvcNamespace, snapshotNamespace, snapshotName)
+	return nil
+}
+
 func getAccessibleTopologies(ctx context.Context, req *csi.CreateVolumeRequest,
 	pool *model.StoragePool) []*csi.Topology {
 	accessibleTopologies := make([]*csi.Topology, 0)
+	supportedTopology := handler.NewCacheWrapper().LoadCacheBackendTopologies(ctx, pool.Parent)
 	if req.GetAccessibilityRequirements() != nil &&
 		len(req.GetAccessibilityRequirements().GetRequisite()) != 0 {
-		supportedTopology := handler.NewCacheWrapper().LoadCacheBackendTopologies(ctx, pool.Parent)
 		if len(supportedTopology) > 0 {
 			for _, segment := range supportedTopology {
 				accessibleTopologies = append(accessibleTopologies, &csi.Topology{Segments: segment})
 			}
 		}
+		return accessibleTopologies
+	}
+
+	// without the topology requirements, the volume still requires the backend label of the nodes
+	if segment := getBackendTopologySegment(supportedTopology); segment != nil {
+		accessibleTopologies = append(accessibleTopologies, &csi.Topology{Segments: segment})
 	}
 	return accessibleTopologies
 }
@@ -267,9 +607,64 @@ func getAttributes(req *csi.CreateVolumeRequest, vol utils.Volume, backendName s
 	if lunWWN, err := vol.GetLunWWN(); err == nil {
 		attributes["lunWWN"] = lunWWN
 	}
+
+	if mountOptions, ok := req.Parameters[manage.MountOptionsKey]; ok {
+		attributes[manage.MountOptionsKey] = mountOptions
+	}
+
+	if _, requested := req.Parameters[encryptedKey]; requested || vol.IsEncrypted() {
+		attributes[encryptedKey] = strconv.FormatBool(vol.IsEncrypted())
+	}
+
+	if percent, ok := req.Parameters[spaceSoftQuotaPercentKey]; ok {
+		attributes[spaceSoftQuotaPercentKey] = percent
+	}
+
+	if allowedClients, ok := req.Parameters[nfsAllowedClientsKey]; ok {
+		attributes[nfsAllowedClientsKey] = allowedClients
+	}
+
+	if isSnapshotOnCreate(req.Parameters) {
+		attributes[snapshotOnCreateKey] = "true"
+	}
+
+	if sharePath := vol.GetSharePath(); sharePath != "" {
+		attributes[manage.SharePathKey] = sharePath
+	}
+
+	for _, key := range []string{connector.ScanVolumeTimeoutKey, connector.DeviceCleanupTimeoutKey} {
+		if timeout, ok := req.Parameters[key]; ok {
+			attributes[key] = timeout
+		}
+	}
+
+	if localOwner, remoteOwner := vol.GetOwningControllers(); localOwner != "" || remoteOwner != "" {
+		attributes["localOwner"] = localOwner
+		attributes["remoteOwner"] = remoteOwner
+	}
 	return attributes
 }
 
+// addStorageSN stamps the serial number of the storage into the volume attributes, the serial number is
+// cached by the backend so that no request is sent to the storage. The serial number is informative only,
+// so the failure of getting it is logged and never fails the request
+func addStorageSN(ctx context.Context, attributes map[string]string, bk plugin.Plugin) {
+	provider, ok := bk.(plugin.InventoryProvider)
+	if !ok {
+		return
+	}
+
+	inventory, err := provider.GetStorageInventory()
+	if err != nil {
+		log.AddContext(ctx).Warningf("Get storage inventory failed, skip the serial number, error: %v", err)
+		return
+	}
+
+	if inventory.SerialNumber != "" {
+		attributes[storageSNKey] = inventory.SerialNumber
+	}
+}
+
 func getVolumeResponse(accessibleTopologies []*csi.Topology,
 	attributes map[string]string,
 	volumeId string, size int64) *csi.Volume {
@@ -288,6 +683,9 @@ func makeCreateVolumeResponse(ctx context.Context, req *csi.CreateVolumeRequest,
 
 	accessibleTopologies := getAccessibleTopologies(ctx, req, pool)
 	attributes := getAttributes(req, vol, pool.Parent)
+	addStorageSN(ctx, attributes, pool.Plugin)
+	attributes[storagePoolAttribute] = pool.Name
+	addPlacementAttributes(attributes, req.GetParameters())
 	csiVolume := getVolumeResponse(accessibleTopologies, attributes, pool.Parent+"."+vol.GetVolumeName(), size)
 	if contentSource != nil {
 		csiVolume.ContentSource = contentSource
@@ -309,6 +707,57 @@ func checkStorageClassParameters(ctx context.Context, parameters map[string]inte
 		return err
 	}
 
+	// check mountOptions parameter in sc
+	err = checkMountOptions(ctx, parameters)
+	if err != nil {
+		return err
+	}
+
+	// check encrypted parameter in sc
+	err = checkEncrypted(ctx, parameters)
+	if err != nil {
+		return err
+	}
+
+	// check spaceSoftQuotaPercent parameter in sc
+	err = checkSpaceSoftQuotaPercent(ctx, parameters)
+	if err != nil {
+		return err
+	}
+
+	// check scanVolumeTimeout and deviceCleanupTimeout parameters in sc
+	err = checkConnectorTimeouts(ctx, parameters)
+	if err != nil {
+		return err
+	}
+
+	// check snapshotOnCreate parameter in sc
+	err = checkSnapshotOnCreate(ctx, parameters)
+	if err != nil {
+		return err
+	}
+
+	// check poolTags parameter in sc
+	err = checkPoolTags(ctx, parameters)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func checkPoolTags(ctx context.Context, parameters map[string]interface{}) error {
+	poolTags, exist := parameters["poolTags"].(string)
+	if !exist {
+		return nil
+	}
+
+	if _, err := storageClient.ParsePoolTags(poolTags); err != nil {
+		errMsg := fmt.Sprintf("StorageClass parameter \"poolTags\": [%s] invalid, %v.", poolTags, err)
+		log.AddContext(ctx).Errorln(errMsg)
+		return errors.New(errMsg)
+	}
+
 	return nil
 }
 
@@ -329,11 +778,44 @@ func checkFsPermission(ctx context.Context, parameters map[string]interface{}) e
 	return nil
 }
 
+func checkEncrypted(ctx context.Context, parameters map[string]interface{}) error {
+	encrypted, exist := parameters[encryptedKey].(string)
+	if !exist {
+		return nil
+	}
+
+	if _, err := strconv.ParseBool(encrypted); err != nil {
+		errMsg := fmt.Sprintf("StorageClass parameter \"%s\": [%s] invalid, it must be true or false.",
+			encryptedKey, encrypted)
+		log.AddContext(ctx).Errorln(errMsg)
+		return errors.New(errMsg)
+	}
+
+	return nil
+}
+
+func checkMountOptions(ctx context.Context, parameters map[string]interface{}) error {
+	mountOptions, exist := parameters[manage.MountOptionsKey].(string)
+	// the mountOptions of the block volumes are passed to their local filesystems without the NAS allow-list
+	if !exist || parameters["volumeType"] == volumeTypeLun {
+		return nil
+	}
+
+	if _, err := manage.ParseMountOptions(mountOptions); err != nil {
+		errMsg := fmt.Sprintf("StorageClass parameter \"%s\": [%s] invalid, %v.",
+			manage.MountOptionsKey, mountOptions, err)
+		log.AddContext(ctx).Errorln(errMsg)
+		return errors.New(errMsg)
+	}
+
+	return nil
+}
+
 func processDescription(ctx context.Context, parameters map[string]interface{}) error {
 	description, exist := parameters["description"].(string)
 	if !exist {
 		// Set description default value
-		parameters["description"] = "Created from Kubernetes CSI"
+		parameters["description"] = plugin.DefaultDescription
 		return nil
 	}
 
@@ -371,6 +853,157 @@ func checkReservedSnapshotSpaceRatio(ctx context.Context, parameters map[string]
 	return nil
 }
 
+func checkSpaceSoftQuotaPercent(ctx context.Context, parameters map[string]interface{}) error {
+	percentString, exist := parameters[spaceSoftQuotaPercentKey].(string)
+	if !exist {
+		return nil
+	}
+
+	// the soft quota must not exceed the hard quota
+	percent, err := strconv.Atoi(percentString)
+	if err != nil || percent < 1 || percent > 100 {
+		errMsg := fmt.Sprintf("spaceSoftQuotaPercent: [%s] must be an integer in range [1, 100], please check "+
+			"this parameter in storageclass.", percentString)
+		log.AddContext(ctx).Errorln(errMsg)
+		return errors.New(errMsg)
+	}
+
+	return nil
+}
+
+func checkConnectorTimeouts(ctx context.Context, parameters map[string]interface{}) error {
+	maxTimeouts := map[string]int{
+		connector.ScanVolumeTimeoutKey:    connector.MaxScanVolumeTimeout,
+		connector.DeviceCleanupTimeoutKey: connector.MaxDeviceCleanupTimeout,
+	}
+	for key, max := range maxTimeouts {
+		value, exist := parameters[key].(string)
+		if !exist {
+			continue
+		}
+
+		if _, err := connector.ParseTimeoutOverride(key, value, max); err != nil {
+			errMsg := fmt.Sprintf("%v, please check this parameter in storageclass.", err)
+			log.AddContext(ctx).Errorln(errMsg)
+			return errors.New(errMsg)
+		}
+	}
+
+	return nil
+}
+
+// getSpaceSoftQuotaPercent returns the soft quota percentage of the DTree volume recorded in the attributes of its PV,
+// empty is returned if the volume is created without the soft quota or the PV can't be got
+func (d *Driver) getSpaceSoftQuotaPercent(ctx context.Context, volumeId string) string {
+	if d.k8sUtils == nil {
+		return ""
+	}
+
+	pv, err := d.k8sUtils.GetPVByVolumeHandle(ctx, d.name, volumeId)
+	if err != nil || pv == nil || pv.Spec.CSI == nil {
+		log.AddContext(ctx).Warningf("Get pv of volume %s failed, the soft quota is not changed, error: %v",
+			volumeId, err)
+		return ""
+	}
+
+	return pv.Spec.CSI.VolumeAttributes[spaceSoftQuotaPercentKey]
+}
+
+// hasNfsClientACL checks whether the nfs share clients of the volume are restricted by the PVC annotation, which is
+// recorded in the volume attributes. The clients are assumed to be restricted if the attributes can't be got.
+func (d *Driver) hasNfsClientACL(ctx context.Context, pvName string) bool {
+	if d.k8sUtils == nil {
+		return false
+	}
+
+	attributes, err := d.k8sUtils.GetVolumeAttributes(ctx, pvName)
+	if err != nil {
+		log.AddContext(ctx).Warningf("Get volume attributes of %s failed, error: %v", pvName, err)
+		return true
+	}
+
+	_, exist := attributes[nfsAllowedClientsKey]
+	return exist
+}
+
+// checkSnapshotParameters rejects the VolumeSnapshotClass parameters which can't be honored. The snapshots of
+// the autoDeleteAfterDays are deleted by the snapshot ttl controller of the storage-backend-sidecar.
+func checkSnapshotParameters(ctx context.Context, parameters map[string]string) error {
+	if err := checkAutoDeleteAfterDays(parameters); err != nil {
+		log.AddContext(ctx).Errorln(err)
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if _, err := isDetachedSourceRequired(parameters); err != nil {
+		log.AddContext(ctx).Errorln(err)
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if _, err := getSnapshotSite(parameters); err != nil {
+		log.AddContext(ctx).Errorln(err)
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	return nil
+}
+
+// checkAutoDeleteAfterDays checks that the autoDeleteAfterDays of the snapshots is a positive number of days
+func checkAutoDeleteAfterDays(parameters map[string]string) error {
+	value, exist := parameters[constants.SnapshotAutoDeleteAfterDaysParameter]
+	if !exist {
+		return nil
+	}
+
+	days, err := strconv.Atoi(value)
+	if err != nil || days <= 0 {
+		return fmt.Errorf("VolumeSnapshotClass parameter \"%s\": [%s] invalid, it must be a positive integer",
+			constants.SnapshotAutoDeleteAfterDaysParameter, value)
+	}
+
+	return nil
+}
+
+// isDetachedSourceRequired returns whether the snapshot requires the source volume to be detached, so that the
+// snapshot is taken while the volume is quiesced. By default, the snapshot of an attached volume is crash-consistent.
+func isDetachedSourceRequired(parameters map[string]string) (bool, error) {
+	value, exist := parameters[requireDetachedSourceKey]
+	if !exist {
+		return false, nil
+	}
+
+	required, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("VolumeSnapshotClass parameter \"%s\": [%s] must be true or false",
+			requireDetachedSourceKey, value)
+	}
+
+	return required, nil
+}
+
+// checkSnapshotSource returns the error if the snapshot requires the source volume to be detached
+// but the volume is still attached to any node
+func (d *Driver) checkSnapshotSource(ctx context.Context, volumeId string, parameters map[string]string) error {
+	if required, _ := isDetachedSourceRequired(parameters); !required {
+		return nil
+	}
+
+	nodes, err := d.k8sUtils.GetVolumeAttachedNodes(ctx, d.name, volumeId)
+	if err != nil {
+		log.AddContext(ctx).Errorf("Get attached nodes of volume %s error: %v", volumeId, err)
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	if len(nodes) != 0 {
+		msg := fmt.Sprintf("volume %s is attached to nodes %v, but the VolumeSnapshotClass requires the source "+
+			"volume to be detached, please detach it or set \"%s\" to false for crash-consistent snapshots",
+			volumeId, nodes, requireDetachedSourceKey)
+		log.AddContext(ctx).Errorln(msg)
+		return status.Error(codes.FailedPrecondition, msg)
+	}
+
+	return nil
+}
+
 func checkCreateVolumeRequest(ctx context.Context, req *csi.CreateVolumeRequest) error {
 	capacityRange := req.GetCapacityRange()
 	if capacityRange == nil || capacityRange.RequiredBytes <= 0 {
@@ -391,6 +1024,18 @@ func checkCreateVolumeRequest(ctx context.Context, req *csi.CreateVolumeRequest)
 		return status.Error(codes.InvalidArgument, msg)
 	}
 
+	err = checkSizeBounds(ctx, req.GetParameters(), "requested capacity", capacityRange.RequiredBytes)
+	if err != nil {
+		return err
+	}
+
+	if req.GetVolumeContentSource().GetVolume() != nil {
+		if err = checkCapabilityEnabled(csi.ControllerServiceCapability_RPC_CLONE_VOLUME); err != nil {
+			log.AddContext(ctx).Errorf("Clone volume %s error: %v", req.GetName(), err)
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -445,30 +1090,240 @@ func processCreateVolumeParametersAfterSelect(parameters map[string]interface{},
 	parameters["accountName"] = backend.GetAccountName(localPool.Parent)
 }
 
+// applyBackendDefaultParameters merges the default parameters of the selected backend into the StorageClass
+// parameters, the StorageClass wins on conflict. The values which are derived from the request, such as the
+// nfsProtocol of the mount options, win as well. The merged parameters are checked and processed as the
+// StorageClass ones.
+func applyBackendDefaultParameters(ctx context.Context, req *csi.CreateVolumeRequest,
+	parameters map[string]interface{}, backendName string) error {
+	defaults := backend.GetDefaultParameters(backendName)
+	if len(defaults) != 0 {
+		merged := make(map[string]string, len(defaults)+len(req.GetParameters()))
+		for key, value := range req.GetParameters() {
+			merged[key] = value
+		}
+		for key, value := range defaults {
+			if _, exist := merged[key]; exist {
+				continue
+			}
+
+			// the description is the only derived value which is a placeholder of the default
+			if _, derived := parameters[key]; derived && key != "description" {
+				continue
+			}
+
+			merged[key] = value
+			parameters[key] = value
+		}
+
+		// the volume context is built from the request parameters
+		req.Parameters = merged
+		if err := checkCreateVolumeRequest(ctx, req); err != nil {
+			log.AddContext(ctx).Errorf("Check default parameters %v of backend %s error: %v",
+				defaults, backendName, err)
+			return err
+		}
+
+		if err := checkDefaultNFSProtocol(ctx, parameters); err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+
+		if err := processDescription(ctx, parameters); err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+
+	log.AddContext(ctx).Debugf("Effective parameters of volume %s: %v", req.GetName(), parameters)
+	return nil
+}
+
+// checkDefaultNFSProtocol checks the nfsProtocol of the backend defaults, which must be one of the protocols
+// the nfsvers mount options are mapped to
+func checkDefaultNFSProtocol(ctx context.Context, parameters map[string]interface{}) error {
+	protocol, exist := parameters["nfsProtocol"].(string)
+	if !exist {
+		return nil
+	}
+
+	for _, supported := range nfsProtocolMap {
+		if protocol == supported {
+			return nil
+		}
+	}
+
+	return utils.Errorf(ctx, "unsupported nfs protocol [%s] of the backend default parameters.", protocol)
+}
+
+// applyPriorityClassQoS sets the qos of the volume by the PriorityClass of the pod which uses the PVC, if the
+// backend maps the PriorityClass to a qos. The qos of the StorageClass wins, and nothing is applied if no pod
+// uses the PVC at provisioning.
+func (d *Driver) applyPriorityClassQoS(ctx context.Context, volumeName string, parameters map[string]interface{},
+	backendName string) {
+	if qos, _ := parameters["qos"].(string); qos != "" || d.k8sUtils == nil {
+		return
+	}
+
+	mapping := backend.GetPriorityClassQoS(backendName)
+	if len(mapping) == 0 {
+		return
+	}
+
+	pod, err := d.k8sUtils.GetPodByPVCName(ctx, volumeName)
+	if err != nil {
+		log.AddContext(ctx).Warningf("Get pod of volume %s failed, skip the PriorityClass qos, error: %v",
+			volumeName, err)
+		return
+	}
+	if pod == nil || pod.Spec.PriorityClassName == "" {
+		return
+	}
+
+	qos, exist := mapping[pod.Spec.PriorityClassName]
+	if !exist {
+		return
+	}
+
+	parameters["qos"] = qos
+	log.AddContext(ctx).Infof("Apply qos %s of PriorityClass %s of pod %s/%s to volume %s", qos,
+		pod.Spec.PriorityClassName, pod.Namespace, pod.Name, volumeName)
+}
+
+// recordPoolSelectionFailure attaches the pool selection failure to the PVC events,
+// the failure of recording is only logged since it must not hide the real error
+func (d *Driver) recordPoolSelectionFailure(ctx context.Context, volumeName string, selectErr error) {
+	d.recordPVCEvent(ctx, volumeName, coreV1.EventTypeWarning, poolSelectionFailedReason, selectErr.Error())
+}
+
+// recordArrayTaskFailure attaches the failed asynchronous task of the storage to the PVC events,
+// so that the task ID can be found without the driver logs
+func (d *Driver) recordArrayTaskFailure(ctx context.Context, volumeName string, err error) {
+	var taskErr *utils.ArrayTaskError
+	if errors.As(err, &taskErr) {
+		d.recordPVCEvent(ctx, volumeName, coreV1.EventTypeWarning, arrayTaskFailedReason, taskErr.Error())
+	}
+}
+
+func (d *Driver) recordPVCEvent(ctx context.Context, volumeName, eventType, reason, message string) {
+	if d.k8sUtils == nil {
+		return
+	}
+
+	err := d.k8sUtils.RecordPVCEvent(ctx, volumeName, eventType, reason, message)
+	if err != nil {
+		log.AddContext(ctx).Warningf("Record %s event of volume %s failed, error: %v", reason, volumeName, err)
+	}
+}
+
+// warnMissingLicenses records a warning event on the PVC if the licenses required by the volume are not present
+// on the storage, the volume is still created
+func (d *Driver) warnMissingLicenses(ctx context.Context, volumeName string, pool *model.StoragePool,
+	parameters map[string]interface{}) {
+	checker, ok := pool.Plugin.(plugin.LicenseChecker)
+	if !ok {
+		return
+	}
+
+	missing, err := checker.GetMissingLicenses(ctx, parameters)
+	if err != nil {
+		log.AddContext(ctx).Warningf("Get license features of backend %s error: %v, the licenses of volume %s "+
+			"are not checked", pool.Parent, err, volumeName)
+		return
+	}
+	if len(missing) == 0 {
+		return
+	}
+
+	msg := fmt.Sprintf("The licenses %v required by volume %s are not present on the storage of backend %s, "+
+		"the volume may not work as requested", missing, volumeName, pool.Parent)
+	log.AddContext(ctx).Warningln(msg)
+	d.recordPVCEvent(ctx, volumeName, coreV1.EventTypeWarning, licenseMissingReason, msg)
+}
+
 // createVolume used to create a lun/filesystem in huawei storage
 func (d *Driver) createVolume(ctx context.Context, req *csi.CreateVolumeRequest) (*csi.CreateVolumeResponse, error) {
+	if err := d.applyNamespaceDefaultParameters(ctx, req); err != nil {
+		return nil, err
+	}
+
 	parameters, err := processCreateVolumeParameters(ctx, req)
 	if err != nil {
 		return nil, err
 	}
-	storagePoolPair, err := d.backendSelector.SelectPoolPair(ctx, req.GetCapacityRange().RequiredBytes, parameters)
+
+	if err = d.selectSnapshotSite(ctx, parameters); err != nil {
+		return nil, err
+	}
+
+	if err = d.checkRequestedBackend(ctx, req, parameters); err != nil {
+		return nil, err
+	}
+
+	storagePoolPair, err := d.selectPoolPair(ctx, req, parameters)
 	if err != nil {
 		log.AddContext(ctx).Errorf("Cannot select pool for volume creation: %v", err)
+		d.recordPoolSelectionFailure(ctx, req.GetName(), err)
+		if errors.Is(err, errPoolExpanding) {
+			return nil, status.Error(codes.Unavailable, err.Error())
+		}
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
 	processCreateVolumeParametersAfterSelect(parameters, storagePoolPair.Local, storagePoolPair.Remote)
+	err = applyBackendDefaultParameters(ctx, req, parameters, storagePoolPair.Local.Parent)
+	if err != nil {
+		return nil, err
+	}
+	spreadLunOwner(ctx, parameters, storagePoolPair.Local)
+
+	d.applyPriorityClassQoS(ctx, req.GetName(), parameters, storagePoolPair.Local.Parent)
+
+	clientACL, allowedClients, err := getNfsAllowedClientACL(storagePoolPair.Local.Plugin, parameters)
+	if err != nil {
+		log.AddContext(ctx).Errorf("Check nfs allowed clients of volume %s error: %v", req.GetName(), err)
+		return nil, err
+	}
 
+	names := resourceNames{
+		backend: storagePoolPair.Local.Parent,
+		pool:    storagePoolPair.Local.Name,
+		volume:  req.GetName(),
+	}
+	if err = checkQosBudget(ctx, storagePoolPair.Local.Plugin, req.GetName(), parameters); err != nil {
+		log.AddContext(ctx).Errorf("Create volume %s error: %v", req.GetName(), err)
+		return nil, names.statusError(codes.ResourceExhausted, err)
+	}
+
+	d.warnMissingLicenses(ctx, req.GetName(), storagePoolPair.Local, parameters)
+
+	defer beginPluginOperation(storagePoolPair.Local.Plugin)()
 	vol, err := storagePoolPair.Local.Plugin.CreateVolume(ctx, req.GetName(), parameters)
 	if err != nil {
 		log.AddContext(ctx).Errorf("Create volume %s error: %v", req.GetName(), err)
-		return nil, status.Error(codes.Internal, err.Error())
+		dumpRequestRecords(ctx, storagePoolPair.Local.Plugin)
+		d.recordArrayTaskFailure(ctx, req.GetName(), err)
+		if errors.Is(err, context.Canceled) {
+			return nil, names.statusError(codes.Canceled, err)
+		}
+		return nil, names.statusError(codes.Internal, err)
+	}
+
+	if clientACL != nil {
+		err = clientACL.UpdateNFSShareClientACL(ctx, vol.GetVolumeName(), allowedClients)
+		if err != nil {
+			log.AddContext(ctx).Errorf("Update nfs share client acl of volume %s error: %v", req.GetName(), err)
+			return nil, names.statusError(codes.Internal, err)
+		}
 	}
 
 	log.AddContext(ctx).Infof("Volume %s is created", req.GetName())
 	res := &csi.CreateVolumeResponse{
 		Volume: makeCreateVolumeResponse(ctx, req, vol, storagePoolPair.Local),
 	}
+	recordPlacement(res.GetVolume().GetVolumeId(), res.GetVolume().GetVolumeContext())
+
+	if isSnapshotOnCreate(req.GetParameters()) {
+		d.createInitialSnapshot(ctx, req.GetName(), res.GetVolume().GetVolumeId(), storagePoolPair.Local)
+	}
 
 	// The topology creation result does not affect current task.
 	go pkgUtils.CreatePVLabel(req.GetName(), res.GetVolume().GetVolumeId())
@@ -511,14 +1366,33 @@ func (d *Driver) manageVolume(ctx context.Context, req *csi.CreateVolumeRequest,
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
+	actualCapacity, err := vol.GetSize()
+	if err != nil {
+		log.AddContext(ctx).Errorf("Get capacity of volume %s error: %v", volumeName, err)
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	err = checkSizeBounds(ctx, req.GetParameters(), fmt.Sprintf("capacity of the managed volume %s", volumeName),
+		actualCapacity)
+	if err != nil {
+		return nil, err
+	}
+
 	err = validateCapacity(ctx, req, vol)
 	if err != nil {
 		log.AddContext(ctx).Errorf("Validate capacity %s error: %v", req.GetName(), err)
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
+	err = validateEncryption(ctx, req, vol)
+	if err != nil {
+		log.AddContext(ctx).Errorf("Validate encryption %s error: %v", req.GetName(), err)
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+
 	accessibleTopologies := getAccessibleTopologies(ctx, req, selectBackend.Pools[0])
 	attributes := getAttributes(req, vol, backendName)
+	addStorageSN(ctx, attributes, selectBackend.Plugin)
 
 	log.AddContext(ctx).Infof("Volume %s is created by manage", req.GetName())
 
@@ -546,6 +1420,57 @@ func validateCapacity(ctx context.Context, req *csi.CreateVolumeRequest, vol uti
 	return nil
 }
 
+// validateEncryption makes sure that the unencrypted volume can't be managed by the class requiring encryption
+func validateEncryption(ctx context.Context, req *csi.CreateVolumeRequest, vol utils.Volume) error {
+	encrypted, exist := req.GetParameters()[encryptedKey]
+	if !exist || !utils.StrToBool(ctx, encrypted) || vol.IsEncrypted() {
+		return nil
+	}
+
+	return utils.Errorf(ctx, "the volume %s is not encrypted on the storage, but the StorageClass requires "+
+		"encrypted volumes", vol.GetVolumeName())
+}
+
+// getPartialManageAnnotationsCode returns the retryable code if the PVC is still in the grace period of setting
+// the manage annotations, so that the provisioner retries instead of treating it as a misconfiguration
+func getPartialManageAnnotationsCode(ctx context.Context, pvName string) codes.Code {
+	gracePeriod := app.GetGlobalConfig().ManageAnnotationsGracePeriod
+	if gracePeriod <= 0 {
+		return codes.FailedPrecondition
+	}
+
+	createdTime, err := app.GetGlobalConfig().K8sUtils.GetVolumeClaimCreationTime(ctx, pvName)
+	if err != nil {
+		log.AddContext(ctx).Warningf("Get creation time of the PVC of volume %s failed, error: %v", pvName, err)
+		return codes.FailedPrecondition
+	}
+
+	return partialManageAnnotationsCode(createdTime, gracePeriod, time.Now())
+}
+
+func partialManageAnnotationsCode(createdTime time.Time, gracePeriod time.Duration, now time.Time) codes.Code {
+	if now.Sub(createdTime) < gracePeriod {
+		return codes.Unavailable
+	}
+
+	return codes.FailedPrecondition
+}
+
+// storageErrorCode returns NotFound if the storage is reachable but the volume does not exist on it, so that the
+// sidecars stop retrying, FailedPrecondition if the replication pair of the volume must be repaired first,
+// and Internal for the other errors of the storage
+func storageErrorCode(err error) codes.Code {
+	if errors.Is(err, constants.ErrVolumeNotFound) {
+		return codes.NotFound
+	}
+
+	if errors.Is(err, constants.ErrReplicationPairUnhealthy) {
+		return codes.FailedPrecondition
+	}
+
+	return codes.Internal
+}
+
 func processAnnotations(annotations map[string]string, req *csi.CreateVolumeRequest) error {
 	fileSystemMode, systemModeOk := annotations[app.GetGlobalConfig().DriverName+annFileSystemMode]
 	if systemModeOk && (fileSystemMode != "HyperMetro" && fileSystemMode != "local") {
@@ -562,9 +1487,85 @@ func processAnnotations(annotations map[string]string, req *csi.CreateVolumeRequ
 	if volumeNameOk {
 		req.Parameters["annVolumeName"] = volumeName
 	}
+
+	if allowedClients, ok := annotations[constants.NfsAllowedClientsAnnotation]; ok {
+		clients, err := pkgUtils.ParseNfsAllowedClients(allowedClients)
+		if err != nil {
+			return fmt.Errorf("the value of annotation %s is invalid, %v",
+				constants.NfsAllowedClientsAnnotation, err)
+		}
+		req.Parameters[nfsAllowedClientsKey] = strings.Join(clients, ",")
+	}
 	return nil
 }
 
+func getNfsAllowedClientACL(bk plugin.Plugin, parameters map[string]interface{}) (
+	plugin.NFSShareClientACL, []string, error) {
+	allowedClients, ok := parameters[nfsAllowedClientsKey].(string)
+	if !ok || allowedClients == "" {
+		return nil, nil, nil
+	}
+
+	clientACL, ok := bk.(plugin.NFSShareClientACL)
+	if !ok {
+		return nil, nil, status.Errorf(codes.InvalidArgument, "annotation %s is only supported by "+
+			"oceanstor-nas backend", constants.NfsAllowedClientsAnnotation)
+	}
+
+	return clientACL, strings.Split(allowedClients, ","), nil
+}
+
+// getPublishedPaths returns the target port WWNs or IPs and the host group name which the volume is mapped by,
+// so that the node is able to verify the expected paths
+func getPublishedPaths(mappingInfo map[string]interface{}) ([]string, string) {
+	var targetPorts []string
+	if wwns, ok := mappingInfo["tgtWWNs"].([]string); ok {
+		targetPorts = append(targetPorts, wwns...)
+	}
+	if portals, ok := mappingInfo["tgtPortals"].([]string); ok {
+		targetPorts = append(targetPorts, portals...)
+	}
+	if pairs, ok := mappingInfo["portWWNList"].([]nvme.PortWWNPair); ok {
+		for _, pair := range pairs {
+			targetPorts = append(targetPorts, pair.TargetPortWWN)
+		}
+	}
+
+	hostGroupName, _ := mappingInfo["hostGroupName"].(string)
+	return targetPorts, hostGroupName
+}
+
+// setRecordedHosts sets the hosts which are recorded in the publish info when the volume is published to the node
+// into the parameters, so that the volume is unpublished from the hosts it is mapped to instead of the hosts found
+// again. Nothing is set if the publish info can't be got, the hosts are found by the node then.
+func (d *Driver) setRecordedHosts(ctx context.Context, volumeId string, parameters map[string]interface{}) {
+	nodeName, _ := parameters["HostName"].(string)
+	if d.k8sUtils == nil || nodeName == "" {
+		return
+	}
+
+	metadata, err := d.k8sUtils.GetVolumeAttachmentMetadata(ctx, d.name, volumeId, nodeName)
+	if err != nil || metadata["publishInfo"] == "" {
+		log.AddContext(ctx).Infof("Publish info of volume %s on node %s is not found, error: %v",
+			volumeId, nodeName, err)
+		return
+	}
+
+	var publishInfo map[string]interface{}
+	if err = json.Unmarshal([]byte(metadata["publishInfo"]), &publishInfo); err != nil {
+		log.AddContext(ctx).Warningf("Unmarshal publish info of volume %s on node %s error: %v",
+			volumeId, nodeName, err)
+		return
+	}
+
+	if hostID, ok := publishInfo["hostID"].(string); ok && hostID != "" {
+		parameters["HostID"] = hostID
+	}
+	if hostID, ok := publishInfo["remoteHostID"].(string); ok && hostID != "" {
+		parameters["RemoteHostID"] = hostID
+	}
+}
+
 func getBackendFilesystemMode(ctx context.Context, bk *model.Backend, volName string) string {
 	if protocol, ok := bk.Parameters["protocol"].(string); ok && protocol == plugin.ProtocolNfsPlus &&
 		bk.Storage != plugin.DTreeStorage {
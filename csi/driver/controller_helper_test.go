@@ -19,8 +19,11 @@ package driver
 import (
 	"context"
 	"errors"
+	"fmt"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"huawei-csi-driver/csi/backend/model"
 
@@ -28,13 +31,20 @@ import (
 	"github.com/container-storage-interface/spec/lib/go/csi"
 	"github.com/prashantv/gostub"
 	"github.com/smartystreets/goconvey/convey"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	coreV1 "k8s.io/api/core/v1"
 
+	"huawei-csi-driver/connector/nvme"
 	"huawei-csi-driver/csi/app"
 	cfg "huawei-csi-driver/csi/app/config"
+	"huawei-csi-driver/csi/backend/cache"
 	"huawei-csi-driver/csi/backend/handler"
 	"huawei-csi-driver/csi/backend/plugin"
+	"huawei-csi-driver/pkg/constants"
 	pkgUtils "huawei-csi-driver/pkg/utils"
 	"huawei-csi-driver/utils"
+	"huawei-csi-driver/utils/k8sutils"
 	"huawei-csi-driver/utils/log"
 )
 
@@ -83,6 +93,18 @@ func TestCheckReservedSnapshotSpaceRatio(t *testing.T) {
 
 }
 
+func TestCheckPoolTags(t *testing.T) {
+	convey.Convey("Normal", t, func() {
+		param := map[string]interface{}{"poolTags": "team=finance,tier=gold"}
+		convey.So(checkPoolTags(context.TODO(), param), convey.ShouldBeNil)
+	})
+
+	convey.Convey("Not key=value", t, func() {
+		param := map[string]interface{}{"poolTags": "team=finance,gold"}
+		convey.So(checkPoolTags(context.TODO(), param), convey.ShouldBeError)
+	})
+}
+
 func mockCreateRequest() *csi.CreateVolumeRequest {
 	capacity := &csi.CapacityRange{
 		RequiredBytes: 1024 * 1024 * 1024,
@@ -131,10 +153,21 @@ func TestCreateVolumeWithoutBackend(t *testing.T) {
 		})
 	defer m.Reset()
 
+	var recordedReason string
+	m.ApplyMethod(reflect.TypeOf(driver.k8sUtils), "RecordPVCEvent",
+		func(_ *k8sutils.KubeClient, _ context.Context, _, _, reason, _ string) error {
+			recordedReason = reason
+			return nil
+		})
+
 	_, err := driver.createVolume(context.TODO(), req)
 	if err == nil {
 		t.Error("test create without backend failed")
 	}
+	if recordedReason != poolSelectionFailedReason {
+		t.Errorf("test create without backend recorded event %q, want %q", recordedReason,
+			poolSelectionFailedReason)
+	}
 }
 
 func TestCreateVolume(t *testing.T) {
@@ -247,3 +280,516 @@ func Test_processAnnotations(t *testing.T) {
 			"but got = %v", annotations, volume)
 	}
 }
+
+func TestGetExpandCapacity(t *testing.T) {
+	tests := []struct {
+		name          string
+		storage       string
+		requiredBytes int64
+		limitBytes    int64
+		want          int64
+		wantErr       bool
+	}{
+		{"NoLimit", "fusionstorage-san", 1024*1024 + 1, 0, 2 * 1024 * 1024, false},
+		{"RoundedWithinLimit", "oceanstor-san", 1000, 1024, 1024, false},
+		{"RoundedEqualLimit", "fusionstorage-san", 1024*1024 + 1, 2 * 1024 * 1024, 2 * 1024 * 1024, false},
+		{"RoundedExceedLimit", "fusionstorage-san", 1024*1024 + 1, 1024*1024 + 512, 0, true},
+		{"NoGranularity", "fusionstorage-nas", 1000, 1000, 1000, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := getExpandCapacity(tt.storage, tt.requiredBytes, tt.limitBytes)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("getExpandCapacity() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("getExpandCapacity() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetPublishedPaths(t *testing.T) {
+	tests := []struct {
+		name          string
+		mappingInfo   map[string]interface{}
+		wantPorts     []string
+		wantHostGroup string
+	}{
+		{"FC", map[string]interface{}{"tgtWWNs": []string{"wwn1", "wwn2"}, "hostGroupName": "group1"},
+			[]string{"wwn1", "wwn2"}, "group1"},
+		{"ISCSI", map[string]interface{}{"tgtPortals": []string{"192.168.1.1:3260"}},
+			[]string{"192.168.1.1:3260"}, ""},
+		{"FCNVMe", map[string]interface{}{"portWWNList": []nvme.PortWWNPair{
+			{InitiatorPortWWN: "initiator1", TargetPortWWN: "target1"}}},
+			[]string{"target1"}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ports, hostGroup := getPublishedPaths(tt.mappingInfo)
+			if !reflect.DeepEqual(ports, tt.wantPorts) || hostGroup != tt.wantHostGroup {
+				t.Errorf("getPublishedPaths() = %v, %s, want %v, %s", ports, hostGroup, tt.wantPorts,
+					tt.wantHostGroup)
+			}
+		})
+	}
+}
+
+func TestValidateEncryption(t *testing.T) {
+	newVolume := func(encrypted bool) utils.Volume {
+		vol := utils.NewVolume("vol")
+		vol.SetEncrypted(encrypted)
+		return vol
+	}
+	tests := []struct {
+		name       string
+		parameters map[string]string
+		vol        utils.Volume
+		wantErr    bool
+	}{
+		{"EncryptedVolume", map[string]string{"encrypted": "true"}, newVolume(true), false},
+		{"UnencryptedVolume", map[string]string{"encrypted": "true"}, newVolume(false), true},
+		{"EncryptionNotRequired", map[string]string{}, newVolume(false), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &csi.CreateVolumeRequest{Parameters: tt.parameters}
+			if err := validateEncryption(context.Background(), req, tt.vol); (err != nil) != tt.wantErr {
+				t.Errorf("validateEncryption() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+type fakeQueryPlugin struct {
+	plugin.Plugin
+	size int64
+	err  error
+}
+
+func (p *fakeQueryPlugin) QueryVolume(_ context.Context, name string, _ map[string]interface{}) (
+	utils.Volume, error) {
+	vol := utils.NewVolume(name)
+	vol.SetSize(p.size)
+	return vol, p.err
+}
+
+func TestCheckExpandingCapacity(t *testing.T) {
+	tests := []struct {
+		name    string
+		plugin  *fakeQueryPlugin
+		want    int64
+		wantErr bool
+	}{
+		{"SmallerThanExpected", &fakeQueryPlugin{size: 512}, 1024, false},
+		{"LargerThanExpectedWithinLimit", &fakeQueryPlugin{size: 2048}, 2048, false},
+		{"ExceedLimit", &fakeQueryPlugin{size: 4096}, 0, true},
+		{"QueryFailed", &fakeQueryPlugin{err: errors.New("query failed")}, 1024, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := checkExpandingCapacity(context.Background(), tt.plugin, "vol", 1024, 3072)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("checkExpandingCapacity() = %d, error = %v, want %d, wantErr %v", got, err, tt.want,
+					tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPartialManageAnnotationsCode(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name        string
+		createdTime time.Time
+		want        codes.Code
+	}{
+		{"InGracePeriod", now.Add(-time.Minute), codes.Unavailable},
+		{"GracePeriodExpired", now.Add(-10 * time.Minute), codes.FailedPrecondition},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := partialManageAnnotationsCode(tt.createdTime, 5*time.Minute, now); got != tt.want {
+				t.Errorf("partialManageAnnotationsCode() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStorageErrorCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want codes.Code
+	}{
+		{"VolumeNotFound", constants.ErrVolumeNotFound, codes.NotFound},
+		{"WrappedVolumeNotFound", fmt.Errorf("%w: lun pvc-1 to expand does not exist",
+			constants.ErrVolumeNotFound), codes.NotFound},
+		{"StorageError", errors.New("Expand lun error: 1077949001"), codes.Internal},
+		{"ReplicationPairUnhealthy", fmt.Errorf("%w: replication pair 1 of lun pvc-1 is in running status 94",
+			constants.ErrReplicationPairUnhealthy), codes.FailedPrecondition},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := storageErrorCode(tt.err); got != tt.want {
+				t.Errorf("storageErrorCode() = %v, want %v", got, tt.want)
+			}
+
+			names := (&Driver{}).newVolumeResourceNames(context.Background(), "backend-1.pvc-1", nil)
+			err := names.statusError(storageErrorCode(tt.err), tt.err)
+			if !strings.Contains(status.Convert(err).Message(), "volume id: backend-1.pvc-1") {
+				t.Errorf("statusError() = %v, want the volume id in the message", err)
+			}
+		})
+	}
+}
+
+type fakeAttachedNodesK8sUtils struct {
+	k8sutils.Interface
+	nodes []string
+	err   error
+}
+
+func (k *fakeAttachedNodesK8sUtils) GetVolumeAttachedNodes(context.Context, string, string) ([]string, error) {
+	return k.nodes, k.err
+}
+
+func TestCheckSnapshotSource(t *testing.T) {
+	tests := []struct {
+		name       string
+		parameters map[string]string
+		k8sUtils   *fakeAttachedNodesK8sUtils
+		want       codes.Code
+	}{
+		{"NotRequired", map[string]string{}, &fakeAttachedNodesK8sUtils{nodes: []string{"node1"}}, codes.OK},
+		{"ExplicitlyNotRequired", map[string]string{requireDetachedSourceKey: "false"},
+			&fakeAttachedNodesK8sUtils{nodes: []string{"node1"}}, codes.OK},
+		{"RequiredAndDetached", map[string]string{requireDetachedSourceKey: "true"},
+			&fakeAttachedNodesK8sUtils{}, codes.OK},
+		{"RequiredButAttached", map[string]string{requireDetachedSourceKey: "true"},
+			&fakeAttachedNodesK8sUtils{nodes: []string{"node1"}}, codes.FailedPrecondition},
+		{"RequiredButQueryFailed", map[string]string{requireDetachedSourceKey: "true"},
+			&fakeAttachedNodesK8sUtils{err: errors.New("list failed")}, codes.Internal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &Driver{name: "csi.huawei.com", k8sUtils: tt.k8sUtils}
+			err := d.checkSnapshotSource(context.Background(), "backend.pvc-1", tt.parameters)
+			if got := status.Code(err); got != tt.want {
+				t.Errorf("checkSnapshotSource() code = %v, want %v, error: %v", got, tt.want, err)
+			}
+		})
+	}
+
+	if err := checkSnapshotParameters(context.Background(),
+		map[string]string{requireDetachedSourceKey: "yes"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("checkSnapshotParameters() error = %v, want InvalidArgument", err)
+	}
+}
+
+func TestCheckSnapshotParameters(t *testing.T) {
+	tests := []struct {
+		name       string
+		parameters map[string]string
+		want       codes.Code
+	}{
+		{"NoParameter", map[string]string{}, codes.OK},
+		{"AutoDeleteAfterDays", map[string]string{constants.SnapshotAutoDeleteAfterDaysParameter: "7"}, codes.OK},
+		{"ZeroDays", map[string]string{constants.SnapshotAutoDeleteAfterDaysParameter: "0"}, codes.InvalidArgument},
+		{"NegativeDays", map[string]string{constants.SnapshotAutoDeleteAfterDaysParameter: "-1"},
+			codes.InvalidArgument},
+		{"NotNumber", map[string]string{constants.SnapshotAutoDeleteAfterDaysParameter: "7d"},
+			codes.InvalidArgument},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := status.Code(checkSnapshotParameters(context.Background(), tt.parameters)); got != tt.want {
+				t.Errorf("checkSnapshotParameters() code = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyBackendDefaultParameters(t *testing.T) {
+	ctx := context.Background()
+	cache.BackendCacheProvider.Store(ctx, "defaults-backend", model.Backend{Name: "defaults-backend",
+		DefaultParameters: map[string]string{"fsPermission": "755", "allocType": "thin", "nfsProtocol": "nfs41",
+			"description": "default"}})
+	cache.BackendCacheProvider.Store(ctx, "invalid-backend", model.Backend{Name: "invalid-backend",
+		DefaultParameters: map[string]string{"fsPermission": "999"}})
+	cache.BackendCacheProvider.Store(ctx, "invalid-nfs-backend", model.Backend{Name: "invalid-nfs-backend",
+		DefaultParameters: map[string]string{"nfsProtocol": "nfsvers=4.1"}})
+	defer cache.BackendCacheProvider.Delete(ctx, "defaults-backend")
+	defer cache.BackendCacheProvider.Delete(ctx, "invalid-backend")
+	defer cache.BackendCacheProvider.Delete(ctx, "invalid-nfs-backend")
+
+	tests := []struct {
+		name      string
+		backend   string
+		scParams  map[string]string
+		derived   map[string]interface{}
+		want      map[string]interface{}
+		wantError codes.Code
+	}{
+		{"NoDefaults", "unknown-backend", map[string]string{"fsPermission": "700"}, nil,
+			map[string]interface{}{"fsPermission": "700"}, codes.OK},
+		{"StorageClassWins", "defaults-backend", map[string]string{"fsPermission": "700"}, nil,
+			map[string]interface{}{"fsPermission": "700", "allocType": "thin", "nfsProtocol": "nfs41",
+				"description": "default"}, codes.OK},
+		{"MountOptionsWin", "defaults-backend", map[string]string{},
+			map[string]interface{}{"nfsProtocol": "nfs3", "description": "Created from Kubernetes CSI"},
+			map[string]interface{}{"fsPermission": "755", "allocType": "thin", "nfsProtocol": "nfs3",
+				"description": "default"}, codes.OK},
+		{"InvalidDefault", "invalid-backend", map[string]string{}, nil,
+			map[string]interface{}{"fsPermission": "999"}, codes.InvalidArgument},
+		{"InvalidDefaultNFSProtocol", "invalid-nfs-backend", map[string]string{}, nil,
+			map[string]interface{}{"nfsProtocol": "nfsvers=4.1"}, codes.InvalidArgument},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &csi.CreateVolumeRequest{Name: "pvc-1", Parameters: tt.scParams,
+				CapacityRange: &csi.CapacityRange{RequiredBytes: 1024 * 1024},
+				VolumeCapabilities: []*csi.VolumeCapability{{
+					AccessType: &csi.VolumeCapability_Mount{Mount: &csi.VolumeCapability_MountVolume{}},
+					AccessMode: &csi.VolumeCapability_AccessMode{
+						Mode: csi.VolumeCapability_AccessMode_SINGLE_NODE_WRITER},
+				}}}
+			parameters := utils.CopyMap(tt.scParams)
+			for key, value := range tt.derived {
+				parameters[key] = value
+			}
+			err := applyBackendDefaultParameters(ctx, req, parameters, tt.backend)
+			if got := status.Code(err); got != tt.wantError {
+				t.Errorf("applyBackendDefaultParameters() code = %v, want %v, error: %v", got, tt.wantError, err)
+			}
+			if !reflect.DeepEqual(parameters, tt.want) {
+				t.Errorf("applyBackendDefaultParameters() parameters = %v, want %v", parameters, tt.want)
+			}
+		})
+	}
+}
+
+type fakeQosBudgetPlugin struct {
+	plugin.Plugin
+	state     *plugin.VolumeState
+	budgetErr error
+}
+
+func (p *fakeQosBudgetPlugin) InspectVolume(_ context.Context, _ string) (*plugin.VolumeState, error) {
+	return p.state, nil
+}
+
+func (p *fakeQosBudgetPlugin) CheckQosBudget(_ context.Context) error {
+	return p.budgetErr
+}
+
+func TestCheckQosBudget(t *testing.T) {
+	exhausted := fmt.Errorf("%w: 512 of 512 qos policies are used", plugin.ErrQosBudgetExhausted)
+	tests := []struct {
+		name       string
+		plugin     *fakeQosBudgetPlugin
+		parameters map[string]interface{}
+		wantErr    bool
+	}{
+		{"WithoutQos", &fakeQosBudgetPlugin{budgetErr: exhausted}, map[string]interface{}{}, false},
+		{"BudgetAvailable", &fakeQosBudgetPlugin{}, map[string]interface{}{"qos": `{"IOPS": 1000}`}, false},
+		{"BudgetExhausted", &fakeQosBudgetPlugin{budgetErr: exhausted},
+			map[string]interface{}{"qos": `{"IOPS": 1000}`}, true},
+		{"VolumeExists", &fakeQosBudgetPlugin{state: &plugin.VolumeState{}, budgetErr: exhausted},
+			map[string]interface{}{"qos": `{"IOPS": 1000}`}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkQosBudget(context.Background(), tt.plugin, "vol", tt.parameters)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("checkQosBudget() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+type fakeSnapshotPlugin struct {
+	plugin.Plugin
+	parentID  string
+	createErr error
+	deleted   []string
+}
+
+func (p *fakeSnapshotPlugin) CreateSnapshot(_ context.Context, _, _ string) (map[string]interface{}, error) {
+	if p.createErr != nil {
+		return nil, p.createErr
+	}
+	return map[string]interface{}{"ParentID": p.parentID, "SizeBytes": int64(1024), "CreationTime": int64(1)}, nil
+}
+
+func (p *fakeSnapshotPlugin) DeleteSnapshot(_ context.Context, parentID, _ string) error {
+	p.deleted = append(p.deleted, parentID)
+	return nil
+}
+
+func TestCreateMetroSnapshot(t *testing.T) {
+	tests := []struct {
+		name          string
+		local         *fakeSnapshotPlugin
+		remote        *fakeSnapshotPlugin
+		wantParentID  string
+		wantErr       bool
+		wantRollbacks int
+	}{
+		{"BothSites", &fakeSnapshotPlugin{parentID: "1"}, &fakeSnapshotPlugin{parentID: "2"}, "1+2", false, 0},
+		{"RemoteFailed", &fakeSnapshotPlugin{parentID: "1"},
+			&fakeSnapshotPlugin{createErr: errors.New("remote failed")}, "", true, 1},
+		{"LocalFailed", &fakeSnapshotPlugin{createErr: errors.New("local failed")},
+			&fakeSnapshotPlugin{parentID: "2"}, "", true, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bk := &model.Backend{Name: "local", Plugin: tt.local,
+				MetroBackend: &model.Backend{Name: "remote", Plugin: tt.remote}}
+			snapshot, err := createMetroSnapshot(context.Background(), bk, "vol", "snap")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("createMetroSnapshot() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && snapshot["ParentID"] != tt.wantParentID {
+				t.Errorf("createMetroSnapshot() ParentID = %v, want %s", snapshot["ParentID"], tt.wantParentID)
+			}
+			if got := len(tt.local.deleted) + len(tt.remote.deleted); got != tt.wantRollbacks {
+				t.Errorf("createMetroSnapshot() rollbacks = %d, want %d", got, tt.wantRollbacks)
+			}
+		})
+	}
+}
+
+type fakePodK8sUtils struct {
+	k8sutils.Interface
+	pod *coreV1.Pod
+	err error
+}
+
+func (k *fakePodK8sUtils) GetPodByPVCName(context.Context, string) (*coreV1.Pod, error) {
+	return k.pod, k.err
+}
+
+func TestApplyPriorityClassQoS(t *testing.T) {
+	ctx := context.Background()
+	cache.BackendCacheProvider.Store(ctx, "qos-backend", model.Backend{Name: "qos-backend",
+		Parameters: map[string]interface{}{"priorityClassToQoS": map[string]interface{}{
+			"high-priority": `{"MINIOPS": 10000}`}}})
+	defer cache.BackendCacheProvider.Delete(ctx, "qos-backend")
+
+	highPriorityPod := &coreV1.Pod{Spec: coreV1.PodSpec{PriorityClassName: "high-priority"}}
+	tests := []struct {
+		name       string
+		backend    string
+		parameters map[string]interface{}
+		k8sUtils   *fakePodK8sUtils
+		want       interface{}
+	}{
+		{"Mapped", "qos-backend", map[string]interface{}{}, &fakePodK8sUtils{pod: highPriorityPod},
+			`{"MINIOPS": 10000}`},
+		{"StorageClassWins", "qos-backend", map[string]interface{}{"qos": `{"MAXIOPS": 100}`},
+			&fakePodK8sUtils{pod: highPriorityPod}, `{"MAXIOPS": 100}`},
+		{"NotMapped", "qos-backend", map[string]interface{}{},
+			&fakePodK8sUtils{pod: &coreV1.Pod{Spec: coreV1.PodSpec{PriorityClassName: "low-priority"}}}, nil},
+		{"NoPod", "qos-backend", map[string]interface{}{}, &fakePodK8sUtils{}, nil},
+		{"GetPodFailed", "qos-backend", map[string]interface{}{},
+			&fakePodK8sUtils{err: errors.New("list failed")}, nil},
+		{"BackendWithoutMapping", "unknown-backend", map[string]interface{}{},
+			&fakePodK8sUtils{pod: highPriorityPod}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &Driver{name: "csi.huawei.com", k8sUtils: tt.k8sUtils}
+			d.applyPriorityClassQoS(ctx, "pvc-1", tt.parameters, tt.backend)
+			if got := tt.parameters["qos"]; got != tt.want {
+				t.Errorf("applyPriorityClassQoS() qos = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+type fakeEventK8sUtils struct {
+	k8sutils.Interface
+	reasons []string
+}
+
+func (f *fakeEventK8sUtils) RecordPVCEvent(_ context.Context, _, _, reason, _ string) error {
+	f.reasons = append(f.reasons, reason)
+	return nil
+}
+
+type fakeLicensePlugin struct {
+	plugin.Plugin
+	missing []string
+	err     error
+}
+
+func (f *fakeLicensePlugin) GetMissingLicenses(context.Context, map[string]interface{}) ([]string, error) {
+	return f.missing, f.err
+}
+
+func TestWarnMissingLicenses(t *testing.T) {
+	tests := []struct {
+		name      string
+		plugin    plugin.Plugin
+		wantEvent bool
+	}{
+		{"LicenseMissing", &fakeLicensePlugin{missing: []string{"CIFS"}}, true},
+		{"LicensePresent", &fakeLicensePlugin{}, false},
+		{"GetLicenseFailed", &fakeLicensePlugin{err: errors.New("get license failed")}, false},
+		{"NotChecked", plugin.GetPlugin("oceanstor-san"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			k8sUtils := &fakeEventK8sUtils{}
+			d := &Driver{name: "csi.huawei.com", k8sUtils: k8sUtils}
+			pool := &model.StoragePool{Name: "pool", Parent: "backend", Plugin: tt.plugin}
+			d.warnMissingLicenses(context.Background(), "pvc-1", pool, map[string]interface{}{})
+			if gotEvent := len(k8sUtils.reasons) != 0; gotEvent != tt.wantEvent {
+				t.Errorf("warnMissingLicenses() events = %v, want event %v", k8sUtils.reasons, tt.wantEvent)
+			}
+		})
+	}
+}
+
+func TestGetSpaceSoftQuotaPercent(t *testing.T) {
+	volumeId := "nfs_dtree.pvc-0a3c7f12-5b8e-4d6a-9c1f-7e2d4b6a8c90"
+	newPV := func(attributes map[string]string) *coreV1.PersistentVolume {
+		return &coreV1.PersistentVolume{Spec: coreV1.PersistentVolumeSpec{
+			PersistentVolumeSource: coreV1.PersistentVolumeSource{CSI: &coreV1.CSIPersistentVolumeSource{
+				VolumeHandle: volumeId, VolumeAttributes: attributes,
+			}}}}
+	}
+
+	tests := []struct {
+		name string
+		pv   *coreV1.PersistentVolume
+		want string
+	}{
+		{"SoftQuota", newPV(map[string]string{spaceSoftQuotaPercentKey: "90"}), "90"},
+		{"WithoutSoftQuota", newPV(map[string]string{}), ""},
+		{"PVNotFound", nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &Driver{name: "csi.huawei.com", k8sUtils: &fakeDTreeK8sUtils{pv: tt.pv}}
+			if got := d.getSpaceSoftQuotaPercent(context.Background(), volumeId); got != tt.want {
+				t.Errorf("getSpaceSoftQuotaPercent() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
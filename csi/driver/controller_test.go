@@ -0,0 +1,363 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package driver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	"github.com/prashantv/gostub"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xuanwuv1 "huawei-csi-driver/client/apis/xuanwu/v1"
+	"huawei-csi-driver/csi/backend/handler"
+	"huawei-csi-driver/csi/backend/model"
+	"huawei-csi-driver/csi/backend/plugin"
+	"huawei-csi-driver/pkg/constants"
+	pkgUtils "huawei-csi-driver/pkg/utils"
+	"huawei-csi-driver/utils"
+	"huawei-csi-driver/utils/k8sutils"
+)
+
+type fakeInventoryPlugin struct {
+	plugin.Plugin
+	inventory plugin.StorageInventory
+}
+
+func (p *fakeInventoryPlugin) GetStorageInventory() (plugin.StorageInventory, error) {
+	return p.inventory, nil
+}
+
+type fakeVolumeCheckerPlugin struct {
+	fakeInventoryPlugin
+	volumes []string
+}
+
+func (p *fakeVolumeCheckerPlugin) VolumeExists(_ context.Context, name string) (bool, error) {
+	return utils.IsContain(name, p.volumes), nil
+}
+
+type fakeBackendSelector struct {
+	handler.BackendSelectInterface
+	backends map[string]*model.Backend
+}
+
+func (s *fakeBackendSelector) SelectBackend(_ context.Context, name string) (*model.Backend, error) {
+	return s.backends[name], nil
+}
+
+func TestControllerGetVolume(t *testing.T) {
+	d := &Driver{backendSelector: &fakeBackendSelector{backends: map[string]*model.Backend{
+		"san": {Name: "san", Plugin: &fakeInventoryPlugin{
+			inventory: plugin.StorageInventory{SerialNumber: "2102351234", Model: "OceanStor Dorado 5000 V6"}}},
+		"other":   {Name: "other", Plugin: &fakeInventoryPlugin{}},
+		"checked": {Name: "checked", Plugin: &fakeVolumeCheckerPlugin{volumes: []string{"pvc_1"}}},
+	}}}
+
+	tests := []struct {
+		name           string
+		volumeId       string
+		wantAttributes map[string]string
+		wantCode       codes.Code
+	}{
+		{"StorageSN", "san.pvc_1",
+			map[string]string{"backend": "san", "name": "pvc_1", storageSNKey: "2102351234"}, codes.OK},
+		{"UnknownStorageSN", "other.pvc_1", map[string]string{"backend": "other", "name": "pvc_1"}, codes.OK},
+		{"BackendNotExist", "absent.pvc_1", nil, codes.NotFound},
+		{"VolumeExist", "checked.pvc_1", map[string]string{"backend": "checked", "name": "pvc_1"}, codes.OK},
+		{"VolumeNotExist", "checked.pvc_2", nil, codes.NotFound},
+		{"InvalidVolumeId", "pvc_1", nil, codes.InvalidArgument},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := d.ControllerGetVolume(context.Background(),
+				&csi.ControllerGetVolumeRequest{VolumeId: tt.volumeId})
+			if status.Code(err) != tt.wantCode {
+				t.Fatalf("ControllerGetVolume() error = %v, want code %s", err, tt.wantCode)
+			}
+			if !reflect.DeepEqual(res.GetVolume().GetVolumeContext(), tt.wantAttributes) {
+				t.Errorf("ControllerGetVolume() attributes = %v, want %v",
+					res.GetVolume().GetVolumeContext(), tt.wantAttributes)
+			}
+		})
+	}
+}
+
+func TestReadOnlyBackend(t *testing.T) {
+	d := &Driver{backendSelector: &fakeBackendSelector{backends: map[string]*model.Backend{
+		"san": {Name: "san", Storage: "oceanstor-san", ReadOnly: true, Plugin: &fakeInventoryPlugin{}},
+	}}}
+
+	tests := []struct {
+		name string
+		call func() error
+	}{
+		{"DeleteVolume", func() error {
+			_, err := d.DeleteVolume(context.Background(), &csi.DeleteVolumeRequest{VolumeId: "san.pvc_1"})
+			return err
+		}},
+		{"DeleteSnapshot", func() error {
+			_, err := d.DeleteSnapshot(context.Background(),
+				&csi.DeleteSnapshotRequest{SnapshotId: "san.1.snapshot_1"})
+			return err
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.call(); status.Code(err) != codes.FailedPrecondition {
+				t.Errorf("%s() error = %v, want code %s", tt.name, err, codes.FailedPrecondition)
+			}
+		})
+	}
+}
+
+type fakeShareACLPlugin struct {
+	plugin.Plugin
+	updatedClients []string
+	updated        bool
+	deleted        bool
+}
+
+func (p *fakeShareACLPlugin) UpdateNFSShareClientACL(_ context.Context, _ string, clients []string) error {
+	p.updatedClients, p.updated = clients, true
+	return nil
+}
+
+func (p *fakeShareACLPlugin) DeleteVolume(context.Context, string) error {
+	p.deleted = true
+	return nil
+}
+
+type fakeVolumeAttributesK8sUtils struct {
+	k8sutils.Interface
+	attributes map[string]string
+}
+
+func (f *fakeVolumeAttributesK8sUtils) GetVolumeAttributes(context.Context, string) (map[string]string, error) {
+	return f.attributes, nil
+}
+
+func TestDeleteVolumeResetsShareClients(t *testing.T) {
+	tests := []struct {
+		name       string
+		attributes map[string]string
+		wantReset  bool
+	}{
+		{"RestrictedClients", map[string]string{nfsAllowedClientsKey: "192.168.1.0/24"}, true},
+		{"UnrestrictedClients", map[string]string{}, false},
+	}
+
+	stub := gostub.StubFunc(&pkgUtils.DeletePVLabel)
+	defer stub.Reset()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			aclPlugin := &fakeShareACLPlugin{}
+			d := &Driver{backendSelector: &fakeBackendSelector{backends: map[string]*model.Backend{
+				"nas": {Name: "nas", Storage: "oceanstor-nas", Plugin: aclPlugin},
+			}}, k8sUtils: &fakeVolumeAttributesK8sUtils{attributes: tt.attributes}}
+
+			_, err := d.DeleteVolume(context.Background(), &csi.DeleteVolumeRequest{VolumeId: "nas.pvc_1"})
+			if err != nil {
+				t.Fatalf("DeleteVolume() error = %v", err)
+			}
+
+			if aclPlugin.updated != tt.wantReset ||
+				(tt.wantReset && !reflect.DeepEqual(aclPlugin.updatedClients, []string{"*"})) {
+				t.Errorf("DeleteVolume() updated = %v, clients = %v, want reset %v",
+					aclPlugin.updated, aclPlugin.updatedClients, tt.wantReset)
+			}
+			if !aclPlugin.deleted {
+				t.Errorf("DeleteVolume() doesn't delete the volume")
+			}
+		})
+	}
+}
+
+type fakeExpandPlugin struct {
+	plugin.Plugin
+	expandedSize int64
+}
+
+func (p *fakeExpandPlugin) ExpandVolume(_ context.Context, _ string, size int64) (bool, error) {
+	p.expandedSize = size
+	return true, nil
+}
+
+func (p *fakeExpandPlugin) QueryVolume(_ context.Context, name string, _ map[string]interface{}) (
+	utils.Volume, error) {
+	vol := utils.NewVolume(name)
+	vol.SetSize(p.expandedSize)
+	return vol, nil
+}
+
+func TestControllerExpandVolumeRoundedCapacity(t *testing.T) {
+	tests := []struct {
+		name          string
+		storage       string
+		requiredBytes int64
+		limitBytes    int64
+		wantCapacity  int64
+		wantCode      codes.Code
+	}{
+		{"RoundedUp", "fusionstorage-san", 1024*1024*1024 + 1, 0, 1025 * 1024 * 1024, codes.OK},
+		{"RoundedWithinLimit", "fusionstorage-san", 1024*1024*1024 + 1, 2 * 1024 * 1024 * 1024,
+			1025 * 1024 * 1024, codes.OK},
+		{"RoundedOverLimit", "fusionstorage-san", 1024*1024*1024 + 1, 1024*1024*1024 + 512, 0, codes.OutOfRange},
+		{"NotRounded", "fusionstorage-nas", 1024*1024*1024 + 1, 0, 1024*1024*1024 + 1, codes.OK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expandPlugin := &fakeExpandPlugin{}
+			d := &Driver{backendSelector: &fakeBackendSelector{backends: map[string]*model.Backend{
+				"bk": {Name: "bk", Storage: tt.storage, Plugin: expandPlugin},
+			}}}
+
+			res, err := d.ControllerExpandVolume(context.Background(), &csi.ControllerExpandVolumeRequest{
+				VolumeId:         "bk.pvc_1",
+				CapacityRange:    &csi.CapacityRange{RequiredBytes: tt.requiredBytes, LimitBytes: tt.limitBytes},
+				VolumeCapability: &csi.VolumeCapability{AccessType: &csi.VolumeCapability_Block{}},
+			})
+			if status.Code(err) != tt.wantCode {
+				t.Fatalf("ControllerExpandVolume() error = %v, want code %s", err, tt.wantCode)
+			}
+			if expandPlugin.expandedSize != tt.wantCapacity || res.GetCapacityBytes() != tt.wantCapacity {
+				t.Errorf("ControllerExpandVolume() expands to %d and reports %d, want %d",
+					expandPlugin.expandedSize, res.GetCapacityBytes(), tt.wantCapacity)
+			}
+		})
+	}
+}
+
+type fakeStorageErrorPlugin struct {
+	plugin.Plugin
+	err error
+}
+
+func (p *fakeStorageErrorPlugin) ExpandVolume(context.Context, string, int64) (bool, error) {
+	return false, p.err
+}
+
+func (p *fakeStorageErrorPlugin) AttachVolume(context.Context, string, map[string]interface{}) (
+	map[string]interface{}, error) {
+	return nil, p.err
+}
+
+func TestControllerVolumeNotFound(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		wantCode codes.Code
+	}{
+		{"VolumeNotFound", fmt.Errorf("%w: lun pvc_1 does not exist", constants.ErrVolumeNotFound), codes.NotFound},
+		{"WrappedVolumeNotFound", fmt.Errorf("storage connect error: %w",
+			fmt.Errorf("%w: lun pvc_1 does not exist", constants.ErrVolumeNotFound)), codes.NotFound},
+		{"StorageError", errors.New("storage is busy"), codes.Internal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &Driver{backendSelector: &fakeBackendSelector{backends: map[string]*model.Backend{
+				"bk": {Name: "bk", Storage: "oceanstor-san", Plugin: &fakeStorageErrorPlugin{err: tt.err}},
+			}}}
+
+			_, err := d.ControllerPublishVolume(context.Background(), &csi.ControllerPublishVolumeRequest{
+				VolumeId: "bk.pvc_1",
+				NodeId:   `{"HostName": "node1"}`,
+			})
+			if status.Code(err) != tt.wantCode {
+				t.Errorf("ControllerPublishVolume() error = %v, want code %s", err, tt.wantCode)
+			}
+
+			_, err = d.ControllerExpandVolume(context.Background(), &csi.ControllerExpandVolumeRequest{
+				VolumeId:         "bk.pvc_1",
+				CapacityRange:    &csi.CapacityRange{RequiredBytes: 1024 * 1024 * 1024},
+				VolumeCapability: &csi.VolumeCapability{AccessType: &csi.VolumeCapability_Block{}},
+			})
+			if status.Code(err) != tt.wantCode {
+				t.Errorf("ControllerExpandVolume() error = %v, want code %s", err, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestCheckRequestedBackend(t *testing.T) {
+	d := &Driver{backendSelector: &fakeBackendSelector{backends: map[string]*model.Backend{
+		"san":       {Name: "san"},
+		"metro-san": {Name: "metro-san", MetroBackend: &model.Backend{Name: "remote-san"}},
+		"ro-san":    {Name: "ro-san", ReadOnly: true},
+	}}}
+
+	tests := []struct {
+		name       string
+		parameters map[string]interface{}
+		wantCode   codes.Code
+	}{
+		{"AutoSelection", map[string]interface{}{"hyperMetro": "true"}, codes.OK},
+		{"MetroPeerConfigured", map[string]interface{}{"backend": "metro-san", "hyperMetro": "true"}, codes.OK},
+		{"MetroPeerNotConfigured", map[string]interface{}{"backend": "san", "hyperMetro": "true"},
+			codes.FailedPrecondition},
+		{"MetroNotRequested", map[string]interface{}{"backend": "san", "hyperMetro": "false"}, codes.OK},
+		{"ReadOnly", map[string]interface{}{"backend": "ro-san"}, codes.FailedPrecondition},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := d.checkRequestedBackend(context.Background(), &csi.CreateVolumeRequest{Name: "pvc-1"},
+				tt.parameters)
+			if status.Code(err) != tt.wantCode {
+				t.Errorf("checkRequestedBackend() error = %v, want code %s", err, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestFindBackend(t *testing.T) {
+	claims := []xuanwuv1.StorageBackendClaim{
+		{ObjectMeta: metav1.ObjectMeta{Name: "san"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "nas"}},
+	}
+
+	tests := []struct {
+		name          string
+		backendName   string
+		wantAvailable []string
+		wantExists    bool
+	}{
+		{"Exists", "san", nil, true},
+		{"NotExists", "dtree", []string{"nas", "san"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			available, exists := findBackend(tt.backendName, claims)
+			if exists != tt.wantExists || !reflect.DeepEqual(available, tt.wantAvailable) {
+				t.Errorf("findBackend() got = %v, %v, want %v, %v", available, exists,
+					tt.wantAvailable, tt.wantExists)
+			}
+		})
+	}
+}
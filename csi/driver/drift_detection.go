@@ -0,0 +1,274 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package driver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	coreV1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/flowcontrol"
+
+	"huawei-csi-driver/csi/backend/cache"
+	"huawei-csi-driver/csi/backend/plugin"
+	"huawei-csi-driver/utils"
+	"huawei-csi-driver/utils/log"
+)
+
+const (
+	driftDetectedReason = "DriftDetected"
+
+	// observedStoragePoolAnnotation records the storage pool which the volume is found in by the drift detection,
+	// the volume may be moved to another pool by the storage administrator
+	observedStoragePoolAnnotation = "csi.huawei.com/observed-storage-pool"
+	// observedDriftAnnotation records the drift of the volume for which the last event is recorded, so that the
+	// event is only recorded again when the drift changes
+	observedDriftAnnotation = "csi.huawei.com/observed-drift"
+)
+
+// DriftDetectionConfig is the configuration of the drift detection between the PVs and their volumes on the storage
+type DriftDetectionConfig struct {
+	Interval           time.Duration
+	SampleSize         int
+	QPS                float64
+	BackendConcurrency int
+}
+
+// driftDetector compares a sample of the PVs with their volumes on the storage in each detection, the PVs are
+// sampled in turn so that all of them are compared over the detections
+type driftDetector struct {
+	d       *Driver
+	config  DriftDetectionConfig
+	limiter flowcontrol.RateLimiter
+	// the index of the first PV of the next sample in the PVs sorted by name
+	cursor int
+}
+
+// DetectDriftInBackground compares a sample of the PVs with their volumes on the storage periodically, until the
+// stop channel is closed. The observed storage pool is recorded in the annotations of the PV, the difference of
+// the fields owned by the driver is recorded as a warning event of the PV.
+func (d *Driver) DetectDriftInBackground(ctx context.Context, config DriftDetectionConfig, stopCh <-chan struct{}) {
+	log.AddContext(ctx).Infof("Start to detect the drift of %d PVs every %s", config.SampleSize, config.Interval)
+	detector := &driftDetector{
+		d:       d,
+		config:  config,
+		limiter: flowcontrol.NewTokenBucketRateLimiter(float32(config.QPS), 1),
+	}
+	wait.Until(func() { detector.detect(ctx) }, config.Interval, stopCh)
+}
+
+func (dd *driftDetector) detect(ctx context.Context) {
+	pvs, err := dd.d.k8sUtils.ListDriverPersistentVolumes(ctx, dd.d.name)
+	if err != nil {
+		log.AddContext(ctx).Warningf("List PVs of driver %s failed, skip detecting drift, error: %v", dd.d.name, err)
+		return
+	}
+
+	storageClasses, err := dd.d.k8sUtils.ListStorageClassesByProvisioner(ctx, dd.d.name)
+	if err != nil {
+		log.AddContext(ctx).Warningf("List StorageClasses of driver %s failed, skip detecting drift, error: %v",
+			dd.d.name, err)
+		return
+	}
+	parameters := make(map[string]map[string]string, len(storageClasses))
+	for _, storageClass := range storageClasses {
+		parameters[storageClass.Name] = storageClass.Parameters
+	}
+
+	var sample []coreV1.PersistentVolume
+	sample, dd.cursor = nextDriftSample(pvs, dd.cursor, dd.config.SampleSize)
+
+	backendPVs := make(map[string][]*coreV1.PersistentVolume)
+	for i := range sample {
+		backendName, _ := utils.SplitVolumeId(sample[i].Spec.CSI.VolumeHandle)
+		backendPVs[backendName] = append(backendPVs[backendName], &sample[i])
+	}
+
+	// the volumes of each backend are queried by a bounded number of workers
+	var wg sync.WaitGroup
+	for _, pvs := range backendPVs {
+		queue := make(chan *coreV1.PersistentVolume, len(pvs))
+		for _, pv := range pvs {
+			queue <- pv
+		}
+		close(queue)
+
+		for i := 0; i < dd.config.BackendConcurrency && i < len(pvs); i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for pv := range queue {
+					dd.detectVolume(ctx, pv, parameters[pv.Spec.StorageClassName])
+				}
+			}()
+		}
+	}
+	wg.Wait()
+}
+
+// nextDriftSample returns at most size PVs sorted by name starting from the cursor, and the cursor of the next
+// sample which wraps around to the first PV
+func nextDriftSample(pvs []coreV1.PersistentVolume, cursor, size int) ([]coreV1.PersistentVolume, int) {
+	if len(pvs) == 0 {
+		return nil, 0
+	}
+
+	sort.Slice(pvs, func(i, j int) bool { return pvs[i].Name < pvs[j].Name })
+	if cursor >= len(pvs) {
+		cursor = 0
+	}
+	if size >= len(pvs) {
+		return pvs, 0
+	}
+
+	sample := make([]coreV1.PersistentVolume, 0, size)
+	for i := 0; i < size; i++ {
+		sample = append(sample, pvs[(cursor+i)%len(pvs)])
+	}
+	return sample, (cursor + size) % len(pvs)
+}
+
+func (dd *driftDetector) detectVolume(ctx context.Context, pv *coreV1.PersistentVolume,
+	parameters map[string]string) {
+	backendName, volumeName := utils.SplitVolumeId(pv.Spec.CSI.VolumeHandle)
+	bk, exist := cache.BackendCacheProvider.Load(backendName)
+	if !exist {
+		return
+	}
+
+	inspector, ok := bk.Plugin.(plugin.VolumeInspector)
+	if !ok {
+		return
+	}
+
+	if err := dd.limiter.Wait(ctx); err != nil {
+		return
+	}
+
+	state, err := inspector.InspectVolume(ctx, volumeName)
+	if err != nil {
+		log.AddContext(ctx).Warningf("Inspect volume %s of PV %s failed, error: %v", volumeName, pv.Name, err)
+		return
+	}
+	if state == nil {
+		log.AddContext(ctx).Debugf("Volume %s of PV %s does not exist, skip detecting drift", volumeName, pv.Name)
+		return
+	}
+
+	drift := strings.Join(getVolumeDrifts(pv, parameters, state), "; ")
+	dd.updateObservedState(ctx, pv, state.StoragePool, drift)
+	if drift == "" || drift == pv.Annotations[observedDriftAnnotation] {
+		return
+	}
+
+	msg := fmt.Sprintf("Volume %s on backend %s drifts from the PV: %s", volumeName, backendName, drift)
+	log.AddContext(ctx).Warningln(msg)
+	if err = dd.d.k8sUtils.RecordPVEvent(ctx, pv.Name, coreV1.EventTypeWarning, driftDetectedReason,
+		msg); err != nil {
+		log.AddContext(ctx).Warningf("Record drift event of PV %s failed, error: %v", pv.Name, err)
+	}
+}
+
+// updateObservedState records the observed storage pool and drift of the volume in the annotations of the PV,
+// the PV is only patched when they change
+func (dd *driftDetector) updateObservedState(ctx context.Context, pv *coreV1.PersistentVolume, pool, drift string) {
+	annotations := make(map[string]string)
+	if pv.Annotations[observedStoragePoolAnnotation] != pool {
+		annotations[observedStoragePoolAnnotation] = pool
+	}
+	if drift != "" && pv.Annotations[observedDriftAnnotation] != drift {
+		annotations[observedDriftAnnotation] = drift
+	}
+
+	if len(annotations) != 0 {
+		if err := dd.d.k8sUtils.UpdatePVAnnotations(ctx, pv.Name, annotations); err != nil {
+			log.AddContext(ctx).Warningf("Update annotations %v of PV %s failed, error: %v", annotations, pv.Name,
+				err)
+		}
+	}
+
+	if _, exist := pv.Annotations[observedDriftAnnotation]; exist && drift == "" {
+		log.AddContext(ctx).Infof("The drift of PV %s is resolved", pv.Name)
+		if err := dd.d.k8sUtils.RemovePVAnnotations(ctx, pv.Name, []string{observedDriftAnnotation}); err != nil {
+			log.AddContext(ctx).Warningf("Remove annotation %s of PV %s failed, error: %v", observedDriftAnnotation,
+				pv.Name, err)
+		}
+	}
+}
+
+// getVolumeDrifts returns the differences between the volume on the storage and the fields owned by the driver,
+// which are the size and the storage pool of the PV, and the QoS and hyperMetro requested by the StorageClass.
+// The volume larger than the PV is not a drift because the storage rounds up the size.
+func getVolumeDrifts(pv *coreV1.PersistentVolume, parameters map[string]string, state *plugin.VolumeState) []string {
+	var drifts []string
+	if size, ok := pv.Spec.Capacity[coreV1.ResourceStorage]; ok && state.Capacity < size.Value() {
+		drifts = append(drifts, fmt.Sprintf("the capacity %d bytes is smaller than the PV size %d bytes",
+			state.Capacity, size.Value()))
+	}
+
+	// the PVs created before the storage pool is recorded in the volume attributes are not compared
+	if pv.Spec.CSI != nil {
+		if pool := pv.Spec.CSI.VolumeAttributes[storagePoolAttribute]; pool != "" && pool != state.StoragePool {
+			drifts = append(drifts, fmt.Sprintf("the volume is moved from storage pool %s to %s", pool,
+				state.StoragePool))
+		}
+	}
+
+	if parameters["qos"] != "" && state.QoSPolicyID == "" {
+		drifts = append(drifts, "the QoS policy requested by the StorageClass is removed")
+	} else if parameters["qos"] != "" {
+		drifts = append(drifts, getQoSDrifts(parameters["qos"], state.QoS)...)
+	}
+
+	if strings.EqualFold(parameters["hyperMetro"], "true") && !state.HyperMetro {
+		drifts = append(drifts, "the hyperMetro pair requested by the StorageClass is removed")
+	}
+
+	return drifts
+}
+
+// getQoSDrifts returns the values of the QoS policy which differ from the qos parameter of the StorageClass,
+// the invalid qos parameter is rejected when the volume is created and is not compared
+func getQoSDrifts(qosConfig string, observed map[string]string) []string {
+	var requested map[string]float64
+	if err := json.Unmarshal([]byte(qosConfig), &requested); err != nil {
+		return nil
+	}
+
+	keys := make([]string, 0, len(requested))
+	for key := range requested {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var drifts []string
+	for _, key := range keys {
+		want := strconv.FormatFloat(requested[key], 'f', -1, 64)
+		if got := observed[key]; got != want {
+			drifts = append(drifts, fmt.Sprintf("the QoS %s is %q instead of %s requested by the StorageClass",
+				key, got, want))
+		}
+	}
+	return drifts
+}
@@ -0,0 +1,149 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package driver
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	coreV1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"huawei-csi-driver/csi/backend/plugin"
+	"huawei-csi-driver/utils/k8sutils"
+)
+
+func TestNextDriftSample(t *testing.T) {
+	var pvs []coreV1.PersistentVolume
+	for _, name := range []string{"pv-c", "pv-a", "pv-b"} {
+		pvs = append(pvs, coreV1.PersistentVolume{ObjectMeta: metaV1.ObjectMeta{Name: name}})
+	}
+
+	tests := []struct {
+		name       string
+		cursor     int
+		size       int
+		want       []string
+		wantCursor int
+	}{
+		{"First", 0, 2, []string{"pv-a", "pv-b"}, 2},
+		{"WrapAround", 2, 2, []string{"pv-c", "pv-a"}, 1},
+		{"All", 1, 5, []string{"pv-a", "pv-b", "pv-c"}, 0},
+		{"CursorOutOfRange", 5, 1, []string{"pv-a"}, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sample, cursor := nextDriftSample(pvs, tt.cursor, tt.size)
+			var got []string
+			for _, pv := range sample {
+				got = append(got, pv.Name)
+			}
+			if !reflect.DeepEqual(got, tt.want) || cursor != tt.wantCursor {
+				t.Errorf("nextDriftSample() = %v, %d, want %v, %d", got, cursor, tt.want, tt.wantCursor)
+			}
+		})
+	}
+}
+
+func TestGetVolumeDrifts(t *testing.T) {
+	pv := &coreV1.PersistentVolume{Spec: coreV1.PersistentVolumeSpec{
+		Capacity: coreV1.ResourceList{coreV1.ResourceStorage: resource.MustParse("10Gi")},
+		PersistentVolumeSource: coreV1.PersistentVolumeSource{CSI: &coreV1.CSIPersistentVolumeSource{
+			VolumeAttributes: map[string]string{"storagePool": "pool-1"}}},
+	}}
+	qos := map[string]string{"qos": `{"IOTYPE": 2, "MAXIOPS": 1000}`, "hyperMetro": "true"}
+	observedQoS := map[string]string{"IOTYPE": "2", "MAXIOPS": "1000", "NAME": "qos_1"}
+
+	tests := []struct {
+		name       string
+		parameters map[string]string
+		state      *plugin.VolumeState
+		want       int
+	}{
+		{"NoDrift", qos, &plugin.VolumeState{StoragePool: "pool-1", Capacity: 10 << 30, QoSPolicyID: "1",
+			QoS: observedQoS, HyperMetro: true}, 0},
+		{"LargerOnStorage", nil, &plugin.VolumeState{StoragePool: "pool-1", Capacity: 11 << 30}, 0},
+		{"Smaller", nil, &plugin.VolumeState{StoragePool: "pool-1", Capacity: 5 << 30}, 1},
+		{"MovedToOtherPool", nil, &plugin.VolumeState{StoragePool: "pool-2", Capacity: 10 << 30}, 1},
+		{"QoSAndHyperMetroRemoved", qos, &plugin.VolumeState{StoragePool: "pool-1", Capacity: 10 << 30}, 2},
+		{"QoSChanged", qos, &plugin.VolumeState{StoragePool: "pool-1", Capacity: 10 << 30, QoSPolicyID: "1",
+			QoS: map[string]string{"IOTYPE": "2", "MAXIOPS": "500"}, HyperMetro: true}, 1},
+		{"QoSNotRequested", nil, &plugin.VolumeState{StoragePool: "pool-1", Capacity: 10 << 30, QoSPolicyID: "1"},
+			0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := getVolumeDrifts(pv, tt.parameters, tt.state); len(got) != tt.want {
+				t.Errorf("getVolumeDrifts() = %v, want %d drifts", got, tt.want)
+			}
+		})
+	}
+}
+
+type fakeDriftK8sUtils struct {
+	k8sutils.Interface
+	updated map[string]string
+	removed []string
+}
+
+func (f *fakeDriftK8sUtils) UpdatePVAnnotations(_ context.Context, _ string, annotations map[string]string) error {
+	f.updated = annotations
+	return nil
+}
+
+func (f *fakeDriftK8sUtils) RemovePVAnnotations(_ context.Context, _ string, keys []string) error {
+	f.removed = keys
+	return nil
+}
+
+func TestUpdateObservedState(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		drift       string
+		wantUpdated map[string]string
+		wantRemoved []string
+	}{
+		{"Unchanged", map[string]string{observedStoragePoolAnnotation: "pool-1"}, "", nil, nil},
+		{"PoolChanged", map[string]string{observedStoragePoolAnnotation: "pool-2"}, "",
+			map[string]string{observedStoragePoolAnnotation: "pool-1"}, nil},
+		{"DriftDetected", map[string]string{observedStoragePoolAnnotation: "pool-1"}, "smaller",
+			map[string]string{observedDriftAnnotation: "smaller"}, nil},
+		{"SameDrift", map[string]string{observedStoragePoolAnnotation: "pool-1", observedDriftAnnotation: "smaller"},
+			"smaller", nil, nil},
+		{"DriftResolved", map[string]string{observedStoragePoolAnnotation: "pool-1",
+			observedDriftAnnotation: "smaller"}, "", nil, []string{observedDriftAnnotation}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			k8sUtils := &fakeDriftK8sUtils{}
+			dd := &driftDetector{d: &Driver{name: "csi.huawei.com", k8sUtils: k8sUtils}}
+			pv := &coreV1.PersistentVolume{ObjectMeta: metaV1.ObjectMeta{Name: "pv-1", Annotations: tt.annotations}}
+			dd.updateObservedState(context.Background(), pv, "pool-1", tt.drift)
+			if !reflect.DeepEqual(k8sUtils.updated, tt.wantUpdated) || !reflect.DeepEqual(k8sUtils.removed,
+				tt.wantRemoved) {
+				t.Errorf("updateObservedState() updated %v and removed %v, want %v and %v", k8sUtils.updated,
+					k8sUtils.removed, tt.wantUpdated, tt.wantRemoved)
+			}
+		})
+	}
+}
@@ -30,6 +30,8 @@ type Driver struct {
 	k8sUtils        k8sutils.Interface
 	nodeName        string
 	backendSelector handler.BackendSelectInterface
+	// whether the version of the node plugin is incompatible with the controller, 1 means incompatible
+	versionSkewed int32
 }
 
 // NewDriver used to inits a new driver
@@ -0,0 +1,148 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package driver
+
+import (
+	"context"
+
+	coreV1 "k8s.io/api/core/v1"
+
+	"huawei-csi-driver/csi/backend/model"
+	"huawei-csi-driver/csi/backend/plugin"
+	"huawei-csi-driver/csi/manage"
+	"huawei-csi-driver/pkg/constants"
+	"huawei-csi-driver/utils"
+	"huawei-csi-driver/utils/log"
+)
+
+// dTreeParentNameAttribute is the volume attribute of the parent filesystem of the DTree volume
+const dTreeParentNameAttribute = manage.DTreeParentNameKey
+
+// WatchDTreeMigration moves the DTree volumes to the filesystem specified by the PV annotation
+func (d *Driver) WatchDTreeMigration(ctx context.Context, stopCh <-chan struct{}) {
+	log.AddContext(ctx).Infoln("Start to watch DTree volume migration")
+	d.k8sUtils.WatchPersistentVolumes(ctx, func(pv *coreV1.PersistentVolume) {
+		d.migrateDTreeVolume(ctx, pv)
+	}, stopCh)
+}
+
+// getDTreeParentOfPV returns the parent filesystem of the DTree volume recorded on the PV, the annotation is
+// set once the volume is moved, the volume attribute is the parent which the volume is created under
+func getDTreeParentOfPV(pv *coreV1.PersistentVolume) string {
+	if parent, exist := pv.Annotations[constants.DTreeParentNameAnnotation]; exist {
+		return parent
+	}
+
+	if pv.Spec.CSI == nil {
+		return ""
+	}
+	return pv.Spec.CSI.VolumeAttributes[dTreeParentNameAttribute]
+}
+
+// getDTreeParentName returns the parent filesystem of the DTree volume, the PV is resolved by the volume handle
+// since the name of the volume on the storage differs from the PV name, the parent of the backend is used if
+// it is not recorded on the PV
+func (d *Driver) getDTreeParentName(ctx context.Context, volumeId string, backend *model.Backend) string {
+	backendParent, _ := utils.ToStringWithFlag(backend.Parameters["parentname"])
+	if d.k8sUtils == nil {
+		return backendParent
+	}
+
+	pv, err := d.k8sUtils.GetPVByVolumeHandle(ctx, d.name, volumeId)
+	if err != nil || pv == nil {
+		log.AddContext(ctx).Warningf("Get pv of volume %s failed, error: %v, use the parent %s of the backend",
+			volumeId, err, backendParent)
+		return backendParent
+	}
+
+	if parent := getDTreeParentOfPV(pv); parent != "" {
+		return parent
+	}
+	return backendParent
+}
+
+// getDTreeMigration returns the current and the target parent of the DTree volume,
+// the empty target means that the volume does not need to be moved
+func getDTreeMigration(pv *coreV1.PersistentVolume, driverName string) (string, string) {
+	if pv.Spec.CSI == nil || pv.Spec.CSI.Driver != driverName {
+		return "", ""
+	}
+
+	target := pv.Annotations[constants.DTreeTargetParentAnnotation]
+	current := getDTreeParentOfPV(pv)
+	if target == "" || current == "" || target == current {
+		return current, ""
+	}
+
+	return current, target
+}
+
+func (d *Driver) migrateDTreeVolume(ctx context.Context, pv *coreV1.PersistentVolume) {
+	current, target := getDTreeMigration(pv, d.name)
+	if target == "" {
+		return
+	}
+
+	volumeId := pv.Spec.CSI.VolumeHandle
+	backendName, volName := utils.SplitVolumeId(volumeId)
+	backend, err := d.backendSelector.SelectBackend(ctx, backendName)
+	if backend == nil || err != nil {
+		log.AddContext(ctx).Errorf("Backend %s of volume %s doesn't exist, error: %v", backendName, volumeId, err)
+		return
+	}
+
+	migrator, ok := backend.Plugin.(plugin.VolumeMigrator)
+	if !ok {
+		log.AddContext(ctx).Warningf("Backend %s of volume %s does not support annotation %s",
+			backendName, volumeId, constants.DTreeTargetParentAnnotation)
+		return
+	}
+
+	// the move is retried when the PV is resynchronized after the maintenance ends
+	if err = d.checkBackendMaintenance(ctx, backend, volName, "moving volume"); err != nil {
+		return
+	}
+
+	// the mounts of a published volume keep the path under the previous parent, so it is moved only when it is
+	// not attached to any node, the move is retried when the PV is resynchronized
+	nodes, err := d.k8sUtils.GetVolumeAttachingNodes(ctx, d.name, volumeId)
+	if err != nil {
+		log.AddContext(ctx).Errorf("Get attached nodes of volume %s error: %v", volumeId, err)
+		return
+	}
+	if len(nodes) != 0 {
+		log.AddContext(ctx).Warningf("Volume %s is published to nodes %v, it is moved to %s after it is "+
+			"unpublished from all nodes", volumeId, nodes, target)
+		return
+	}
+
+	log.AddContext(ctx).Infof("Start to move volume %s from %s to %s", volumeId, current, target)
+	if err = migrator.MigrateVolume(ctx, volName, current, target); err != nil {
+		log.AddContext(ctx).Errorf("Move volume %s from %s to %s error: %v", volumeId, current, target, err)
+		return
+	}
+
+	err = d.k8sUtils.UpdatePVAnnotations(ctx, pv.Name, map[string]string{
+		constants.DTreeParentNameAnnotation: target,
+	})
+	if err != nil {
+		log.AddContext(ctx).Errorf("Update parent of volume %s to %s error: %v", volumeId, target, err)
+		return
+	}
+
+	log.AddContext(ctx).Infof("Volume %s is moved from %s to %s", volumeId, current, target)
+}
@@ -0,0 +1,221 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package driver
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	coreV1 "k8s.io/api/core/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"huawei-csi-driver/csi/backend/model"
+	"huawei-csi-driver/csi/backend/plugin"
+	"huawei-csi-driver/pkg/constants"
+	"huawei-csi-driver/utils/k8sutils"
+)
+
+type fakeDTreeK8sUtils struct {
+	k8sutils.Interface
+	pv          *coreV1.PersistentVolume
+	nodes       []string
+	annotateErr error
+	annotated   map[string]string
+}
+
+func (f *fakeDTreeK8sUtils) GetPVByVolumeHandle(_ context.Context, _, volumeHandle string) (
+	*coreV1.PersistentVolume, error) {
+	if f.pv == nil || f.pv.Spec.CSI.VolumeHandle != volumeHandle {
+		return nil, nil
+	}
+	return f.pv, nil
+}
+
+func (f *fakeDTreeK8sUtils) GetVolumeAttachingNodes(context.Context, string, string) ([]string, error) {
+	return f.nodes, nil
+}
+
+func (f *fakeDTreeK8sUtils) UpdatePVAnnotations(_ context.Context, _ string, annotations map[string]string) error {
+	if f.annotateErr != nil {
+		return f.annotateErr
+	}
+	f.annotated = annotations
+	return nil
+}
+
+type fakeDTreeMigratorPlugin struct {
+	plugin.Plugin
+	moves int
+}
+
+func (p *fakeDTreeMigratorPlugin) MigrateVolume(context.Context, string, string, string) error {
+	p.moves++
+	return nil
+}
+
+func TestGetDTreeMigration(t *testing.T) {
+	newPV := func(driver string, annotations map[string]string) *coreV1.PersistentVolume {
+		return &coreV1.PersistentVolume{
+			ObjectMeta: metaV1.ObjectMeta{Name: "pv", Annotations: annotations},
+			Spec: coreV1.PersistentVolumeSpec{
+				PersistentVolumeSource: coreV1.PersistentVolumeSource{
+					CSI: &coreV1.CSIPersistentVolumeSource{
+						Driver:           driver,
+						VolumeHandle:     "backend.dtree",
+						VolumeAttributes: map[string]string{dTreeParentNameAttribute: "fs1"},
+					},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name        string
+		pv          *coreV1.PersistentVolume
+		wantCurrent string
+		wantTarget  string
+	}{
+		{"NoAnnotation", newPV("csi.huawei.com", nil), "fs1", ""},
+		{"OtherDriver", newPV("other.csi.com",
+			map[string]string{constants.DTreeTargetParentAnnotation: "fs2"}), "", ""},
+		{"MoveFromAttribute", newPV("csi.huawei.com",
+			map[string]string{constants.DTreeTargetParentAnnotation: "fs2"}), "fs1", "fs2"},
+		{"AlreadyMoved", newPV("csi.huawei.com", map[string]string{
+			constants.DTreeTargetParentAnnotation: "fs2",
+			constants.DTreeParentNameAnnotation:   "fs2"}), "fs2", ""},
+		{"MoveFromAnnotation", newPV("csi.huawei.com", map[string]string{
+			constants.DTreeTargetParentAnnotation: "fs3",
+			constants.DTreeParentNameAnnotation:   "fs2"}), "fs2", "fs3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current, target := getDTreeMigration(tt.pv, "csi.huawei.com")
+			if current != tt.wantCurrent || target != tt.wantTarget {
+				t.Errorf("getDTreeMigration() = (%s, %s), want (%s, %s)",
+					current, target, tt.wantCurrent, tt.wantTarget)
+			}
+		})
+	}
+}
+
+func TestGetDTreeParentOfPV(t *testing.T) {
+	newPV := func(annotations, attributes map[string]string) *coreV1.PersistentVolume {
+		return &coreV1.PersistentVolume{
+			ObjectMeta: metaV1.ObjectMeta{Name: "pv", Annotations: annotations},
+			Spec: coreV1.PersistentVolumeSpec{
+				PersistentVolumeSource: coreV1.PersistentVolumeSource{
+					CSI: &coreV1.CSIPersistentVolumeSource{VolumeAttributes: attributes},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name string
+		pv   *coreV1.PersistentVolume
+		want string
+	}{
+		{"NotRecorded", newPV(nil, nil), ""},
+		{"CreatedUnder", newPV(nil, map[string]string{dTreeParentNameAttribute: "fs1"}), "fs1"},
+		{"MovedTo", newPV(map[string]string{constants.DTreeParentNameAnnotation: "fs2"},
+			map[string]string{dTreeParentNameAttribute: "fs1"}), "fs2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := getDTreeParentOfPV(tt.pv); got != tt.want {
+				t.Errorf("getDTreeParentOfPV() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func newDTreeMigrationPV(annotations map[string]string) *coreV1.PersistentVolume {
+	return &coreV1.PersistentVolume{
+		ObjectMeta: metaV1.ObjectMeta{Name: "pvc-1", Annotations: annotations},
+		Spec: coreV1.PersistentVolumeSpec{
+			PersistentVolumeSource: coreV1.PersistentVolumeSource{
+				CSI: &coreV1.CSIPersistentVolumeSource{
+					Driver:           "csi.huawei.com",
+					VolumeHandle:     "dtree.prefix_1",
+					VolumeAttributes: map[string]string{dTreeParentNameAttribute: "fs1"},
+				},
+			},
+		},
+	}
+}
+
+func TestMigrateDTreeVolume(t *testing.T) {
+	tests := []struct {
+		name          string
+		nodes         []string
+		annotateErr   error
+		wantMoves     int
+		wantAnnotated bool
+	}{
+		{"Unpublished", nil, nil, 1, true},
+		{"Published", []string{"node-1"}, nil, 0, false},
+		{"RecordFailed", nil, errors.New("update failed"), 1, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			migrator := &fakeDTreeMigratorPlugin{}
+			k8sUtils := &fakeDTreeK8sUtils{nodes: tt.nodes, annotateErr: tt.annotateErr}
+			d := &Driver{name: "csi.huawei.com", k8sUtils: k8sUtils,
+				backendSelector: &fakeBackendSelector{backends: map[string]*model.Backend{
+					"dtree": {Name: "dtree", Storage: plugin.DTreeStorage, Plugin: migrator},
+				}}}
+
+			d.migrateDTreeVolume(context.Background(),
+				newDTreeMigrationPV(map[string]string{constants.DTreeTargetParentAnnotation: "fs2"}))
+			if migrator.moves != tt.wantMoves {
+				t.Errorf("migrateDTreeVolume() moves = %d, want %d", migrator.moves, tt.wantMoves)
+			}
+			if (k8sUtils.annotated[constants.DTreeParentNameAnnotation] == "fs2") != tt.wantAnnotated {
+				t.Errorf("migrateDTreeVolume() annotated = %v, want annotated %v", k8sUtils.annotated,
+					tt.wantAnnotated)
+			}
+		})
+	}
+}
+
+func TestGetDTreeParentName(t *testing.T) {
+	backend := &model.Backend{Name: "dtree", Parameters: map[string]interface{}{"parentname": "backend-fs"}}
+	tests := []struct {
+		name     string
+		pv       *coreV1.PersistentVolume
+		volumeId string
+		want     string
+	}{
+		{"CreatedUnder", newDTreeMigrationPV(nil), "dtree.prefix_1", "fs1"},
+		{"MovedTo", newDTreeMigrationPV(map[string]string{constants.DTreeParentNameAnnotation: "fs2"}),
+			"dtree.prefix_1", "fs2"},
+		{"PVNotFound", newDTreeMigrationPV(nil), "dtree.prefix_2", "backend-fs"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &Driver{name: "csi.huawei.com", k8sUtils: &fakeDTreeK8sUtils{pv: tt.pv}}
+			if got := d.getDTreeParentName(context.Background(), tt.volumeId, backend); got != tt.want {
+				t.Errorf("getDTreeParentName() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
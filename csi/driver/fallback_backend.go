@@ -0,0 +1,186 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package driver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	coreV1 "k8s.io/api/core/v1"
+
+	"huawei-csi-driver/cli/helper"
+	"huawei-csi-driver/csi/backend"
+	"huawei-csi-driver/csi/backend/model"
+	"huawei-csi-driver/utils/log"
+)
+
+const (
+	fallbackBackendKey    = "fallbackBackend"
+	fallbackBackendReason = "FallbackBackendSelected"
+
+	fallbackStoragePoolKey    = "fallbackStoragePool"
+	fallbackStoragePoolReason = "FallbackStoragePoolSelected"
+)
+
+// getFallbackCause returns why the pools of the primary backend can't be selected, only the backend being
+// offline or out of capacity allows falling back, the other errors mean misconfiguration and are returned as is
+func (d *Driver) getFallbackCause(ctx context.Context, primaryBackend string, selectErr error) (string, bool) {
+	if errors.Is(selectErr, backend.ErrInsufficientCapacity) {
+		return "out of capacity", true
+	}
+
+	if d.backendSelector.IsBackendOffline(ctx, primaryBackend) {
+		return "offline", true
+	}
+
+	return "", false
+}
+
+// canFallbackPool checks whether the volume is allowed to be provisioned on the fallback pool of the same backend
+func canFallbackPool(parameters map[string]interface{}) bool {
+	fallbackPool, _ := parameters[fallbackStoragePoolKey].(string)
+	preferredPool, _ := parameters["storagepool"].(string)
+	return fallbackPool != "" && preferredPool != "" && fallbackPool != preferredPool
+}
+
+// selectFallbackPool retries on the fallback pool of the same backend if the preferred pool is out of capacity,
+// the error of the preferred pool is returned if there is no fallback pool. The fallback pool must have enough
+// free capacity if the volume is only moved off an overcommitted thin pool
+func (d *Driver) selectFallbackPool(ctx context.Context, req *csi.CreateVolumeRequest,
+	parameters map[string]interface{}, selectErr error, requireFreeCapacity bool) (*model.SelectPoolPair, error) {
+	if !canFallbackPool(parameters) || !errors.Is(selectErr, backend.ErrInsufficientCapacity) {
+		return nil, selectErr
+	}
+
+	fallbackPool, _ := parameters[fallbackStoragePoolKey].(string)
+	preferredPool, _ := parameters["storagepool"].(string)
+	log.AddContext(ctx).Warningf("Pool %s of volume %s is out of capacity, select fallback pool %s, error: %v",
+		preferredPool, req.GetName(), fallbackPool, selectErr)
+	parameters["storagepool"] = fallbackPool
+	requestSize := req.GetCapacityRange().RequiredBytes
+	poolPair, err := d.backendSelector.SelectPoolPair(ctx, requestSize, parameters)
+	if err == nil && requireFreeCapacity && exceedsFreeCapacity(poolPair.Local, requestSize) {
+		err = fmt.Errorf("the volume exceeds the free capacity %s of pool %s",
+			poolPair.Local.GetCapacity("FreeCapacity"), fallbackPool)
+	}
+	if err != nil {
+		parameters["storagepool"] = preferredPool
+		return nil, fmt.Errorf("%w, and select fallback pool %s failed, error: %v", selectErr, fallbackPool, err)
+	}
+
+	d.recordPVCEvent(ctx, req.GetName(), coreV1.EventTypeWarning, fallbackStoragePoolReason,
+		fmt.Sprintf("Pool %s is out of capacity, the volume is provisioned on the fallback pool %s of backend %s",
+			preferredPool, fallbackPool, poolPair.Local.Parent))
+	return poolPair, nil
+}
+
+// exceedsFreeCapacity checks whether the volume exceeds the free capacity of the selected pool, which only happens
+// to the thin volumes since the thin pools are allowed to be overcommitted
+func exceedsFreeCapacity(pool *model.StoragePool, requestSize int64) bool {
+	freeCapacity, err := strconv.ParseInt(pool.GetCapacity("FreeCapacity"), 10, 64)
+	return err == nil && requestSize > freeCapacity
+}
+
+// canFallbackBackend checks whether the volume is allowed to be provisioned on the fallback backend,
+// the cloned volumes must be on the backend of the source
+func canFallbackBackend(req *csi.CreateVolumeRequest, parameters map[string]interface{}) bool {
+	fallbackBackend, _ := parameters[fallbackBackendKey].(string)
+	primaryBackend, _ := parameters["backend"].(string)
+	cloneFrom, _ := parameters["cloneFrom"].(string)
+	return fallbackBackend != "" && primaryBackend != "" && cloneFrom == "" && req.GetVolumeContentSource() == nil
+}
+
+// selectPoolPair selects the pools on the backend of the StorageClass, and retries after expanding a pool
+// automatically or on its fallback pool if the preferred pool is out of capacity, then on its fallback backend if
+// the backend is offline or out of capacity. A thin volume exceeding the free capacity of the selected pool is
+// provisioned on the fallback if there is one, and is still provisioned on the overcommitted pool otherwise
+func (d *Driver) selectPoolPair(ctx context.Context, req *csi.CreateVolumeRequest,
+	parameters map[string]interface{}) (*model.SelectPoolPair, error) {
+	requestSize := req.GetCapacityRange().RequiredBytes
+	poolPair, err := d.backendSelector.SelectPoolPair(ctx, requestSize, parameters)
+	var overcommitted *model.SelectPoolPair
+	if err == nil {
+		canFallback := canFallbackPool(parameters) || canFallbackBackend(req, parameters)
+		if !canFallback || !exceedsFreeCapacity(poolPair.Local, requestSize) {
+			return poolPair, nil
+		}
+
+		overcommitted = poolPair
+		err = fmt.Errorf("%w: volume %s exceeds the free capacity %s of thin pool %s of backend %s",
+			backend.ErrInsufficientCapacity, req.GetName(), poolPair.Local.GetCapacity("FreeCapacity"),
+			poolPair.Local.Name, poolPair.Local.Parent)
+	} else {
+		poolPair, err = d.selectExpandedPool(ctx, req, parameters, err)
+		if err == nil {
+			return poolPair, nil
+		}
+	}
+
+	poolPair, err = d.selectFallbackPool(ctx, req, parameters, err, overcommitted != nil)
+	if err == nil {
+		return poolPair, nil
+	}
+
+	poolPair, err = d.selectFallbackBackend(ctx, req, parameters, err, overcommitted != nil)
+	if err != nil && overcommitted != nil {
+		log.AddContext(ctx).Warningf("Provision thin volume %s on the overcommitted pool %s of backend %s, "+
+			"error: %v", req.GetName(), overcommitted.Local.Name, overcommitted.Local.Parent, err)
+		return overcommitted, nil
+	}
+
+	return poolPair, err
+}
+
+// selectFallbackBackend retries on the fallback backend if the primary backend is offline or out of capacity,
+// the error of the primary backend is returned if it can't fall back. The fallback pool must have enough free
+// capacity if the volume is only moved off an overcommitted thin pool
+func (d *Driver) selectFallbackBackend(ctx context.Context, req *csi.CreateVolumeRequest,
+	parameters map[string]interface{}, err error, requireFreeCapacity bool) (*model.SelectPoolPair, error) {
+	if !canFallbackBackend(req, parameters) {
+		return nil, err
+	}
+
+	primaryBackend, _ := parameters["backend"].(string)
+	cause, fallback := d.getFallbackCause(ctx, primaryBackend, err)
+	if !fallback {
+		return nil, err
+	}
+
+	fallbackBackend := helper.GetBackendName(parameters[fallbackBackendKey].(string))
+	log.AddContext(ctx).Warningf("Backend %s of volume %s is %s, select pool on fallback backend %s, error: %v",
+		primaryBackend, req.GetName(), cause, fallbackBackend, err)
+	parameters["backend"] = fallbackBackend
+	requestSize := req.GetCapacityRange().RequiredBytes
+	poolPair, fallbackErr := d.backendSelector.SelectPoolPair(ctx, requestSize, parameters)
+	if fallbackErr == nil && requireFreeCapacity && exceedsFreeCapacity(poolPair.Local, requestSize) {
+		fallbackErr = fmt.Errorf("the volume exceeds the free capacity %s of pool %s",
+			poolPair.Local.GetCapacity("FreeCapacity"), poolPair.Local.Name)
+	}
+	if fallbackErr != nil {
+		parameters["backend"] = primaryBackend
+		return nil, fmt.Errorf("%v, and select pool on fallback backend %s failed, error: %v",
+			err, fallbackBackend, fallbackErr)
+	}
+
+	d.recordPVCEvent(ctx, req.GetName(), coreV1.EventTypeWarning, fallbackBackendReason,
+		fmt.Sprintf("Backend %s is %s, the volume is provisioned on the fallback backend %s",
+			primaryBackend, cause, fallbackBackend))
+	return poolPair, nil
+}
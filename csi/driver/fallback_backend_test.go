@@ -0,0 +1,261 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package driver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+
+	"huawei-csi-driver/csi/backend"
+	"huawei-csi-driver/csi/backend/handler"
+	"huawei-csi-driver/csi/backend/model"
+	"huawei-csi-driver/csi/backend/plugin"
+)
+
+type fakeFallbackSelector struct {
+	handler.BackendSelectInterface
+	selectErrs map[string]error
+	poolErrs   map[string]error
+	offline    map[string]bool
+	expandable *model.StoragePool
+	// the free capacities of the selected pools keyed by pool, or by backend if the pool is not specified
+	freeCapacities map[string]string
+}
+
+func (s *fakeFallbackSelector) SelectPoolPair(_ context.Context, _ int64, params map[string]interface{}) (
+	*model.SelectPoolPair, error) {
+	name, _ := params["backend"].(string)
+	if err := s.selectErrs[name]; err != nil {
+		return nil, err
+	}
+
+	pool, _ := params["storagepool"].(string)
+	if err := s.poolErrs[pool]; err != nil {
+		return nil, err
+	}
+	freeCapacity, exist := s.freeCapacities[pool]
+	if !exist {
+		freeCapacity = s.freeCapacities[name]
+	}
+	return &model.SelectPoolPair{Local: &model.StoragePool{Name: pool, Parent: name,
+		Capacities: map[string]string{"FreeCapacity": freeCapacity}}}, nil
+}
+
+func (s *fakeFallbackSelector) SelectExpandablePool(context.Context, map[string]interface{}) (
+	*model.StoragePool, error) {
+	return s.expandable, nil
+}
+
+func (s *fakeFallbackSelector) IsBackendOffline(_ context.Context, name string) bool {
+	return s.offline[name]
+}
+
+func TestSelectPoolPairWithFallback(t *testing.T) {
+	capacityErr := fmt.Errorf("%s: %w", backend.NoAvailablePool, backend.ErrInsufficientCapacity)
+	tests := []struct {
+		name        string
+		selector    *fakeFallbackSelector
+		wantBackend string
+		wantErr     bool
+	}{
+		{"PrimarySelected", &fakeFallbackSelector{}, "primary", false},
+		{"PrimaryOutOfCapacity",
+			&fakeFallbackSelector{selectErrs: map[string]error{"primary": capacityErr}}, "fallback", false},
+		{"PrimaryOffline", &fakeFallbackSelector{selectErrs: map[string]error{"primary": errors.New("no pool")},
+			offline: map[string]bool{"primary": true}}, "fallback", false},
+		{"Misconfiguration", &fakeFallbackSelector{selectErrs: map[string]error{"primary": errors.New("no pool")}},
+			"", true},
+		{"FallbackFailed", &fakeFallbackSelector{selectErrs: map[string]error{"primary": capacityErr,
+			"fallback": capacityErr}}, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &Driver{backendSelector: tt.selector}
+			parameters := map[string]interface{}{"backend": "primary", fallbackBackendKey: "fallback"}
+			req := &csi.CreateVolumeRequest{Name: "pvc-test", CapacityRange: &csi.CapacityRange{RequiredBytes: 1}}
+			got, err := d.selectPoolPair(context.Background(), req, parameters)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("selectPoolPair() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && got.Local.Parent != tt.wantBackend {
+				t.Errorf("selectPoolPair() backend = %s, want %s", got.Local.Parent, tt.wantBackend)
+			}
+		})
+	}
+}
+
+func TestSelectPoolPairWithFallbackPool(t *testing.T) {
+	capacityErr := fmt.Errorf("%s: %w", backend.NoAvailablePool, backend.ErrInsufficientCapacity)
+	tests := []struct {
+		name     string
+		selector *fakeFallbackSelector
+		wantPool string
+		wantErr  bool
+	}{
+		{"PreferredSelected", &fakeFallbackSelector{}, "preferred", false},
+		{"PreferredOutOfCapacity",
+			&fakeFallbackSelector{poolErrs: map[string]error{"preferred": capacityErr}}, "fallback", false},
+		{"Misconfiguration", &fakeFallbackSelector{poolErrs: map[string]error{"preferred": errors.New("no pool")}},
+			"", true},
+		{"FallbackFailed", &fakeFallbackSelector{poolErrs: map[string]error{"preferred": capacityErr,
+			"fallback": capacityErr}}, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &Driver{backendSelector: tt.selector}
+			parameters := map[string]interface{}{"backend": "primary", "storagepool": "preferred",
+				fallbackStoragePoolKey: "fallback"}
+			req := &csi.CreateVolumeRequest{Name: "pvc-test", CapacityRange: &csi.CapacityRange{RequiredBytes: 1}}
+			got, err := d.selectPoolPair(context.Background(), req, parameters)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("selectPoolPair() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && got.Local.Name != tt.wantPool {
+				t.Errorf("selectPoolPair() pool = %s, want %s", got.Local.Name, tt.wantPool)
+			}
+		})
+	}
+}
+
+func TestSelectPoolPairOfThinVolumeWithFallback(t *testing.T) {
+	tests := []struct {
+		name           string
+		freeCapacities map[string]string
+		wantBackend    string
+	}{
+		{"PrimaryHasFreeCapacity", map[string]string{"primary": "1024", "fallback": "1024"}, "primary"},
+		{"PrimaryOvercommitted", map[string]string{"primary": "0", "fallback": "1024"}, "fallback"},
+		{"BothOvercommitted", map[string]string{"primary": "0", "fallback": "0"}, "primary"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &Driver{backendSelector: &fakeFallbackSelector{freeCapacities: tt.freeCapacities}}
+			parameters := map[string]interface{}{"backend": "primary", "allocType": "thin",
+				fallbackBackendKey: "fallback"}
+			req := &csi.CreateVolumeRequest{Name: "pvc-test", CapacityRange: &csi.CapacityRange{RequiredBytes: 512}}
+			got, err := d.selectPoolPair(context.Background(), req, parameters)
+			if err != nil {
+				t.Fatalf("selectPoolPair() error = %v", err)
+			}
+			if got.Local.Parent != tt.wantBackend {
+				t.Errorf("selectPoolPair() backend = %s, want %s", got.Local.Parent, tt.wantBackend)
+			}
+		})
+	}
+}
+
+func TestSelectPoolPairOfThinVolumeWithFallbackPool(t *testing.T) {
+	tests := []struct {
+		name           string
+		freeCapacities map[string]string
+		wantPool       string
+	}{
+		{"PreferredHasFreeCapacity", map[string]string{"preferred": "1024", "fallback": "1024"}, "preferred"},
+		{"PreferredOvercommitted", map[string]string{"preferred": "0", "fallback": "1024"}, "fallback"},
+		{"BothOvercommitted", map[string]string{"preferred": "0", "fallback": "0"}, "preferred"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &Driver{backendSelector: &fakeFallbackSelector{freeCapacities: tt.freeCapacities}}
+			parameters := map[string]interface{}{"backend": "primary", "storagepool": "preferred",
+				"allocType": "thin", fallbackStoragePoolKey: "fallback"}
+			req := &csi.CreateVolumeRequest{Name: "pvc-test", CapacityRange: &csi.CapacityRange{RequiredBytes: 512}}
+			got, err := d.selectPoolPair(context.Background(), req, parameters)
+			if err != nil {
+				t.Fatalf("selectPoolPair() error = %v", err)
+			}
+			if got.Local.Name != tt.wantPool {
+				t.Errorf("selectPoolPair() pool = %s, want %s", got.Local.Name, tt.wantPool)
+			}
+		})
+	}
+}
+
+type fakePoolExpander struct {
+	plugin.Plugin
+	selector *fakeFallbackSelector
+	err      error
+}
+
+func (p *fakePoolExpander) ExpandStoragePool(_ context.Context, _ string, additional int64) (int64, int64, error) {
+	if p.err != nil {
+		return 0, 0, p.err
+	}
+
+	delete(p.selector.selectErrs, "primary")
+	return 0, additional, nil
+}
+
+func TestSelectPoolPairWithPoolExpansion(t *testing.T) {
+	capacityErr := fmt.Errorf("%s: %w", backend.NoAvailablePool, backend.ErrInsufficientCapacity)
+	tests := []struct {
+		name      string
+		expandErr error
+		wantErr   bool
+	}{
+		{"Expanded", nil, false},
+		{"ExpandFailed", errors.New("no spare disk"), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selector := &fakeFallbackSelector{selectErrs: map[string]error{"primary": capacityErr}}
+			selector.expandable = &model.StoragePool{Name: "pool1", Parent: "primary",
+				Capacities: map[string]string{"FreeCapacity": "0"},
+				Plugin:     &fakePoolExpander{selector: selector, err: tt.expandErr}}
+			d := &Driver{backendSelector: selector}
+			parameters := map[string]interface{}{"backend": "primary"}
+			req := &csi.CreateVolumeRequest{Name: "pvc-test", CapacityRange: &csi.CapacityRange{RequiredBytes: 1}}
+			got, err := d.selectPoolPair(context.Background(), req, parameters)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("selectPoolPair() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && got.Local.Parent != "primary" {
+				t.Errorf("selectPoolPair() backend = %s, want primary", got.Local.Parent)
+			}
+			if err != nil && !errors.Is(err, backend.ErrInsufficientCapacity) {
+				t.Errorf("selectPoolPair() error = %v, want wrapping ErrInsufficientCapacity", err)
+			}
+		})
+	}
+}
+
+func TestSelectPoolPairWhilePoolExpanding(t *testing.T) {
+	capacityErr := fmt.Errorf("%s: %w", backend.NoAvailablePool, backend.ErrInsufficientCapacity)
+	selector := &fakeFallbackSelector{selectErrs: map[string]error{"primary": capacityErr}}
+	selector.expandable = &model.StoragePool{Name: "pool-expanding", Parent: "primary",
+		Capacities: map[string]string{"FreeCapacity": "0"},
+		Plugin:     &fakePoolExpander{selector: selector}}
+	expansion := getPoolExpansion(selector.expandable)
+	expansion.mutex.Lock()
+	defer expansion.mutex.Unlock()
+
+	d := &Driver{backendSelector: selector}
+	req := &csi.CreateVolumeRequest{Name: "pvc-test", CapacityRange: &csi.CapacityRange{RequiredBytes: 1}}
+	_, err := d.selectPoolPair(context.Background(), req, map[string]interface{}{"backend": "primary"})
+	if !errors.Is(err, errPoolExpanding) {
+		t.Errorf("selectPoolPair() error = %v, want wrapping errPoolExpanding", err)
+	}
+}
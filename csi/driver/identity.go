@@ -19,6 +19,8 @@ package driver
 import (
 	"context"
 
+	"huawei-csi-driver/csi/app"
+	"huawei-csi-driver/csi/backend/cache"
 	"huawei-csi-driver/utils/log"
 
 	"github.com/container-storage-interface/spec/lib/go/csi"
@@ -30,6 +32,7 @@ func (d *Driver) GetPluginInfo(ctx context.Context, req *csi.GetPluginInfoReques
 	return &csi.GetPluginInfoResponse{
 		Name:          d.name,
 		VendorVersion: d.version,
+		Manifest:      pluginManifest(app.GetGlobalConfig(), cache.BackendCacheProvider.List(ctx)),
 	}, nil
 }
 
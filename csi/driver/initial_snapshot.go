@@ -0,0 +1,139 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package driver
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	coreV1 "k8s.io/api/core/v1"
+
+	"huawei-csi-driver/csi/backend/model"
+	"huawei-csi-driver/utils"
+	"huawei-csi-driver/utils/log"
+)
+
+const (
+	// snapshotOnCreateKey is the StorageClass parameter to take the initial snapshot of the LUN right after
+	// it is created, so that the initial data state is recoverable before any workload touches the volume
+	snapshotOnCreateKey = "snapshotOnCreate"
+
+	initialSnapshotSuffix = "-initial"
+	// initialSnapshotHashLength keeps the initial snapshot name within the 31 characters of the storage
+	initialSnapshotHashLength = 23
+
+	sanStorage = "oceanstor-san"
+
+	initialSnapshotCreatedReason = "InitialSnapshotCreated"
+	initialSnapshotFailedReason  = "InitialSnapshotFailed"
+)
+
+// checkSnapshotOnCreate checks the snapshotOnCreate parameter, which is only supported by the LUN volumes
+func checkSnapshotOnCreate(ctx context.Context, parameters map[string]interface{}) error {
+	value, exist := parameters[snapshotOnCreateKey].(string)
+	if !exist {
+		return nil
+	}
+
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		return utils.Errorf(ctx, "StorageClass parameter \"%s\": [%s] invalid, it must be true or false",
+			snapshotOnCreateKey, value)
+	}
+
+	if volumeType, _ := parameters["volumeType"].(string); enabled && volumeType != "" && volumeType != volumeTypeLun {
+		return utils.Errorf(ctx, "StorageClass parameter \"%s\" is only supported by the volumeType %s, "+
+			"but the volumeType is %s", snapshotOnCreateKey, volumeTypeLun, volumeType)
+	}
+
+	return nil
+}
+
+func isSnapshotOnCreate(parameters map[string]string) bool {
+	enabled, _ := strconv.ParseBool(parameters[snapshotOnCreateKey])
+	return enabled
+}
+
+// initialSnapshotName returns the name of the initial snapshot of the volume, the snapshot names of the storage
+// are limited to 31 characters, so the volume name is shortened to its hash to keep the suffix and the names of
+// different volumes distinct
+func initialSnapshotName(volumeName string) string {
+	sum := sha256.Sum256([]byte(volumeName))
+	return hex.EncodeToString(sum[:])[:initialSnapshotHashLength] + initialSnapshotSuffix
+}
+
+// createInitialSnapshot takes the initial snapshot of the created LUN through CreateSnapshot. The failure is
+// reported as a warning event of the PVC and does not fail the creation, since the volume is usable without it.
+func (d *Driver) createInitialSnapshot(ctx context.Context, pvName, volumeId string, pool *model.StoragePool) {
+	if pool.Storage != sanStorage {
+		d.recordPVCEvent(ctx, pvName, coreV1.EventTypeWarning, initialSnapshotFailedReason,
+			fmt.Sprintf("StorageClass parameter \"%s\" is not supported by the %s backend %s",
+				snapshotOnCreateKey, pool.Storage, pool.Parent))
+		return
+	}
+
+	_, volName := utils.SplitVolumeId(volumeId)
+	snapshotName := initialSnapshotName(volName)
+	res, err := d.CreateSnapshot(ctx, &csi.CreateSnapshotRequest{SourceVolumeId: volumeId, Name: snapshotName})
+	if err != nil {
+		log.AddContext(ctx).Errorf("Create initial snapshot of volume %s error: %v", volumeId, err)
+		d.recordPVCEvent(ctx, pvName, coreV1.EventTypeWarning, initialSnapshotFailedReason,
+			fmt.Sprintf("Create initial snapshot %s failed: %v", snapshotName, err))
+		return
+	}
+
+	creationTime := time.Unix(res.GetSnapshot().GetCreationTime().GetSeconds(), 0).UTC()
+	d.recordPVCEvent(ctx, pvName, coreV1.EventTypeNormal, initialSnapshotCreatedReason,
+		fmt.Sprintf("Initial snapshot %s is created at %s", snapshotName, creationTime.Format(time.RFC3339)))
+}
+
+// deleteInitialSnapshot deletes the initial snapshot before deleting the LUN, which can not be deleted with
+// its snapshots. Only the volumes created with snapshotOnCreate have it, and it does nothing if the initial
+// snapshot does not exist.
+func (d *Driver) deleteInitialSnapshot(ctx context.Context, bk *model.Backend, volumeId string) error {
+	if bk.Storage != sanStorage || !d.hasInitialSnapshot(ctx, volumeId) {
+		return nil
+	}
+
+	_, volName := utils.SplitVolumeId(volumeId)
+	return bk.Plugin.DeleteSnapshot(ctx, "", initialSnapshotName(volName))
+}
+
+// hasInitialSnapshot returns whether the volume is created with snapshotOnCreate, which is recorded in the
+// volume attributes. The deletion is tried when the PV can't be got, since the name is only used by the driver.
+func (d *Driver) hasInitialSnapshot(ctx context.Context, volumeId string) bool {
+	if d.k8sUtils == nil {
+		return false
+	}
+
+	pv, err := d.k8sUtils.GetPVByVolumeHandle(ctx, d.name, volumeId)
+	if err != nil {
+		log.AddContext(ctx).Warningf("Get pv of volume %s failed, try to delete its initial snapshot, error: %v",
+			volumeId, err)
+		return true
+	}
+	if pv == nil || pv.Spec.CSI == nil {
+		return false
+	}
+
+	return isSnapshotOnCreate(pv.Spec.CSI.VolumeAttributes)
+}
@@ -0,0 +1,129 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package driver
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	coreV1 "k8s.io/api/core/v1"
+
+	"huawei-csi-driver/csi/backend/model"
+	"huawei-csi-driver/csi/backend/plugin"
+	"huawei-csi-driver/utils"
+)
+
+func TestCheckSnapshotOnCreate(t *testing.T) {
+	tests := []struct {
+		name       string
+		parameters map[string]interface{}
+		wantErr    bool
+	}{
+		{"NotSet", map[string]interface{}{}, false},
+		{"DefaultVolumeType", map[string]interface{}{snapshotOnCreateKey: "true"}, false},
+		{"Lun", map[string]interface{}{snapshotOnCreateKey: "true", "volumeType": "lun"}, false},
+		{"DisabledFilesystem", map[string]interface{}{snapshotOnCreateKey: "false", "volumeType": "fs"}, false},
+		{"Filesystem", map[string]interface{}{snapshotOnCreateKey: "true", "volumeType": "fs"}, true},
+		{"Invalid", map[string]interface{}{snapshotOnCreateKey: "yes"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := checkSnapshotOnCreate(context.Background(), tt.parameters); (err != nil) != tt.wantErr {
+				t.Errorf("checkSnapshotOnCreate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+type fakeInitialSnapshotPlugin struct {
+	plugin.Plugin
+	deleted []string
+}
+
+// DeleteSnapshot records the name which the SAN plugin passes to the storage client
+func (p *fakeInitialSnapshotPlugin) DeleteSnapshot(_ context.Context, _, snapshotName string) error {
+	p.deleted = append(p.deleted, utils.GetSnapshotName(snapshotName))
+	return nil
+}
+
+func TestInitialSnapshotName(t *testing.T) {
+	volumes := []string{
+		"pvc-6bc1f8a2-4c1e-4b9f-9a7d-2f2f0c6e5d11",
+		"pvc-6bc1f8a2-4c1e-4b9f-9a7d-2f2f0c6e5d12",
+		"pvc-1",
+	}
+
+	names := map[string]bool{}
+	for _, volume := range volumes {
+		name := initialSnapshotName(volume)
+		if utils.GetSnapshotName(name) != name {
+			t.Errorf("initialSnapshotName(%s) = %s is truncated by the storage", volume, name)
+		}
+		if !strings.HasSuffix(name, initialSnapshotSuffix) {
+			t.Errorf("initialSnapshotName(%s) = %s, want suffix %s", volume, name, initialSnapshotSuffix)
+		}
+		if names[name] {
+			t.Errorf("initialSnapshotName(%s) = %s is not distinct", volume, name)
+		}
+		names[name] = true
+	}
+}
+
+func TestDeleteInitialSnapshot(t *testing.T) {
+	newPV := func(attributes map[string]string) *coreV1.PersistentVolume {
+		return &coreV1.PersistentVolume{Spec: coreV1.PersistentVolumeSpec{
+			PersistentVolumeSource: coreV1.PersistentVolumeSource{CSI: &coreV1.CSIPersistentVolumeSource{
+				VolumeHandle: "backend.pvc-6bc1f8a2-4c1e-4b9f-9a7d-2f2f0c6e5d11", VolumeAttributes: attributes,
+			}}}}
+	}
+
+	tests := []struct {
+		name        string
+		storage     string
+		pv          *coreV1.PersistentVolume
+		wantDeleted bool
+	}{
+		{"SnapshotOnCreate", sanStorage, newPV(map[string]string{snapshotOnCreateKey: "true"}), true},
+		{"WithoutSnapshotOnCreate", sanStorage, newPV(map[string]string{}), false},
+		{"PVNotFound", sanStorage, nil, false},
+		{"Filesystem", "oceanstor-nas", newPV(map[string]string{snapshotOnCreateKey: "true"}), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &fakeInitialSnapshotPlugin{}
+			bk := &model.Backend{Name: "backend", Storage: tt.storage, Plugin: p}
+			d := &Driver{name: "csi.huawei.com", k8sUtils: &fakeDTreeK8sUtils{pv: tt.pv}}
+
+			volumeId := "backend.pvc-6bc1f8a2-4c1e-4b9f-9a7d-2f2f0c6e5d11"
+			if err := d.deleteInitialSnapshot(context.Background(), bk, volumeId); err != nil {
+				t.Fatalf("deleteInitialSnapshot() error = %v", err)
+			}
+
+			var want []string
+			if tt.wantDeleted {
+				want = []string{initialSnapshotName("pvc-6bc1f8a2-4c1e-4b9f-9a7d-2f2f0c6e5d11")}
+			}
+			if !reflect.DeepEqual(p.deleted, want) {
+				t.Errorf("deleteInitialSnapshot() deleted %v, want %v", p.deleted, want)
+			}
+		})
+	}
+}
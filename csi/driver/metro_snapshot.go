@@ -0,0 +1,241 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package driver
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"huawei-csi-driver/csi/backend/model"
+	"huawei-csi-driver/csi/backend/plugin"
+	"huawei-csi-driver/utils"
+	"huawei-csi-driver/utils/log"
+)
+
+const (
+	// metroConsistentKey is the VolumeSnapshotClass parameter to take the snapshots on both sites of the hyperMetro
+	// volume together, the snapshot ID of such a snapshot carries the parent IDs of both sites
+	metroConsistentKey = "metroConsistent"
+
+	// snapshotSiteKey is the VolumeSnapshotClass parameter choosing the sites of the hyperMetro volume to take
+	// the snapshot on, both is the same as metroConsistent
+	snapshotSiteKey = "snapshotSite"
+
+	snapshotSiteLocal  = "local"
+	snapshotSiteRemote = "remote"
+	snapshotSiteBoth   = "both"
+
+	// remoteSnapshotParentIdKey is the parameter of the remote parent ID of the source snapshot taken on
+	// the remote site of the hyperMetro volume
+	remoteSnapshotParentIdKey = "remoteSnapshotParentId"
+)
+
+// isMetroConsistentRequired returns whether the snapshot is required to be taken on both sites of the hyperMetro
+func isMetroConsistentRequired(parameters map[string]string) (bool, error) {
+	value, exist := parameters[metroConsistentKey]
+	if !exist {
+		return false, nil
+	}
+
+	required, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("VolumeSnapshotClass parameter \"%s\": [%s] must be true or false",
+			metroConsistentKey, value)
+	}
+
+	return required, nil
+}
+
+// getSnapshotSite returns the sites of the hyperMetro volume to take the snapshot on, the local site by default
+func getSnapshotSite(parameters map[string]string) (string, error) {
+	metroConsistent, err := isMetroConsistentRequired(parameters)
+	if err != nil {
+		return "", err
+	}
+
+	site, exist := parameters[snapshotSiteKey]
+	if !exist {
+		if metroConsistent {
+			return snapshotSiteBoth, nil
+		}
+		return snapshotSiteLocal, nil
+	}
+
+	if site != snapshotSiteLocal && site != snapshotSiteRemote && site != snapshotSiteBoth {
+		return "", fmt.Errorf("VolumeSnapshotClass parameter \"%s\": [%s] must be %s, %s or %s",
+			snapshotSiteKey, site, snapshotSiteLocal, snapshotSiteRemote, snapshotSiteBoth)
+	}
+
+	if metroConsistent && site != snapshotSiteBoth {
+		return "", fmt.Errorf("VolumeSnapshotClass parameter \"%s\": [%s] conflicts with \"%s\": [true]",
+			snapshotSiteKey, site, metroConsistentKey)
+	}
+
+	return site, nil
+}
+
+type snapshotResult struct {
+	snapshot map[string]interface{}
+	err      error
+}
+
+// createMetroSnapshot takes the snapshots of the hyperMetro volume on the local and the remote sites at the same
+// time, since the storage can't group the snapshots of two arrays, the requests are sent concurrently to make the
+// snapshots as close as possible. If either side fails, the snapshot of the other side is deleted. The returned
+// snapshot is the local one whose ParentID is joined with the parent ID of the remote one.
+func createMetroSnapshot(ctx context.Context, bk *model.Backend, volName, snapshotName string) (
+	map[string]interface{}, error) {
+	if bk.MetroBackend == nil {
+		return nil, fmt.Errorf("backend %s has no hyperMetro remote backend, the VolumeSnapshotClass "+
+			"parameter \"%s\" is not supported", bk.Name, metroConsistentKey)
+	}
+
+	backends := []*model.Backend{bk, bk.MetroBackend}
+	results := make([]snapshotResult, len(backends))
+	var wg sync.WaitGroup
+	for i, b := range backends {
+		wg.Add(1)
+		go func(i int, b *model.Backend) {
+			defer wg.Done()
+			snapshot, err := b.Plugin.CreateSnapshot(ctx, volName, snapshotName)
+			results[i] = snapshotResult{snapshot: snapshot, err: err}
+		}(i, b)
+	}
+	wg.Wait()
+
+	var createErr error
+	for i, result := range results {
+		if result.err != nil && createErr == nil {
+			createErr = fmt.Errorf("create snapshot %s on backend %s error: %w",
+				snapshotName, backends[i].Name, result.err)
+		}
+	}
+
+	if createErr != nil {
+		for i, result := range results {
+			if result.err == nil {
+				rollbackMetroSnapshot(ctx, backends[i], result.snapshot, snapshotName)
+			}
+		}
+		return nil, createErr
+	}
+
+	local, remote := results[0].snapshot, results[1].snapshot
+	local["ParentID"] = utils.JoinMetroSnapshotParentId(local["ParentID"].(string), remote["ParentID"].(string))
+	return local, nil
+}
+
+// createRemoteSnapshot takes the snapshot of the hyperMetro volume on the remote site only, the returned ParentID
+// is joined with an empty local parent ID, so that the snapshot is deleted and restored on the remote site
+func createRemoteSnapshot(ctx context.Context, bk *model.Backend, volName, snapshotName string) (
+	map[string]interface{}, error) {
+	if bk.MetroBackend == nil {
+		return nil, fmt.Errorf("backend %s has no hyperMetro remote backend, the VolumeSnapshotClass "+
+			"parameter \"%s\": [%s] is not supported", bk.Name, snapshotSiteKey, snapshotSiteRemote)
+	}
+
+	snapshot, err := bk.MetroBackend.Plugin.CreateSnapshot(ctx, volName, snapshotName)
+	if err != nil {
+		return nil, fmt.Errorf("create snapshot %s on backend %s error: %w", snapshotName, bk.MetroBackend.Name, err)
+	}
+
+	snapshot["ParentID"] = utils.JoinMetroSnapshotParentId("", snapshot["ParentID"].(string))
+	return snapshot, nil
+}
+
+func rollbackMetroSnapshot(ctx context.Context, bk *model.Backend, snapshot map[string]interface{},
+	snapshotName string) {
+	parentID, _ := snapshot["ParentID"].(string)
+	if err := bk.Plugin.DeleteSnapshot(ctx, parentID, snapshotName); err != nil {
+		log.AddContext(ctx).Errorf("Rollback snapshot %s on backend %s error: %v, CAUTION: snapshot need to "+
+			"manually delete from array.", snapshotName, bk.Name, err)
+	}
+}
+
+// deleteMetroSnapshot deletes the snapshot of the remote site if the snapshot is taken on both sites of the
+// hyperMetro volume, the snapshot of the local site is deleted by the caller
+func deleteMetroSnapshot(ctx context.Context, bk *model.Backend, remoteParentID, snapshotName string) error {
+	if remoteParentID == "" {
+		return nil
+	}
+
+	if bk.MetroBackend == nil {
+		log.AddContext(ctx).Warningf("The hyperMetro remote backend of backend %s doesn't exist. Ignore the "+
+			"remote snapshot %s. CAUTION: snapshot need to manually delete from array.", bk.Name, snapshotName)
+		return nil
+	}
+
+	return bk.MetroBackend.Plugin.DeleteSnapshot(ctx, remoteParentID, snapshotName)
+}
+
+// selectSnapshotSite chooses the site to restore the volume from the snapshot taken on the sites of the
+// hyperMetro volume. The local site is preferred, the remote site is chosen when the snapshot of the local site
+// does not exist or can't be checked, e.g. during a site failover.
+func (d *Driver) selectSnapshotSite(ctx context.Context, parameters map[string]interface{}) error {
+	remoteParentId, _ := parameters[remoteSnapshotParentIdKey].(string)
+	delete(parameters, remoteSnapshotParentIdKey)
+	if remoteParentId == "" {
+		return nil
+	}
+
+	backendName, _ := parameters["backend"].(string)
+	snapshotName, _ := parameters["sourceSnapshotName"].(string)
+	bk, err := d.backendSelector.SelectBackend(ctx, backendName)
+	if err != nil {
+		log.AddContext(ctx).Warningf("Select backend %s of snapshot %s error: %v", backendName, snapshotName, err)
+	}
+
+	localParentId, _ := parameters["snapshotParentId"].(string)
+	if bk != nil && localParentId != "" && snapshotExistsOn(ctx, bk, localParentId, snapshotName) {
+		return nil
+	}
+
+	if bk != nil && bk.MetroBackend != nil && snapshotExistsOn(ctx, bk.MetroBackend, remoteParentId, snapshotName) {
+		log.AddContext(ctx).Infof("Restore snapshot %s from the hyperMetro remote backend %s",
+			snapshotName, bk.MetroBackend.Name)
+		parameters["backend"] = bk.MetroBackend.Name
+		parameters["snapshotParentId"] = remoteParentId
+		return nil
+	}
+
+	msg := fmt.Sprintf("snapshot %s does not exist on any available site of the hyperMetro backend %s",
+		snapshotName, backendName)
+	log.AddContext(ctx).Errorln(msg)
+	return status.Error(codes.FailedPrecondition, msg)
+}
+
+// snapshotExistsOn checks whether the snapshot exists on the backend, the backend whose plugin can't check
+// the snapshots is assumed to have the snapshot
+func snapshotExistsOn(ctx context.Context, bk *model.Backend, parentId, snapshotName string) bool {
+	checker, ok := bk.Plugin.(plugin.SnapshotChecker)
+	if !ok {
+		return true
+	}
+
+	exist, err := checker.SnapshotExists(ctx, parentId, snapshotName)
+	if err != nil {
+		log.AddContext(ctx).Warningf("Check snapshot %s on backend %s error: %v", snapshotName, bk.Name, err)
+		return false
+	}
+
+	return exist
+}
@@ -0,0 +1,131 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package driver
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"huawei-csi-driver/csi/backend/model"
+)
+
+func TestGetSnapshotSite(t *testing.T) {
+	tests := []struct {
+		name       string
+		parameters map[string]string
+		want       string
+		wantErr    bool
+	}{
+		{"Default", map[string]string{}, snapshotSiteLocal, false},
+		{"MetroConsistent", map[string]string{metroConsistentKey: "true"}, snapshotSiteBoth, false},
+		{"Remote", map[string]string{snapshotSiteKey: "remote"}, snapshotSiteRemote, false},
+		{"BothWithMetroConsistent", map[string]string{snapshotSiteKey: "both", metroConsistentKey: "true"},
+			snapshotSiteBoth, false},
+		{"Conflict", map[string]string{snapshotSiteKey: "local", metroConsistentKey: "true"}, "", true},
+		{"Invalid", map[string]string{snapshotSiteKey: "peer"}, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := getSnapshotSite(tt.parameters)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("getSnapshotSite() = %s, %v, want %s, wantErr %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCreateRemoteSnapshot(t *testing.T) {
+	local, remote := &fakeSnapshotPlugin{parentID: "1"}, &fakeSnapshotPlugin{parentID: "2"}
+	bk := &model.Backend{Name: "local", Plugin: local,
+		MetroBackend: &model.Backend{Name: "remote", Plugin: remote}}
+	snapshot, err := createRemoteSnapshot(context.Background(), bk, "vol", "snap")
+	if err != nil || snapshot["ParentID"] != "+2" {
+		t.Errorf("createRemoteSnapshot() = %v, %v, want ParentID +2", snapshot, err)
+	}
+
+	if _, err = createRemoteSnapshot(context.Background(), &model.Backend{Name: "local", Plugin: local},
+		"vol", "snap"); err == nil {
+		t.Error("createRemoteSnapshot() without the hyperMetro remote backend succeeded")
+	}
+}
+
+type fakeSnapshotCheckerPlugin struct {
+	fakeSnapshotPlugin
+	exist bool
+	err   error
+}
+
+func (p *fakeSnapshotCheckerPlugin) SnapshotExists(context.Context, string, string) (bool, error) {
+	return p.exist, p.err
+}
+
+func TestSelectSnapshotSite(t *testing.T) {
+	tests := []struct {
+		name           string
+		localParentId  string
+		remoteParentId string
+		local          *fakeSnapshotCheckerPlugin
+		remote         *fakeSnapshotCheckerPlugin
+		wantBackend    string
+		wantParentId   string
+		wantCode       codes.Code
+	}{
+		{"NotMetroSnapshot", "1", "", &fakeSnapshotCheckerPlugin{}, &fakeSnapshotCheckerPlugin{},
+			"local", "1", codes.OK},
+		{"LocalExists", "1", "2", &fakeSnapshotCheckerPlugin{exist: true},
+			&fakeSnapshotCheckerPlugin{exist: true}, "local", "1", codes.OK},
+		{"LocalUnreachable", "1", "2", &fakeSnapshotCheckerPlugin{err: errors.New("unreachable")},
+			&fakeSnapshotCheckerPlugin{exist: true}, "remote", "2", codes.OK},
+		{"RemoteOnly", "", "2", &fakeSnapshotCheckerPlugin{exist: true},
+			&fakeSnapshotCheckerPlugin{exist: true}, "remote", "2", codes.OK},
+		{"NoneExists", "1", "2", &fakeSnapshotCheckerPlugin{}, &fakeSnapshotCheckerPlugin{},
+			"local", "1", codes.FailedPrecondition},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &Driver{backendSelector: &fakeBackendSelector{backends: map[string]*model.Backend{
+				"local": {Name: "local", Plugin: tt.local,
+					MetroBackend: &model.Backend{Name: "remote", Plugin: tt.remote}},
+			}}}
+			parameters := map[string]interface{}{
+				"backend":                 "local",
+				"sourceSnapshotName":      "snap",
+				"snapshotParentId":        tt.localParentId,
+				remoteSnapshotParentIdKey: tt.remoteParentId,
+			}
+
+			err := d.selectSnapshotSite(context.Background(), parameters)
+			if status.Code(err) != tt.wantCode {
+				t.Fatalf("selectSnapshotSite() error = %v, want code %s", err, tt.wantCode)
+			}
+			if err == nil && (parameters["backend"] != tt.wantBackend ||
+				parameters["snapshotParentId"] != tt.wantParentId) {
+				t.Errorf("selectSnapshotSite() backend = %v, parent ID = %v, want %s, %s",
+					parameters["backend"], parameters["snapshotParentId"], tt.wantBackend, tt.wantParentId)
+			}
+			if _, exist := parameters[remoteSnapshotParentIdKey]; exist {
+				t.Errorf("selectSnapshotSite() kept the parameter %s", remoteSnapshotParentIdKey)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package driver
+
+import (
+	"context"
+	"sort"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	coreV1 "k8s.io/api/core/v1"
+
+	"huawei-csi-driver/csi/app"
+	"huawei-csi-driver/pkg/constants"
+	"huawei-csi-driver/utils/log"
+)
+
+// applyNamespaceDefaultParameters merges the default parameters of the namespace of the PVC into the
+// StorageClass parameters before the pool is selected, the StorageClass wins on conflict. The merged parameters
+// are checked as the StorageClass ones.
+func (d *Driver) applyNamespaceDefaultParameters(ctx context.Context, req *csi.CreateVolumeRequest) error {
+	if !app.GetGlobalConfig().EnableNamespaceDefaultParameters || d.k8sUtils == nil {
+		return nil
+	}
+
+	namespace, err := d.k8sUtils.GetVolumeClaimNamespace(ctx, req.GetName())
+	if err != nil {
+		log.AddContext(ctx).Errorf("Get namespace of the PVC of volume %s failed, error: %v", req.GetName(), err)
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	configmaps, err := d.k8sUtils.ListConfigmapsByLabel(ctx, namespace,
+		constants.NamespaceDefaultParametersLabel+"=true")
+	if err != nil {
+		log.AddContext(ctx).Errorf("List default parameters of namespace %s failed, error: %v", namespace, err)
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	defaults := mergeNamespaceDefaultParameters(configmaps)
+	if len(defaults) == 0 {
+		return nil
+	}
+
+	for key, value := range req.GetParameters() {
+		defaults[key] = value
+	}
+	req.Parameters = defaults
+	if err = checkCreateVolumeRequest(ctx, req); err != nil {
+		log.AddContext(ctx).Errorf("Check default parameters of namespace %s error: %v", namespace, err)
+		return err
+	}
+
+	log.AddContext(ctx).Infof("Apply default parameters of namespace %s to volume %s", namespace, req.GetName())
+	return nil
+}
+
+// mergeNamespaceDefaultParameters merges the data of the configmaps in the order of their names,
+// the latter configmap wins on conflict
+func mergeNamespaceDefaultParameters(configmaps []coreV1.ConfigMap) map[string]string {
+	sort.Slice(configmaps, func(i, j int) bool {
+		return configmaps[i].Name < configmaps[j].Name
+	})
+
+	defaults := make(map[string]string)
+	for _, configmap := range configmaps {
+		for key, value := range configmap.Data {
+			defaults[key] = value
+		}
+	}
+	return defaults
+}
@@ -0,0 +1,97 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package driver
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	"github.com/prashantv/gostub"
+	coreV1 "k8s.io/api/core/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"huawei-csi-driver/csi/app"
+	cfg "huawei-csi-driver/csi/app/config"
+	"huawei-csi-driver/utils/k8sutils"
+)
+
+type fakeNamespaceDefaultsK8sUtils struct {
+	k8sutils.Interface
+	configmaps []coreV1.ConfigMap
+}
+
+func (k *fakeNamespaceDefaultsK8sUtils) GetVolumeClaimNamespace(context.Context, string) (string, error) {
+	return "tenant-a", nil
+}
+
+func (k *fakeNamespaceDefaultsK8sUtils) ListConfigmapsByLabel(context.Context, string,
+	string) ([]coreV1.ConfigMap, error) {
+	return k.configmaps, nil
+}
+
+func newDefaultsConfigmap(name string, data map[string]string) coreV1.ConfigMap {
+	return coreV1.ConfigMap{ObjectMeta: metaV1.ObjectMeta{Name: name}, Data: data}
+}
+
+func TestApplyNamespaceDefaultParameters(t *testing.T) {
+	config := cfg.MockCompletedConfig()
+	config.EnableNamespaceDefaultParameters = true
+	getGlobalConfig := gostub.StubFunc(&app.GetGlobalConfig, config)
+	defer getGlobalConfig.Reset()
+
+	tests := []struct {
+		name       string
+		configmaps []coreV1.ConfigMap
+		parameters map[string]string
+		want       map[string]string
+	}{
+		{"NoDefaults", nil, map[string]string{"volumeType": "lun"}, map[string]string{"volumeType": "lun"}},
+		{"MergeDefaults",
+			[]coreV1.ConfigMap{newDefaultsConfigmap("qos", map[string]string{"qos": `{"MAXIOPS": 1000}`})},
+			map[string]string{"volumeType": "lun"},
+			map[string]string{"volumeType": "lun", "qos": `{"MAXIOPS": 1000}`}},
+		{"StorageClassWins",
+			[]coreV1.ConfigMap{newDefaultsConfigmap("qos", map[string]string{"qos": `{"MAXIOPS": 1000}`})},
+			map[string]string{"volumeType": "lun", "qos": `{"MAXIOPS": 5000}`},
+			map[string]string{"volumeType": "lun", "qos": `{"MAXIOPS": 5000}`}},
+		{"LatterConfigmapWins",
+			[]coreV1.ConfigMap{newDefaultsConfigmap("b", map[string]string{"allocType": "thick"}),
+				newDefaultsConfigmap("a", map[string]string{"allocType": "thin"})},
+			map[string]string{"volumeType": "lun"},
+			map[string]string{"volumeType": "lun", "allocType": "thick"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &Driver{k8sUtils: &fakeNamespaceDefaultsK8sUtils{configmaps: tt.configmaps}}
+			req := &csi.CreateVolumeRequest{
+				Name:               "pvc-1",
+				Parameters:         tt.parameters,
+				CapacityRange:      &csi.CapacityRange{RequiredBytes: 1 << 30},
+				VolumeCapabilities: []*csi.VolumeCapability{{}},
+			}
+			if err := d.applyNamespaceDefaultParameters(context.Background(), req); err != nil {
+				t.Fatalf("applyNamespaceDefaultParameters() error = %v", err)
+			}
+			if !reflect.DeepEqual(req.GetParameters(), tt.want) {
+				t.Errorf("applyNamespaceDefaultParameters() parameters = %v, want %v", req.GetParameters(), tt.want)
+			}
+		})
+	}
+}
@@ -43,6 +43,11 @@ func (d *Driver) NodeStageVolume(ctx context.Context, req *csi.NodeStageVolumeRe
 	volumeId := req.GetVolumeId()
 	log.AddContext(ctx).Infof("Start to stage volume %s", volumeId)
 	backendName, volName := utils.SplitVolumeId(volumeId)
+	ctx = connector.WithVolumeTimeouts(ctx, req.GetVolumeContext())
+
+	if err := d.checkStageVersion(ctx, volumeId); err != nil {
+		return nil, err
+	}
 
 	manager, err := manage.NewManager(ctx, backendName)
 	if err != nil {
@@ -181,6 +186,8 @@ func (d *Driver) NodeGetInfo(ctx context.Context, req *csi.NodeGetInfoRequest) (
 		}, nil
 	}
 
+	d.checkVersionSkew(ctx)
+
 	// Get topology info from Node labels
 	topology, err := d.k8sUtils.GetNodeTopology(ctx, d.nodeName)
 	if err != nil {
@@ -222,6 +229,13 @@ func (d *Driver) NodeGetCapabilities(ctx context.Context, req *csi.NodeGetCapabi
 					},
 				},
 			},
+			{
+				Type: &csi.NodeServiceCapability_Rpc{
+					Rpc: &csi.NodeServiceCapability_RPC{
+						Type: csi.NodeServiceCapability_RPC_VOLUME_CONDITION,
+					},
+				},
+			},
 		},
 	}, nil
 }
@@ -242,6 +256,14 @@ func (d *Driver) NodeGetVolumeStats(ctx context.Context, req *csi.NodeGetVolumeS
 		return nil, status.Error(codes.InvalidArgument, msg)
 	}
 
+	// the metrics of an abnormal volume are not collected, since statfs may hang when the storage is down
+	condition := getVolumeCondition(ctx, volumePath)
+	volumeCondition := &csi.VolumeCondition{Abnormal: condition.Abnormal, Message: condition.Message}
+	if condition.Abnormal {
+		log.AddContext(ctx).Warningf("Volume %s at %s is abnormal: %s", volumeID, volumePath, condition.Message)
+		return &csi.NodeGetVolumeStatsResponse{VolumeCondition: volumeCondition}, nil
+	}
+
 	volumeMetrics, err := utils.GetVolumeMetrics(volumePath)
 	if err != nil {
 		msg := fmt.Sprintf("get volume metrics failed, reason %v", err)
@@ -306,6 +328,7 @@ func (d *Driver) NodeGetVolumeStats(ctx context.Context, req *csi.NodeGetVolumeS
 				Unit:      csi.VolumeUsage_INODES,
 			},
 		},
+		VolumeCondition: volumeCondition,
 	}
 	return response, nil
 }
@@ -0,0 +1,108 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package driver
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	coreV1 "k8s.io/api/core/v1"
+
+	"huawei-csi-driver/csi/backend/model"
+	"huawei-csi-driver/csi/backend/plugin"
+	"huawei-csi-driver/utils"
+	"huawei-csi-driver/utils/log"
+)
+
+// nodeIDAnnotation is the annotation which the kubelet records the node ids of csi drivers on
+const nodeIDAnnotation = "csi.volume.kubernetes.io/nodeid"
+
+// WatchNodeDeletion detaches all volumes of the nodes which are deleted from Kubernetes without draining,
+// so that the mappings and host objects on the storage do not linger
+func (d *Driver) WatchNodeDeletion(ctx context.Context, stopCh <-chan struct{}) {
+	log.AddContext(ctx).Infoln("Start to watch node deletion")
+	d.k8sUtils.WatchNodeDeletion(ctx, func(node *coreV1.Node) {
+		d.detachDeletedNode(ctx, node)
+	}, stopCh)
+}
+
+func (d *Driver) detachDeletedNode(ctx context.Context, node *coreV1.Node) {
+	nodeID := getCSINodeID(node, d.name)
+	log.AddContext(ctx).Infof("Node %s is deleted, start to detach its volumes, node id: %s", node.Name, nodeID)
+
+	var parameters map[string]interface{}
+	if err := json.Unmarshal([]byte(nodeID), &parameters); err != nil {
+		log.AddContext(ctx).Errorf("Unmarshal node id %s of node %s error: %v", nodeID, node.Name, err)
+		return
+	}
+
+	volumes, err := d.k8sUtils.GetNodeAttachedVolumes(ctx, d.name, node.Name)
+	if err != nil {
+		log.AddContext(ctx).Errorf("Get attached volumes of node %s error: %v", node.Name, err)
+		return
+	}
+
+	backends := make(map[string]*model.Backend)
+	for _, volumeId := range volumes {
+		_, err = d.ControllerUnpublishVolume(ctx, &csi.ControllerUnpublishVolumeRequest{
+			VolumeId: volumeId,
+			NodeId:   nodeID,
+		})
+		if err != nil {
+			log.AddContext(ctx).Errorf("Detach volume %s from deleted node %s error: %v", volumeId, node.Name, err)
+			continue
+		}
+
+		backendName, _ := utils.SplitVolumeId(volumeId)
+		if backend, err := d.backendSelector.SelectBackend(ctx, backendName); err == nil && backend != nil {
+			backends[backendName] = backend
+		}
+	}
+
+	for name, backend := range backends {
+		hostCleaner, ok := backend.Plugin.(plugin.HostCleaner)
+		if !ok {
+			continue
+		}
+
+		if err = hostCleaner.DeleteHost(ctx, parameters); err != nil {
+			log.AddContext(ctx).Warningf("Delete host of deleted node %s on backend %s error: %v",
+				node.Name, name, err)
+		}
+	}
+
+	log.AddContext(ctx).Infof("Volumes of deleted node %s are detached", node.Name)
+}
+
+// getCSINodeID gets the node id which is reported by NodeGetInfo of the driver,
+// the node name is used as the host name if the node id is not recorded
+func getCSINodeID(node *coreV1.Node, driverName string) string {
+	var nodeIDs map[string]string
+	if err := json.Unmarshal([]byte(node.Annotations[nodeIDAnnotation]), &nodeIDs); err == nil {
+		if nodeID, exist := nodeIDs[driverName]; exist {
+			return nodeID
+		}
+	}
+
+	nodeID, err := json.Marshal(map[string]interface{}{"HostName": node.Name})
+	if err != nil {
+		return ""
+	}
+
+	return string(nodeID)
+}
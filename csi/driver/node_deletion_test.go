@@ -0,0 +1,47 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package driver
+
+import (
+	"testing"
+
+	coreV1 "k8s.io/api/core/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGetCSINodeID(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        string
+	}{
+		{"RecordedNodeID", map[string]string{nodeIDAnnotation: `{"csi.huawei.com":"{\"HostName\":\"host-1\"}"}`},
+			`{"HostName":"host-1"}`},
+		{"OtherDriverNodeID", map[string]string{nodeIDAnnotation: `{"other.csi.com":"node-1"}`},
+			`{"HostName":"node-1"}`},
+		{"NoAnnotation", nil, `{"HostName":"node-1"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node := &coreV1.Node{ObjectMeta: metaV1.ObjectMeta{Name: "node-1", Annotations: tt.annotations}}
+			if got := getCSINodeID(node, "csi.huawei.com"); got != tt.want {
+				t.Errorf("getCSINodeID() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package driver
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"huawei-csi-driver/connector/host"
+	"huawei-csi-driver/csi/app"
+	"huawei-csi-driver/utils/log"
+)
+
+const protocolFcNvme = "fc-nvme"
+
+// onlineExpansionRule matches the node environment by version prefix, an empty version matches any version
+type onlineExpansionRule struct {
+	kernelVersion    string
+	ultraPathVersion string
+}
+
+func (r onlineExpansionRule) match(kernelVersion, ultraPathVersion string) bool {
+	return strings.HasPrefix(kernelVersion, r.kernelVersion) &&
+		strings.HasPrefix(ultraPathVersion, r.ultraPathVersion)
+}
+
+// parseUnsupportedOnlineExpansion parses the unsupported-online-expansion configuration, which is validated on
+// startup, into the rules keyed by the nvme multipath type. No rule is built in, because the environments which
+// fail the online expansion are only known from the release notes of the multipath software.
+func parseUnsupportedOnlineExpansion(value string) map[string][]onlineExpansionRule {
+	rules := make(map[string][]onlineExpansionRule)
+	if value == "" {
+		return rules
+	}
+
+	for _, entry := range strings.Split(value, ",") {
+		fields := strings.Split(strings.TrimSpace(entry), ":")
+		rule := onlineExpansionRule{kernelVersion: fields[1]}
+		if len(fields) > 2 {
+			rule.ultraPathVersion = fields[2]
+		}
+		rules[fields[0]] = append(rules[fields[0]], rule)
+	}
+
+	return rules
+}
+
+// isOnlineExpansionSupported checks whether the node environment supports expanding an attached volume
+func isOnlineExpansionSupported(rules map[string][]onlineExpansionRule,
+	multiPathType, kernelVersion, ultraPathVersion string) bool {
+	for _, rule := range rules[multiPathType] {
+		if rule.match(kernelVersion, ultraPathVersion) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// checkOnlineExpansion returns the error if the volume is attached to a node whose environment is configured
+// to not support online expansion. The environment of each node is read from the host information saved by
+// its node plugin, and the nodes which have not saved it are skipped.
+func (d *Driver) checkOnlineExpansion(ctx context.Context, volumeId string) error {
+	rules := parseUnsupportedOnlineExpansion(app.GetGlobalConfig().UnsupportedOnlineExpansion)
+	if len(rules) == 0 {
+		return nil
+	}
+
+	nodes, err := d.k8sUtils.GetVolumeAttachedNodes(ctx, d.name, volumeId)
+	if err != nil {
+		log.AddContext(ctx).Warningf("Get attached nodes of volume %s failed, skip online expansion check, "+
+			"error: %v", volumeId, err)
+		return nil
+	}
+
+	for _, node := range nodes {
+		hostInfo, err := host.GetNodeHostInfoByNodeName(ctx, node)
+		if err != nil {
+			log.AddContext(ctx).Warningf("Get host info of node %s failed, error: %v", node, err)
+			continue
+		}
+
+		if hostInfo == nil || hostInfo.NvmeMultiPathType == "" {
+			continue
+		}
+
+		if !isOnlineExpansionSupported(rules, hostInfo.NvmeMultiPathType, hostInfo.KernelVersion,
+			hostInfo.UltraPathVersion) {
+			return fmt.Errorf("volume %s is attached to node %s using %s (kernel %s, UltraPath %s), "+
+				"which does not support online expansion of %s volumes, please scale the workload to zero "+
+				"and expand the volume offline", volumeId, node, hostInfo.NvmeMultiPathType,
+				hostInfo.KernelVersion, hostInfo.UltraPathVersion, protocolFcNvme)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,55 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package driver
+
+import (
+	"testing"
+
+	"huawei-csi-driver/connector"
+)
+
+func TestIsOnlineExpansionSupported(t *testing.T) {
+	rules := parseUnsupportedOnlineExpansion("HW-UltraPath-NVMe:3.10.0, HW-UltraPath-NVMe:4.18.0-80:31.0")
+	tests := []struct {
+		name             string
+		multiPathType    string
+		kernelVersion    string
+		ultraPathVersion string
+		want             bool
+	}{
+		{"DMMultiPath", connector.DMMultiPath, "3.10.0-1160.el7.x86_64", "", true},
+		{"UltraPathAnyVersionOnBadKernel", connector.HWUltraPathNVMe, "3.10.0-1160.el7.x86_64", "31.2.0", false},
+		{"UltraPathBadVersion", connector.HWUltraPathNVMe, "4.18.0-80.el8.x86_64", "31.0.1", false},
+		{"UltraPathOtherVersion", connector.HWUltraPathNVMe, "4.18.0-80.el8.x86_64", "31.1.0", true},
+		{"UltraPathOtherKernel", connector.HWUltraPathNVMe, "5.10.0-60.18.0.50.oe2203.x86_64", "31.0.1", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := isOnlineExpansionSupported(rules, tt.multiPathType, tt.kernelVersion, tt.ultraPathVersion)
+			if got != tt.want {
+				t.Errorf("isOnlineExpansionSupported() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseUnsupportedOnlineExpansionEmpty(t *testing.T) {
+	if rules := parseUnsupportedOnlineExpansion(""); len(rules) != 0 {
+		t.Errorf("parseUnsupportedOnlineExpansion() = %v, want no rule", rules)
+	}
+}
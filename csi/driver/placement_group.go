@@ -0,0 +1,90 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package driver
+
+import (
+	"context"
+
+	"huawei-csi-driver/csi/backend"
+	"huawei-csi-driver/csi/backend/model"
+	"huawei-csi-driver/csi/backend/plugin"
+	"huawei-csi-driver/utils/log"
+)
+
+// placementGroupAttribute is the volume attribute of the placement group which the volume belongs to
+const placementGroupAttribute = "placementGroup"
+
+// spreadLunOwner requests the controller of the selected backend owning the fewest volumes of the placement
+// group as the owner of the lun, unless an owner is requested by the StorageClass
+func spreadLunOwner(ctx context.Context, parameters map[string]interface{}, pool *model.StoragePool) {
+	group := backend.GetPlacementGroup(parameters)
+	if owner, _ := parameters["localOwner"].(string); group == "" || owner != "" {
+		return
+	}
+
+	ownerController, ok := pool.Plugin.(plugin.LunOwnerController)
+	if !ok {
+		return
+	}
+
+	controllers, err := ownerController.GetOwnerControllers(ctx)
+	if err != nil {
+		log.AddContext(ctx).Warningf("Get controllers of backend %s error: %v, the volume of placement group "+
+			"%s is not spread across the controllers", pool.Parent, err, group)
+		return
+	}
+
+	if owner := backend.SpreadControllerByPlacementGroup(group, pool.Parent, controllers); owner != "" {
+		log.AddContext(ctx).Infof("Placement group %s requests controller %s of backend %s as the owner",
+			group, owner, pool.Parent)
+		parameters["localOwner"] = owner
+	}
+}
+
+// addPlacementAttributes records the placement group of the volume in its attributes
+func addPlacementAttributes(attributes map[string]string, parameters map[string]string) {
+	if group := parameters[placementGroupAttribute]; group != "" {
+		attributes[placementGroupAttribute] = group
+	}
+}
+
+// recordPlacement records the created volume in its placement group by the attributes of the volume
+func recordPlacement(volumeID string, attributes map[string]string) {
+	backend.RecordPlacement(volumeID, attributes[placementGroupAttribute], attributes["backend"],
+		attributes[storagePoolAttribute], attributes["localOwner"])
+}
+
+// RestorePlacementGroups rebuilds the placement groups from the attributes of the PVs, since the placement groups
+// are only tracked in memory
+func (d *Driver) RestorePlacementGroups(ctx context.Context) {
+	pvs, err := d.k8sUtils.ListDriverPersistentVolumes(ctx, d.name)
+	if err != nil {
+		log.AddContext(ctx).Errorf("List PVs to restore the placement groups error: %v", err)
+		return
+	}
+
+	var restored int
+	for _, pv := range pvs {
+		attributes := pv.Spec.CSI.VolumeAttributes
+		if attributes[placementGroupAttribute] == "" {
+			continue
+		}
+		recordPlacement(pv.Spec.CSI.VolumeHandle, attributes)
+		restored++
+	}
+	log.AddContext(ctx).Infof("Restored %d volumes of the placement groups", restored)
+}
@@ -0,0 +1,83 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package driver
+
+import (
+	"context"
+	"testing"
+
+	coreV1 "k8s.io/api/core/v1"
+
+	"huawei-csi-driver/csi/backend/model"
+	"huawei-csi-driver/csi/backend/plugin"
+	"huawei-csi-driver/utils/k8sutils"
+)
+
+type fakePlacementK8sUtils struct {
+	k8sutils.Interface
+	pvs []coreV1.PersistentVolume
+}
+
+func (f *fakePlacementK8sUtils) ListDriverPersistentVolumes(context.Context, string) (
+	[]coreV1.PersistentVolume, error) {
+	return f.pvs, nil
+}
+
+type fakeOwnerControllerPlugin struct {
+	plugin.Plugin
+	controllers []string
+}
+
+func (f *fakeOwnerControllerPlugin) GetOwnerControllers(context.Context) ([]string, error) {
+	return f.controllers, nil
+}
+
+func newPlacementPV(volumeHandle string, attributes map[string]string) coreV1.PersistentVolume {
+	return coreV1.PersistentVolume{Spec: coreV1.PersistentVolumeSpec{PersistentVolumeSource: coreV1.PersistentVolumeSource{
+		CSI: &coreV1.CSIPersistentVolumeSource{VolumeHandle: volumeHandle, VolumeAttributes: attributes}}}}
+}
+
+func TestSpreadLunOwnerAfterRestorePlacementGroups(t *testing.T) {
+	d := &Driver{name: "csi.huawei.com", k8sUtils: &fakePlacementK8sUtils{pvs: []coreV1.PersistentVolume{
+		newPlacementPV("backend.pvc-1", map[string]string{"backend": "backend", "placementGroup": "restored",
+			"storagePool": "pool", "localOwner": "0A"}),
+		newPlacementPV("backend.pvc-2", map[string]string{"backend": "backend", "localOwner": "0B"}),
+	}}}
+	d.RestorePlacementGroups(context.Background())
+
+	pool := &model.StoragePool{Name: "pool", Parent: "backend",
+		Plugin: &fakeOwnerControllerPlugin{controllers: []string{"0A", "0B"}}}
+	tests := []struct {
+		name        string
+		parameters  map[string]interface{}
+		expectOwner interface{}
+	}{
+		{"SpreadFromRestoredVolume", map[string]interface{}{"placementGroup": "restored"}, "0B"},
+		{"FirstOfGroup", map[string]interface{}{"placementGroup": "new"}, "0A"},
+		{"RequestedOwner", map[string]interface{}{"placementGroup": "restored", "localOwner": "0A"}, "0A"},
+		{"WithoutGroup", map[string]interface{}{}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spreadLunOwner(context.Background(), tt.parameters, pool)
+			if tt.parameters["localOwner"] != tt.expectOwner {
+				t.Errorf("spreadLunOwner() localOwner = %v, want %v", tt.parameters["localOwner"], tt.expectOwner)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package driver
+
+import (
+	"strconv"
+
+	cfg "huawei-csi-driver/csi/app/config"
+	"huawei-csi-driver/csi/backend/model"
+	"huawei-csi-driver/pkg/constants"
+	"huawei-csi-driver/utils/version"
+)
+
+const (
+	manifestGitCommitKey  = "gitCommit"
+	manifestBuildDateKey  = "buildDate"
+	manifestFeaturePrefix = "feature."
+	manifestBackendPrefix = "backend."
+)
+
+// globalFeatureGates returns whether the optional features are enabled by the flags of this deployment
+func globalFeatureGates(config *cfg.CompletedConfig) map[string]bool {
+	return map[string]bool{
+		"snapshot":                   !config.DisableSnapshot,
+		"clone":                      !config.DisableClone,
+		"expand":                     !config.DisableExpand,
+		"nodeDeletionDetach":         config.EnableNodeDeletionDetach,
+		"retainedShareCleanup":       config.EnableRetainedShareCleanup,
+		"namespaceDefaultParameters": config.EnableNamespaceDefaultParameters,
+		"strictVersionCheck":         config.StrictVersionCheck,
+		"lunBatchCreate":             config.LunBatchCreateWindow > 0,
+		"snapshotTiering":            config.TierSnapshotsAfter > 0,
+		"tracing":                    config.EnableTracing,
+		"requestRecording":           config.EnableRequestRecording,
+		"metrics":                    config.MetricsAddress != "",
+		"maintenancePause":           config.MaintenancePolicy == constants.MaintenancePolicyPause,
+		"backendTopology":            config.EnableBackendTopology,
+		"driftDetection":             config.DriftDetectionInterval > 0,
+	}
+}
+
+// backendFeatureGates returns whether the optional features are enabled by the configuration of the backends,
+// the features are keyed by <backend>.<feature>
+func backendFeatureGates(backends []model.Backend) map[string]bool {
+	gates := make(map[string]bool)
+	for _, bk := range backends {
+		gates[bk.Name+".metro"] = bk.MetroBackend != nil
+		gates[bk.Name+".replication"] = bk.ReplicaBackend != nil
+		gates[bk.Name+".readOnly"] = bk.ReadOnly
+		gates[bk.Name+".pausedByMaintenance"] = bk.IsPausedByMaintenance()
+	}
+	return gates
+}
+
+// pluginManifest returns the build metadata and the feature gates of the running driver,
+// the features of the backends are only known by the controller which caches the backends
+func pluginManifest(config *cfg.CompletedConfig, backends []model.Backend) map[string]string {
+	manifest := map[string]string{
+		manifestGitCommitKey: version.GitCommit,
+		manifestBuildDateKey: version.BuildDate,
+	}
+	if config != nil {
+		for name, enabled := range globalFeatureGates(config) {
+			manifest[manifestFeaturePrefix+name] = strconv.FormatBool(enabled)
+		}
+	}
+	for name, enabled := range backendFeatureGates(backends) {
+		manifest[manifestBackendPrefix+name] = strconv.FormatBool(enabled)
+	}
+
+	return manifest
+}
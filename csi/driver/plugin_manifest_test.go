@@ -0,0 +1,53 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package driver
+
+import (
+	"testing"
+
+	cfg "huawei-csi-driver/csi/app/config"
+	"huawei-csi-driver/csi/backend/model"
+)
+
+func TestPluginManifest(t *testing.T) {
+	config := &cfg.CompletedConfig{Config: &cfg.Config{}}
+	config.DisableSnapshot = true
+	config.EnableTracing = true
+	backends := []model.Backend{{Name: "san", MetroBackend: &model.Backend{Name: "san-metro"}}}
+
+	manifest := pluginManifest(config, backends)
+
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{"feature.snapshot", "false"},
+		{"feature.tracing", "true"},
+		{"feature.clone", "true"},
+		{"feature.requestRecording", "false"},
+		{"backend.san.metro", "true"},
+		{"backend.san.replication", "false"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			if got := manifest[tt.key]; got != tt.want {
+				t.Errorf("pluginManifest()[%s] = %v, want %v", tt.key, got, tt.want)
+			}
+		})
+	}
+}
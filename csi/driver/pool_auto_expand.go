@@ -0,0 +1,129 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package driver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	coreV1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"huawei-csi-driver/csi/app"
+	"huawei-csi-driver/csi/backend"
+	"huawei-csi-driver/csi/backend/model"
+	"huawei-csi-driver/csi/backend/plugin"
+	"huawei-csi-driver/utils"
+	"huawei-csi-driver/utils/log"
+)
+
+const poolAutoExpandedReason = "StoragePoolAutoExpanded"
+
+// errPoolExpanding means that the pool is being expanded automatically, the volume creation is retried after
+// the expansion completes
+var errPoolExpanding = errors.New("pool is being expanded")
+
+// poolExpansions records the automatic expansion of each pool by its backend and name
+var poolExpansions sync.Map
+
+// poolExpansion serializes the automatic expansions of a pool, so that the volumes waiting for the same pool
+// do not expand it repeatedly
+type poolExpansion struct {
+	mutex sync.Mutex
+	// the submitted expansion is regarded as in progress until the deadline if its wait stopped before completion
+	deadline time.Time
+}
+
+func getPoolExpansion(pool *model.StoragePool) *poolExpansion {
+	expansion, _ := poolExpansions.LoadOrStore(pool.Parent+"/"+pool.Name, &poolExpansion{})
+	return expansion.(*poolExpansion)
+}
+
+// selectExpandedPool expands the pool whose backend allows the automatic pool expansion if no pool has enough
+// free capacity, then selects the pools again. The error of the first selection is returned if no pool can be
+// expanded, and the error wrapping errPoolExpanding is returned while the pool is being expanded.
+func (d *Driver) selectExpandedPool(ctx context.Context, req *csi.CreateVolumeRequest,
+	parameters map[string]interface{}, selectErr error) (*model.SelectPoolPair, error) {
+	if !errors.Is(selectErr, backend.ErrInsufficientCapacity) {
+		return nil, selectErr
+	}
+
+	pool, err := d.backendSelector.SelectExpandablePool(ctx, parameters)
+	if err != nil || pool == nil {
+		log.AddContext(ctx).Debugf("No pool can be expanded automatically for volume %s, error: %v",
+			req.GetName(), err)
+		return nil, selectErr
+	}
+
+	expander, ok := pool.Plugin.(plugin.PoolExpander)
+	if !ok {
+		return nil, selectErr
+	}
+
+	expansion := getPoolExpansion(pool)
+	if !expansion.mutex.TryLock() {
+		return nil, fmt.Errorf("%w: pool %s of backend %s is being expanded for another volume, retry later",
+			errPoolExpanding, pool.Name, pool.Parent)
+	}
+	defer expansion.mutex.Unlock()
+
+	// the pool may have been expanded for another volume before the lock
+	requestSize := req.GetCapacityRange().RequiredBytes
+	if poolPair, err := d.backendSelector.SelectPoolPair(ctx, requestSize, parameters); err == nil {
+		return poolPair, nil
+	}
+
+	if time.Now().Before(expansion.deadline) {
+		return nil, fmt.Errorf("%w: the expansion of pool %s of backend %s submitted before is still in "+
+			"progress, retry later", errPoolExpanding, pool.Name, pool.Parent)
+	}
+
+	freeCapacity := utils.ParseIntWithDefault(pool.GetCapacity("FreeCapacity"), 10, 64, 0)
+	log.AddContext(ctx).Infof("No pool has enough free capacity for volume %s, expand pool %s:%s automatically",
+		req.GetName(), pool.Parent, pool.Name)
+	expansion.deadline = time.Now().Add(app.GetGlobalConfig().PoolExpandTimeout)
+	oldFree, newFree, err := expander.ExpandStoragePool(ctx, pool.Name, requestSize-freeCapacity)
+	if err != nil && ctx.Err() != nil {
+		return nil, fmt.Errorf("%w: stop waiting for the expansion of pool %s of backend %s, error: %v",
+			errPoolExpanding, pool.Name, pool.Parent, err)
+	}
+
+	expansion.deadline = time.Time{}
+	if err != nil {
+		return nil, fmt.Errorf("%w, and expand pool %s of backend %s failed, error: %v",
+			selectErr, pool.Name, pool.Parent, err)
+	}
+
+	pool.SetCapacity("FreeCapacity", strconv.FormatInt(newFree, 10))
+	d.recordPVCEvent(ctx, req.GetName(), coreV1.EventTypeNormal, poolAutoExpandedReason,
+		fmt.Sprintf("Pool %s of backend %s is expanded automatically, its free capacity grows from %s to %s",
+			pool.Name, pool.Parent, resource.NewQuantity(oldFree, resource.BinarySI),
+			resource.NewQuantity(newFree, resource.BinarySI)))
+
+	poolPair, err := d.backendSelector.SelectPoolPair(ctx, requestSize, parameters)
+	if err != nil {
+		return nil, fmt.Errorf("%w, and select pool after expanding pool %s failed, error: %v",
+			selectErr, pool.Name, err)
+	}
+
+	return poolPair, nil
+}
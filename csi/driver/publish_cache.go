@@ -0,0 +1,129 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package driver
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+
+	"huawei-csi-driver/csi/backend/model"
+	"huawei-csi-driver/csi/backend/plugin"
+	"huawei-csi-driver/utils"
+	"huawei-csi-driver/utils/log"
+)
+
+type publishKey struct {
+	volumeId string
+	nodeId   string
+}
+
+type publishEntry struct {
+	fingerprint    string
+	publishContext map[string]string
+}
+
+// publishCache keeps the publish context of the published volumes, so that the repeated publish of the CO retries
+// is answered after a light check of the mapping instead of running the whole attach again
+type publishCache struct {
+	mutex   sync.Mutex
+	entries map[publishKey]publishEntry
+}
+
+var volumePublishCache = &publishCache{entries: make(map[publishKey]publishEntry)}
+
+// publishFingerprint identifies the parameters of the publish request, the cached publish context is only reused
+// for the request with identical parameters
+func publishFingerprint(req *csi.ControllerPublishVolumeRequest) string {
+	fingerprint, err := json.Marshal(struct {
+		Readonly      bool
+		Capability    string
+		VolumeContext map[string]string
+	}{req.GetReadonly(), req.GetVolumeCapability().String(), req.GetVolumeContext()})
+	if err != nil {
+		return ""
+	}
+	return string(fingerprint)
+}
+
+func (c *publishCache) load(req *csi.ControllerPublishVolumeRequest) (map[string]string, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	entry, exist := c.entries[publishKey{volumeId: req.GetVolumeId(), nodeId: req.GetNodeId()}]
+	if !exist || entry.fingerprint != publishFingerprint(req) {
+		return nil, false
+	}
+	return entry.publishContext, true
+}
+
+func (c *publishCache) store(req *csi.ControllerPublishVolumeRequest, publishContext map[string]string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.entries[publishKey{volumeId: req.GetVolumeId(), nodeId: req.GetNodeId()}] = publishEntry{
+		fingerprint:    publishFingerprint(req),
+		publishContext: publishContext,
+	}
+}
+
+func (c *publishCache) delete(volumeId, nodeId string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	delete(c.entries, publishKey{volumeId: volumeId, nodeId: nodeId})
+}
+
+// deleteVolume forgets the publish context of the volume on all nodes
+func (c *publishCache) deleteVolume(volumeId string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for key := range c.entries {
+		if key.volumeId == volumeId {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// loadCachedPublishContext returns the cached publish context of the repeated publish, if the backend is able to
+// check that the volume is still mapped to the node. The cache is dropped if the mapping is gone.
+func loadCachedPublishContext(ctx context.Context, req *csi.ControllerPublishVolumeRequest, bk *model.Backend,
+	parameters map[string]interface{}) (map[string]string, bool) {
+	checker, ok := bk.Plugin.(plugin.MappingChecker)
+	if !ok {
+		return nil, false
+	}
+
+	publishContext, exist := volumePublishCache.load(req)
+	if !exist {
+		return nil, false
+	}
+
+	_, volName := utils.SplitVolumeId(req.GetVolumeId())
+	mapped, err := checker.IsVolumeMapped(ctx, volName, parameters)
+	if err != nil || !mapped {
+		log.AddContext(ctx).Infof("Volume %s is not mapped to node %s as cached, mapped: %t, error: %v, "+
+			"publish it again", req.GetVolumeId(), req.GetNodeId(), mapped, err)
+		volumePublishCache.delete(req.GetVolumeId(), req.GetNodeId())
+		return nil, false
+	}
+
+	return publishContext, true
+}
@@ -0,0 +1,73 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package driver
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+
+	"huawei-csi-driver/csi/backend/model"
+	"huawei-csi-driver/csi/backend/plugin"
+)
+
+type fakeMappingPlugin struct {
+	plugin.Plugin
+	mapped bool
+	err    error
+}
+
+func (p *fakeMappingPlugin) IsVolumeMapped(context.Context, string, map[string]interface{}) (bool, error) {
+	return p.mapped, p.err
+}
+
+func TestLoadCachedPublishContext(t *testing.T) {
+	cached := map[string]string{"publishInfo": "{}"}
+	newRequest := func(readonly bool) *csi.ControllerPublishVolumeRequest {
+		return &csi.ControllerPublishVolumeRequest{VolumeId: "backend.pvc-1", NodeId: "node-1", Readonly: readonly}
+	}
+
+	cases := []struct {
+		name       string
+		plugin     plugin.Plugin
+		request    *csi.ControllerPublishVolumeRequest
+		want       map[string]string
+		wantExist  bool
+		wantCached bool
+	}{
+		{"mapped", &fakeMappingPlugin{mapped: true}, newRequest(false), cached, true, true},
+		{"parameters changed", &fakeMappingPlugin{mapped: true}, newRequest(true), nil, false, true},
+		{"mapping gone", &fakeMappingPlugin{mapped: false}, newRequest(false), nil, false, false},
+		{"check failed", &fakeMappingPlugin{err: errors.New("check failed")}, newRequest(false), nil, false, false},
+		{"check unsupported", &fakeInventoryPlugin{}, newRequest(false), nil, false, true},
+	}
+
+	for _, c := range cases {
+		volumePublishCache.store(newRequest(false), cached)
+		got, exist := loadCachedPublishContext(context.TODO(), c.request, &model.Backend{Plugin: c.plugin}, nil)
+		if exist != c.wantExist || !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%s: loadCachedPublishContext() = %v, %t, want %v, %t", c.name, got, exist, c.want, c.wantExist)
+		}
+		if _, cachedExist := volumePublishCache.load(newRequest(false)); cachedExist != c.wantCached {
+			t.Errorf("%s: cached = %t, want %t", c.name, cachedExist, c.wantCached)
+		}
+		volumePublishCache.deleteVolume("backend.pvc-1")
+	}
+}
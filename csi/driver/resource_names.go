@@ -0,0 +1,111 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package driver
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"huawei-csi-driver/csi/backend/model"
+	"huawei-csi-driver/utils"
+	"huawei-csi-driver/utils/log"
+)
+
+// resourceNames holds the human-readable names of the resources an operation works on. The storage errors
+// usually only reference the array ids, so the names are appended to the user-facing errors for debugging.
+type resourceNames struct {
+	backend    string
+	pool       string
+	volume     string
+	pv         string
+	pvc        string
+	volumeId   string
+	snapshot   string
+	snapshotId string
+}
+
+// newVolumeResourceNames resolves the names by the volume id and the backend which the volume belongs to,
+// and the names of the PV and PVC by the volume handle
+func (d *Driver) newVolumeResourceNames(ctx context.Context, volumeId string, bk *model.Backend) resourceNames {
+	backendName, volName := utils.SplitVolumeId(volumeId)
+	names := resourceNames{backend: backendName, volume: volName, volumeId: volumeId}
+
+	// the pool of an existing volume can be resolved without querying the storage only if the backend has one pool
+	if bk != nil && len(bk.Pools) == 1 {
+		names.pool = bk.Pools[0].Name
+	}
+
+	if d.k8sUtils == nil {
+		return names
+	}
+
+	pv, err := d.k8sUtils.GetPVByVolumeHandle(ctx, d.name, volumeId)
+	if err != nil || pv == nil {
+		log.AddContext(ctx).Debugf("Get PV of volume %s for the error message failed, error: %v", volumeId, err)
+		return names
+	}
+
+	// the volume is usually named after the PV, which is not repeated then
+	if pv.Name != volName {
+		names.pv = pv.Name
+	}
+	if claim := pv.Spec.ClaimRef; claim != nil {
+		names.pvc = claim.Namespace + "/" + claim.Name
+	}
+
+	return names
+}
+
+// newSnapshotResourceNames resolves the names by the snapshot id
+func newSnapshotResourceNames(snapshotId string) resourceNames {
+	backendName, _, snapshotName := utils.SplitSnapshotId(snapshotId)
+	return resourceNames{backend: backendName, snapshot: snapshotName, snapshotId: snapshotId}
+}
+
+func (n resourceNames) String() string {
+	fields := []struct {
+		key   string
+		value string
+	}{
+		{"backend", n.backend},
+		{"pool", n.pool},
+		{"volume", n.volume},
+		{"pv", n.pv},
+		{"pvc", n.pvc},
+		{"volume id", n.volumeId},
+		{"snapshot", n.snapshot},
+		{"snapshot id", n.snapshotId},
+	}
+
+	var names []string
+	for _, field := range fields {
+		if field.value != "" {
+			names = append(names, fmt.Sprintf("%s: %s", field.key, field.value))
+		}
+	}
+
+	return strings.Join(names, ", ")
+}
+
+// statusError returns the grpc error whose message includes the names of the resources
+func (n resourceNames) statusError(code codes.Code, err error) error {
+	return status.Errorf(code, "%v (%s)", err, n)
+}
@@ -0,0 +1,79 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package driver
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	coreV1 "k8s.io/api/core/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"huawei-csi-driver/csi/backend/model"
+	"huawei-csi-driver/utils/k8sutils"
+)
+
+type fakeResourceNamesK8sUtils struct {
+	k8sutils.Interface
+	pvs map[string]*coreV1.PersistentVolume
+}
+
+func (f *fakeResourceNamesK8sUtils) GetPVByVolumeHandle(_ context.Context, _, volumeHandle string) (
+	*coreV1.PersistentVolume, error) {
+	return f.pvs[volumeHandle], nil
+}
+
+func TestResourceNamesStatusError(t *testing.T) {
+	onePoolBackend := &model.Backend{Pools: []*model.StoragePool{{Name: "pool1"}}}
+	twoPoolsBackend := &model.Backend{Pools: []*model.StoragePool{{Name: "pool1"}, {Name: "pool2"}}}
+	d := &Driver{name: "csi.huawei.com", k8sUtils: &fakeResourceNamesK8sUtils{pvs: map[string]*coreV1.PersistentVolume{
+		"backend1.pvc-2": {ObjectMeta: metaV1.ObjectMeta{Name: "pvc-2"},
+			Spec: coreV1.PersistentVolumeSpec{ClaimRef: &coreV1.ObjectReference{Namespace: "default", Name: "data"}}},
+		"backend1.lun-3": {ObjectMeta: metaV1.ObjectMeta{Name: "pv-static"},
+			Spec: coreV1.PersistentVolumeSpec{ClaimRef: &coreV1.ObjectReference{Namespace: "default", Name: "logs"}}},
+	}}}
+	ctx := context.Background()
+	tests := []struct {
+		name  string
+		names resourceNames
+		want  string
+	}{
+		{"SinglePoolBackend", d.newVolumeResourceNames(ctx, "backend1.pvc-1", onePoolBackend),
+			"lun 12 not found (backend: backend1, pool: pool1, volume: pvc-1, volume id: backend1.pvc-1)"},
+		{"MultiPoolsBackend", d.newVolumeResourceNames(ctx, "backend1.pvc-1", twoPoolsBackend),
+			"lun 12 not found (backend: backend1, volume: pvc-1, volume id: backend1.pvc-1)"},
+		{"BoundVolume", d.newVolumeResourceNames(ctx, "backend1.pvc-2", twoPoolsBackend),
+			"lun 12 not found (backend: backend1, volume: pvc-2, pvc: default/data, volume id: backend1.pvc-2)"},
+		{"StaticVolume", d.newVolumeResourceNames(ctx, "backend1.lun-3", twoPoolsBackend),
+			"lun 12 not found (backend: backend1, volume: lun-3, pv: pv-static, pvc: default/logs, " +
+				"volume id: backend1.lun-3)"},
+		{"Snapshot", newSnapshotResourceNames("backend1.12.snapshot-1"),
+			"lun 12 not found (backend: backend1, snapshot: snapshot-1, snapshot id: backend1.12.snapshot-1)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.names.statusError(codes.Internal, errors.New("lun 12 not found"))
+			if got := status.Convert(err); got.Code() != codes.Internal || got.Message() != tt.want {
+				t.Errorf("statusError() = %v, want %s", err, tt.want)
+			}
+		})
+	}
+}
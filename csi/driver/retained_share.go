@@ -0,0 +1,193 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package driver
+
+import (
+	"context"
+	"fmt"
+
+	coreV1 "k8s.io/api/core/v1"
+
+	"huawei-csi-driver/csi/backend/plugin"
+	"huawei-csi-driver/pkg/constants"
+	"huawei-csi-driver/utils"
+	"huawei-csi-driver/utils/log"
+)
+
+const (
+	nfsShareDeletedReason  = "NFSShareDeleted"
+	nfsShareRestoredReason = "NFSShareRestored"
+)
+
+// WatchRetainedVolumeShares deletes the nfs shares of the released volumes whose reclaim policy is Retain,
+// DeleteVolume is not called for them so their shares are still accessible although no PVC uses them,
+// the filesystems and their data are kept for the recovery by the admins. Once such a volume is bound again,
+// its nfs share is restored so that the volume is able to be mounted.
+func (d *Driver) WatchRetainedVolumeShares(ctx context.Context, stopCh <-chan struct{}) {
+	log.AddContext(ctx).Infoln("Start to watch the nfs shares of retained volumes")
+	d.k8sUtils.WatchPersistentVolumes(ctx, func(pv *coreV1.PersistentVolume) {
+		d.deleteRetainedVolumeShare(ctx, pv)
+		d.restoreRetainedVolumeShare(ctx, pv)
+	}, stopCh)
+}
+
+// isRetainedVolumeShareDeletable checks whether the volume is released and retained,
+// and its nfs share is not deleted yet
+func isRetainedVolumeShareDeletable(pv *coreV1.PersistentVolume, driverName string) bool {
+	if pv.Spec.CSI == nil || pv.Spec.CSI.Driver != driverName {
+		return false
+	}
+
+	return pv.Status.Phase == coreV1.VolumeReleased &&
+		pv.Spec.PersistentVolumeReclaimPolicy == coreV1.PersistentVolumeReclaimRetain &&
+		pv.Annotations[constants.NFSShareAnnotation] != constants.NFSShareDeleted
+}
+
+// isRetainedVolumeShareRestorable checks whether the nfs share of the volume is deleted and the volume is bound again
+func isRetainedVolumeShareRestorable(pv *coreV1.PersistentVolume, driverName string) bool {
+	if pv.Spec.CSI == nil || pv.Spec.CSI.Driver != driverName {
+		return false
+	}
+
+	return pv.Status.Phase == coreV1.VolumeBound &&
+		pv.Annotations[constants.NFSShareAnnotation] == constants.NFSShareDeleted
+}
+
+func (d *Driver) deleteRetainedVolumeShare(ctx context.Context, pv *coreV1.PersistentVolume) {
+	if !isRetainedVolumeShareDeletable(pv, d.name) {
+		return
+	}
+
+	volumeId := pv.Spec.CSI.VolumeHandle
+	backendName, volName := utils.SplitVolumeId(volumeId)
+	backend, err := d.backendSelector.SelectBackend(ctx, backendName)
+	if backend == nil || err != nil {
+		log.AddContext(ctx).Errorf("Backend %s of volume %s doesn't exist, error: %v", backendName, volumeId, err)
+		return
+	}
+
+	// only the nas backends are able to delete the nfs shares
+	deleter, ok := backend.Plugin.(plugin.NFSShareDeleter)
+	if !ok {
+		return
+	}
+
+	log.AddContext(ctx).Infof("Start to delete nfs share of retained volume %s", volumeId)
+	if err = deleter.DeleteNFSShare(ctx, volName); err != nil {
+		log.AddContext(ctx).Errorf("Delete nfs share of retained volume %s error: %v", volumeId, err)
+		return
+	}
+
+	err = d.k8sUtils.UpdatePVAnnotations(ctx, pv.Name, map[string]string{
+		constants.NFSShareAnnotation: constants.NFSShareDeleted,
+	})
+	if err != nil {
+		log.AddContext(ctx).Errorf("Record nfs share deletion of volume %s error: %v", volumeId, err)
+		return
+	}
+
+	msg := fmt.Sprintf("NFS share of the retained volume %s is deleted, the filesystem and its data are kept",
+		volName)
+	if err = d.k8sUtils.RecordPVEvent(ctx, pv.Name, coreV1.EventTypeNormal, nfsShareDeletedReason,
+		msg); err != nil {
+		log.AddContext(ctx).Warningf("Record %s event of volume %s failed, error: %v",
+			nfsShareDeletedReason, volumeId, err)
+	}
+
+	log.AddContext(ctx).Infof("NFS share of retained volume %s is deleted", volumeId)
+}
+
+func (d *Driver) restoreRetainedVolumeShare(ctx context.Context, pv *coreV1.PersistentVolume) {
+	if !isRetainedVolumeShareRestorable(pv, d.name) {
+		return
+	}
+
+	volumeId := pv.Spec.CSI.VolumeHandle
+	backendName, volName := utils.SplitVolumeId(volumeId)
+	backend, err := d.backendSelector.SelectBackend(ctx, backendName)
+	if backend == nil || err != nil {
+		log.AddContext(ctx).Errorf("Backend %s of volume %s doesn't exist, error: %v", backendName, volumeId, err)
+		return
+	}
+
+	restorer, ok := backend.Plugin.(plugin.NFSShareDeleter)
+	if !ok {
+		log.AddContext(ctx).Errorf("Backend %s of volume %s is not able to restore the nfs share",
+			backendName, volumeId)
+		return
+	}
+
+	parameters, err := d.getRetainedShareParameters(ctx, pv)
+	if err != nil {
+		log.AddContext(ctx).Errorf("Get share parameters of retained volume %s error: %v", volumeId, err)
+		return
+	}
+
+	log.AddContext(ctx).Infof("Start to restore nfs share of retained volume %s", volumeId)
+	if err = restorer.RestoreNFSShare(ctx, volName, parameters); err != nil {
+		log.AddContext(ctx).Errorf("Restore nfs share of retained volume %s error: %v", volumeId, err)
+		return
+	}
+
+	clientACL, clients, err := getNfsAllowedClientACL(backend.Plugin, parameters)
+	if err != nil {
+		log.AddContext(ctx).Errorf("Get nfs allowed clients of retained volume %s error: %v", volumeId, err)
+		return
+	}
+	if clientACL != nil {
+		if err = clientACL.UpdateNFSShareClientACL(ctx, volName, clients); err != nil {
+			log.AddContext(ctx).Errorf("Restore nfs allowed clients of retained volume %s error: %v",
+				volumeId, err)
+			return
+		}
+	}
+
+	if err = d.k8sUtils.RemovePVAnnotations(ctx, pv.Name, []string{constants.NFSShareAnnotation}); err != nil {
+		log.AddContext(ctx).Errorf("Record nfs share restoration of volume %s error: %v", volumeId, err)
+		return
+	}
+
+	msg := fmt.Sprintf("NFS share of the retained volume %s is restored as the volume is bound again", volName)
+	if err = d.k8sUtils.RecordPVEvent(ctx, pv.Name, coreV1.EventTypeNormal, nfsShareRestoredReason,
+		msg); err != nil {
+		log.AddContext(ctx).Warningf("Record %s event of volume %s failed, error: %v",
+			nfsShareRestoredReason, volumeId, err)
+	}
+
+	log.AddContext(ctx).Infof("NFS share of retained volume %s is restored", volumeId)
+}
+
+// getRetainedShareParameters returns the parameters of the StorageClass which the volume is provisioned by,
+// and the nfs allowed clients of the volume attributes, to restore the nfs share as it is created
+func (d *Driver) getRetainedShareParameters(ctx context.Context,
+	pv *coreV1.PersistentVolume) (map[string]interface{}, error) {
+	scParameters, err := d.k8sUtils.GetVolumeStorageClassParameters(ctx, d.name, pv.Spec.CSI.VolumeHandle)
+	if err != nil {
+		return nil, err
+	}
+	if scParameters == nil {
+		return nil, fmt.Errorf("volume %s is not provisioned by any StorageClass", pv.Name)
+	}
+
+	parameters := utils.CopyMap(scParameters)
+	capacity := pv.Spec.Capacity[coreV1.ResourceStorage]
+	parameters["size"] = capacity.Value()
+	if allowedClients, ok := pv.Spec.CSI.VolumeAttributes[nfsAllowedClientsKey]; ok {
+		parameters[nfsAllowedClientsKey] = allowedClients
+	}
+	return parameters, nil
+}
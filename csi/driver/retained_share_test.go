@@ -0,0 +1,162 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package driver
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	coreV1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"huawei-csi-driver/csi/backend/model"
+	"huawei-csi-driver/csi/backend/plugin"
+	"huawei-csi-driver/pkg/constants"
+	"huawei-csi-driver/utils/k8sutils"
+)
+
+type fakeShareK8sUtils struct {
+	k8sutils.Interface
+	annotations map[string]string
+	events      []string
+}
+
+func (f *fakeShareK8sUtils) GetVolumeStorageClassParameters(context.Context, string, string) (
+	map[string]string, error) {
+	return map[string]string{"authClient": "*", "allSquash": "no_all_squash"}, nil
+}
+
+func (f *fakeShareK8sUtils) RemovePVAnnotations(_ context.Context, _ string, keys []string) error {
+	for _, key := range keys {
+		delete(f.annotations, key)
+	}
+	return nil
+}
+
+func (f *fakeShareK8sUtils) RecordPVEvent(_ context.Context, _, _, reason, _ string) error {
+	f.events = append(f.events, reason)
+	return nil
+}
+
+type fakeSharePlugin struct {
+	plugin.Plugin
+	restored   map[string]interface{}
+	aclClients []string
+}
+
+func (p *fakeSharePlugin) DeleteNFSShare(context.Context, string) error {
+	return nil
+}
+
+func (p *fakeSharePlugin) RestoreNFSShare(_ context.Context, _ string, parameters map[string]interface{}) error {
+	p.restored = parameters
+	return nil
+}
+
+func (p *fakeSharePlugin) UpdateNFSShareClientACL(_ context.Context, _ string, clients []string) error {
+	p.aclClients = clients
+	return nil
+}
+
+func TestIsRetainedVolumeShareDeletable(t *testing.T) {
+	tests := []struct {
+		name        string
+		driver      string
+		phase       coreV1.PersistentVolumePhase
+		policy      coreV1.PersistentVolumeReclaimPolicy
+		annotations map[string]string
+		want        bool
+	}{
+		{"ReleasedAndRetained", "csi.huawei.com", coreV1.VolumeReleased,
+			coreV1.PersistentVolumeReclaimRetain, nil, true},
+		{"Bound", "csi.huawei.com", coreV1.VolumeBound, coreV1.PersistentVolumeReclaimRetain, nil, false},
+		{"ReleasedAndDeleted", "csi.huawei.com", coreV1.VolumeReleased,
+			coreV1.PersistentVolumeReclaimDelete, nil, false},
+		{"ShareDeleted", "csi.huawei.com", coreV1.VolumeReleased, coreV1.PersistentVolumeReclaimRetain,
+			map[string]string{constants.NFSShareAnnotation: constants.NFSShareDeleted}, false},
+		{"OtherDriver", "other.csi.com", coreV1.VolumeReleased, coreV1.PersistentVolumeReclaimRetain, nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pv := &coreV1.PersistentVolume{
+				ObjectMeta: metaV1.ObjectMeta{Name: "pv-1", Annotations: tt.annotations},
+				Spec: coreV1.PersistentVolumeSpec{
+					PersistentVolumeReclaimPolicy: tt.policy,
+					PersistentVolumeSource: coreV1.PersistentVolumeSource{
+						CSI: &coreV1.CSIPersistentVolumeSource{Driver: tt.driver, VolumeHandle: "nas.pvc-1"},
+					},
+				},
+				Status: coreV1.PersistentVolumeStatus{Phase: tt.phase},
+			}
+			if got := isRetainedVolumeShareDeletable(pv, "csi.huawei.com"); got != tt.want {
+				t.Errorf("isRetainedVolumeShareDeletable() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRestoreRetainedVolumeShare(t *testing.T) {
+	k8sUtils := &fakeShareK8sUtils{
+		annotations: map[string]string{constants.NFSShareAnnotation: constants.NFSShareDeleted},
+	}
+	sharePlugin := &fakeSharePlugin{}
+	d := &Driver{
+		name:     "csi.huawei.com",
+		k8sUtils: k8sUtils,
+		backendSelector: &fakeBackendSelector{backends: map[string]*model.Backend{
+			"nas": {Name: "nas", Storage: "oceanstor-nas", Plugin: sharePlugin},
+		}},
+	}
+	pv := &coreV1.PersistentVolume{
+		ObjectMeta: metaV1.ObjectMeta{Name: "pv-1", Annotations: k8sUtils.annotations},
+		Spec: coreV1.PersistentVolumeSpec{
+			Capacity: coreV1.ResourceList{coreV1.ResourceStorage: resource.MustParse("1Gi")},
+			PersistentVolumeSource: coreV1.PersistentVolumeSource{
+				CSI: &coreV1.CSIPersistentVolumeSource{Driver: "csi.huawei.com", VolumeHandle: "nas.pvc-1",
+					VolumeAttributes: map[string]string{nfsAllowedClientsKey: "10.0.0.1,10.0.0.2"}},
+			},
+		},
+		Status: coreV1.PersistentVolumeStatus{Phase: coreV1.VolumeReleased},
+	}
+
+	// the share is not restored until the volume is bound again
+	d.restoreRetainedVolumeShare(context.Background(), pv)
+	if sharePlugin.restored != nil {
+		t.Fatalf("restoreRetainedVolumeShare() restored the share of the released volume")
+	}
+
+	pv.Status.Phase = coreV1.VolumeBound
+	d.restoreRetainedVolumeShare(context.Background(), pv)
+
+	wantParameters := map[string]interface{}{"authClient": "*", "allSquash": "no_all_squash",
+		"size": int64(1024 * 1024 * 1024), nfsAllowedClientsKey: "10.0.0.1,10.0.0.2"}
+	if !reflect.DeepEqual(sharePlugin.restored, wantParameters) {
+		t.Errorf("restoreRetainedVolumeShare() parameters = %v, want %v", sharePlugin.restored, wantParameters)
+	}
+	if !reflect.DeepEqual(sharePlugin.aclClients, []string{"10.0.0.1", "10.0.0.2"}) {
+		t.Errorf("restoreRetainedVolumeShare() clients = %v, want [10.0.0.1 10.0.0.2]", sharePlugin.aclClients)
+	}
+	if _, exist := k8sUtils.annotations[constants.NFSShareAnnotation]; exist {
+		t.Errorf("restoreRetainedVolumeShare() kept annotation %s", constants.NFSShareAnnotation)
+	}
+	if !reflect.DeepEqual(k8sUtils.events, []string{nfsShareRestoredReason}) {
+		t.Errorf("restoreRetainedVolumeShare() events = %v, want [%s]", k8sUtils.events, nfsShareRestoredReason)
+	}
+}
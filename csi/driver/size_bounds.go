@@ -0,0 +1,125 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package driver
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"huawei-csi-driver/utils/log"
+)
+
+const (
+	minSizeKey = "minSize"
+	maxSizeKey = "maxSize"
+)
+
+// sizeBounds is the capacity range of the volumes allowed by the StorageClass, the zero bound means no limit
+type sizeBounds struct {
+	minSize int64
+	maxSize int64
+}
+
+func parseSizeBound(parameters map[string]string, key string) (int64, error) {
+	value, exist := parameters[key]
+	if !exist {
+		return 0, nil
+	}
+
+	quantity, err := resource.ParseQuantity(value)
+	if err != nil {
+		return 0, fmt.Errorf("StorageClass parameter \"%s\": [%s] invalid, %v", key, value, err)
+	}
+
+	if quantity.Sign() <= 0 {
+		return 0, fmt.Errorf("StorageClass parameter \"%s\": [%s] invalid, it must be positive", key, value)
+	}
+
+	return quantity.Value(), nil
+}
+
+// parseSizeBounds parses the minSize and maxSize parameters of the StorageClass
+func parseSizeBounds(parameters map[string]string) (sizeBounds, error) {
+	var bounds sizeBounds
+	var err error
+	if bounds.minSize, err = parseSizeBound(parameters, minSizeKey); err != nil {
+		return bounds, err
+	}
+
+	if bounds.maxSize, err = parseSizeBound(parameters, maxSizeKey); err != nil {
+		return bounds, err
+	}
+
+	if bounds.maxSize > 0 && bounds.minSize > bounds.maxSize {
+		return bounds, fmt.Errorf("StorageClass parameter \"%s\": [%s] is larger than \"%s\": [%s]",
+			minSizeKey, parameters[minSizeKey], maxSizeKey, parameters[maxSizeKey])
+	}
+
+	return bounds, nil
+}
+
+func (b sizeBounds) contains(size int64) bool {
+	return size >= b.minSize && (b.maxSize == 0 || size <= b.maxSize)
+}
+
+func (b sizeBounds) String() string {
+	maxSize := "unlimited"
+	if b.maxSize > 0 {
+		maxSize = fmt.Sprintf("%d", b.maxSize)
+	}
+	return fmt.Sprintf("[%d, %s] bytes", b.minSize, maxSize)
+}
+
+// checkSizeBounds returns the InvalidArgument error if the bounds of the StorageClass are invalid,
+// and the OutOfRange error if the capacity is out of the bounds
+func checkSizeBounds(ctx context.Context, parameters map[string]string, capacityName string, size int64) error {
+	bounds, err := parseSizeBounds(parameters)
+	if err != nil {
+		log.AddContext(ctx).Errorln(err)
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if !bounds.contains(size) {
+		msg := fmt.Sprintf("%s %d bytes is out of the range %s allowed by the %s and %s of the StorageClass",
+			capacityName, size, bounds, minSizeKey, maxSizeKey)
+		log.AddContext(ctx).Errorln(msg)
+		return status.Error(codes.OutOfRange, msg)
+	}
+
+	return nil
+}
+
+// checkExpansionSizeBounds checks the expanded capacity against the bounds of the StorageClass of the volume.
+// The expansion is rejected if the StorageClass can't be got, since the bounds can't be checked.
+func (d *Driver) checkExpansionSizeBounds(ctx context.Context, volumeId string, size int64) error {
+	if d.k8sUtils == nil {
+		return nil
+	}
+
+	parameters, err := d.k8sUtils.GetVolumeStorageClassParameters(ctx, d.name, volumeId)
+	if err != nil {
+		msg := fmt.Sprintf("Get StorageClass parameters of volume %s failed, error: %v", volumeId, err)
+		log.AddContext(ctx).Errorln(msg)
+		return status.Error(codes.Internal, msg)
+	}
+
+	return checkSizeBounds(ctx, parameters, "expanded capacity", size)
+}
@@ -0,0 +1,51 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package driver
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestCheckSizeBounds(t *testing.T) {
+	tests := []struct {
+		name       string
+		parameters map[string]string
+		size       int64
+		wantCode   codes.Code
+	}{
+		{"NoBounds", map[string]string{}, 50 << 40, codes.OK},
+		{"WithinBounds", map[string]string{minSizeKey: "1Gi", maxSizeKey: "10Gi"}, 10 << 30, codes.OK},
+		{"SmallerThanMinSize", map[string]string{minSizeKey: "1Gi"}, 1 << 20, codes.OutOfRange},
+		{"LargerThanMaxSize", map[string]string{maxSizeKey: "10Ti"}, 50 << 40, codes.OutOfRange},
+		{"InvalidQuantity", map[string]string{maxSizeKey: "10TB!"}, 1 << 30, codes.InvalidArgument},
+		{"MinSizeLargerThanMaxSize", map[string]string{minSizeKey: "2Gi", maxSizeKey: "1Gi"}, 1 << 30,
+			codes.InvalidArgument},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkSizeBounds(context.TODO(), tt.parameters, "requested capacity", tt.size)
+			if got := status.Code(err); got != tt.wantCode {
+				t.Errorf("checkSizeBounds() code = %v, want %v, error: %v", got, tt.wantCode, err)
+			}
+		})
+	}
+}
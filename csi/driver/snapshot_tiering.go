@@ -0,0 +1,270 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package driver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	coreV1 "k8s.io/api/core/v1"
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"huawei-csi-driver/csi/app"
+	"huawei-csi-driver/csi/backend/cache"
+	"huawei-csi-driver/csi/backend/plugin"
+	"huawei-csi-driver/utils/log"
+)
+
+const (
+	tieredSnapshotsConfigmap = "huawei-csi-tiered-snapshots"
+	snapshotTieredReason     = "SnapshotTiered"
+
+	// the interval of scanning the snapshots to tier
+	snapshotTieringInterval = time.Hour
+	// the submitted tiering not completed in the timeout is submitted again
+	snapshotTieringTimeout = 24 * time.Hour
+
+	snapshotTieringStateSubmitted = "Submitted"
+	snapshotTieringStateTiered    = "Tiered"
+)
+
+// tieredSnapshotRecord is the audit record of a tiered snapshot in the configmap, the snapshot is recorded as
+// submitted until its tiering is confirmed to be completed
+type tieredSnapshotRecord struct {
+	Name        string `json:"name"`
+	State       string `json:"state"`
+	SubmittedAt string `json:"submittedAt"`
+	TieredAt    string `json:"tieredAt,omitempty"`
+}
+
+// tieredSnapshotKey returns the configmap key of the tiered snapshot, the snapshot IDs are unique in a backend
+func tieredSnapshotKey(backendName, snapshotID string) string {
+	return backendName + "." + snapshotID
+}
+
+// getRecordSnapshotID returns the snapshot ID of the record key if the record belongs to the backend
+func getRecordSnapshotID(backendName, key string) (string, bool) {
+	// the snapshot IDs do not contain dot, which distinguishes the backends whose names have the same prefix
+	id := strings.TrimPrefix(key, backendName+".")
+	if id == key || strings.Contains(id, ".") {
+		return "", false
+	}
+	return id, true
+}
+
+// getSnapshotsToTier returns the snapshots of the backend which are not tiered yet, and the keys of the records
+// whose snapshots do not exist anymore
+func getSnapshotsToTier(backendName string, snapshots, records map[string]string) (map[string]string, []string) {
+	toTier := make(map[string]string)
+	for id, name := range snapshots {
+		if _, tiered := records[tieredSnapshotKey(backendName, id)]; !tiered {
+			toTier[id] = name
+		}
+	}
+
+	var stale []string
+	for key := range records {
+		id, ok := getRecordSnapshotID(backendName, key)
+		if !ok {
+			continue
+		}
+		if _, exist := snapshots[id]; !exist {
+			stale = append(stale, key)
+		}
+	}
+	return toTier, stale
+}
+
+// getRemovedBackendRecords returns the keys of the records which do not belong to any of the backends
+func getRemovedBackendRecords(backendNames []string, records map[string]string) []string {
+	var removed []string
+	for key := range records {
+		var found bool
+		for _, name := range backendNames {
+			if _, found = getRecordSnapshotID(name, key); found {
+				break
+			}
+		}
+		if !found {
+			removed = append(removed, key)
+		}
+	}
+	return removed
+}
+
+// TierSnapshotsInBackground tiers the snapshots older than the given age to the object storage periodically,
+// until the stop channel is closed
+func (d *Driver) TierSnapshotsInBackground(ctx context.Context, after time.Duration, stopCh <-chan struct{}) {
+	log.AddContext(ctx).Infof("Start to tier the snapshots older than %s to the cloud", after)
+	wait.Until(func() { d.tierSnapshots(ctx, time.Now().Add(-after)) }, snapshotTieringInterval, stopCh)
+}
+
+func (d *Driver) tierSnapshots(ctx context.Context, before time.Time) {
+	configmap, err := d.getTieredSnapshotsConfigmap(ctx)
+	if err != nil {
+		log.AddContext(ctx).Warningf("Get configmap %s failed, skip tiering snapshots, error: %v",
+			tieredSnapshotsConfigmap, err)
+		return
+	}
+
+	var tiered, backendNames []string
+	var changed bool
+	for _, bk := range cache.BackendCacheProvider.List(ctx) {
+		backendNames = append(backendNames, bk.Name)
+		tierer, ok := bk.Plugin.(plugin.SnapshotTierer)
+		if !ok {
+			continue
+		}
+
+		snapshots, err := tierer.GetSnapshotsCreatedBefore(ctx, before)
+		if err != nil {
+			log.AddContext(ctx).Warningf("Get snapshots of backend %s failed, skip tiering them, error: %v",
+				bk.Name, err)
+			continue
+		}
+
+		toTier, stale := getSnapshotsToTier(bk.Name, snapshots, configmap.Data)
+		for _, key := range stale {
+			delete(configmap.Data, key)
+			changed = true
+		}
+
+		for id := range snapshots {
+			key := tieredSnapshotKey(bk.Name, id)
+			completed, recordChanged := confirmSnapshotTiering(ctx, tierer, bk.Name, configmap.Data, key)
+			changed = changed || recordChanged
+			if completed {
+				tiered = append(tiered, fmt.Sprintf("snapshot %s (ID %s) of backend %s", snapshots[id], id, bk.Name))
+			}
+		}
+
+		for id, name := range toTier {
+			if err := tierer.TierSnapshotToCloud(ctx, name); err != nil {
+				log.AddContext(ctx).Errorf("Tier snapshot %s of backend %s to cloud error: %v", name, bk.Name, err)
+				continue
+			}
+
+			record, err := json.Marshal(tieredSnapshotRecord{Name: name, State: snapshotTieringStateSubmitted,
+				SubmittedAt: time.Now().Format(time.RFC3339)})
+			if err != nil {
+				log.AddContext(ctx).Errorf("Marshal tiered snapshot record of %s error: %v", name, err)
+				continue
+			}
+			configmap.Data[tieredSnapshotKey(bk.Name, id)] = string(record)
+			changed = true
+		}
+	}
+
+	// the records of the removed backends are never pruned by the snapshots of the backends
+	for _, key := range getRemovedBackendRecords(backendNames, configmap.Data) {
+		delete(configmap.Data, key)
+		changed = true
+	}
+
+	if !changed {
+		return
+	}
+
+	configmap, err = d.k8sUtils.UpdateConfigmap(ctx, configmap)
+	if err != nil {
+		log.AddContext(ctx).Errorf("Record tiered snapshots in configmap %s error: %v", tieredSnapshotsConfigmap, err)
+		return
+	}
+
+	for _, snapshot := range tiered {
+		msg := fmt.Sprintf("The %s is tiered to the cloud", snapshot)
+		log.AddContext(ctx).Infoln(msg)
+		err = d.k8sUtils.RecordConfigmapEvent(ctx, configmap, coreV1.EventTypeNormal, snapshotTieredReason, msg)
+		if err != nil {
+			log.AddContext(ctx).Warningf("Record event of the tiered %s failed, error: %v", snapshot, err)
+		}
+	}
+}
+
+// confirmSnapshotTiering checks the submitted tiering of the recorded snapshot, the record is marked as tiered
+// once the tiering is completed, and removed to submit the tiering again if it is not completed in the timeout.
+// It returns whether the tiering is confirmed to be completed in this check, and whether the record is changed.
+func confirmSnapshotTiering(ctx context.Context, tierer plugin.SnapshotTierer, backendName string,
+	records map[string]string, key string) (bool, bool) {
+	data, exist := records[key]
+	if !exist {
+		return false, false
+	}
+
+	var record tieredSnapshotRecord
+	if err := json.Unmarshal([]byte(data), &record); err != nil {
+		log.AddContext(ctx).Warningf("Unmarshal tiered snapshot record %s error: %v, submit it again", key, err)
+		delete(records, key)
+		return false, true
+	}
+
+	if record.State != snapshotTieringStateSubmitted {
+		return false, false
+	}
+
+	completed, err := tierer.IsSnapshotTiered(ctx, record.Name)
+	if err != nil {
+		log.AddContext(ctx).Warningf("Check tiering of snapshot %s of backend %s failed, error: %v",
+			record.Name, backendName, err)
+		return false, false
+	}
+
+	if !completed {
+		submittedAt, err := time.Parse(time.RFC3339, record.SubmittedAt)
+		if err != nil || time.Since(submittedAt) > snapshotTieringTimeout {
+			log.AddContext(ctx).Warningf("Tiering of snapshot %s of backend %s is not completed in %s, "+
+				"submit it again", record.Name, backendName, snapshotTieringTimeout)
+			delete(records, key)
+			return false, true
+		}
+		return false, false
+	}
+
+	record.State = snapshotTieringStateTiered
+	record.TieredAt = time.Now().Format(time.RFC3339)
+	updated, err := json.Marshal(record)
+	if err != nil {
+		log.AddContext(ctx).Errorf("Marshal tiered snapshot record of %s error: %v", record.Name, err)
+		return false, false
+	}
+	records[key] = string(updated)
+	return true, true
+}
+
+// getTieredSnapshotsConfigmap returns the configmap recording the tiered snapshots, it is created if not exist
+func (d *Driver) getTieredSnapshotsConfigmap(ctx context.Context) (*coreV1.ConfigMap, error) {
+	namespace := app.GetGlobalConfig().Namespace
+	configmap, err := d.k8sUtils.GetConfigmap(ctx, tieredSnapshotsConfigmap, namespace)
+	if apiErrors.IsNotFound(err) {
+		configmap, err = d.k8sUtils.CreateConfigmap(ctx, &coreV1.ConfigMap{
+			ObjectMeta: metaV1.ObjectMeta{Name: tieredSnapshotsConfigmap, Namespace: namespace},
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if configmap.Data == nil {
+		configmap.Data = make(map[string]string)
+	}
+	return configmap, nil
+}
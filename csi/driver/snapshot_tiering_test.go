@@ -0,0 +1,149 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package driver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"huawei-csi-driver/csi/backend/plugin"
+)
+
+type fakeSnapshotTierer struct {
+	plugin.SnapshotTierer
+	tiered bool
+	err    error
+}
+
+func (f *fakeSnapshotTierer) IsSnapshotTiered(context.Context, string) (bool, error) {
+	return f.tiered, f.err
+}
+
+func marshalTieredSnapshotRecord(t *testing.T, state string, submittedAt time.Time) string {
+	data, err := json.Marshal(tieredSnapshotRecord{Name: "snapshot-1", State: state,
+		SubmittedAt: submittedAt.Format(time.RFC3339)})
+	if err != nil {
+		t.Fatalf("marshal record error: %v", err)
+	}
+	return string(data)
+}
+
+func TestGetSnapshotsToTier(t *testing.T) {
+	cases := []struct {
+		name       string
+		snapshots  map[string]string
+		records    map[string]string
+		wantToTier map[string]string
+		wantStale  []string
+	}{
+		{
+			name:       "nothing tiered",
+			snapshots:  map[string]string{"1": "snapshot-1", "2": "snapshot-2"},
+			records:    map[string]string{},
+			wantToTier: map[string]string{"1": "snapshot-1", "2": "snapshot-2"},
+		},
+		{
+			name:       "partially tiered",
+			snapshots:  map[string]string{"1": "snapshot-1", "2": "snapshot-2"},
+			records:    map[string]string{"backend.1": "{}"},
+			wantToTier: map[string]string{"2": "snapshot-2"},
+		},
+		{
+			name:       "tiered snapshot deleted",
+			snapshots:  map[string]string{"2": "snapshot-2"},
+			records:    map[string]string{"backend.1": "{}", "backend.2": "{}"},
+			wantToTier: map[string]string{},
+			wantStale:  []string{"backend.1"},
+		},
+		{
+			name:       "records of other backends",
+			snapshots:  map[string]string{},
+			records:    map[string]string{"other.1": "{}", "backend.other.1": "{}"},
+			wantToTier: map[string]string{},
+		},
+	}
+
+	for _, c := range cases {
+		toTier, stale := getSnapshotsToTier("backend", c.snapshots, c.records)
+		sort.Strings(stale)
+		if !reflect.DeepEqual(toTier, c.wantToTier) || !reflect.DeepEqual(stale, c.wantStale) {
+			t.Errorf("%s: getSnapshotsToTier() = %v, %v, want %v, %v",
+				c.name, toTier, stale, c.wantToTier, c.wantStale)
+		}
+	}
+}
+
+func TestGetRemovedBackendRecords(t *testing.T) {
+	records := map[string]string{"backend.1": "{}", "removed.1": "{}", "backend.removed.1": "{}"}
+
+	removed := getRemovedBackendRecords([]string{"backend"}, records)
+	sort.Strings(removed)
+
+	want := []string{"backend.removed.1", "removed.1"}
+	if !reflect.DeepEqual(removed, want) {
+		t.Errorf("getRemovedBackendRecords() = %v, want %v", removed, want)
+	}
+}
+
+func TestConfirmSnapshotTiering(t *testing.T) {
+	cases := []struct {
+		name          string
+		record        string
+		tierer        *fakeSnapshotTierer
+		wantCompleted bool
+		wantChanged   bool
+		wantState     string
+	}{
+		{"TieringCompleted", marshalTieredSnapshotRecord(t, snapshotTieringStateSubmitted, time.Now()),
+			&fakeSnapshotTierer{tiered: true}, true, true, snapshotTieringStateTiered},
+		{"TieringInProgress", marshalTieredSnapshotRecord(t, snapshotTieringStateSubmitted, time.Now()),
+			&fakeSnapshotTierer{}, false, false, snapshotTieringStateSubmitted},
+		{"TieringTimeout", marshalTieredSnapshotRecord(t, snapshotTieringStateSubmitted,
+			time.Now().Add(-2*snapshotTieringTimeout)), &fakeSnapshotTierer{}, false, true, ""},
+		{"CheckFailed", marshalTieredSnapshotRecord(t, snapshotTieringStateSubmitted, time.Now()),
+			&fakeSnapshotTierer{err: errors.New("check failed")}, false, false, snapshotTieringStateSubmitted},
+		{"AlreadyTiered", marshalTieredSnapshotRecord(t, snapshotTieringStateTiered, time.Now()),
+			&fakeSnapshotTierer{tiered: true}, false, false, snapshotTieringStateTiered},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			records := map[string]string{"backend.1": c.record}
+			completed, changed := confirmSnapshotTiering(context.Background(), c.tierer, "backend", records,
+				"backend.1")
+			if completed != c.wantCompleted || changed != c.wantChanged {
+				t.Errorf("confirmSnapshotTiering() = %v, %v, want %v, %v",
+					completed, changed, c.wantCompleted, c.wantChanged)
+			}
+
+			var record tieredSnapshotRecord
+			if data, exist := records["backend.1"]; exist {
+				if err := json.Unmarshal([]byte(data), &record); err != nil {
+					t.Fatalf("unmarshal record error: %v", err)
+				}
+			}
+			if record.State != c.wantState {
+				t.Errorf("record state = %q, want %q", record.State, c.wantState)
+			}
+		})
+	}
+}
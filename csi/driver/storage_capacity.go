@@ -0,0 +1,35 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package driver
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"huawei-csi-driver/csi/backend/handler"
+	"huawei-csi-driver/utils/log"
+)
+
+// PublishStorageCapacitiesInBackground publishes the free capacity of the pools by the CSIStorageCapacity objects
+// periodically, until the stop channel is closed
+func (d *Driver) PublishStorageCapacitiesInBackground(ctx context.Context, interval time.Duration,
+	stopCh <-chan struct{}) {
+	log.AddContext(ctx).Infof("Start to publish the storage capacities every %s", interval)
+	wait.Until(func() { handler.PublishStorageCapacities(ctx) }, interval, stopCh)
+}
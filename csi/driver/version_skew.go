@@ -0,0 +1,165 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package driver
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	coreV1 "k8s.io/api/core/v1"
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"huawei-csi-driver/csi/app"
+	"huawei-csi-driver/utils/log"
+)
+
+const (
+	controllerVersionConfigmap = "huawei-csi-version"
+	controllerVersionKey       = "controllerVersion"
+	versionSkewReason          = "VersionSkew"
+
+	// the node plugin is compatible with the controller plugin of the adjacent major versions
+	maxMajorVersionSkew = 1
+)
+
+func majorVersion(version string) (int, error) {
+	major, _, _ := strings.Cut(strings.TrimPrefix(strings.TrimSpace(version), "v"), ".")
+	return strconv.Atoi(major)
+}
+
+// isVersionCompatible checks whether the major versions of the node and the controller differ by at most one
+func isVersionCompatible(nodeVersion, controllerVersion string) (bool, error) {
+	nodeMajor, err := majorVersion(nodeVersion)
+	if err != nil {
+		return false, fmt.Errorf("invalid node version %s, %v", nodeVersion, err)
+	}
+
+	controllerMajor, err := majorVersion(controllerVersion)
+	if err != nil {
+		return false, fmt.Errorf("invalid controller version %s, %v", controllerVersion, err)
+	}
+
+	skew := nodeMajor - controllerMajor
+	return -maxMajorVersionSkew <= skew && skew <= maxMajorVersionSkew, nil
+}
+
+// PublishControllerVersion records the version of the controller into the configmap,
+// so that the node plugins are able to detect the version skew after a rolling upgrade
+func (d *Driver) PublishControllerVersion(ctx context.Context) {
+	namespace := app.GetGlobalConfig().Namespace
+	configmap, err := d.k8sUtils.GetConfigmap(ctx, controllerVersionConfigmap, namespace)
+	if apiErrors.IsNotFound(err) {
+		_, err = d.k8sUtils.CreateConfigmap(ctx, &coreV1.ConfigMap{
+			ObjectMeta: metaV1.ObjectMeta{Name: controllerVersionConfigmap, Namespace: namespace},
+			Data:       map[string]string{controllerVersionKey: d.version},
+		})
+	} else if err == nil {
+		if configmap.Data == nil {
+			configmap.Data = make(map[string]string)
+		}
+		configmap.Data[controllerVersionKey] = d.version
+		_, err = d.k8sUtils.UpdateConfigmap(ctx, configmap)
+	}
+
+	if err != nil {
+		log.AddContext(ctx).Warningf("Publish controller version %s to configmap %s/%s failed, error: %v",
+			d.version, namespace, controllerVersionConfigmap, err)
+		return
+	}
+
+	log.AddContext(ctx).Infof("Controller version %s is published", d.version)
+}
+
+// checkVersionSkew compares the version of this node plugin with the published version of the controller,
+// the incompatible versions are reported as a warning event of the node
+func (d *Driver) checkVersionSkew(ctx context.Context) {
+	controllerVersion, err := d.refreshVersionSkew(ctx)
+	if err != nil {
+		log.AddContext(ctx).Warningf("Check version skew failed, skip version check, error: %v", err)
+		return
+	}
+
+	if !d.isVersionSkewed() {
+		return
+	}
+
+	msg := fmt.Sprintf("The version %s of the node plugin is incompatible with the version %s of the controller "+
+		"plugin, please upgrade them to the same version", d.version, controllerVersion)
+	log.AddContext(ctx).Warningln(msg)
+	err = d.k8sUtils.RecordNodeEvent(ctx, d.nodeName, coreV1.EventTypeWarning, versionSkewReason, msg)
+	if err != nil {
+		log.AddContext(ctx).Warningf("Record version skew of node %s failed, error: %v", d.nodeName, err)
+	}
+}
+
+// checkStageVersion checks the version skew again before the volume is connected, because the controller may be
+// upgraded after NodeGetInfo. The last known result is used if the controller version is unable to be got.
+func (d *Driver) checkStageVersion(ctx context.Context, volumeId string) error {
+	if !app.GetGlobalConfig().StrictVersionCheck {
+		return nil
+	}
+
+	if _, err := d.refreshVersionSkew(ctx); err != nil {
+		log.AddContext(ctx).Warningf("Check version skew before staging volume %s failed, error: %v", volumeId, err)
+	}
+
+	if !d.isVersionSkewed() {
+		return nil
+	}
+
+	msg := fmt.Sprintf("Stage volume %s is rejected, the version %s of the node plugin is incompatible "+
+		"with the controller plugin", volumeId, d.version)
+	log.AddContext(ctx).Errorln(msg)
+	return status.Error(codes.FailedPrecondition, msg)
+}
+
+// refreshVersionSkew gets the published version of the controller and updates whether this node plugin is skewed
+func (d *Driver) refreshVersionSkew(ctx context.Context) (string, error) {
+	namespace := app.GetGlobalConfig().Namespace
+	configmap, err := d.k8sUtils.GetConfigmap(ctx, controllerVersionConfigmap, namespace)
+	if err != nil {
+		return "", fmt.Errorf("get controller version from configmap %s/%s failed, %v", namespace,
+			controllerVersionConfigmap, err)
+	}
+
+	controllerVersion := configmap.Data[controllerVersionKey]
+	compatible, err := isVersionCompatible(d.version, controllerVersion)
+	if err != nil {
+		return "", err
+	}
+
+	d.setVersionSkewed(!compatible)
+	return controllerVersion, nil
+}
+
+func (d *Driver) setVersionSkewed(skewed bool) {
+	var value int32
+	if skewed {
+		value = 1
+	}
+	atomic.StoreInt32(&d.versionSkewed, value)
+}
+
+func (d *Driver) isVersionSkewed() bool {
+	return atomic.LoadInt32(&d.versionSkewed) == 1
+}
@@ -0,0 +1,104 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package driver
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/prashantv/gostub"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	coreV1 "k8s.io/api/core/v1"
+
+	"huawei-csi-driver/csi/app"
+	cfg "huawei-csi-driver/csi/app/config"
+	"huawei-csi-driver/utils/k8sutils"
+)
+
+type fakeVersionK8sUtils struct {
+	k8sutils.Interface
+	controllerVersion string
+	getErr            error
+}
+
+func (k *fakeVersionK8sUtils) GetConfigmap(context.Context, string, string) (*coreV1.ConfigMap, error) {
+	if k.getErr != nil {
+		return nil, k.getErr
+	}
+	return &coreV1.ConfigMap{Data: map[string]string{controllerVersionKey: k.controllerVersion}}, nil
+}
+
+func TestIsVersionCompatible(t *testing.T) {
+	tests := []struct {
+		name              string
+		nodeVersion       string
+		controllerVersion string
+		want              bool
+		wantErr           bool
+	}{
+		{"SameVersion", "4.3.0", "4.3.0", true, false},
+		{"AdjacentMajorVersion", "3.2.0", "4.3.0", true, false},
+		{"NodeTooOld", "2.2.16", "4.3.0", false, false},
+		{"ControllerTooOld", "v4.3.0", "v2.2.16", false, false},
+		{"MissingControllerVersion", "4.3.0", "", false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := isVersionCompatible(tt.nodeVersion, tt.controllerVersion)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("isVersionCompatible() = %v, error = %v, want %v, wantErr %v", got, err, tt.want,
+					tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckStageVersion(t *testing.T) {
+	config := cfg.MockCompletedConfig()
+	config.StrictVersionCheck = true
+	getGlobalConfig := gostub.StubFunc(&app.GetGlobalConfig, config)
+	defer getGlobalConfig.Reset()
+
+	tests := []struct {
+		name              string
+		skewedAtNodeInfo  bool
+		controllerVersion string
+		getErr            error
+		wantCode          codes.Code
+	}{
+		{"Compatible", false, "4.3.0", nil, codes.OK},
+		{"ControllerUpgradedAfterNodeInfo", false, "6.0.0", nil, codes.FailedPrecondition},
+		{"ControllerDowngradedAfterNodeInfo", true, "4.3.0", nil, codes.OK},
+		{"KeepLastResultWhenGetFailed", true, "", errors.New("get configmap failed"), codes.FailedPrecondition},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &Driver{version: "4.3.0",
+				k8sUtils: &fakeVersionK8sUtils{controllerVersion: tt.controllerVersion, getErr: tt.getErr}}
+			d.setVersionSkewed(tt.skewedAtNodeInfo)
+
+			err := d.checkStageVersion(context.Background(), "backend.pvc-1")
+			if status.Code(err) != tt.wantCode {
+				t.Errorf("checkStageVersion() error = %v, want code %v", err, tt.wantCode)
+			}
+		})
+	}
+}
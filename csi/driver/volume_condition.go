@@ -0,0 +1,119 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package driver
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
+
+	"huawei-csi-driver/connector"
+	"huawei-csi-driver/csi/app"
+)
+
+const (
+	// volumeConditionTimeout bounds the condition check, so that the stats collection does not hang
+	// when the storage is down
+	volumeConditionTimeout = 5 * time.Second
+	nfsProbeTimeout        = 3 * time.Second
+)
+
+// runningConditionChecks records the volume paths whose condition checks are still running. The check of a hung
+// mount can't be interrupted, so it is not started again until the running one returns, which leaves at most one
+// blocked goroutine per volume path.
+var runningConditionChecks sync.Map
+
+// getVolumeCondition computes the condition of the published volume within volumeConditionTimeout
+func getVolumeCondition(ctx context.Context, volumePath string) connector.VolumeCondition {
+	if _, running := runningConditionChecks.LoadOrStore(volumePath, struct{}{}); running {
+		return connector.VolumeCondition{Abnormal: true,
+			Message: fmt.Sprintf("the previous condition check of %s is still running", volumePath)}
+	}
+
+	result := make(chan connector.VolumeCondition, 1)
+	go func() {
+		condition := checkVolumeCondition(ctx, volumePath)
+		runningConditionChecks.Delete(volumePath)
+		result <- condition
+	}()
+
+	select {
+	case condition := <-result:
+		return condition
+	case <-time.After(volumeConditionTimeout):
+		return connector.VolumeCondition{Abnormal: true,
+			Message: fmt.Sprintf("check condition of %s timed out after %s", volumePath, volumeConditionTimeout)}
+	}
+}
+
+func checkVolumeCondition(ctx context.Context, volumePath string) connector.VolumeCondition {
+	mountMap, err := connector.ReadMountPoints(ctx)
+	if err == nil && strings.Contains(mountMap[volumePath], ":/") {
+		return connector.GetNFSVolumeCondition(volumePath, nfsProbeTimeout)
+	}
+
+	device, err := getBlockDeviceName(volumePath)
+	if err != nil {
+		return connector.VolumeCondition{Abnormal: true,
+			Message: fmt.Sprintf("get device of %s failed, error: %v", volumePath, err)}
+	}
+
+	config := app.GetGlobalConfig()
+	switch {
+	case strings.HasPrefix(device, "dm-"):
+		return connector.GetDMVolumeCondition(device, config.VolumeConditionMinPaths)
+	case strings.HasPrefix(device, "nvme") && config.NvmeMultiPathType == connector.HWUltraPathNVMe:
+		return connector.GetUltraPathVolumeCondition(ctx, connector.UltraPathNVMeCommand, device)
+	case strings.HasPrefix(device, "sd") && config.ScsiMultiPathType == connector.HWUltraPath:
+		return connector.GetUltraPathVolumeCondition(ctx, connector.UltraPathCommand, device)
+	default:
+		return connector.VolumeCondition{Message: fmt.Sprintf("device %s is attached", device)}
+	}
+}
+
+// getBlockDeviceName returns the kernel name of the block device backing the volume path, the device itself
+// for a block volume and the device of the filesystem for a filesystem volume
+func getBlockDeviceName(volumePath string) (string, error) {
+	info, err := os.Stat(volumePath)
+	if err != nil {
+		return "", err
+	}
+
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return "", fmt.Errorf("convert stat of %s failed", volumePath)
+	}
+
+	dev := uint64(stat.Dev)
+	if info.Mode()&os.ModeDevice != 0 {
+		dev = uint64(stat.Rdev)
+	}
+
+	link, err := os.Readlink(fmt.Sprintf("/sys/dev/block/%d:%d", unix.Major(dev), unix.Minor(dev)))
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Base(link), nil
+}
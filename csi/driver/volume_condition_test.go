@@ -0,0 +1,64 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package driver
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"github.com/agiledragon/gomonkey/v2"
+
+	"huawei-csi-driver/connector"
+)
+
+func TestGetVolumeConditionOfRunningCheck(t *testing.T) {
+	var checks int32
+	started, release := make(chan struct{}), make(chan struct{})
+	patches := gomonkey.ApplyFunc(checkVolumeCondition,
+		func(context.Context, string) connector.VolumeCondition {
+			if atomic.AddInt32(&checks, 1) == 1 {
+				close(started)
+			}
+			<-release
+			return connector.VolumeCondition{Message: "device dm-0 is attached"}
+		})
+	defer patches.Reset()
+
+	volumePath := "/var/lib/kubelet/pods/pod-1/volumes/kubernetes.io~csi/pvc-1/mount"
+	done := make(chan connector.VolumeCondition)
+	go func() {
+		done <- getVolumeCondition(context.Background(), volumePath)
+	}()
+	<-started
+
+	// the check of the hung mount is still running, so no other check is started
+	if condition := getVolumeCondition(context.Background(), volumePath); !condition.Abnormal {
+		t.Errorf("getVolumeCondition() = %v, want abnormal while the previous check is running", condition)
+	}
+	if got := atomic.LoadInt32(&checks); got != 1 {
+		t.Errorf("getVolumeCondition() started %d checks, want 1", got)
+	}
+
+	close(release)
+	if condition := <-done; condition.Abnormal {
+		t.Errorf("getVolumeCondition() = %v, want normal", condition)
+	}
+	if condition := getVolumeCondition(context.Background(), volumePath); condition.Abnormal {
+		t.Errorf("getVolumeCondition() = %v, want normal after the previous check returned", condition)
+	}
+}
@@ -0,0 +1,644 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package driver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	coreV1 "k8s.io/api/core/v1"
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+
+	xuanwuV1 "huawei-csi-driver/client/apis/xuanwu/v1"
+	"huawei-csi-driver/csi/app"
+	"huawei-csi-driver/csi/backend/model"
+	"huawei-csi-driver/csi/backend/plugin"
+	"huawei-csi-driver/pkg/constants"
+	"huawei-csi-driver/utils"
+	"huawei-csi-driver/utils/log"
+)
+
+// volumeMigrationResyncPeriod is the interval of polling the progress of the running migrations
+const volumeMigrationResyncPeriod = 30 * time.Second
+
+// migrationStrippedParameters are the StorageClass parameters which do not apply to the target volume
+var migrationStrippedParameters = []string{
+	"hyperMetro", "replication", "cloneFrom", "sourceSnapshotName", "sourceVolumeName", "localOwner", "remoteOwner",
+	fallbackBackendKey, fallbackStoragePoolKey,
+}
+
+// WatchVolumeMigrations runs the VolumeMigrations step by step, each event or resync of a VolumeMigration
+// moves it forward by one idempotent step until it is completed
+func (d *Driver) WatchVolumeMigrations(ctx context.Context, stopCh <-chan struct{}) {
+	log.AddContext(ctx).Infoln("Start to watch volume migrations")
+	migrations := app.GetGlobalConfig().BackendUtils.XuanwuV1().VolumeMigrations()
+	source := &cache.ListWatch{
+		ListFunc: func(options metaV1.ListOptions) (runtime.Object, error) {
+			return migrations.List(ctx, options)
+		},
+		WatchFunc: func(options metaV1.ListOptions) (watch.Interface, error) {
+			return migrations.Watch(ctx, options)
+		},
+	}
+
+	handle := func(obj interface{}) {
+		migration, ok := obj.(*xuanwuV1.VolumeMigration)
+		if !ok {
+			log.AddContext(ctx).Errorf("Volume migration watcher expected VolumeMigration; got %v", obj)
+			return
+		}
+		d.reconcileVolumeMigration(ctx, migration.DeepCopy())
+	}
+
+	informer := cache.NewSharedIndexInformer(source, &xuanwuV1.VolumeMigration{}, volumeMigrationResyncPeriod,
+		cache.Indexers{})
+	_, err := informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: handle,
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			handle(newObj)
+		},
+	})
+	if err != nil {
+		log.AddContext(ctx).Errorf("Add volume migration event handler failed, error %v", err)
+		return
+	}
+
+	informer.Run(stopCh)
+}
+
+func (d *Driver) reconcileVolumeMigration(ctx context.Context, migration *xuanwuV1.VolumeMigration) {
+	var err error
+	if migration.DeletionTimestamp != nil {
+		err = d.rollbackVolumeMigration(ctx, migration)
+	} else {
+		switch migration.Status.Phase {
+		case "", xuanwuV1.VolumeMigrationPending:
+			err = d.startVolumeMigration(ctx, migration)
+		case xuanwuV1.VolumeMigrationCreating:
+			err = d.createMigrationTarget(ctx, migration)
+		case xuanwuV1.VolumeMigrationReplicating:
+			err = d.replicateMigrationTarget(ctx, migration)
+		case xuanwuV1.VolumeMigrationSynced:
+			if migration.Spec.Cutover {
+				migration.Status.Phase = xuanwuV1.VolumeMigrationCuttingOver
+				migration.Status.Message = ""
+				_, err = updateVolumeMigrationStatus(ctx, migration)
+			}
+		case xuanwuV1.VolumeMigrationCuttingOver:
+			err = d.cutoverVolumeMigration(ctx, migration)
+		case xuanwuV1.VolumeMigrationCompleted:
+			err = removeVolumeMigrationFinalizer(ctx, migration)
+		}
+	}
+
+	if err != nil {
+		log.AddContext(ctx).Errorf("Volume migration %s in phase %s error: %v",
+			migration.Name, migration.Status.Phase, err)
+		setVolumeMigrationMessage(ctx, migration, err.Error())
+	}
+}
+
+// startVolumeMigration validates the migration, the invalid migration is failed without any change
+func (d *Driver) startVolumeMigration(ctx context.Context, migration *xuanwuV1.VolumeMigration) error {
+	sourceHandle, err := d.validateVolumeMigration(ctx, migration)
+	if err != nil {
+		log.AddContext(ctx).Errorf("Volume migration %s is invalid: %v", migration.Name, err)
+		migration.Status.Phase = xuanwuV1.VolumeMigrationFailed
+		migration.Status.Message = err.Error()
+		_, err = updateVolumeMigrationStatus(ctx, migration)
+		return err
+	}
+
+	if !utils.IsContain(constants.VolumeMigrationFinalizer, migration.Finalizers) {
+		migration.Finalizers = append(migration.Finalizers, constants.VolumeMigrationFinalizer)
+		migration, err = app.GetGlobalConfig().BackendUtils.XuanwuV1().VolumeMigrations().Update(ctx,
+			migration, metaV1.UpdateOptions{})
+		if err != nil {
+			return err
+		}
+	}
+
+	log.AddContext(ctx).Infof("Start to migrate volume %s to backend %s",
+		sourceHandle, migration.Spec.TargetBackend)
+	migration.Status.Phase = xuanwuV1.VolumeMigrationCreating
+	migration.Status.Message = ""
+	migration.Status.SourceVolumeHandle = sourceHandle
+	_, err = updateVolumeMigrationStatus(ctx, migration)
+	return err
+}
+
+func (d *Driver) validateVolumeMigration(ctx context.Context, migration *xuanwuV1.VolumeMigration) (string, error) {
+	pv, err := d.k8sUtils.GetPVByName(ctx, migration.Spec.PersistentVolumeName)
+	if err != nil {
+		return "", fmt.Errorf("get persistent volume %s error: %v", migration.Spec.PersistentVolumeName, err)
+	}
+	if pv.Spec.CSI == nil || pv.Spec.CSI.Driver != d.name {
+		return "", fmt.Errorf("persistent volume %s is not provisioned by %s", pv.Name, d.name)
+	}
+
+	sourceHandle := pv.Spec.CSI.VolumeHandle
+	sourceBackend, _ := utils.SplitVolumeId(sourceHandle)
+	if sourceBackend == migration.Spec.TargetBackend {
+		return "", fmt.Errorf("volume %s is already on backend %s", sourceHandle, sourceBackend)
+	}
+
+	source, _, err := d.getReplicaCopier(ctx, sourceBackend)
+	if err != nil {
+		return "", err
+	}
+	target, _, err := d.getReplicaCopier(ctx, migration.Spec.TargetBackend)
+	if err != nil {
+		return "", err
+	}
+
+	sourceSN, err := source.GetStorageSN(ctx)
+	if err != nil {
+		return "", err
+	}
+	targetSN, err := target.GetStorageSN(ctx)
+	if err != nil {
+		return "", err
+	}
+	if sourceSN == targetSN {
+		return "", fmt.Errorf("backend %s and %s are on the same storage %s", sourceBackend,
+			migration.Spec.TargetBackend, sourceSN)
+	}
+
+	return sourceHandle, nil
+}
+
+// createMigrationTarget creates the target volume as the source volume is provisioned by its StorageClass,
+// except that the backend and the pool are the target ones
+func (d *Driver) createMigrationTarget(ctx context.Context, migration *xuanwuV1.VolumeMigration) error {
+	pv, err := d.k8sUtils.GetPVByName(ctx, migration.Spec.PersistentVolumeName)
+	if err != nil {
+		return err
+	}
+
+	scParameters, err := d.k8sUtils.GetVolumeStorageClassParameters(ctx, d.name,
+		migration.Status.SourceVolumeHandle)
+	if err != nil {
+		return err
+	}
+
+	req := buildMigrationTargetRequest(pv, scParameters, migration.Spec)
+	if err = checkCreateVolumeRequest(ctx, req); err != nil {
+		return err
+	}
+
+	res, err := d.createVolume(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	log.AddContext(ctx).Infof("Target volume %s of volume migration %s is created",
+		res.GetVolume().GetVolumeId(), migration.Name)
+	migration.Status.Phase = xuanwuV1.VolumeMigrationReplicating
+	migration.Status.Message = ""
+	migration.Status.TargetVolumeHandle = res.GetVolume().GetVolumeId()
+	migration.Status.TargetVolumeAttributes = res.GetVolume().GetVolumeContext()
+	_, err = updateVolumeMigrationStatus(ctx, migration)
+	return err
+}
+
+// replicateMigrationTarget starts copying the source volume to the target volume and waits for the first copy
+func (d *Driver) replicateMigrationTarget(ctx context.Context, migration *xuanwuV1.VolumeMigration) error {
+	sourceBackend, sourceName := utils.SplitVolumeId(migration.Status.SourceVolumeHandle)
+	source, _, err := d.getReplicaCopier(ctx, sourceBackend)
+	if err != nil {
+		return err
+	}
+
+	if migration.Status.ReplicationPairID == "" {
+		targetBackend, targetName := utils.SplitVolumeId(migration.Status.TargetVolumeHandle)
+		target, _, err := d.getReplicaCopier(ctx, targetBackend)
+		if err != nil {
+			return err
+		}
+
+		targetSN, err := target.GetStorageSN(ctx)
+		if err != nil {
+			return err
+		}
+		targetID, err := target.GetVolumeID(ctx, targetName)
+		if err != nil {
+			return err
+		}
+
+		pairID, err := source.CreateReplicaCopy(ctx, sourceName, targetSN, targetID)
+		if err != nil {
+			return err
+		}
+
+		migration.Status.ReplicationPairID = pairID
+		migration.Status.Message = ""
+		_, err = updateVolumeMigrationStatus(ctx, migration)
+		return err
+	}
+
+	synced, progress, err := source.GetReplicaCopyStatus(ctx, migration.Status.ReplicationPairID)
+	if err != nil {
+		return err
+	}
+
+	if synced {
+		log.AddContext(ctx).Infof("Volume %s of volume migration %s is synced, waiting for cutover",
+			migration.Status.SourceVolumeHandle, migration.Name)
+		migration.Status.Phase = xuanwuV1.VolumeMigrationSynced
+		migration.Status.Message = "Set spec.cutover to true to switch the persistent volume to the target volume"
+	} else {
+		if migration.Status.Progress == progress && migration.Status.Message == "" {
+			return nil
+		}
+		migration.Status.Message = ""
+	}
+
+	migration.Status.Progress = progress
+	_, err = updateVolumeMigrationStatus(ctx, migration)
+	return err
+}
+
+// cutoverVolumeMigration fences the source volume from being published and copies the changes since the first
+// copy after the workload is scaled down, then deletes the copy and replaces the persistent volume with the one
+// of the target volume
+func (d *Driver) cutoverVolumeMigration(ctx context.Context, migration *xuanwuV1.VolumeMigration) error {
+	if migration.Status.TargetPersistentVolume == "" {
+		if err := d.fenceMigrationSource(ctx, migration); err != nil {
+			return err
+		}
+
+		done, err := d.finalSyncVolumeMigration(ctx, migration)
+		if err != nil || !done {
+			return err
+		}
+	}
+
+	if migration.Status.ReplicationPairID != "" {
+		sourceBackend, _ := utils.SplitVolumeId(migration.Status.SourceVolumeHandle)
+		source, _, err := d.getReplicaCopier(ctx, sourceBackend)
+		if err != nil {
+			return err
+		}
+		if err = source.DeleteReplicaCopy(ctx, migration.Status.ReplicationPairID); err != nil {
+			return err
+		}
+
+		migration.Status.ReplicationPairID = ""
+		migration, err = updateVolumeMigrationStatus(ctx, migration)
+		if err != nil {
+			return err
+		}
+	}
+
+	var pv coreV1.PersistentVolume
+	if err := json.Unmarshal([]byte(migration.Status.TargetPersistentVolume), &pv); err != nil {
+		return err
+	}
+
+	replaced, err := d.k8sUtils.ReplacePersistentVolume(ctx, &pv)
+	if err != nil || !replaced {
+		return err
+	}
+
+	log.AddContext(ctx).Infof("Persistent volume %s is migrated to volume %s", pv.Name,
+		migration.Status.TargetVolumeHandle)
+	migration.Status.Phase = xuanwuV1.VolumeMigrationCompleted
+	migration.Status.Message = fmt.Sprintf("The source volume %s is retained on the storage, delete it "+
+		"manually once the migrated data is verified", migration.Status.SourceVolumeHandle)
+	migration.Status.TargetPersistentVolume = ""
+	migration, err = updateVolumeMigrationStatus(ctx, migration)
+	if err != nil {
+		return err
+	}
+
+	return removeVolumeMigrationFinalizer(ctx, migration)
+}
+
+// fenceMigrationSource annotates the source persistent volume so that ControllerPublishVolume refuses the source
+// volume, the attachments are checked after the fence so that no publish is missed
+func (d *Driver) fenceMigrationSource(ctx context.Context, migration *xuanwuV1.VolumeMigration) error {
+	pv, err := d.k8sUtils.GetPVByName(ctx, migration.Spec.PersistentVolumeName)
+	if err != nil {
+		return err
+	}
+	if pv.Annotations[constants.VolumeMigrationFenceAnnotation] == migration.Name {
+		return nil
+	}
+
+	log.AddContext(ctx).Infof("Fence volume %s of volume migration %s from being published",
+		migration.Status.SourceVolumeHandle, migration.Name)
+	return d.k8sUtils.UpdatePVAnnotations(ctx, pv.Name,
+		map[string]string{constants.VolumeMigrationFenceAnnotation: migration.Name})
+}
+
+// unfenceMigrationSource allows the source volume to be published again after the migration is rolled back
+func (d *Driver) unfenceMigrationSource(ctx context.Context, migration *xuanwuV1.VolumeMigration) error {
+	pv, err := d.k8sUtils.GetPVByName(ctx, migration.Spec.PersistentVolumeName)
+	if apiErrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if pv.Annotations[constants.VolumeMigrationFenceAnnotation] != migration.Name {
+		return nil
+	}
+
+	return d.k8sUtils.RemovePVAnnotations(ctx, pv.Name, []string{constants.VolumeMigrationFenceAnnotation})
+}
+
+// checkVolumeMigrationFence refuses publishing the source volume of the migration which is cutting over,
+// otherwise the writes after the final copy are lost
+func (d *Driver) checkVolumeMigrationFence(ctx context.Context, volumeId string) error {
+	if d.k8sUtils == nil {
+		return nil
+	}
+
+	pv, err := d.k8sUtils.GetPVByVolumeHandle(ctx, d.name, volumeId)
+	if err != nil {
+		return fmt.Errorf("get persistent volume of volume %s error: %v", volumeId, err)
+	}
+	if pv == nil {
+		return nil
+	}
+
+	if migration, fenced := pv.Annotations[constants.VolumeMigrationFenceAnnotation]; fenced {
+		return fmt.Errorf("volume %s is being cut over by volume migration %s", volumeId, migration)
+	}
+	return nil
+}
+
+func (d *Driver) finalSyncVolumeMigration(ctx context.Context, migration *xuanwuV1.VolumeMigration) (bool, error) {
+	nodes, err := d.k8sUtils.GetVolumeAttachingNodes(ctx, d.name, migration.Status.SourceVolumeHandle)
+	if err != nil {
+		return false, err
+	}
+	if len(nodes) != 0 {
+		setVolumeMigrationMessage(ctx, migration, fmt.Sprintf("Volume is attached to nodes %v, "+
+			"scale down the workload using the persistent volume to cut over", nodes))
+		return false, nil
+	}
+
+	sourceBackend, _ := utils.SplitVolumeId(migration.Status.SourceVolumeHandle)
+	source, _, err := d.getReplicaCopier(ctx, sourceBackend)
+	if err != nil {
+		return false, err
+	}
+
+	pairID := migration.Status.ReplicationPairID
+	if !migration.Status.FinalSyncStarted {
+		if err = source.SyncReplicaCopy(ctx, pairID); err != nil {
+			return false, err
+		}
+
+		migration.Status.FinalSyncStarted = true
+		migration.Status.Message = ""
+		_, err = updateVolumeMigrationStatus(ctx, migration)
+		return false, err
+	}
+
+	synced, progress, err := source.GetReplicaCopyStatus(ctx, pairID)
+	if err != nil {
+		return false, err
+	}
+	if !synced {
+		if migration.Status.Progress != progress {
+			migration.Status.Progress = progress
+			_, err = updateVolumeMigrationStatus(ctx, migration)
+		}
+		return false, err
+	}
+
+	pv, err := d.k8sUtils.GetPVByName(ctx, migration.Spec.PersistentVolumeName)
+	if err != nil {
+		return false, err
+	}
+	manifest, err := json.Marshal(buildMigrationTargetPV(pv, migration.Status))
+	if err != nil {
+		return false, err
+	}
+
+	migration.Status.Progress = progress
+	migration.Status.Message = ""
+	migration.Status.TargetPersistentVolume = string(manifest)
+	updated, err := updateVolumeMigrationStatus(ctx, migration)
+	if err != nil {
+		return false, err
+	}
+
+	*migration = *updated
+	return true, nil
+}
+
+// rollbackVolumeMigration removes the copy and the target volume, the source volume is never changed.
+// The migration which has started to replace the persistent volume can not be rolled back, it is completed first.
+func (d *Driver) rollbackVolumeMigration(ctx context.Context, migration *xuanwuV1.VolumeMigration) error {
+	if !utils.IsContain(constants.VolumeMigrationFinalizer, migration.Finalizers) {
+		return nil
+	}
+
+	switch {
+	case migration.Status.Phase == xuanwuV1.VolumeMigrationCompleted:
+		return removeVolumeMigrationFinalizer(ctx, migration)
+	case migration.Status.TargetPersistentVolume != "":
+		return d.cutoverVolumeMigration(ctx, migration)
+	}
+
+	if migration.Status.Phase != xuanwuV1.VolumeMigrationRollingBack {
+		log.AddContext(ctx).Infof("Start to roll back volume migration %s", migration.Name)
+		migration.Status.Phase = xuanwuV1.VolumeMigrationRollingBack
+		migration.Status.Message = ""
+		updated, err := updateVolumeMigrationStatus(ctx, migration)
+		if err != nil {
+			return err
+		}
+		migration = updated
+	}
+
+	if err := d.unfenceMigrationSource(ctx, migration); err != nil {
+		return err
+	}
+
+	if migration.Status.ReplicationPairID != "" {
+		sourceBackend, _ := utils.SplitVolumeId(migration.Status.SourceVolumeHandle)
+		source, _, err := d.getReplicaCopier(ctx, sourceBackend)
+		if err != nil {
+			return err
+		}
+		if err = source.DeleteReplicaCopy(ctx, migration.Status.ReplicationPairID); err != nil {
+			return err
+		}
+	}
+
+	if migration.Status.TargetVolumeHandle != "" {
+		targetBackend, targetName := utils.SplitVolumeId(migration.Status.TargetVolumeHandle)
+		_, bk, err := d.getReplicaCopier(ctx, targetBackend)
+		if err != nil {
+			return err
+		}
+		if err = bk.Plugin.DeleteVolume(ctx, targetName); err != nil {
+			return err
+		}
+	}
+
+	log.AddContext(ctx).Infof("Volume migration %s is rolled back", migration.Name)
+	return removeVolumeMigrationFinalizer(ctx, migration)
+}
+
+func (d *Driver) getReplicaCopier(ctx context.Context, backendName string) (plugin.ReplicaCopier,
+	*model.Backend, error) {
+	bk, err := d.backendSelector.SelectBackend(ctx, backendName)
+	if err != nil {
+		return nil, nil, err
+	}
+	if bk == nil {
+		return nil, nil, fmt.Errorf("backend %s does not exist", backendName)
+	}
+
+	copier, ok := bk.Plugin.(plugin.ReplicaCopier)
+	if !ok {
+		return nil, nil, fmt.Errorf("backend %s of storage %s does not support volume migration",
+			backendName, bk.Storage)
+	}
+	return copier, bk, nil
+}
+
+// buildMigrationTargetRequest builds the request to create the target volume of the persistent volume
+func buildMigrationTargetRequest(pv *coreV1.PersistentVolume, scParameters map[string]string,
+	spec xuanwuV1.VolumeMigrationSpec) *csi.CreateVolumeRequest {
+	parameters := make(map[string]string, len(scParameters)+2)
+	for key, value := range scParameters {
+		parameters[key] = value
+	}
+	for _, key := range migrationStrippedParameters {
+		delete(parameters, key)
+	}
+
+	parameters["backend"] = spec.TargetBackend
+	delete(parameters, "pool")
+	if spec.TargetPool != "" {
+		parameters["pool"] = spec.TargetPool
+	}
+
+	capability := &csi.VolumeCapability{
+		AccessType: &csi.VolumeCapability_Mount{
+			Mount: &csi.VolumeCapability_MountVolume{FsType: pv.Spec.CSI.FSType},
+		},
+	}
+	if pv.Spec.VolumeMode != nil && *pv.Spec.VolumeMode == coreV1.PersistentVolumeBlock {
+		capability.AccessType = &csi.VolumeCapability_Block{Block: &csi.VolumeCapability_BlockVolume{}}
+	}
+
+	capabilities := make([]*csi.VolumeCapability, 0, len(pv.Spec.AccessModes))
+	for _, accessMode := range pv.Spec.AccessModes {
+		mode := csi.VolumeCapability_AccessMode_SINGLE_NODE_WRITER
+		switch accessMode {
+		case coreV1.ReadOnlyMany:
+			mode = csi.VolumeCapability_AccessMode_MULTI_NODE_READER_ONLY
+		case coreV1.ReadWriteMany:
+			mode = csi.VolumeCapability_AccessMode_MULTI_NODE_MULTI_WRITER
+		}
+
+		capabilities = append(capabilities, &csi.VolumeCapability{
+			AccessType: capability.AccessType,
+			AccessMode: &csi.VolumeCapability_AccessMode{Mode: mode},
+		})
+	}
+
+	storage := pv.Spec.Capacity[coreV1.ResourceStorage]
+	return &csi.CreateVolumeRequest{
+		Name:               pv.Name,
+		CapacityRange:      &csi.CapacityRange{RequiredBytes: storage.Value()},
+		VolumeCapabilities: capabilities,
+		Parameters:         parameters,
+	}
+}
+
+// buildMigrationTargetPV builds the persistent volume which replaces the source one, the volume attributes
+// of the target volume override the source ones. The finalizers are kept, while the fence is not.
+func buildMigrationTargetPV(pv *coreV1.PersistentVolume,
+	status xuanwuV1.VolumeMigrationStatus) *coreV1.PersistentVolume {
+	target := &coreV1.PersistentVolume{
+		TypeMeta: pv.TypeMeta,
+		ObjectMeta: metaV1.ObjectMeta{
+			Name:        pv.Name,
+			Labels:      pv.Labels,
+			Annotations: make(map[string]string, len(pv.Annotations)),
+			Finalizers:  append([]string(nil), pv.Finalizers...),
+		},
+		Spec: *pv.Spec.DeepCopy(),
+	}
+	for key, value := range pv.Annotations {
+		if key != constants.VolumeMigrationFenceAnnotation {
+			target.Annotations[key] = value
+		}
+	}
+
+	attributes := make(map[string]string, len(pv.Spec.CSI.VolumeAttributes)+len(status.TargetVolumeAttributes))
+	for key, value := range pv.Spec.CSI.VolumeAttributes {
+		attributes[key] = value
+	}
+	for key, value := range status.TargetVolumeAttributes {
+		attributes[key] = value
+	}
+
+	target.Spec.CSI.VolumeHandle = status.TargetVolumeHandle
+	target.Spec.CSI.VolumeAttributes = attributes
+	return target
+}
+
+func updateVolumeMigrationStatus(ctx context.Context, migration *xuanwuV1.VolumeMigration) (
+	*xuanwuV1.VolumeMigration, error) {
+	return app.GetGlobalConfig().BackendUtils.XuanwuV1().VolumeMigrations().UpdateStatus(ctx, migration,
+		metaV1.UpdateOptions{})
+}
+
+// setVolumeMigrationMessage shows why the migration does not move forward, the failure of updating is only logged
+func setVolumeMigrationMessage(ctx context.Context, migration *xuanwuV1.VolumeMigration, message string) {
+	if migration.Status.Message == message {
+		return
+	}
+
+	migration.Status.Message = message
+	if _, err := updateVolumeMigrationStatus(ctx, migration); err != nil {
+		log.AddContext(ctx).Warningf("Update message of volume migration %s error: %v", migration.Name, err)
+	}
+}
+
+func removeVolumeMigrationFinalizer(ctx context.Context, migration *xuanwuV1.VolumeMigration) error {
+	finalizers := make([]string, 0, len(migration.Finalizers))
+	for _, finalizer := range migration.Finalizers {
+		if finalizer != constants.VolumeMigrationFinalizer {
+			finalizers = append(finalizers, finalizer)
+		}
+	}
+	if len(finalizers) == len(migration.Finalizers) {
+		return nil
+	}
+
+	migration.Finalizers = finalizers
+	_, err := app.GetGlobalConfig().BackendUtils.XuanwuV1().VolumeMigrations().Update(ctx, migration,
+		metaV1.UpdateOptions{})
+	return err
+}
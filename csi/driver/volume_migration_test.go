@@ -0,0 +1,482 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package driver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	"github.com/prashantv/gostub"
+	coreV1 "k8s.io/api/core/v1"
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stesting "k8s.io/client-go/testing"
+
+	xuanwuV1 "huawei-csi-driver/client/apis/xuanwu/v1"
+	"huawei-csi-driver/csi/app"
+	cfg "huawei-csi-driver/csi/app/config"
+	"huawei-csi-driver/csi/backend/handler"
+	"huawei-csi-driver/csi/backend/model"
+	"huawei-csi-driver/csi/backend/plugin"
+	"huawei-csi-driver/pkg/client/clientset/versioned/fake"
+	"huawei-csi-driver/pkg/constants"
+	pkgUtils "huawei-csi-driver/pkg/utils"
+	"huawei-csi-driver/utils"
+	"huawei-csi-driver/utils/k8sutils"
+)
+
+func newMigrationSourcePV(volumeMode coreV1.PersistentVolumeMode,
+	accessModes ...coreV1.PersistentVolumeAccessMode) *coreV1.PersistentVolume {
+	return &coreV1.PersistentVolume{
+		ObjectMeta: metaV1.ObjectMeta{Name: "pvc-1", ResourceVersion: "10", UID: "uid-1",
+			Finalizers: []string{"kubernetes.io/pv-protection"}},
+		Spec: coreV1.PersistentVolumeSpec{
+			Capacity:    coreV1.ResourceList{coreV1.ResourceStorage: resource.MustParse("10Gi")},
+			AccessModes: accessModes,
+			VolumeMode:  &volumeMode,
+			ClaimRef:    &coreV1.ObjectReference{Namespace: "default", Name: "mypvc"},
+			PersistentVolumeSource: coreV1.PersistentVolumeSource{
+				CSI: &coreV1.CSIPersistentVolumeSource{
+					Driver:       "csi.huawei.com",
+					VolumeHandle: "old-backend.pvc-1",
+					FSType:       "ext4",
+					VolumeAttributes: map[string]string{
+						"backend": "old-backend", "lunWWN": "old-wwn", "fsPermission": "755"},
+				},
+			},
+		},
+	}
+}
+
+func TestBuildMigrationTargetRequest(t *testing.T) {
+	scParameters := map[string]string{
+		"backend": "old-backend", "pool": "old-pool", "volumeType": "lun",
+		"hyperMetro": "true", fallbackBackendKey: "backup-backend",
+	}
+
+	tests := []struct {
+		name           string
+		pv             *coreV1.PersistentVolume
+		spec           xuanwuV1.VolumeMigrationSpec
+		wantParameters map[string]string
+		wantBlock      bool
+		wantModes      []csi.VolumeCapability_AccessMode_Mode
+	}{
+		{"FilesystemToPool", newMigrationSourcePV(coreV1.PersistentVolumeFilesystem, coreV1.ReadWriteOnce),
+			xuanwuV1.VolumeMigrationSpec{TargetBackend: "new-backend", TargetPool: "new-pool"},
+			map[string]string{"backend": "new-backend", "pool": "new-pool", "volumeType": "lun"},
+			false, []csi.VolumeCapability_AccessMode_Mode{csi.VolumeCapability_AccessMode_SINGLE_NODE_WRITER}},
+		{"BlockToAnyPool", newMigrationSourcePV(coreV1.PersistentVolumeBlock,
+			coreV1.ReadWriteMany, coreV1.ReadOnlyMany),
+			xuanwuV1.VolumeMigrationSpec{TargetBackend: "new-backend"},
+			map[string]string{"backend": "new-backend", "volumeType": "lun"},
+			true, []csi.VolumeCapability_AccessMode_Mode{csi.VolumeCapability_AccessMode_MULTI_NODE_MULTI_WRITER,
+				csi.VolumeCapability_AccessMode_MULTI_NODE_READER_ONLY}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := buildMigrationTargetRequest(tt.pv, scParameters, tt.spec)
+			if req.GetName() != "pvc-1" || req.GetCapacityRange().GetRequiredBytes() != 10*1024*1024*1024 {
+				t.Errorf("buildMigrationTargetRequest() name = %s, capacity = %d", req.GetName(),
+					req.GetCapacityRange().GetRequiredBytes())
+			}
+			if !reflect.DeepEqual(req.GetParameters(), tt.wantParameters) {
+				t.Errorf("buildMigrationTargetRequest() parameters = %v, want %v",
+					req.GetParameters(), tt.wantParameters)
+			}
+
+			var modes []csi.VolumeCapability_AccessMode_Mode
+			for _, capability := range req.GetVolumeCapabilities() {
+				if (capability.GetBlock() != nil) != tt.wantBlock {
+					t.Errorf("buildMigrationTargetRequest() capability = %v, want block %t", capability, tt.wantBlock)
+				}
+				modes = append(modes, capability.GetAccessMode().GetMode())
+			}
+			if !reflect.DeepEqual(modes, tt.wantModes) {
+				t.Errorf("buildMigrationTargetRequest() access modes = %v, want %v", modes, tt.wantModes)
+			}
+		})
+	}
+
+	if scParameters["hyperMetro"] != "true" {
+		t.Errorf("buildMigrationTargetRequest() changed the StorageClass parameters %v", scParameters)
+	}
+}
+
+func TestBuildMigrationTargetPV(t *testing.T) {
+	pv := newMigrationSourcePV(coreV1.PersistentVolumeFilesystem, coreV1.ReadWriteOnce)
+	target := buildMigrationTargetPV(pv, xuanwuV1.VolumeMigrationStatus{
+		TargetVolumeHandle:     "new-backend.pvc-1",
+		TargetVolumeAttributes: map[string]string{"backend": "new-backend", "lunWWN": "new-wwn"},
+	})
+
+	wantAttributes := map[string]string{"backend": "new-backend", "lunWWN": "new-wwn", "fsPermission": "755"}
+	if target.Spec.CSI.VolumeHandle != "new-backend.pvc-1" ||
+		!reflect.DeepEqual(target.Spec.CSI.VolumeAttributes, wantAttributes) {
+		t.Errorf("buildMigrationTargetPV() csi = %v, want handle new-backend.pvc-1 and attributes %v",
+			target.Spec.CSI, wantAttributes)
+	}
+	if target.Name != pv.Name || target.ResourceVersion != "" || target.UID != "" {
+		t.Errorf("buildMigrationTargetPV() metadata = %v, want only the name of %s", target.ObjectMeta, pv.Name)
+	}
+	if !reflect.DeepEqual(target.Finalizers, pv.Finalizers) {
+		t.Errorf("buildMigrationTargetPV() finalizers = %v, want %v", target.Finalizers, pv.Finalizers)
+	}
+	if target.Spec.ClaimRef == nil || target.Spec.ClaimRef.Name != "mypvc" {
+		t.Errorf("buildMigrationTargetPV() claimRef = %v, want mypvc", target.Spec.ClaimRef)
+	}
+	if pv.Spec.CSI.VolumeHandle != "old-backend.pvc-1" || pv.Spec.CSI.VolumeAttributes["backend"] != "old-backend" {
+		t.Errorf("buildMigrationTargetPV() changed the source persistent volume %v", pv.Spec.CSI)
+	}
+}
+
+// errInjectedCrash is returned by the step which the migration crashes at
+var errInjectedCrash = errors.New("injected crash")
+
+// crashInjector fails the mutating step of the given sequence number once, as if the controller crashed there
+type crashInjector struct {
+	steps   int
+	crashAt int
+}
+
+func (c *crashInjector) step() error {
+	c.steps++
+	if c.steps == c.crashAt {
+		return errInjectedCrash
+	}
+	return nil
+}
+
+type fakeMigrationK8sUtils struct {
+	k8sutils.Interface
+	t        *testing.T
+	crash    *crashInjector
+	pv       *coreV1.PersistentVolume
+	deleting bool
+}
+
+func (f *fakeMigrationK8sUtils) GetPVByName(_ context.Context, name string) (*coreV1.PersistentVolume, error) {
+	if f.pv == nil || f.deleting {
+		return nil, apiErrors.NewNotFound(coreV1.Resource("persistentvolumes"), name)
+	}
+	return f.pv.DeepCopy(), nil
+}
+
+func (f *fakeMigrationK8sUtils) GetPVByVolumeHandle(_ context.Context, _, volumeHandle string) (
+	*coreV1.PersistentVolume, error) {
+	if f.pv == nil || f.deleting || f.pv.Spec.CSI.VolumeHandle != volumeHandle {
+		return nil, nil
+	}
+	return f.pv.DeepCopy(), nil
+}
+
+func (f *fakeMigrationK8sUtils) GetVolumeStorageClassParameters(context.Context, string, string) (
+	map[string]string, error) {
+	return map[string]string{"volumeType": "lun"}, nil
+}
+
+func (f *fakeMigrationK8sUtils) GetVolumeAttachingNodes(context.Context, string, string) ([]string, error) {
+	return nil, nil
+}
+
+func (f *fakeMigrationK8sUtils) UpdatePVAnnotations(_ context.Context, _ string, annotations map[string]string) error {
+	if err := f.crash.step(); err != nil {
+		return err
+	}
+	if f.pv.Annotations == nil {
+		f.pv.Annotations = map[string]string{}
+	}
+	for key, value := range annotations {
+		f.pv.Annotations[key] = value
+	}
+	return nil
+}
+
+func (f *fakeMigrationK8sUtils) RemovePVAnnotations(_ context.Context, _ string, keys []string) error {
+	if err := f.crash.step(); err != nil {
+		return err
+	}
+	for _, key := range keys {
+		delete(f.pv.Annotations, key)
+	}
+	return nil
+}
+
+// ReplacePersistentVolume deletes the old persistent volume by the first call and creates the new one by the next
+func (f *fakeMigrationK8sUtils) ReplacePersistentVolume(_ context.Context, pv *coreV1.PersistentVolume) (bool, error) {
+	if f.pv.Spec.CSI.VolumeHandle == pv.Spec.CSI.VolumeHandle && !f.deleting {
+		return true, nil
+	}
+	if err := f.crash.step(); err != nil {
+		return false, err
+	}
+	if !f.deleting {
+		f.deleting = true
+		return false, nil
+	}
+
+	f.pv, f.deleting = pv.DeepCopy(), false
+	return true, nil
+}
+
+func (f *fakeMigrationK8sUtils) RecordPVCEvent(context.Context, string, string, string, string) error {
+	return nil
+}
+
+type fakeMigrationCopier struct {
+	plugin.Plugin
+	k8sUtils *fakeMigrationK8sUtils
+	sn       string
+	volumes  map[string]bool
+	pairs    map[string]bool
+	created  int
+}
+
+func (p *fakeMigrationCopier) GetStorageSN(context.Context) (string, error) {
+	return p.sn, nil
+}
+
+func (p *fakeMigrationCopier) GetVolumeID(_ context.Context, name string) (string, error) {
+	return "id-" + name, nil
+}
+
+func (p *fakeMigrationCopier) CreateVolume(_ context.Context, name string, _ map[string]interface{}) (
+	utils.Volume, error) {
+	if err := p.k8sUtils.crash.step(); err != nil {
+		return nil, err
+	}
+	p.volumes[name] = true
+	return utils.NewVolume(name), nil
+}
+
+func (p *fakeMigrationCopier) DeleteVolume(_ context.Context, name string) error {
+	if err := p.k8sUtils.crash.step(); err != nil {
+		return err
+	}
+	delete(p.volumes, name)
+	return nil
+}
+
+func (p *fakeMigrationCopier) CreateReplicaCopy(context.Context, string, string, string) (string, error) {
+	if err := p.k8sUtils.crash.step(); err != nil {
+		return "", err
+	}
+	p.created++
+	pairID := fmt.Sprintf("pair-%d", p.created)
+	p.pairs[pairID] = true
+	return pairID, nil
+}
+
+func (p *fakeMigrationCopier) SyncReplicaCopy(context.Context, string) error {
+	if p.k8sUtils.pv.Annotations[constants.VolumeMigrationFenceAnnotation] == "" {
+		p.k8sUtils.t.Errorf("SyncReplicaCopy() is called before the source volume is fenced")
+	}
+	return p.k8sUtils.crash.step()
+}
+
+func (p *fakeMigrationCopier) GetReplicaCopyStatus(context.Context, string) (bool, int, error) {
+	return true, 100, nil
+}
+
+func (p *fakeMigrationCopier) DeleteReplicaCopy(_ context.Context, pairID string) error {
+	if err := p.k8sUtils.crash.step(); err != nil {
+		return err
+	}
+	delete(p.pairs, pairID)
+	return nil
+}
+
+type fakeMigrationSelector struct {
+	handler.BackendSelectInterface
+	backends map[string]*model.Backend
+}
+
+func (s *fakeMigrationSelector) SelectBackend(_ context.Context, name string) (*model.Backend, error) {
+	return s.backends[name], nil
+}
+
+func (s *fakeMigrationSelector) SelectPoolPair(_ context.Context, _ int64, parameters map[string]interface{}) (
+	*model.SelectPoolPair, error) {
+	name, _ := parameters["backend"].(string)
+	bk := s.backends[name]
+	return &model.SelectPoolPair{Local: &model.StoragePool{Name: "pool", Parent: bk.Name, Plugin: bk.Plugin}}, nil
+}
+
+type migrationFixture struct {
+	driver   *Driver
+	k8sUtils *fakeMigrationK8sUtils
+	source   *fakeMigrationCopier
+	target   *fakeMigrationCopier
+	client   *fake.Clientset
+}
+
+func newMigrationFixture(t *testing.T, crashAt int) *migrationFixture {
+	crash := &crashInjector{crashAt: crashAt}
+	k8sUtils := &fakeMigrationK8sUtils{t: t, crash: crash,
+		pv: newMigrationSourcePV(coreV1.PersistentVolumeFilesystem, coreV1.ReadWriteOnce)}
+	source := &fakeMigrationCopier{k8sUtils: k8sUtils, sn: "sn-old", volumes: map[string]bool{"pvc-1": true},
+		pairs: map[string]bool{}}
+	target := &fakeMigrationCopier{k8sUtils: k8sUtils, sn: "sn-new", volumes: map[string]bool{},
+		pairs: map[string]bool{}}
+
+	client := fake.NewSimpleClientset(&xuanwuV1.VolumeMigration{
+		ObjectMeta: metaV1.ObjectMeta{Name: "migrate-pvc-1"},
+		Spec: xuanwuV1.VolumeMigrationSpec{PersistentVolumeName: "pvc-1", TargetBackend: "new-backend",
+			Cutover: true},
+	})
+	client.PrependReactor("update", "volumemigrations",
+		func(k8stesting.Action) (bool, runtime.Object, error) {
+			if err := crash.step(); err != nil {
+				return true, nil, err
+			}
+			return false, nil, nil
+		})
+
+	return &migrationFixture{
+		driver: &Driver{name: "csi.huawei.com", k8sUtils: k8sUtils,
+			backendSelector: &fakeMigrationSelector{backends: map[string]*model.Backend{
+				"old-backend": {Name: "old-backend", Plugin: source},
+				"new-backend": {Name: "new-backend", Plugin: target},
+			}}},
+		k8sUtils: k8sUtils,
+		source:   source,
+		target:   target,
+		client:   client,
+	}
+}
+
+// reconcile runs the migration until it stops moving forward, as the informer does on each event and resync
+func (f *migrationFixture) reconcile(t *testing.T) *xuanwuV1.VolumeMigration {
+	var migration *xuanwuV1.VolumeMigration
+	for i := 0; i < 30; i++ {
+		var err error
+		migration, err = f.client.XuanwuV1().VolumeMigrations().Get(context.Background(), "migrate-pvc-1",
+			metaV1.GetOptions{})
+		if err != nil {
+			t.Fatalf("get volume migration error: %v", err)
+		}
+		f.driver.reconcileVolumeMigration(context.Background(), migration.DeepCopy())
+	}
+	return migration
+}
+
+func stubVolumeMigrationConfig(client *fake.Clientset) *gostub.Stubs {
+	config := cfg.MockCompletedConfig()
+	config.BackendUtils = client
+	stubs := gostub.StubFunc(&app.GetGlobalConfig, config)
+	stubs.StubFunc(&pkgUtils.CreatePVLabel)
+	return stubs
+}
+
+func TestReconcileVolumeMigrationCrashInEachStep(t *testing.T) {
+	for crashAt := 1; ; crashAt++ {
+		f := newMigrationFixture(t, crashAt)
+		stubs := stubVolumeMigrationConfig(f.client)
+		migration := f.reconcile(t)
+		stubs.Reset()
+
+		if migration.Status.Phase != xuanwuV1.VolumeMigrationCompleted || len(migration.Finalizers) != 0 {
+			t.Errorf("crash at step %d: migration phase = %s, finalizers = %v, message = %s, want completed",
+				crashAt, migration.Status.Phase, migration.Finalizers, migration.Status.Message)
+		}
+		pv := f.k8sUtils.pv
+		if pv.Spec.CSI.VolumeHandle != "new-backend.pvc-1" {
+			t.Errorf("crash at step %d: volume handle = %s, want new-backend.pvc-1", crashAt,
+				pv.Spec.CSI.VolumeHandle)
+		}
+		if !reflect.DeepEqual(pv.Finalizers, []string{"kubernetes.io/pv-protection"}) {
+			t.Errorf("crash at step %d: finalizers = %v, want the finalizers of the source", crashAt, pv.Finalizers)
+		}
+		if _, fenced := pv.Annotations[constants.VolumeMigrationFenceAnnotation]; fenced {
+			t.Errorf("crash at step %d: the migrated persistent volume is still fenced", crashAt)
+		}
+		if len(f.source.pairs) != 0 || f.source.created != 1 || len(f.target.volumes) != 1 {
+			t.Errorf("crash at step %d: pairs = %v, created pairs = %d, target volumes = %v", crashAt,
+				f.source.pairs, f.source.created, f.target.volumes)
+		}
+
+		if f.k8sUtils.crash.steps < crashAt {
+			break
+		}
+	}
+}
+
+func TestRollbackVolumeMigrationCrashInEachStep(t *testing.T) {
+	for crashAt := 1; ; crashAt++ {
+		f := newMigrationFixture(t, 0)
+		stubs := stubVolumeMigrationConfig(f.client)
+
+		// run until the source volume is fenced, then delete the migration before the final copy is completed
+		migrations := f.client.XuanwuV1().VolumeMigrations()
+		migration, _ := migrations.Get(context.Background(), "migrate-pvc-1", metaV1.GetOptions{})
+		migration.Spec.Cutover = false
+		_, _ = migrations.Update(context.Background(), migration, metaV1.UpdateOptions{})
+		f.reconcile(t)
+		migration, _ = migrations.Get(context.Background(), "migrate-pvc-1", metaV1.GetOptions{})
+		migration.Status.Phase = xuanwuV1.VolumeMigrationCuttingOver
+		migration, _ = migrations.UpdateStatus(context.Background(), migration, metaV1.UpdateOptions{})
+		if err := f.driver.fenceMigrationSource(context.Background(), migration); err != nil {
+			t.Fatalf("fenceMigrationSource() error = %v", err)
+		}
+		now := metaV1.Now()
+		migration.DeletionTimestamp = &now
+		_, _ = migrations.Update(context.Background(), migration, metaV1.UpdateOptions{})
+
+		f.k8sUtils.crash.steps, f.k8sUtils.crash.crashAt = 0, crashAt
+		migration = f.reconcile(t)
+		stubs.Reset()
+
+		if len(migration.Finalizers) != 0 {
+			t.Errorf("crash at step %d: finalizers = %v, want rolled back", crashAt, migration.Finalizers)
+		}
+		pv := f.k8sUtils.pv
+		if pv.Spec.CSI.VolumeHandle != "old-backend.pvc-1" {
+			t.Errorf("crash at step %d: volume handle = %s, want old-backend.pvc-1", crashAt,
+				pv.Spec.CSI.VolumeHandle)
+		}
+		if _, fenced := pv.Annotations[constants.VolumeMigrationFenceAnnotation]; fenced {
+			t.Errorf("crash at step %d: the source persistent volume is still fenced", crashAt)
+		}
+		if len(f.source.pairs) != 0 || len(f.target.volumes) != 0 {
+			t.Errorf("crash at step %d: pairs = %v, target volumes = %v", crashAt, f.source.pairs,
+				f.target.volumes)
+		}
+
+		if f.k8sUtils.crash.steps < crashAt {
+			break
+		}
+	}
+}
+
+func TestCheckVolumeMigrationFence(t *testing.T) {
+	f := newMigrationFixture(t, 0)
+	if err := f.driver.checkVolumeMigrationFence(context.Background(), "old-backend.pvc-1"); err != nil {
+		t.Errorf("checkVolumeMigrationFence() error = %v, want nil before the cutover", err)
+	}
+
+	f.k8sUtils.pv.Annotations = map[string]string{constants.VolumeMigrationFenceAnnotation: "migrate-pvc-1"}
+	if err := f.driver.checkVolumeMigrationFence(context.Background(), "old-backend.pvc-1"); err == nil {
+		t.Errorf("checkVolumeMigrationFence() error = nil, want the fenced volume refused")
+	}
+}
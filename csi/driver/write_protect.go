@@ -0,0 +1,94 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package driver
+
+import (
+	"context"
+	"errors"
+
+	coreV1 "k8s.io/api/core/v1"
+
+	"huawei-csi-driver/csi/backend/plugin"
+	"huawei-csi-driver/pkg/constants"
+	"huawei-csi-driver/utils"
+	"huawei-csi-driver/utils/log"
+)
+
+// WatchWriteProtectRemoval removes the write protection of the volumes requested by the PV annotation,
+// the annotation is only allowed to be set by the cluster admins, which is enforced by the admission webhook
+func (d *Driver) WatchWriteProtectRemoval(ctx context.Context, stopCh <-chan struct{}) {
+	log.AddContext(ctx).Infoln("Start to watch write protection removal")
+	d.k8sUtils.WatchPersistentVolumes(ctx, func(pv *coreV1.PersistentVolume) {
+		d.removeWriteProtect(ctx, pv)
+	}, stopCh)
+}
+
+// isWriteProtectRemovalRequested checks whether the removal of the write protection of the volume is requested
+func isWriteProtectRemovalRequested(pv *coreV1.PersistentVolume, driverName string) bool {
+	if pv.Spec.CSI == nil || pv.Spec.CSI.Driver != driverName {
+		return false
+	}
+
+	return pv.Annotations[constants.WriteProtectAnnotation] == constants.WriteProtectRemove
+}
+
+func (d *Driver) removeWriteProtect(ctx context.Context, pv *coreV1.PersistentVolume) {
+	if !isWriteProtectRemovalRequested(pv, d.name) {
+		return
+	}
+
+	volumeId := pv.Spec.CSI.VolumeHandle
+	backendName, volName := utils.SplitVolumeId(volumeId)
+	backend, err := d.backendSelector.SelectBackend(ctx, backendName)
+	if backend == nil || err != nil {
+		log.AddContext(ctx).Errorf("Backend %s of volume %s doesn't exist, error: %v", backendName, volumeId, err)
+		return
+	}
+
+	remover, ok := backend.Plugin.(plugin.WriteProtectRemover)
+	if !ok {
+		log.AddContext(ctx).Warningf("Backend %s of volume %s does not support annotation %s",
+			backendName, volumeId, constants.WriteProtectAnnotation)
+		return
+	}
+
+	// the removal is retried when the PV is resynchronized after the maintenance ends
+	if err = d.checkBackendMaintenance(ctx, backend, volName, "removing write protection"); err != nil {
+		return
+	}
+
+	log.AddContext(ctx).Infof("Start to remove write protection of volume %s", volumeId)
+	if err = remover.RemoveWriteProtect(ctx, volName); err != nil {
+		if errors.Is(err, constants.ErrVolumeNotFound) {
+			log.AddContext(ctx).Warningf("Volume %s does not exist on the storage, skip removing its write "+
+				"protection", volumeId)
+			return
+		}
+		log.AddContext(ctx).Errorf("Remove write protection of volume %s error: %v", volumeId, err)
+		return
+	}
+
+	err = d.k8sUtils.UpdatePVAnnotations(ctx, pv.Name, map[string]string{
+		constants.WriteProtectAnnotation: constants.WriteProtectRemoved,
+	})
+	if err != nil {
+		log.AddContext(ctx).Errorf("Record write protection removal of volume %s error: %v", volumeId, err)
+		return
+	}
+
+	log.AddContext(ctx).Infof("Write protection of volume %s is removed", volumeId)
+}
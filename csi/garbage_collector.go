@@ -24,6 +24,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"huawei-csi-driver/connector"
 	"huawei-csi-driver/connector/utils/lock"
 	"huawei-csi-driver/csi/app"
 	"huawei-csi-driver/csi/manage"
@@ -228,8 +229,14 @@ func checkAndClearStaleDevices(ctx context.Context, k8sUtils k8sutils.Interface,
 			continue
 		}
 
+		volumeRetry := retry
+		if _, ok := volumeAttr[connector.DeviceCleanupTimeoutKey]; ok {
+			cleanupTimeout := connector.GetDeviceCleanupTimeout(connector.WithVolumeTimeouts(ctx, volumeAttr))
+			volumeRetry = int(cleanupTimeout.Seconds()) / lock.GetLockTimeoutSec
+		}
+
 		staleVolumesCnt++
-		go cleanStaleDevicesWithRetry(ctx, retry, nodePV.VolumeHandle, lunWWN, staleDeviceCleanupChan)
+		go cleanStaleDevicesWithRetry(ctx, volumeRetry, nodePV.VolumeHandle, lunWWN, staleDeviceCleanupChan)
 	}
 
 	for i := 0; i < staleVolumesCnt; i++ {
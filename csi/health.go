@@ -0,0 +1,134 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"k8s.io/client-go/kubernetes"
+
+	"huawei-csi-driver/csi/app"
+	"huawei-csi-driver/csi/backend/handler"
+	"huawei-csi-driver/csi/provider"
+	pkgUtils "huawei-csi-driver/pkg/utils"
+	"huawei-csi-driver/utils/health"
+	"huawei-csi-driver/utils/log"
+)
+
+const (
+	// the backend cache is stale if it is not refreshed in the periods of backend update interval
+	backendCacheStalePeriods = 3
+	// backendSidecarLockName is the prefix of the leader lock of the storage backend sidecar
+	backendSidecarLockName = "sb-sidecar-"
+)
+
+var (
+	// warmUp is opened when the driver completes the preparations before serving the requests
+	warmUp = health.NewGate("warm-up")
+	// csiServing is opened when the driver starts to serve the CSI socket
+	csiServing = health.NewGate("serving CSI socket")
+)
+
+// startHealthServer serves the liveness and readiness probes of the driver if the address is configured
+func startHealthServer(ctx context.Context, isController bool) {
+	address := app.GetGlobalConfig().HealthAddress
+	if address == "" {
+		return
+	}
+
+	server := health.NewServer()
+	server.AddLivenessCheck("csi-server", checkCSIServerAlive)
+	server.AddReadinessCheck("warm-up", warmUp.Check)
+	server.AddReadinessCheck("csi-socket", csiServing.Check)
+	server.AddReadinessCheck("csi-server", probeCSIServer)
+	if isController {
+		server.AddReadinessCheck("backend-cache", newBackendCacheCheck(ctx))
+	}
+
+	go server.Serve(ctx, address)
+}
+
+// checkCSIServerAlive probes the CSI server once it is started, the server being started is
+// reported by the readiness probe, so that the slow warm-up does not restart the driver
+func checkCSIServerAlive(ctx context.Context) error {
+	if csiServing.Check(ctx) != nil {
+		return nil
+	}
+
+	return probeCSIServer(ctx)
+}
+
+func probeCSIServer(ctx context.Context) error {
+	conn, err := grpc.DialContext(ctx, "unix:"+app.GetGlobalConfig().Endpoint,
+		grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithBlock())
+	if err != nil {
+		return fmt.Errorf("connect to %s failed, error: %v", app.GetGlobalConfig().Endpoint, err)
+	}
+	defer conn.Close()
+
+	_, err = csi.NewIdentityClient(conn).Probe(ctx, &csi.ProbeRequest{})
+	return err
+}
+
+// newBackendCacheCheck returns the check whether the backend cache is refreshed by the storage backend sidecar
+// in time. Only the leader of the sidecar polls the driver of its own pod, so the cache of the other replicas is
+// never refreshed and they are skipped.
+func newBackendCacheCheck(ctx context.Context) health.Check {
+	var k8sClient kubernetes.Interface
+	if app.GetGlobalConfig().EnableLeaderElection {
+		clientSet, _, err := pkgUtils.GetK8SAndSBCClient(ctx)
+		if err != nil {
+			log.AddContext(ctx).Warningf("Get kubernetes client error: %v, skip checking the backend cache", err)
+			return func(context.Context) error { return nil }
+		}
+		k8sClient = clientSet
+	}
+
+	return func(ctx context.Context) error {
+		interval := time.Duration(app.GetGlobalConfig().BackendUpdateInterval) * time.Second
+		if interval <= 0 || handler.NewCacheWrapper().Count() == 0 {
+			return nil
+		}
+
+		if k8sClient != nil {
+			leader, err := pkgUtils.IsLeader(ctx, pkgUtils.LeaderElectionConf{
+				LeaderName:    backendSidecarLockName + app.GetGlobalConfig().DriverName,
+				LockType:      app.GetGlobalConfig().LeaderLockType,
+				LockNamespace: app.GetGlobalConfig().LeaderLockNamespace,
+			}, k8sClient)
+			if err != nil {
+				log.AddContext(ctx).Warningf("Check leader of the storage backend sidecar error: %v, skip "+
+					"checking the backend cache", err)
+				return nil
+			}
+			if !leader {
+				return nil
+			}
+		}
+
+		if err := provider.BackendStatsHeartbeat.CheckAge(backendCacheStalePeriods * interval); err != nil {
+			return fmt.Errorf("backend cache is stale, %v", err)
+		}
+
+		return nil
+	}
+}
@@ -19,6 +19,7 @@ package main
 import (
 	"context"
 	"net"
+	"net/http"
 	"os"
 	"os/signal"
 	"path/filepath"
@@ -28,7 +29,9 @@ import (
 	"github.com/container-storage-interface/spec/lib/go/csi"
 	"github.com/sirupsen/logrus"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/keepalive"
 
+	"huawei-csi-driver/connector"
 	"huawei-csi-driver/connector/host"
 	connUtils "huawei-csi-driver/connector/utils"
 	"huawei-csi-driver/connector/utils/lock"
@@ -38,10 +41,13 @@ import (
 	"huawei-csi-driver/csi/driver"
 	"huawei-csi-driver/csi/provider"
 	"huawei-csi-driver/lib/drcsi"
+	pkgUtils "huawei-csi-driver/pkg/utils"
 	labelLock "huawei-csi-driver/pkg/utils/label_lock"
 	"huawei-csi-driver/utils"
 	"huawei-csi-driver/utils/log"
+	"huawei-csi-driver/utils/metrics"
 	"huawei-csi-driver/utils/notify"
+	"huawei-csi-driver/utils/tracing"
 	"huawei-csi-driver/utils/version"
 )
 
@@ -52,6 +58,15 @@ const (
 
 	csiVersion      = "4.3.0"
 	endpointDirPerm = 0755
+
+	// releaseClientTimeout bounds the logout of all the backends on shutdown
+	releaseClientTimeout = 3 * time.Second
+
+	// drKeepaliveMinTime is the minimum keep-alive ping interval accepted from the storage-backend-sidecar
+	drKeepaliveMinTime = 10 * time.Second
+
+	// leaderWorkersLockName is the prefix of the resource lock of the controller leader workers
+	leaderWorkersLockName = "huawei-csi-controller-"
 )
 
 var (
@@ -85,7 +100,20 @@ func ensureRuntimePanicLogging(ctx context.Context) {
 }
 
 func releaseStorageClient(ctx context.Context) {
-	handler.NewCacheWrapper().Clear(ctx)
+	ctx, cancel := context.WithTimeout(ctx, releaseClientTimeout)
+	defer cancel()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		handler.NewCacheWrapper().Clear(ctx)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		log.Warningf("Release storage clients incomplete, exited due to timeout")
+	}
 }
 
 func runCSIController(ctx context.Context) {
@@ -96,8 +124,26 @@ func runCSIController(ctx context.Context) {
 	// Clean up before exiting
 	go exitClean(true)
 
+	// Serve the liveness and readiness probes of the controller
+	startHealthServer(ctx, true)
+
 	// Refresh backend cache
-	go job.RunSyncBackendTaskInBackground()
+	go func() {
+		job.RunSyncBackendTaskInBackground()
+		warmUp.Open()
+	}()
+
+	// Serve the metrics of the controller
+	if app.GetGlobalConfig().MetricsAddress != "" {
+		go metrics.Serve(ctx, app.GetGlobalConfig().MetricsAddress, map[string]http.Handler{
+			handler.CapacityTrendPath: handler.CapacityTrendHandler(),
+		})
+	}
+
+	// Reload backends when their configmap changes
+	if app.GetGlobalConfig().BackendConfigConfigmap != "" {
+		go job.WatchBackendConfigmapInBackground(app.GetGlobalConfig().BackendConfigConfigmap)
+	}
 
 	// register the kahu community DRCSI service
 	go registerDRCSIServer()
@@ -112,6 +158,9 @@ func runCSIController(ctx context.Context) {
 func runCSINode(ctx context.Context) {
 	go exitClean(false)
 
+	// Serve the liveness and readiness probes of the node
+	startHealthServer(ctx, false)
+
 	// Init file lock
 	err := lock.InitLock(app.GetGlobalConfig().DriverName)
 	if err != nil {
@@ -128,6 +177,11 @@ func runCSINode(ctx context.Context) {
 
 	triggerGarbageCollector()
 
+	// Serve the metrics of the node, such as the cleaned orphaned multipath maps
+	if app.GetGlobalConfig().MetricsAddress != "" {
+		go metrics.Serve(ctx, app.GetGlobalConfig().MetricsAddress, nil)
+	}
+
 	// Save host info to secret, such as: hostname, initiator
 	go func() {
 		if err := host.SaveNodeHostInfoToSecret(context.Background()); err != nil {
@@ -136,11 +190,24 @@ func runCSINode(ctx context.Context) {
 		log.Infof("save node info to secret success")
 	}()
 
+	warmUp.Open()
+
 	// register the K8S community CSI service
 	registerCSIServer()
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == pluginInfoCommand {
+		if err := printPluginInfo(); err != nil {
+			logrus.Fatalf("Print plugin info failed. error: %v", err)
+		}
+		return
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == nodePrecheckCommand {
+		os.Exit(runNodePrecheck(os.Args[2:]))
+	}
+
 	// Processing Input Parameters
 	if err := app.NewCommand().Execute(); err != nil {
 		logrus.Fatalf("Execute app command failed. error: %v", err)
@@ -159,6 +226,16 @@ func main() {
 		logrus.Fatalf("Init log error: %v", err)
 	}
 
+	// Export the spans of the requests to the storage
+	if app.GetGlobalConfig().EnableTracing {
+		shutdownTracing, err := tracing.Init(context.Background(), app.GetGlobalConfig().TracingEndpoint,
+			getLogFileName())
+		if err != nil {
+			notify.Stop("Init tracing error: %v", err)
+		}
+		defer shutdownTracing(context.Background())
+	}
+
 	// Start CSI service
 	if app.GetGlobalConfig().Controller {
 		runCSIController(context.Background())
@@ -172,6 +249,11 @@ func registerDRCSIServer() {
 	drListener := listenEndpoint(app.GetGlobalConfig().DrEndpoint)
 	opts := []grpc.ServerOption{
 		grpc.UnaryInterceptor(log.EnsureGRPCContext),
+		// accept the keep-alive pings of the idle storage-backend-sidecar connection
+		grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+			MinTime:             drKeepaliveMinTime,
+			PermitWithoutStream: true,
+		}),
 	}
 	grpcServer := grpc.NewServer(opts...)
 	drcsi.RegisterIdentityServer(grpcServer, p)
@@ -187,10 +269,75 @@ func registerCSIServer() {
 		csiVersion,
 		app.GetGlobalConfig().K8sUtils,
 		app.GetGlobalConfig().NodeName)
+	if app.GetGlobalConfig().Controller {
+		go runLeaderWorkers(context.Background(), d)
+		go d.PublishControllerVersion(context.Background())
+		go d.RestorePlacementGroups(context.Background())
+	}
+
 	listener := listenEndpoint(app.GetGlobalConfig().Endpoint)
 	registerServer(listener, d)
 }
 
+// runLeaderWorkers runs the background workers of the controller which act on the whole cluster, so that only
+// one replica runs them when the leader election is enabled
+func runLeaderWorkers(ctx context.Context, d *driver.Driver) {
+	run := func(ctx context.Context) {
+		log.AddContext(ctx).Infoln("Start the leader workers of the controller")
+		if app.GetGlobalConfig().EnableNodeDeletionDetach {
+			go d.WatchNodeDeletion(ctx, ctx.Done())
+		}
+		go d.WatchDTreeMigration(ctx, ctx.Done())
+		go d.WatchVolumeMigrations(ctx, ctx.Done())
+		go d.WatchWriteProtectRemoval(ctx, ctx.Done())
+		if app.GetGlobalConfig().EnableBackendTopology {
+			go d.WatchBackendNodeLabels(ctx, ctx.Done())
+		}
+		if app.GetGlobalConfig().EnableRetainedShareCleanup {
+			go d.WatchRetainedVolumeShares(ctx, ctx.Done())
+		}
+		if app.GetGlobalConfig().DriftDetectionInterval > 0 {
+			go d.DetectDriftInBackground(ctx, driver.DriftDetectionConfig{
+				Interval:           app.GetGlobalConfig().DriftDetectionInterval,
+				SampleSize:         app.GetGlobalConfig().DriftDetectionSampleSize,
+				QPS:                app.GetGlobalConfig().DriftDetectionQPS,
+				BackendConcurrency: app.GetGlobalConfig().DriftDetectionBackendConcurrency,
+			}, ctx.Done())
+		}
+		if app.GetGlobalConfig().EnableStorageCapacity {
+			go d.PublishStorageCapacitiesInBackground(ctx,
+				time.Duration(app.GetGlobalConfig().BackendUpdateInterval)*time.Second, ctx.Done())
+		}
+		if app.GetGlobalConfig().TierSnapshotsAfter > 0 {
+			go d.TierSnapshotsInBackground(ctx, app.GetGlobalConfig().TierSnapshotsAfter, ctx.Done())
+		}
+		<-ctx.Done()
+	}
+
+	if !app.GetGlobalConfig().EnableLeaderElection {
+		run(ctx)
+		return
+	}
+
+	k8sClient, _, err := pkgUtils.GetK8SAndSBCClient(ctx)
+	if err != nil {
+		notify.Stop("Get kubernetes client for leader election error: %v", err)
+	}
+
+	leaderElection := pkgUtils.LeaderElectionConf{
+		LeaderName:    leaderWorkersLockName + app.GetGlobalConfig().DriverName,
+		LeaseDuration: app.GetGlobalConfig().LeaderLeaseDuration,
+		RenewDeadline: app.GetGlobalConfig().LeaderRenewDeadline,
+		RetryPeriod:   app.GetGlobalConfig().LeaderRetryPeriod,
+		LockType:      app.GetGlobalConfig().LeaderLockType,
+		LockNamespace: app.GetGlobalConfig().LeaderLockNamespace,
+	}
+	err = pkgUtils.RunAsLeader(ctx, leaderElection, k8sClient, pkgUtils.InitRecorder(k8sClient, "huawei-csi"), run)
+	if err != nil {
+		notify.Stop("Run the leader workers error: %v", err)
+	}
+}
+
 func listenEndpoint(endpoint string) net.Listener {
 	endpointDir := filepath.Dir(endpoint)
 	_, err := os.Stat(endpointDir)
@@ -227,6 +374,7 @@ func registerServer(listener net.Listener, d *driver.Driver) {
 	csi.RegisterNodeServer(server, d)
 
 	log.Infof("Starting Huawei CSI driver, listening on %s", app.GetGlobalConfig().Endpoint)
+	csiServing.Open()
 	if err := server.Serve(listener); err != nil {
 		notify.Stop("Start Huawei CSI driver error: %v", err)
 	}
@@ -250,6 +398,12 @@ func checkMultiPathService() {
 	if err != nil {
 		notify.Stop("Check multipath service failed. error:%v", err)
 	}
+
+	if app.GetGlobalConfig().VolumeUseMultiPath && app.GetGlobalConfig().NvmeMultiPathType == connector.NativeNVMe {
+		if err = connector.VerifyNativeNVMeMultipath(context.Background()); err != nil {
+			notify.Stop("Check native nvme multipath failed. error:%v", err)
+		}
+	}
 	log.Infof("Check multipath service success.")
 }
 
@@ -29,6 +29,7 @@ import (
 
 	"huawei-csi-driver/connector"
 	_ "huawei-csi-driver/connector/nfs_plus"
+	"huawei-csi-driver/connector/nvme"
 	"huawei-csi-driver/csi/app"
 	"huawei-csi-driver/csi/backend"
 	"huawei-csi-driver/csi/backend/plugin"
@@ -39,6 +40,10 @@ import (
 	"huawei-csi-driver/utils/log"
 )
 
+// DTreeParentNameKey is the key of the parent filesystem of the dTree in the volume context, which is the parent
+// the dTree is created under, and in the publish context, which is the parent the dTree is moved to
+const DTreeParentNameKey = "dTreeParentName"
+
 // BuildParameterOption define build function
 type BuildParameterOption func(map[string]interface{}) error
 
@@ -69,6 +74,9 @@ func WithControllerPublishInfo(ctx context.Context, req *csi.NodeStageVolumeRequ
 		}
 
 		parameters["publishInfo"] = publishInfo
+		if targetPorts := req.PublishContext["targetPorts"]; targetPorts != "" {
+			parameters["targetPorts"] = strings.Split(targetPorts, ",")
+		}
 		return nil
 	}
 }
@@ -146,6 +154,13 @@ func WithVolumeCapability(ctx context.Context, req *csi.NodeStageVolumeRequest)
 				opts = append(opts, "ro")
 			}
 
+			protocol, _ := parameters["protocol"].(string)
+			opts, err := MergeMountOptions(opts, req.GetVolumeContext()[MountOptionsKey], IsNasProtocol(protocol))
+			if err != nil {
+				log.AddContext(ctx).Errorf("merge mount options failed, error: %v", err)
+				return err
+			}
+
 			parameters["targetPath"] = req.GetStagingTargetPath()
 			parameters["fsType"] = mnt.GetFsType()
 			parameters["mountFlags"] = strings.Join(opts, ",")
@@ -176,6 +191,64 @@ func CheckParam(ctx context.Context, req *csi.NodeStageVolumeRequest) error {
 
 }
 
+// targetPorts returns the target port WWNs or IPs of the paths in the publish info
+func (c *ControllerPublishInfo) targetPorts() []string {
+	targetPorts := append(append([]string{}, c.TgtWWNs...), c.TgtPortals...)
+	for _, pair := range c.PortWWNList {
+		targetPorts = append(targetPorts, pair.TargetPortWWN)
+	}
+
+	return targetPorts
+}
+
+// filterTargetPorts keeps only the paths through the target ports which the controller intended to use,
+// and fails if none of the paths matches. The paths are kept as they are if no target port is expected.
+func (c *ControllerPublishInfo) filterTargetPorts(expected []string) error {
+	if len(expected) == 0 {
+		return nil
+	}
+
+	var tgtPortals, tgtIQNs, tgtHostLUNs, tgtWWNs []string
+	for i, portal := range c.TgtPortals {
+		if !utils.IsContain(portal, expected) {
+			continue
+		}
+		tgtPortals = append(tgtPortals, portal)
+		if i < len(c.TgtIQNs) {
+			tgtIQNs = append(tgtIQNs, c.TgtIQNs[i])
+		}
+		if len(c.TgtWWNs) == 0 && i < len(c.TgtHostLUNs) {
+			tgtHostLUNs = append(tgtHostLUNs, c.TgtHostLUNs[i])
+		}
+	}
+
+	for i, wwn := range c.TgtWWNs {
+		if !utils.IsContain(wwn, expected) {
+			continue
+		}
+		tgtWWNs = append(tgtWWNs, wwn)
+		if i < len(c.TgtHostLUNs) {
+			tgtHostLUNs = append(tgtHostLUNs, c.TgtHostLUNs[i])
+		}
+	}
+
+	var portWWNList []nvme.PortWWNPair
+	for _, pair := range c.PortWWNList {
+		if utils.IsContain(pair.TargetPortWWN, expected) {
+			portWWNList = append(portWWNList, pair)
+		}
+	}
+
+	if len(tgtPortals) == 0 && len(tgtWWNs) == 0 && len(portWWNList) == 0 {
+		return fmt.Errorf("none of the target ports %v matches the expected target ports %v",
+			c.targetPorts(), expected)
+	}
+
+	c.TgtPortals, c.TgtIQNs, c.TgtHostLUNs, c.TgtWWNs = tgtPortals, tgtIQNs, tgtHostLUNs, tgtWWNs
+	c.PortWWNList = portWWNList
+	return nil
+}
+
 // ReflectToMap use reflection to convert ControllerPublishInfo to map, where key of map is json tag
 // and value of map is field value
 func (c *ControllerPublishInfo) ReflectToMap() map[string]interface{} {
@@ -370,7 +443,7 @@ func PublishFilesystem(ctx context.Context, req *csi.NodePublishVolumeRequest) e
 	opts := []string{"bind"}
 	// process volume with type is dTree
 	if bk.dTreeParentName != "" {
-		sourcePath = bk.portals[0] + ":/" + bk.dTreeParentName + "/" + volumeName
+		sourcePath = bk.portals[0] + ":/" + getDTreeParentName(req, bk.dTreeParentName) + "/" + volumeName
 		protocol = bk.protocol
 		if req.GetVolumeCapability() != nil && req.GetVolumeCapability().GetMount() != nil &&
 			req.GetVolumeCapability().GetMount().GetMountFlags() != nil {
@@ -383,6 +456,16 @@ func PublishFilesystem(ctx context.Context, req *csi.NodePublishVolumeRequest) e
 		opts = append(opts, "ro")
 	}
 
+	// the mountOptions are merged for every NAS type, the ones of the block volumes are applied when their
+	// filesystems are staged
+	if IsNasProtocol(bk.protocol) {
+		opts, err = MergeMountOptions(opts, req.GetVolumeContext()[MountOptionsKey], true)
+		if err != nil {
+			log.AddContext(ctx).Errorf("merge mount options failed, error: %v", err)
+			return err
+		}
+	}
+
 	connectInfo := map[string]interface{}{
 		"srcType":    connector.MountFSType,
 		"sourcePath": sourcePath,
@@ -397,10 +480,30 @@ func PublishFilesystem(ctx context.Context, req *csi.NodePublishVolumeRequest) e
 		return err
 	}
 
+	// the staging path of dTree volume is not used, so there is no need to count the references
+	if bk.dTreeParentName == "" {
+		if err = addPublishTarget(req.GetStagingTargetPath(), targetPath); err != nil {
+			log.AddContext(ctx).Errorf("Record publish target %s of volume %s error: %v", targetPath, volumeId, err)
+			return err
+		}
+	}
+
 	log.AddContext(ctx).Infof("Volume %s is node published to %s", volumeId, targetPath)
 	return nil
 }
 
+// getDTreeParentName returns the parent filesystem of the dTree volume, the parent in the publish context is the
+// current one of a moved volume, the volumes created before the parent is recorded are under the backend parent
+func getDTreeParentName(req *csi.NodePublishVolumeRequest, backendParent string) string {
+	if parent := req.GetPublishContext()[DTreeParentNameKey]; parent != "" {
+		return parent
+	}
+	if parent := req.GetVolumeContext()[DTreeParentNameKey]; parent != "" {
+		return parent
+	}
+	return backendParent
+}
+
 func getConnectorByProtocol(ctx context.Context, protocol string) connector.Connector {
 	return map[string]connector.Connector{
 		plugin.ProtocolNfs:     connector.GetConnector(ctx, connector.NFSDriver),
@@ -67,6 +67,10 @@ func mockControllerPublishInfo() *ControllerPublishInfo {
 		TgtWWNs:            []string{"mock_wwn_1"},
 		VolumeUseMultiPath: true,
 		MultiPathType:      "mock_type_1",
+		HostGroupName:      "mock_host_group_1",
+		HostName:           "mock_host_1",
+		HostID:             "mock_host_id_1",
+		RemoteHostID:       "mock_remote_host_id_1",
 		PortWWNList: []nvme.PortWWNPair{
 			{InitiatorPortWWN: "mock_initiator_port_wwn_1", TargetPortWWN: "mock_target_port_wwn_1"},
 		},
@@ -220,6 +224,10 @@ func TestControllerPublishInfoReflectToMap(t *testing.T) {
 		"tgtWWNs":            []string{"mock_wwn_1"},
 		"volumeUseMultiPath": true,
 		"multiPathType":      "mock_type_1",
+		"hostGroupName":      "mock_host_group_1",
+		"hostName":           "mock_host_1",
+		"hostID":             "mock_host_id_1",
+		"remoteHostID":       "mock_remote_host_id_1",
 		"portWWNList": []nvme.PortWWNPair{
 			{InitiatorPortWWN: "mock_initiator_port_wwn_1", TargetPortWWN: "mock_target_port_wwn_1"},
 		},
@@ -441,3 +449,72 @@ func newManagerTest(t *testing.T, testCase testCaseStructForNewManager) {
 		t.Errorf("NewManager() want manager = %+v, got manager = %+v", testCase.want, got)
 	}
 }
+
+func TestGetDTreeParentName(t *testing.T) {
+	tests := []struct {
+		name           string
+		publishContext map[string]string
+		volumeContext  map[string]string
+		want           string
+	}{
+		{"Moved", map[string]string{DTreeParentNameKey: "fs2"}, map[string]string{DTreeParentNameKey: "fs1"},
+			"fs2"},
+		{"CreatedUnder", nil, map[string]string{DTreeParentNameKey: "fs1"}, "fs1"},
+		{"NotRecorded", nil, nil, "backend-fs"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &csi.NodePublishVolumeRequest{PublishContext: tt.publishContext, VolumeContext: tt.volumeContext}
+			if got := getDTreeParentName(req, "backend-fs"); got != tt.want {
+				t.Errorf("getDTreeParentName() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterTargetPorts(t *testing.T) {
+	tests := []struct {
+		name     string
+		info     ControllerPublishInfo
+		expected []string
+		want     ControllerPublishInfo
+		wantErr  bool
+	}{
+		{"NotExpected",
+			ControllerPublishInfo{TgtPortals: []string{"ip1", "ip2"}, TgtIQNs: []string{"iqn1", "iqn2"},
+				TgtHostLUNs: []string{"1", "2"}}, nil,
+			ControllerPublishInfo{TgtPortals: []string{"ip1", "ip2"}, TgtIQNs: []string{"iqn1", "iqn2"},
+				TgtHostLUNs: []string{"1", "2"}}, false},
+		{"Iscsi",
+			ControllerPublishInfo{TgtPortals: []string{"ip1", "ip2"}, TgtIQNs: []string{"iqn1", "iqn2"},
+				TgtHostLUNs: []string{"1", "2"}}, []string{"ip2"},
+			ControllerPublishInfo{TgtPortals: []string{"ip2"}, TgtIQNs: []string{"iqn2"},
+				TgtHostLUNs: []string{"2"}}, false},
+		{"Fc",
+			ControllerPublishInfo{TgtWWNs: []string{"wwn1", "wwn2"}, TgtHostLUNs: []string{"1", "2"}},
+			[]string{"wwn1"},
+			ControllerPublishInfo{TgtWWNs: []string{"wwn1"}, TgtHostLUNs: []string{"1"}}, false},
+		{"FcNvme",
+			ControllerPublishInfo{PortWWNList: []nvme.PortWWNPair{{TargetPortWWN: "wwn1"}, {TargetPortWWN: "wwn2"}}},
+			[]string{"wwn2"},
+			ControllerPublishInfo{PortWWNList: []nvme.PortWWNPair{{TargetPortWWN: "wwn2"}}}, false},
+		{"NoneMatched",
+			ControllerPublishInfo{TgtPortals: []string{"ip1"}, TgtIQNs: []string{"iqn1"},
+				TgtHostLUNs: []string{"1"}}, []string{"ip3"},
+			ControllerPublishInfo{TgtPortals: []string{"ip1"}, TgtIQNs: []string{"iqn1"},
+				TgtHostLUNs: []string{"1"}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.info.filterTargetPorts(tt.expected)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("filterTargetPorts() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(tt.info, tt.want) {
+				t.Errorf("filterTargetPorts() got %+v, want %+v", tt.info, tt.want)
+			}
+		})
+	}
+}
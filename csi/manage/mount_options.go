@@ -0,0 +1,184 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package manage
+
+import (
+	"fmt"
+	"strings"
+
+	"huawei-csi-driver/csi/backend/plugin"
+)
+
+// MountOptionsKey is the key of mount options in StorageClass parameters and volume context
+const MountOptionsKey = "mountOptions"
+
+var (
+	// allowedMountOptions is the allow-list of options that can be passed through StorageClass mountOptions
+	// to the NAS volumes,
+	// the value indicates whether the option requires a value, e.g. timeo=600.
+	// nfsvers and vers are not allowed here, they are derived from the protocol by mountFlags.
+	allowedMountOptions = map[string]bool{
+		"hard":       false,
+		"soft":       false,
+		"intr":       false,
+		"nointr":     false,
+		"timeo":      true,
+		"retrans":    true,
+		"rsize":      true,
+		"wsize":      true,
+		"actimeo":    true,
+		"acregmin":   true,
+		"acregmax":   true,
+		"acdirmin":   true,
+		"acdirmax":   true,
+		"noac":       false,
+		"nolock":     false,
+		"lock":       false,
+		"proto":      true,
+		"nconnect":   true,
+		"sec":        true,
+		"sync":       false,
+		"async":      false,
+		"atime":      false,
+		"noatime":    false,
+		"diratime":   false,
+		"nodiratime": false,
+		"relatime":   false,
+		"discard":    false,
+		"nodiscard":  false,
+		"ro":         false,
+		"rw":         false,
+	}
+
+	// exclusiveMountOptions defines the options that can not be used at the same time
+	exclusiveMountOptions = map[string]string{
+		"hard":       "soft",
+		"soft":       "hard",
+		"intr":       "nointr",
+		"nointr":     "intr",
+		"lock":       "nolock",
+		"nolock":     "lock",
+		"sync":       "async",
+		"async":      "sync",
+		"atime":      "noatime",
+		"noatime":    "atime",
+		"diratime":   "nodiratime",
+		"nodiratime": "diratime",
+		"discard":    "nodiscard",
+		"nodiscard":  "discard",
+		"ro":         "rw",
+		"rw":         "ro",
+	}
+)
+
+func splitMountOption(option string) (string, string) {
+	key, value, _ := strings.Cut(option, "=")
+	return key, value
+}
+
+// IsNasProtocol returns whether the volumes of the protocol are mounted from the shares of the storage
+func IsNasProtocol(protocol string) bool {
+	return protocol == plugin.ProtocolNfs || protocol == plugin.ProtocolNfsPlus || protocol == plugin.PROTOCOL_DPC
+}
+
+// splitMountOptions splits the comma separated mountOptions without checking them, the options of the block
+// volumes are passed to the mount of their local filesystem, which the allow-list of the NAS volumes does not fit
+func splitMountOptions(mountOptions string) []string {
+	var options []string
+	for _, option := range strings.Split(mountOptions, ",") {
+		if option = strings.TrimSpace(option); option != "" {
+			options = append(options, option)
+		}
+	}
+	return options
+}
+
+// ParseMountOptions parses the comma separated mountOptions of the NAS volumes and checks them against
+// the allow-list
+func ParseMountOptions(mountOptions string) ([]string, error) {
+	var options []string
+	for _, option := range strings.Split(mountOptions, ",") {
+		option = strings.TrimSpace(option)
+		if option == "" {
+			continue
+		}
+
+		key, value := splitMountOption(option)
+		needValue, allowed := allowedMountOptions[key]
+		if !allowed {
+			return nil, fmt.Errorf("mount option [%s] is not allowed in %s", option, MountOptionsKey)
+		}
+
+		if needValue && value == "" {
+			return nil, fmt.Errorf("mount option [%s] in %s must be set in the format %s=<value>",
+				option, MountOptionsKey, key)
+		}
+
+		if !needValue && strings.Contains(option, "=") {
+			return nil, fmt.Errorf("mount option [%s] in %s does not support a value", option, MountOptionsKey)
+		}
+
+		options = append(options, option)
+	}
+
+	return options, checkMountOptionsConflict(options)
+}
+
+// MergeMountOptions merges the mountOptions with the protocol-derived options, the mountOptions of the NAS volumes
+// are checked against the allow-list. Duplicate options are only kept once, and the conflicting options will
+// return an error.
+func MergeMountOptions(protocolOptions []string, mountOptions string, nas bool) ([]string, error) {
+	options := splitMountOptions(mountOptions)
+	if nas {
+		var err error
+		if options, err = ParseMountOptions(mountOptions); err != nil {
+			return nil, err
+		}
+	}
+
+	merged := make([]string, 0, len(protocolOptions)+len(options))
+	exist := make(map[string]bool)
+	for _, option := range append(append([]string{}, protocolOptions...), options...) {
+		option = strings.TrimSpace(option)
+		if option == "" || exist[option] {
+			continue
+		}
+		exist[option] = true
+		merged = append(merged, option)
+	}
+
+	return merged, checkMountOptionsConflict(merged)
+}
+
+func checkMountOptionsConflict(options []string) error {
+	values := make(map[string]string)
+	for _, option := range options {
+		key, value := splitMountOption(option)
+		if exclusive, ok := exclusiveMountOptions[key]; ok {
+			if _, exist := values[exclusive]; exist {
+				return fmt.Errorf("mount option [%s] conflicts with [%s]", key, exclusive)
+			}
+		}
+
+		if exist, ok := values[key]; ok && exist != value {
+			return fmt.Errorf("mount option [%s] is set to different values [%s] and [%s]", key, exist, value)
+		}
+		values[key] = value
+	}
+
+	return nil
+}
@@ -0,0 +1,99 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package manage
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMergeMountOptions(t *testing.T) {
+	tests := []struct {
+		name            string
+		protocolOptions []string
+		mountOptions    string
+		nas             bool
+		want            []string
+		wantErr         bool
+	}{
+		{
+			name:            "test_merge_without_mount_options",
+			protocolOptions: []string{"nfsvers=3"},
+			mountOptions:    "",
+			nas:             true,
+			want:            []string{"nfsvers=3"},
+		},
+		{
+			name:            "test_merge_with_mount_options",
+			protocolOptions: []string{"nfsvers=4.1", "ro"},
+			mountOptions:    "hard, timeo=600,retrans=3,ro",
+			nas:             true,
+			want:            []string{"nfsvers=4.1", "ro", "hard", "timeo=600", "retrans=3"},
+		},
+		{
+			name:            "test_merge_with_not_allowed_option",
+			protocolOptions: []string{"nfsvers=3"},
+			mountOptions:    "nfsvers=4",
+			nas:             true,
+			wantErr:         true,
+		},
+		{
+			name:         "test_merge_with_missing_value",
+			mountOptions: "timeo",
+			nas:          true,
+			wantErr:      true,
+		},
+		{
+			name:            "test_merge_with_exclusive_options",
+			protocolOptions: []string{"ro"},
+			mountOptions:    "rw",
+			nas:             true,
+			wantErr:         true,
+		},
+		{
+			name:            "test_merge_with_different_values",
+			protocolOptions: []string{"timeo=100"},
+			mountOptions:    "timeo=600",
+			nas:             true,
+			wantErr:         true,
+		},
+		{
+			name:            "test_merge_block_options_without_allow_list",
+			protocolOptions: []string{"ro"},
+			mountOptions:    "nouuid, data=ordered",
+			want:            []string{"ro", "nouuid", "data=ordered"},
+		},
+		{
+			name:            "test_merge_block_options_with_exclusive_options",
+			protocolOptions: []string{"ro"},
+			mountOptions:    "rw",
+			wantErr:         true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := MergeMountOptions(tt.protocolOptions, tt.mountOptions, tt.nas)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("MergeMountOptions() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MergeMountOptions() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
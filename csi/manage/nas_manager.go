@@ -18,6 +18,7 @@ package manage
 
 import (
 	"context"
+	"strings"
 
 	"github.com/container-storage-interface/spec/lib/go/csi"
 
@@ -28,6 +29,9 @@ import (
 	"huawei-csi-driver/utils/log"
 )
 
+// SharePathKey is the volume context key of the nfs share path recorded when the volume is created
+const SharePathKey = "sharePath"
+
 // NasManager implements Manager interface
 type NasManager struct {
 	protocol        string
@@ -76,7 +80,7 @@ func (m *NasManager) StageVolume(ctx context.Context, req *csi.NodeStageVolumeRe
 	case plugin.PROTOCOL_DPC:
 		sourcePath = "/" + volumeName
 	case plugin.ProtocolNfs, plugin.ProtocolNfsPlus:
-		sourcePath = m.portals[0] + ":/" + volumeName
+		sourcePath = m.portals[0] + ":" + getSharePath(req.GetVolumeContext(), volumeName)
 	default:
 		return pkgUtils.Errorf(ctx, "stage volume protocol is invalid, protocol: %s, param: %+v",
 			m.protocol, parameters)
@@ -100,7 +104,20 @@ func (m *NasManager) UnStageVolume(ctx context.Context, req *csi.NodeUnstageVolu
 		log.AddContext(ctx).Infoln("dtree needn't to unstage volume")
 		return nil
 	}
-	return Unmount(ctx, req.GetStagingTargetPath())
+
+	stagingPath := req.GetStagingTargetPath()
+	count, err := prunePublishTargets(ctx, stagingPath, connector.MountPathIsExist)
+	if err != nil {
+		log.AddContext(ctx).Errorf("Check publish targets of staging path %s error: %v", stagingPath, err)
+		return err
+	}
+
+	if count > 0 {
+		return utils.Errorf(ctx, "volume %s is still published to %d targets, can not unmount the "+
+			"staging path %s", req.GetVolumeId(), count, stagingPath)
+	}
+
+	return Unmount(ctx, stagingPath)
 }
 
 // ExpandVolume for nas volumes, nodeExpandVolume is not required, because the NodeExpandionRequired field
@@ -115,3 +132,13 @@ func (m *NasManager) UnStageWithWwn(ctx context.Context, wwn, volumeId string) e
 	log.AddContext(ctx).Infof("start to unstage nas volume with wwn, wwn: %s, volumeId: %s", wwn, volumeId)
 	return nil
 }
+
+// getSharePath returns the nfs share path recorded in the volume context, the volumes created before the share
+// path is recorded are shared at the path of the filesystem
+func getSharePath(volumeContext map[string]string, volumeName string) string {
+	sharePath := strings.TrimSuffix(volumeContext[SharePathKey], "/")
+	if sharePath == "" {
+		return "/" + volumeName
+	}
+	return sharePath
+}
@@ -80,6 +80,33 @@ func TestNasManagerStageNfsVolume(t *testing.T) {
 	}
 }
 
+func TestNasManagerStageNfsVolumeWithSharePath(t *testing.T) {
+	manager := &NasManager{
+		protocol: "nfs",
+		portals:  []string{"127.0.0.1"},
+		Conn:     connector.GetConnector(context.Background(), connector.NFSDriver),
+	}
+
+	mockMountShare := gomonkey.ApplyFunc(Mount, func(ctx context.Context, parameters map[string]interface{}) error {
+		expectedConnectInfo := mockExpectedConnectInfo()
+		expectedConnectInfo["sourcePath"] = "127.0.0.1:/exports/k8s/pvc_nas_xxx"
+		expectedConnectInfo["portals"] = []string{"127.0.0.1"}
+		if !reflect.DeepEqual(parameters, expectedConnectInfo) {
+			return fmt.Errorf("stage nfs volume error parameter: %+v expectConnectInfo: %+v", parameters,
+				expectedConnectInfo)
+		}
+		return nil
+	})
+	defer mockMountShare.Reset()
+
+	req := mockNasStageVolumeRequest()
+	req.VolumeContext = map[string]string{SharePathKey: "/exports/k8s/pvc_nas_xxx/"}
+	err := manager.StageVolume(context.Background(), req)
+	if err != nil {
+		t.Errorf("TestNasManagerStageNfsVolumeWithSharePath() want error = nil, got error = %v", err)
+	}
+}
+
 func TestNasManagerStageDpcVolume(t *testing.T) {
 	manager := &NasManager{
 		protocol: "dpc",
@@ -0,0 +1,151 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package manage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"huawei-csi-driver/utils/log"
+)
+
+const (
+	// publishRecordFile records the target paths which the staged volume is published to, it is saved
+	// next to the staging path, so that it lives and dies with the staging metadata of kubelet
+	publishRecordFile = "huawei_csi_publish.json"
+
+	publishRecordFilePerm = 0600
+)
+
+// publishRecordLocks serializes the updates of the publish record of each staging path
+var publishRecordLocks sync.Map
+
+type publishRecord struct {
+	Targets []string `json:"targets"`
+}
+
+func getPublishRecordPath(stagingPath string) string {
+	return filepath.Join(filepath.Dir(filepath.Clean(stagingPath)), publishRecordFile)
+}
+
+func lockPublishRecord(stagingPath string) func() {
+	lock, _ := publishRecordLocks.LoadOrStore(getPublishRecordPath(stagingPath), &sync.Mutex{})
+	mutex := lock.(*sync.Mutex)
+	mutex.Lock()
+	return mutex.Unlock
+}
+
+func readPublishTargets(stagingPath string) (map[string]struct{}, error) {
+	targets := make(map[string]struct{})
+	data, err := os.ReadFile(getPublishRecordPath(stagingPath))
+	if errors.Is(err, os.ErrNotExist) {
+		return targets, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var record publishRecord
+	if err = json.Unmarshal(data, &record); err != nil {
+		return nil, err
+	}
+
+	for _, target := range record.Targets {
+		targets[target] = struct{}{}
+	}
+	return targets, nil
+}
+
+func writePublishTargets(stagingPath string, targets map[string]struct{}) error {
+	record := publishRecord{Targets: make([]string, 0, len(targets))}
+	for target := range targets {
+		record.Targets = append(record.Targets, target)
+	}
+	sort.Strings(record.Targets)
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	// write to a temporary file and rename it, so that the record is never half written
+	recordPath := getPublishRecordPath(stagingPath)
+	tmpPath := recordPath + ".tmp"
+	if err = os.WriteFile(tmpPath, data, publishRecordFilePerm); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, recordPath)
+}
+
+// addPublishTarget records that the staged volume is published to the target path, recording the same
+// target repeatedly is idempotent, so that the retries of kubelet are not counted twice
+func addPublishTarget(stagingPath, targetPath string) error {
+	defer lockPublishRecord(stagingPath)()
+
+	targets, err := readPublishTargets(stagingPath)
+	if err != nil {
+		return err
+	}
+
+	if _, exist := targets[targetPath]; exist {
+		return nil
+	}
+
+	targets[targetPath] = struct{}{}
+	return writePublishTargets(stagingPath, targets)
+}
+
+// prunePublishTargets removes the recorded targets which are no longer mounted, and returns the count of
+// targets which still use the staging path
+func prunePublishTargets(ctx context.Context, stagingPath string,
+	isMounted func(context.Context, string) (bool, error)) (int, error) {
+	defer lockPublishRecord(stagingPath)()
+
+	targets, err := readPublishTargets(stagingPath)
+	if err != nil {
+		return 0, err
+	}
+
+	for target := range targets {
+		mounted, err := isMounted(ctx, target)
+		if err != nil {
+			return 0, err
+		}
+
+		if !mounted {
+			log.AddContext(ctx).Infof("Target %s of staging path %s is unpublished", target, stagingPath)
+			delete(targets, target)
+		}
+	}
+
+	if len(targets) == 0 {
+		return 0, removePublishRecord(stagingPath)
+	}
+	return len(targets), writePublishTargets(stagingPath, targets)
+}
+
+func removePublishRecord(stagingPath string) error {
+	err := os.Remove(getPublishRecordPath(stagingPath))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
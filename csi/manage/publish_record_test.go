@@ -0,0 +1,87 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package manage
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+type fakeMounts struct {
+	sync.Mutex
+	targets map[string]bool
+}
+
+func (f *fakeMounts) set(target string, mounted bool) {
+	f.Lock()
+	defer f.Unlock()
+	f.targets[target] = mounted
+}
+
+func (f *fakeMounts) isMounted(ctx context.Context, target string) (bool, error) {
+	f.Lock()
+	defer f.Unlock()
+	return f.targets[target], nil
+}
+
+func TestPublishRecordInterleaved(t *testing.T) {
+	const targetCount = 100
+	stagingPath := filepath.Join(t.TempDir(), "globalmount")
+	mounts := &fakeMounts{targets: make(map[string]bool)}
+
+	var wg sync.WaitGroup
+	for i := 0; i < targetCount; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			target := fmt.Sprintf("/pods/%d/mount", i)
+			mounts.set(target, true)
+			// publish twice to simulate the retry of kubelet
+			for j := 0; j < 2; j++ {
+				if err := addPublishTarget(stagingPath, target); err != nil {
+					t.Errorf("addPublishTarget() error = %v", err)
+				}
+			}
+
+			// unpublish the odd targets while the others are still being published
+			if i%2 == 1 {
+				mounts.set(target, false)
+				if _, err := prunePublishTargets(context.TODO(), stagingPath, mounts.isMounted); err != nil {
+					t.Errorf("prunePublishTargets() error = %v", err)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	count, err := prunePublishTargets(context.TODO(), stagingPath, mounts.isMounted)
+	if err != nil || count != targetCount/2 {
+		t.Fatalf("prunePublishTargets() = %d, %v, want %d", count, err, targetCount/2)
+	}
+
+	for i := 0; i < targetCount; i += 2 {
+		mounts.set(fmt.Sprintf("/pods/%d/mount", i), false)
+	}
+
+	count, err = prunePublishTargets(context.TODO(), stagingPath, mounts.isMounted)
+	if err != nil || count != 0 {
+		t.Fatalf("prunePublishTargets() = %d, %v, want 0", count, err)
+	}
+}
@@ -19,6 +19,8 @@ package manage
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
 
 	"github.com/container-storage-interface/spec/lib/go/csi"
 
@@ -77,7 +79,8 @@ func (m *SanManager) StageVolume(ctx context.Context, req *csi.NodeStageVolumeRe
 	tasks := taskflow.NewTaskFlow(ctx, "StageVolume").
 		AddTaskWithOutRevert(clearResidualPathWithWwn).
 		AddTaskWithOutRevert(clearResidualPathWithLunId).
-		AddTaskWithOutRevert(connectVolume)
+		AddTaskWithOutRevert(connectVolume).
+		AddTaskWithOutRevert(verifyDeviceWwn)
 
 	if volMode, exist := parameters["volumeMode"].(string); exist && volMode == "Block" {
 		tasks = tasks.AddTaskWithOutRevert(stageForBlock)
@@ -159,6 +162,11 @@ func (m *SanManager) UnStageWithWwn(ctx context.Context, wwn, volumeId string) e
 		log.AddContext(ctx).Errorf("remove wwn file failed while unstage volume, "+
 			"volumeId: %s, error: %v", volumeId, err)
 	}
+
+	if err := utils.RemovePortalFile(ctx, volumeId); err != nil {
+		log.AddContext(ctx).Errorf("remove portal file failed while unstage volume, "+
+			"volumeId: %s, error: %v", volumeId, err)
+	}
 	return nil
 }
 
@@ -227,21 +235,90 @@ func connectVolume(ctx context.Context, parameters map[string]interface{}) error
 		return errors.New("publishInfo not fount while connect volume")
 	}
 
-	connectionParams := publishInfo.ReflectToMap()
 	conn, exist := parameters["connector"].(connector.Connector)
 	if !exist {
 		return errors.New("connector doesn't exist while connect volume")
 	}
 
+	expectedPorts, _ := parameters["targetPorts"].([]string)
+	if err := publishInfo.filterTargetPorts(expectedPorts); err != nil {
+		return utils.Errorf(ctx, "refuse to connect the volume of host group %s, error: %v",
+			publishInfo.HostGroupName, err)
+	}
+	connectionParams := publishInfo.ReflectToMap()
+
+	volumeId, _ := parameters["volumeId"].(string)
+	if parameters["protocol"] == "iscsi" {
+		connectionParams[connector.LastSuccessfulPortal] = utils.ReadPortalFile(ctx, volumeId)
+	}
+
+	log.AddContext(ctx).Infof("Connect volume by the target ports %v of host group %s",
+		publishInfo.targetPorts(), publishInfo.HostGroupName)
 	devPath, err := conn.ConnectVolume(ctx, connectionParams)
 	if err != nil {
 		return err
 	}
 
+	if portal, ok := connectionParams[connector.LastSuccessfulPortal].(string); ok && portal != "" {
+		if err = utils.WritePortalFile(ctx, portal, volumeId); err != nil {
+			log.AddContext(ctx).Warningf("write portal file failed, portal: %s, volumeId: %s, error: %v",
+				portal, volumeId, err)
+		}
+	}
+
 	parameters["devPath"] = devPath
 	return nil
 }
 
+// verifyDeviceWwn refuses to stage the connected device whose WWN differs from the WWN of the volume,
+// e.g. a stale multipath device which points to another LUN after the udev churn.
+// The volume is connected again with a rescan before the refusal.
+func verifyDeviceWwn(ctx context.Context, parameters map[string]interface{}) error {
+	wwn, err := ExtractWwn(parameters)
+	if err != nil {
+		log.AddContext(ctx).Errorf("extract wwn failed while verify device wwn, error: %v", err)
+		return err
+	}
+
+	if wwn == "" {
+		log.AddContext(ctx).Infoln("the wwn of the volume is not published, skip verifying the device wwn")
+		return nil
+	}
+
+	devPath, _ := parameters["devPath"].(string)
+	deviceWwn, err := connector.GetWwnByDevice(ctx, devPath)
+	if err == nil && isDeviceWwnMatched(deviceWwn, wwn) {
+		return nil
+	}
+
+	log.AddContext(ctx).Warningf("the wwn %s of device %s doesn't match the wwn %s of the volume, error: %v, "+
+		"rescan and connect the volume again", deviceWwn, devPath, wwn, err)
+	if err = connectVolume(ctx, parameters); err != nil {
+		return err
+	}
+
+	devPath, _ = parameters["devPath"].(string)
+	deviceWwn, err = connector.GetWwnByDevice(ctx, devPath)
+	if err != nil {
+		return utils.Errorf(ctx, "refuse to stage the volume, the wwn of device %s can not be verified, "+
+			"error: %v", devPath, err)
+	}
+
+	if !isDeviceWwnMatched(deviceWwn, wwn) {
+		msg := fmt.Sprintf("REFUSE TO STAGE THE VOLUME: device %s has the wwn %s, but the wwn of the volume is %s, "+
+			"the device may be a stale path of another LUN", devPath, deviceWwn, wwn)
+		log.AddContext(ctx).Errorln(msg)
+		return errors.New(msg)
+	}
+
+	return nil
+}
+
+// isDeviceWwnMatched checks the wwn of the device, which may be prefixed with its type, e.g. "3" or "naa."
+func isDeviceWwnMatched(deviceWwn, wwn string) bool {
+	return deviceWwn != "" && strings.HasSuffix(strings.ToLower(deviceWwn), strings.ToLower(wwn))
+}
+
 // stageForMount when AccessType is csi.VolumeCapability_Mount, this function will be called to mount share path
 func stageForMount(ctx context.Context, parameters map[string]interface{}) error {
 	log.AddContext(ctx).Infoln("the request to stage filesystem device")
@@ -82,6 +82,7 @@ func TestSanManagerStageFileSystemVolume(t *testing.T) {
 			patches := gomonkey.NewPatches()
 			mockClearResidualPath(patches, tt.manager.protocol)
 			tt.connectVolumeFunc(patches, tt.manager.Conn)
+			mockGetWwnByDevice(patches, tt.manager.protocol)
 			mockMountShare(patches)
 			mockChmodFsPermission(patches, t)
 			request := mockSanStageVolumeRequest(t, "filesystem")
@@ -107,6 +108,7 @@ func TestSanManagerStageBlockVolume(t *testing.T) {
 	patches := gomonkey.NewPatches()
 	mockClearResidualPath(patches, manager.protocol)
 	mockConnectIscsiVolume(patches, manager.Conn)
+	mockGetWwnByDevice(patches, manager.protocol)
 	mockCreateSymlink(patches)
 	request := mockSanStageVolumeRequest(t, "Block")
 
@@ -139,6 +141,64 @@ func mockClearResidualPath(patch *gomonkey.Patches, protocol string) {
 	})
 }
 
+func TestVerifyDeviceWwn(t *testing.T) {
+	conn := connector.GetConnector(context.Background(), connector.ISCSIDriver)
+	tests := []struct {
+		name        string
+		tgtLunWWN   string
+		deviceWwns  []string
+		wantConnect bool
+		wantErr     bool
+	}{
+		{"Matched", "mock_tgt_lun_wwn_1", []string{"3mock_tgt_lun_wwn_1"}, false, false},
+		{"MatchedAfterRescan", "mock_tgt_lun_wwn_1", []string{"3mock_stale_lun_wwn", "3mock_tgt_lun_wwn_1"},
+			true, false},
+		{"Mismatched", "mock_tgt_lun_wwn_1", []string{"3mock_stale_lun_wwn", "3mock_stale_lun_wwn"}, true, true},
+		{"WwnNotPublished", "", nil, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			patches := gomonkey.NewPatches()
+			defer patches.Reset()
+			if len(tt.deviceWwns) != 0 {
+				var outputs []gomonkey.OutputCell
+				for _, wwn := range tt.deviceWwns {
+					outputs = append(outputs, gomonkey.OutputCell{Values: gomonkey.Params{wwn, nil}})
+				}
+				patches.ApplyFuncSeq(connector.GetWwnByDevice, outputs)
+			}
+
+			connected := false
+			patches.ApplyMethod(reflect.TypeOf(conn), "ConnectVolume",
+				func(_ *iscsi.ISCSI, ctx context.Context, params map[string]interface{}) (string, error) {
+					connected = true
+					return "test_dev_path", nil
+				})
+
+			err := verifyDeviceWwn(context.Background(), map[string]interface{}{
+				"protocol":    "iscsi",
+				"connector":   conn,
+				"devPath":     "test_dev_path",
+				"publishInfo": &ControllerPublishInfo{TgtLunWWN: tt.tgtLunWWN},
+			})
+			if (err != nil) != tt.wantErr || connected != tt.wantConnect {
+				t.Errorf("verifyDeviceWwn() error = %v, connected = %v, wantErr %v, wantConnect %v",
+					err, connected, tt.wantErr, tt.wantConnect)
+			}
+		})
+	}
+}
+
+func mockGetWwnByDevice(patch *gomonkey.Patches, protocol string) {
+	patch.ApplyFunc(connector.GetWwnByDevice, func(ctx context.Context, devicePath string) (string, error) {
+		if protocol == "roce" || protocol == "fc-nvme" {
+			return "mock_lun_guid_1", nil
+		}
+		return "3mock_tgt_lun_wwn_1", nil
+	})
+}
+
 func mockConnectIscsiVolume(patch *gomonkey.Patches, conn connector.Connector) {
 	patch.ApplyMethod(reflect.TypeOf(conn), "ConnectVolume",
 		func(_ *iscsi.ISCSI, ctx context.Context, params map[string]interface{}) (string, error) {
@@ -49,6 +49,10 @@ type ControllerPublishInfo struct {
 	PortWWNList        []nvme.PortWWNPair `json:"portWWNList"`
 	VolumeUseMultiPath bool               `json:"volumeUseMultiPath"`
 	MultiPathType      string             `json:"multiPathType"`
+	HostGroupName      string             `json:"hostGroupName"`
+	HostName           string             `json:"hostName"`
+	HostID             string             `json:"hostID"`
+	RemoteHostID       string             `json:"remoteHostID"`
 }
 
 // BackendConfig backend configuration
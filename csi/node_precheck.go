@@ -0,0 +1,282 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"huawei-csi-driver/connector/precheck"
+	clientSet "huawei-csi-driver/pkg/client/clientset/versioned"
+	"huawei-csi-driver/pkg/constants"
+)
+
+const (
+	// nodePrecheckCommand checks whether the host is ready for the protocols of the backends instead of serving,
+	// which is run by a Job or a one-shot DaemonSet with the root of the host mounted
+	nodePrecheckCommand = "node-precheck"
+	// nodePrecheckAnnotation is the annotation of the node which the report of the precheck is recorded in
+	nodePrecheckAnnotation = "csi.huawei.com/node-precheck"
+	nodePrecheckTimeout    = 30 * time.Second
+	// hostInfoSecretName is the secret which the node plugins record the initiators of the hosts in
+	hostInfoSecretName = "huawei-csi-host-info"
+)
+
+type nodePrecheckOptions struct {
+	protocols    string
+	skip         string
+	hostRoot     string
+	nodeName     string
+	annotateNode bool
+	namespace    string
+	kubeConfig   string
+}
+
+// runNodePrecheck runs the checks of the host and prints the report in json, returns the exit code which is
+// non-zero if any check fails
+func runNodePrecheck(args []string) int {
+	opt := &nodePrecheckOptions{}
+	fs := flag.NewFlagSet(nodePrecheckCommand, flag.ExitOnError)
+	fs.StringVar(&opt.protocols, "protocols", "",
+		"The protocols to check separated by commas, such as iscsi,fc, the protocols of the backends "+
+			"of the cluster are checked if it is not specified")
+	fs.StringVar(&opt.skip, "skip", "",
+		"The checks to skip separated by commas, one of iscsi-initiator, multipath-blacklist, kernel-modules, "+
+			"udev-rules and sysctl")
+	fs.StringVar(&opt.hostRoot, "host-root", "/", "The directory which the root of the host is mounted on")
+	fs.StringVar(&opt.nodeName, "node-name", os.Getenv("NODE_NAME"),
+		"The name of the node, which is also the host name recorded with the initiator of the host")
+	fs.BoolVar(&opt.annotateNode, "annotate-node", false,
+		"Record the report in the "+nodePrecheckAnnotation+" annotation of the node")
+	fs.StringVar(&opt.namespace, "namespace", getEnvOrDefault(constants.NamespaceEnv, constants.DefaultNamespace),
+		"The namespace of the driver, which the initiators of the hosts are recorded in")
+	fs.StringVar(&opt.kubeConfig, "kubeconfig", "", "The kube config file, the in-cluster config is used if empty")
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+
+	if err := validateNodePrecheckOptions(opt); err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid options: %v\n", err)
+		return 1
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), nodePrecheckTimeout)
+	defer cancel()
+
+	k8sClient, backendClient, err := newNodePrecheckClients(opt.kubeConfig)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Connect to the cluster failed, the checks requiring it are limited: %v\n", err)
+	}
+
+	protocols := splitList(opt.protocols)
+	if len(protocols) == 0 {
+		if protocols, err = getBackendProtocols(ctx, k8sClient, backendClient); err != nil {
+			fmt.Fprintf(os.Stderr, "Get the protocols of the backends failed, specify them by --protocols: %v\n",
+				err)
+			return 1
+		}
+	}
+
+	report := precheck.Run(&precheck.Options{
+		HostRoot:   opt.hostRoot,
+		Protocols:  protocols,
+		Skip:       splitList(opt.skip),
+		Initiators: getHostInitiators(ctx, k8sClient, opt.namespace),
+		HostName:   opt.nodeName,
+	})
+
+	output, err := json.Marshal(report)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Marshal the report failed: %v\n", err)
+		return 1
+	}
+	fmt.Println(string(output))
+
+	if opt.annotateNode {
+		if err = annotateNodePrecheck(ctx, k8sClient, opt.nodeName, output); err != nil {
+			fmt.Fprintf(os.Stderr, "Annotate node %s failed: %v\n", opt.nodeName, err)
+			return 1
+		}
+	}
+
+	if !report.Passed {
+		return 1
+	}
+	return 0
+}
+
+func validateNodePrecheckOptions(opt *nodePrecheckOptions) error {
+	for _, name := range splitList(opt.skip) {
+		if !precheck.IsValidCheck(name) {
+			return fmt.Errorf("unknown check %s to skip", name)
+		}
+	}
+
+	if opt.annotateNode && opt.nodeName == "" {
+		return fmt.Errorf("--node-name is required by --annotate-node")
+	}
+	return nil
+}
+
+func newNodePrecheckClients(kubeConfig string) (kubernetes.Interface, clientSet.Interface, error) {
+	var config *rest.Config
+	var err error
+	if kubeConfig != "" {
+		config, err = clientcmd.BuildConfigFromFlags("", kubeConfig)
+	} else {
+		config, err = rest.InClusterConfig()
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	k8sClient, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	backendClient, err := clientSet.NewForConfig(config)
+	if err != nil {
+		return nil, nil, err
+	}
+	return k8sClient, backendClient, nil
+}
+
+// getBackendProtocols gets the protocols of the backends by the configmaps of the StorageBackendContents
+func getBackendProtocols(ctx context.Context, k8sClient kubernetes.Interface,
+	backendClient clientSet.Interface) ([]string, error) {
+	if k8sClient == nil || backendClient == nil {
+		return nil, fmt.Errorf("not connected to the cluster")
+	}
+
+	contents, err := backendClient.XuanwuV1().StorageBackendContents().List(ctx, metaV1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	var protocols []string
+	for _, content := range contents.Items {
+		namespace, name, found := strings.Cut(content.Spec.ConfigmapMeta, "/")
+		if !found {
+			continue
+		}
+
+		configmap, err := k8sClient.CoreV1().ConfigMaps(namespace).Get(ctx, name, metaV1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+
+		var csiConfig struct {
+			Backends struct {
+				Parameters struct {
+					Protocol string `json:"protocol"`
+				} `json:"parameters"`
+			} `json:"backends"`
+		}
+		if err = json.Unmarshal([]byte(configmap.Data["csi.json"]), &csiConfig); err != nil {
+			return nil, fmt.Errorf("parse configmap %s failed: %v", content.Spec.ConfigmapMeta, err)
+		}
+
+		protocol := csiConfig.Backends.Parameters.Protocol
+		if protocol != "" && !containsString(protocols, protocol) {
+			protocols = append(protocols, protocol)
+		}
+	}
+
+	return protocols, nil
+}
+
+// getHostInitiators gets the iscsi initiators recorded by the node plugins, the uniqueness of the initiator is
+// not checked if they are not available
+func getHostInitiators(ctx context.Context, k8sClient kubernetes.Interface, namespace string) map[string]string {
+	if k8sClient == nil {
+		return nil
+	}
+
+	secret, err := k8sClient.CoreV1().Secrets(namespace).Get(ctx, hostInfoSecretName, metaV1.GetOptions{})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Get the initiators of the hosts failed, skip checking the uniqueness: %v\n", err)
+		return nil
+	}
+
+	initiators := make(map[string]string)
+	for hostName, data := range secret.Data {
+		var hostInfo struct {
+			IscsiInitiator string `json:"iscsiInitiator"`
+		}
+		if err = json.Unmarshal(data, &hostInfo); err == nil && hostInfo.IscsiInitiator != "" {
+			initiators[hostName] = hostInfo.IscsiInitiator
+		}
+	}
+	return initiators
+}
+
+func annotateNodePrecheck(ctx context.Context, k8sClient kubernetes.Interface, nodeName string,
+	report []byte) error {
+	if k8sClient == nil {
+		return fmt.Errorf("not connected to the cluster")
+	}
+
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]string{nodePrecheckAnnotation: string(report)},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = k8sClient.CoreV1().Nodes().Patch(ctx, nodeName, types.MergePatchType, patch, metaV1.PatchOptions{})
+	return err
+}
+
+func splitList(value string) []string {
+	var values []string
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func getEnvOrDefault(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
@@ -0,0 +1,68 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+const (
+	// pluginInfoCommand prints the plugin info of the driver running in the same container instead of serving,
+	// which is executed in the container by oceanctl version
+	pluginInfoCommand = "plugin-info"
+	// pluginInfoEndpointEnv is the env of the CSI socket of the driver container
+	pluginInfoEndpointEnv = "CSI_ENDPOINT"
+	pluginInfoTimeout     = 10 * time.Second
+)
+
+// printPluginInfo queries the plugin info from the CSI socket and prints it in json
+func printPluginInfo() error {
+	endpoint := os.Getenv(pluginInfoEndpointEnv)
+	if endpoint == "" {
+		return fmt.Errorf("env %s of the CSI socket is not set", pluginInfoEndpointEnv)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), pluginInfoTimeout)
+	defer cancel()
+	conn, err := grpc.DialContext(ctx, "unix:"+endpoint,
+		grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithBlock())
+	if err != nil {
+		return fmt.Errorf("connect to %s failed, error: %v", endpoint, err)
+	}
+	defer conn.Close()
+
+	info, err := csi.NewIdentityClient(conn).GetPluginInfo(ctx, &csi.GetPluginInfoRequest{})
+	if err != nil {
+		return fmt.Errorf("get plugin info from %s failed, error: %v", endpoint, err)
+	}
+
+	output, err := json.Marshal(info)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(string(output))
+	return nil
+}
@@ -105,6 +105,7 @@ func (p *Provider) GetBackendStats(ctx context.Context, req *drcsi.GetBackendSta
 
 	log.AddContext(ctx).Debugf("Start to get storage backend %s status.", req.BackendId)
 	defer log.AddContext(ctx).Debugf("Finish to get storage backend %s status.", req.BackendId)
+	BackendStatsHeartbeat.Beat()
 
 	// If the sbct is offline, the status information is not obtained.
 	if !pkgUtils.IsSBCTOnline(ctx, req.BackendId) {
@@ -17,7 +17,13 @@
 // Package provider is related with storage provider
 package provider
 
-import "huawei-csi-driver/csi/backend/handler"
+import (
+	"huawei-csi-driver/csi/backend/handler"
+	"huawei-csi-driver/utils/health"
+)
+
+// BackendStatsHeartbeat beats whenever the sidecar requests the backend stats, which refreshes the backend cache
+var BackendStatsHeartbeat = health.NewHeartbeat()
 
 // Provider is for storage provider
 type Provider struct {
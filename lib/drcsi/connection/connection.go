@@ -17,22 +17,34 @@ package connection
 
 import (
 	"context"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/kubernetes-csi/csi-lib-utils/connection"
 	"github.com/kubernetes-csi/csi-lib-utils/metrics"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/backoff"
+	"google.golang.org/grpc/credentials/insecure"
 )
 
+const unixPrefix = "unix://"
+
 // Connect opens insecure gRPC connection to a CSI driver. Address must be either absolute path to UNIX domain socket
 // file or have format '<protocol>://', following gRPC name resolution mechanism at
 // https://github.com/grpc/grpc/blob/master/doc/naming.md.
-func Connect(ctx context.Context, drCSIAddress string, metricsManager metrics.CSIMetricsManager) (conn *grpc.ClientConn, err error) {
+// The dialOptions, such as the keep-alive parameters, are appended to the default dial options.
+func Connect(ctx context.Context, drCSIAddress string, metricsManager metrics.CSIMetricsManager,
+	dialOptions ...grpc.DialOption) (conn *grpc.ClientConn, err error) {
 	var m sync.Mutex
 	var canceled bool
 	ready := make(chan bool)
 	go func() {
-		conn, err = connection.Connect(drCSIAddress, metricsManager)
+		if len(dialOptions) == 0 {
+			conn, err = connection.Connect(drCSIAddress, metricsManager)
+		} else {
+			conn, err = dial(drCSIAddress, metricsManager, dialOptions)
+		}
 
 		m.Lock()
 		defer m.Unlock()
@@ -54,3 +66,26 @@ func Connect(ctx context.Context, drCSIAddress string, metricsManager metrics.CS
 		return conn, err
 	}
 }
+
+// dial blocks until the connection succeeds, with the same default dial options as connection.Connect
+func dial(address string, metricsManager metrics.CSIMetricsManager,
+	dialOptions []grpc.DialOption) (*grpc.ClientConn, error) {
+	backoffConfig := backoff.DefaultConfig
+	backoffConfig.MaxDelay = time.Second
+	options := []grpc.DialOption{
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithConnectParams(grpc.ConnectParams{Backoff: backoffConfig}),
+		grpc.WithBlock(),
+		grpc.WithChainUnaryInterceptor(
+			connection.LogGRPC,
+			connection.ExtendedCSIMetricsManager{CSIMetricsManager: metricsManager}.RecordMetricsClientInterceptor,
+		),
+	}
+	options = append(options, dialOptions...)
+
+	if strings.HasPrefix(address, "/") {
+		address = unixPrefix + address
+	}
+
+	return grpc.Dial(address, options...)
+}
@@ -38,6 +38,10 @@ func (c *FakeXuanwuV1) StorageBackendContents() v1.StorageBackendContentInterfac
 	return &FakeStorageBackendContents{c}
 }
 
+func (c *FakeXuanwuV1) VolumeMigrations() v1.VolumeMigrationInterface {
+	return &FakeVolumeMigrations{c}
+}
+
 // RESTClient returns a RESTClient that is used to communicate
 // with API server by this client implementation.
 func (c *FakeXuanwuV1) RESTClient() rest.Interface {
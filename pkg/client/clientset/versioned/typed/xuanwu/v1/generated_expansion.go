@@ -20,3 +20,5 @@ type ResourceTopologyExpansion interface{}
 type StorageBackendClaimExpansion interface{}
 
 type StorageBackendContentExpansion interface{}
+
+type VolumeMigrationExpansion interface{}
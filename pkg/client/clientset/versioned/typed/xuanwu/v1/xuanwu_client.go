@@ -28,6 +28,7 @@ type XuanwuV1Interface interface {
 	ResourceTopologiesGetter
 	StorageBackendClaimsGetter
 	StorageBackendContentsGetter
+	VolumeMigrationsGetter
 }
 
 // XuanwuV1Client is used to interact with features provided by the xuanwu.huawei.io group.
@@ -47,6 +48,10 @@ func (c *XuanwuV1Client) StorageBackendContents() StorageBackendContentInterface
 	return newStorageBackendContents(c)
 }
 
+func (c *XuanwuV1Client) VolumeMigrations() VolumeMigrationInterface {
+	return newVolumeMigrations(c)
+}
+
 // NewForConfig creates a new XuanwuV1Client for the given config.
 // NewForConfig is equivalent to NewForConfigAndClient(c, httpClient),
 // where httpClient was generated with rest.HTTPClientFor(c).
@@ -56,6 +56,8 @@ func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Xuanwu().V1().StorageBackendClaims().Informer()}, nil
 	case v1.SchemeGroupVersion.WithResource("storagebackendcontents"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Xuanwu().V1().StorageBackendContents().Informer()}, nil
+	case v1.SchemeGroupVersion.WithResource("volumemigrations"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Xuanwu().V1().VolumeMigrations().Informer()}, nil
 
 	}
 
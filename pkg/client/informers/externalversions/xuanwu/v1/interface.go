@@ -27,6 +27,8 @@ type Interface interface {
 	StorageBackendClaims() StorageBackendClaimInformer
 	// StorageBackendContents returns a StorageBackendContentInformer.
 	StorageBackendContents() StorageBackendContentInformer
+	// VolumeMigrations returns a VolumeMigrationInformer.
+	VolumeMigrations() VolumeMigrationInformer
 }
 
 type version struct {
@@ -54,3 +56,8 @@ func (v *version) StorageBackendClaims() StorageBackendClaimInformer {
 func (v *version) StorageBackendContents() StorageBackendContentInformer {
 	return &storageBackendContentInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
 }
+
+// VolumeMigrations returns a VolumeMigrationInformer.
+func (v *version) VolumeMigrations() VolumeMigrationInformer {
+	return &volumeMigrationInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
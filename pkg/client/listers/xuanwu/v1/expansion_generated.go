@@ -30,3 +30,7 @@ type StorageBackendClaimNamespaceListerExpansion interface{}
 // StorageBackendContentListerExpansion allows custom methods to be added to
 // StorageBackendContentLister.
 type StorageBackendContentListerExpansion interface{}
+
+// VolumeMigrationListerExpansion allows custom methods to be added to
+// VolumeMigrationLister.
+type VolumeMigrationListerExpansion interface{}
@@ -75,11 +75,64 @@ const (
 
 	// DefaultKubeletVolumeDevicesDirName default kubelet volumeDevice name
 	DefaultKubeletVolumeDevicesDirName = "/volumeDevices/"
+
+	// NfsAllowedClientsAnnotation is the PVC annotation to restrict the clients of the nfs share
+	NfsAllowedClientsAnnotation = "huawei-csi/nfs-allowed-clients"
+
+	// NamespaceDefaultParametersLabel is the configmap label of the default StorageClass parameters of the namespace
+	NamespaceDefaultParametersLabel = "huawei-csi/namespace-default-parameters"
+
+	// SnapshotTTLAnnotation is the VolumeSnapshot annotation of the time to live, e.g. 48h
+	SnapshotTTLAnnotation = "huawei-csi/snapshot-ttl"
+	// SnapshotAutoDeleteAfterDaysParameter is the VolumeSnapshotClass parameter of the number of days after which
+	// the snapshots of the class are deleted, the SnapshotTTLAnnotation of a VolumeSnapshot wins over it
+	SnapshotAutoDeleteAfterDaysParameter = "autoDeleteAfterDays"
+
+	// DTreeTargetParentAnnotation is the PV annotation of the filesystem which the DTree volume is moved to,
+	// the volume is moved once it is not published to any node
+	DTreeTargetParentAnnotation = "huawei-csi/target-parent"
+	// DTreeParentNameAnnotation is the PV annotation of the filesystem which the DTree volume is moved to,
+	// it overrides the dTreeParentName volume attribute which is immutable
+	DTreeParentNameAnnotation = "huawei-csi/dtree-parent-name"
+	// WriteProtectAnnotation is the PV annotation to request the removal of the write protection of the volume,
+	// only the cluster admins are allowed to set it to WriteProtectRemove
+	WriteProtectAnnotation = "huawei-csi/write-protect"
+	// WriteProtectRemove requests the removal of the write protection
+	WriteProtectRemove = "remove"
+	// WriteProtectRemoved records that the write protection is removed
+	WriteProtectRemoved = "removed"
+	// NFSShareAnnotation is the PV annotation recording that the nfs share of the retained volume is deleted
+	NFSShareAnnotation = "csi.huawei.com/nfs-share"
+	// NFSShareDeleted records that the nfs share is deleted
+	NFSShareDeleted = "deleted"
+	// VolumeMigrationFenceAnnotation is the PV annotation fencing the source volume of the VolumeMigration named by
+	// the value, the volume is not allowed to be published while the persistent volume is being replaced
+	VolumeMigrationFenceAnnotation = "csi.huawei.com/volume-migration"
+	// VolumeMigrationFinalizer is the VolumeMigration finalizer which rolls back the migration before it is deleted
+	VolumeMigrationFinalizer = "xuanwu.huawei.io/volume-migration"
+
+	// CapabilityDisabledFormat is the message format of using a capability disabled by the deployment flags
+	CapabilityDisabledFormat = "%s is disabled by the policy of this deployment"
+
+	// PoolTieBreakerLeastRecentlyUsed selects the least recently selected pool of the pools with equal free capacity
+	PoolTieBreakerLeastRecentlyUsed = "lru"
+	// PoolTieBreakerLexical selects the first pool by backend and pool name of the pools with equal free capacity
+	PoolTieBreakerLexical = "lexical"
+
+	// MaintenancePolicyIgnore keeps running the operations when the storage is in maintenance
+	MaintenancePolicyIgnore = "ignore"
+	// MaintenancePolicyPause rejects the operations modifying the volumes when the storage is in maintenance
+	MaintenancePolicyPause = "pause"
 )
 
 var (
 	// ErrTimeout defines the timeout error
 	ErrTimeout = errors.New("timeout")
+	// ErrVolumeNotFound means the storage is reachable but the object of the volume does not exist on it
+	ErrVolumeNotFound = errors.New("volume not found")
+	// ErrReplicationPairUnhealthy means the replication pair of the volume is in a state which may make the data
+	// of the volume inconsistent after failover
+	ErrReplicationPairUnhealthy = errors.New("replication pair is unhealthy")
 )
 
 // DRCSIConfig contains storage normal configuration
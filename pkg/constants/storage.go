@@ -66,3 +66,9 @@ var SupportMetroNAS BackendCapability = "SupportMetroNAS"
 
 // SupportLabel defines backend capability SupportLabel
 var SupportLabel BackendCapability = "SupportLabel"
+
+// SupportEncryption defines backend capability SupportEncryption
+var SupportEncryption BackendCapability = "SupportEncryption"
+
+// SupportPerFsDedup defines backend capability SupportPerFsDedup
+var SupportPerFsDedup BackendCapability = "SupportPerFsDedup"
@@ -21,6 +21,8 @@ import (
 	"reflect"
 
 	coreV1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	xuanwuv1 "huawei-csi-driver/client/apis/xuanwu/v1"
 	"huawei-csi-driver/lib/drcsi"
@@ -28,6 +30,11 @@ import (
 	"huawei-csi-driver/utils/log"
 )
 
+const (
+	enoughPoolsHealthyReason = "EnoughPoolsHealthy"
+	poolsUnhealthyReason     = "PoolsUnhealthy"
+)
+
 func (ctrl *backendController) initContentStatus(ctx context.Context, content *xuanwuv1.StorageBackendContent) (
 	*xuanwuv1.StorageBackendContent, error) {
 
@@ -165,8 +172,9 @@ func (ctrl *backendController) shouldUpdateContentStatus(ctx context.Context, co
 		content.Status.Capabilities = status.Capabilities
 	}
 
-	if !reflect.DeepEqual(content.Status.Specification, status.Specifications) {
-		content.Status.Specification = status.Specifications
+	specifications := setPoolHealthStatus(content.Status, status.Specifications, content.Generation)
+	if !reflect.DeepEqual(content.Status.Specification, specifications) {
+		content.Status.Specification = specifications
 	}
 
 	if !reflect.DeepEqual(content.Status.Pools, status.Pools) {
@@ -266,3 +274,37 @@ func (ctrl *backendController) updateContentWrapper(ctx context.Context,
 	log.AddContext(ctx).Infof("Update storage backend with content %s successful", content.Name)
 	return nil
 }
+
+// setPoolHealthStatus records the pool health which the provider reports in the specifications of the stats as
+// the ready pools and the Ready condition of the content, and returns the other specifications
+func setPoolHealthStatus(status *xuanwuv1.StorageBackendContentStatus, specifications map[string]string,
+	generation int64) map[string]string {
+	readyPools, exist := specifications[xuanwuv1.ReadyPoolsSpecification]
+	if !exist {
+		return specifications
+	}
+
+	others := make(map[string]string, len(specifications))
+	for key, value := range specifications {
+		others[key] = value
+	}
+	delete(others, xuanwuv1.ReadyPoolsSpecification)
+	delete(others, xuanwuv1.BackendReadySpecification)
+	delete(others, xuanwuv1.BackendReadyMessageSpecification)
+
+	condition := metaV1.Condition{
+		Type:               xuanwuv1.BackendReady,
+		Status:             metaV1.ConditionStatus(specifications[xuanwuv1.BackendReadySpecification]),
+		Reason:             enoughPoolsHealthyReason,
+		Message:            specifications[xuanwuv1.BackendReadyMessageSpecification],
+		ObservedGeneration: generation,
+	}
+	if condition.Status != metaV1.ConditionTrue {
+		condition.Status = metaV1.ConditionFalse
+		condition.Reason = poolsUnhealthyReason
+	}
+
+	status.ReadyPools = readyPools
+	meta.SetStatusCondition(&status.Conditions, condition)
+	return others
+}
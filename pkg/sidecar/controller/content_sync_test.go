@@ -0,0 +1,74 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package controller
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+
+	xuanwuv1 "huawei-csi-driver/client/apis/xuanwu/v1"
+)
+
+func TestSetPoolHealthStatus(t *testing.T) {
+	cases := []struct {
+		name           string
+		specifications map[string]string
+		wantReadyPools string
+		wantReady      bool
+		wantReason     string
+	}{
+		{"enough pools healthy", map[string]string{"LocalDeviceSN": "sn",
+			xuanwuv1.ReadyPoolsSpecification: "2/4", xuanwuv1.BackendReadySpecification: "True",
+			xuanwuv1.BackendReadyMessageSpecification: "2/4 pools are healthy"},
+			"2/4", true, enoughPoolsHealthyReason},
+		{"too few pools healthy", map[string]string{"LocalDeviceSN": "sn",
+			xuanwuv1.ReadyPoolsSpecification: "1/4", xuanwuv1.BackendReadySpecification: "False",
+			xuanwuv1.BackendReadyMessageSpecification: "only 1/4 pools are healthy"},
+			"1/4", false, poolsUnhealthyReason},
+	}
+
+	for _, c := range cases {
+		status := &xuanwuv1.StorageBackendContentStatus{}
+		others := setPoolHealthStatus(status, c.specifications, 1)
+
+		if !reflect.DeepEqual(others, map[string]string{"LocalDeviceSN": "sn"}) {
+			t.Errorf("%s: setPoolHealthStatus() = %v, want the specifications without the pool health",
+				c.name, others)
+		}
+		condition := meta.FindStatusCondition(status.Conditions, xuanwuv1.BackendReady)
+		if status.ReadyPools != c.wantReadyPools || condition == nil ||
+			meta.IsStatusConditionTrue(status.Conditions, xuanwuv1.BackendReady) != c.wantReady ||
+			condition.Reason != c.wantReason {
+			t.Errorf("%s: ready pools %s, conditions %v, want %s, ready %t and reason %s",
+				c.name, status.ReadyPools, status.Conditions, c.wantReadyPools, c.wantReady, c.wantReason)
+		}
+	}
+}
+
+func TestSetPoolHealthStatusWithoutPoolHealth(t *testing.T) {
+	status := &xuanwuv1.StorageBackendContentStatus{ReadyPools: "2/2"}
+	specifications := map[string]string{"LocalDeviceSN": "sn"}
+
+	if got := setPoolHealthStatus(status, specifications, 1); !reflect.DeepEqual(got, specifications) {
+		t.Errorf("setPoolHealthStatus() = %v, want %v", got, specifications)
+	}
+	if status.ReadyPools != "2/2" || len(status.Conditions) != 0 {
+		t.Errorf("setPoolHealthStatus() changed the status to %+v without the pool health", status)
+	}
+}
@@ -0,0 +1,403 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package controller
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strconv"
+	"time"
+
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+
+	"huawei-csi-driver/pkg/constants"
+	"huawei-csi-driver/utils/log"
+)
+
+var (
+	snapshotTTLCheckInterval = flag.Duration(
+		"snapshot-ttl-check-interval",
+		5*time.Minute,
+		"The interval of checking whether the VolumeSnapshots exceed their TTL.")
+	snapshotDeleteInterval = flag.Duration(
+		"snapshot-delete-interval",
+		10*time.Second,
+		"The minimum interval between two expired snapshot deletions, to avoid overloading the storage.")
+
+	volumeSnapshotResource = schema.GroupVersionResource{
+		Group:    "snapshot.storage.k8s.io",
+		Version:  "v1",
+		Resource: "volumesnapshots",
+	}
+	volumeSnapshotContentResource = schema.GroupVersionResource{
+		Group:    "snapshot.storage.k8s.io",
+		Version:  "v1",
+		Resource: "volumesnapshotcontents",
+	}
+	volumeSnapshotClassResource = schema.GroupVersionResource{
+		Group:    "snapshot.storage.k8s.io",
+		Version:  "v1",
+		Resource: "volumesnapshotclasses",
+	}
+)
+
+// snapshotDeletionPolicyDelete is the deletionPolicy of the VolumeSnapshotContent whose storage snapshot is
+// deleted by the driver when the content is deleted
+const snapshotDeletionPolicyDelete = "Delete"
+
+type snapshotTTLController struct {
+	providerName string
+
+	dynamicClient   dynamic.Interface
+	discoveryClient discovery.DiscoveryInterface
+	timeout         time.Duration
+
+	snapshotQueue workqueue.RateLimitingInterface
+	deleteTicker  *time.Ticker
+}
+
+// SnapshotTTLControllerRequest is a request for new snapshot ttl controller
+type SnapshotTTLControllerRequest struct {
+	// provider name, only the snapshots of this provider will be deleted
+	ProviderName string
+	// dynamic client used to access VolumeSnapshot resources
+	DynamicClient dynamic.Interface
+	// discovery client used to check whether the VolumeSnapshot CRDs are installed
+	DiscoveryClient discovery.DiscoveryInterface
+	// request time out
+	TimeOut time.Duration
+}
+
+// NewSnapshotTTLController return a new *snapshotTTLController
+func NewSnapshotTTLController(request SnapshotTTLControllerRequest) *snapshotTTLController {
+	rateLimiter := workqueue.NewItemExponentialFailureRateLimiter(*retryIntervalStart, *retryIntervalMax)
+	return &snapshotTTLController{
+		providerName:    request.ProviderName,
+		dynamicClient:   request.DynamicClient,
+		discoveryClient: request.DiscoveryClient,
+		timeout:         request.TimeOut,
+		snapshotQueue:   workqueue.NewNamedRateLimitingQueue(rateLimiter, "sidecar-snapshot-ttl-controller"),
+		deleteTicker:    time.NewTicker(*snapshotDeleteInterval),
+	}
+}
+
+// Run defines the snapshot ttl controller process
+func (ctrl *snapshotTTLController) Run(ctx context.Context, workers int, stopCh <-chan struct{}) {
+	// the workers waiting for the delete ticker are released once the controller is stopped
+	workerCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	defer ctrl.snapshotQueue.ShutDown()
+	defer ctrl.deleteTicker.Stop()
+
+	log.AddContext(ctx).Infoln("Starting snapshot ttl controller")
+	defer log.AddContext(ctx).Infoln("Shutting down snapshot ttl controller")
+
+	go wait.Until(func() { ctrl.enqueueExpiredSnapshots(ctx) }, *snapshotTTLCheckInterval, stopCh)
+	for i := 0; i < workers; i++ {
+		go wait.UntilWithContext(workerCtx, ctrl.runSnapshotWorker, time.Second)
+	}
+
+	if stopCh != nil {
+		sign := <-stopCh
+		log.AddContext(ctx).Infof("Snapshot ttl controller exited, reason: %v", sign)
+	}
+}
+
+func (ctrl *snapshotTTLController) enqueueExpiredSnapshots(ctx context.Context) {
+	installed, err := ctrl.isSnapshotCRDInstalled()
+	if err != nil {
+		log.AddContext(ctx).Errorf("Check VolumeSnapshot CRDs failed, error: %v", err)
+		return
+	}
+	if !installed {
+		log.AddContext(ctx).Debugf("VolumeSnapshot CRDs of %s are not installed, skip checking the ttl",
+			volumeSnapshotResource.GroupVersion())
+		return
+	}
+
+	snapshots, err := ctrl.dynamicClient.Resource(volumeSnapshotResource).Namespace(metaV1.NamespaceAll).
+		List(ctx, metaV1.ListOptions{})
+	if err != nil {
+		log.AddContext(ctx).Errorf("List VolumeSnapshots failed, error: %v", err)
+		return
+	}
+
+	classTTLs := ctrl.getSnapshotClassTTLs(ctx)
+	now := time.Now()
+	for i := range snapshots.Items {
+		snapshot := &snapshots.Items[i]
+		expired, err := isSnapshotExpired(snapshot, now, classTTLs)
+		if err != nil {
+			log.AddContext(ctx).Warningf("Check ttl of VolumeSnapshot %s/%s failed, error: %v",
+				snapshot.GetNamespace(), snapshot.GetName(), err)
+			continue
+		}
+
+		if !expired {
+			continue
+		}
+
+		key, err := cache.MetaNamespaceKeyFunc(snapshot)
+		if err != nil {
+			log.AddContext(ctx).Errorf("failed to get key from object: %v, %v", snapshot, err)
+			continue
+		}
+
+		// the snapshot which is waiting for retry is added back to the queue by the back-off
+		if ctrl.snapshotQueue.NumRequeues(key) > 0 {
+			continue
+		}
+
+		log.AddContext(ctx).Debugf("enqueued expired VolumeSnapshot %q for deletion", key)
+		ctrl.snapshotQueue.Add(key)
+	}
+}
+
+// isSnapshotCRDInstalled checks whether the VolumeSnapshot and VolumeSnapshotContent CRDs are served,
+// the snapshot CRDs are optional in the cluster
+func (ctrl *snapshotTTLController) isSnapshotCRDInstalled() (bool, error) {
+	if ctrl.discoveryClient == nil {
+		return true, nil
+	}
+
+	resources, err := ctrl.discoveryClient.ServerResourcesForGroupVersion(
+		volumeSnapshotResource.GroupVersion().String())
+	if apiErrors.IsNotFound(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	served := map[string]bool{}
+	for _, resource := range resources.APIResources {
+		served[resource.Name] = true
+	}
+	return served[volumeSnapshotResource.Resource] && served[volumeSnapshotContentResource.Resource], nil
+}
+
+// getSnapshotClassTTLs returns the ttl of the snapshots of each VolumeSnapshotClass of the provider whose
+// autoDeleteAfterDays is set, the classes with an invalid value are skipped
+func (ctrl *snapshotTTLController) getSnapshotClassTTLs(ctx context.Context) map[string]time.Duration {
+	classes, err := ctrl.dynamicClient.Resource(volumeSnapshotClassResource).List(ctx, metaV1.ListOptions{})
+	if err != nil {
+		log.AddContext(ctx).Errorf("List VolumeSnapshotClasses failed, only the ttl annotations are checked, "+
+			"error: %v", err)
+		return nil
+	}
+
+	classTTLs := make(map[string]time.Duration)
+	for _, class := range classes.Items {
+		driver, _, _ := unstructured.NestedString(class.Object, "driver")
+		value, exist, _ := unstructured.NestedString(class.Object, "parameters",
+			constants.SnapshotAutoDeleteAfterDaysParameter)
+		if driver != ctrl.providerName || !exist {
+			continue
+		}
+
+		days, err := strconv.Atoi(value)
+		if err != nil || days <= 0 {
+			log.AddContext(ctx).Warningf("Invalid parameter %s: %s of VolumeSnapshotClass %s",
+				constants.SnapshotAutoDeleteAfterDaysParameter, value, class.GetName())
+			continue
+		}
+		classTTLs[class.GetName()] = time.Duration(days) * 24 * time.Hour
+	}
+
+	return classTTLs
+}
+
+// isSnapshotExpired checks the ttl annotation of the snapshot, or the ttl of its VolumeSnapshotClass if the
+// snapshot is not annotated
+func isSnapshotExpired(snapshot *unstructured.Unstructured, now time.Time,
+	classTTLs map[string]time.Duration) (bool, error) {
+	if snapshot.GetDeletionTimestamp() != nil {
+		return false, nil
+	}
+
+	ttl, exist := snapshot.GetAnnotations()[constants.SnapshotTTLAnnotation]
+	if !exist {
+		className, _, _ := unstructured.NestedString(snapshot.Object, "spec", "volumeSnapshotClassName")
+		duration, exist := classTTLs[className]
+		return exist && snapshot.GetCreationTimestamp().Add(duration).Before(now), nil
+	}
+
+	duration, err := time.ParseDuration(ttl)
+	if err != nil {
+		return false, fmt.Errorf("invalid annotation %s: %s, %v", constants.SnapshotTTLAnnotation, ttl, err)
+	}
+
+	return snapshot.GetCreationTimestamp().Add(duration).Before(now), nil
+}
+
+func (ctrl *snapshotTTLController) runSnapshotWorker(ctx context.Context) {
+	for ctrl.processNextSnapshotWorkItem(ctx) {
+	}
+}
+
+func (ctrl *snapshotTTLController) processNextSnapshotWorkItem(workerCtx context.Context) bool {
+	obj, shutdown := ctrl.snapshotQueue.Get()
+	if shutdown {
+		log.Infof("processNextSnapshotWorkItem obj: [%v], shutdown: [%v]", obj, shutdown)
+		return false
+	}
+	defer ctrl.snapshotQueue.Done(obj)
+
+	key, ok := obj.(string)
+	if !ok {
+		ctrl.snapshotQueue.Forget(obj)
+		log.Errorf("expected string in snapshot queue but got %#v", obj)
+		return true
+	}
+
+	// limit the deletion rate, so that the storage is not flooded by bulk deletions
+	select {
+	case <-ctrl.deleteTicker.C:
+	case <-workerCtx.Done():
+		return false
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), ctrl.timeout)
+	defer cancel()
+	if err := ctrl.deleteExpiredSnapshot(ctx, key); err != nil {
+		log.AddContext(ctx).Errorf("Delete expired VolumeSnapshot %s failed, requeue it, error: %v", key, err)
+		ctrl.snapshotQueue.AddRateLimited(key)
+		return true
+	}
+
+	ctrl.snapshotQueue.Forget(key)
+	return true
+}
+
+func (ctrl *snapshotTTLController) deleteExpiredSnapshot(ctx context.Context, key string) error {
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return err
+	}
+
+	snapshotClient := ctrl.dynamicClient.Resource(volumeSnapshotResource).Namespace(namespace)
+	snapshot, err := snapshotClient.Get(ctx, name, metaV1.GetOptions{})
+	if apiErrors.IsNotFound(err) {
+		log.AddContext(ctx).Infof("VolumeSnapshot %s is already deleted", key)
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	// the annotation may be changed after the snapshot is enqueued
+	var classTTLs map[string]time.Duration
+	if _, annotated := snapshot.GetAnnotations()[constants.SnapshotTTLAnnotation]; !annotated {
+		classTTLs = ctrl.getSnapshotClassTTLs(ctx)
+	}
+	if expired, err := isSnapshotExpired(snapshot, time.Now(), classTTLs); err != nil || !expired {
+		return nil
+	}
+
+	content, err := ctrl.getDeletableContent(ctx, snapshot)
+	if err != nil {
+		return err
+	}
+
+	if content == nil {
+		log.AddContext(ctx).Debugf("VolumeSnapshot %s is not ready or not created by %s, skip it",
+			key, ctrl.providerName)
+		return nil
+	}
+
+	// the snapshot-controller deletes the bound VolumeSnapshotContent and the storage snapshot by the driver
+	// only if the deletionPolicy of the content is Delete, otherwise the expired storage snapshot is retained
+	if err = ctrl.setContentDeletionPolicy(ctx, content); err != nil {
+		return err
+	}
+
+	err = snapshotClient.Delete(ctx, name, metaV1.DeleteOptions{})
+	if err != nil && !apiErrors.IsNotFound(err) {
+		return err
+	}
+
+	log.AddContext(ctx).Infof("Expired VolumeSnapshot %s is deleted", key)
+	return nil
+}
+
+// getDeletableContent returns the content bound to the snapshot if the snapshot is ready and the content
+// belongs to this provider, nil is returned for the snapshot which is still being created or belongs to other
+// drivers
+func (ctrl *snapshotTTLController) getDeletableContent(ctx context.Context,
+	snapshot *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	readyToUse, _, err := unstructured.NestedBool(snapshot.Object, "status", "readyToUse")
+	if err != nil || !readyToUse {
+		return nil, err
+	}
+
+	contentName, _, err := unstructured.NestedString(snapshot.Object, "status", "boundVolumeSnapshotContentName")
+	if err != nil || contentName == "" {
+		return nil, err
+	}
+
+	content, err := ctrl.dynamicClient.Resource(volumeSnapshotContentResource).Get(ctx, contentName,
+		metaV1.GetOptions{})
+	if apiErrors.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	driver, _, err := unstructured.NestedString(content.Object, "spec", "driver")
+	if err != nil || driver != ctrl.providerName {
+		return nil, err
+	}
+
+	snapshotHandle, _, err := unstructured.NestedString(content.Object, "status", "snapshotHandle")
+	if err != nil || snapshotHandle == "" {
+		return nil, err
+	}
+	return content, nil
+}
+
+// setContentDeletionPolicy sets the deletionPolicy of the content to Delete, so that the storage snapshot is
+// deleted by the driver together with the expired VolumeSnapshot
+func (ctrl *snapshotTTLController) setContentDeletionPolicy(ctx context.Context,
+	content *unstructured.Unstructured) error {
+	policy, _, err := unstructured.NestedString(content.Object, "spec", "deletionPolicy")
+	if err != nil {
+		return err
+	}
+	if policy == snapshotDeletionPolicyDelete {
+		return nil
+	}
+
+	patch := fmt.Sprintf(`{"spec":{"deletionPolicy":%q}}`, snapshotDeletionPolicyDelete)
+	_, err = ctrl.dynamicClient.Resource(volumeSnapshotContentResource).Patch(ctx, content.GetName(),
+		types.MergePatchType, []byte(patch), metaV1.PatchOptions{})
+	if err != nil {
+		return fmt.Errorf("set deletionPolicy of VolumeSnapshotContent %s to %s failed, error: %w",
+			content.GetName(), snapshotDeletionPolicyDelete, err)
+	}
+
+	log.AddContext(ctx).Infof("DeletionPolicy of VolumeSnapshotContent %s is changed from %s to %s for the "+
+		"expired VolumeSnapshot", content.GetName(), policy, snapshotDeletionPolicyDelete)
+	return nil
+}
@@ -0,0 +1,276 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package controller
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	discoveryFake "k8s.io/client-go/discovery/fake"
+	dynamicFake "k8s.io/client-go/dynamic/fake"
+	k8sTesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/util/workqueue"
+
+	"huawei-csi-driver/pkg/constants"
+	"huawei-csi-driver/utils/log"
+)
+
+const (
+	testProviderName = "csi.huawei.com"
+	testSnapshotName = "snapshot-1"
+	testContentName  = "snapcontent-1"
+	testNamespace    = "default"
+
+	logName = "snapshot_ttl_controller_test.log"
+)
+
+func TestMain(m *testing.M) {
+	log.MockInitLogging(logName)
+	defer log.MockStopLogging(logName)
+
+	m.Run()
+}
+
+func TestIsSnapshotExpired(t *testing.T) {
+	now := time.Now()
+	classTTLs := map[string]time.Duration{"daily": 24 * time.Hour}
+	tests := []struct {
+		name      string
+		ttl       string
+		className string
+		created   time.Time
+		deleting  bool
+		want      bool
+		wantError bool
+	}{
+		{"NoTTL", "", "", now.Add(-72 * time.Hour), false, false, false},
+		{"Expired", "48h", "", now.Add(-72 * time.Hour), false, true, false},
+		{"NotExpired", "48h", "", now.Add(-24 * time.Hour), false, false, false},
+		{"Deleting", "48h", "", now.Add(-72 * time.Hour), true, false, false},
+		{"InvalidTTL", "2days", "", now.Add(-72 * time.Hour), false, false, true},
+		{"ClassExpired", "", "daily", now.Add(-48 * time.Hour), false, true, false},
+		{"ClassNotExpired", "", "daily", now.Add(-12 * time.Hour), false, false, false},
+		{"AnnotationOverClass", "72h", "daily", now.Add(-48 * time.Hour), false, false, false},
+		{"ClassWithoutTTL", "", "weekly", now.Add(-48 * time.Hour), false, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			snapshot := &unstructured.Unstructured{Object: map[string]interface{}{
+				"spec": map[string]interface{}{"volumeSnapshotClassName": tt.className},
+			}}
+			snapshot.SetCreationTimestamp(metaV1.NewTime(tt.created))
+			if tt.ttl != "" {
+				snapshot.SetAnnotations(map[string]string{constants.SnapshotTTLAnnotation: tt.ttl})
+			}
+			if tt.deleting {
+				deletionTime := metaV1.NewTime(now)
+				snapshot.SetDeletionTimestamp(&deletionTime)
+			}
+
+			got, err := isSnapshotExpired(snapshot, now, classTTLs)
+			if (err != nil) != tt.wantError {
+				t.Errorf("isSnapshotExpired() error = %v, wantError %v", err, tt.wantError)
+			}
+			if got != tt.want {
+				t.Errorf("isSnapshotExpired() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func newTestVolumeSnapshotClass(name, driver string, parameters map[string]interface{}) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "snapshot.storage.k8s.io/v1",
+		"kind":       "VolumeSnapshotClass",
+		"metadata": map[string]interface{}{
+			"name": name,
+		},
+		"driver":         driver,
+		"deletionPolicy": "Delete",
+		"parameters":     parameters,
+	}}
+}
+
+func TestGetSnapshotClassTTLs(t *testing.T) {
+	dynamicClient := dynamicFake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{volumeSnapshotClassResource: "VolumeSnapshotClassList"},
+		newTestVolumeSnapshotClass("daily", testProviderName,
+			map[string]interface{}{constants.SnapshotAutoDeleteAfterDaysParameter: "1"}),
+		newTestVolumeSnapshotClass("invalid", testProviderName,
+			map[string]interface{}{constants.SnapshotAutoDeleteAfterDaysParameter: "0"}),
+		newTestVolumeSnapshotClass("no-ttl", testProviderName, map[string]interface{}{}),
+		newTestVolumeSnapshotClass("other-driver", "other.csi.driver",
+			map[string]interface{}{constants.SnapshotAutoDeleteAfterDaysParameter: "1"}))
+	ctrl := &snapshotTTLController{providerName: testProviderName, dynamicClient: dynamicClient}
+
+	got := ctrl.getSnapshotClassTTLs(context.Background())
+	want := map[string]time.Duration{"daily": 24 * time.Hour}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("getSnapshotClassTTLs() = %v, want %v", got, want)
+	}
+}
+
+func newTestVolumeSnapshot(readyToUse bool) *unstructured.Unstructured {
+	snapshot := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "snapshot.storage.k8s.io/v1",
+		"kind":       "VolumeSnapshot",
+		"metadata": map[string]interface{}{
+			"name":      testSnapshotName,
+			"namespace": testNamespace,
+		},
+		"status": map[string]interface{}{
+			"boundVolumeSnapshotContentName": testContentName,
+			"readyToUse":                     readyToUse,
+		},
+	}}
+	snapshot.SetCreationTimestamp(metaV1.NewTime(time.Now().Add(-72 * time.Hour)))
+	snapshot.SetAnnotations(map[string]string{constants.SnapshotTTLAnnotation: "48h"})
+	return snapshot
+}
+
+func newTestVolumeSnapshotContent(driver, deletionPolicy, snapshotHandle string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "snapshot.storage.k8s.io/v1",
+		"kind":       "VolumeSnapshotContent",
+		"metadata": map[string]interface{}{
+			"name": testContentName,
+		},
+		"spec": map[string]interface{}{
+			"driver":         driver,
+			"deletionPolicy": deletionPolicy,
+		},
+		"status": map[string]interface{}{
+			"snapshotHandle": snapshotHandle,
+		},
+	}}
+}
+
+func TestDeleteExpiredSnapshot(t *testing.T) {
+	tests := []struct {
+		name        string
+		snapshot    *unstructured.Unstructured
+		content     *unstructured.Unstructured
+		wantDeleted bool
+	}{
+		{"DeletePolicy", newTestVolumeSnapshot(true),
+			newTestVolumeSnapshotContent(testProviderName, "Delete", "snapshot-id"), true},
+		{"RetainPolicy", newTestVolumeSnapshot(true),
+			newTestVolumeSnapshotContent(testProviderName, "Retain", "snapshot-id"), true},
+		{"OtherDriver", newTestVolumeSnapshot(true),
+			newTestVolumeSnapshotContent("other.csi.driver", "Delete", "snapshot-id"), false},
+		{"NotReady", newTestVolumeSnapshot(false),
+			newTestVolumeSnapshotContent(testProviderName, "Delete", "snapshot-id"), false},
+		{"EmptySnapshotHandle", newTestVolumeSnapshot(true),
+			newTestVolumeSnapshotContent(testProviderName, "Delete", ""), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dynamicClient := dynamicFake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+				map[schema.GroupVersionResource]string{
+					volumeSnapshotResource:        "VolumeSnapshotList",
+					volumeSnapshotContentResource: "VolumeSnapshotContentList",
+				}, tt.snapshot, tt.content)
+			ctrl := &snapshotTTLController{providerName: testProviderName, dynamicClient: dynamicClient}
+
+			ctx := context.Background()
+			if err := ctrl.deleteExpiredSnapshot(ctx, testNamespace+"/"+testSnapshotName); err != nil {
+				t.Errorf("deleteExpiredSnapshot() error = %v", err)
+			}
+
+			_, err := dynamicClient.Resource(volumeSnapshotResource).Namespace(testNamespace).
+				Get(ctx, testSnapshotName, metaV1.GetOptions{})
+			if deleted := apiErrors.IsNotFound(err); deleted != tt.wantDeleted {
+				t.Errorf("VolumeSnapshot deleted = %v, want %v", deleted, tt.wantDeleted)
+			}
+
+			// the content is left to the snapshot-controller, which deletes the storage snapshot by the driver
+			// once the deletionPolicy is Delete
+			content, err := dynamicClient.Resource(volumeSnapshotContentResource).
+				Get(ctx, testContentName, metaV1.GetOptions{})
+			if err != nil {
+				t.Fatalf("VolumeSnapshotContent should not be deleted, error = %v", err)
+			}
+			policy, _, _ := unstructured.NestedString(content.Object, "spec", "deletionPolicy")
+			if tt.wantDeleted && policy != snapshotDeletionPolicyDelete {
+				t.Errorf("deletionPolicy of the deleted VolumeSnapshot = %s, want %s", policy,
+					snapshotDeletionPolicyDelete)
+			}
+		})
+	}
+}
+
+func TestIsSnapshotCRDInstalled(t *testing.T) {
+	tests := []struct {
+		name      string
+		resources []*metaV1.APIResourceList
+		want      bool
+	}{
+		{"Installed", []*metaV1.APIResourceList{{GroupVersion: "snapshot.storage.k8s.io/v1",
+			APIResources: []metaV1.APIResource{{Name: "volumesnapshots"}, {Name: "volumesnapshotcontents"}}}},
+			true},
+		{"ContentNotInstalled", []*metaV1.APIResourceList{{GroupVersion: "snapshot.storage.k8s.io/v1",
+			APIResources: []metaV1.APIResource{{Name: "volumesnapshots"}}}}, false},
+		{"NotInstalled", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := &snapshotTTLController{
+				discoveryClient: &discoveryFake.FakeDiscovery{Fake: &k8sTesting.Fake{Resources: tt.resources}},
+			}
+
+			got, err := ctrl.isSnapshotCRDInstalled()
+			if err != nil || got != tt.want {
+				t.Errorf("isSnapshotCRDInstalled() = %v, error = %v, want %v", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSnapshotWorkerStoppedWhileWaitingTicker(t *testing.T) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+	ctrl := &snapshotTTLController{
+		snapshotQueue: workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter()),
+		deleteTicker:  ticker,
+	}
+	defer ctrl.snapshotQueue.ShutDown()
+	ctrl.snapshotQueue.Add(testNamespace + "/" + testSnapshotName)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		ctrl.runSnapshotWorker(ctx)
+		close(stopped)
+	}()
+	cancel()
+
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("runSnapshotWorker() is not stopped in 5 seconds after the context is done")
+	}
+}
@@ -19,6 +19,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"reflect"
 
 	coreV1 "k8s.io/api/core/v1"
 	apiErrors "k8s.io/apimachinery/pkg/api/errors"
@@ -220,18 +221,25 @@ func (ctrl *BackendController) createContent(ctx context.Context, storageBackend
 		needUpdate = true
 	}
 
+	if len(storageBackend.Spec.DefaultParameters) != 0 &&
+		!reflect.DeepEqual(storageBackend.Status.DefaultParameters, storageBackend.Spec.DefaultParameters) {
+		storageBackend.Status.DefaultParameters = storageBackend.Spec.DefaultParameters
+		needUpdate = true
+	}
+
 	storageBackendContent := &xuanwuv1.StorageBackendContent{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: utils.GenDynamicContentName(storageBackend),
 		},
 
 		Spec: xuanwuv1.StorageBackendContentSpec{
-			Provider:         storageBackend.Spec.Provider,
-			ConfigmapMeta:    configmapMeta,
-			SecretMeta:       secretMeta,
-			BackendClaim:     utils.StorageBackendClaimKey(storageBackend),
-			MaxClientThreads: storageBackend.Spec.MaxClientThreads,
-			Parameters:       storageBackend.Spec.Parameters,
+			Provider:          storageBackend.Spec.Provider,
+			ConfigmapMeta:     configmapMeta,
+			SecretMeta:        secretMeta,
+			BackendClaim:      utils.StorageBackendClaimKey(storageBackend),
+			MaxClientThreads:  storageBackend.Spec.MaxClientThreads,
+			Parameters:        storageBackend.Spec.Parameters,
+			DefaultParameters: storageBackend.Spec.DefaultParameters,
 		},
 	}
 
@@ -543,6 +551,7 @@ func (ctrl *BackendController) updateStorageBackendClaim(ctx context.Context, cl
 	claim.Status.SecretMeta = claim.Spec.SecretMeta
 	claim.Status.UseCert = claim.Spec.UseCert
 	claim.Status.CertSecret = claim.Spec.CertSecret
+	claim.Status.DefaultParameters = claim.Spec.DefaultParameters
 	newClaim, err := ctrl.updateClaimStatusWithEvent(ctx, claim, "UpdateClaim",
 		"Successful update claim for storageBackendClaim")
 	if err != nil {
@@ -562,6 +571,7 @@ func (ctrl *BackendController) updateStorageBackendClaim(ctx context.Context, cl
 	content.Spec.SecretMeta = claim.Spec.SecretMeta
 	content.Spec.UseCert = claim.Spec.UseCert
 	content.Spec.CertSecret = claim.Spec.CertSecret
+	content.Spec.DefaultParameters = claim.Spec.DefaultParameters
 	_, err = utils.UpdateContent(ctx, ctrl.clientSet, content)
 	if err != nil {
 		log.AddContext(ctx).Errorf("updateStorageBackendClaim: update storageBackendContent %s failed, "+
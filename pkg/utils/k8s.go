@@ -26,6 +26,7 @@ import (
 
 	coreV1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/scheme"
 	clientV1 "k8s.io/client-go/kubernetes/typed/core/v1"
@@ -102,19 +103,28 @@ func GetCertPool(ctx context.Context, useCert bool, secretMeta string) (bool, *x
 		return false, nil, fmt.Errorf("get cert from secret %s failed, error: %v", secretName, err)
 	}
 
-	certBlock, _ := pem.Decode(certMeta)
+	certPool, err := NewCertPool(certMeta)
+	if err != nil {
+		return false, nil, err
+	}
+	return true, certPool, nil
+}
+
+// NewCertPool returns the cert pool of the PEM encoded certificate
+func NewCertPool(certData []byte) (*x509.CertPool, error) {
+	certBlock, _ := pem.Decode(certData)
 	if certBlock == nil {
-		return false, nil, fmt.Errorf("certificate data decode failed")
+		return nil, fmt.Errorf("certificate data decode failed")
 	}
 
 	cert, err := x509.ParseCertificate(certBlock.Bytes)
 	if err != nil {
-		return false, nil, fmt.Errorf("error parse certificate: %v", err)
+		return nil, fmt.Errorf("error parse certificate: %v", err)
 	}
 
 	certPool := x509.NewCertPool()
 	certPool.AddCert(cert)
-	return true, certPool, nil
+	return certPool, nil
 }
 
 func GetBackendConfigmapByClaimName(ctx context.Context, claimNameMeta string) (*coreV1.ConfigMap, error) {
@@ -341,8 +351,7 @@ func SetStorageBackendContentOnlineStatus(ctx context.Context, backendID string,
 	return nil
 }
 
-// GetK8SAndSBCClient return k8sClient, storageBackendClient
-func GetK8SAndSBCClient(ctx context.Context) (*kubernetes.Clientset, *clientSet.Clientset, error) {
+func getClusterConfig(ctx context.Context) (*rest.Config, error) {
 	var config *rest.Config
 	var err error
 	if app.GetGlobalConfig().KubeConfig != "" {
@@ -354,6 +363,16 @@ func GetK8SAndSBCClient(ctx context.Context) (*kubernetes.Clientset, *clientSet.
 	if err != nil {
 		log.AddContext(ctx).Errorf("Error getting cluster config, kube config: %s, %v",
 			app.GetGlobalConfig().KubeConfig, err)
+		return nil, err
+	}
+
+	return config, nil
+}
+
+// GetK8SAndSBCClient return k8sClient, storageBackendClient
+func GetK8SAndSBCClient(ctx context.Context) (*kubernetes.Clientset, *clientSet.Clientset, error) {
+	config, err := getClusterConfig(ctx)
+	if err != nil {
 		return nil, nil, err
 	}
 
@@ -372,6 +391,22 @@ func GetK8SAndSBCClient(ctx context.Context) (*kubernetes.Clientset, *clientSet.
 	return k8sClient, storageBackendClient, nil
 }
 
+// GetDynamicClient return the dynamic client, which is used to access the resources without typed clientset
+func GetDynamicClient(ctx context.Context) (dynamic.Interface, error) {
+	config, err := getClusterConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		log.AddContext(ctx).Errorf("Error getting dynamic client, %v", err)
+		return nil, err
+	}
+
+	return dynamicClient, nil
+}
+
 // InitRecorder used to init event recorder
 func InitRecorder(client kubernetes.Interface, componentName string) record.EventRecorder {
 	eventBroadcaster := record.NewBroadcaster()
@@ -21,6 +21,7 @@ import (
 	"syscall"
 	"time"
 
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/leaderelection"
 	"k8s.io/client-go/tools/leaderelection/resourcelock"
@@ -37,6 +38,26 @@ type LeaderElectionConf struct {
 	LeaseDuration time.Duration
 	RenewDeadline time.Duration
 	RetryPeriod   time.Duration
+	// LockType is the type of the resource lock, configmapsleases is used if it is empty
+	LockType string
+	// LockNamespace is the namespace of the resource lock, the namespace of the CSI is used if it is empty
+	LockNamespace string
+}
+
+func (c LeaderElectionConf) lockType() string {
+	if c.LockType == "" {
+		return resourcelock.ConfigMapsLeasesResourceLock
+	}
+
+	return c.LockType
+}
+
+func (c LeaderElectionConf) lockNamespace() string {
+	if c.LockNamespace == "" {
+		return app.GetGlobalConfig().Namespace
+	}
+
+	return c.LockNamespace
 }
 
 // RunWithLeaderElection run the function with leader election
@@ -63,8 +84,8 @@ func RunWithLeaderElection(ctx context.Context, leaderElection LeaderElectionCon
 	}
 
 	resourceLock, err := resourcelock.New(
-		resourcelock.ConfigMapsLeasesResourceLock,
-		app.GetGlobalConfig().Namespace,
+		leaderElection.lockType(),
+		leaderElection.lockNamespace(),
 		leaderElection.LeaderName,
 		k8sClient.CoreV1(),
 		k8sClient.CoordinationV1(),
@@ -102,3 +123,81 @@ func RunWithLeaderElection(ctx context.Context, leaderElection LeaderElectionCon
 	}
 	leaderElector.Run(ctx)
 }
+
+// RunAsLeader runs the function only while this replica holds the leader lock, the context passed to the
+// function is cancelled once the leadership is lost, and the replica campaigns again until ctx is done
+func RunAsLeader(ctx context.Context, leaderElection LeaderElectionConf, k8sClient kubernetes.Interface,
+	recorder record.EventRecorder, runFunc func(ctx context.Context)) error {
+	id, err := os.Hostname()
+	if err != nil {
+		return err
+	}
+
+	resourceLock, err := resourcelock.New(
+		leaderElection.lockType(),
+		leaderElection.lockNamespace(),
+		leaderElection.LeaderName,
+		k8sClient.CoreV1(),
+		k8sClient.CoordinationV1(),
+		resourcelock.ResourceLockConfig{Identity: id, EventRecorder: recorder})
+	if err != nil {
+		return err
+	}
+
+	leaderElector, err := leaderelection.NewLeaderElector(leaderelection.LeaderElectionConfig{
+		Lock:            resourceLock,
+		LeaseDuration:   leaderElection.LeaseDuration,
+		RenewDeadline:   leaderElection.RenewDeadline,
+		RetryPeriod:     leaderElection.RetryPeriod,
+		ReleaseOnCancel: true,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: runFunc,
+			OnStoppedLeading: func() {
+				log.AddContext(ctx).Warningf("Lost the leader lock %s", leaderElection.LeaderName)
+			},
+			OnNewLeader: func(identity string) {
+				log.AddContext(ctx).Infof("New leader of %s elected. Current leader %s",
+					leaderElection.LeaderName, identity)
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	for ctx.Err() == nil {
+		leaderElector.Run(ctx)
+	}
+
+	return nil
+}
+
+// IsLeader checks whether this replica holds the leader lock, the containers of a pod share its hostname, so
+// that the lock held by another container of the pod is also held by this replica
+func IsLeader(ctx context.Context, leaderElection LeaderElectionConf, k8sClient kubernetes.Interface) (bool, error) {
+	id, err := os.Hostname()
+	if err != nil {
+		return false, err
+	}
+
+	resourceLock, err := resourcelock.New(
+		leaderElection.lockType(),
+		leaderElection.lockNamespace(),
+		leaderElection.LeaderName,
+		k8sClient.CoreV1(),
+		k8sClient.CoordinationV1(),
+		resourcelock.ResourceLockConfig{Identity: id})
+	if err != nil {
+		return false, err
+	}
+
+	record, _, err := resourceLock.Get(ctx)
+	if apiErrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return record.HolderIdentity == id, nil
+}
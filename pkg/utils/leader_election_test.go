@@ -0,0 +1,68 @@
+/*
+ Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+      http://www.apache.org/licenses/LICENSE-2.0
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+// Package utils is rbac audit related utils
+package utils
+import (
+	"context"
+	"os"
+	"testing"
+
+	coordinationV1 "k8s.io/api/coordination/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+)
+
+func TestIsLeader(t *testing.T) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		t.Fatalf("Hostname() error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		holder string
+		want   bool
+	}{
+		{"HeldByThisReplica", hostname, true},
+		{"HeldByOtherReplica", hostname + "-other", false},
+		{"NotElected", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := fake.NewSimpleClientset()
+			if tt.holder != "" {
+				_, err := client.CoordinationV1().Leases("huawei-csi").Create(context.Background(),
+					&coordinationV1.Lease{
+						ObjectMeta: metaV1.ObjectMeta{Name: "sb-sidecar-csi.huawei.com", Namespace: "huawei-csi"},
+						Spec:       coordinationV1.LeaseSpec{HolderIdentity: &tt.holder},
+					}, metaV1.CreateOptions{})
+				if err != nil {
+					t.Fatalf("create lease error = %v", err)
+				}
+			}
+
+			leader, err := IsLeader(context.Background(), LeaderElectionConf{
+				LeaderName:    "sb-sidecar-csi.huawei.com",
+				LockType:      resourcelock.LeasesResourceLock,
+				LockNamespace: "huawei-csi",
+			}, client)
+			if err != nil || leader != tt.want {
+				t.Errorf("IsLeader() = %v, error = %v, want %v", leader, err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+/*
+ Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+      http://www.apache.org/licenses/LICENSE-2.0
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+// Package utils is rbac audit related utils
+package utils
+
+import (
+	"context"
+	"fmt"
+
+	authorizationV1 "k8s.io/api/authorization/v1"
+	coreV1 "k8s.io/api/core/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/record"
+
+	"huawei-csi-driver/utils/log"
+)
+
+const rbacAuditFailedReason = "RBACAuditFailed"
+
+// ResourcePermission defines a permission which the service account requires
+type ResourcePermission struct {
+	Group     string
+	Resource  string
+	Verb      string
+	Namespace string
+}
+
+func (p ResourcePermission) String() string {
+	resource := p.Resource
+	if p.Group != "" {
+		resource = fmt.Sprintf("%s.%s", p.Resource, p.Group)
+	}
+
+	if p.Namespace == "" {
+		return fmt.Sprintf("%s %s", p.Verb, resource)
+	}
+	return fmt.Sprintf("%s %s in namespace %s", p.Verb, resource, p.Namespace)
+}
+
+// AuditRBACPermissions checks whether the service account has the required permissions by the
+// SelfSubjectAccessReview API, logs each missing permission and emits a warning event to the default
+// namespace if any check fails. It returns the missing permissions.
+func AuditRBACPermissions(ctx context.Context, client kubernetes.Interface, recorder record.EventRecorder,
+	permissions []ResourcePermission) []ResourcePermission {
+	var missing []ResourcePermission
+	for _, permission := range permissions {
+		review := &authorizationV1.SelfSubjectAccessReview{
+			Spec: authorizationV1.SelfSubjectAccessReviewSpec{
+				ResourceAttributes: &authorizationV1.ResourceAttributes{
+					Namespace: permission.Namespace,
+					Verb:      permission.Verb,
+					Group:     permission.Group,
+					Resource:  permission.Resource,
+				},
+			},
+		}
+
+		result, err := client.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, review,
+			metaV1.CreateOptions{})
+		if err != nil {
+			log.AddContext(ctx).Warningf("Check RBAC permission [%s] failed, error: %v", permission, err)
+			continue
+		}
+
+		if !result.Status.Allowed {
+			log.AddContext(ctx).Errorf("The service account is missing RBAC permission [%s], reason: %s",
+				permission, result.Status.Reason)
+			missing = append(missing, permission)
+		}
+	}
+
+	if len(missing) != 0 && recorder != nil {
+		recorder.Eventf(&coreV1.ObjectReference{Kind: "Namespace", APIVersion: "v1", Name: metaV1.NamespaceDefault},
+			coreV1.EventTypeWarning, rbacAuditFailedReason,
+			"The service account is missing RBAC permissions: %v, please check the ClusterRole of the service account",
+			missing)
+	}
+
+	return missing
+}
@@ -0,0 +1,54 @@
+/*
+ Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+      http://www.apache.org/licenses/LICENSE-2.0
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+// Package utils is rbac audit related utils
+package utils
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	authorizationV1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8sTesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestAuditRBACPermissions(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	client.PrependReactor("create", "selfsubjectaccessreviews",
+		func(action k8sTesting.Action) (bool, runtime.Object, error) {
+			review := action.(k8sTesting.CreateAction).GetObject().(*authorizationV1.SelfSubjectAccessReview)
+			review.Status.Allowed = review.Spec.ResourceAttributes.Resource != "secrets"
+			return true, review, nil
+		})
+
+	recorder := record.NewFakeRecorder(1)
+	permissions := []ResourcePermission{
+		{Group: "xuanwu.huawei.io", Resource: "storagebackendcontents", Verb: "list"},
+		{Resource: "secrets", Verb: "get", Namespace: "huawei-csi"},
+		{Resource: "events", Verb: "create", Namespace: "default"},
+	}
+
+	missing := AuditRBACPermissions(context.TODO(), client, recorder, permissions)
+	if want := permissions[1:2]; !reflect.DeepEqual(missing, want) {
+		t.Errorf("AuditRBACPermissions() = %v, want %v", missing, want)
+	}
+
+	if len(recorder.Events) != 1 {
+		t.Errorf("AuditRBACPermissions() emitted %d events, want 1", len(recorder.Events))
+	}
+}
@@ -19,10 +19,12 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net"
 	"os"
 	"os/signal"
 	"reflect"
 	"strconv"
+	"strings"
 	"syscall"
 
 	coreV1 "k8s.io/api/core/v1"
@@ -255,6 +257,10 @@ func NeedChangeContent(storageBackend *xuanwuv1.StorageBackendClaim) bool {
 		return true
 	}
 
+	if !reflect.DeepEqual(storageBackend.Status.DefaultParameters, storageBackend.Spec.DefaultParameters) {
+		return true
+	}
+
 	return false
 }
 
@@ -304,3 +310,27 @@ func ConvertToMapValueX[T bool | string | int64 | map[string]interface{}](ctx co
 	}
 	return r
 }
+
+// ParseNfsAllowedClients parses the comma separated nfs allowed clients, each client must be an IP or a CIDR
+func ParseNfsAllowedClients(allowedClients string) ([]string, error) {
+	var clients []string
+	for _, c := range strings.Split(allowedClients, ",") {
+		c = strings.TrimSpace(c)
+		if c == "" {
+			continue
+		}
+
+		if net.ParseIP(c) == nil {
+			if _, _, err := net.ParseCIDR(c); err != nil {
+				return nil, fmt.Errorf("nfs allowed client [%s] is neither an IP nor a CIDR", c)
+			}
+		}
+		clients = append(clients, c)
+	}
+
+	if len(clients) == 0 {
+		return nil, errors.New("nfs allowed clients can not be empty")
+	}
+
+	return clients, nil
+}
@@ -300,3 +300,18 @@ func TestConvertToMapValueX(t *testing.T) {
 			poolCapabilities["pool1"], capability)
 	}
 }
+
+func TestParseNfsAllowedClients(t *testing.T) {
+	clients, err := ParseNfsAllowedClients("192.168.1.10, 10.0.0.0/24")
+	if err != nil || !reflect.DeepEqual(clients, []string{"192.168.1.10", "10.0.0.0/24"}) {
+		t.Errorf("TestParseNfsAllowedClients failed, clients: %v, error: %v", clients, err)
+	}
+
+	if _, err = ParseNfsAllowedClients("10.0.0.0/33"); err == nil {
+		t.Error("TestParseNfsAllowedClients failed, invalid CIDR should return error")
+	}
+
+	if _, err = ParseNfsAllowedClients(" , "); err == nil {
+		t.Error("TestParseNfsAllowedClients failed, empty clients should return error")
+	}
+}
@@ -0,0 +1,157 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	admissionV1 "k8s.io/api/admission/v1"
+	storageV1 "k8s.io/api/storage/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"huawei-csi-driver/csi/app"
+	"huawei-csi-driver/pkg/constants"
+	pkgUtils "huawei-csi-driver/pkg/utils"
+	"huawei-csi-driver/utils"
+	"huawei-csi-driver/utils/log"
+)
+
+const (
+	deduplicationParameter = "deduplication"
+	backendParameter       = "backend"
+)
+
+// volumeSnapshotClass only contains the fields of VolumeSnapshotClass which are validated
+type volumeSnapshotClass struct {
+	metaV1.ObjectMeta `json:"metadata,omitempty"`
+	Driver            string `json:"driver"`
+}
+
+func validateStorageClass(ctx context.Context, sc *storageV1.StorageClass) error {
+	if sc.Provisioner != app.GetGlobalConfig().DriverName {
+		return nil
+	}
+
+	if sc.AllowVolumeExpansion != nil && *sc.AllowVolumeExpansion && app.GetGlobalConfig().DisableExpand {
+		msg := fmt.Sprintf("StorageClass %s allows volume expansion, but "+constants.CapabilityDisabledFormat,
+			sc.Name, "volume expansion")
+		log.AddContext(ctx).Errorln(msg)
+		return fmt.Errorf(msg)
+	}
+
+	if deduplication, ok := sc.Parameters[deduplicationParameter]; ok && utils.StrToBool(ctx, deduplication) {
+		return validateDeduplication(ctx, sc)
+	}
+
+	return nil
+}
+
+// validateDeduplication checks that at least one backend the StorageClass may select has the deduplication
+// licensed, the backends are limited to the one named by the backend parameter if it is set
+func validateDeduplication(ctx context.Context, sc *storageV1.StorageClass) error {
+	contents, err := pkgUtils.ListContent(ctx, app.GetGlobalConfig().BackendUtils)
+	if err != nil {
+		log.AddContext(ctx).Errorf("List StorageBackendContents failed, error: %v", err)
+		return err
+	}
+
+	backendName := sc.Parameters[backendParameter]
+	for _, content := range contents.Items {
+		if content.Status == nil || !content.Status.Capabilities[string(constants.SupportPerFsDedup)] {
+			continue
+		}
+
+		_, name, err := pkgUtils.SplitMetaNamespaceKey(content.Spec.BackendClaim)
+		if err != nil {
+			log.AddContext(ctx).Warningf("Split backend claim %s failed, error: %v",
+				content.Spec.BackendClaim, err)
+			continue
+		}
+
+		if backendName == "" || backendName == name {
+			return nil
+		}
+	}
+
+	msg := fmt.Sprintf("StorageClass %s requests deduplication, but no backend has the deduplication licensed",
+		sc.Name)
+	if backendName != "" {
+		msg = fmt.Sprintf("StorageClass %s requests deduplication, but backend %s does not have "+
+			"the deduplication licensed", sc.Name, backendName)
+	}
+	log.AddContext(ctx).Errorln(msg)
+	return fmt.Errorf(msg)
+}
+
+func validateVolumeSnapshotClass(ctx context.Context, vsc *volumeSnapshotClass) error {
+	if vsc.Driver != app.GetGlobalConfig().DriverName {
+		return nil
+	}
+
+	if app.GetGlobalConfig().DisableSnapshot {
+		msg := fmt.Sprintf("VolumeSnapshotClass %s references driver %s, but "+constants.CapabilityDisabledFormat,
+			vsc.Name, vsc.Driver, "snapshot")
+		log.AddContext(ctx).Errorln(msg)
+		return fmt.Errorf(msg)
+	}
+
+	return nil
+}
+
+func admitStorageClass(ar admissionV1.AdmissionReview) *admissionV1.AdmissionResponse {
+	log.Infoln("Start admit StorageClass.")
+	ctx := context.Background()
+	if ar.Request.Operation != admissionV1.Create && ar.Request.Operation != admissionV1.Update {
+		return getTrueAdmissionResponse()
+	}
+
+	sc := &storageV1.StorageClass{}
+	if _, _, err := Codecs.UniversalDeserializer().Decode(ar.Request.Object.Raw, nil, sc); err != nil {
+		log.AddContext(ctx).Errorf("Decode StorageClass %v failed, error: %v", ar.Request.Object.Raw, err)
+		return getFalseAdmissionResponse(err)
+	}
+
+	if err := validateStorageClass(ctx, sc); err != nil {
+		return getFalseAdmissionResponse(err)
+	}
+
+	log.AddContext(ctx).Infof("Successful admitting StorageClass %s.", sc.Name)
+	return getTrueAdmissionResponse()
+}
+
+func admitVolumeSnapshotClass(ar admissionV1.AdmissionReview) *admissionV1.AdmissionResponse {
+	log.Infoln("Start admit VolumeSnapshotClass.")
+	ctx := context.Background()
+	if ar.Request.Operation != admissionV1.Create && ar.Request.Operation != admissionV1.Update {
+		return getTrueAdmissionResponse()
+	}
+
+	vsc := &volumeSnapshotClass{}
+	if err := json.Unmarshal(ar.Request.Object.Raw, vsc); err != nil {
+		log.AddContext(ctx).Errorf("Decode VolumeSnapshotClass %v failed, error: %v", ar.Request.Object.Raw, err)
+		return getFalseAdmissionResponse(err)
+	}
+
+	if err := validateVolumeSnapshotClass(ctx, vsc); err != nil {
+		return getFalseAdmissionResponse(err)
+	}
+
+	log.AddContext(ctx).Infof("Successful admitting VolumeSnapshotClass %s.", vsc.Name)
+	return getTrueAdmissionResponse()
+}
@@ -0,0 +1,139 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package webhook
+
+import (
+	"testing"
+
+	"github.com/prashantv/gostub"
+	storageV1 "k8s.io/api/storage/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xuanwuv1 "huawei-csi-driver/client/apis/xuanwu/v1"
+	"huawei-csi-driver/csi/app"
+	cfg "huawei-csi-driver/csi/app/config"
+	"huawei-csi-driver/pkg/client/clientset/versioned/fake"
+)
+
+const fakeDriverName = "csi.huawei.com"
+
+func stubCapabilityConfig(disableSnapshot, disableExpand bool) *gostub.Stubs {
+	config := cfg.MockCompletedConfig()
+	config.DriverName = fakeDriverName
+	config.DisableSnapshot = disableSnapshot
+	config.DisableExpand = disableExpand
+	return gostub.StubFunc(&app.GetGlobalConfig, config)
+}
+
+func TestValidateStorageClass(t *testing.T) {
+	allowExpansion := true
+	cases := []struct {
+		name          string
+		provisioner   string
+		disableExpand bool
+		wantErr       bool
+	}{
+		{"ExpandEnabled", fakeDriverName, false, false},
+		{"ExpandDisabled", fakeDriverName, true, true},
+		{"OtherProvisioner", "other.csi.com", true, false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			stubs := stubCapabilityConfig(false, c.disableExpand)
+			defer stubs.Reset()
+
+			sc := &storageV1.StorageClass{
+				ObjectMeta:           metaV1.ObjectMeta{Name: "sc"},
+				Provisioner:          c.provisioner,
+				AllowVolumeExpansion: &allowExpansion,
+			}
+			if err := validateStorageClass(ctx, sc); (err != nil) != c.wantErr {
+				t.Errorf("validateStorageClass() error = %v, wantErr %v", err, c.wantErr)
+			}
+		})
+	}
+}
+
+func fakeDedupContent(name string, supportDedup bool) *xuanwuv1.StorageBackendContent {
+	return &xuanwuv1.StorageBackendContent{
+		ObjectMeta: metaV1.ObjectMeta{Name: "content-" + name},
+		Spec:       xuanwuv1.StorageBackendContentSpec{BackendClaim: "huawei-csi/" + name},
+		Status: &xuanwuv1.StorageBackendContentStatus{
+			Capabilities: map[string]bool{"SupportPerFsDedup": supportDedup},
+		},
+	}
+}
+
+func TestValidateStorageClassDeduplication(t *testing.T) {
+	cases := []struct {
+		name       string
+		parameters map[string]string
+		wantErr    bool
+	}{
+		{"NotRequested", map[string]string{"deduplication": "false"}, false},
+		{"AnyBackendLicensed", map[string]string{"deduplication": "true"}, false},
+		{"NamedBackendLicensed", map[string]string{"deduplication": "true", "backend": "nas-a"}, false},
+		{"NamedBackendNotLicensed", map[string]string{"deduplication": "true", "backend": "nas-b"}, true},
+		{"NamedBackendNotExist", map[string]string{"deduplication": "true", "backend": "nas-c"}, true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			config := cfg.MockCompletedConfig()
+			config.DriverName = fakeDriverName
+			config.BackendUtils = fake.NewSimpleClientset(fakeDedupContent("nas-a", true),
+				fakeDedupContent("nas-b", false))
+			stubs := gostub.StubFunc(&app.GetGlobalConfig, config)
+			defer stubs.Reset()
+
+			sc := &storageV1.StorageClass{
+				ObjectMeta:  metaV1.ObjectMeta{Name: "sc"},
+				Provisioner: fakeDriverName,
+				Parameters:  c.parameters,
+			}
+			if err := validateStorageClass(ctx, sc); (err != nil) != c.wantErr {
+				t.Errorf("validateStorageClass() error = %v, wantErr %v", err, c.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateVolumeSnapshotClass(t *testing.T) {
+	cases := []struct {
+		name            string
+		driver          string
+		disableSnapshot bool
+		wantErr         bool
+	}{
+		{"SnapshotEnabled", fakeDriverName, false, false},
+		{"SnapshotDisabled", fakeDriverName, true, true},
+		{"OtherDriver", "other.csi.com", true, false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			stubs := stubCapabilityConfig(c.disableSnapshot, false)
+			defer stubs.Reset()
+
+			vsc := &volumeSnapshotClass{ObjectMeta: metaV1.ObjectMeta{Name: "vsc"}, Driver: c.driver}
+			if err := validateVolumeSnapshotClass(ctx, vsc); (err != nil) != c.wantErr {
+				t.Errorf("validateVolumeSnapshotClass() error = %v, wantErr %v", err, c.wantErr)
+			}
+		})
+	}
+}
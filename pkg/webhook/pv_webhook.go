@@ -0,0 +1,90 @@
+/*
+Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+  http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"fmt"
+
+	admissionV1 "k8s.io/api/admission/v1"
+	authenticationV1 "k8s.io/api/authentication/v1"
+	coreV1 "k8s.io/api/core/v1"
+
+	"huawei-csi-driver/csi/app"
+	"huawei-csi-driver/pkg/constants"
+	"huawei-csi-driver/utils/k8sutils"
+	"huawei-csi-driver/utils/log"
+)
+
+// validateWriteProtectRemoval only allows the cluster admins to request the removal of the write protection,
+// the old PersistentVolume is nil when it is created
+func validateWriteProtectRemoval(ctx context.Context, oldPV, pv *coreV1.PersistentVolume,
+	user authenticationV1.UserInfo, authorizer k8sutils.AuthorizationOps) error {
+	if pv.Annotations[constants.WriteProtectAnnotation] != constants.WriteProtectRemove {
+		return nil
+	}
+	if oldPV != nil && oldPV.Annotations[constants.WriteProtectAnnotation] == constants.WriteProtectRemove {
+		return nil
+	}
+
+	admin, err := authorizer.IsClusterAdmin(ctx, user)
+	if err != nil {
+		log.AddContext(ctx).Errorf("Check whether user %s is cluster admin failed, error: %v", user.Username, err)
+		return err
+	}
+
+	if !admin {
+		msg := fmt.Sprintf("only the cluster admins are allowed to set annotation %s of PersistentVolume %s to %s, "+
+			"user %s is not", constants.WriteProtectAnnotation, pv.Name, constants.WriteProtectRemove, user.Username)
+		log.AddContext(ctx).Errorln(msg)
+		return fmt.Errorf(msg)
+	}
+
+	log.AddContext(ctx).Infof("User %s requests to remove write protection of PersistentVolume %s",
+		user.Username, pv.Name)
+	return nil
+}
+
+func admitPersistentVolume(ar admissionV1.AdmissionReview) *admissionV1.AdmissionResponse {
+	log.Infoln("Start admit PersistentVolume.")
+	ctx := context.Background()
+	if ar.Request.Operation != admissionV1.Create && ar.Request.Operation != admissionV1.Update {
+		return getTrueAdmissionResponse()
+	}
+
+	pv := &coreV1.PersistentVolume{}
+	if _, _, err := Codecs.UniversalDeserializer().Decode(ar.Request.Object.Raw, nil, pv); err != nil {
+		log.AddContext(ctx).Errorf("Decode PersistentVolume %v failed, error: %v", ar.Request.Object.Raw, err)
+		return getFalseAdmissionResponse(err)
+	}
+
+	var oldPV *coreV1.PersistentVolume
+	if ar.Request.Operation == admissionV1.Update {
+		oldPV = &coreV1.PersistentVolume{}
+		if _, _, err := Codecs.UniversalDeserializer().Decode(ar.Request.OldObject.Raw, nil, oldPV); err != nil {
+			log.AddContext(ctx).Errorf("Decode old PersistentVolume %v failed, error: %v",
+				ar.Request.OldObject.Raw, err)
+			return getFalseAdmissionResponse(err)
+		}
+	}
+
+	err := validateWriteProtectRemoval(ctx, oldPV, pv, ar.Request.UserInfo, app.GetGlobalConfig().K8sUtils)
+	if err != nil {
+		return getFalseAdmissionResponse(err)
+	}
+
+	log.AddContext(ctx).Infof("Successful admitting PersistentVolume %s.", pv.Name)
+	return getTrueAdmissionResponse()
+}
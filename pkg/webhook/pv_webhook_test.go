@@ -0,0 +1,80 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package webhook
+
+import (
+	"context"
+	"testing"
+
+	authenticationV1 "k8s.io/api/authentication/v1"
+	coreV1 "k8s.io/api/core/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"huawei-csi-driver/pkg/constants"
+)
+
+type fakeAuthorizer struct {
+	admins map[string]bool
+	checks int
+}
+
+func (f *fakeAuthorizer) IsClusterAdmin(_ context.Context, user authenticationV1.UserInfo) (bool, error) {
+	f.checks++
+	return f.admins[user.Username], nil
+}
+
+func TestValidateWriteProtectRemoval(t *testing.T) {
+	newPV := func(writeProtect string) *coreV1.PersistentVolume {
+		pv := &coreV1.PersistentVolume{ObjectMeta: metaV1.ObjectMeta{Name: "pv"}}
+		if writeProtect != "" {
+			pv.Annotations = map[string]string{constants.WriteProtectAnnotation: writeProtect}
+		}
+		return pv
+	}
+
+	tests := []struct {
+		name       string
+		oldPV      *coreV1.PersistentVolume
+		pv         *coreV1.PersistentVolume
+		user       string
+		wantErr    bool
+		wantChecks int
+	}{
+		{"NoAnnotation", newPV(""), newPV(""), "user", false, 0},
+		{"RemovedByController", newPV(constants.WriteProtectRemove),
+			newPV(constants.WriteProtectRemoved), "controller", false, 0},
+		{"UnchangedRemoval", newPV(constants.WriteProtectRemove),
+			newPV(constants.WriteProtectRemove), "user", false, 0},
+		{"RemovalByAdmin", newPV(""), newPV(constants.WriteProtectRemove), "admin", false, 1},
+		{"RemovalByUser", newPV(""), newPV(constants.WriteProtectRemove), "user", true, 1},
+		{"CreateWithRemovalByUser", nil, newPV(constants.WriteProtectRemove), "user", true, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authorizer := &fakeAuthorizer{admins: map[string]bool{"admin": true}}
+			err := validateWriteProtectRemoval(context.Background(), tt.oldPV, tt.pv,
+				authenticationV1.UserInfo{Username: tt.user}, authorizer)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateWriteProtectRemoval() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if authorizer.checks != tt.wantChecks {
+				t.Errorf("validateWriteProtectRemoval() checks = %d, want %d", authorizer.checks, tt.wantChecks)
+			}
+		})
+	}
+}
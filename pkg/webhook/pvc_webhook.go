@@ -0,0 +1,98 @@
+/*
+Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+  http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"fmt"
+
+	admissionV1 "k8s.io/api/admission/v1"
+	coreV1 "k8s.io/api/core/v1"
+
+	"huawei-csi-driver/pkg/constants"
+	"huawei-csi-driver/pkg/utils"
+	"huawei-csi-driver/utils/log"
+)
+
+// validatePersistentVolumeClaim validates the nfs allowed clients annotation, the old PersistentVolumeClaim is nil
+// when it is created
+func validatePersistentVolumeClaim(ctx context.Context, oldPVC, pvc *coreV1.PersistentVolumeClaim) error {
+	if err := validateNfsAllowedClientsChange(oldPVC, pvc); err != nil {
+		log.AddContext(ctx).Errorln(err)
+		return err
+	}
+
+	allowedClients, exist := pvc.Annotations[constants.NfsAllowedClientsAnnotation]
+	if !exist {
+		return nil
+	}
+
+	if _, err := utils.ParseNfsAllowedClients(allowedClients); err != nil {
+		msg := fmt.Sprintf("annotation %s of PersistentVolumeClaim %s/%s is invalid, %v",
+			constants.NfsAllowedClientsAnnotation, pvc.Namespace, pvc.Name, err)
+		log.AddContext(ctx).Errorln(msg)
+		return fmt.Errorf(msg)
+	}
+
+	return nil
+}
+
+// validateNfsAllowedClientsChange rejects the change of the nfs allowed clients annotation after the volume of
+// the claim is provisioned, the clients are only applied to the nfs share when the volume is created
+func validateNfsAllowedClientsChange(oldPVC, pvc *coreV1.PersistentVolumeClaim) error {
+	if oldPVC == nil || oldPVC.Spec.VolumeName == "" {
+		return nil
+	}
+
+	oldClients, oldExist := oldPVC.Annotations[constants.NfsAllowedClientsAnnotation]
+	clients, exist := pvc.Annotations[constants.NfsAllowedClientsAnnotation]
+	if oldExist == exist && oldClients == clients {
+		return nil
+	}
+
+	return fmt.Errorf("annotation %s of PersistentVolumeClaim %s/%s can't be changed after its volume %s is "+
+		"provisioned", constants.NfsAllowedClientsAnnotation, pvc.Namespace, pvc.Name, oldPVC.Spec.VolumeName)
+}
+
+func admitPersistentVolumeClaim(ar admissionV1.AdmissionReview) *admissionV1.AdmissionResponse {
+	log.Infoln("Start admit PersistentVolumeClaim.")
+	ctx := context.Background()
+	if ar.Request.Operation != admissionV1.Create && ar.Request.Operation != admissionV1.Update {
+		return getTrueAdmissionResponse()
+	}
+
+	pvc := &coreV1.PersistentVolumeClaim{}
+	if _, _, err := Codecs.UniversalDeserializer().Decode(ar.Request.Object.Raw, nil, pvc); err != nil {
+		log.AddContext(ctx).Errorf("Decode PersistentVolumeClaim %v failed, error: %v", ar.Request.Object.Raw, err)
+		return getFalseAdmissionResponse(err)
+	}
+
+	var oldPVC *coreV1.PersistentVolumeClaim
+	if ar.Request.Operation == admissionV1.Update {
+		oldPVC = &coreV1.PersistentVolumeClaim{}
+		if _, _, err := Codecs.UniversalDeserializer().Decode(ar.Request.OldObject.Raw, nil, oldPVC); err != nil {
+			log.AddContext(ctx).Errorf("Decode old PersistentVolumeClaim %v failed, error: %v",
+				ar.Request.OldObject.Raw, err)
+			return getFalseAdmissionResponse(err)
+		}
+	}
+
+	if err := validatePersistentVolumeClaim(ctx, oldPVC, pvc); err != nil {
+		return getFalseAdmissionResponse(err)
+	}
+
+	log.AddContext(ctx).Infof("Successful admitting PersistentVolumeClaim %s/%s.", pvc.Namespace, pvc.Name)
+	return getTrueAdmissionResponse()
+}
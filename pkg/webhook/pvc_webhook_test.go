@@ -0,0 +1,64 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package webhook
+
+import (
+	"context"
+	"testing"
+
+	coreV1 "k8s.io/api/core/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"huawei-csi-driver/pkg/constants"
+)
+
+func TestValidatePersistentVolumeClaim(t *testing.T) {
+	newPVC := func(allowedClients, volumeName string) *coreV1.PersistentVolumeClaim {
+		pvc := &coreV1.PersistentVolumeClaim{
+			ObjectMeta: metaV1.ObjectMeta{Name: "pvc", Namespace: "default"},
+			Spec:       coreV1.PersistentVolumeClaimSpec{VolumeName: volumeName},
+		}
+		if allowedClients != "" {
+			pvc.Annotations = map[string]string{constants.NfsAllowedClientsAnnotation: allowedClients}
+		}
+		return pvc
+	}
+
+	tests := []struct {
+		name    string
+		oldPVC  *coreV1.PersistentVolumeClaim
+		pvc     *coreV1.PersistentVolumeClaim
+		wantErr bool
+	}{
+		{"CreateValid", nil, newPVC("10.0.0.0/24,192.168.1.100", ""), false},
+		{"CreateInvalid", nil, newPVC("10.0.0.0/33", ""), true},
+		{"ChangedBeforeProvisioned", newPVC("10.0.0.0/24", ""), newPVC("10.0.1.0/24", ""), false},
+		{"UnchangedAfterProvisioned", newPVC("10.0.0.0/24", "pv"), newPVC("10.0.0.0/24", "pv"), false},
+		{"ChangedAfterProvisioned", newPVC("10.0.0.0/24", "pv"), newPVC("10.0.1.0/24", "pv"), true},
+		{"AddedAfterProvisioned", newPVC("", "pv"), newPVC("10.0.1.0/24", "pv"), true},
+		{"RemovedAfterProvisioned", newPVC("10.0.0.0/24", "pv"), newPVC("", "pv"), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validatePersistentVolumeClaim(context.Background(), tt.oldPVC, tt.pvc)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validatePersistentVolumeClaim() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
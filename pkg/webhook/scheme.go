@@ -19,6 +19,7 @@ import (
 	admissionV1 "k8s.io/api/admission/v1"
 	admissionRegistrationV1 "k8s.io/api/admissionregistration/v1"
 	coreV1 "k8s.io/api/core/v1"
+	storageV1 "k8s.io/api/storage/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/serializer"
 	utilRuntime "k8s.io/apimachinery/pkg/util/runtime"
@@ -37,4 +38,5 @@ func addToScheme(scheme *runtime.Scheme) {
 	utilRuntime.Must(coreV1.AddToScheme(scheme))
 	utilRuntime.Must(admissionV1.AddToScheme(scheme))
 	utilRuntime.Must(admissionRegistrationV1.AddToScheme(scheme))
+	utilRuntime.Must(storageV1.AddToScheme(scheme))
 }
@@ -34,6 +34,10 @@ type AdmissionWebHookCFG struct {
 	WebhookPort   int32
 	AdmissionOps  []admissionV1.OperationType
 	AdmissionRule AdmissionRule
+	// FailurePolicy defaults to Fail if not set
+	FailurePolicy admissionV1.FailurePolicyType
+	// NamespaceSelector limits the namespaces of the admitted objects, all namespaces are admitted if not set
+	NamespaceSelector *metaV1.LabelSelector
 }
 
 // AdmissionRule includes admission rules
@@ -48,6 +52,9 @@ func CreateValidateWebhook(ctx context.Context, admissionWebhook AdmissionWebHoo
 	caBundle []byte, ns string) error {
 	sideEffect := admissionV1.SideEffectClassNoneOnDryRun
 	failurePolicy := admissionV1.Fail
+	if admissionWebhook.FailurePolicy != "" {
+		failurePolicy = admissionWebhook.FailurePolicy
+	}
 	matchPolicy := admissionV1.Exact
 	webhook := admissionV1.ValidatingWebhook{
 		Name: admissionWebhook.WebhookName,
@@ -72,6 +79,7 @@ func CreateValidateWebhook(ctx context.Context, admissionWebhook AdmissionWebHoo
 		FailurePolicy:           &failurePolicy,
 		AdmissionReviewVersions: []string{"v1", "v1beta1"},
 		MatchPolicy:             &matchPolicy,
+		NamespaceSelector:       admissionWebhook.NamespaceSelector,
 	}
 
 	req := &admissionV1.ValidatingWebhookConfiguration{
@@ -82,10 +90,26 @@ func CreateValidateWebhook(ctx context.Context, admissionWebhook AdmissionWebHoo
 	}
 
 	_, err := admission.Instance().CreateValidatingWebhookCfg(req)
-	if err != nil && !apisErrors.IsAlreadyExists(err) {
+	if apisErrors.IsAlreadyExists(err) {
+		err = updateValidateWebhook(req)
+	}
+	if err != nil {
 		log.AddContext(ctx).Errorf("unable to create webhook configuration: %v", err)
 		return err
 	}
 	log.AddContext(ctx).Infof("%v webhook v1 configured", admissionWebhook.WebhookName)
 	return nil
 }
+
+// updateValidateWebhook updates the webhooks of the existing configuration, so that the changes of the webhooks,
+// such as the failure policy and the selectors, take effect after the upgrade
+func updateValidateWebhook(req *admissionV1.ValidatingWebhookConfiguration) error {
+	current, err := admission.Instance().GetValidatingWebhookCfg(req.Name)
+	if err != nil {
+		return err
+	}
+
+	current.Webhooks = req.Webhooks
+	_, err = admission.Instance().UpdateValidatingWebhookCfg(current)
+	return err
+}
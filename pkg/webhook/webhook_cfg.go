@@ -19,6 +19,8 @@ import (
 	"fmt"
 
 	admissionV1 "k8s.io/api/admissionregistration/v1"
+	coreV1 "k8s.io/api/core/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"huawei-csi-driver/csi/app"
 	"huawei-csi-driver/pkg/constants"
@@ -34,14 +36,42 @@ const (
 	claimAPIGroups   = "xuanwu.huawei.io"
 	claimAPIVersions = "v1"
 	claimResources   = "storagebackendclaims"
+
+	pvcWebhookPath = "/persistentvolumeclaim"
+	pvcAPIVersions = "v1"
+	pvcResources   = "persistentvolumeclaims"
+
+	pvWebhookPath = "/persistentvolume"
+	pvResources   = "persistentvolumes"
+
+	scWebhookPath = "/storageclass"
+	scAPIGroups   = "storage.k8s.io"
+	scAPIVersions = "v1"
+	scResources   = "storageclasses"
+
+	vscWebhookPath = "/volumesnapshotclass"
+	vscAPIGroups   = "snapshot.storage.k8s.io"
+	vscAPIVersions = "v1"
+	vscResources   = "volumesnapshotclasses"
 )
 
+// systemNamespaces are the namespaces of the Kubernetes components, whose claims are not admitted
+var systemNamespaces = []string{metaV1.NamespaceSystem, metaV1.NamespacePublic, coreV1.NamespaceNodeLease}
+
 // GetStorageWebHookCfg used to get storage webhook configuration
 func GetStorageWebHookCfg() (WebHook, []AdmissionWebHookCFG) {
 	var handleFuncPair []HandleFuncPair
 	handleFuncPair = append(handleFuncPair,
 		HandleFuncPair{WebhookPath: claimWebhookPath,
-			WebHookFunc: admitStorageBackendClaim})
+			WebHookFunc: admitStorageBackendClaim},
+		HandleFuncPair{WebhookPath: pvcWebhookPath,
+			WebHookFunc: admitPersistentVolumeClaim},
+		HandleFuncPair{WebhookPath: pvWebhookPath,
+			WebHookFunc: admitPersistentVolume},
+		HandleFuncPair{WebhookPath: scWebhookPath,
+			WebHookFunc: admitStorageClass},
+		HandleFuncPair{WebhookPath: vscWebhookPath,
+			WebHookFunc: admitVolumeSnapshotClass})
 
 	webHookCfg := WebHook{
 		NamespaceEnv:     constants.NamespaceEnv,
@@ -72,8 +102,85 @@ func GetStorageWebHookCfg() (WebHook, []AdmissionWebHookCFG) {
 		},
 	}
 
+	// the PersistentVolumeClaim webhook matches the claims of all provisioners, so it skips the system
+	// namespaces and must not block the claims when the webhook service is unavailable
+	pvcAdmissionWebhook := AdmissionWebHookCFG{
+		WebhookName: fmt.Sprintf("%s-pvc.xuanwu.huawei.io", containerName),
+		ServiceName: serviceName,
+		WebhookPath: pvcWebhookPath,
+		WebhookPort: int32(app.GetGlobalConfig().WebHookPort),
+		AdmissionOps: []admissionV1.OperationType{
+			admissionV1.Create,
+			admissionV1.Update},
+		AdmissionRule: AdmissionRule{
+			APIGroups:   []string{""},
+			APIVersions: []string{pvcAPIVersions},
+			Resources:   []string{pvcResources},
+		},
+		FailurePolicy: admissionV1.Ignore,
+		NamespaceSelector: &metaV1.LabelSelector{
+			MatchExpressions: []metaV1.LabelSelectorRequirement{{
+				Key:      coreV1.LabelMetadataName,
+				Operator: metaV1.LabelSelectorOpNotIn,
+				Values:   systemNamespaces,
+			}},
+		},
+	}
+
+	// only the cluster admins are allowed to request the removal of the write protection of the volumes, the
+	// guard must not be bypassed when the webhook service is unavailable, so the requests fail in that case
+	pvAdmissionWebhook := AdmissionWebHookCFG{
+		WebhookName: fmt.Sprintf("%s-pv.xuanwu.huawei.io", containerName),
+		ServiceName: serviceName,
+		WebhookPath: pvWebhookPath,
+		WebhookPort: int32(app.GetGlobalConfig().WebHookPort),
+		AdmissionOps: []admissionV1.OperationType{
+			admissionV1.Create,
+			admissionV1.Update},
+		AdmissionRule: AdmissionRule{
+			APIGroups:   []string{""},
+			APIVersions: []string{pvcAPIVersions},
+			Resources:   []string{pvResources},
+		},
+		FailurePolicy: admissionV1.Fail,
+	}
+
+	// the classes which reference the capabilities disabled by the flags of this deployment are rejected
+	scAdmissionWebhook := AdmissionWebHookCFG{
+		WebhookName: fmt.Sprintf("%s-sc.xuanwu.huawei.io", containerName),
+		ServiceName: serviceName,
+		WebhookPath: scWebhookPath,
+		WebhookPort: int32(app.GetGlobalConfig().WebHookPort),
+		AdmissionOps: []admissionV1.OperationType{
+			admissionV1.Create,
+			admissionV1.Update},
+		AdmissionRule: AdmissionRule{
+			APIGroups:   []string{scAPIGroups},
+			APIVersions: []string{scAPIVersions},
+			Resources:   []string{scResources},
+		},
+		FailurePolicy: admissionV1.Ignore,
+	}
+
+	vscAdmissionWebhook := AdmissionWebHookCFG{
+		WebhookName: fmt.Sprintf("%s-vsc.xuanwu.huawei.io", containerName),
+		ServiceName: serviceName,
+		WebhookPath: vscWebhookPath,
+		WebhookPort: int32(app.GetGlobalConfig().WebHookPort),
+		AdmissionOps: []admissionV1.OperationType{
+			admissionV1.Create,
+			admissionV1.Update},
+		AdmissionRule: AdmissionRule{
+			APIGroups:   []string{vscAPIGroups},
+			APIVersions: []string{vscAPIVersions},
+			Resources:   []string{vscResources},
+		},
+		FailurePolicy: admissionV1.Ignore,
+	}
+
 	var admissionWebhooks []AdmissionWebHookCFG
-	admissionWebhooks = append(admissionWebhooks, admissionWebhook)
+	admissionWebhooks = append(admissionWebhooks, admissionWebhook, pvcAdmissionWebhook, pvAdmissionWebhook,
+		scAdmissionWebhook, vscAdmissionWebhook)
 
 	return webHookCfg, admissionWebhooks
 }
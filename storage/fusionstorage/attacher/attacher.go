@@ -27,6 +27,7 @@ import (
 	"huawei-csi-driver/connector"
 	_ "huawei-csi-driver/connector/iscsi"
 	_ "huawei-csi-driver/connector/local"
+	"huawei-csi-driver/pkg/constants"
 	"huawei-csi-driver/storage/fusionstorage/client"
 	"huawei-csi-driver/storage/oceanstor/attacher"
 	"huawei-csi-driver/utils"
@@ -35,7 +36,7 @@ import (
 
 // Attacher defines attacher client
 type Attacher struct {
-	cli      *client.Client
+	cli      client.BaseClientInterface
 	protocol string
 	invoker  string
 	portals  []string
@@ -49,7 +50,7 @@ const (
 )
 
 // NewAttacher used to init a new attacher
-func NewAttacher(cli *client.Client, protocol, invoker string, portals []string,
+func NewAttacher(cli client.BaseClientInterface, protocol, invoker string, portals []string,
 	hosts map[string]string, alua map[string]interface{}) *Attacher {
 	return &Attacher{
 		cli:      cli,
@@ -272,9 +273,8 @@ func (p *Attacher) doMapping(ctx context.Context, lunName, hostName string) (str
 		return "", err
 	}
 	if lun == nil {
-		msg := fmt.Sprintf("Lun %s not exist for attaching", lunName)
-		log.AddContext(ctx).Errorln(msg)
-		return "", errors.New(msg)
+		log.AddContext(ctx).Errorf("Lun %s not exist for attaching", lunName)
+		return "", fmt.Errorf("%w: lun %s not exist for attaching", constants.ErrVolumeNotFound, lunName)
 	}
 
 	if p.protocol == "iscsi" {
@@ -28,12 +28,15 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/http/cookiejar"
+	netUrl "net/url"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	pkgUtils "huawei-csi-driver/pkg/utils"
 	"huawei-csi-driver/storage/fusionstorage/types"
+	storageUtils "huawei-csi-driver/storage/fusionstorage/utils"
 	"huawei-csi-driver/utils"
 	"huawei-csi-driver/utils/log"
 )
@@ -46,6 +49,8 @@ const (
 	maxParallelCount     int = 1000
 	minParallelCount     int = 20
 
+	queryCountPerPage int = 100
+
 	loginFailed         = 1077949061
 	loginFailedWithArg  = 1077987870
 	userPasswordInvalid = 1073754390
@@ -81,6 +86,33 @@ func isFilterLog(method, url string) bool {
 	return exist && filter[url]
 }
 
+// BaseClientInterface defines interfaces for base client operations
+type BaseClientInterface interface {
+	Host
+	Iscsi
+	Namespace
+	Qos
+	Quota
+	Snapshot
+	System
+	Volume
+
+	// DuplicateClient used to duplicate client
+	DuplicateClient() *Client
+	// ValidateLogin try to login fusion storage by secret
+	ValidateLogin(ctx context.Context) error
+	// Login try to login fusion storage by backend id
+	Login(ctx context.Context) error
+	// SetAccountId used to set account id of the client
+	SetAccountId(ctx context.Context) error
+	// Logout used to log out
+	Logout(ctx context.Context)
+	// KeepAlive used to keep connection token alive
+	KeepAlive(ctx context.Context)
+	// Post used to send post request to storage client
+	Post(ctx context.Context, url string, data map[string]interface{}) (map[string]interface{}, error)
+}
+
 // Client defines fusion storage client
 type Client struct {
 	url             string
@@ -503,6 +535,50 @@ func (cli *Client) get(ctx context.Context,
 	return body, err
 }
 
+// getPagedData gets the data list page by page with the range={"offset":x,"limit":y} query, until all the data
+// are got or the limit is reached, the limit 0 means no limit
+func (cli *Client) getPagedData(ctx context.Context, url string, limit int) ([]map[string]interface{}, error) {
+	separator := "?"
+	if strings.Contains(url, "?") {
+		separator = "&"
+	}
+
+	var dataList []map[string]interface{}
+	for offset := 0; limit <= 0 || offset < limit; offset += queryCountPerPage {
+		rangeRaw := fmt.Sprintf("{\"offset\":%d,\"limit\":%d}", offset, queryCountPerPage)
+		resp, err := cli.get(ctx, url+separator+"range="+netUrl.QueryEscape(rangeRaw), nil)
+		if err != nil {
+			return nil, err
+		}
+
+		if err = storageUtils.CheckErrorCode(resp); err != nil {
+			return nil, err
+		}
+
+		page, ok := resp["data"].([]interface{})
+		if !ok && resp["data"] != nil {
+			return nil, fmt.Errorf("convert data [%v] to []interface{} failed", resp["data"])
+		}
+
+		for _, i := range page {
+			data, ok := i.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("convert data [%v] to map[string]interface{} failed", i)
+			}
+			dataList = append(dataList, data)
+		}
+
+		if len(page) < queryCountPerPage {
+			break
+		}
+	}
+
+	if limit > 0 && len(dataList) > limit {
+		dataList = dataList[:limit]
+	}
+	return dataList, nil
+}
+
 // Post used to send post request to storage client
 func (cli *Client) Post(ctx context.Context, url string, data map[string]interface{}) (map[string]interface{}, error) {
 	return cli.post(ctx, url, data)
@@ -28,6 +28,26 @@ const (
 	hostnameAlreadyExist int64 = 50157019
 )
 
+// Host defines interfaces for host operations
+type Host interface {
+	// GetHostByName used to get host by name
+	GetHostByName(ctx context.Context, hostName string) (map[string]interface{}, error)
+	// CreateHost used to create host
+	CreateHost(ctx context.Context, hostName string, alua map[string]interface{}) error
+	// UpdateHost used to update host
+	UpdateHost(ctx context.Context, hostName string, alua map[string]interface{}) error
+	// QueryHostByPort used query host by port
+	QueryHostByPort(ctx context.Context, port string) (string, error)
+	// AddPortToHost used add port to host
+	AddPortToHost(ctx context.Context, initiatorName, hostName string) error
+	// AddLunToHost usd to add lun to host
+	AddLunToHost(ctx context.Context, lunName, hostName string) error
+	// DeleteLunFromHost used to delete lun from host
+	DeleteLunFromHost(ctx context.Context, lunName, hostName string) error
+	// QueryHostOfVolume used to query host of volume
+	QueryHostOfVolume(ctx context.Context, lunName string) ([]map[string]interface{}, error)
+}
+
 // GetHostByName used to get host by name
 func (cli *Client) GetHostByName(ctx context.Context, hostName string) (map[string]interface{}, error) {
 	data := map[string]interface{}{
@@ -29,6 +29,16 @@ const (
 	initiatorNotExist     int64 = 50155103
 )
 
+// Iscsi defines interfaces for iscsi operations
+type Iscsi interface {
+	// GetInitiatorByName used to get initiator by name
+	GetInitiatorByName(ctx context.Context, name string) (map[string]interface{}, error)
+	// CreateInitiator used to create initiator by name
+	CreateInitiator(ctx context.Context, name string) error
+	// QueryIscsiPortal used to query iscsi portal
+	QueryIscsiPortal(ctx context.Context) ([]map[string]interface{}, error)
+}
+
 // GetInitiatorByName used to get initiator by name
 func (cli *Client) GetInitiatorByName(ctx context.Context, name string) (map[string]interface{}, error) {
 	data := map[string]interface{}{
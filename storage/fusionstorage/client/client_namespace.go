@@ -33,6 +33,30 @@ const (
 	notForbidden       int   = 0
 )
 
+// Namespace defines interfaces for namespace operations
+type Namespace interface {
+	// CreateFileSystem used to create file system by params
+	CreateFileSystem(ctx context.Context, params map[string]interface{}) (map[string]interface{}, error)
+	// DeleteFileSystem used to delete file system by id
+	DeleteFileSystem(ctx context.Context, id string) error
+	// GetFileSystemByName used to get file system by name
+	GetFileSystemByName(ctx context.Context, name string) (map[string]interface{}, error)
+	// CreateNfsShare used to create nfs share by params
+	CreateNfsShare(ctx context.Context, params map[string]interface{}) (map[string]interface{}, error)
+	// DeleteNfsShare used to delete nfs share by id
+	DeleteNfsShare(ctx context.Context, id, accountId string) error
+	// GetNfsShareByPath used to get nfs share by path
+	GetNfsShareByPath(ctx context.Context, path, accountId string) (map[string]interface{}, error)
+	// AllowNfsShareAccess used for create nfs share client
+	AllowNfsShareAccess(ctx context.Context, req *AllowNfsShareAccessRequest) error
+	// DeleteNfsShareAccess used to delete nfs share access by id
+	DeleteNfsShareAccess(ctx context.Context, accessID string) error
+	// GetNfsShareAccess used to get nfs share access by id
+	GetNfsShareAccess(ctx context.Context, shareID string) (map[string]interface{}, error)
+	// GetQuotaByFileSystemName query quota info by file system name
+	GetQuotaByFileSystemName(ctx context.Context, fsName string) (map[string]interface{}, error)
+}
+
 // CreateFileSystem used to create file system by params
 func (cli *Client) CreateFileSystem(ctx context.Context, params map[string]interface{}) (map[string]interface{}, error) {
 	data := map[string]interface{}{
@@ -217,41 +241,14 @@ func (cli *Client) GetNfsShareByPath(ctx context.Context, path, accountId string
 
 	sharePath := fusionURL.QueryEscape(fmt.Sprintf("%s", bytesPath))
 	url := fmt.Sprintf("/api/v2/nas_protocol/nfs_share_list?account_id=%s&filter=%s", accountId, sharePath)
-	resp, err := cli.get(ctx, url, nil)
+	shares, err := cli.getPagedData(ctx, url, 0)
 	if err != nil {
+		log.AddContext(ctx).Errorf("Get NFS share path %s error: %v", path, err)
 		return nil, err
 	}
 
-	result, ok := resp["result"].(map[string]interface{})
-	if !ok {
-		msg := fmt.Sprintf("The result of response %v's format is not map[string]interface{}", resp)
-		log.AddContext(ctx).Errorln(msg)
-		return nil, errors.New(msg)
-	}
-
-	errorCode := int64(result["code"].(float64))
-	if errorCode != 0 {
-		msg := fmt.Sprintf("Get NFS share path %s error: %d", path, errorCode)
-		log.AddContext(ctx).Errorln(msg)
-		return nil, errors.New(msg)
-	}
-
-	respData, ok := resp["data"].([]interface{})
-	if !ok {
-		msg := fmt.Sprintf("There is no data info in response %v.", resp)
-		log.AddContext(ctx).Errorln(msg)
-		return nil, errors.New(msg)
-	}
-
-	for _, s := range respData {
-		share, ok := s.(map[string]interface{})
-		if !ok {
-			msg := fmt.Sprintf("The result of response %v's format is not map[string]interface{}", resp)
-			log.AddContext(ctx).Errorln(msg)
-			return nil, errors.New(msg)
-		}
-
-		if share["share_path"].(string) == path {
+	for _, share := range shares {
+		if sharePathOfList, _ := share["share_path"].(string); sharePathOfList == path {
 			return share, nil
 		}
 	}
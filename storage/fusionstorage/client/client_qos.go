@@ -28,6 +28,36 @@ import (
 	"huawei-csi-driver/utils/log"
 )
 
+// Qos defines interfaces for qos operations
+type Qos interface {
+	// GetConvergedQoSNameByID used to get qos name by id
+	GetConvergedQoSNameByID(ctx context.Context, qosId int) (string, error)
+	// CreateConvergedQoS used to create converged QoS
+	CreateConvergedQoS(ctx context.Context, req *types.CreateConvergedQoSReq) (int, error)
+	// DeleteConvergedQoS used to delete converged QoS by name
+	DeleteConvergedQoS(ctx context.Context, qosName string) error
+	// CreateQoS used to create QoS
+	CreateQoS(ctx context.Context, qosName string, qosData map[string]int) error
+	// DeleteQoS used to delete QoS by name
+	DeleteQoS(ctx context.Context, qosName string) error
+	// DisassociateConvergedQoSWithVolume used to delete a converged QoS policy association
+	DisassociateConvergedQoSWithVolume(ctx context.Context, objectName string) error
+	// AssociateConvergedQoSWithVolume used to add a converged QoS policy association
+	AssociateConvergedQoSWithVolume(ctx context.Context, req *types.AssociateConvergedQoSWithVolumeReq) error
+	// AssociateQoSWithVolume used to associate QoS with volume
+	AssociateQoSWithVolume(ctx context.Context, volName, qosName string) error
+	// DisassociateQoSWithVolume used to disassociate QoS with volume
+	DisassociateQoSWithVolume(ctx context.Context, volName, qosName string) error
+	// GetQoSPolicyAssociationCount used to get count of qos association
+	GetQoSPolicyAssociationCount(ctx context.Context, qosPolicyId int) (int, error)
+	// GetQoSPolicyIdByFsName used to get qos id by fs name
+	GetQoSPolicyIdByFsName(ctx context.Context, namespaceName string) (int, error)
+	// GetQoSNameByVolume used to get QoS name by volume name
+	GetQoSNameByVolume(ctx context.Context, volName string) (string, error)
+	// GetAssociateCountOfQoS used to get associate count of QoS
+	GetAssociateCountOfQoS(ctx context.Context, qosName string) (int, error)
+}
+
 // GetConvergedQoSNameByID used to get qos name by id
 func (cli *Client) GetConvergedQoSNameByID(ctx context.Context, qosId int) (string, error) {
 	url := fmt.Sprintf("/api/v2/dros_service/converged_qos_policy?qos_scale=%d&id=%d",
@@ -246,31 +276,15 @@ func (cli *Client) GetQoSPolicyAssociationCount(ctx context.Context, qosPolicyId
 
 // GetQoSPolicyIdByFsName used to get qos id by fs name
 func (cli *Client) GetQoSPolicyIdByFsName(ctx context.Context, namespaceName string) (int, error) {
-	rangeRaw := "{\"offset\":0,\"limit\":100}"
 	filterRaw := fmt.Sprintf("{\"object_name\":\"%s\",\"qos_scale\":\"0\",\"account_id\":\"%d\"}",
 		namespaceName, cli.accountId)
-	url := fmt.Sprintf("/api/v2/dros_service/converged_qos_association?range=%s&filter=%s",
-		netUrl.QueryEscape(rangeRaw), netUrl.QueryEscape(filterRaw))
-	resp, err := cli.get(ctx, url, nil)
-	if err != nil {
-		return types.NoQoSPolicyId, err
-	}
-
-	err = utils.CheckErrorCode(resp)
+	url := fmt.Sprintf("/api/v2/dros_service/converged_qos_association?filter=%s", netUrl.QueryEscape(filterRaw))
+	dataList, err := cli.getPagedData(ctx, url, 0)
 	if err != nil {
 		return types.NoQoSPolicyId, err
 	}
 
-	dataListInterfaces, ok := resp["data"].([]interface{})
-	if !ok {
-		return types.NoQoSPolicyId, fmt.Errorf("convert data [%v] to []map[string]interface{} failed", resp["data"])
-	}
-	for _, dataListInterface := range dataListInterfaces {
-		data, ok := dataListInterface.(map[string]interface{})
-		if !ok {
-			return types.NoQoSPolicyId, pkgUtils.Errorln(ctx, fmt.Sprintf("convert dataListInterface: [%v] to "+
-				"map[string]interface{} failed.", dataListInterface))
-		}
+	for _, data := range dataList {
 		if data["object_name"] == namespaceName {
 			qosPolicyId, ok := data["qos_policy_id"].(float64)
 			if !ok {
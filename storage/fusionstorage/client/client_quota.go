@@ -28,6 +28,18 @@ const (
 	quotaNotExist int64 = 37767685
 )
 
+// Quota defines interfaces for quota operations
+type Quota interface {
+	// CreateQuota creates quota by params
+	CreateQuota(ctx context.Context, params map[string]interface{}) error
+	// UpdateQuota updates quota by params
+	UpdateQuota(ctx context.Context, params map[string]interface{}) error
+	// GetQuotaByFileSystemById query quota info by file system id
+	GetQuotaByFileSystemById(ctx context.Context, fsID string) (map[string]interface{}, error)
+	// DeleteQuota deletes quota by id
+	DeleteQuota(ctx context.Context, quotaID string) error
+}
+
 // CreateQuota creates quota by params
 func (cli *Client) CreateQuota(ctx context.Context, params map[string]interface{}) error {
 	resp, err := cli.post(ctx, "/api/v2/file_service/fs_quota", params)
@@ -74,39 +86,16 @@ func (cli *Client) UpdateQuota(ctx context.Context, params map[string]interface{
 
 // GetQuotaByFileSystemById query quota info by file system id
 func (cli *Client) GetQuotaByFileSystemById(ctx context.Context, fsID string) (map[string]interface{}, error) {
-	url := "/api/v2/file_service/fs_quota?parent_type=40&parent_id=" +
-		fsID + "&range=%7B%22offset%22%3A0%2C%22limit%22%3A100%7D"
-	resp, err := cli.get(ctx, url, nil)
+	url := "/api/v2/file_service/fs_quota?parent_type=40&parent_id=" + fsID
+	fsQuotas, err := cli.getPagedData(ctx, url, 1)
 	if err != nil {
-		return nil, err
-	}
-
-	result, ok := resp["result"].(map[string]interface{})
-	if !ok {
-		msg := fmt.Sprintf("The result of response %v's format is not map[string]interface{}", resp)
-		log.AddContext(ctx).Errorln(msg)
-		return nil, errors.New(msg)
-	}
-	errorCode := int64(result["code"].(float64))
-	if errorCode != 0 {
-		return nil, fmt.Errorf("get quota by filesystem id %s error: %d", fsID, errorCode)
+		return nil, fmt.Errorf("get quota by filesystem id %s error: %v", fsID, err)
 	}
 
-	fsQuotas, exist := resp["data"].([]interface{})
-	if !exist || len(fsQuotas) <= 0 {
+	if len(fsQuotas) == 0 {
 		return nil, nil
 	}
-
-	for _, q := range fsQuotas {
-		quota, ok := q.(map[string]interface{})
-		if !ok {
-			msg := fmt.Sprintf("The fsQuota %v's format is not map[string]interface{}", q)
-			log.AddContext(ctx).Errorln(msg)
-			return nil, errors.New(msg)
-		}
-		return quota, nil
-	}
-	return nil, nil
+	return fsQuotas[0], nil
 }
 
 // DeleteQuota deletes quota by id
@@ -27,6 +27,18 @@ const (
 	snapshotNotExist int64 = 50150006
 )
 
+// Snapshot defines interfaces for snapshot operations
+type Snapshot interface {
+	// CreateSnapshot creates volume snapshot
+	CreateSnapshot(ctx context.Context, snapshotName, volName string) error
+	// DeleteSnapshot deletes volume snapshot
+	DeleteSnapshot(ctx context.Context, snapshotName string) error
+	// GetSnapshotByName get snapshot by name
+	GetSnapshotByName(ctx context.Context, snapshotName string) (map[string]interface{}, error)
+	// CreateVolumeFromSnapshot creates volume from snapshot
+	CreateVolumeFromSnapshot(ctx context.Context, volName string, volSize int64, snapshotName string) error
+}
+
 // CreateSnapshot creates volume snapshot
 func (cli *Client) CreateSnapshot(ctx context.Context, snapshotName, volName string) error {
 	data := map[string]interface{}{
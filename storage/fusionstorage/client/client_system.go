@@ -27,6 +27,22 @@ import (
 	"huawei-csi-driver/utils/log"
 )
 
+// System defines interfaces for system operations
+type System interface {
+	// GetAccountIdByName gets account id by account name
+	GetAccountIdByName(ctx context.Context, accountName string) (string, error)
+	// GetPoolByName gets pool by pool name
+	GetPoolByName(ctx context.Context, poolName string) (map[string]interface{}, error)
+	// GetPoolById gets pool by pool id
+	GetPoolById(ctx context.Context, poolId int64) (map[string]interface{}, error)
+	// GetAllAccounts gets all accounts
+	GetAllAccounts(ctx context.Context) ([]string, error)
+	// GetAllPools gets all pools
+	GetAllPools(ctx context.Context) (map[string]interface{}, error)
+	// GetNFSServiceSetting gets nfs service settings
+	GetNFSServiceSetting(ctx context.Context) (map[string]bool, error)
+}
+
 // GetAccountIdByName gets account id by account name
 func (cli *Client) GetAccountIdByName(ctx context.Context, accountName string) (string, error) {
 	url := fmt.Sprintf("/dfv/service/obsPOE/query_accounts?name=%s", accountName)
@@ -18,6 +18,10 @@ package client
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/prashantv/gostub"
@@ -101,3 +105,54 @@ func TestGetErrorCode(t *testing.T) {
 		convey.So(err, convey.ShouldBeError)
 	})
 }
+
+func TestGetPagedData(t *testing.T) {
+	tests := []struct {
+		name      string
+		total     int
+		limit     int
+		wantCount int
+		wantCalls int
+	}{
+		{"Empty", 0, 0, 0, 1},
+		{"OnePage", 100, 0, 100, 2},
+		{"OneMoreThanPage", 101, 0, 101, 2},
+		{"SeveralPages", 250, 0, 250, 3},
+		{"LimitOnPageBoundary", 250, 100, 100, 1},
+		{"LimitInsidePage", 250, 150, 150, 2},
+		{"LimitOverTotal", 101, 150, 101, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				var pageRange struct{ Offset, Limit int }
+				if err := json.Unmarshal([]byte(r.URL.Query().Get("range")), &pageRange); err != nil {
+					t.Errorf("unmarshal range %s error: %v", r.URL.Query().Get("range"), err)
+				}
+
+				data := []map[string]interface{}{}
+				for i := pageRange.Offset; i < tt.total && i < pageRange.Offset+pageRange.Limit; i++ {
+					data = append(data, map[string]interface{}{"id": fmt.Sprint(i)})
+				}
+				resp, _ := json.Marshal(map[string]interface{}{"result": map[string]interface{}{"code": 0},
+					"data": data})
+				w.Write(resp)
+			}))
+			defer server.Close()
+
+			cli := &Client{url: server.URL, client: server.Client()}
+			got, err := cli.getPagedData(context.Background(), "/api/v2/file_service/fs_quota?parent_type=40",
+				tt.limit)
+			if err != nil {
+				t.Fatalf("getPagedData() error = %v", err)
+			}
+			if len(got) != tt.wantCount || calls != tt.wantCalls {
+				t.Errorf("getPagedData() got %d items with %d calls, want %d items with %d calls",
+					len(got), calls, tt.wantCount, tt.wantCalls)
+			}
+		})
+	}
+}
@@ -31,6 +31,24 @@ const (
 	queryVolumeNotExist  int64 = 31000000
 )
 
+// Volume defines interfaces for volume operations
+type Volume interface {
+	// CreateVolume creates volume by params
+	CreateVolume(ctx context.Context, params map[string]interface{}) error
+	// GetVolumeByName gets volume info by name
+	GetVolumeByName(ctx context.Context, name string) (map[string]interface{}, error)
+	// DeleteVolume deletes volume by name
+	DeleteVolume(ctx context.Context, name string) error
+	// AttachVolume attaches volume target ip
+	AttachVolume(ctx context.Context, name, ip string) error
+	// DetachVolume detaches volume from target ip
+	DetachVolume(ctx context.Context, name, ip string) error
+	// ExtendVolume extends volume capacity
+	ExtendVolume(ctx context.Context, lunName string, newCapacity int64) error
+	// GetHostLunId gets host lun id of hostName
+	GetHostLunId(ctx context.Context, hostName, lunName string) (string, error)
+}
+
 // CreateVolume creates volume by params
 func (cli *Client) CreateVolume(ctx context.Context, params map[string]interface{}) error {
 	data := map[string]interface{}{
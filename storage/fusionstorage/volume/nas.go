@@ -27,6 +27,7 @@ import (
 	"strings"
 	"time"
 
+	"huawei-csi-driver/pkg/constants"
 	pkgUtils "huawei-csi-driver/pkg/utils"
 	"huawei-csi-driver/storage/fusionstorage/client"
 	"huawei-csi-driver/storage/fusionstorage/smartx"
@@ -61,11 +62,11 @@ const (
 
 // NAS provides nas storage client
 type NAS struct {
-	cli *client.Client
+	cli client.BaseClientInterface
 }
 
 // NewNAS inits a new nas client
-func NewNAS(cli *client.Client) *NAS {
+func NewNAS(cli client.BaseClientInterface) *NAS {
 	return &NAS{
 		cli: cli,
 	}
@@ -345,13 +346,14 @@ func (p *NAS) createFS(ctx context.Context,
 		return nil, err
 	}
 
-	err = p.waitFilesystemCreated(ctx, fsName)
+	fsID := strconv.FormatInt(int64(fs["id"].(float64)), 10)
+	err = p.waitFilesystemCreated(ctx, fsID, fsName)
 	if err != nil {
 		return nil, err
 	}
 
 	return map[string]interface{}{
-		"fsID":   strconv.FormatInt(int64(fs["id"].(float64)), 10),
+		"fsID":   fsID,
 		"fsName": fsName,
 	}, nil
 }
@@ -600,8 +602,8 @@ func (p *NAS) createShare(ctx context.Context,
 	}, nil
 }
 
-func (p *NAS) waitFilesystemCreated(ctx context.Context, fsName string) error {
-	err := utils.WaitUntil(func() (bool, error) {
+func (p *NAS) waitFilesystemCreated(ctx context.Context, fsID, fsName string) error {
+	err := utils.WaitArrayTask(ctx, "FileSystemCreation", fsID, func() (bool, error) {
 		fs, err := p.cli.GetFileSystemByName(ctx, fsName)
 		if err != nil {
 			return false, err
@@ -724,6 +726,67 @@ func (p *NAS) DeleteNfsShare(ctx context.Context, fsName, accountId string) (str
 	return fsIdInShare, nil
 }
 
+// DeleteShare deletes the nfs share of the filesystem and keeps the filesystem and its data
+func (p *NAS) DeleteShare(ctx context.Context, fsName string) error {
+	fs, err := p.cli.GetFileSystemByName(ctx, fsName)
+	if err != nil {
+		log.AddContext(ctx).Errorf("Get filesystem %s error: %v", fsName, err)
+		return err
+	}
+	if fs == nil {
+		log.AddContext(ctx).Infof("Filesystem %s of the share to delete does not exist", fsName)
+		return nil
+	}
+
+	accountId, ok := fs["account_id"].(string)
+	if !ok {
+		return pkgUtils.Errorf(ctx, "convert accountID to string failed, data: %v", fs["account_id"])
+	}
+	_, err = p.DeleteNfsShare(ctx, fsName, accountId)
+	return err
+}
+
+// RestoreShare recreates the deleted nfs share of the filesystem and allows the access of the clients
+func (p *NAS) RestoreShare(ctx context.Context, params map[string]interface{}) error {
+	fsName, ok := params["name"].(string)
+	if !ok {
+		return pkgUtils.Errorf(ctx, "convert fsName to string failed, data: %v", params["name"])
+	}
+
+	if err := p.checkAuthclient(ctx, params); err != nil {
+		return err
+	}
+
+	fs, err := p.cli.GetFileSystemByName(ctx, fsName)
+	if err != nil {
+		log.AddContext(ctx).Errorf("Get filesystem %s error: %v", fsName, err)
+		return err
+	}
+	if fs == nil {
+		return fmt.Errorf("%w: filesystem %s of the share to restore does not exist", constants.ErrVolumeNotFound,
+			fsName)
+	}
+
+	if params["accountid"], ok = fs["account_id"].(string); !ok {
+		return pkgUtils.Errorf(ctx, "convert accountID to string failed, data: %v", fs["account_id"])
+	}
+
+	if err = p.preProcessSquash(ctx, params); err != nil {
+		return err
+	}
+
+	taskResult := map[string]interface{}{
+		"fsID": strconv.FormatInt(int64(fs["id"].(float64)), 10),
+	}
+	shareResult, err := p.createShare(ctx, params, taskResult)
+	if err != nil {
+		return err
+	}
+
+	_, err = p.allowShareAccess(ctx, params, shareResult)
+	return err
+}
+
 // Delete deletes volume by name
 func (p *NAS) Delete(ctx context.Context, fsName string) error {
 	fs, err := p.cli.GetFileSystemByName(ctx, fsName)
@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"strconv"
 
+	"huawei-csi-driver/pkg/constants"
 	pkgUtils "huawei-csi-driver/pkg/utils"
 	"huawei-csi-driver/storage/fusionstorage/client"
 	"huawei-csi-driver/storage/fusionstorage/smartx"
@@ -40,11 +41,11 @@ const (
 
 // SAN provides san storage client
 type SAN struct {
-	cli *client.Client
+	cli client.BaseClientInterface
 }
 
 // NewSAN inits a new san client
-func NewSAN(cli *client.Client) *SAN {
+func NewSAN(cli client.BaseClientInterface) *SAN {
 	return &SAN{
 		cli: cli,
 	}
@@ -346,9 +347,8 @@ func (p *SAN) Expand(ctx context.Context, name string, newSize int64) (bool, err
 		return false, err
 	}
 	if lun == nil {
-		msg := fmt.Sprintf("Lun %s to expand does not exist", name)
-		log.AddContext(ctx).Errorf(msg)
-		return false, errors.New(msg)
+		log.AddContext(ctx).Errorf("Lun %s to expand does not exist", name)
+		return false, fmt.Errorf("%w: lun %s to expand does not exist", constants.ErrVolumeNotFound, name)
 	}
 
 	isAttached := int64(lun["volType"].(float64)) == SCSITYPE || int64(lun["volType"].(float64)) == ISCSITYPE
@@ -417,9 +417,9 @@ func (p *SAN) CreateSnapshot(ctx context.Context,
 		log.AddContext(ctx).Errorf("Get lun by name %s error: %v", lunName, err)
 		return nil, err
 	} else if lun == nil {
-		msg := fmt.Sprintf("Create snapshot from Lun %s does not exist", lunName)
-		log.AddContext(ctx).Errorln(msg)
-		return nil, errors.New(msg)
+		log.AddContext(ctx).Errorf("Create snapshot from Lun %s does not exist", lunName)
+		return nil, fmt.Errorf("%w: lun %s to create snapshot does not exist", constants.ErrVolumeNotFound,
+			lunName)
 	}
 
 	snapshot, err := p.cli.GetSnapshotByName(ctx, snapshotName)
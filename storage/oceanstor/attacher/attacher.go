@@ -19,12 +19,15 @@ package attacher
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"net"
 	"strings"
 
 	"huawei-csi-driver/connector/nvme"
+	"huawei-csi-driver/pkg/constants"
 	pkgUtils "huawei-csi-driver/pkg/utils"
 	"huawei-csi-driver/storage/oceanstor/client"
 	"huawei-csi-driver/utils"
@@ -34,23 +37,48 @@ import (
 const (
 	hostGroupType = 14
 	lunGroupType  = 256
+
+	defaultHostNamePrefix = "k8s_"
+
+	// ownHostDescription is the description of the hosts created by the driver, which tells them apart from the
+	// hosts created by others whatever their names are
+	ownHostDescription = "Created by huawei-csi"
+
+	// hostNameHashLength is the length of the hash which replaces the cut part of a long host name
+	hostNameHashLength = 8
+
+	// MaxHostNameLength is the max length of the host name on the storage
+	MaxHostNameLength = 31
+
+	// MaxHostNamePrefixLength is the max length of the host name prefix, so that a long host name still keeps
+	// a part of the node name besides the hash
+	MaxHostNamePrefixLength = 16
 )
 
 // AttacherPlugin defines interfaces of attach operations
 type AttacherPlugin interface {
 	ControllerAttach(context.Context, string, map[string]interface{}) (map[string]interface{}, error)
 	ControllerDetach(context.Context, string, map[string]interface{}) (string, error)
+	ControllerDeleteHost(context.Context, map[string]interface{}) error
+	IsMapped(context.Context, string, map[string]interface{}) (bool, error)
 	getTargetRoCEPortals(context.Context) ([]string, error)
 	getLunInfo(context.Context, string) (map[string]interface{}, error)
 }
 
+// HostPolicy defines how the hosts on the storage are named
+type HostPolicy struct {
+	// NamePrefix is the prefix of the host names created by the driver, "k8s_" is used if it is empty
+	NamePrefix string
+}
+
 // Attacher defines attacher to attach volume
 type Attacher struct {
-	cli      client.BaseClientInterface
-	protocol string
-	invoker  string
-	portals  []string
-	alua     map[string]interface{}
+	cli        client.BaseClientInterface
+	protocol   string
+	invoker    string
+	portals    []string
+	alua       map[string]interface{}
+	hostPolicy HostPolicy
 }
 
 // NewAttacher init a new attacher
@@ -59,22 +87,70 @@ func NewAttacher(
 	cli client.BaseClientInterface,
 	protocol, invoker string,
 	portals []string,
-	alua map[string]interface{}) AttacherPlugin {
+	alua map[string]interface{},
+	hostPolicy HostPolicy) AttacherPlugin {
 	switch product {
 	case "DoradoV6":
-		return newDoradoV6Attacher(cli, protocol, invoker, portals, alua)
+		return newDoradoV6Attacher(cli, protocol, invoker, portals, alua, hostPolicy)
 	default:
-		return newOceanStorAttacher(cli, protocol, invoker, portals, alua)
+		return newOceanStorAttacher(cli, protocol, invoker, portals, alua, hostPolicy)
 	}
 }
 
+func (p *Attacher) getHostNamePrefix() string {
+	if p.hostPolicy.NamePrefix == "" {
+		return defaultHostNamePrefix
+	}
+
+	return p.hostPolicy.NamePrefix
+}
+
+// getHostName returns the name of the host of the node. The name longer than the storage allows is cut and ended
+// with the hash of the node name, so that the nodes whose names only differ in the cut part get different hosts.
 func (p *Attacher) getHostName(postfix string) string {
-	host := fmt.Sprintf("k8s_%s", postfix)
-	if len(host) <= 31 {
+	prefix := p.getHostNamePrefix()
+	host := prefix + postfix
+	if len(host) <= MaxHostNameLength {
+		return host
+	}
+
+	sum := sha256.Sum256([]byte(postfix))
+	hash := hex.EncodeToString(sum[:])[:hostNameHashLength]
+	keep := MaxHostNameLength - len(prefix) - len(hash) - 1
+	if keep < 0 {
+		keep = 0
+	}
+	return prefix + postfix[:keep] + "_" + hash
+}
+
+// getLegacyHostName returns the name of the host of the node created before the prefix is configurable and the
+// long names are hashed
+func getLegacyHostName(postfix string) string {
+	host := defaultHostNamePrefix + postfix
+	if len(host) <= MaxHostNameLength {
 		return host
 	}
 
-	return host[:31]
+	return host[:MaxHostNameLength]
+}
+
+// isOwnHost checks whether the host is created by the driver for the node. The hosts created by the driver carry
+// its description, and the hosts created by the earlier versions are known by their exact names, so that the
+// existing hosts are still used after the prefix is changed.
+func (p *Attacher) isOwnHost(host map[string]interface{}, postfix string) bool {
+	if description, ok := host["DESCRIPTION"].(string); ok && description == ownHostDescription {
+		return true
+	}
+
+	name, ok := host["NAME"].(string)
+	return ok && (name == p.getHostName(postfix) || name == getLegacyHostName(postfix))
+}
+
+// getHostAlua returns the ALUA configuration of the host, only the hosts created by the driver are attached to,
+// so the configuration is always applied to the host
+func (p *Attacher) getHostAlua(ctx context.Context, host map[string]interface{}) map[string]interface{} {
+	name, _ := host["NAME"].(string)
+	return utils.GetAlua(ctx, p.alua, name)
 }
 
 func (p *Attacher) getHostGroupName(postfix string) string {
@@ -89,40 +165,166 @@ func (p *Attacher) getMappingName(postfix string) string {
 	return fmt.Sprintf("k8s_%s_mapping_%s", p.invoker, postfix)
 }
 
+// getHost returns the host of the node created by the driver. If toCreate is true, the host is created when it
+// does not exist, so that a host lost on the storage, e.g. after the configuration of the storage is reset, is
+// recreated by the next attach together with its initiators, host group and mapping.
 func (p *Attacher) getHost(ctx context.Context,
 	parameters map[string]interface{},
 	toCreate bool) (map[string]interface{}, error) {
-	var err error
-
 	hostname, exist := parameters["HostName"].(string)
 	if !exist {
-		log.AddContext(ctx).Errorf("Get hostname error: %v", err)
-		return nil, err
+		log.AddContext(ctx).Errorf("Get hostname from %v failed", parameters)
+		return nil, errors.New("hostname of the node is not given")
+	}
+
+	// the legacy name is only queried when it is not cut, since the cut names of different nodes may collide,
+	// the hosts with the cut names are found through the initiators of the node instead
+	hostsToQuery := []string{p.getHostName(hostname)}
+	if legacy := getLegacyHostName(hostname); legacy == defaultHostNamePrefix+hostname && legacy != hostsToQuery[0] {
+		hostsToQuery = append(hostsToQuery, legacy)
 	}
 
-	hostToQuery := p.getHostName(hostname)
-	host, err := p.cli.GetHostByName(ctx, hostToQuery)
+	for _, hostToQuery := range hostsToQuery {
+		host, err := p.cli.GetHostByName(ctx, hostToQuery)
+		if err != nil {
+			log.AddContext(ctx).Errorf("Get host %s error: %v", hostToQuery, err)
+			return nil, err
+		}
+		if host != nil {
+			return host, nil
+		}
+	}
+
+	if !toCreate {
+		return nil, nil
+	}
+
+	hostToCreate := p.getHostName(hostname)
+	host, err := p.cli.CreateHost(ctx, hostToCreate, ownHostDescription)
 	if err != nil {
-		log.AddContext(ctx).Errorf("Get host %s error: %v", hostToQuery, err)
+		log.AddContext(ctx).Errorf("Create host %s error: %v", hostToCreate, err)
 		return nil, err
 	}
-	if host == nil && toCreate {
-		host, err = p.cli.CreateHost(ctx, hostToQuery)
+	if host == nil {
+		return nil, fmt.Errorf("cannot create host %s", hostToCreate)
+	}
+
+	return host, nil
+}
+
+// getAttachHost returns the host which the luns of the node are mapped to. The host recorded when the volume is
+// attached is used if it is given, otherwise the host created by the driver which the initiators of the node
+// already belong to is preferred, whatever its name is. The hosts which are not created by the driver are never
+// used, because mapping luns to them changes their host groups and mapping views.
+func (p *Attacher) getAttachHost(ctx context.Context,
+	parameters map[string]interface{},
+	toCreate bool) (map[string]interface{}, error) {
+	hostname, _ := parameters["HostName"].(string)
+	if hostID, ok := parameters["HostID"].(string); ok && hostID != "" {
+		host, err := p.cli.GetHostByID(ctx, hostID)
 		if err != nil {
-			log.AddContext(ctx).Errorf("Create host %s error: %v", hostToQuery, err)
+			log.AddContext(ctx).Errorf("Get recorded host %s error: %v", hostID, err)
 			return nil, err
 		}
+		if host != nil && !p.isOwnHost(host, hostname) {
+			log.AddContext(ctx).Warningf("Recorded host %s of %s is not created by the driver any more, "+
+				"ignore it", hostID, hostname)
+			return nil, nil
+		}
+		return host, nil
 	}
 
-	if host != nil {
-		return host, nil
+	parents, err := p.getInitiatorParents(ctx, parameters)
+	if err != nil && toCreate {
+		return nil, err
+	}
+	if err != nil {
+		log.AddContext(ctx).Warningf("Get initiators of %s error: %v, find its host by name", hostname, err)
 	}
 
-	if toCreate {
-		return nil, fmt.Errorf("cannot create host %s", hostToQuery)
+	for _, parent := range parents {
+		host, err := p.cli.GetHostByID(ctx, parent)
+		if err != nil {
+			log.AddContext(ctx).Errorf("Get host %s error: %v", parent, err)
+			return nil, err
+		}
+
+		if host != nil && p.isOwnHost(host, hostname) {
+			return host, nil
+		}
+	}
+
+	return p.getHost(ctx, parameters, toCreate)
+}
+
+// getInitiatorParents returns the IDs of the hosts which the initiators of the node belong to
+func (p *Attacher) getInitiatorParents(ctx context.Context, parameters map[string]interface{}) ([]string, error) {
+	var initiators []map[string]interface{}
+	switch p.protocol {
+	case "iscsi", "roce":
+		initiator, err := p.getSingleInitiator(ctx, parameters)
+		if err != nil {
+			return nil, err
+		}
+		initiators = append(initiators, initiator)
+	case "fc", "fc-nvme":
+		wwns, err := GetMultipleInitiators(ctx, FC, parameters)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, wwn := range wwns {
+			initiator, err := p.cli.GetFCInitiator(ctx, wwn)
+			if err != nil {
+				return nil, err
+			}
+			initiators = append(initiators, initiator)
+		}
 	}
 
-	return nil, nil
+	var parents []string
+	for _, initiator := range initiators {
+		if initiator == nil || initiator["ISFREE"] == "true" {
+			continue
+		}
+
+		if parent, ok := initiator["PARENTID"].(string); ok && parent != "" {
+			parents = append(parents, parent)
+		}
+	}
+
+	return parents, nil
+}
+
+func (p *Attacher) getSingleInitiator(ctx context.Context,
+	parameters map[string]interface{}) (map[string]interface{}, error) {
+	if p.protocol == "roce" {
+		name, err := GetSingleInitiator(ctx, ROCE, parameters)
+		if err != nil {
+			return nil, err
+		}
+		return p.cli.GetRoCEInitiator(ctx, name)
+	}
+
+	name, err := GetSingleInitiator(ctx, ISCSI, parameters)
+	if err != nil {
+		return nil, err
+	}
+	return p.cli.GetIscsiInitiator(ctx, name)
+}
+
+// initiatorConflictError returns the error which names the host that the initiator already belongs to
+func (p *Attacher) initiatorConflictError(ctx context.Context, initiatorType, initiator, parent string) error {
+	host, err := p.cli.GetHostByID(ctx, parent)
+	if err != nil || host == nil {
+		log.AddContext(ctx).Warningf("Get host %s which initiator %s belongs to failed, error: %v",
+			parent, initiator, err)
+		return fmt.Errorf("%s initiator %s is already associated to another host %s", initiatorType, initiator, parent)
+	}
+
+	return fmt.Errorf("%s initiator %s is already associated to host %v (ID %s) which is not created by the "+
+		"driver for this node, the driver never maps volumes to such hosts, remove the initiator from it to "+
+		"attach volumes through the driver", initiatorType, initiator, host["NAME"], parent)
 }
 
 func (p *Attacher) createMapping(ctx context.Context, hostID string) (string, error) {
@@ -618,9 +820,9 @@ func (p *Attacher) attachISCSI(ctx context.Context, hostID string, parameters ma
 			return nil, err
 		}
 	} else if parentExist && parent != hostID {
-		msg := fmt.Sprintf("ISCSI initiator %s is already associated to another host %s", name, parent)
-		log.AddContext(ctx).Errorln(msg)
-		return nil, errors.New(msg)
+		err := p.initiatorConflictError(ctx, "ISCSI", name, parent)
+		log.AddContext(ctx).Errorln(err)
+		return nil, err
 	}
 
 	return initiator, nil
@@ -665,9 +867,9 @@ func (p *Attacher) attachFC(ctx context.Context, hostID string, parameters map[s
 		if freeExist && isFree == "true" {
 			addWWNs = append(addWWNs, wwn)
 		} else if parentExist && parent != hostID {
-			msg := fmt.Sprintf("FC initiator %s is already associated to another host %s", wwn, parent)
-			log.AddContext(ctx).Errorln(msg)
-			return nil, errors.New(msg)
+			err := p.initiatorConflictError(ctx, "FC", wwn, parent)
+			log.AddContext(ctx).Errorln(err)
+			return nil, err
 		}
 
 		hostInitiators = append(hostInitiators, initiator)
@@ -720,9 +922,9 @@ func (p *Attacher) attachRoCE(ctx context.Context, hostID string, parameters map
 			return nil, err
 		}
 	} else if parentExist && parent != hostID {
-		msg := fmt.Sprintf("RoCE initiator %s is already associated to another host %s", name, parent)
-		log.AddContext(ctx).Errorln(msg)
-		return nil, errors.New(msg)
+		err := p.initiatorConflictError(ctx, "RoCE", name, parent)
+		log.AddContext(ctx).Errorln(err)
+		return nil, err
 	}
 
 	return initiator, nil
@@ -735,9 +937,8 @@ func (p *Attacher) doMapping(ctx context.Context, hostID, lunName string) (strin
 		return "", "", err
 	}
 	if lun == nil {
-		msg := fmt.Sprintf("Lun %s not exist for attaching", lunName)
-		log.AddContext(ctx).Errorln(msg)
-		return "", "", errors.New(msg)
+		log.AddContext(ctx).Errorf("Lun %s not exist for attaching", lunName)
+		return "", "", fmt.Errorf("%w: lun %s not exist for attaching", constants.ErrVolumeNotFound, lunName)
 	}
 
 	lunID, ok := lun["ID"].(string)
@@ -827,7 +1028,7 @@ func (p *Attacher) doUnmapping(ctx context.Context, hostID, lunName string) (str
 func (p *Attacher) ControllerDetach(ctx context.Context,
 	lunName string,
 	parameters map[string]interface{}) (string, error) {
-	host, err := p.getHost(ctx, parameters, false)
+	host, err := p.getAttachHost(ctx, parameters, false)
 	if err != nil {
 		log.AddContext(ctx).Infof("Get host ID error: %v", err)
 		return "", err
@@ -850,6 +1051,173 @@ func (p *Attacher) ControllerDetach(ctx context.Context,
 	return wwn, nil
 }
 
+// IsMapped checks whether the lun is still in the lun group of the host of the node, without creating anything,
+// so that a repeated attach can be answered without running the whole attach again
+func (p *Attacher) IsMapped(ctx context.Context, lunName string, parameters map[string]interface{}) (bool, error) {
+	host, err := p.getAttachHost(ctx, parameters, false)
+	if err != nil || host == nil {
+		return false, err
+	}
+
+	hostID, ok := host["ID"].(string)
+	if !ok {
+		return false, pkgUtils.Errorf(ctx, "convert hostID to string failed, data: %v", host["ID"])
+	}
+
+	lun, err := p.cli.GetLunByName(ctx, lunName)
+	if err != nil || lun == nil {
+		return false, err
+	}
+
+	lunID, ok := lun["ID"].(string)
+	if !ok {
+		return false, pkgUtils.Errorf(ctx, "convert lunID to string failed, data: %v", lun["ID"])
+	}
+
+	lunGroups, err := p.cli.QueryAssociateLunGroup(ctx, 11, lunID)
+	if err != nil {
+		return false, err
+	}
+
+	lunGroupName := p.getLunGroupName(hostID)
+	for _, i := range lunGroups {
+		group, ok := i.(map[string]interface{})
+		if ok && group["NAME"] == lunGroupName {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// ControllerDeleteHost deletes the host and the mapping, lun group and host group created for it,
+// the host is kept if any lun is still mapped to it
+func (p *Attacher) ControllerDeleteHost(ctx context.Context, parameters map[string]interface{}) error {
+	host, err := p.getAttachHost(ctx, parameters, false)
+	if err != nil {
+		return err
+	}
+	if host == nil {
+		log.AddContext(ctx).Infof("Host of %v doesn't exist, no need to delete", parameters["HostName"])
+		return nil
+	}
+
+	hostID, ok := host["ID"].(string)
+	if !ok {
+		return pkgUtils.Errorf(ctx, "convert hostID to string failed, data: %v", host["ID"])
+	}
+
+	lunCount, err := p.cli.GetLunCountOfHost(ctx, hostID)
+	if err != nil {
+		return err
+	}
+	if lunCount > 0 {
+		log.AddContext(ctx).Warningf("There are still %d luns mapped to host %s, skip deleting it", lunCount, hostID)
+		return nil
+	}
+
+	lunGroupID, err := p.getLunGroupOfHost(ctx, hostID)
+	if err != nil {
+		return err
+	}
+
+	hostGroup, err := p.cli.GetHostGroupByName(ctx, p.getHostGroupName(hostID))
+	if err != nil {
+		return err
+	}
+
+	var hostGroupID string
+	if hostGroup != nil {
+		if hostGroupID, ok = hostGroup["ID"].(string); !ok {
+			return pkgUtils.Errorf(ctx, "convert hostGroupID to string failed, data: %v", hostGroup["ID"])
+		}
+	}
+
+	if err = p.deleteMapping(ctx, hostID, lunGroupID, hostGroupID); err != nil {
+		return err
+	}
+
+	if lunGroupID != "" {
+		if err = p.cli.DeleteLunGroup(ctx, lunGroupID); err != nil {
+			log.AddContext(ctx).Errorf("Delete lun group %s of host %s error: %v", lunGroupID, hostID, err)
+			return err
+		}
+	}
+
+	if hostGroupID != "" {
+		if err = p.cli.RemoveHostFromGroup(ctx, hostID, hostGroupID); err != nil {
+			log.AddContext(ctx).Errorf("Remove host %s from group %s error: %v", hostID, hostGroupID, err)
+			return err
+		}
+
+		if err = p.cli.DeleteHostGroup(ctx, hostGroupID); err != nil {
+			log.AddContext(ctx).Errorf("Delete host group %s error: %v", hostGroupID, err)
+			return err
+		}
+	}
+
+	if err = p.cli.DeleteHost(ctx, hostID); err != nil {
+		log.AddContext(ctx).Errorf("Delete host %s error: %v", hostID, err)
+		return err
+	}
+
+	log.AddContext(ctx).Infof("Host %s of %v is deleted", hostID, parameters["HostName"])
+	return nil
+}
+
+// getLunGroupOfHost returns the id of the lun group of the host, empty if the lun group does not exist
+func (p *Attacher) getLunGroupOfHost(ctx context.Context, hostID string) (string, error) {
+	lunGroup, err := p.cli.GetLunGroupByName(ctx, p.getLunGroupName(hostID))
+	if err != nil || lunGroup == nil {
+		return "", err
+	}
+
+	lunGroupID, ok := lunGroup["ID"].(string)
+	if !ok {
+		return "", pkgUtils.Errorf(ctx, "convert lunGroupID to string failed, data: %v", lunGroup["ID"])
+	}
+
+	return lunGroupID, nil
+}
+
+func (p *Attacher) deleteMapping(ctx context.Context, hostID, lunGroupID, hostGroupID string) error {
+	mapping, err := p.cli.GetMappingByName(ctx, p.getMappingName(hostID))
+	if err != nil {
+		return err
+	}
+	if mapping == nil {
+		return nil
+	}
+
+	mappingID, ok := mapping["ID"].(string)
+	if !ok {
+		return pkgUtils.Errorf(ctx, "convert mappingID to string failed, data: %v", mapping["ID"])
+	}
+
+	if lunGroupID != "" {
+		err = p.cli.RemoveGroupFromMapping(ctx, lunGroupType, lunGroupID, mappingID)
+		if err != nil {
+			log.AddContext(ctx).Errorf("Remove lun group from mapping %s error: %v", mappingID, err)
+			return err
+		}
+	}
+
+	if hostGroupID != "" {
+		err = p.cli.RemoveGroupFromMapping(ctx, hostGroupType, hostGroupID, mappingID)
+		if err != nil {
+			log.AddContext(ctx).Errorf("Remove host group from mapping %s error: %v", mappingID, err)
+			return err
+		}
+	}
+
+	if err = p.cli.DeleteMapping(ctx, mappingID); err != nil {
+		log.AddContext(ctx).Errorf("Delete mapping %s error: %v", mappingID, err)
+		return err
+	}
+
+	return nil
+}
+
 func (p *Attacher) getLunInfo(ctx context.Context, lunName string) (map[string]interface{}, error) {
 	lun, err := p.cli.GetLunByName(ctx, lunName)
 	if err != nil {
@@ -0,0 +1,200 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package attacher
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/agiledragon/gomonkey/v2"
+
+	"huawei-csi-driver/connector/host"
+	"huawei-csi-driver/storage/oceanstor/client"
+)
+
+func TestGetHostNameWithPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  HostPolicy
+		postfix string
+		want    string
+	}{
+		{name: "DefaultPrefix", postfix: "node1", want: "k8s_node1"},
+		{name: "CustomPrefix", policy: HostPolicy{NamePrefix: "hw_"}, postfix: "node1", want: "hw_node1"},
+		{name: "LongNameIsHashed", policy: HostPolicy{NamePrefix: "huawei_csi_"},
+			postfix: "a-very-long-node-name-of-cluster", want: "huawei_csi_a-very-long_"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &Attacher{hostPolicy: tt.policy}
+			got := p.getHostName(tt.postfix)
+			if len(got) > MaxHostNameLength || !strings.HasPrefix(got, tt.want) {
+				t.Errorf("getHostName() = %v, want %v within %d characters", got, tt.want, MaxHostNameLength)
+			}
+		})
+	}
+}
+
+func TestGetHostNameOfLongNamesNotCollide(t *testing.T) {
+	p := &Attacher{}
+	first := p.getHostName("worker-node-of-the-production-cluster-1")
+	second := p.getHostName("worker-node-of-the-production-cluster-2")
+	if first == second {
+		t.Errorf("getHostName() of different nodes = %v, want different names", first)
+	}
+}
+
+func TestIsOwnHost(t *testing.T) {
+	tests := []struct {
+		name string
+		host map[string]interface{}
+		want bool
+	}{
+		{name: "CreatedByDriverWithOldPrefix",
+			host: map[string]interface{}{"NAME": "old_node1", "DESCRIPTION": ownHostDescription}, want: true},
+		{name: "CurrentName", host: map[string]interface{}{"NAME": "hw_node1"}, want: true},
+		{name: "LegacyName", host: map[string]interface{}{"NAME": "k8s_node1"}, want: true},
+		{name: "SamePrefixOfOtherNode", host: map[string]interface{}{"NAME": "hw_node2"}, want: false},
+		{name: "ForeignHost", host: map[string]interface{}{"NAME": "esxi_node1"}, want: false},
+	}
+
+	p := &Attacher{hostPolicy: HostPolicy{NamePrefix: "hw_"}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := p.isOwnHost(tt.host, "node1"); got != tt.want {
+				t.Errorf("isOwnHost() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+type fakeHostClient struct {
+	client.BaseClientInterface
+	hosts      map[string]map[string]interface{}
+	initiators map[string]map[string]interface{}
+	created    []string
+	updated    []string
+}
+
+func (c *fakeHostClient) GetHostByID(_ context.Context, id string) (map[string]interface{}, error) {
+	return c.hosts[id], nil
+}
+
+func (c *fakeHostClient) GetHostByName(_ context.Context, name string) (map[string]interface{}, error) {
+	for _, h := range c.hosts {
+		if h["NAME"] == name {
+			return h, nil
+		}
+	}
+	return nil, nil
+}
+
+func (c *fakeHostClient) CreateHost(_ context.Context, name, description string) (map[string]interface{}, error) {
+	c.created = append(c.created, name)
+	h := map[string]interface{}{"ID": "new", "NAME": name, "DESCRIPTION": description}
+	c.hosts["new"] = h
+	return h, nil
+}
+
+func (c *fakeHostClient) UpdateHost(_ context.Context, id string, _ map[string]interface{}) error {
+	c.updated = append(c.updated, id)
+	return nil
+}
+
+func (c *fakeHostClient) GetIscsiInitiator(_ context.Context, name string) (map[string]interface{}, error) {
+	return c.initiators[name], nil
+}
+
+func TestGetAttachHost(t *testing.T) {
+	stub := gomonkey.ApplyFunc(host.GetNodeHostInfosFromSecret,
+		func(_ context.Context, hostName string) (*host.NodeHostInfo, error) {
+			return &host.NodeHostInfo{HostName: hostName, IscsiInitiator: "iqn.node1"}, nil
+		})
+	defer stub.Reset()
+
+	legacyHost := map[string]interface{}{"ID": "1", "NAME": "k8s_node1"}
+	foreignHost := map[string]interface{}{"ID": "2", "NAME": "esxi_node1"}
+	tests := []struct {
+		name        string
+		hosts       []map[string]interface{}
+		parameters  map[string]interface{}
+		parent      string
+		toCreate    bool
+		wantID      string
+		wantCreated bool
+	}{
+		{name: "LegacyHostOfInitiatorIsUsedAfterPrefixChanged", hosts: []map[string]interface{}{legacyHost},
+			parent: "1", toCreate: true, wantID: "1"},
+		{name: "ForeignHostOfInitiatorIsNotUsed", hosts: []map[string]interface{}{foreignHost},
+			parent: "2", toCreate: true, wantID: "new", wantCreated: true},
+		{name: "RecordedHostIsUsedForDetach", hosts: []map[string]interface{}{legacyHost, foreignHost},
+			parameters: map[string]interface{}{"HostID": "1"}, parent: "2", wantID: "1"},
+		{name: "RecordedForeignHostIsIgnored", hosts: []map[string]interface{}{foreignHost},
+			parameters: map[string]interface{}{"HostID": "2"}, wantID: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cli := &fakeHostClient{
+				hosts: map[string]map[string]interface{}{},
+				initiators: map[string]map[string]interface{}{
+					"iqn.node1": {"ID": "iqn.node1", "ISFREE": "false", "PARENTID": tt.parent},
+				},
+			}
+			for _, h := range tt.hosts {
+				cli.hosts[h["ID"].(string)] = h
+			}
+			p := &Attacher{cli: cli, protocol: "iscsi", hostPolicy: HostPolicy{NamePrefix: "hw_"}}
+			parameters := map[string]interface{}{"HostName": "node1"}
+			for key, value := range tt.parameters {
+				parameters[key] = value
+			}
+
+			got, err := p.getAttachHost(context.Background(), parameters, tt.toCreate)
+			if err != nil {
+				t.Fatalf("getAttachHost() error = %v", err)
+			}
+
+			var gotID string
+			if got != nil {
+				gotID, _ = got["ID"].(string)
+			}
+			if gotID != tt.wantID {
+				t.Errorf("getAttachHost() = %v, want host %q", got, tt.wantID)
+			}
+			if (len(cli.created) != 0) != tt.wantCreated {
+				t.Errorf("getAttachHost() created hosts %v, want created %v", cli.created, tt.wantCreated)
+			}
+			if tt.wantCreated && cli.hosts["new"]["DESCRIPTION"] != ownHostDescription {
+				t.Errorf("created host %v, want description %q", cli.hosts["new"], ownHostDescription)
+			}
+		})
+	}
+}
+
+func TestGetRemoteParameters(t *testing.T) {
+	parameters := map[string]interface{}{"HostName": "node1", "HostID": "1", "RemoteHostID": "7"}
+	remote := getRemoteParameters(parameters)
+	if remote["HostID"] != "7" || remote["HostName"] != "node1" {
+		t.Errorf("getRemoteParameters() = %v, want the remote host recorded as HostID", remote)
+	}
+	if parameters["HostID"] != "1" {
+		t.Errorf("getRemoteParameters() changed the local parameters to %v", parameters)
+	}
+}
@@ -21,7 +21,6 @@ import (
 	"errors"
 
 	"huawei-csi-driver/storage/oceanstor/client"
-	"huawei-csi-driver/utils"
 	"huawei-csi-driver/utils/log"
 )
 
@@ -39,14 +38,16 @@ func newDoradoV6Attacher(
 	cli client.BaseClientInterface,
 	protocol, invoker string,
 	portals []string,
-	alua map[string]interface{}) AttacherPlugin {
+	alua map[string]interface{},
+	hostPolicy HostPolicy) AttacherPlugin {
 	return &DoradoV6Attacher{
 		Attacher: Attacher{
-			cli:      cli,
-			protocol: protocol,
-			invoker:  invoker,
-			portals:  portals,
-			alua:     alua,
+			cli:        cli,
+			protocol:   protocol,
+			invoker:    invoker,
+			portals:    portals,
+			alua:       alua,
+			hostPolicy: hostPolicy,
 		},
 	}
 }
@@ -75,7 +76,7 @@ func (p *DoradoV6Attacher) needUpdateHost(host map[string]interface{}, hostAlua
 func (p *DoradoV6Attacher) ControllerAttach(ctx context.Context,
 	lunName string,
 	parameters map[string]interface{}) (map[string]interface{}, error) {
-	host, err := p.getHost(ctx, parameters, true)
+	host, err := p.getAttachHost(ctx, parameters, true)
 	if err != nil {
 		log.AddContext(ctx).Errorf("Get host ID error: %v", err)
 		return nil, err
@@ -85,7 +86,7 @@ func (p *DoradoV6Attacher) ControllerAttach(ctx context.Context,
 	if !ok {
 		return nil, errors.New("convert host[\"ID\"] to string failed")
 	}
-	hostAlua := utils.GetAlua(ctx, p.alua, host["NAME"].(string))
+	hostAlua := p.getHostAlua(ctx, host)
 
 	if hostAlua != nil && p.needUpdateHost(host, hostAlua) {
 		err := p.cli.UpdateHost(ctx, hostID, hostAlua)
@@ -114,5 +115,13 @@ func (p *DoradoV6Attacher) ControllerAttach(ctx context.Context,
 		return nil, err
 	}
 
-	return p.getMappingProperties(ctx, wwn, hostLunId, parameters)
+	mappingInfo, err := p.getMappingProperties(ctx, wwn, hostLunId, parameters)
+	if err != nil {
+		return nil, err
+	}
+
+	mappingInfo["hostGroupName"] = p.getHostGroupName(hostID)
+	mappingInfo["hostID"] = hostID
+	mappingInfo["hostName"] = host["NAME"]
+	return mappingInfo, nil
 }
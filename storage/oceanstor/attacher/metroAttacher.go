@@ -50,7 +50,13 @@ func (p *MetroAttacher) mergeMappingInfo(ctx context.Context,
 
 	if localMapping == nil {
 		localMapping = remoteMapping
-	} else if remoteMapping != nil {
+		localMapping["remoteHostID"] = remoteMapping["hostID"]
+		delete(localMapping, "hostID")
+		return localMapping, nil
+	}
+
+	if remoteMapping != nil {
+		localMapping["remoteHostID"] = remoteMapping["hostID"]
 		if p.protocol == "iscsi" {
 			localMapping["tgtPortals"] = append(localMapping["tgtPortals"].([]string),
 				remoteMapping["tgtPortals"].([]string)...)
@@ -92,7 +98,7 @@ func (p *MetroAttacher) ControllerAttach(ctx context.Context,
 func (p *MetroAttacher) ControllerDetach(ctx context.Context,
 	lunName string,
 	parameters map[string]interface{}) (string, error) {
-	rmtLunWWN, err := p.remoteAttacher.ControllerDetach(ctx, lunName, parameters)
+	rmtLunWWN, err := p.remoteAttacher.ControllerDetach(ctx, lunName, getRemoteParameters(parameters))
 	if err != nil {
 		log.AddContext(ctx).Errorf("Detach hypermetro remote volume %s error: %v", lunName, err)
 		return "", err
@@ -107,6 +113,36 @@ func (p *MetroAttacher) ControllerDetach(ctx context.Context,
 	return p.mergeLunWWN(ctx, locLunWWN, rmtLunWWN)
 }
 
+// getRemoteParameters returns the parameters of the remote storage, in which the host recorded on the remote
+// storage replaces the one recorded on the local storage
+func getRemoteParameters(parameters map[string]interface{}) map[string]interface{} {
+	remoteParameters := make(map[string]interface{}, len(parameters))
+	for key, value := range parameters {
+		remoteParameters[key] = value
+	}
+
+	delete(remoteParameters, "HostID")
+	if hostID, ok := parameters["RemoteHostID"]; ok {
+		remoteParameters["HostID"] = hostID
+	}
+	return remoteParameters
+}
+
+// ControllerDeleteHost deletes the host on both local and remote storage
+func (p *MetroAttacher) ControllerDeleteHost(ctx context.Context, parameters map[string]interface{}) error {
+	if err := p.remoteAttacher.ControllerDeleteHost(ctx, parameters); err != nil {
+		log.AddContext(ctx).Errorf("Delete hypermetro remote host of %v error: %v", parameters["HostName"], err)
+		return err
+	}
+
+	if err := p.localAttacher.ControllerDeleteHost(ctx, parameters); err != nil {
+		log.AddContext(ctx).Errorf("Delete hypermetro local host of %v error: %v", parameters["HostName"], err)
+		return err
+	}
+
+	return nil
+}
+
 func (p *MetroAttacher) mergeLunWWN(ctx context.Context, locLunWWN, rmtLunWWN string) (string, error) {
 	if rmtLunWWN == "" && locLunWWN == "" {
 		log.AddContext(ctx).Infoln("both storage site of HyperMetro are failed to get lun WWN")
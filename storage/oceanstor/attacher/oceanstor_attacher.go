@@ -21,7 +21,6 @@ import (
 	"errors"
 
 	"huawei-csi-driver/storage/oceanstor/client"
-	"huawei-csi-driver/utils"
 	"huawei-csi-driver/utils/log"
 )
 
@@ -40,14 +39,16 @@ func newOceanStorAttacher(
 	protocol,
 	invoker string,
 	portals []string,
-	alua map[string]interface{}) AttacherPlugin {
+	alua map[string]interface{},
+	hostPolicy HostPolicy) AttacherPlugin {
 	return &OceanStorAttacher{
 		Attacher: Attacher{
-			cli:      cli,
-			protocol: protocol,
-			invoker:  invoker,
-			portals:  portals,
-			alua:     alua,
+			cli:        cli,
+			protocol:   protocol,
+			invoker:    invoker,
+			portals:    portals,
+			alua:       alua,
+			hostPolicy: hostPolicy,
 		},
 	}
 }
@@ -83,14 +84,13 @@ func (p *OceanStorAttacher) needUpdateInitiatorAlua(initiator map[string]interfa
 	return false
 }
 
-func (p *OceanStorAttacher) attachISCSI(ctx context.Context, hostID, hostName string,
-	parameters map[string]interface{}) error {
+func (p *OceanStorAttacher) attachISCSI(ctx context.Context, hostID string,
+	hostAlua, parameters map[string]interface{}) error {
 	iscsiInitiator, err := p.Attacher.attachISCSI(ctx, hostID, parameters)
 	if err != nil {
 		return err
 	}
 
-	hostAlua := utils.GetAlua(ctx, p.alua, hostName)
 	if hostAlua != nil && p.needUpdateInitiatorAlua(iscsiInitiator, hostAlua) {
 		err = p.cli.UpdateIscsiInitiator(ctx, iscsiInitiator["ID"].(string), hostAlua)
 	}
@@ -98,14 +98,13 @@ func (p *OceanStorAttacher) attachISCSI(ctx context.Context, hostID, hostName st
 	return err
 }
 
-func (p *OceanStorAttacher) attachFC(ctx context.Context, hostID, hostName string,
-	parameters map[string]interface{}) error {
+func (p *OceanStorAttacher) attachFC(ctx context.Context, hostID string,
+	hostAlua, parameters map[string]interface{}) error {
 	fcInitiators, err := p.Attacher.attachFC(ctx, hostID, parameters)
 	if err != nil {
 		return err
 	}
 
-	hostAlua := utils.GetAlua(ctx, p.alua, hostName)
 	if hostAlua != nil {
 		for _, i := range fcInitiators {
 			if !p.needUpdateInitiatorAlua(i, hostAlua) {
@@ -132,7 +131,7 @@ func (p *OceanStorAttacher) ControllerAttach(ctx context.Context,
 	lunName string,
 	parameters map[string]interface{}) (
 	map[string]interface{}, error) {
-	host, err := p.getHost(ctx, parameters, true)
+	host, err := p.getAttachHost(ctx, parameters, true)
 	if err != nil {
 		log.AddContext(ctx).Errorf("Get host ID error: %v", err)
 		return nil, err
@@ -148,9 +147,9 @@ func (p *OceanStorAttacher) ControllerAttach(ctx context.Context,
 	}
 
 	if p.protocol == "iscsi" {
-		err = p.attachISCSI(ctx, hostID, hostName, parameters)
+		err = p.attachISCSI(ctx, hostID, p.getHostAlua(ctx, host), parameters)
 	} else if p.protocol == "fc" || p.protocol == "fc-nvme" {
-		err = p.attachFC(ctx, hostID, hostName, parameters)
+		err = p.attachFC(ctx, hostID, p.getHostAlua(ctx, host), parameters)
 	} else if p.protocol == "roce" {
 		err = p.attachRoCE(ctx, hostID, parameters)
 	}
@@ -166,5 +165,13 @@ func (p *OceanStorAttacher) ControllerAttach(ctx context.Context,
 		return nil, err
 	}
 
-	return p.getMappingProperties(ctx, wwn, hostLunId, parameters)
+	mappingInfo, err := p.getMappingProperties(ctx, wwn, hostLunId, parameters)
+	if err != nil {
+		return nil, err
+	}
+
+	mappingInfo["hostGroupName"] = p.getHostGroupName(hostID)
+	mappingInfo["hostID"] = hostID
+	mappingInfo["hostName"] = hostName
+	return mappingInfo, nil
 }
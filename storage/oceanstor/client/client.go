@@ -20,6 +20,7 @@ import (
 	"bytes"
 	"context"
 	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -29,6 +30,7 @@ import (
 	"net/http/cookiejar"
 	"regexp"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -58,6 +60,11 @@ const (
 	defaultVStore   string = "System_vStore"
 	defaultVStoreID string = "0"
 
+	// the scope field of the login request to /xx/sessions, 0 is the local user and 1 is the LDAP user.
+	// No scope is used for the AD user, the AD domain is rejected by the plugin before login.
+	localUserScope  = "0"
+	domainUserScope = "1"
+
 	// IPLockErrorCode defines error code of ip lock
 	IPLockErrorCode = 1077949071
 
@@ -69,6 +76,12 @@ const (
 
 	// UrlNotFound defines error msg of url not found
 	UrlNotFound = "404_NotFound"
+
+	// EncryptedAttribute defines the attribute of lun and filesystem which is encrypted by the storage
+	EncryptedAttribute = "ENCRYPTED"
+
+	// DedupAttribute defines the attribute of filesystem which enables the deduplication on the storage
+	DedupAttribute = "ENABLEDEDUP"
 )
 
 var (
@@ -76,11 +89,16 @@ var (
 	WrongPasswordErrorCodes = []int64{1077987870, 1077949081, 1077949061}
 	// AccountBeenLocked account been locked
 	AccountBeenLocked = []int64{1077949070, 1077987871}
+
+	// logoutTimeout bounds the logout, so that an unreachable storage doesn't delay the release of the client
+	// and the shutdown for the whole http timeout
+	logoutTimeout = 2 * time.Second
 )
 
 // BaseClientInterface defines interfaces for base client operations
 type BaseClientInterface interface {
 	ApplicationType
+	CIFS
 	Clone
 	FC
 	Filesystem
@@ -90,11 +108,14 @@ type BaseClientInterface interface {
 	Iscsi
 	Lun
 	LunCopy
+	LunPerformance
 	LunSnapshot
 	Mapping
+	NFSAccessLog
 	Qos
 	Replication
 	RoCE
+	SnapshotTiering
 	System
 	VStore
 	DTree
@@ -112,6 +133,7 @@ type BaseClientInterface interface {
 	Login(ctx context.Context) error
 	Logout(ctx context.Context)
 	ReLogin(ctx context.Context) error
+	DumpRequestRecords(ctx context.Context) (string, error)
 }
 
 var (
@@ -180,13 +202,25 @@ type BaseClient struct {
 	SecretName      string
 	VStoreName      string
 	VStoreID        string
+	AuthDomain      string
 	StorageVersion  string
+	StorageModel    string
+	FirmwareVersion string
 	BackendID       string
+	// Tracing injects the W3C Trace Context headers into the requests to the storage
+	Tracing bool
+	// Recorder keeps the last exchanges with the storage, it is nil if the recording mode is off
+	Recorder *RequestRecorder
+	// PoolTags are the tags of the pools configured in the backend, keyed by pool name
+	PoolTags map[string]map[string]string
 
 	DeviceId string
 	Token    string
 
 	ReLoginMutex sync.Mutex
+
+	// password is only set for the standalone client, which logs in without the backend secret
+	password string
 }
 
 // HTTP defines for http request process
@@ -223,14 +257,18 @@ func newHTTPClientByBackendID(ctx context.Context, backendID string) (HTTP, erro
 }
 
 func newHTTPClientByCertMeta(ctx context.Context, useCert bool, certMeta string) (HTTP, error) {
-	jar, err := cookiejar.New(nil)
+	useCert, certPool, err := pkgUtils.GetCertPool(ctx, useCert, certMeta)
 	if err != nil {
-		log.AddContext(ctx).Errorf("create jar failed, error: %v", err)
 		return nil, err
 	}
 
-	useCert, certPool, err := pkgUtils.GetCertPool(ctx, useCert, certMeta)
+	return newHTTPClientByCertPool(ctx, useCert, certPool)
+}
+
+func newHTTPClientByCertPool(ctx context.Context, useCert bool, certPool *x509.CertPool) (HTTP, error) {
+	jar, err := cookiejar.New(nil)
 	if err != nil {
+		log.AddContext(ctx).Errorf("create jar failed, error: %v", err)
 		return nil, err
 	}
 
@@ -256,10 +294,20 @@ type NewClientConfig struct {
 	SecretName      string
 	SecretNamespace string
 	VstoreName      string
-	ParallelNum     string
-	BackendID       string
-	UseCert         bool
-	CertSecretMeta  string
+	// AuthDomain is the LDAP domain of the user, empty means the user is a local user of the storage
+	AuthDomain     string
+	ParallelNum    string
+	BackendID      string
+	UseCert        bool
+	CertSecretMeta string
+	// RootCAs verifies the certificate of the storage instead of the cert secret of CertSecretMeta,
+	// it is used by the standalone client which can't read the secret through the CSI driver
+	RootCAs       *x509.CertPool
+	Tracing       bool
+	RecordSize    int
+	RecordDumpDir string
+	// PoolTags are the tags of the pools configured in the backend, keyed by pool name
+	PoolTags map[string]map[string]string
 }
 
 // NewClient inits a new base client
@@ -281,7 +329,12 @@ func NewClient(ctx context.Context, param *NewClientConfig) (*BaseClient, error)
 	log.AddContext(ctx).Infof("Init parallel count is %d", parallelCount)
 	ClientSemaphore = utils.NewSemaphore(parallelCount)
 
-	httpClient, err := newHTTPClientByCertMeta(ctx, param.UseCert, param.CertSecretMeta)
+	var httpClient HTTP
+	if param.RootCAs != nil {
+		httpClient, err = newHTTPClientByCertPool(ctx, true, param.RootCAs)
+	} else {
+		httpClient, err = newHTTPClientByCertMeta(ctx, param.UseCert, param.CertSecretMeta)
+	}
 	if err != nil {
 		log.AddContext(ctx).Errorf("new http client by cert meta failed, err is %v", err)
 		return nil, err
@@ -293,11 +346,27 @@ func NewClient(ctx context.Context, param *NewClientConfig) (*BaseClient, error)
 		SecretName:      param.SecretName,
 		SecretNamespace: param.SecretNamespace,
 		VStoreName:      param.VstoreName,
+		AuthDomain:      param.AuthDomain,
 		Client:          httpClient,
 		BackendID:       param.BackendID,
+		Tracing:         param.Tracing,
+		Recorder:        NewRequestRecorder(param.BackendID, param.RecordSize, param.RecordDumpDir),
+		PoolTags:        param.PoolTags,
 	}, nil
 }
 
+// NewStandaloneClient inits a client which logs in with the given password instead of the backend secret,
+// it is used by the tools running outside the CSI driver, such as oceanctl.
+func NewStandaloneClient(ctx context.Context, param *NewClientConfig, password string) (*BaseClient, error) {
+	cli, err := NewClient(ctx, param)
+	if err != nil {
+		return nil, err
+	}
+
+	cli.password = password
+	return cli, nil
+}
+
 // Call provides call for restful request
 func (cli *BaseClient) Call(ctx context.Context,
 	method string, url string,
@@ -394,7 +463,8 @@ func (cli *BaseClient) BaseCall(ctx context.Context,
 	reqUrl := cli.Url
 	reqUrl += url
 
-	if url != "/xx/sessions" && url != "/sessions" {
+	sessionUrl := url == "/xx/sessions" || url == "/sessions"
+	if !sessionUrl {
 		cli.ReLoginMutex.Lock()
 		req, err = cli.GetRequest(ctx, method, url, data)
 		cli.ReLoginMutex.Unlock()
@@ -406,9 +476,28 @@ func (cli *BaseClient) BaseCall(ctx context.Context,
 		return r, err
 	}
 
+	// the login and logout are canceled with the context, instead of waiting for the http timeout
+	if sessionUrl {
+		req = req.WithContext(ctx)
+	}
+
 	log.FilteredLog(ctx, isFilterLog(method, url), utils.IsDebugLog(method, url, debugLog, debugLogRegex),
 		fmt.Sprintf("Request method: %s, Url: %s, body: %v", method, req.URL, data))
 
+	if cli.Tracing {
+		endSpan := startRequestSpan(ctx, req)
+		defer endSpan()
+	}
+
+	var status int
+	var body []byte
+	if cli.Recorder != nil {
+		start := time.Now()
+		defer func() {
+			cli.Recorder.Record(ctx, method, req.URL.String(), data, status, body, err, time.Since(start))
+		}()
+	}
+
 	ClientSemaphore.Acquire()
 	defer ClientSemaphore.Release()
 
@@ -420,7 +509,8 @@ func (cli *BaseClient) BaseCall(ctx context.Context,
 
 	defer resp.Body.Close()
 
-	body, err := ioutil.ReadAll(resp.Body)
+	status = resp.StatusCode
+	body, err = ioutil.ReadAll(resp.Body)
 	if err != nil {
 		log.AddContext(ctx).Errorf("Read response data error: %v", err)
 		return r, err
@@ -458,6 +548,12 @@ func (cli *BaseClient) Delete(ctx context.Context, url string, data map[string]i
 	return cli.Call(ctx, "DELETE", url, data)
 }
 
+// DumpRequestRecords dumps the recorded exchanges with the storage to a file and returns its path,
+// the path is empty if the recording mode is off
+func (cli *BaseClient) DumpRequestRecords(ctx context.Context) (string, error) {
+	return cli.Recorder.Dump(ctx)
+}
+
 // DuplicateClient clone a base client from origin client
 func (cli *BaseClient) DuplicateClient() *BaseClient {
 	dup := *cli
@@ -480,15 +576,7 @@ func (cli *BaseClient) ValidateLogin(ctx context.Context) error {
 		return err
 	}
 
-	data := map[string]interface{}{
-		"username": cli.User,
-		"password": password,
-		"scope":    "0",
-	}
-
-	if len(cli.VStoreName) > 0 && cli.VStoreName != defaultVStore {
-		data["vstorename"] = cli.VStoreName
-	}
+	data := cli.newLoginData(password)
 
 	cli.DeviceId = ""
 	cli.Token = ""
@@ -548,10 +636,12 @@ func (cli *BaseClient) Login(ctx context.Context) error {
 	var resp Response
 	var err error
 
-	cli.Client, err = newHTTPClientByBackendID(ctx, cli.BackendID)
-	if err != nil {
-		log.AddContext(ctx).Errorf("new http client by backend %s failed, err is %v", cli.BackendID, err)
-		return err
+	if cli.password == "" {
+		cli.Client, err = newHTTPClientByBackendID(ctx, cli.BackendID)
+		if err != nil {
+			log.AddContext(ctx).Errorf("new http client by backend %s failed, err is %v", cli.BackendID, err)
+			return err
+		}
 	}
 
 	data, err := cli.getRequestParams(ctx, cli.BackendID)
@@ -587,8 +677,8 @@ func (cli *BaseClient) Login(ctx context.Context) error {
 	errCode, _ := resp.Error["code"].(float64)
 	if code := int64(errCode); code != 0 {
 		msg := fmt.Sprintf("Login %s error: %+v", cli.Url, resp)
-		if utils.Contains(WrongPasswordErrorCodes, code) || utils.Contains(AccountBeenLocked, code) ||
-			code == IPLockErrorCode {
+		if cli.password == "" && (utils.Contains(WrongPasswordErrorCodes, code) ||
+			utils.Contains(AccountBeenLocked, code) || code == IPLockErrorCode) {
 			if err := pkgUtils.SetStorageBackendContentOnlineStatus(ctx, cli.BackendID, false); err != nil {
 				msg = msg + fmt.Sprintf("\nSetStorageBackendContentOffline [%s] failed. error: %v", cli.BackendID, err)
 			}
@@ -597,6 +687,10 @@ func (cli *BaseClient) Login(ctx context.Context) error {
 	}
 
 	if err = cli.setDataFromRespData(ctx, resp); err != nil {
+		if cli.password != "" {
+			return err
+		}
+
 		setErr := pkgUtils.SetStorageBackendContentOnlineStatus(ctx, cli.BackendID, false)
 		if setErr != nil {
 			log.AddContext(ctx).Errorf("SetStorageBackendContentOffline [%s] failed. error: %v", cli.BackendID, setErr)
@@ -645,8 +739,11 @@ func (cli *BaseClient) setDataFromRespData(ctx context.Context, resp Response) e
 	return nil
 }
 
-// Logout logout
+// Logout logout, it gives up after the logout timeout if the storage doesn't respond
 func (cli *BaseClient) Logout(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, logoutTimeout)
+	defer cancel()
+
 	resp, err := cli.BaseCall(ctx, "DELETE", "/sessions", nil)
 	if err != nil {
 		log.AddContext(ctx).Warningf("Logout %s error: %v", cli.Url, err)
@@ -772,7 +869,12 @@ func (cli *BaseClient) getObjByvStoreName(objList []interface{}) map[string]inte
 
 func (cli *BaseClient) getObj(ctx context.Context, url string, start, end int, filterLog bool) (
 	[]map[string]interface{}, error) {
-	objUrl := fmt.Sprintf("%s?range=[%d-%d]", url, start, end)
+	separator := "?"
+	if strings.Contains(url, "?") {
+		separator = "&"
+	}
+
+	objUrl := fmt.Sprintf("%s%srange=[%d-%d]", url, separator, start, end)
 	resp, err := cli.Get(ctx, objUrl, nil)
 	if err != nil {
 		return nil, err
@@ -780,7 +882,7 @@ func (cli *BaseClient) getObj(ctx context.Context, url string, start, end int, f
 
 	code := int64(resp.Error["code"].(float64))
 	if code != 0 {
-		return nil, fmt.Errorf("get batch obj list error: %d", code)
+		return nil, fmt.Errorf("get batch obj list of %s error: %d", url, code)
 	}
 
 	if !filterLog {
@@ -808,43 +910,77 @@ func (cli *BaseClient) getObj(ctx context.Context, url string, start, end int, f
 }
 
 func (cli *BaseClient) getBatchObjs(ctx context.Context, url string, filterLog bool) ([]map[string]interface{}, error) {
-	rangeStart := 0
+	return cli.getPagedObjs(ctx, url, 0, filterLog)
+}
+
+// getPagedObjs gets the objects page by page with the range=[start-end] query, until all the objects are got
+// or the limit is reached, the limit 0 means no limit
+func (cli *BaseClient) getPagedObjs(ctx context.Context, url string, limit int, filterLog bool) (
+	[]map[string]interface{}, error) {
 	var objList []map[string]interface{}
-	for true {
-		rangeEnd := rangeStart + QueryCountPerBatch
-		objs, err := cli.getObj(ctx, url, rangeStart, rangeEnd, filterLog)
+	for rangeStart := 0; limit <= 0 || rangeStart < limit; rangeStart += QueryCountPerBatch {
+		objs, err := cli.getObj(ctx, url, rangeStart, rangeStart+QueryCountPerBatch, filterLog)
 		if err != nil {
 			return nil, err
 		}
 
-		if objs == nil {
-			break
-		}
-
 		objList = append(objList, objs...)
 		if len(objs) < QueryCountPerBatch {
 			break
 		}
-		rangeStart = rangeEnd + QueryCountPerBatch
+	}
+
+	if limit > 0 && len(objList) > limit {
+		objList = objList[:limit]
 	}
 	return objList, nil
 }
 
-func (cli *BaseClient) getRequestParams(ctx context.Context, backendID string) (map[string]interface{}, error) {
-	password, err := pkgUtils.GetPasswordFromBackendID(ctx, backendID)
-	if err != nil {
+// getPagedList is the same as getBatchObjs, but returns the objects as the list of the response data
+func (cli *BaseClient) getPagedList(ctx context.Context, url string) ([]interface{}, error) {
+	objs, err := cli.getBatchObjs(ctx, url, true)
+	if err != nil || len(objs) == 0 {
 		return nil, err
 	}
 
+	list := make([]interface{}, 0, len(objs))
+	for _, obj := range objs {
+		list = append(list, obj)
+	}
+	return list, nil
+}
+
+// newLoginData returns the body of the login request, the user of the auth domain logs in as a LDAP user
+func (cli *BaseClient) newLoginData(password string) map[string]interface{} {
 	data := map[string]interface{}{
 		"username": cli.User,
 		"password": password,
-		"scope":    "0",
+		"scope":    localUserScope,
+	}
+
+	if cli.AuthDomain != "" {
+		data["scope"] = domainUserScope
+		data["DOMAIN"] = cli.AuthDomain
 	}
 
 	if len(cli.VStoreName) > 0 && cli.VStoreName != defaultVStore {
 		data["vstorename"] = cli.VStoreName
 	}
 
-	return data, err
+	return data
+}
+
+func (cli *BaseClient) getRequestParams(ctx context.Context, backendID string) (map[string]interface{}, error) {
+	password := cli.password
+	if password == "" {
+		var err error
+		password, err = pkgUtils.GetPasswordFromBackendID(ctx, backendID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	data := cli.newLoginData(password)
+
+	return data, nil
 }
@@ -0,0 +1,213 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+
+	"huawei-csi-driver/utils/log"
+)
+
+const (
+	// CIFSSharePermissionReadOnly is the read-only permission of the cifs share
+	CIFSSharePermissionReadOnly = 0
+	// CIFSSharePermissionReadWrite is the read and write permission of the cifs share
+	CIFSSharePermissionReadWrite = 1
+	// CIFSSharePermissionFullControl is the full control permission of the cifs share
+	CIFSSharePermissionFullControl = 5
+)
+
+// CIFS defines interfaces for cifs share operations
+type CIFS interface {
+	// GetCIFSShareByPath used for get cifs share by path
+	GetCIFSShareByPath(ctx context.Context, path, vStoreID string) (map[string]interface{}, error)
+	// CreateCIFSShare used for create cifs share
+	CreateCIFSShare(ctx context.Context, req *CreateCIFSShareRequest) (map[string]interface{}, error)
+	// DeleteCIFSShare used for delete cifs share by id
+	DeleteCIFSShare(ctx context.Context, id, vStoreID string) error
+	// SetCIFSSharePermission used for set the permission of a user or group to the cifs share
+	SetCIFSSharePermission(ctx context.Context, req *SetCIFSSharePermissionRequest) error
+}
+
+// CreateCIFSShareRequest used for CreateCIFSShare request
+type CreateCIFSShareRequest struct {
+	Name        string
+	SharePath   string
+	FsID        string
+	DTreeID     string
+	Description string
+	VStoreID    string
+}
+
+// SetCIFSSharePermissionRequest used for SetCIFSSharePermission request
+type SetCIFSSharePermissionRequest struct {
+	ShareID    string
+	Name       string
+	Permission int
+	VStoreID   string
+}
+
+// GetCIFSShareByPath used for get cifs share by path
+func (cli *BaseClient) GetCIFSShareByPath(ctx context.Context, path, vStoreID string) (map[string]interface{},
+	error) {
+	query := fmt.Sprintf("/CIFSHARE?filter=SHAREPATH::%s&range=[0-100]", url.QueryEscape(path))
+	var data = make(map[string]interface{})
+	if vStoreID != "" {
+		data["vstoreId"] = vStoreID
+	}
+
+	resp, err := cli.Get(ctx, query, data)
+	if err != nil {
+		return nil, err
+	}
+
+	code := int64(resp.Error["code"].(float64))
+	if code == sharePathInvalid {
+		log.AddContext(ctx).Infof("Cifs share of path %s does not exist", path)
+		return nil, nil
+	}
+	if code != 0 {
+		return nil, fmt.Errorf("get cifs share of path %s error: %d", path, code)
+	}
+
+	respData, ok := resp.Data.([]interface{})
+	if !ok || len(respData) == 0 {
+		log.AddContext(ctx).Infof("Cifs share of path %s does not exist", path)
+		return nil, nil
+	}
+
+	share, ok := respData[0].(map[string]interface{})
+	if !ok {
+		return nil, errors.New("convert respData[0] to map[string]interface{} failed")
+	}
+	return share, nil
+}
+
+// CreateCIFSShare used for create cifs share, the existing share of the path is returned
+func (cli *BaseClient) CreateCIFSShare(ctx context.Context, req *CreateCIFSShareRequest) (map[string]interface{},
+	error) {
+	data := map[string]interface{}{
+		"NAME":        req.Name,
+		"SHAREPATH":   req.SharePath,
+		"FSID":        req.FsID,
+		"DESCRIPTION": req.Description,
+	}
+	if req.DTreeID != "" {
+		data["DTREEID"] = req.DTreeID
+	}
+	if req.VStoreID != "" {
+		data["vstoreId"] = req.VStoreID
+	}
+
+	resp, err := cli.Post(ctx, "/CIFSHARE", data)
+	if err != nil {
+		return nil, err
+	}
+
+	code := int64(resp.Error["code"].(float64))
+	if code == shareAlreadyExist || code == sharePathAlreadyExist {
+		log.AddContext(ctx).Infof("Cifs share %s already exists while creating", req.SharePath)
+		return cli.GetCIFSShareByPath(ctx, req.SharePath, req.VStoreID)
+	}
+	if code != 0 {
+		return nil, fmt.Errorf("create cifs share %s error: %d", req.SharePath, code)
+	}
+
+	share, ok := resp.Data.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("convert resp.Data to map[string]interface{} failed")
+	}
+	return share, nil
+}
+
+// DeleteCIFSShare used for delete cifs share by id
+func (cli *BaseClient) DeleteCIFSShare(ctx context.Context, id, vStoreID string) error {
+	var data = make(map[string]interface{})
+	if vStoreID != "" {
+		data["vstoreId"] = vStoreID
+	}
+
+	resp, err := cli.Delete(ctx, fmt.Sprintf("/CIFSHARE/%s", id), data)
+	if err != nil {
+		return err
+	}
+
+	code := int64(resp.Error["code"].(float64))
+	if code == shareNotExist {
+		log.AddContext(ctx).Infof("Cifs share %s does not exist while deleting", id)
+		return nil
+	}
+	if code != 0 {
+		return fmt.Errorf("delete cifs share %s error: %d", id, code)
+	}
+
+	return nil
+}
+
+// SetCIFSSharePermission used for set the permission of a user or group to the cifs share,
+// the permission of the user or group which is already allowed is updated
+func (cli *BaseClient) SetCIFSSharePermission(ctx context.Context, req *SetCIFSSharePermissionRequest) error {
+	var data = map[string]interface{}{"PARENTID": req.ShareID}
+	if req.VStoreID != "" {
+		data["vstoreId"] = req.VStoreID
+	}
+
+	query := fmt.Sprintf("/CIFS_SHARE_AUTH_CLIENT?filter=PARENTID::%s&range=[0-100]", req.ShareID)
+	resp, err := cli.Get(ctx, query, data)
+	if err != nil {
+		return err
+	}
+
+	code := int64(resp.Error["code"].(float64))
+	if code != 0 {
+		return fmt.Errorf("get auth clients of cifs share %s error: %d", req.ShareID, code)
+	}
+
+	data["PERMISSION"] = req.Permission
+	respData, _ := resp.Data.([]interface{})
+	for _, i := range respData {
+		authClient, ok := i.(map[string]interface{})
+		if !ok || authClient["NAME"] != req.Name {
+			continue
+		}
+
+		resp, err = cli.Put(ctx, fmt.Sprintf("/CIFS_SHARE_AUTH_CLIENT/%v", authClient["ID"]), data)
+		if err != nil {
+			return err
+		}
+		return cifsShareAuthClientError(resp, req)
+	}
+
+	data["NAME"] = req.Name
+	resp, err = cli.Post(ctx, "/CIFS_SHARE_AUTH_CLIENT", data)
+	if err != nil {
+		return err
+	}
+	return cifsShareAuthClientError(resp, req)
+}
+
+func cifsShareAuthClientError(resp Response, req *SetCIFSSharePermissionRequest) error {
+	code := int64(resp.Error["code"].(float64))
+	if code != 0 {
+		return fmt.Errorf("set permission %d of %s to cifs share %s error: %d",
+			req.Permission, req.Name, req.ShareID, code)
+	}
+	return nil
+}
@@ -45,6 +45,8 @@ type DTree interface {
 	DeleteDTreeByID(ctx context.Context, vStoreID, dTreeID string) error
 	// DeleteDTreeByName use for delete a dTree by name
 	DeleteDTreeByName(ctx context.Context, parentName, dTreeName, vStoreID string) error
+	// MoveDTree use for move a dTree to another parent filesystem without data copy
+	MoveDTree(ctx context.Context, srcParent, dstParent, dTreeName string) error
 }
 
 // CreateDTree use for create a dTree
@@ -116,3 +118,23 @@ func (cli *BaseClient) DeleteDTreeByName(ctx context.Context, parentName, dTreeN
 
 	return nil
 }
+
+// MoveDTree use for move a dTree to another parent filesystem without data copy
+func (cli *BaseClient) MoveDTree(ctx context.Context, srcParent, dstParent, dTreeName string) error {
+	resp, err := cli.Put(ctx, "/QUOTATREE/move", map[string]interface{}{
+		"PARENTNAME":    srcParent,
+		"NAME":          dTreeName,
+		"NEWPARENTNAME": dstParent,
+		"vstoreId":      cli.VStoreID,
+	})
+	if err != nil {
+		return err
+	}
+
+	if utils.ResCodeExist(resp.Error["code"]) {
+		return fmt.Errorf("move dtree %s from %s to %s failed, error: %s", dTreeName, srcParent, dstParent,
+			resp.Error["description"])
+	}
+
+	return nil
+}
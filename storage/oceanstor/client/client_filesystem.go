@@ -78,6 +78,8 @@ type Filesystem interface {
 	DeleteNfsShare(ctx context.Context, id, vStoreID string) error
 	// GetNFSServiceSetting used for get nfs service setting
 	GetNFSServiceSetting(ctx context.Context) (map[string]bool, error)
+	// UpdateNFSShareClientACL used for update the clients which are allowed to access the nfs share
+	UpdateNFSShareClientACL(ctx context.Context, req *UpdateNFSShareClientACLRequest) error
 }
 
 // DeleteFileSystem used for delete file system
@@ -369,6 +371,78 @@ func (cli *BaseClient) AllowNfsShareAccess(ctx context.Context, req *AllowNfsSha
 	return nil
 }
 
+// UpdateNFSShareClientACLRequest used for UpdateNFSShareClientACL request
+type UpdateNFSShareClientACLRequest struct {
+	ShareID    string
+	VStoreID   string
+	Clients    []string
+	AllSquash  int
+	RootSquash int
+}
+
+// UpdateNFSShareClientACL used for update the clients which are allowed to access the nfs share,
+// the clients not in the request will be removed from the nfs share
+func (cli *BaseClient) UpdateNFSShareClientACL(ctx context.Context, req *UpdateNFSShareClientACLRequest) error {
+	count, err := cli.GetNfsShareAccessCount(ctx, req.ShareID, req.VStoreID)
+	if err != nil {
+		return err
+	}
+
+	accesses := make(map[string]string)
+	var i int64 = 0
+	for ; i < count; i += 100 { // Query per page 100
+		clients, err := cli.GetNfsShareAccessRange(ctx, req.ShareID, req.VStoreID, i, i+100)
+		if err != nil {
+			return err
+		}
+		if clients == nil {
+			break
+		}
+
+		for _, c := range clients {
+			access, ok := c.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			name, _ := access["NAME"].(string)
+			id, _ := access["ID"].(string)
+			accesses[name] = id
+		}
+	}
+
+	for _, name := range req.Clients {
+		if _, exist := accesses[name]; exist {
+			delete(accesses, name)
+			continue
+		}
+
+		err = cli.AllowNfsShareAccess(ctx, &AllowNfsShareAccessRequest{
+			Name:        name,
+			ParentID:    req.ShareID,
+			VStoreID:    req.VStoreID,
+			AccessVal:   1,
+			Sync:        0,
+			AllSquash:   req.AllSquash,
+			RootSquash:  req.RootSquash,
+			AccessKrb5:  -1,
+			AccessKrb5i: -1,
+			AccessKrb5p: -1,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	for name, id := range accesses {
+		if err = cli.DeleteNfsShareAccess(ctx, id, req.VStoreID); err != nil {
+			return fmt.Errorf("remove client %s from nfs share %s error: %v", name, req.ShareID, err)
+		}
+	}
+
+	log.AddContext(ctx).Infof("Update nfs share %s client acl to %v", req.ShareID, req.Clients)
+	return nil
+}
+
 // CreateNfsShare used for create nfs share
 func (cli *BaseClient) CreateNfsShare(ctx context.Context,
 	params map[string]interface{}) (map[string]interface{}, error) {
@@ -561,6 +635,14 @@ func (cli *BaseClient) CreateFileSystem(ctx context.Context, params map[string]i
 		data["workloadTypeId"] = uint32(res)
 	}
 
+	if encrypted, ok := params["encrypted"].(bool); ok && encrypted {
+		data[EncryptedAttribute] = true
+	}
+
+	if deduplication, ok := params["deduplication"].(bool); ok && deduplication {
+		data[DedupAttribute] = true
+	}
+
 	resp, err := cli.Post(ctx, "/filesystem", data)
 	if err != nil {
 		return nil, err
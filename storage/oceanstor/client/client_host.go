@@ -38,14 +38,16 @@ type Host interface {
 	QueryAssociateHostGroup(ctx context.Context, objType int, objID string) ([]interface{}, error)
 	// GetHostByName used to get host by name
 	GetHostByName(ctx context.Context, name string) (map[string]interface{}, error)
+	// GetHostByID used to get host by id
+	GetHostByID(ctx context.Context, id string) (map[string]interface{}, error)
 	// GetHostGroupByName used for get host group by name
 	GetHostGroupByName(ctx context.Context, name string) (map[string]interface{}, error)
 	// DeleteHost used for delete host by id
 	DeleteHost(ctx context.Context, id string) error
 	// DeleteHostGroup used for delete host group
 	DeleteHostGroup(ctx context.Context, id string) error
-	// CreateHost used for create  host
-	CreateHost(ctx context.Context, name string) (map[string]interface{}, error)
+	// CreateHost used for create host with the description
+	CreateHost(ctx context.Context, name, description string) (map[string]interface{}, error)
 	// UpdateHost used for update host
 	UpdateHost(ctx context.Context, id string, alua map[string]interface{}) error
 	// AddHostToGroup used for add host to group
@@ -109,33 +111,23 @@ func (cli *BaseClient) RemoveHostFromGroup(ctx context.Context, hostID, hostGrou
 // QueryAssociateHostGroup used for query associate host group
 func (cli *BaseClient) QueryAssociateHostGroup(ctx context.Context, objType int, objID string) ([]interface{}, error) {
 	url := fmt.Sprintf("/hostgroup/associate?ASSOCIATEOBJTYPE=%d&ASSOCIATEOBJID=%s", objType, objID)
-	resp, err := cli.Get(ctx, url, nil)
+	respData, err := cli.getPagedList(ctx, url)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("associate query hostgroup by obj %s of type %d error: %v", objID, objType, err)
 	}
 
-	code := int64(resp.Error["code"].(float64))
-	if code != 0 {
-		return nil, fmt.Errorf("associate query hostgroup by obj %s of type %d error: %d", objID, objType, code)
-	}
-
-	if resp.Data == nil {
+	if respData == nil {
 		log.AddContext(ctx).Infof("obj %s of type %d doesn't associate to any hostgroup", objID, objType)
-		return nil, nil
-	}
-
-	respData, ok := resp.Data.([]interface{})
-	if !ok {
-		return nil, errors.New("convert resp.Data to []interface{} failed")
 	}
 	return respData, nil
 }
 
-// CreateHost used for create  host
-func (cli *BaseClient) CreateHost(ctx context.Context, name string) (map[string]interface{}, error) {
+// CreateHost used for create host with the description
+func (cli *BaseClient) CreateHost(ctx context.Context, name, description string) (map[string]interface{}, error) {
 	data := map[string]interface{}{
 		"NAME":            name,
 		"OPERATIONSYSTEM": 0,
+		"DESCRIPTION":     description,
 	}
 
 	resp, err := cli.Post(ctx, "/host", data)
@@ -221,6 +213,30 @@ func (cli *BaseClient) GetHostByName(ctx context.Context, name string) (map[stri
 	return host, nil
 }
 
+// GetHostByID used to get host by id
+func (cli *BaseClient) GetHostByID(ctx context.Context, id string) (map[string]interface{}, error) {
+	url := fmt.Sprintf("/host/%s", id)
+	resp, err := cli.Get(ctx, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	code := int64(resp.Error["code"].(float64))
+	if code == hostNotExist {
+		log.AddContext(ctx).Infof("Host %s does not exist", id)
+		return nil, nil
+	}
+	if code != 0 {
+		return nil, fmt.Errorf("get host %s error: %d", id, code)
+	}
+
+	host, ok := resp.Data.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("convert resp.Data to map[string]interface{} failed")
+	}
+	return host, nil
+}
+
 // DeleteHost used for delete host by id
 func (cli *BaseClient) DeleteHost(ctx context.Context, id string) error {
 	url := fmt.Sprintf("/host/%s", id)
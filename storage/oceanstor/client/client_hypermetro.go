@@ -52,35 +52,18 @@ type HyperMetro interface {
 
 // GetHyperMetroDomainByName used for get hyper metro domain by name
 func (cli *BaseClient) GetHyperMetroDomainByName(ctx context.Context, name string) (map[string]interface{}, error) {
-	resp, err := cli.Get(ctx, "/HyperMetroDomain?range=[0-100]", nil)
+	domains, err := cli.getBatchObjs(ctx, "/HyperMetroDomain", true)
 	if err != nil {
-		return nil, err
-	}
-
-	code := int64(resp.Error["code"].(float64))
-	if code != 0 {
-		return nil, fmt.Errorf("Get HyperMetroDomain of name %s error: %d", name, code)
-	}
-	if resp.Data == nil {
-		log.AddContext(ctx).Infof("No HyperMetroDomain %s exist", name)
-		return nil, nil
+		return nil, fmt.Errorf("Get HyperMetroDomain of name %s error: %v", name, err)
 	}
 
-	respData, ok := resp.Data.([]interface{})
-	if !ok {
-		return nil, pkgUtils.Errorf(ctx, "convert respData to arr failed, data: %v", resp.Data)
-	}
-	for _, i := range respData {
-		domain, ok := i.(map[string]interface{})
-		if !ok {
-			log.AddContext(ctx).Warningf("convert domain to map failed, data: %v", i)
-			continue
-		}
+	for _, domain := range domains {
 		if domain["NAME"].(string) == name {
 			return domain, nil
 		}
 	}
 
+	log.AddContext(ctx).Infof("No HyperMetroDomain %s exist", name)
 	return nil, nil
 }
 
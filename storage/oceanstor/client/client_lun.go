@@ -36,6 +36,10 @@ const (
 	parameterIncorrect int64 = 50331651
 )
 
+// ErrBatchCreateRejected means the storage rejects the batch creation of luns, such as the storage which does not
+// support it, so that the luns have to be created one by one
+var ErrBatchCreateRejected = errors.New("the batch creation of luns is rejected by the storage")
+
 // Lun defines interfaces for lun operations
 type Lun interface {
 	// QueryAssociateLunGroup used for query associate lun group by object type and object id
@@ -62,10 +66,16 @@ type Lun interface {
 	ExtendLun(ctx context.Context, lunID string, newCapacity int64) error
 	// CreateLun used for create lun
 	CreateLun(ctx context.Context, params map[string]interface{}) (map[string]interface{}, error)
+	// BatchCreateLuns used for create luns in one request, the luns are returned in the order of the requests
+	BatchCreateLuns(ctx context.Context, requests []LunCreateRequest) ([]map[string]interface{}, error)
 	// GetHostLunId used for get host lun id
 	GetHostLunId(ctx context.Context, hostID, lunID string) (string, error)
 	// UpdateLun used for update lun
 	UpdateLun(ctx context.Context, lunID string, params map[string]interface{}) error
+	// SetLunWriteProtect used for make lun read-only to the hosts
+	SetLunWriteProtect(ctx context.Context, lunID string) error
+	// ClearLunWriteProtect used for make write-protected lun writable again
+	ClearLunWriteProtect(ctx context.Context, lunID string) error
 	// AddLunToGroup used for add lun to group
 	AddLunToGroup(ctx context.Context, lunID string, groupID string) error
 	// CreateLunGroup used for create lun group
@@ -75,24 +85,13 @@ type Lun interface {
 // QueryAssociateLunGroup used for query associate lun group by object type and object id
 func (cli *BaseClient) QueryAssociateLunGroup(ctx context.Context, objType int, objID string) ([]interface{}, error) {
 	url := fmt.Sprintf("/lungroup/associate?ASSOCIATEOBJTYPE=%d&ASSOCIATEOBJID=%s", objType, objID)
-	resp, err := cli.Get(ctx, url, nil)
+	respData, err := cli.getPagedList(ctx, url)
 	if err != nil {
-		return nil, err
-	}
-
-	code := int64(resp.Error["code"].(float64))
-	if code != 0 {
-		return nil, fmt.Errorf("associate query lungroup by obj %s of type %d error: %d", objID, objType, code)
+		return nil, fmt.Errorf("associate query lungroup by obj %s of type %d error: %v", objID, objType, err)
 	}
 
-	if resp.Data == nil {
+	if respData == nil {
 		log.AddContext(ctx).Infof("obj %s of type %d doesn't associate to any lungroup", objID, objType)
-		return nil, nil
-	}
-
-	respData, ok := resp.Data.([]interface{})
-	if !ok {
-		return nil, pkgUtils.Errorf(ctx, "convert respData to arr failed, data: %v", resp.Data)
 	}
 	return respData, nil
 }
@@ -307,6 +306,12 @@ func (cli *BaseClient) CreateLun(ctx context.Context, params map[string]interfac
 	if val, ok := params["workloadTypeID"].(string); ok {
 		data["WORKLOADTYPEID"] = val
 	}
+	if encrypted, ok := params["encrypted"].(bool); ok && encrypted {
+		data[EncryptedAttribute] = true
+	}
+	if owner, ok := params["owningcontroller"].(string); ok && owner != "" {
+		data["OWNINGCONTROLLER"] = owner
+	}
 
 	resp, err := cli.Post(ctx, "/lun", data)
 	if err != nil {
@@ -330,6 +335,120 @@ func (cli *BaseClient) CreateLun(ctx context.Context, params map[string]interfac
 	return respData, nil
 }
 
+// LunCreateRequest defines the parameters of a lun to create
+type LunCreateRequest struct {
+	Name           string
+	ParentID       string
+	Description    string
+	WorkloadTypeID string
+	Capacity       int64
+	AllocType      int
+	Encrypted      bool
+	// OwningController is the controller owning the lun, the storage chooses it if it's empty
+	OwningController string
+}
+
+// NewLunCreateRequest converts the parameters of CreateLun to a LunCreateRequest
+func NewLunCreateRequest(params map[string]interface{}) (LunCreateRequest, error) {
+	var request LunCreateRequest
+	var ok bool
+	if request.Name, ok = params["name"].(string); !ok {
+		return request, fmt.Errorf("convert name to string failed, data: %v", params["name"])
+	}
+	if request.ParentID, ok = params["parentid"].(string); !ok {
+		return request, fmt.Errorf("convert parentid to string failed, data: %v", params["parentid"])
+	}
+	if request.Capacity, ok = params["capacity"].(int64); !ok {
+		return request, fmt.Errorf("convert capacity to int64 failed, data: %v", params["capacity"])
+	}
+	if request.AllocType, ok = params["alloctype"].(int); !ok {
+		return request, fmt.Errorf("convert alloctype to int failed, data: %v", params["alloctype"])
+	}
+	request.Description, _ = params["description"].(string)
+	request.WorkloadTypeID, _ = params["workloadTypeID"].(string)
+	request.Encrypted, _ = params["encrypted"].(bool)
+	request.OwningController, _ = params["owningcontroller"].(string)
+
+	return request, nil
+}
+
+func (r LunCreateRequest) data() map[string]interface{} {
+	data := map[string]interface{}{
+		"NAME":        r.Name,
+		"PARENTID":    r.ParentID,
+		"CAPACITY":    r.Capacity,
+		"DESCRIPTION": r.Description,
+		"ALLOCTYPE":   r.AllocType,
+	}
+	if r.WorkloadTypeID != "" {
+		data["WORKLOADTYPEID"] = r.WorkloadTypeID
+	}
+	if r.Encrypted {
+		data[EncryptedAttribute] = true
+	}
+	if r.OwningController != "" {
+		data["OWNINGCONTROLLER"] = r.OwningController
+	}
+
+	return data
+}
+
+// BatchCreateLuns used for create luns in one request, the luns are returned in the order of the requests.
+// The batch interface is not described by the REST API reference this client follows for the other lun
+// operations, so any error code or unexpected response is taken as the storage not supporting it.
+func (cli *BaseClient) BatchCreateLuns(ctx context.Context,
+	requests []LunCreateRequest) ([]map[string]interface{}, error) {
+	lunList := make([]map[string]interface{}, 0, len(requests))
+	for _, request := range requests {
+		lunList = append(lunList, request.data())
+	}
+
+	resp, err := cli.Post(ctx, "/lun/batch_create", map[string]interface{}{"LUNLIST": lunList})
+	if err != nil {
+		return nil, err
+	}
+
+	code := int64(resp.Error["code"].(float64))
+	if code != 0 {
+		return nil, fmt.Errorf("%w, batch create %d luns error: %d", ErrBatchCreateRejected, len(requests), code)
+	}
+
+	respData, ok := resp.Data.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%w, unexpected response data: %v", ErrBatchCreateRejected, resp.Data)
+	}
+
+	return sortBatchCreatedLuns(requests, respData)
+}
+
+// sortBatchCreatedLuns matches the created luns to the requests by name, the storage does not guarantee the order
+func sortBatchCreatedLuns(requests []LunCreateRequest, respData []interface{}) ([]map[string]interface{}, error) {
+	created := make(map[string]map[string]interface{}, len(respData))
+	for _, data := range respData {
+		lun, ok := data.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("convert lun to map failed, data: %v", data)
+		}
+
+		name, ok := lun["NAME"].(string)
+		if !ok {
+			return nil, fmt.Errorf("convert NAME of lun to string failed, data: %v", lun)
+		}
+		created[name] = lun
+	}
+
+	luns := make([]map[string]interface{}, 0, len(requests))
+	for _, request := range requests {
+		lun, exist := created[request.Name]
+		if !exist {
+			return nil, fmt.Errorf("lun %s is not returned by the batch creation", request.Name)
+		}
+		luns = append(luns, lun)
+	}
+
+	return luns, nil
+}
+
 // DeleteLun used for delete lun by lun id
 func (cli *BaseClient) DeleteLun(ctx context.Context, id string) error {
 	url := fmt.Sprintf("/lun/%s", id)
@@ -371,6 +490,16 @@ func (cli *BaseClient) ExtendLun(ctx context.Context, lunID string, newCapacity
 	return nil
 }
 
+// SetLunWriteProtect used for make lun read-only to the hosts
+func (cli *BaseClient) SetLunWriteProtect(ctx context.Context, lunID string) error {
+	return cli.UpdateLun(ctx, lunID, map[string]interface{}{"WRPROTECT": true})
+}
+
+// ClearLunWriteProtect used for make write-protected lun writable again
+func (cli *BaseClient) ClearLunWriteProtect(ctx context.Context, lunID string) error {
+	return cli.UpdateLun(ctx, lunID, map[string]interface{}{"WRPROTECT": false})
+}
+
 // GetLunCountOfMapping used for get lun count of mapping by mapping id
 func (cli *BaseClient) GetLunCountOfMapping(ctx context.Context, mappingID string) (int64, error) {
 	url := fmt.Sprintf("/lun/count?ASSOCIATEOBJTYPE=245&ASSOCIATEOBJID=%s", mappingID)
@@ -429,28 +558,12 @@ func (cli *BaseClient) GetLunCountOfHost(ctx context.Context, hostID string) (in
 func (cli *BaseClient) GetHostLunId(ctx context.Context, hostID, lunID string) (string, error) {
 	hostLunId := "1"
 	url := fmt.Sprintf("/lun/associate?TYPE=11&ASSOCIATEOBJTYPE=21&ASSOCIATEOBJID=%s", hostID)
-	resp, err := cli.Get(ctx, url, nil)
+	hostLunInfos, err := cli.getBatchObjs(ctx, url, true)
 	if err != nil {
-		return "", err
+		return "", fmt.Errorf("Get hostLunId of host %s, lun %s error: %v", hostID, lunID, err)
 	}
 
-	code := int64(resp.Error["code"].(float64))
-	if code != 0 {
-		return "", fmt.Errorf("Get hostLunId of host %s, lun %s error: %d", hostID, lunID, code)
-	}
-
-	respData, ok := resp.Data.([]interface{})
-	if !ok {
-		return "", pkgUtils.Errorf(ctx, "convert respData to arr failed, data: %v", resp.Data)
-	}
-
-	for _, i := range respData {
-		hostLunInfo, ok := i.(map[string]interface{})
-		if !ok {
-			log.AddContext(ctx).Warningf(fmt.Sprintf("convert hostLunInfo to map failed, data: %v", i))
-			continue
-		}
-
+	for _, hostLunInfo := range hostLunInfos {
 		if hostLunInfo["ID"].(string) == lunID {
 			var associateData map[string]interface{}
 			associateDataBytes := []byte(hostLunInfo["ASSOCIATEMETADATA"].(string))
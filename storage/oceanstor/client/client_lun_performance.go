@@ -0,0 +1,98 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	// the object type of lun in the performance statistics
+	lunStatisticObjectType = 11
+
+	// the data ids of the performance statistics, the bandwidth is in MB/s and the latency is in us
+	statisticTotalIOPS       = 22
+	statisticTotalBandwidth  = 21
+	statisticAvgResponseTime = 370
+
+	latencyUsPerMs = 1000
+)
+
+// LunPerformance defines interfaces for lun performance operations
+type LunPerformance interface {
+	// GetLunPerformanceData used for get the real-time performance data of the lun
+	GetLunPerformanceData(ctx context.Context, lunID string) (*LunPerformanceData, error)
+}
+
+// LunPerformanceData defines the real-time performance data of a lun
+type LunPerformanceData struct {
+	IOPS float64
+	// Bandwidth is the throughput in MB/s
+	Bandwidth float64
+	// Latency is the average response time in ms
+	Latency float64
+}
+
+// GetLunPerformanceData used for get the real-time performance data of the lun,
+// the performance monitoring must be enabled on the storage
+func (cli *BaseClient) GetLunPerformanceData(ctx context.Context, lunID string) (*LunPerformanceData, error) {
+	url := fmt.Sprintf("/performace_statistic/cur_statistic_data?CMO_STATISTIC_UUID=%d:%s"+
+		"&CMO_STATISTIC_DATA_ID_LIST=%d,%d,%d", lunStatisticObjectType, lunID,
+		statisticTotalIOPS, statisticTotalBandwidth, statisticAvgResponseTime)
+	resp, err := cli.Get(ctx, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	code := int64(resp.Error["code"].(float64))
+	if code != 0 {
+		return nil, fmt.Errorf("get performance data of lun %s error: %d", lunID, code)
+	}
+
+	respData, ok := resp.Data.([]interface{})
+	if !ok || len(respData) == 0 {
+		return nil, fmt.Errorf("performance data of lun %s is empty", lunID)
+	}
+
+	statistic, ok := respData[0].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("convert performance data of lun %s to map failed, data: %v", lunID, respData[0])
+	}
+
+	dataList, _ := statistic["CMO_STATISTIC_DATA_LIST"].(string)
+	values := strings.Split(dataList, ",")
+	if len(values) != 3 {
+		return nil, fmt.Errorf("performance data list %s of lun %s is incorrect", dataList, lunID)
+	}
+
+	var numbers [3]float64
+	for i, value := range values {
+		numbers[i], err = strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return nil, fmt.Errorf("convert performance data %s of lun %s error: %v", value, lunID, err)
+		}
+	}
+
+	return &LunPerformanceData{
+		IOPS:      numbers[0],
+		Bandwidth: numbers[1],
+		Latency:   numbers[2] / latencyUsPerMs,
+	}, nil
+}
@@ -45,6 +45,8 @@ type Qos interface {
 	ActivateQos(ctx context.Context, qosID, vStoreID string) error
 	// DeactivateQos used for deactivate qos
 	DeactivateQos(ctx context.Context, qosID, vStoreID string) error
+	// GetQosCount used for get the count of qos policies
+	GetQosCount(ctx context.Context, vStoreID string) (int64, error)
 }
 
 // CreateQos used for create qos
@@ -252,3 +254,18 @@ func (cli *BaseClient) UpdateQos(ctx context.Context, qosID, vStoreID string, pa
 
 	return nil
 }
+
+// GetQosCount used for get the count of qos policies which are existing on the storage
+func (cli *BaseClient) GetQosCount(ctx context.Context, vStoreID string) (int64, error) {
+	url := "/ioclass"
+	if vStoreID != "" {
+		url = fmt.Sprintf("/ioclass?vstoreId=%s", vStoreID)
+	}
+
+	qosList, err := cli.getBatchObjs(ctx, url, true)
+	if err != nil {
+		return 0, err
+	}
+
+	return int64(len(qosList)), nil
+}
@@ -0,0 +1,252 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"huawei-csi-driver/utils/log"
+)
+
+const (
+	// maxRecordBodyLength is the max length of the request or response body kept in a record,
+	// the longer body is truncated to bound the memory of the recorder
+	maxRecordBodyLength = 4096
+	// maxRecordDumpFiles is the max number of the dump files kept in the dump directory
+	maxRecordDumpFiles = 50
+
+	maskedValue     = "******"
+	omittedBody     = "<non-json body omitted>"
+	dumpFileSuffix  = ".json"
+	dumpFilePerm    = 0600
+	dumpDirPerm     = 0700
+	truncatedSuffix = "...<truncated>"
+)
+
+// sensitiveKeyParts are the parts of the body keys whose values are masked in the records
+var sensitiveKeyParts = []string{"password", "passwd", "token", "secret", "credential", "cookie", "chap"}
+
+// RequestRecord is a sanitized exchange with the storage
+type RequestRecord struct {
+	Time         string `json:"time"`
+	RequestID    string `json:"requestID,omitempty"`
+	Method       string `json:"method"`
+	URL          string `json:"url"`
+	RequestBody  string `json:"requestBody,omitempty"`
+	Status       int    `json:"status"`
+	ResponseBody string `json:"responseBody,omitempty"`
+	Error        string `json:"error,omitempty"`
+	Duration     string `json:"duration"`
+}
+
+// RequestRecorder keeps the last exchanges with the storage in a ring buffer, and dumps them to a file when an
+// operation fails, so that the exchanges leading to the failure can be reproduced offline.
+// A nil recorder records nothing.
+type RequestRecorder struct {
+	mutex   sync.Mutex
+	records []RequestRecord
+	next    int
+	full    bool
+	name    string
+	dumpDir string
+}
+
+// NewRequestRecorder returns the recorder keeping the last size exchanges of the backend name,
+// or nil if the size is not positive
+func NewRequestRecorder(name string, size int, dumpDir string) *RequestRecorder {
+	if size <= 0 {
+		return nil
+	}
+
+	return &RequestRecorder{
+		records: make([]RequestRecord, size),
+		name:    name,
+		dumpDir: dumpDir,
+	}
+}
+
+// Record adds the exchange to the ring buffer, the oldest exchange is overwritten if the buffer is full
+func (r *RequestRecorder) Record(ctx context.Context, method, url string, reqData map[string]interface{},
+	status int, respBody []byte, err error, duration time.Duration) {
+	if r == nil {
+		return
+	}
+
+	record := RequestRecord{
+		Time:     time.Now().Format(time.RFC3339Nano),
+		Method:   method,
+		URL:      url,
+		Status:   status,
+		Duration: duration.String(),
+	}
+	if requestID, ok := ctx.Value(log.CsiRequestID).(string); ok {
+		record.RequestID = requestID
+	}
+	if reqData != nil {
+		record.RequestBody = sanitizeBody(reqData)
+	}
+	if len(respBody) > 0 {
+		record.ResponseBody = sanitizeRawBody(respBody)
+	}
+	if err != nil {
+		record.Error = err.Error()
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.records[r.next] = record
+	r.next = (r.next + 1) % len(r.records)
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+// Records returns the recorded exchanges from the oldest to the latest
+func (r *RequestRecorder) Records() []RequestRecord {
+	if r == nil {
+		return nil
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if !r.full {
+		return append([]RequestRecord{}, r.records[:r.next]...)
+	}
+
+	return append(append([]RequestRecord{}, r.records[r.next:]...), r.records[:r.next]...)
+}
+
+// Dump writes the recorded exchanges to a file named by the request ID of ctx in the dump directory,
+// and returns the path of the file. The oldest dump files are removed to keep at most maxRecordDumpFiles files.
+func (r *RequestRecorder) Dump(ctx context.Context) (string, error) {
+	if r == nil {
+		return "", nil
+	}
+
+	requestID, ok := ctx.Value(log.CsiRequestID).(string)
+	if !ok || requestID == "" {
+		requestID = fmt.Sprintf("%d", time.Now().UnixNano())
+	}
+
+	data, err := json.MarshalIndent(r.Records(), "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	if err = os.MkdirAll(r.dumpDir, dumpDirPerm); err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(r.dumpDir, fmt.Sprintf("%s-%s%s", r.name, requestID, dumpFileSuffix))
+	if err = ioutil.WriteFile(path, data, dumpFilePerm); err != nil {
+		return "", err
+	}
+
+	pruneDumpFiles(ctx, r.dumpDir)
+	return path, nil
+}
+
+func pruneDumpFiles(ctx context.Context, dir string) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		log.AddContext(ctx).Warningf("Read request record dump directory %s error: %v", dir, err)
+		return
+	}
+
+	var dumps []os.FileInfo
+	for _, file := range files {
+		if !file.IsDir() && strings.HasSuffix(file.Name(), dumpFileSuffix) {
+			dumps = append(dumps, file)
+		}
+	}
+
+	if len(dumps) <= maxRecordDumpFiles {
+		return
+	}
+
+	sort.Slice(dumps, func(i, j int) bool { return dumps[i].ModTime().Before(dumps[j].ModTime()) })
+	for _, file := range dumps[:len(dumps)-maxRecordDumpFiles] {
+		if err = os.Remove(filepath.Join(dir, file.Name())); err != nil {
+			log.AddContext(ctx).Warningf("Remove request record dump %s error: %v", file.Name(), err)
+		}
+	}
+}
+
+// sanitizeRawBody masks the sensitive values of the json body, the body which is not json is omitted
+// since its sensitive values can not be located
+func sanitizeRawBody(body []byte) string {
+	var data interface{}
+	if err := json.Unmarshal(body, &data); err != nil {
+		return omittedBody
+	}
+
+	return sanitizeBody(data)
+}
+
+func sanitizeBody(data interface{}) string {
+	body, err := json.Marshal(maskSensitiveValues(data))
+	if err != nil {
+		return omittedBody
+	}
+
+	if len(body) > maxRecordBodyLength {
+		return string(body[:maxRecordBodyLength]) + truncatedSuffix
+	}
+	return string(body)
+}
+
+func maskSensitiveValues(data interface{}) interface{} {
+	switch value := data.(type) {
+	case map[string]interface{}:
+		masked := make(map[string]interface{}, len(value))
+		for k, v := range value {
+			if isSensitiveKey(k) {
+				masked[k] = maskedValue
+			} else {
+				masked[k] = maskSensitiveValues(v)
+			}
+		}
+		return masked
+	case []interface{}:
+		masked := make([]interface{}, len(value))
+		for i, v := range value {
+			masked[i] = maskSensitiveValues(v)
+		}
+		return masked
+	default:
+		return value
+	}
+}
+
+func isSensitiveKey(key string) bool {
+	lowerKey := strings.ToLower(key)
+	for _, part := range sensitiveKeyParts {
+		if strings.Contains(lowerKey, part) {
+			return true
+		}
+	}
+	return false
+}
@@ -130,31 +130,16 @@ func (cli *BaseClient) GetReplicationPairByResID(ctx context.Context, resID stri
 	[]map[string]interface{}, error) {
 
 	url := fmt.Sprintf("/REPLICATIONPAIR/associate?ASSOCIATEOBJTYPE=%d&ASSOCIATEOBJID=%s", resType, resID)
-	resp, err := cli.Get(ctx, url, nil)
+	pairs, err := cli.getBatchObjs(ctx, url, true)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("Get replication pairs resource %s associated error: %v", resID, err)
 	}
 
-	code := int64(resp.Error["code"].(float64))
-	if code != 0 {
-		return nil, fmt.Errorf("Get replication pairs resource %s associated error: %d", resID, code)
-	}
-
-	if resp.Data == nil {
+	if len(pairs) == 0 {
 		log.AddContext(ctx).Infof("Replication pairs resource %s associated does not exist", resID)
 		return nil, nil
 	}
 
-	var pairs []map[string]interface{}
-
-	respData, ok := resp.Data.([]interface{})
-	if !ok {
-		return nil, pkgUtils.Errorf(ctx, "convert respData to arr failed, data: %v", resp.Data)
-	}
-	for _, i := range respData {
-		pairs = append(pairs, i.(map[string]interface{}))
-	}
-
 	return pairs, nil
 }
 
@@ -0,0 +1,99 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package client
+
+import (
+	"context"
+	"fmt"
+)
+
+const (
+	// snapshotTieringStatusKey and snapshotTieringStatusCompleted follow the tiering interface below, which is not
+	// described by the REST API reference this client follows for the other snapshot operations. The tiering is
+	// never regarded as completed if the storage does not report the status.
+	snapshotTieringStatusKey       = "TIERSTATUS"
+	snapshotTieringStatusCompleted = "2"
+)
+
+// SnapshotTiering defines interfaces for tiering the lun snapshot data to the object storage
+type SnapshotTiering interface {
+	// GetCSILunSnapshots used for get the lun snapshots created by CSI
+	GetCSILunSnapshots(ctx context.Context) ([]map[string]interface{}, error)
+	// TierSnapshotToCloud used for tier the data of the lun snapshot to the object storage
+	TierSnapshotToCloud(ctx context.Context, snapshotName string) error
+	// IsSnapshotTieredToCloud used for check whether the tiering of the lun snapshot is completed
+	IsSnapshotTieredToCloud(ctx context.Context, snapshotName string) (bool, error)
+}
+
+// GetCSILunSnapshots used for get the lun snapshots created by CSI
+func (cli *BaseClient) GetCSILunSnapshots(ctx context.Context) ([]map[string]interface{}, error) {
+	snapshots, err := cli.getBatchObjs(ctx, "/snapshot", true)
+	if err != nil {
+		return nil, err
+	}
+
+	var csiSnapshots []map[string]interface{}
+	for _, snapshot := range snapshots {
+		if snapshot["DESCRIPTION"] == description {
+			csiSnapshots = append(csiSnapshots, snapshot)
+		}
+	}
+	return csiSnapshots, nil
+}
+
+// TierSnapshotToCloud used for tier the data of the lun snapshot to the object storage,
+// the tiering runs asynchronously on the storage
+func (cli *BaseClient) TierSnapshotToCloud(ctx context.Context, snapshotName string) error {
+	snapshot, err := cli.GetLunSnapshotByName(ctx, snapshotName)
+	if err != nil {
+		return err
+	}
+
+	if snapshot == nil {
+		return fmt.Errorf("snapshot %s to tier does not exist", snapshotName)
+	}
+
+	data := map[string]interface{}{
+		"ID": snapshot["ID"],
+	}
+
+	resp, err := cli.Put(ctx, "/snapshot/tier_to_cloud", data)
+	if err != nil {
+		return err
+	}
+
+	code := int64(resp.Error["code"].(float64))
+	if code != 0 {
+		return fmt.Errorf("tier snapshot %s to cloud error: %d", snapshotName, code)
+	}
+
+	return nil
+}
+
+// IsSnapshotTieredToCloud used for check whether the tiering of the lun snapshot is completed
+func (cli *BaseClient) IsSnapshotTieredToCloud(ctx context.Context, snapshotName string) (bool, error) {
+	snapshot, err := cli.GetLunSnapshotByName(ctx, snapshotName)
+	if err != nil {
+		return false, err
+	}
+
+	if snapshot == nil {
+		return false, fmt.Errorf("tiered snapshot %s does not exist", snapshotName)
+	}
+
+	return fmt.Sprintf("%v", snapshot[snapshotTieringStatusKey]) == snapshotTieringStatusCompleted, nil
+}
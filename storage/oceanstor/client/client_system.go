@@ -20,6 +20,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 
 	pkgUtils "huawei-csi-driver/pkg/utils"
 	"huawei-csi-driver/utils/log"
@@ -31,6 +32,12 @@ type System interface {
 	GetPoolByName(ctx context.Context, name string) (map[string]interface{}, error)
 	// GetAllPools used for get all pools
 	GetAllPools(ctx context.Context) (map[string]interface{}, error)
+	// GetPoolByTags used for get the pools whose tags include all the tags
+	GetPoolByTags(ctx context.Context, tags map[string]string) ([]map[string]interface{}, error)
+	// MatchPoolTags checks whether the tags of the pool include all the tags
+	MatchPoolTags(poolName string, tags map[string]string) bool
+	// ExpandStoragePool used for add capacity to the pool from the spare disk resources by SmartProvision
+	ExpandStoragePool(ctx context.Context, poolID string, additionalCapacityGB int64) error
 	// GetSystem used for get system info
 	GetSystem(ctx context.Context) (map[string]interface{}, error)
 	// GetLicenseFeature used for get license feature
@@ -39,10 +46,45 @@ type System interface {
 	GetRemoteDeviceBySN(ctx context.Context, sn string) (map[string]interface{}, error)
 	// GetAllRemoteDevices used for get all remote devices
 	GetAllRemoteDevices(ctx context.Context) ([]map[string]interface{}, error)
+	// GetAllControllers used for get all controllers
+	GetAllControllers(ctx context.Context) ([]map[string]interface{}, error)
 	// GetDeviceSN used for get device sn
 	GetDeviceSN() string
 	// GetStorageVersion used for get storage version
 	GetStorageVersion() string
+	// GetStorageModel used for get storage model cached by GetSystem
+	GetStorageModel() string
+	// GetFirmwareVersion used for get storage firmware version cached by GetSystem
+	GetFirmwareVersion() string
+	// GetDomainByName used for get the LDAP auth domain configured on the storage
+	GetDomainByName(ctx context.Context, name string) (map[string]interface{}, error)
+}
+
+// GetDomainByName used for get the LDAP auth domain configured on the storage by name, the LDAP domains are
+// queried from the ldap_domain objects, nil is returned if the domain does not exist
+func (cli *BaseClient) GetDomainByName(ctx context.Context, name string) (map[string]interface{}, error) {
+	url := fmt.Sprintf("/ldap_domain?filter=NAME::%s", name)
+	resp, err := cli.Get(ctx, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	code := int64(resp.Error["code"].(float64))
+	if code != 0 {
+		return nil, fmt.Errorf("get auth domain %s error: %d", name, code)
+	}
+
+	respData, ok := resp.Data.([]interface{})
+	if !ok || len(respData) == 0 {
+		log.AddContext(ctx).Infof("Auth domain %s does not exist", name)
+		return nil, nil
+	}
+
+	domain, ok := respData[0].(map[string]interface{})
+	if !ok {
+		return nil, pkgUtils.Errorf(ctx, "convert auth domain to map failed, data: %v", respData[0])
+	}
+	return domain, nil
 }
 
 // GetPoolByName used for get pool by name
@@ -80,36 +122,44 @@ func (cli *BaseClient) GetPoolByName(ctx context.Context, name string) (map[stri
 	return pool, nil
 }
 
-// GetAllPools used for get all pools
-func (cli *BaseClient) GetAllPools(ctx context.Context) (map[string]interface{}, error) {
-	resp, err := cli.Get(ctx, "/storagepool", nil)
+// ExpandStoragePool used for add capacity to the pool from the spare disk resources by SmartProvision,
+// the expansion runs asynchronously on the storage.
+// The expansion interface is not described by the REST API reference this client follows for the other pool
+// operations, so any error code is taken as the storage not supporting it and the pool is left unexpanded.
+func (cli *BaseClient) ExpandStoragePool(ctx context.Context, poolID string, additionalCapacityGB int64) error {
+	data := map[string]interface{}{
+		"ID":          poolID,
+		"ADDCAPACITY": additionalCapacityGB,
+	}
+
+	resp, err := cli.Put(ctx, "/storagepool/expand", data)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	code := int64(resp.Error["code"].(float64))
 	if code != 0 {
-		msg := fmt.Sprintf("Get all pools info error: %d", code)
-		return nil, errors.New(msg)
+		return fmt.Errorf("expand pool %s by %dGB error: %d, %v", poolID, additionalCapacityGB, code,
+			resp.Error["description"])
 	}
 
-	if resp.Data == nil {
+	return nil
+}
+
+// GetAllPools used for get all pools
+func (cli *BaseClient) GetAllPools(ctx context.Context) (map[string]interface{}, error) {
+	respData, err := cli.getBatchObjs(ctx, "/storagepool", true)
+	if err != nil {
+		return nil, fmt.Errorf("Get all pools info error: %v", err)
+	}
+
+	if len(respData) == 0 {
 		log.AddContext(ctx).Infof("There's no pools exist")
 		return nil, nil
 	}
 
 	pools := make(map[string]interface{})
-
-	respData, ok := resp.Data.([]interface{})
-	if !ok {
-		return nil, pkgUtils.Errorf(ctx, "convert resp.Data to arr failed, data: %v", resp.Data)
-	}
-	for _, p := range respData {
-		pool, ok := p.(map[string]interface{})
-		if !ok {
-			log.AddContext(ctx).Warningf(fmt.Sprintf("convert pool to map failed, data: %v", p))
-			continue
-		}
+	for _, pool := range respData {
 		name, ok := pool["NAME"].(string)
 		if !ok {
 			log.AddContext(ctx).Warningf(fmt.Sprintf("convert name to map failed, data: %v", pool["NAME"]))
@@ -121,6 +171,60 @@ func (cli *BaseClient) GetAllPools(ctx context.Context) (map[string]interface{},
 	return pools, nil
 }
 
+// GetPoolByTags used for get the pools whose tags include all the tags, the tags of the pools are configured by
+// the poolTags of the backend, so only the pools which exist on the storage are returned
+func (cli *BaseClient) GetPoolByTags(ctx context.Context, tags map[string]string) ([]map[string]interface{}, error) {
+	pools, err := cli.GetAllPools(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var matchedPools []map[string]interface{}
+	for name, p := range pools {
+		pool, ok := p.(map[string]interface{})
+		if ok && cli.MatchPoolTags(name, tags) {
+			matchedPools = append(matchedPools, pool)
+		}
+	}
+
+	return matchedPools, nil
+}
+
+// MatchPoolTags checks whether the tags of the pool configured by the poolTags of the backend include all the tags
+func (cli *BaseClient) MatchPoolTags(poolName string, tags map[string]string) bool {
+	poolTags, exist := cli.PoolTags[poolName]
+	return exist && containsPoolTags(poolTags, tags)
+}
+
+// ParsePoolTags parses the comma-separated key=value pairs to the pool tags
+func ParsePoolTags(tags string) (map[string]string, error) {
+	result := make(map[string]string)
+	for _, pair := range strings.Split(tags, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+
+		key, value, found := strings.Cut(pair, "=")
+		key = strings.TrimSpace(key)
+		if !found || key == "" {
+			return nil, fmt.Errorf("invalid pool tag %q, the format must be key=value", pair)
+		}
+		result[key] = strings.TrimSpace(value)
+	}
+
+	return result, nil
+}
+
+func containsPoolTags(poolTags, tags map[string]string) bool {
+	for key, value := range tags {
+		if poolValue, exist := poolTags[key]; !exist || poolValue != value {
+			return false
+		}
+	}
+
+	return true
+}
+
 // GetLicenseFeature used for get license feature
 func (cli *BaseClient) GetLicenseFeature(ctx context.Context) (map[string]int, error) {
 	resp, err := cli.Get(ctx, "/license/feature", nil)
@@ -175,6 +279,7 @@ func (cli *BaseClient) GetSystem(ctx context.Context) (map[string]interface{}, e
 		return nil, pkgUtils.Errorf(ctx, "convert respData to map failed, data: %v", resp.Data)
 	}
 	cli.setStorageVersion(respData)
+	cli.setStorageInventory(respData)
 	return respData, nil
 }
 
@@ -218,6 +323,11 @@ func (cli *BaseClient) GetAllRemoteDevices(ctx context.Context) ([]map[string]in
 	return cli.getBatchObjs(ctx, "/remote_device", true)
 }
 
+// GetAllControllers used for get all controllers
+func (cli *BaseClient) GetAllControllers(ctx context.Context) ([]map[string]interface{}, error) {
+	return cli.getBatchObjs(ctx, "/controller", true)
+}
+
 // GetDeviceSN used for get device sn
 func (cli *BaseClient) GetDeviceSN() string {
 	return cli.DeviceId
@@ -234,3 +344,29 @@ func (cli *BaseClient) setStorageVersion(systemInfo map[string]interface{}) {
 func (cli *BaseClient) GetStorageVersion() string {
 	return cli.StorageVersion
 }
+
+// setStorageInventory caches the model and the firmware version of the storage, the readable model name is
+// preferred to the model code which is returned by all versions
+func (cli *BaseClient) setStorageInventory(systemInfo map[string]interface{}) {
+	if model, ok := systemInfo["productModeString"].(string); ok && model != "" {
+		cli.StorageModel = model
+	} else if model, ok = systemInfo["PRODUCTMODE"].(string); ok {
+		cli.StorageModel = model
+	}
+
+	if version, ok := systemInfo["pointRelease"].(string); ok && version != "" {
+		cli.FirmwareVersion = version
+	} else if version, ok = systemInfo["PRODUCTVERSION"].(string); ok {
+		cli.FirmwareVersion = version
+	}
+}
+
+// GetStorageModel used for get storage model cached by GetSystem
+func (cli *BaseClient) GetStorageModel() string {
+	return cli.StorageModel
+}
+
+// GetFirmwareVersion used for get storage firmware version cached by GetSystem
+func (cli *BaseClient) GetFirmwareVersion() string {
+	return cli.FirmwareVersion
+}
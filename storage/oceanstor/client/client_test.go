@@ -20,15 +20,18 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"fmt"
 	"io/ioutil"
 	"net/http"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/agiledragon/gomonkey/v2"
 	"github.com/golang/mock/gomock"
 	"github.com/prashantv/gostub"
 	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/trace"
 
 	"huawei-csi-driver/csi/app"
 	cfg "huawei-csi-driver/csi/app/config"
@@ -107,6 +110,36 @@ func getTestLoginPatches() *gomonkey.Patches {
 	return m
 }
 
+func TestStartRequestSpan(t *testing.T) {
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	spanContext := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: trace.FlagsSampled,
+	})
+
+	cases := []struct {
+		name            string
+		ctx             context.Context
+		wantTraceParent string
+	}{
+		{"WithoutSpan", context.Background(), ""},
+		{"WithSpan", trace.ContextWithSpanContext(context.Background(), spanContext),
+			"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", "https://127.0.0.1:8088/deviceManager/rest/lun", nil)
+			startRequestSpan(c.ctx, req)()
+			if got := req.Header.Get("traceparent"); got != c.wantTraceParent {
+				t.Errorf("startRequestSpan() traceparent = %s, want %s", got, c.wantTraceParent)
+			}
+		})
+	}
+}
+
 func TestLogout(t *testing.T) {
 	var cases = []struct {
 		Name         string
@@ -866,7 +899,7 @@ func TestCreateHost(t *testing.T) {
 			}, nil
 		}).AnyTimes()
 
-		_, err := testClient.CreateHost(context.TODO(), "")
+		_, err := testClient.CreateHost(context.TODO(), "", "")
 		assert.Equal(t, s.wantErr, err != nil, "%s, err:%v", s.Name, err)
 	}
 }
@@ -1702,3 +1735,235 @@ func TestMain(m *testing.M) {
 
 	m.Run()
 }
+
+func TestParsePoolTags(t *testing.T) {
+	cases := []struct {
+		Name     string
+		Tags     string
+		wantTags map[string]string
+		wantErr  bool
+	}{
+		{"Normal", "tier=gold, zone=a", map[string]string{"tier": "gold", "zone": "a"}, false},
+		{"Empty", "", map[string]string{}, false},
+		{"Missing value separator", "tier", nil, true},
+		{"Empty key", "=gold", nil, true},
+	}
+
+	for _, c := range cases {
+		tags, err := ParsePoolTags(c.Tags)
+		assert.Equal(t, c.wantErr, err != nil, "%s, err:%v", c.Name, err)
+		assert.Equal(t, c.wantTags, tags, c.Name)
+	}
+}
+
+func TestGetPagedObjs(t *testing.T) {
+	cases := []struct {
+		Name      string
+		Limit     int
+		wantCount int
+		wantPages int
+	}{
+		{"All pages", 0, 230, 3},
+		{"Limit within the first page", 50, 50, 1},
+		{"Limit across the pages", 150, 150, 2},
+	}
+
+	for _, c := range cases {
+		ctrl := gomock.NewController(t)
+		mockClient := NewMockHTTPClient(ctrl)
+		temp := testClient.Client
+		testClient.Client = mockClient
+
+		var ranges []string
+		mockClient.EXPECT().Do(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+			ranges = append(ranges, req.URL.Query().Get("range"))
+			count := QueryCountPerBatch
+			if len(ranges) == 3 {
+				count = 30
+			}
+
+			objs := make([]string, 0, count)
+			for i := 0; i < count; i++ {
+				objs = append(objs, fmt.Sprintf("{\"ID\":\"%d\"}", (len(ranges)-1)*QueryCountPerBatch+i))
+			}
+			body := fmt.Sprintf("{\"data\":[%s],\"error\":{\"code\":0}}", strings.Join(objs, ","))
+			return &http.Response{
+				StatusCode: int(successStatus),
+				Body:       ioutil.NopCloser(bytes.NewReader([]byte(body))),
+			}, nil
+		}).AnyTimes()
+
+		objs, err := testClient.getPagedObjs(context.TODO(), "/lun/associate?TYPE=11", c.Limit, true)
+		assert.NoError(t, err, c.Name)
+		assert.Equal(t, c.wantCount, len(objs), c.Name)
+		assert.Equal(t, c.wantPages, len(ranges), c.Name)
+		assert.Equal(t, "[0-100]", ranges[0], c.Name)
+		assert.Equal(t, fmt.Sprintf("%d", c.wantCount-1), objs[len(objs)-1]["ID"], c.Name)
+
+		testClient.Client = temp
+		ctrl.Finish()
+	}
+}
+
+func TestGetQosCount(t *testing.T) {
+	cases := []struct {
+		Name      string
+		VStoreID  string
+		Count     int
+		wantQuery string
+	}{
+		{"Qos policies of the system vstore", "", 30, ""},
+		{"Qos policies across the pages", "", 130, ""},
+		{"Qos policies of the vstore", "1", 30, "1"},
+	}
+
+	for _, c := range cases {
+		ctrl := gomock.NewController(t)
+		mockClient := NewMockHTTPClient(ctrl)
+		temp := testClient.Client
+		testClient.Client = mockClient
+
+		var vStoreIDs []string
+		mockClient.EXPECT().Do(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+			vStoreIDs = append(vStoreIDs, req.URL.Query().Get("vstoreId"))
+			count := c.Count - (len(vStoreIDs)-1)*QueryCountPerBatch
+			if count > QueryCountPerBatch {
+				count = QueryCountPerBatch
+			}
+
+			objs := make([]string, 0, count)
+			for i := 0; i < count; i++ {
+				objs = append(objs, fmt.Sprintf("{\"ID\":\"%d\"}", i))
+			}
+			body := fmt.Sprintf("{\"data\":[%s],\"error\":{\"code\":0}}", strings.Join(objs, ","))
+			return &http.Response{
+				StatusCode: int(successStatus),
+				Body:       ioutil.NopCloser(bytes.NewReader([]byte(body))),
+			}, nil
+		}).AnyTimes()
+
+		count, err := testClient.GetQosCount(context.TODO(), c.VStoreID)
+		assert.NoError(t, err, c.Name)
+		assert.Equal(t, int64(c.Count), count, c.Name)
+		assert.Equal(t, c.wantQuery, vStoreIDs[0], c.Name)
+
+		testClient.Client = temp
+		ctrl.Finish()
+	}
+}
+
+func TestRequestRecorderMasksCredentials(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockClient := NewMockHTTPClient(ctrl)
+	mockClient.EXPECT().Do(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+		body := "{\"data\":{\"deviceid\":\"2102352TRW10KB000001\",\"iBaseToken\":\"mock-token-value\"}," +
+			"\"error\":{\"code\":0}}"
+		return &http.Response{
+			StatusCode: int(successStatus),
+			Body:       ioutil.NopCloser(bytes.NewReader([]byte(body))),
+		}, nil
+	}).AnyTimes()
+
+	dumpDir := t.TempDir()
+	cli := &BaseClient{
+		Client:   mockClient,
+		Url:      "https://127.0.0.1:8088/deviceManager/rest",
+		Recorder: NewRequestRecorder("backend", 3, dumpDir),
+	}
+
+	ctx := context.WithValue(context.Background(), log.CsiRequestID, "mock-request-id")
+	_, err := cli.BaseCall(ctx, "POST", "/xx/sessions", map[string]interface{}{
+		"username": "admin",
+		"password": "mock-password-value",
+	})
+	assert.NoError(t, err)
+	_, err = cli.BaseCall(ctx, "PUT", "/iscsi_initiator/iqn", map[string]interface{}{
+		"CHAPINFO": "admin;mock-chap-value",
+		"settings": []interface{}{map[string]interface{}{"newPassword": "mock-new-password"}},
+	})
+	assert.NoError(t, err)
+	_, err = cli.BaseCall(ctx, "GET", "/lun?filter=NAME::pvc-1", nil)
+	assert.NoError(t, err)
+
+	path, err := cli.DumpRequestRecords(ctx)
+	assert.NoError(t, err)
+	assert.True(t, strings.Contains(path, "mock-request-id"))
+
+	dump, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	for _, secret := range []string{"mock-password-value", "mock-token-value", "mock-chap-value", "mock-new-password"} {
+		assert.False(t, strings.Contains(string(dump), secret), "%s appears in the dump", secret)
+	}
+	assert.True(t, strings.Contains(string(dump), "2102352TRW10KB000001"))
+
+	_, err = cli.BaseCall(ctx, "GET", "/lun?filter=NAME::pvc-2", nil)
+	assert.NoError(t, err)
+	records := cli.Recorder.Records()
+	assert.Equal(t, 3, len(records), "the ring buffer keeps only the last exchanges")
+	assert.Equal(t, "PUT", records[0].Method)
+	assert.Equal(t, "mock-request-id", records[2].RequestID)
+}
+
+func TestGetNFSAccessLog(t *testing.T) {
+	cases := []struct {
+		Name        string
+		AuditLog    string
+		wantErr     error
+		wantRecords int
+	}{
+		{"Audit log enabled", "true", nil, 2},
+		{"Audit log disabled", "false", ErrNFSAuditLogDisabled, 0},
+	}
+
+	for _, c := range cases {
+		ctrl := gomock.NewController(t)
+		mockClient := NewMockHTTPClient(ctrl)
+		temp := testClient.Client
+		testClient.Client = mockClient
+
+		mockClient.EXPECT().Do(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+			body := fmt.Sprintf("{\"data\":{\"ENABLEAUDITLOG\":\"%s\"},\"error\":{\"code\":0}}", c.AuditLog)
+			if strings.Contains(req.URL.Path, "/nfs_access_log") {
+				body = "{\"data\":[{\"CLIENTIP\":\"192.168.1.10\",\"OPERATIONTYPE\":\"write\"," +
+					"\"FILEPATH\":\"/pvc-1/a.txt\",\"TIMESTAMP\":\"1685606400\"},{\"CLIENTIP\":\"192.168.1.11\"," +
+					"\"OPERATIONTYPE\":\"read\",\"FILEPATH\":\"/pvc-1/b.txt\",\"TIMESTAMP\":\"1685606460\"}]," +
+					"\"error\":{\"code\":0}}"
+			}
+			return &http.Response{
+				StatusCode: int(successStatus),
+				Body:       ioutil.NopCloser(bytes.NewReader([]byte(body))),
+			}, nil
+		}).AnyTimes()
+
+		records, err := testClient.GetNFSAccessLog(context.TODO(), "fs-1", "/pvc-1", 1685606000)
+		assert.Equal(t, c.wantErr, err, c.Name)
+		assert.Equal(t, c.wantRecords, len(records), c.Name)
+
+		testClient.Client = temp
+		ctrl.Finish()
+	}
+}
+
+func TestNewLoginData(t *testing.T) {
+	tests := []struct {
+		name       string
+		cli        *BaseClient
+		wantScope  string
+		wantDomain interface{}
+	}{
+		{name: "LocalUser", cli: &BaseClient{User: "admin"}, wantScope: "0"},
+		{name: "DomainUser", cli: &BaseClient{User: "csi", AuthDomain: "corp.example.com"},
+			wantScope: "1", wantDomain: "corp.example.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := tt.cli.newLoginData("password")
+			if data["scope"] != tt.wantScope || data["DOMAIN"] != tt.wantDomain {
+				t.Errorf("newLoginData() = %v, want scope %s and DOMAIN %v", data, tt.wantScope, tt.wantDomain)
+			}
+		})
+	}
+}
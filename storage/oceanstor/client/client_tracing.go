@@ -0,0 +1,41 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package client
+
+import (
+	"context"
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "huawei-csi-driver/storage/oceanstor/client"
+
+// traceContextPropagator injects the traceparent and tracestate headers of W3C Trace Context
+var traceContextPropagator = propagation.TraceContext{}
+
+// startRequestSpan starts a client span of the request to the storage and injects its W3C Trace Context headers
+// into the request, so that the request can be correlated with the audit logs of the storage. The span is a child
+// of the span in ctx if present, and the returned function must be called to end it when the request completes.
+func startRequestSpan(ctx context.Context, req *http.Request) func() {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, req.Method+" "+req.URL.Path,
+		trace.WithSpanKind(trace.SpanKindClient))
+	traceContextPropagator.Inject(ctx, propagation.HeaderCarrier(req.Header))
+	return func() { span.End() }
+}
@@ -0,0 +1,53 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package client
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestStartRequestSpanExported(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	defer otel.SetTracerProvider(previous)
+
+	req, err := http.NewRequest(http.MethodGet, "https://127.0.0.1:8088/deviceManager/rest/sn/lun", nil)
+	if err != nil {
+		t.Fatalf("NewRequest() error = %v", err)
+	}
+	startRequestSpan(context.Background(), req)()
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 || spans[0].Name != "GET /deviceManager/rest/sn/lun" {
+		t.Fatalf("exported spans = %v, want one span of the request", spans)
+	}
+
+	traceparent := req.Header.Get("traceparent")
+	if !strings.Contains(traceparent, spans[0].SpanContext.TraceID().String()) ||
+		!strings.Contains(traceparent, spans[0].SpanContext.SpanID().String()) {
+		t.Errorf("traceparent header = %s, want the trace and span of %v", traceparent, spans[0].SpanContext)
+	}
+}
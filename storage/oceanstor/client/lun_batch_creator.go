@@ -0,0 +1,209 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package client
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"huawei-csi-driver/utils"
+	"huawei-csi-driver/utils/log"
+)
+
+// maxLunBatchSize is the max count of luns created in one batch request
+const maxLunBatchSize = 64
+
+type lunCreateTask struct {
+	ctx     context.Context
+	params  map[string]interface{}
+	request LunCreateRequest
+	result  chan lunCreateResult
+}
+
+type lunCreateResult struct {
+	lun map[string]interface{}
+	err error
+	// createByCaller is set when the lun is not created in a batch, the caller creates it on its own goroutine
+	// so that the collector is never blocked by the creations one by one
+	createByCaller bool
+}
+
+// LunBatchCreator collects the luns created within the window and creates them in one batch request,
+// which reduces the requests to the storage when many volumes are provisioned at the same time
+type LunBatchCreator struct {
+	cli    Lun
+	window time.Duration
+
+	// batchRejected is set to 1 when the storage rejects the batch creation, then the callers create their luns
+	// by themselves without waiting for the window
+	batchRejected int32
+
+	tasks     chan *lunCreateTask
+	stopCh    chan struct{}
+	startOnce sync.Once
+	stopOnce  sync.Once
+}
+
+// NewLunBatchCreator inits a new lun batch creator, the collector is started on the first creation
+func NewLunBatchCreator(cli Lun, window time.Duration) *LunBatchCreator {
+	return &LunBatchCreator{
+		cli:    cli,
+		window: window,
+		tasks:  make(chan *lunCreateTask),
+		stopCh: make(chan struct{}),
+	}
+}
+
+// CreateLun used for create lun in the next batch, it has the same parameters as the CreateLun of the client
+func (c *LunBatchCreator) CreateLun(ctx context.Context,
+	params map[string]interface{}) (map[string]interface{}, error) {
+	request, err := NewLunCreateRequest(params)
+	if err != nil {
+		return nil, err
+	}
+
+	if atomic.LoadInt32(&c.batchRejected) == 1 {
+		return c.createOne(ctx, request.Name, params)
+	}
+
+	c.startOnce.Do(func() { go c.collect() })
+
+	task := &lunCreateTask{ctx: ctx, params: params, request: request, result: make(chan lunCreateResult, 1)}
+	select {
+	case c.tasks <- task:
+	case <-c.stopCh:
+		return nil, errors.New("lun batch creator is stopped")
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	select {
+	case result := <-task.result:
+		if result.createByCaller {
+			return c.createOne(ctx, request.Name, params)
+		}
+		return result.lun, result.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Stop stops the collector, the collected luns are still created
+func (c *LunBatchCreator) Stop() {
+	c.stopOnce.Do(func() { close(c.stopCh) })
+}
+
+func (c *LunBatchCreator) collect() {
+	for {
+		var batch []*lunCreateTask
+		select {
+		case task := <-c.tasks:
+			batch = append(batch, task)
+		case <-c.stopCh:
+			return
+		}
+
+		timer := time.NewTimer(c.window)
+	collecting:
+		for len(batch) < maxLunBatchSize {
+			select {
+			case task := <-c.tasks:
+				batch = append(batch, task)
+			case <-timer.C:
+				break collecting
+			}
+		}
+		timer.Stop()
+
+		c.createBatch(batch)
+	}
+}
+
+func (c *LunBatchCreator) createBatch(batch []*lunCreateTask) {
+	// the callers which are gone are not waiting for the luns any more
+	pending := make([]*lunCreateTask, 0, len(batch))
+	for _, task := range batch {
+		if err := task.ctx.Err(); err != nil {
+			task.result <- lunCreateResult{err: err}
+			continue
+		}
+		pending = append(pending, task)
+	}
+
+	if len(pending) == 0 {
+		return
+	}
+
+	if len(pending) == 1 {
+		returnToCallers(pending)
+		return
+	}
+
+	// the batch request serves all the callers, so it must not be canceled with the context of any one of them
+	ctx := utils.NewContextWithRequestID()
+	requests := make([]LunCreateRequest, 0, len(pending))
+	names := make([]string, 0, len(pending))
+	for _, task := range pending {
+		requests = append(requests, task.request)
+		names = append(names, task.request.Name)
+	}
+
+	luns, err := c.cli.BatchCreateLuns(ctx, requests)
+	if err != nil {
+		if errors.Is(err, ErrBatchCreateRejected) {
+			atomic.StoreInt32(&c.batchRejected, 1)
+			log.AddContext(ctx).Warningf("The storage rejects the batch creation of luns, create the luns one "+
+				"by one from now on, error: %v", err)
+		} else {
+			log.AddContext(ctx).Warningf("Batch create luns %v failed, create them one by one, error: %v",
+				names, err)
+		}
+		returnToCallers(pending)
+		return
+	}
+
+	log.AddContext(ctx).Infof("Batch created luns %v", names)
+	for i, task := range pending {
+		log.AddContext(task.ctx).Infof("Lun %s is created in the batch of %d luns", task.request.Name,
+			len(pending))
+		task.result <- lunCreateResult{lun: luns[i]}
+	}
+}
+
+// returnToCallers hands the luns back to their callers, which create them one by one concurrently
+func returnToCallers(tasks []*lunCreateTask) {
+	for _, task := range tasks {
+		task.result <- lunCreateResult{createByCaller: true}
+	}
+}
+
+// createOne creates the lun by itself, the lun may be already created by the failed batch request
+func (c *LunBatchCreator) createOne(ctx context.Context, name string,
+	params map[string]interface{}) (map[string]interface{}, error) {
+	lun, err := c.cli.GetLunByName(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	if lun == nil {
+		lun, err = c.cli.CreateLun(ctx, params)
+	}
+	return lun, err
+}
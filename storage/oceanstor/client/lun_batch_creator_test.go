@@ -0,0 +1,176 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeBatchLunClient struct {
+	Lun
+	batchErr error
+
+	mutex       sync.Mutex
+	batchCalls  int
+	createCalls int
+}
+
+func (f *fakeBatchLunClient) BatchCreateLuns(ctx context.Context,
+	requests []LunCreateRequest) ([]map[string]interface{}, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.batchCalls++
+	if f.batchErr != nil {
+		return nil, f.batchErr
+	}
+
+	luns := make([]map[string]interface{}, 0, len(requests))
+	for _, request := range requests {
+		luns = append(luns, map[string]interface{}{"NAME": request.Name})
+	}
+	return luns, nil
+}
+
+func (f *fakeBatchLunClient) GetLunByName(ctx context.Context, name string) (map[string]interface{}, error) {
+	return nil, nil
+}
+
+func (f *fakeBatchLunClient) CreateLun(ctx context.Context,
+	params map[string]interface{}) (map[string]interface{}, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.createCalls++
+	return map[string]interface{}{"NAME": params["name"]}, nil
+}
+
+func TestLunBatchCreatorCreateLun(t *testing.T) {
+	const lunCount = 3
+	cases := []struct {
+		name            string
+		batchErr        error
+		wantBatchCalls  int
+		wantCreateCalls int
+	}{
+		{"Created in one batch", nil, 1, 0},
+		{"Fall back to one by one", errors.New("batch create luns error: 1"), 1, lunCount},
+	}
+
+	for _, c := range cases {
+		cli := &fakeBatchLunClient{batchErr: c.batchErr}
+		creator := NewLunBatchCreator(cli, time.Second)
+
+		var wg sync.WaitGroup
+		errs := make(chan error, lunCount)
+		for i := 0; i < lunCount; i++ {
+			wg.Add(1)
+			go func(name string) {
+				defer wg.Done()
+				lun, err := creator.CreateLun(context.Background(), map[string]interface{}{
+					"name": name, "parentid": "0", "capacity": int64(2097152), "alloctype": 1,
+				})
+				if err == nil && lun["NAME"] != name {
+					err = fmt.Errorf("want lun %s, but got %v", name, lun)
+				}
+				errs <- err
+			}(fmt.Sprintf("pvc-%d", i))
+		}
+		wg.Wait()
+		creator.Stop()
+		close(errs)
+
+		for err := range errs {
+			if err != nil {
+				t.Errorf("%s: create lun failed, error: %v", c.name, err)
+			}
+		}
+		if cli.batchCalls != c.wantBatchCalls || cli.createCalls != c.wantCreateCalls {
+			t.Errorf("%s: want %d batch calls and %d create calls, but got %d and %d", c.name,
+				c.wantBatchCalls, c.wantCreateCalls, cli.batchCalls, cli.createCalls)
+		}
+	}
+}
+
+func newTestLunCreateTask(t *testing.T, ctx context.Context, name string) *lunCreateTask {
+	params := map[string]interface{}{"name": name, "parentid": "0", "capacity": int64(2097152), "alloctype": 1}
+	request, err := NewLunCreateRequest(params)
+	if err != nil {
+		t.Fatalf("new lun create request failed, error: %v", err)
+	}
+
+	return &lunCreateTask{ctx: ctx, params: params, request: request, result: make(chan lunCreateResult, 1)}
+}
+
+func TestLunBatchCreatorCreateBatch(t *testing.T) {
+	canceledCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	cli := &fakeBatchLunClient{}
+	creator := NewLunBatchCreator(cli, time.Second)
+	tasks := []*lunCreateTask{
+		newTestLunCreateTask(t, canceledCtx, "pvc-0"),
+		newTestLunCreateTask(t, context.Background(), "pvc-1"),
+		newTestLunCreateTask(t, context.Background(), "pvc-2"),
+	}
+	creator.createBatch(tasks)
+
+	if result := <-tasks[0].result; !errors.Is(result.err, context.Canceled) {
+		t.Errorf("want the canceled lun to fail with %v, but got %v", context.Canceled, result.err)
+	}
+	for _, task := range tasks[1:] {
+		if result := <-task.result; result.err != nil || result.lun["NAME"] != task.request.Name {
+			t.Errorf("want lun %s created, but got %v, error: %v", task.request.Name, result.lun, result.err)
+		}
+	}
+	if cli.batchCalls != 1 || cli.createCalls != 0 {
+		t.Errorf("want 1 batch call and 0 create calls, but got %d and %d", cli.batchCalls, cli.createCalls)
+	}
+}
+
+func TestLunBatchCreatorStopsBatchingWhenRejected(t *testing.T) {
+	cli := &fakeBatchLunClient{batchErr: fmt.Errorf("%w, batch create 2 luns error: 1", ErrBatchCreateRejected)}
+	creator := NewLunBatchCreator(cli, time.Hour)
+	defer creator.Stop()
+
+	// the rejected luns are handed back to their callers instead of being created by the collector
+	tasks := []*lunCreateTask{
+		newTestLunCreateTask(t, context.Background(), "pvc-0"),
+		newTestLunCreateTask(t, context.Background(), "pvc-1"),
+	}
+	creator.createBatch(tasks)
+	for _, task := range tasks {
+		if result := <-task.result; !result.createByCaller {
+			t.Errorf("want lun %s handed back to the caller, but got %v", task.request.Name, result)
+		}
+	}
+
+	// the window is never waited for once the batch creation is rejected
+	lun, err := creator.CreateLun(context.Background(), map[string]interface{}{
+		"name": "pvc-2", "parentid": "0", "capacity": int64(2097152), "alloctype": 1,
+	})
+	if err != nil || lun["NAME"] != "pvc-2" {
+		t.Errorf("want lun pvc-2 created, but got %v, error: %v", lun, err)
+	}
+
+	if cli.batchCalls != 1 || cli.createCalls != 1 {
+		t.Errorf("want 1 batch call and 1 create call, but got %d and %d", cli.batchCalls, cli.createCalls)
+	}
+}
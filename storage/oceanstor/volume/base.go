@@ -21,6 +21,7 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
+	"time"
 
 	pkgUtils "huawei-csi-driver/pkg/utils"
 	"huawei-csi-driver/storage/oceanstor/client"
@@ -29,6 +30,38 @@ import (
 	"huawei-csi-driver/utils/log"
 )
 
+const (
+	allocTypeThick = 0
+	allocTypeThin  = 1
+
+	deletingObjectWaitTimeout  = 2 * time.Minute
+	deletingObjectWaitInterval = 2 * time.Second
+
+	expandedCapacityWaitTimeout  = 2 * time.Minute
+	expandedCapacityWaitInterval = 2 * time.Second
+
+	encryptionLicenseFeature = "HyperEncryption"
+)
+
+// cloneAllocTypeRule is an alloc type of the clone target volume which the storage product rejects
+type cloneAllocTypeRule struct {
+	allocType int
+	reason    string
+}
+
+// unsupportedCloneAllocTypes lists the rejected alloc types of the clone target volumes by the storage product.
+// The target lun is created before the clone pair or the lun copy, so a rejected alloc type would fail the clone
+// only after the source is resolved. The clone speed is not part of the rules, none of the products limits it
+// beyond the range checked by getCloneSpeed.
+var unsupportedCloneAllocTypes = map[string][]cloneAllocTypeRule{
+	"Dorado": {
+		{allocType: allocTypeThick, reason: "the clones of Dorado storage are always thin"},
+	},
+	"DoradoV6": {
+		{allocType: allocTypeThick, reason: "the clone pairs of DoradoV6 storage only support thin target volumes"},
+	},
+}
+
 // Base defines the base storage client
 type Base struct {
 	cli              client.BaseClientInterface
@@ -41,9 +74,11 @@ func (p *Base) commonPreCreate(ctx context.Context, params map[string]interface{
 	analyzers := [...]func(context.Context, map[string]interface{}) error{
 		p.getAllocType,
 		p.getCloneSpeed,
+		p.checkCloneAllocType,
 		p.getPoolID,
 		p.getQoS,
 		p.getFileMode,
+		p.checkEncryptionLicense,
 	}
 
 	for _, analyzer := range analyzers {
@@ -58,9 +93,9 @@ func (p *Base) commonPreCreate(ctx context.Context, params map[string]interface{
 
 func (p *Base) getAllocType(_ context.Context, params map[string]interface{}) error {
 	if v, exist := params["alloctype"].(string); exist && v == "thick" {
-		params["alloctype"] = 0
+		params["alloctype"] = allocTypeThick
 	} else {
-		params["alloctype"] = 1
+		params["alloctype"] = allocTypeThin
 	}
 
 	return nil
@@ -86,6 +121,29 @@ func (p *Base) getCloneSpeed(_ context.Context, params map[string]interface{}) e
 
 	return nil
 }
+
+// checkCloneAllocType rejects the alloctype of the clone which is not supported by the storage,
+// so that the clone fails before any resource is created on the storage
+func (p *Base) checkCloneAllocType(_ context.Context, params map[string]interface{}) error {
+	// the clonespeed is only set for the clones
+	if _, isClone := params["clonespeed"].(int); !isClone {
+		return nil
+	}
+
+	allocType, _ := params["alloctype"].(int)
+	for _, rule := range unsupportedCloneAllocTypes[p.product] {
+		if rule.allocType == allocType {
+			allocTypeName := "thin"
+			if allocType == allocTypeThick {
+				allocTypeName = "thick"
+			}
+			return fmt.Errorf("alloctype %s is not supported by the clones on %s storage, %s, "+
+				"please change the alloctype in the StorageClass", allocTypeName, p.product, rule.reason)
+		}
+	}
+
+	return nil
+}
 func (p *Base) getFileMode(_ context.Context, params map[string]interface{}) error {
 	if params == nil || len(params) == 0 {
 		return nil
@@ -321,6 +379,138 @@ func (p *Base) prepareVolObj(ctx context.Context, params, res map[string]interfa
 		if lunWWN, ok := res["lunWWN"].(string); ok {
 			volObj.SetLunWWN(lunWWN)
 		}
+		if sharePath, ok := res["sharePath"].(string); ok {
+			volObj.SetSharePath(sharePath)
+		}
+	}
+	if encrypted, ok := params["encrypted"].(bool); ok {
+		volObj.SetEncrypted(encrypted)
 	}
+	localOwner, _ := params["localowner"].(string)
+	remoteOwner, _ := params["remoteowner"].(string)
+	volObj.SetOwningControllers(localOwner, remoteOwner)
 	return volObj
 }
+
+// isEncrypted returns whether the lun or filesystem is encrypted by the storage
+func isEncrypted(object map[string]interface{}) bool {
+	return fmt.Sprintf("%v", object[client.EncryptedAttribute]) == "true"
+}
+
+// inheritEncryption makes the clone encrypted as its source since the clone shares the data of the source,
+// the clone of an unencrypted source is rejected if the StorageClass requires encrypted volumes
+func inheritEncryption(ctx context.Context, params, source map[string]interface{}) error {
+	sourceEncrypted := isEncrypted(source)
+	if requested, _ := params["encrypted"].(bool); requested && !sourceEncrypted {
+		return pkgUtils.Errorf(ctx, "the clone source %v is not encrypted on the storage, but the StorageClass "+
+			"requires encrypted volumes", source["NAME"])
+	}
+
+	params["encrypted"] = sourceEncrypted
+	return nil
+}
+
+// checkEncryptionLicense makes sure that the storage is licensed to encrypt the volume before it is created
+func (p *Base) checkEncryptionLicense(ctx context.Context, params map[string]interface{}) error {
+	if encrypted, _ := params["encrypted"].(bool); !encrypted {
+		return nil
+	}
+
+	features, err := p.cli.GetLicenseFeature(ctx)
+	if err != nil {
+		log.AddContext(ctx).Errorf("Get license feature error: %v", err)
+		return err
+	}
+
+	if !utils.IsSupportFeature(features, encryptionLicenseFeature) {
+		return pkgUtils.Errorf(ctx, "the %s license of the storage is not valid, the encrypted volume %v "+
+			"can not be created", encryptionLicenseFeature, params["name"])
+	}
+	return nil
+}
+
+// confirmEncrypted makes sure that the created lun or filesystem is encrypted by the storage as requested
+func confirmEncrypted(ctx context.Context, objectType string, params, object map[string]interface{}) error {
+	if encrypted, _ := params["encrypted"].(bool); !encrypted {
+		return nil
+	}
+
+	if object == nil {
+		return pkgUtils.Errorf(ctx, "%s %v to confirm the encryption does not exist", objectType, params["name"])
+	}
+
+	if !isEncrypted(object) {
+		return pkgUtils.Errorf(ctx, "%s %v is not encrypted by the storage, the %s attribute is %v",
+			objectType, params["name"], client.EncryptedAttribute, object[client.EncryptedAttribute])
+	}
+	return nil
+}
+
+// isDeleting returns whether the lun or filesystem is being deleted by the storage
+func isDeleting(object map[string]interface{}) bool {
+	return fmt.Sprintf("%v", object["RUNNINGSTATUS"]) == objectRunningStatusDeleting
+}
+
+// waitCapacityExpanded waits for the capacity of the same-named objects on both sides of a hyperMetro pair to
+// reach the new size, so that the expanded volume is consistent on both storages when the expansion returns
+func waitCapacityExpanded(ctx context.Context, objectType, name string, newSize int64,
+	getObjects ...func(context.Context, string) (map[string]interface{}, error)) error {
+	err := utils.WaitUntil(func() (bool, error) {
+		for _, getObject := range getObjects {
+			object, err := getObject(ctx, name)
+			if err != nil {
+				return false, err
+			}
+			if object == nil {
+				return false, fmt.Errorf("%s %s does not exist", objectType, name)
+			}
+
+			capacity := utils.ParseIntWithDefault(fmt.Sprintf("%v", object["CAPACITY"]), 10, 64, 0)
+			if capacity < newSize {
+				log.AddContext(ctx).Infof("The capacity %d of %s %s has not reached %d yet",
+					capacity, objectType, name, newSize)
+				return false, nil
+			}
+		}
+		return true, nil
+	}, expandedCapacityWaitTimeout, expandedCapacityWaitInterval)
+	if err != nil {
+		return fmt.Errorf("wait for the capacity of %s %s to reach %d failed, error: %v",
+			objectType, name, newSize, err)
+	}
+
+	return nil
+}
+
+// waitDeletingObjectGone waits for the same-named lun or filesystem which is being deleted to disappear, so that
+// a volume deleted and recreated rapidly does not conflict with its old object. The object which is not being
+// deleted is returned as it is.
+func waitDeletingObjectGone(ctx context.Context, objectType, name string, object map[string]interface{},
+	getObject func(context.Context, string) (map[string]interface{}, error)) (map[string]interface{}, error) {
+	if object == nil || !isDeleting(object) {
+		return object, nil
+	}
+
+	log.AddContext(ctx).Infof("%s %s is being deleted, wait for it to disappear", objectType, name)
+	timeout := time.NewTimer(deletingObjectWaitTimeout)
+	defer timeout.Stop()
+	for {
+		var err error
+		object, err = getObject(ctx, name)
+		if err != nil {
+			return nil, fmt.Errorf("wait for the deleting %s %s to disappear failed, error: %v", objectType, name, err)
+		}
+		if object == nil || !isDeleting(object) {
+			return object, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("wait for the deleting %s %s to disappear failed, error: %v",
+				objectType, name, ctx.Err())
+		case <-timeout.C:
+			return nil, fmt.Errorf("wait for the deleting %s %s to disappear timeout", objectType, name)
+		case <-time.After(deletingObjectWaitInterval):
+		}
+	}
+}
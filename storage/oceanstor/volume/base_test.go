@@ -0,0 +1,197 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package volume
+
+import (
+	"context"
+	"testing"
+
+	"huawei-csi-driver/storage/oceanstor/client"
+)
+
+func TestCheckCloneAllocType(t *testing.T) {
+	tests := []struct {
+		name      string
+		product   string
+		params    map[string]interface{}
+		expectErr bool
+	}{
+		{"ThickCloneOnDoradoV6", "DoradoV6",
+			map[string]interface{}{"alloctype": allocTypeThick, "clonespeed": 3}, true},
+		{"ThinCloneOnDoradoV6", "DoradoV6",
+			map[string]interface{}{"alloctype": allocTypeThin, "clonespeed": 3}, false},
+		{"ThickCloneOnV5", "V5",
+			map[string]interface{}{"alloctype": allocTypeThick, "clonespeed": 4}, false},
+		{"ThickVolumeOnDoradoV6", "DoradoV6",
+			map[string]interface{}{"alloctype": allocTypeThick}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &Base{product: tt.product}
+			if err := p.checkCloneAllocType(context.Background(), tt.params); (err != nil) != tt.expectErr {
+				t.Errorf("checkCloneAllocType() error = %v, expectErr %v", err, tt.expectErr)
+			}
+		})
+	}
+}
+
+func TestWaitDeletingObjectGone(t *testing.T) {
+	deleting := map[string]interface{}{"NAME": "pvc-1", "RUNNINGSTATUS": objectRunningStatusDeleting}
+	online := map[string]interface{}{"NAME": "pvc-1", "RUNNINGSTATUS": "27"}
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	tests := []struct {
+		name      string
+		ctx       context.Context
+		object    map[string]interface{}
+		remaining []map[string]interface{}
+		want      map[string]interface{}
+		wantErr   bool
+	}{
+		{"NotExist", context.Background(), nil, nil, nil, false},
+		{"NotDeleting", context.Background(), online, nil, online, false},
+		{"DeletedAfterWait", context.Background(), deleting, []map[string]interface{}{nil}, nil, false},
+		{"ContextCanceled", canceled, deleting, []map[string]interface{}{deleting}, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			getObject := func(context.Context, string) (map[string]interface{}, error) {
+				object := tt.remaining[0]
+				tt.remaining = tt.remaining[1:]
+				return object, nil
+			}
+			got, err := waitDeletingObjectGone(tt.ctx, "LUN", "pvc-1", tt.object, getObject)
+			if (err != nil) != tt.wantErr || (got == nil) != (tt.want == nil) {
+				t.Errorf("waitDeletingObjectGone() = %v, %v, want %v, wantErr %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWaitCapacityExpanded(t *testing.T) {
+	expanded := map[string]interface{}{"NAME": "pvc-1", "CAPACITY": "4194304"}
+	tests := []struct {
+		name    string
+		remote  map[string]interface{}
+		wantErr bool
+	}{
+		{"BothExpanded", expanded, false},
+		{"RemoteNotExist", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			getLocal := func(context.Context, string) (map[string]interface{}, error) {
+				return expanded, nil
+			}
+			getRemote := func(context.Context, string) (map[string]interface{}, error) {
+				return tt.remote, nil
+			}
+			err := waitCapacityExpanded(context.Background(), "lun", "pvc-1", 4194304, getLocal, getRemote)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("waitCapacityExpanded() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestInheritEncryption(t *testing.T) {
+	encrypted := map[string]interface{}{"NAME": "pvc-src", "ENCRYPTED": "true"}
+	unencrypted := map[string]interface{}{"NAME": "pvc-src", "ENCRYPTED": "false"}
+	tests := []struct {
+		name          string
+		params        map[string]interface{}
+		source        map[string]interface{}
+		wantEncrypted bool
+		expectErr     bool
+	}{
+		{"EncryptedSource", map[string]interface{}{}, encrypted, true, false},
+		{"UnencryptedSource", map[string]interface{}{}, unencrypted, false, false},
+		{"EncryptedClassEncryptedSource", map[string]interface{}{"encrypted": true}, encrypted, true, false},
+		{"EncryptedClassUnencryptedSource", map[string]interface{}{"encrypted": true}, unencrypted, true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := inheritEncryption(context.Background(), tt.params, tt.source)
+			if (err != nil) != tt.expectErr {
+				t.Fatalf("inheritEncryption() error = %v, expectErr %v", err, tt.expectErr)
+			}
+			if got, _ := tt.params["encrypted"].(bool); got != tt.wantEncrypted {
+				t.Errorf("inheritEncryption() encrypted = %v, want %v", got, tt.wantEncrypted)
+			}
+		})
+	}
+}
+
+type fakeLicenseClient struct {
+	client.BaseClientInterface
+	features map[string]int
+}
+
+func (c *fakeLicenseClient) GetLicenseFeature(context.Context) (map[string]int, error) {
+	return c.features, nil
+}
+
+func TestCheckEncryptionLicense(t *testing.T) {
+	tests := []struct {
+		name      string
+		params    map[string]interface{}
+		features  map[string]int
+		expectErr bool
+	}{
+		{"Licensed", map[string]interface{}{"encrypted": true}, map[string]int{"HyperEncryption": 1}, false},
+		{"LicenseExpired", map[string]interface{}{"encrypted": true}, map[string]int{"HyperEncryption": 3}, true},
+		{"NotLicensed", map[string]interface{}{"encrypted": true}, map[string]int{}, true},
+		{"NotRequested", map[string]interface{}{}, map[string]int{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &Base{cli: &fakeLicenseClient{features: tt.features}}
+			if err := p.checkEncryptionLicense(context.Background(), tt.params); (err != nil) != tt.expectErr {
+				t.Errorf("checkEncryptionLicense() error = %v, expectErr %v", err, tt.expectErr)
+			}
+		})
+	}
+}
+
+func TestConfirmEncrypted(t *testing.T) {
+	tests := []struct {
+		name      string
+		params    map[string]interface{}
+		object    map[string]interface{}
+		expectErr bool
+	}{
+		{"Encrypted", map[string]interface{}{"encrypted": true}, map[string]interface{}{"ENCRYPTED": "true"}, false},
+		{"NotEncrypted", map[string]interface{}{"encrypted": true}, map[string]interface{}{"ENCRYPTED": "false"},
+			true},
+		{"AttributeMissing", map[string]interface{}{"encrypted": true}, map[string]interface{}{}, true},
+		{"NotExist", map[string]interface{}{"encrypted": true}, nil, true},
+		{"NotRequested", map[string]interface{}{}, map[string]interface{}{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := confirmEncrypted(context.Background(), "LUN", tt.params, tt.object); (err != nil) != tt.expectErr {
+				t.Errorf("confirmEncrypted() error = %v, expectErr %v", err, tt.expectErr)
+			}
+		})
+	}
+}
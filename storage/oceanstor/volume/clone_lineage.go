@@ -0,0 +1,72 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package volume
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"huawei-csi-driver/utils"
+	"huawei-csi-driver/utils/log"
+)
+
+const (
+	// cloneRootTag is appended to the description of the cloned volumes, followed by the name of the root ancestor
+	cloneRootTag = "cloneRoot="
+
+	// maxDescriptionLength is the description length limit of the LUNs and filesystems
+	maxDescriptionLength = 255
+)
+
+var cloneRootPattern = regexp.MustCompile(cloneRootTag + `(\S+)`)
+
+// getCloneRoot returns the root ancestor tagged in the description of the clone source, or the source itself
+// if it is not cloned from another volume
+func getCloneRoot(source string, sourceDescription string) string {
+	if match := cloneRootPattern.FindStringSubmatch(sourceDescription); len(match) > 1 {
+		return match[1]
+	}
+
+	return source
+}
+
+// tagCloneRoot appends the root ancestor of the clone source to the description of the cloned volume, so that
+// every volume of a multi-generation clone tree can be traced back to the original volume without walking the
+// tree. The source object is nil if it does not exist, which is reported by the clone itself.
+func tagCloneRoot(ctx context.Context, params map[string]interface{}, sourceObj map[string]interface{}) {
+	source, _ := params["clonefrom"].(string)
+	if source == "" || sourceObj == nil {
+		return
+	}
+
+	sourceDescription, _ := utils.ToStringWithFlag(sourceObj["DESCRIPTION"])
+	tag := cloneRootTag + getCloneRoot(source, sourceDescription)
+	description, _ := params["description"].(string)
+	// a description inherited from the source carries the tag of the source
+	description = strings.TrimSpace(cloneRootPattern.ReplaceAllString(description, ""))
+	if maxLength := maxDescriptionLength - len(tag) - 1; len(description) > maxLength {
+		description = description[:maxLength]
+		for !utf8.ValidString(description) {
+			description = description[:len(description)-1]
+		}
+	}
+
+	params["description"] = strings.TrimSpace(description + " " + tag)
+	log.AddContext(ctx).Infof("Tag the clone of %s with %s", source, tag)
+}
@@ -0,0 +1,54 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package volume
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestTagCloneRoot(t *testing.T) {
+	long := strings.Repeat("a", maxDescriptionLength)
+	tests := []struct {
+		name        string
+		description string
+		sourceObj   map[string]interface{}
+		want        string
+	}{
+		{"SourceNotExist", "created by csi", nil, "created by csi"},
+		{"FirstGeneration", "created by csi",
+			map[string]interface{}{"DESCRIPTION": "created by csi"}, "created by csi cloneRoot=pvc-src"},
+		{"SecondGeneration", "created by csi",
+			map[string]interface{}{"DESCRIPTION": "created by csi cloneRoot=pvc-root"},
+			"created by csi cloneRoot=pvc-root"},
+		{"InheritedTagReplaced", "copy cloneRoot=pvc-old",
+			map[string]interface{}{"DESCRIPTION": "cloneRoot=pvc-root"}, "copy cloneRoot=pvc-root"},
+		{"Truncated", long, map[string]interface{}{},
+			long[:maxDescriptionLength-len(" cloneRoot=pvc-src")] + " cloneRoot=pvc-src"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := map[string]interface{}{"clonefrom": "pvc-src", "description": tt.description}
+			tagCloneRoot(context.Background(), params, tt.sourceObj)
+			if params["description"] != tt.want {
+				t.Errorf("tagCloneRoot() description = %v, want %v", params["description"], tt.want)
+			}
+		})
+	}
+}
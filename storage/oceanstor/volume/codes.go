@@ -64,4 +64,6 @@ const (
 
 	snapshotRunningStatusActive   = "43"
 	snapshotRunningStatusInactive = "45"
+
+	objectRunningStatusDeleting = "106"
 )
@@ -20,8 +20,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 
+	"huawei-csi-driver/pkg/constants"
 	"huawei-csi-driver/storage/oceanstor/client"
 	"huawei-csi-driver/utils"
 	"huawei-csi-driver/utils/log"
@@ -88,7 +90,7 @@ func (p *DTree) preCreate(ctx context.Context, params map[string]interface{}) er
 		}
 	}
 
-	return nil
+	return preCreateCIFSShare(ctx, params)
 }
 
 // Create creates DTree volume
@@ -103,6 +105,7 @@ func (p *DTree) Create(ctx context.Context, params map[string]interface{}) (util
 	taskFlow.AddTask("Create-DTree", p.createDtree, p.revertDtree)
 	taskFlow.AddTask("Create-Share", p.createShare, p.revertShare)
 	taskFlow.AddTask("Allow-Share-Access", p.allowShareAccess, p.revertShareAccess)
+	taskFlow.AddTask("Create-CIFS-Share", p.createCIFSShare, p.revertCIFSShare)
 	taskFlow.AddTask("Create-Quota", p.createQuota, p.revertQuota)
 
 	_, err = taskFlow.Run(params)
@@ -122,6 +125,7 @@ func (p *DTree) Delete(ctx context.Context, params map[string]interface{}) error
 	taskFlow := taskflow.NewTaskFlow(ctx, "Delete-FileSystem-DTree-Volume")
 	taskFlow.AddTask("Check-DTree", p.checkDtreeExist, nil)
 	taskFlow.AddTask("Delete-Quota", p.deleteQuota, nil)
+	taskFlow.AddTask("Delete-CIFS-Share", p.deleteCIFSShare, nil)
 	taskFlow.AddTask("Delete-Share", p.deleteShare, nil)
 	taskFlow.AddTask("Delete-DTree", p.deleteDtree, nil)
 
@@ -134,6 +138,21 @@ func (p *DTree) Delete(ctx context.Context, params map[string]interface{}) error
 	return nil
 }
 
+// GetSpaceSoftQuota returns the soft quota of the DTree by the percentage of the hard quota,
+// 0 is returned if the percentage is empty, which means that the soft quota is not set
+func GetSpaceSoftQuota(spaceHardQuota int64, percent string) (int64, error) {
+	if percent == "" {
+		return 0, nil
+	}
+
+	value, err := strconv.Atoi(percent)
+	if err != nil || value < 1 || value > 100 {
+		return 0, fmt.Errorf("spaceSoftQuotaPercent [%s] must be an integer in range [1, 100]", percent)
+	}
+
+	return spaceHardQuota * int64(value) / 100, nil
+}
+
 // Expand expands volume size
 func (p *DTree) Expand(ctx context.Context, parentName, dTreeName, vstoreID string, spaceSoftQuota,
 	spaceHardQuota int64) error {
@@ -163,6 +182,9 @@ func (p *DTree) Expand(ctx context.Context, parentName, dTreeName, vstoreID stri
 		data["QUOTATYPE"] = client.QuotaTypeDir
 		data["SPACEUNITTYPE"] = client.SpaceUnitTypeGB
 		data["SPACEHARDQUOTA"] = spaceHardQuota
+		if spaceSoftQuota > 0 {
+			data["SPACESOFTQUOTA"] = spaceSoftQuota
+		}
 		data["vstoreId"] = vstoreID
 		_, err = p.cli.CreateQuota(ctx, data)
 		if err != nil {
@@ -178,13 +200,18 @@ func (p *DTree) Expand(ctx context.Context, parentName, dTreeName, vstoreID stri
 		return errors.New("data in response is not valid")
 	}
 	quotaID, _ := utils.ToStringWithFlag(quotaInfo["ID"])
-	err = p.cli.UpdateQuota(ctx, quotaID, map[string]interface{}{
+	data := map[string]interface{}{
 		"SPACEHARDQUOTA": spaceHardQuota,
 		"vstoreId":       vstoreID,
-	})
+	}
+	if spaceSoftQuota > 0 {
+		data["SPACESOFTQUOTA"] = spaceSoftQuota
+	}
+	err = p.cli.UpdateQuota(ctx, quotaID, data)
 	if err != nil {
-		log.AddContext(ctx).Errorf("update quota failed, SPACEHARDQUOTA :%v, SPACEUNITTYPE: %v vstoreId: %v, err: %v",
-			spaceHardQuota, client.SpaceUnitTypeGB, vstoreID, err)
+		log.AddContext(ctx).Errorf("update quota failed, SPACEHARDQUOTA :%v, SPACESOFTQUOTA :%v, "+
+			"SPACEUNITTYPE: %v vstoreId: %v, err: %v",
+			spaceHardQuota, spaceSoftQuota, client.SpaceUnitTypeGB, vstoreID, err)
 		return err
 	}
 	return nil
@@ -504,6 +531,16 @@ func (p *DTree) createQuota(ctx context.Context,
 	data["SPACEHARDQUOTA"] = spaceHardQuota * 512
 	data["vstoreId"] = params["vstoreid"]
 
+	percent, _ := utils.ToStringWithFlag(params["spacesoftquotapercent"])
+	spaceSoftQuota, err := GetSpaceSoftQuota(spaceHardQuota*512, percent)
+	if err != nil {
+		log.AddContext(ctx).Errorln(err)
+		return nil, err
+	}
+	if spaceSoftQuota > 0 {
+		data["SPACESOFTQUOTA"] = spaceSoftQuota
+	}
+
 	quota, err := p.cli.CreateQuota(ctx, data)
 	if err != nil {
 		log.AddContext(ctx).Errorf("create quota failed, data: %+v, err: %v", data, err)
@@ -663,7 +700,8 @@ func (p *DTree) getDtreeID(ctx context.Context, parentName, vstoreID, dTreeName
 	if dTreeInfo == nil {
 		log.AddContext(ctx).Errorf("get empty dtree finish,parentName :%s, vstoreID: %s, dTreeName: %s",
 			parentName, vstoreID, dTreeInfo)
-		return "", errors.New("empty dTree")
+		return "", fmt.Errorf("%w: dTree %s of filesystem %s does not exist", constants.ErrVolumeNotFound,
+			dTreeName, parentName)
 	}
 	dTreeID, _ := utils.ToStringWithFlag(dTreeInfo["ID"])
 
@@ -0,0 +1,168 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package volume
+
+import (
+	"context"
+	"fmt"
+
+	"huawei-csi-driver/storage/oceanstor/client"
+	"huawei-csi-driver/utils"
+	"huawei-csi-driver/utils/log"
+)
+
+const (
+	// defaultCIFSAuthClient is the user group allowed to access the cifs share of the dual-protocol DTree
+	defaultCIFSAuthClient = "Everyone"
+)
+
+var cifsPermissions = map[string]int{
+	"read_only":    client.CIFSSharePermissionReadOnly,
+	"read_write":   client.CIFSSharePermissionReadWrite,
+	"full_control": client.CIFSSharePermissionFullControl,
+}
+
+// preCreateCIFSShare checks the cifs share parameters of the dual-protocol DTree
+func preCreateCIFSShare(ctx context.Context, params map[string]interface{}) error {
+	if dualProtocol, _ := params["dualprotocol"].(bool); !dualProtocol {
+		return nil
+	}
+
+	if authClient, _ := utils.ToStringWithFlag(params["cifsauthclient"]); authClient == "" {
+		params["cifsauthclient"] = defaultCIFSAuthClient
+	}
+
+	val, _ := utils.ToStringWithFlag(params["cifspermission"])
+	if val == "" {
+		params["cifspermission"] = client.CIFSSharePermissionFullControl
+		return nil
+	}
+
+	permission, exist := cifsPermissions[val]
+	if !exist {
+		return utils.Errorf(ctx, "parameter cifsPermission [%v] in sc must be read_only, read_write "+
+			"or full_control.", val)
+	}
+	params["cifspermission"] = permission
+	return nil
+}
+
+// createCIFSShare creates the cifs share of the dual-protocol DTree, so that the SMB clients outside the cluster
+// access the DTree, and applies the ACL of the share
+func (p *DTree) createCIFSShare(ctx context.Context, params,
+	taskResult map[string]interface{}) (map[string]interface{}, error) {
+	if dualProtocol, _ := params["dualprotocol"].(bool); !dualProtocol {
+		return nil, nil
+	}
+
+	parentName, _ := utils.ToStringWithFlag(params["parentname"])
+	dTreeName, _ := utils.ToStringWithFlag(params["name"])
+	vStoreID, _ := utils.ToStringWithFlag(params["vstoreid"])
+	sharePath := fmt.Sprintf("/%s/%s", parentName, dTreeName)
+
+	share, err := p.cli.GetCIFSShareByPath(ctx, sharePath, vStoreID)
+	if err != nil {
+		log.AddContext(ctx).Errorf("Get dTree cifs share by path %s error: %v", sharePath, err)
+		return nil, err
+	}
+
+	if share == nil {
+		fsID, _ := utils.ToStringWithFlag(taskResult["fsId"])
+		dTreeID, _ := utils.ToStringWithFlag(taskResult["dTreeId"])
+		description, _ := utils.ToStringWithFlag(params["description"])
+		req := &client.CreateCIFSShareRequest{
+			Name:        dTreeName,
+			SharePath:   sharePath,
+			FsID:        fsID,
+			DTreeID:     dTreeID,
+			Description: description,
+			VStoreID:    vStoreID,
+		}
+		share, err = p.cli.CreateCIFSShare(ctx, req)
+		if err != nil {
+			log.AddContext(ctx).Errorf("Create dTree cifs share %s error: %v", sharePath, err)
+			return nil, err
+		}
+	}
+
+	shareID, _ := utils.ToStringWithFlag(share["ID"])
+	result := map[string]interface{}{"cifsShareId": shareID}
+
+	authClient, _ := utils.ToStringWithFlag(params["cifsauthclient"])
+	permission, _ := params["cifspermission"].(int)
+	err = p.cli.SetCIFSSharePermission(ctx, &client.SetCIFSSharePermissionRequest{
+		ShareID:    shareID,
+		Name:       authClient,
+		Permission: permission,
+		VStoreID:   vStoreID,
+	})
+	if err != nil {
+		log.AddContext(ctx).Errorf("Set permission of %s to cifs share %s error: %v", authClient, shareID, err)
+		// the share is reverted by the task flow only if the task succeeds, so it is deleted here
+		if delErr := p.cli.DeleteCIFSShare(ctx, shareID, vStoreID); delErr != nil {
+			log.AddContext(ctx).Errorf("Delete cifs share %s error: %v", shareID, delErr)
+		}
+		return nil, err
+	}
+
+	log.AddContext(ctx).Infof("Create cifs share success, shareID: %v", shareID)
+	return result, nil
+}
+
+func (p *DTree) revertCIFSShare(ctx context.Context, taskResult map[string]interface{}) error {
+	shareID, _ := utils.ToStringWithFlag(taskResult["cifsShareId"])
+	if shareID == "" {
+		return nil
+	}
+	vStoreID, _ := utils.ToStringWithFlag(taskResult["vstoreid"])
+
+	err := p.cli.DeleteCIFSShare(ctx, shareID, vStoreID)
+	if err != nil {
+		log.AddContext(ctx).Errorf("Revert cifs share %s error: %v", shareID, err)
+		return err
+	}
+	return nil
+}
+
+// deleteCIFSShare deletes the cifs share of the DTree if it exists, the DTree volumes created without
+// dual-protocol don't have the share, so the failure of querying the share doesn't stop the deletion
+func (p *DTree) deleteCIFSShare(ctx context.Context, params,
+	taskResult map[string]interface{}) (map[string]interface{}, error) {
+	parentName, _ := utils.ToStringWithFlag(params["parentname"])
+	dTreeName, _ := utils.ToStringWithFlag(params["name"])
+	vStoreID, _ := utils.ToStringWithFlag(params["vstoreid"])
+	sharePath := fmt.Sprintf("/%s/%s", parentName, dTreeName)
+
+	share, err := p.cli.GetCIFSShareByPath(ctx, sharePath, vStoreID)
+	if err != nil {
+		log.AddContext(ctx).Warningf("Get cifs share by path %s error: %v", sharePath, err)
+		return nil, nil
+	}
+	if share == nil {
+		return nil, nil
+	}
+
+	shareID, _ := utils.ToStringWithFlag(share["ID"])
+	err = p.cli.DeleteCIFSShare(ctx, shareID, vStoreID)
+	if err != nil {
+		log.AddContext(ctx).Errorf("Delete cifs share %s error: %v", shareID, err)
+		return nil, err
+	}
+
+	log.AddContext(ctx).Infof("Delete cifs share success, shareID: %v", shareID)
+	return nil, nil
+}
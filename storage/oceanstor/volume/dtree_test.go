@@ -17,12 +17,14 @@
 package volume
 
 import (
+	"context"
 	"testing"
 
 	"github.com/prashantv/gostub"
 	"github.com/stretchr/testify/assert"
 	"huawei-csi-driver/csi/app"
 	cfg "huawei-csi-driver/csi/app/config"
+	"huawei-csi-driver/storage/oceanstor/client"
 	"huawei-csi-driver/utils/log"
 )
 
@@ -72,3 +74,98 @@ func TestFormatKerberosParam(t *testing.T) {
 		assert.Equal(t, c.expected, formatKerberosParam(c.target))
 	}
 }
+
+func TestGetSpaceSoftQuota(t *testing.T) {
+	tests := []struct {
+		name    string
+		percent string
+		want    int64
+		wantErr bool
+	}{
+		{"NotSet", "", 0, false},
+		{"NinetyPercent", "90", 9216, false},
+		{"EqualToHard", "100", 10240, false},
+		{"ExceedHard", "101", 0, true},
+		{"Zero", "0", 0, true},
+		{"NotInteger", "ninety", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GetSpaceSoftQuota(10240, tt.percent)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("GetSpaceSoftQuota() = %d, error = %v, want %d, wantErr %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+type fakeDTreeCIFSClient struct {
+	client.BaseClientInterface
+
+	permission *client.SetCIFSSharePermissionRequest
+	created    bool
+}
+
+func (f *fakeDTreeCIFSClient) GetCIFSShareByPath(ctx context.Context, path, vStoreID string) (
+	map[string]interface{}, error) {
+	return nil, nil
+}
+
+func (f *fakeDTreeCIFSClient) CreateCIFSShare(ctx context.Context, req *client.CreateCIFSShareRequest) (
+	map[string]interface{}, error) {
+	f.created = true
+	return map[string]interface{}{"ID": "1"}, nil
+}
+
+func (f *fakeDTreeCIFSClient) SetCIFSSharePermission(ctx context.Context,
+	req *client.SetCIFSSharePermissionRequest) error {
+	f.permission = req
+	return nil
+}
+
+func TestCreateDTreeCIFSShare(t *testing.T) {
+	tests := []struct {
+		name           string
+		params         map[string]interface{}
+		wantCreated    bool
+		wantPermission int
+		wantErr        bool
+	}{
+		{"NotDualProtocol", map[string]interface{}{}, false, 0, false},
+		{"DefaultPermission", map[string]interface{}{"dualprotocol": true}, true,
+			client.CIFSSharePermissionFullControl, false},
+		{"ReadOnly", map[string]interface{}{"dualprotocol": true, "cifspermission": "read_only"}, true,
+			client.CIFSSharePermissionReadOnly, false},
+		{"InvalidPermission", map[string]interface{}{"dualprotocol": true, "cifspermission": "write"}, false, 0,
+			true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cli := &fakeDTreeCIFSClient{}
+			tt.params["parentname"] = "fs_1"
+			tt.params["name"] = "pvc_1"
+			err := preCreateCIFSShare(context.Background(), tt.params)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("preCreateCIFSShare() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			_, err = NewDTree(cli).createCIFSShare(context.Background(), tt.params, map[string]interface{}{})
+			if err != nil {
+				t.Fatalf("createCIFSShare() error = %v", err)
+			}
+			if cli.created != tt.wantCreated {
+				t.Errorf("createCIFSShare() created = %v, want %v", cli.created, tt.wantCreated)
+			}
+			if tt.wantCreated && (cli.permission.Permission != tt.wantPermission ||
+				cli.permission.Name != defaultCIFSAuthClient) {
+				t.Errorf("createCIFSShare() permission = %+v, want %d of %s", cli.permission,
+					tt.wantPermission, defaultCIFSAuthClient)
+			}
+		})
+	}
+}
@@ -59,6 +59,7 @@ type NASHyperMetro struct {
 type NAS struct {
 	Base
 	NASHyperMetro
+	sharePathPrefix string
 }
 
 type allowNfsShareAccessParam struct {
@@ -70,7 +71,8 @@ type allowNfsShareAccessParam struct {
 }
 
 // NewNAS inits a new nas client
-func NewNAS(cli, metroRemoteCli, replicaRemoteCli client.BaseClientInterface, product string, nasHyperMetro NASHyperMetro) *NAS {
+func NewNAS(cli, metroRemoteCli, replicaRemoteCli client.BaseClientInterface, product string,
+	nasHyperMetro NASHyperMetro, sharePathPrefix string) *NAS {
 	return &NAS{
 		Base: Base{
 			cli:              cli,
@@ -78,10 +80,22 @@ func NewNAS(cli, metroRemoteCli, replicaRemoteCli client.BaseClientInterface, pr
 			replicaRemoteCli: replicaRemoteCli,
 			product:          product,
 		},
-		NASHyperMetro: nasHyperMetro,
+		NASHyperMetro:   nasHyperMetro,
+		sharePathPrefix: sharePathPrefix,
 	}
 }
 
+// getSharePath returns the path of the nfs share of the filesystem under the configured prefix
+func (p *NAS) getSharePath(fsName string) string {
+	return p.sharePathPrefix + utils.GetSharePath(fsName)
+}
+
+// getOriginSharePath returns the path of the nfs share of the filesystem under the configured prefix,
+// the filesystem name is used as it is
+func (p *NAS) getOriginSharePath(fsName string) string {
+	return p.sharePathPrefix + utils.GetOriginSharePath(fsName)
+}
+
 func (p *NAS) preCreate(ctx context.Context, params map[string]interface{}) error {
 	if _, exist := params["authclient"].(string); !exist {
 		msg := "authclient must be provided for filesystem"
@@ -108,14 +122,50 @@ func (p *NAS) preCreate(ctx context.Context, params map[string]interface{}) erro
 		params["clonefrom"] = v
 	}
 
+	if cloneFrom, exist := params["clonefrom"].(string); exist {
+		srcFS, err := p.cli.GetFileSystemByName(ctx, cloneFrom)
+		if err != nil {
+			log.AddContext(ctx).Errorf("Get clone src filesystem %s error: %v", cloneFrom, err)
+			return err
+		}
+		tagCloneRoot(ctx, params, srcFS)
+	}
+
 	err = p.setWorkLoadID(ctx, p.cli, params)
 	if err != nil {
 		return err
 	}
 
+	if err = p.preProcessSquash(ctx, params); err != nil {
+		return err
+	}
+
+	if val, ok := params["snapshotdirectoryvisibility"].(string); ok {
+		if strings.EqualFold(val, visibleString) {
+			params["isshowsnapdir"] = true
+		} else if strings.EqualFold(val, invisibleString) {
+			params["isshowsnapdir"] = false
+		} else {
+			return utils.Errorf(ctx, "parameter snapshotDirectoryVisibility [%v] in sc must be %s or %s.",
+				params["snapshotdirectoryvisibility"], visibleString, invisibleString)
+		}
+	}
+
+	// convert reservedsnapshotspaceratio to int
+	if val, exist := params["reservedsnapshotspaceratio"].(string); exist {
+		intVal, err := strconv.Atoi(val)
+		if err != nil {
+			return err
+		}
+		params["reservedsnapshotspaceratio"] = intVal
+	}
+
+	return nil
+}
+
+func (p *NAS) preProcessSquash(ctx context.Context, params map[string]interface{}) error {
 	// all_squash  all_squash: 0  no_all_squash: 1
 	val, exist := params["allsquash"].(string)
-
 	if !exist || val == "" {
 		params["allsquash"] = noAllSquash
 	} else {
@@ -144,26 +194,6 @@ func (p *NAS) preCreate(ctx context.Context, params map[string]interface{}) erro
 		}
 	}
 
-	if val, ok := params["snapshotdirectoryvisibility"].(string); ok {
-		if strings.EqualFold(val, visibleString) {
-			params["isshowsnapdir"] = true
-		} else if strings.EqualFold(val, invisibleString) {
-			params["isshowsnapdir"] = false
-		} else {
-			return utils.Errorf(ctx, "parameter snapshotDirectoryVisibility [%v] in sc must be %s or %s.",
-				params["snapshotdirectoryvisibility"], visibleString, invisibleString)
-		}
-	}
-
-	// convert reservedsnapshotspaceratio to int
-	if val, exist := params["reservedsnapshotspaceratio"].(string); exist {
-		intVal, err := strconv.Atoi(val)
-		if err != nil {
-			return err
-		}
-		params["reservedsnapshotspaceratio"] = intVal
-	}
-
 	return nil
 }
 
@@ -190,6 +220,7 @@ func (p *NAS) Create(ctx context.Context, params map[string]interface{}) (utils.
 	}
 
 	taskflow.AddTask("Create-Local-FS", p.createLocalFS, p.revertLocalFS)
+	taskflow.AddTask("Confirm-Encryption", p.confirmFSEncrypted, nil)
 	if replicationOK && replication {
 		taskflow.AddTask("Create-Remote-FS", p.createRemoteFS, p.revertRemoteFS)
 		taskflow.AddTask("Create-Remote-QoS", p.createRemoteQoS, p.revertRemoteQoS)
@@ -209,7 +240,7 @@ func (p *NAS) Create(ctx context.Context, params map[string]interface{}) (utils.
 
 	params["localVStoreID"] = p.LocVStoreID
 	params["remoteVStoreID"] = p.RmtVStoreID
-	_, err = taskflow.Run(params)
+	res, err := taskflow.Run(params)
 	if err != nil {
 		// In order to prevent residue from being left in the event of a creation failure (If the deletion
 		// operation fails for the first time and the deletion operation is delivered for the second time,
@@ -218,7 +249,7 @@ func (p *NAS) Create(ctx context.Context, params map[string]interface{}) (utils.
 		return nil, err
 	}
 
-	volObj := p.prepareVolObj(ctx, params, nil)
+	volObj := p.prepareVolObj(ctx, params, res)
 	return volObj, nil
 }
 
@@ -250,6 +281,18 @@ func (p *NAS) validateManageWorkLoadType(ctx context.Context, params, fs map[str
 	return nil
 }
 
+func (p *NAS) confirmFSEncrypted(ctx context.Context, params, taskResult map[string]interface{}) (
+	map[string]interface{}, error) {
+	fsName, _ := params["name"].(string)
+	fs, err := p.cli.GetFileSystemByName(ctx, fsName)
+	if err != nil {
+		log.AddContext(ctx).Errorf("Get filesystem %s error: %v", fsName, err)
+		return nil, err
+	}
+
+	return nil, confirmEncrypted(ctx, "filesystem", params, fs)
+}
+
 func (p *NAS) createLocalFS(ctx context.Context, params, taskResult map[string]interface{}) (
 	map[string]interface{}, error) {
 
@@ -263,19 +306,29 @@ func (p *NAS) createLocalFS(ctx context.Context, params, taskResult map[string]i
 		return nil, err
 	}
 
+	fs, err = waitDeletingObjectGone(ctx, "filesystem", fsName, fs, p.cli.GetFileSystemByName)
+	if err != nil {
+		log.AddContext(ctx).Errorln(err)
+		return nil, err
+	}
+
 	var isClone bool
 	if fs == nil {
 		params["parentid"] = params["poolID"]
 		params["vstoreId"] = params["localVStoreID"]
 
 		if _, exist := params["clonefrom"]; exist {
-			fs, err = p.clone(ctx, params)
+			if err = p.inheritSourceEncryption(ctx, params); err == nil {
+				fs, err = p.clone(ctx, params)
+			}
 			if err != nil {
 				log.AddContext(ctx).Warningf("p.clone() failed, param:%+v", params)
 			}
 			isClone = true
 		} else if _, exist := params["fromSnapshot"]; exist {
-			fs, err = p.createFromSnapshot(ctx, params)
+			if err = p.inheritSourceEncryption(ctx, params); err == nil {
+				fs, err = p.createFromSnapshot(ctx, params)
+			}
 			if err != nil {
 				log.AddContext(ctx).Warningf("p.createFromSnapshot() failed, param:%+v", params)
 			}
@@ -402,6 +455,39 @@ func (p *NAS) clone(ctx context.Context, params map[string]interface{}) (map[str
 	return cloneFS, nil
 }
 
+// inheritSourceEncryption takes the encryption of the cloned filesystem from the source filesystem or the parent
+// filesystem of the source snapshot, the missing source is left to the clone which reports it
+func (p *NAS) inheritSourceEncryption(ctx context.Context, params map[string]interface{}) error {
+	var sourceName string
+	if cloneFrom, ok := params["clonefrom"].(string); ok {
+		sourceName = cloneFrom
+	} else if fromSnapshot, ok := params["fromSnapshot"].(string); ok {
+		snapshotParentID, _ := params["snapshotparentid"].(string)
+		snapshot, err := p.cli.GetFSSnapshotByName(ctx, snapshotParentID, fromSnapshot)
+		if err != nil {
+			log.AddContext(ctx).Errorf("Get src filesystem snapshot %s error: %v", fromSnapshot, err)
+			return err
+		}
+		if snapshot != nil {
+			sourceName, _ = snapshot["PARENTNAME"].(string)
+		}
+	}
+	if sourceName == "" {
+		return nil
+	}
+
+	source, err := p.cli.GetFileSystemByName(ctx, sourceName)
+	if err != nil {
+		log.AddContext(ctx).Errorf("Get clone src filesystem %s error: %v", sourceName, err)
+		return err
+	}
+	if source == nil {
+		return nil
+	}
+
+	return inheritEncryption(ctx, params, source)
+}
+
 func (p *NAS) createFromSnapshot(ctx context.Context, params map[string]interface{}) (map[string]interface{}, error) {
 	srcSnapshotName, ok := params["fromSnapshot"].(string)
 	if !ok {
@@ -475,7 +561,15 @@ func (p *NAS) cloneFilesystem(ctx context.Context, req *CloneFilesystemRequest)
 	if !ok {
 		return nil, pkgUtils.Errorf(ctx, "convert cloneFSID to string failed, data: %v", cloneFS["ID"])
 	}
-	if req.CloneFsCapacity > req.SrcCapacity {
+	// The clone takes the size of its parent snapshot, which is smaller than the size of
+	// the parent filesystem if the filesystem was expanded after the snapshot was taken.
+	cloneCapacity := p.getCloneFilesystemCapacity(ctx, cloneFS, req.SrcCapacity)
+	if req.CloneFsCapacity < cloneCapacity {
+		log.AddContext(ctx).Warningf("Requested capacity %d of filesystem %s is smaller than the capacity %d "+
+			"of its clone source, keep the clone source capacity", req.CloneFsCapacity, req.FsName, cloneCapacity)
+	} else if req.CloneFsCapacity > cloneCapacity {
+		log.AddContext(ctx).Infof("Extend clone filesystem %s from capacity %d to %d",
+			cloneFSID, cloneCapacity, req.CloneFsCapacity)
 		err := p.cli.ExtendFileSystem(ctx, cloneFSID, req.CloneFsCapacity)
 		if err != nil {
 			log.AddContext(ctx).Errorf("Extend filesystem %s to capacity %d error: %v",
@@ -498,6 +592,29 @@ func (p *NAS) cloneFilesystem(ctx context.Context, req *CloneFilesystemRequest)
 	return cloneFS, nil
 }
 
+// getCloneFilesystemCapacity returns the capacity the clone filesystem is created with,
+// falling back to the capacity of the clone source when the storage does not report it.
+func (p *NAS) getCloneFilesystemCapacity(ctx context.Context, cloneFS map[string]interface{},
+	srcCapacity int64) int64 {
+	capacity, ok := cloneFS["CAPACITY"].(string)
+	if !ok {
+		fs, err := p.cli.GetFileSystemByID(ctx, cloneFS["ID"].(string))
+		if err != nil || fs == nil {
+			log.AddContext(ctx).Warningf("Get capacity of clone filesystem %v failed, error: %v", cloneFS["ID"], err)
+			return srcCapacity
+		}
+		capacity, ok = fs["CAPACITY"].(string)
+	}
+
+	cloneCapacity, err := strconv.ParseInt(capacity, 10, 64)
+	if !ok || err != nil {
+		log.AddContext(ctx).Warningf("Parse capacity %v of clone filesystem %v failed", capacity, cloneFS["ID"])
+		return srcCapacity
+	}
+
+	return cloneCapacity
+}
+
 func (p *NAS) splitClone(ctx context.Context, cloneFSID string, req *CloneFilesystemRequest) error {
 	err := p.cli.SplitCloneFS(ctx, cloneFSID, req.VStoreId, req.CloneSpeed, req.DeleteParentSnapshot)
 	if err != nil {
@@ -513,7 +630,7 @@ func (p *NAS) splitClone(ctx context.Context, cloneFSID string, req *CloneFilesy
 }
 
 func (p *NAS) waitFSSplitDone(ctx context.Context, fsID string) error {
-	return utils.WaitUntil(func() (bool, error) {
+	return utils.WaitArrayTask(ctx, "FileSystemSplit", fsID, func() (bool, error) {
 		fs, err := p.cli.GetFileSystemByID(ctx, fsID)
 		if err != nil {
 			return false, err
@@ -524,7 +641,8 @@ func (p *NAS) waitFSSplitDone(ctx context.Context, fsID string) error {
 		}
 
 		if fs["HEALTHSTATUS"].(string) != filesystemHealthStatusNormal {
-			return false, fmt.Errorf("filesystem %s has the bad healthStatus code %s", fs["NAME"], fs["HEALTHSTATUS"].(string))
+			return false, utils.NewArrayTaskError("FileSystemSplit", fsID,
+				fmt.Sprintf("filesystem %s has the bad healthStatus code %s", fs["NAME"], fs["HEALTHSTATUS"].(string)))
 		}
 
 		splitStatus, ok := fs["SPLITSTATUS"].(string)
@@ -536,8 +654,9 @@ func (p *NAS) waitFSSplitDone(ctx context.Context, fsID string) error {
 			splitStatus == filesystemSplitStatusNotStart {
 			return false, nil
 		} else if splitStatus == filesystemSplitStatusAbnormal {
-			return false, fmt.Errorf("filesystem clone [%s] split status is interrupted, SPLITSTATUS: [%s]",
-				fs["NAME"], splitStatus)
+			return false, utils.NewArrayTaskError("FileSystemSplit", fsID,
+				fmt.Sprintf("filesystem clone [%s] split status is interrupted, SPLITSTATUS: [%s]",
+					fs["NAME"], splitStatus))
 		} else {
 			return true, nil
 		}
@@ -650,7 +769,7 @@ func (p *NAS) createShare(ctx context.Context,
 	if !ok {
 		return nil, pkgUtils.Errorf(ctx, "convert fsName to string failed, data: %v", params["name"])
 	}
-	sharePath := utils.GetSharePath(fsName)
+	sharePath := p.getSharePath(fsName)
 	activeClient := p.getActiveClient(taskResult)
 	vStoreID := p.getVStoreID(taskResult)
 	share, err := activeClient.GetNfsShareByPath(ctx, sharePath, vStoreID)
@@ -675,8 +794,13 @@ func (p *NAS) createShare(ctx context.Context,
 		}
 	}
 
+	// the node mounts the path the storage exports, which is recorded in the volume context
+	if exportedPath, ok := share["SHAREPATH"].(string); ok && exportedPath != "" {
+		sharePath = exportedPath
+	}
 	return map[string]interface{}{
-		"shareID": share["ID"].(string),
+		"shareID":   share["ID"].(string),
+		"sharePath": sharePath,
 	}, nil
 }
 
@@ -780,6 +904,64 @@ func (p *NAS) allowShareAccess(ctx context.Context,
 	}, nil
 }
 
+// UpdateShareClientACL updates the clients which are allowed to access the nfs share of the filesystem,
+// the squash settings of the current share accesses are kept for the new clients
+func (p *NAS) UpdateShareClientACL(ctx context.Context, fsName string, clients []string) error {
+	fs, err := p.cli.GetFileSystemByName(ctx, fsName)
+	if err != nil {
+		log.AddContext(ctx).Errorf("Get filesystem %s error: %v", fsName, err)
+		return err
+	}
+	if fs == nil {
+		log.AddContext(ctx).Errorf("Filesystem %s of the nfs share does not exist", fsName)
+		return fmt.Errorf("%w: filesystem %s of the nfs share does not exist", constants.ErrVolumeNotFound, fsName)
+	}
+
+	vStoreID, _ := fs["vstoreId"].(string)
+	sharePath := p.getOriginSharePath(fsName)
+	share, err := p.cli.GetNfsShareByPath(ctx, sharePath, vStoreID)
+	if err != nil {
+		log.AddContext(ctx).Errorf("Get nfs share by path %s error: %v", sharePath, err)
+		return err
+	}
+	if share == nil {
+		return pkgUtils.Errorf(ctx, "nfs share of filesystem %s does not exist", fsName)
+	}
+
+	shareID, ok := share["ID"].(string)
+	if !ok {
+		return pkgUtils.Errorf(ctx, "convert shareID to string failed, data: %v", share["ID"])
+	}
+
+	accesses, err := p.getCurrentShareAccess(ctx, shareID, vStoreID, p.cli)
+	if err != nil {
+		return err
+	}
+
+	allSquashVal, rootSquashVal := noAllSquash, noRootSquash
+	for _, i := range accesses {
+		access, ok := i.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if val, err := strconv.Atoi(utils.ToStringSafe(access["ALLSQUASH"])); err == nil {
+			allSquashVal = val
+		}
+		if val, err := strconv.Atoi(utils.ToStringSafe(access["ROOTSQUASH"])); err == nil {
+			rootSquashVal = val
+		}
+		break
+	}
+
+	return p.cli.UpdateNFSShareClientACL(ctx, &client.UpdateNFSShareClientACLRequest{
+		ShareID:    shareID,
+		VStoreID:   vStoreID,
+		Clients:    clients,
+		AllSquash:  allSquashVal,
+		RootSquash: rootSquashVal,
+	})
+}
+
 func (p *NAS) preShareAccessParam(ctx context.Context, params,
 	taskResult map[string]interface{}) (*allowNfsShareAccessParam, error) {
 	var res allowNfsShareAccessParam
@@ -869,6 +1051,7 @@ func (p *NAS) Query(ctx context.Context, fsName string, params map[string]interf
 	if fileSystemMode, ok := fs["fileSystemMode"].(string); ok {
 		volObj.SetFilesystemMode(fileSystemMode)
 	}
+	volObj.SetEncrypted(isEncrypted(fs))
 
 	return volObj, nil
 }
@@ -971,6 +1154,62 @@ func (p *NAS) Delete(ctx context.Context, fsName string) error {
 	return err
 }
 
+// DeleteShare deletes the nfs share of the filesystem and keeps the filesystem and its data
+func (p *NAS) DeleteShare(ctx context.Context, fsName string) error {
+	fs, err := p.cli.GetFileSystemByName(ctx, fsName)
+	if err != nil {
+		log.AddContext(ctx).Errorf("Get filesystem %s error: %v", fsName, err)
+		return err
+	}
+	if fs == nil {
+		log.AddContext(ctx).Infof("Filesystem %s of the share to delete does not exist", fsName)
+		return nil
+	}
+
+	vStoreID, _ := fs["vstoreId"].(string)
+	return p.deleteShare(ctx, fsName, vStoreID, p.cli)
+}
+
+// RestoreShare recreates the deleted nfs share of the filesystem and allows the access of the clients, the
+// share is restored on the local storage only as DeleteShare deletes it
+func (p *NAS) RestoreShare(ctx context.Context, params map[string]interface{}) error {
+	fsName, ok := params["name"].(string)
+	if !ok {
+		return pkgUtils.Errorf(ctx, "convert fsName to string failed, data: %v", params["name"])
+	}
+
+	if _, exist := params["authclient"].(string); !exist {
+		return pkgUtils.Errorf(ctx, "authclient must be provided to restore the share of filesystem %s", fsName)
+	}
+
+	fs, err := p.cli.GetFileSystemByName(ctx, fsName)
+	if err != nil {
+		log.AddContext(ctx).Errorf("Get filesystem %s error: %v", fsName, err)
+		return err
+	}
+	if fs == nil {
+		return fmt.Errorf("%w: filesystem %s of the share to restore does not exist", constants.ErrVolumeNotFound,
+			fsName)
+	}
+
+	if err = p.preProcessSquash(ctx, params); err != nil {
+		return err
+	}
+
+	taskResult := map[string]interface{}{
+		"localFSID":     fs["ID"],
+		"localVStoreID": fs["vstoreId"],
+	}
+	shareResult, err := p.createShare(ctx, params, taskResult)
+	if err != nil {
+		return err
+	}
+
+	taskResult["shareID"] = shareResult["shareID"]
+	_, err = p.allowShareAccess(ctx, params, taskResult)
+	return err
+}
+
 // Expand expands volume size
 func (p *NAS) Expand(ctx context.Context, fsName string, newSize int64) error {
 	fs, err := p.cli.GetFileSystemByName(ctx, fsName)
@@ -980,9 +1219,8 @@ func (p *NAS) Expand(ctx context.Context, fsName string, newSize int64) error {
 	}
 
 	if fs == nil {
-		msg := fmt.Sprintf("Filesystem %s to expand does not exist", fsName)
-		log.AddContext(ctx).Errorf(msg)
-		return errors.New(msg)
+		log.AddContext(ctx).Errorf("Filesystem %s to expand does not exist", fsName)
+		return fmt.Errorf("%w: filesystem %s to expand does not exist", constants.ErrVolumeNotFound, fsName)
 	}
 
 	curSize := utils.ParseIntWithDefault(fs["CAPACITY"].(string), 10, 64, 0)
@@ -1030,6 +1268,10 @@ func (p *NAS) Expand(ctx context.Context, fsName string, newSize int64) error {
 	}
 
 	expandTask.AddTask("Expand-Local-FileSystem", p.expandLocalFS, nil)
+	if len(hyperMetroIDs) > 0 {
+		expandTask.AddTask("Wait-HyperMetro-Expanded", p.waitHyperMetroFSExpanded, nil)
+	}
+
 	params := map[string]interface{}{
 		"name":            fsName,
 		"size":            newSize,
@@ -1305,7 +1547,7 @@ func (p *NAS) deleteHyperMetroShare(ctx context.Context,
 }
 
 func (p *NAS) deleteShare(ctx context.Context, name, vStoreID string, cli client.BaseClientInterface) error {
-	sharePath := utils.GetOriginSharePath(name)
+	sharePath := p.getOriginSharePath(name)
 	share, err := cli.GetNfsShareByPath(ctx, sharePath, vStoreID)
 	if err != nil {
 		log.AddContext(ctx).Errorf("Get nfs share by path %s error: %v", sharePath, err)
@@ -1729,6 +1971,21 @@ func (p *NAS) expandLocalFS(ctx context.Context,
 	return nil, err
 }
 
+func (p *NAS) waitHyperMetroFSExpanded(ctx context.Context,
+	params, taskResult map[string]interface{}) (map[string]interface{}, error) {
+	fsName, ok := params["name"].(string)
+	if !ok {
+		return nil, pkgUtils.Errorf(ctx, "convert fsName to string failed, data: %v", params["name"])
+	}
+	newSize, ok := params["size"].(int64)
+	if !ok {
+		return nil, pkgUtils.Errorf(ctx, "convert newSize to int64 failed, data: %v", params["size"])
+	}
+
+	return nil, waitCapacityExpanded(ctx, "filesystem", fsName, newSize,
+		p.cli.GetFileSystemByName, p.metroRemoteCli.GetFileSystemByName)
+}
+
 // CreateSnapshot creates fs snapshot
 func (p *NAS) CreateSnapshot(ctx context.Context, fsName, snapshotName string) (map[string]interface{}, error) {
 	fs, err := p.cli.GetFileSystemByName(ctx, fsName)
@@ -1737,9 +1994,9 @@ func (p *NAS) CreateSnapshot(ctx context.Context, fsName, snapshotName string) (
 		return nil, err
 	}
 	if fs == nil {
-		msg := fmt.Sprintf("Filesystem %s to create snapshot does not exist", fsName)
-		log.AddContext(ctx).Errorf(msg)
-		return nil, errors.New(msg)
+		log.AddContext(ctx).Errorf("Filesystem %s to create snapshot does not exist", fsName)
+		return nil, fmt.Errorf("%w: filesystem %s to create snapshot does not exist",
+			constants.ErrVolumeNotFound, fsName)
 	}
 
 	fsId, ok := fs["ID"].(string)
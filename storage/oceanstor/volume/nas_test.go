@@ -0,0 +1,157 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package volume
+
+import (
+	"context"
+	"testing"
+
+	"huawei-csi-driver/storage/oceanstor/client"
+)
+
+type fakeCloneFSClient struct {
+	client.BaseClientInterface
+
+	cloneFS  map[string]interface{}
+	fs       map[string]interface{}
+	extended int64
+}
+
+func (f *fakeCloneFSClient) CloneFileSystem(ctx context.Context, name string, allocType int,
+	parentID, parentSnapshotID string) (map[string]interface{}, error) {
+	return f.cloneFS, nil
+}
+
+func (f *fakeCloneFSClient) GetFileSystemByID(ctx context.Context, id string) (map[string]interface{}, error) {
+	return f.fs, nil
+}
+
+func (f *fakeCloneFSClient) ExtendFileSystem(ctx context.Context, fsID string, newCapacity int64) error {
+	f.extended = newCapacity
+	return nil
+}
+
+func (f *fakeCloneFSClient) SplitCloneFS(ctx context.Context, fsID, vStoreId string, splitSpeed int,
+	isDeleteParentSnapshot bool) error {
+	return nil
+}
+
+func TestCloneFilesystemExtendsToRequestedCapacity(t *testing.T) {
+	tests := []struct {
+		name    string
+		cloneFS map[string]interface{}
+		fs      map[string]interface{}
+		want    int64
+	}{
+		{"SnapshotSmallerThanRequest",
+			map[string]interface{}{"ID": "1", "CAPACITY": "2097152"}, nil, 4194304},
+		{"SnapshotEqualToRequest",
+			map[string]interface{}{"ID": "1", "CAPACITY": "4194304"}, nil, 0},
+		{"CapacityFromFilesystem",
+			map[string]interface{}{"ID": "1"}, map[string]interface{}{"CAPACITY": "2097152", "ISCLONEFS": "false"},
+			4194304},
+		{"CapacityUnknown",
+			map[string]interface{}{"ID": "1"}, map[string]interface{}{"ISCLONEFS": "false"}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.fs == nil {
+				tt.fs = map[string]interface{}{"ISCLONEFS": "false"}
+			}
+			cli := &fakeCloneFSClient{cloneFS: tt.cloneFS, fs: tt.fs}
+			req := &CloneFilesystemRequest{
+				FsName:          "pvc_1",
+				CloneFsCapacity: 4194304,
+				SrcCapacity:     4194304,
+			}
+			_, err := NewNAS(cli, nil, nil, "", NASHyperMetro{}, "").cloneFilesystem(context.Background(), req)
+			if err != nil {
+				t.Fatalf("cloneFilesystem() error = %v", err)
+			}
+			if cli.extended != tt.want {
+				t.Errorf("cloneFilesystem() extended to %d, want %d", cli.extended, tt.want)
+			}
+		})
+	}
+}
+
+type fakeShareACLClient struct {
+	client.BaseClientInterface
+
+	queriedVStoreIDs []string
+	request          *client.UpdateNFSShareClientACLRequest
+}
+
+func (f *fakeShareACLClient) GetFileSystemByName(ctx context.Context, name string) (map[string]interface{}, error) {
+	return map[string]interface{}{"ID": "1", "NAME": name, "vstoreId": "2"}, nil
+}
+
+func (f *fakeShareACLClient) GetNfsShareByPath(ctx context.Context, path, vStoreID string) (
+	map[string]interface{}, error) {
+	f.queriedVStoreIDs = append(f.queriedVStoreIDs, vStoreID)
+	return map[string]interface{}{"ID": "10"}, nil
+}
+
+func (f *fakeShareACLClient) GetNfsShareAccessCount(ctx context.Context, parentID, vStoreID string) (int64, error) {
+	f.queriedVStoreIDs = append(f.queriedVStoreIDs, vStoreID)
+	return 1, nil
+}
+
+func (f *fakeShareACLClient) GetNfsShareAccessRange(ctx context.Context, parentID, vStoreID string,
+	startRange, endRange int64) ([]interface{}, error) {
+	f.queriedVStoreIDs = append(f.queriedVStoreIDs, vStoreID)
+	return []interface{}{map[string]interface{}{"ID": "100", "NAME": "10.0.0.0/24", "ALLSQUASH": "0",
+		"ROOTSQUASH": "0"}}, nil
+}
+
+func (f *fakeShareACLClient) UpdateNFSShareClientACL(ctx context.Context,
+	req *client.UpdateNFSShareClientACLRequest) error {
+	f.request = req
+	return nil
+}
+
+func TestUpdateShareClientACL(t *testing.T) {
+	tests := []struct {
+		name    string
+		clients []string
+	}{
+		{"Restrict", []string{"10.0.0.0/24", "192.168.1.100"}},
+		{"Revoke", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cli := &fakeShareACLClient{}
+			nas := NewNAS(cli, nil, nil, "", NASHyperMetro{}, "")
+			if err := nas.UpdateShareClientACL(context.Background(), "pvc_1", tt.clients); err != nil {
+				t.Fatalf("UpdateShareClientACL() error = %v", err)
+			}
+
+			for _, vStoreID := range cli.queriedVStoreIDs {
+				if vStoreID != "2" {
+					t.Errorf("UpdateShareClientACL() queries vStore %q, want the vStore of the filesystem", vStoreID)
+				}
+			}
+			if cli.request == nil || cli.request.VStoreID != "2" || cli.request.ShareID != "10" ||
+				len(cli.request.Clients) != len(tt.clients) || cli.request.AllSquash != 0 {
+				t.Errorf("UpdateShareClientACL() request = %+v, want clients %v in vStore 2", cli.request,
+					tt.clients)
+			}
+		})
+	}
+}
@@ -24,6 +24,7 @@ import (
 	"strconv"
 	"time"
 
+	"huawei-csi-driver/pkg/constants"
 	pkgUtils "huawei-csi-driver/pkg/utils"
 	"huawei-csi-driver/storage/oceanstor/client"
 	"huawei-csi-driver/storage/oceanstor/smartx"
@@ -35,6 +36,7 @@ import (
 // SAN provides base san client
 type SAN struct {
 	Base
+	lunBatchCreator *client.LunBatchCreator
 }
 
 // NewSAN inits a new san client
@@ -49,6 +51,20 @@ func NewSAN(cli, metroRemoteCli, replicaRemoteCli client.BaseClientInterface, pr
 	}
 }
 
+// WithLunBatchCreator creates the new luns in batches by the creator
+func (p *SAN) WithLunBatchCreator(creator *client.LunBatchCreator) *SAN {
+	p.lunBatchCreator = creator
+	return p
+}
+
+func (p *SAN) createLun(ctx context.Context, params map[string]interface{}) (map[string]interface{}, error) {
+	if p.lunBatchCreator != nil {
+		return p.lunBatchCreator.CreateLun(ctx, params)
+	}
+
+	return p.cli.CreateLun(ctx, params)
+}
+
 func (p *SAN) preCreate(ctx context.Context, params map[string]interface{}) error {
 	err := p.commonPreCreate(ctx, params)
 	if err != nil {
@@ -69,6 +85,15 @@ func (p *SAN) preCreate(ctx context.Context, params map[string]interface{}) erro
 		params["clonefrom"] = p.cli.MakeLunName(v)
 	}
 
+	if cloneFrom, exist := params["clonefrom"].(string); exist {
+		srcLun, err := p.cli.GetLunByName(ctx, cloneFrom)
+		if err != nil {
+			log.AddContext(ctx).Errorf("Get clone src LUN %s error: %v", cloneFrom, err)
+			return err
+		}
+		tagCloneRoot(ctx, params, srcLun)
+	}
+
 	err = p.setWorkLoadID(ctx, p.cli, params)
 	if err != nil {
 		return err
@@ -99,6 +124,7 @@ func (p *SAN) Create(ctx context.Context, params map[string]interface{}) (utils.
 	}
 
 	taskflow.AddTask("Create-Local-LUN", p.createLocalLun, p.revertLocalLun)
+	taskflow.AddTask("Confirm-Encryption", p.confirmLunEncrypted, nil)
 	taskflow.AddTask("Create-Local-QoS", p.createLocalQoS, p.revertLocalQoS)
 
 	if replicationOK && replication {
@@ -111,6 +137,13 @@ func (p *SAN) Create(ctx context.Context, params map[string]interface{}) (utils.
 		taskflow.AddTask("Create-HyperMetro", p.createHyperMetro, p.revertHyperMetro)
 	}
 
+	if writeProtect, ok := params["writeprotect"].(bool); ok && writeProtect {
+		if hyperMetroOK && hyperMetro {
+			return nil, pkgUtils.Errorf(ctx, "cannot write-protect a hypermetro volume")
+		}
+		taskflow.AddTask("Set-Write-Protect", p.setWriteProtect, nil)
+	}
+
 	res, err := taskflow.Run(params)
 	if err != nil {
 		taskflow.Revert()
@@ -141,6 +174,7 @@ func (p *SAN) Query(ctx context.Context, name string) (utils.Volume, error) {
 	if capacity, err := strconv.ParseInt(lun["CAPACITY"].(string), 10, 64); err == nil {
 		volObj.SetSize(utils.TransK8SCapacity(capacity, 512))
 	}
+	volObj.SetEncrypted(isEncrypted(lun))
 
 	return volObj, nil
 }
@@ -206,9 +240,8 @@ func (p *SAN) Expand(ctx context.Context, name string, newSize int64) (bool, err
 		log.AddContext(ctx).Errorf("Get lun by name %s error: %v", lunName, err)
 		return false, err
 	} else if lun == nil {
-		msg := fmt.Sprintf("Lun %s to expand does not exist", lunName)
-		log.AddContext(ctx).Errorf(msg)
-		return false, errors.New(msg)
+		log.AddContext(ctx).Errorf("Lun %s to expand does not exist", lunName)
+		return false, fmt.Errorf("%w: lun %s to expand does not exist", constants.ErrVolumeNotFound, lunName)
 	}
 
 	isAttached := lun["EXPOSEDTOINITIATOR"] == "true"
@@ -224,13 +257,21 @@ func (p *SAN) Expand(ctx context.Context, name string, newSize int64) (bool, err
 	if err != nil {
 		return false, pkgUtils.Errorf(ctx, "Unmarshal HASHSSOBJECT failed, error: %v", err)
 	}
+	isHyperMetro := rss["HyperMetro"] == "TRUE"
+	if isHyperMetro && p.metroRemoteCli == nil {
+		return false, pkgUtils.Errorf(ctx, "lun %s is a hypermetro lun, but the remote client for hypermetro is nil",
+			lunName)
+	}
+
 	expandTask := taskflow.NewTaskFlow(ctx, "Expand-LUN-Volume")
 	expandTask.AddTask("Expand-PreCheck-Capacity", p.preExpandCheckCapacity, nil)
 
-	if hyperMetro, ok := rss["HyperMetro"]; ok && hyperMetro == "TRUE" {
+	// Both sides of the hypermetro pair are checked before any of them is expanded, and the suspended pair is
+	// resumed if the expansion fails, so that a failed expansion does not leave the pair inconsistent.
+	if isHyperMetro {
 		expandTask.AddTask("Expand-HyperMetro-Remote-PreCheck-Capacity",
 			p.preExpandHyperMetroCheckRemoteCapacity, nil)
-		expandTask.AddTask("Suspend-HyperMetro", p.suspendHyperMetro, nil)
+		expandTask.AddTask("Suspend-HyperMetro", p.suspendHyperMetro, p.revertSuspendHyperMetro)
 		expandTask.AddTask("Expand-HyperMetro-Remote-LUN", p.expandHyperMetroRemoteLun, nil)
 	}
 
@@ -243,8 +284,9 @@ func (p *SAN) Expand(ctx context.Context, name string, newSize int64) (bool, err
 
 	expandTask.AddTask("Expand-Local-Lun", p.expandLocalLun, nil)
 
-	if hyperMetro, ok := rss["HyperMetro"]; ok && hyperMetro == "TRUE" {
+	if isHyperMetro {
 		expandTask.AddTask("Sync-HyperMetro", p.syncHyperMetro, nil)
+		expandTask.AddTask("Wait-HyperMetro-Expanded", p.waitHyperMetroLunExpanded, nil)
 	}
 
 	if remoteReplication, ok := rss["RemoteReplication"]; ok && remoteReplication == "TRUE" {
@@ -259,9 +301,24 @@ func (p *SAN) Expand(ctx context.Context, name string, newSize int64) (bool, err
 		"localParentName": lun["PARENTNAME"].(string),
 	}
 	_, err = expandTask.Run(params)
+	if err != nil {
+		expandTask.Revert()
+	}
 	return isAttached, err
 }
 
+func (p *SAN) confirmLunEncrypted(ctx context.Context,
+	params, taskResult map[string]interface{}) (map[string]interface{}, error) {
+	lunName, _ := params["name"].(string)
+	lun, err := p.cli.GetLunByName(ctx, lunName)
+	if err != nil {
+		log.AddContext(ctx).Errorf("Get LUN %s error: %v", lunName, err)
+		return nil, err
+	}
+
+	return nil, confirmEncrypted(ctx, "LUN", params, lun)
+}
+
 func (p *SAN) createLocalLun(ctx context.Context,
 	params, taskResult map[string]interface{}) (map[string]interface{}, error) {
 	lunName, ok := params["name"].(string)
@@ -274,15 +331,28 @@ func (p *SAN) createLocalLun(ctx context.Context,
 		return nil, err
 	}
 
+	lun, err = waitDeletingObjectGone(ctx, "LUN", lunName, lun, p.cli.GetLunByName)
+	if err != nil {
+		log.AddContext(ctx).Errorln(err)
+		return nil, err
+	}
+
 	if lun == nil {
 		params["parentid"] = params["poolID"]
+		params["owningcontroller"], _ = params["localowner"].(string)
 
 		if _, exist := params["clonefrom"]; exist {
-			lun, err = p.clone(ctx, params, taskResult)
+			err = p.inheritSourceEncryption(ctx, params)
+			if err == nil {
+				lun, err = p.clone(ctx, params, taskResult)
+			}
 		} else if _, exist := params["fromSnapshot"]; exist {
-			lun, err = p.createFromSnapshot(ctx, params, taskResult)
+			err = p.inheritSourceEncryption(ctx, params)
+			if err == nil {
+				lun, err = p.createFromSnapshot(ctx, params, taskResult)
+			}
 		} else {
-			lun, err = p.cli.CreateLun(ctx, params)
+			lun, err = p.createLun(ctx, params)
 		}
 
 		if err != nil {
@@ -303,6 +373,32 @@ func (p *SAN) createLocalLun(ctx context.Context,
 	}, nil
 }
 
+// inheritSourceEncryption takes the encryption of the cloned LUN from the source LUN or the parent LUN of the
+// source snapshot, the missing source is left to the clone which reports it
+func (p *SAN) inheritSourceEncryption(ctx context.Context, params map[string]interface{}) error {
+	var source map[string]interface{}
+	var err error
+	if cloneFrom, ok := params["clonefrom"].(string); ok {
+		source, err = p.cli.GetLunByName(ctx, cloneFrom)
+	} else if fromSnapshot, ok := params["fromSnapshot"].(string); ok {
+		var snapshot map[string]interface{}
+		snapshot, err = p.cli.GetLunSnapshotByName(ctx, fromSnapshot)
+		if err == nil && snapshot != nil {
+			parentID, _ := snapshot["PARENTID"].(string)
+			source, err = p.cli.GetLunByID(ctx, parentID)
+		}
+	}
+	if err != nil {
+		log.AddContext(ctx).Errorf("Get clone source of LUN %v error: %v", params["name"], err)
+		return err
+	}
+	if source == nil {
+		return nil
+	}
+
+	return inheritEncryption(ctx, params, source)
+}
+
 func (p *SAN) clonePair(ctx context.Context, params map[string]interface{}) (map[string]interface{}, error) {
 	cloneFrom, ok := params["clonefrom"].(string)
 	if !ok {
@@ -706,6 +802,22 @@ func (p *SAN) createFromSnapshot(ctx context.Context,
 	}
 }
 
+// setWriteProtect makes the local LUN read-only after all the other tasks which may write it
+func (p *SAN) setWriteProtect(ctx context.Context,
+	params, taskResult map[string]interface{}) (map[string]interface{}, error) {
+	lunID, ok := taskResult["localLunID"].(string)
+	if !ok {
+		return nil, pkgUtils.Errorf(ctx, "lunID convert to string failed, data: %v", taskResult["localLunID"])
+	}
+
+	if err := p.cli.SetLunWriteProtect(ctx, lunID); err != nil {
+		log.AddContext(ctx).Errorf("Set write protection of LUN %s error: %v", lunID, err)
+		return nil, err
+	}
+
+	return nil, nil
+}
+
 func (p *SAN) revertLocalLun(ctx context.Context, taskResult map[string]interface{}) error {
 	lunID, exist := taskResult["localLunID"].(string)
 	if !exist || lunID == "" {
@@ -835,7 +947,7 @@ func (p *SAN) deleteLunCopy(ctx context.Context, lunCopyName string, isDeleteSna
 }
 
 func (p *SAN) waitLunCopyFinish(ctx context.Context, lunCopyName string) error {
-	err := utils.WaitUntil(func() (bool, error) {
+	err := utils.WaitArrayTask(ctx, "LunCopy", lunCopyName, func() (bool, error) {
 		lunCopy, err := p.cli.GetLunCopyByName(ctx, lunCopyName)
 		if err != nil {
 			return false, err
@@ -848,8 +960,10 @@ func (p *SAN) waitLunCopyFinish(ctx context.Context, lunCopyName string) error {
 		if !ok {
 			return false, pkgUtils.Errorf(ctx, "healthStatus convert to string failed, data: %v", lunCopy["HEALTHSTATUS"])
 		}
+		lunCopyID, _ := lunCopy["ID"].(string)
 		if healthStatus == lunCopyHealthStatusFault {
-			return false, fmt.Errorf("luncopy %s is at fault status", lunCopyName)
+			return false, utils.NewArrayTaskError("LunCopy", lunCopyID,
+				fmt.Sprintf("luncopy %s is at fault health status %s", lunCopyName, healthStatus))
 		}
 
 		runningStatus, ok := lunCopy["RUNNINGSTATUS"].(string)
@@ -861,7 +975,8 @@ func (p *SAN) waitLunCopyFinish(ctx context.Context, lunCopyName string) error {
 			return false, nil
 		} else if runningStatus == lunCopyRunningStatusStop ||
 			runningStatus == lunCopyRunningStatusPaused {
-			return false, fmt.Errorf("Luncopy %s is stopped", lunCopyName)
+			return false, utils.NewArrayTaskError("LunCopy", lunCopyID,
+				fmt.Sprintf("luncopy %s is stopped at running status %s", lunCopyName, runningStatus))
 		} else {
 			return true, nil
 		}
@@ -875,7 +990,7 @@ func (p *SAN) waitLunCopyFinish(ctx context.Context, lunCopyName string) error {
 }
 
 func (p *SAN) waitClonePairFinish(ctx context.Context, clonePairID string) error {
-	err := utils.WaitUntil(func() (bool, error) {
+	err := utils.WaitArrayTask(ctx, "ClonePair", clonePairID, func() (bool, error) {
 		clonePair, err := p.cli.GetClonePairInfo(ctx, clonePairID)
 		if err != nil {
 			return false, err
@@ -889,7 +1004,8 @@ func (p *SAN) waitClonePairFinish(ctx context.Context, clonePairID string) error
 			return false, pkgUtils.Errorf(ctx, "healthStatus convert to string failed, data: %v", clonePair["copyStatus"])
 		}
 		if healthStatus == clonePairHealthStatusFault {
-			return false, fmt.Errorf("ClonePair %s is at fault status", clonePairID)
+			return false, utils.NewArrayTaskError("ClonePair", clonePairID,
+				fmt.Sprintf("clone pair is at fault copy status %s", healthStatus))
 		}
 
 		runningStatus, ok := clonePair["syncStatus"].(string)
@@ -903,7 +1019,8 @@ func (p *SAN) waitClonePairFinish(ctx context.Context, clonePairID string) error
 			runningStatus == clonePairRunningStatusUnsyncing {
 			return false, nil
 		} else {
-			return false, fmt.Errorf("ClonePair %s running status is abnormal", clonePairID)
+			return false, utils.NewArrayTaskError("ClonePair", clonePairID,
+				fmt.Sprintf("clone pair is at abnormal sync status %s", runningStatus))
 		}
 	}, time.Hour*6, time.Second*5)
 
@@ -968,6 +1085,7 @@ func (p *SAN) createRemoteLun(ctx context.Context,
 		}
 
 		params["parentid"] = taskResult["remotePoolID"]
+		params["owningcontroller"], _ = params["remoteowner"].(string)
 		lun, err = remoteCli.CreateLun(ctx, params)
 		if err != nil {
 			log.AddContext(ctx).Errorf("Create remote LUN %s error: %v", lunName, err)
@@ -1117,7 +1235,7 @@ func (p *SAN) createHyperMetro(ctx context.Context,
 }
 
 func (p *SAN) waitHyperMetroSyncFinish(ctx context.Context, pairID string) error {
-	err := utils.WaitUntil(func() (bool, error) {
+	err := utils.WaitArrayTask(ctx, "HyperMetroPair", pairID, func() (bool, error) {
 		pair, err := p.cli.GetHyperMetroPair(ctx, pairID)
 		if err != nil {
 			return false, err
@@ -1133,7 +1251,8 @@ func (p *SAN) waitHyperMetroSyncFinish(ctx context.Context, pairID string) error
 			return false, pkgUtils.Errorf(ctx, "healthStatus convert to string failed, data: %v", pair["HEALTHSTATUS"])
 		}
 		if healthStatus == hyperMetroPairHealthStatusFault {
-			return false, fmt.Errorf("Hypermetro pair %s is fault", pairID)
+			return false, utils.NewArrayTaskError("HyperMetroPair", pairID,
+				fmt.Sprintf("hypermetro pair is at fault health status %s", healthStatus))
 		}
 
 		runningStatus, ok := pair["RUNNINGSTATUS"].(string)
@@ -1147,7 +1266,8 @@ func (p *SAN) waitHyperMetroSyncFinish(ctx context.Context, pairID string) error
 			runningStatus == hyperMetroPairRunningStatusPause ||
 			runningStatus == hyperMetroPairRunningStatusError ||
 			runningStatus == hyperMetroPairRunningStatusInvalid {
-			return false, fmt.Errorf("Hypermetro pair %s is at running status %s", pairID, runningStatus)
+			return false, utils.NewArrayTaskError("HyperMetroPair", pairID,
+				fmt.Sprintf("hypermetro pair is at running status %s", runningStatus))
 		} else {
 			return true, nil
 		}
@@ -1337,59 +1457,60 @@ func (p *SAN) deleteHyperMetro(ctx context.Context,
 }
 
 func (p *SAN) preExpandCheckRemoteCapacity(ctx context.Context,
-	params map[string]interface{}, cli client.BaseClientInterface) (string, error) {
+	params map[string]interface{}, cli client.BaseClientInterface) (string, int64, error) {
 	// check the remote pool
 	name, ok := params["name"].(string)
 	if !ok {
-		return "", pkgUtils.Errorf(ctx, "format name to string failed, data: %v", params["name"])
+		return "", 0, pkgUtils.Errorf(ctx, "format name to string failed, data: %v", params["name"])
 	}
 	remoteLunName := p.cli.MakeLunName(name)
 	remoteLun, err := cli.GetLunByName(ctx, remoteLunName)
 	if err != nil {
 		log.AddContext(ctx).Errorf("Get lun by name %s error: %v", remoteLunName, err)
-		return "", err
+		return "", 0, err
 	}
 	if remoteLun == nil {
 		msg := fmt.Sprintf("remote lun %s to extend does not exist", remoteLunName)
 		log.AddContext(ctx).Errorln(msg)
-		return "", errors.New(msg)
+		return "", 0, errors.New(msg)
 	}
 
 	newSize, ok := params["size"].(int64)
 	if !ok {
-		return "", pkgUtils.Errorf(ctx, "format newSize to int64 failed, data: %v", params["size"])
+		return "", 0, pkgUtils.Errorf(ctx, "format newSize to int64 failed, data: %v", params["size"])
 	}
 
 	curSize, err := strconv.ParseInt(remoteLun["CAPACITY"].(string), 10, 64)
 	if err != nil {
-		return "", err
+		return "", 0, err
 	}
 
 	if newSize < curSize {
 		msg := fmt.Sprintf("Remote Lun %s newSize %d must be greater than curSize %d",
 			remoteLunName, newSize, curSize)
 		log.AddContext(ctx).Errorln(msg)
-		return "", errors.New(msg)
+		return "", 0, errors.New(msg)
 	}
 
-	return remoteLun["ID"].(string), nil
+	return remoteLun["ID"].(string), curSize, nil
 }
 
 func (p *SAN) preExpandHyperMetroCheckRemoteCapacity(ctx context.Context,
 	params, taskResult map[string]interface{}) (map[string]interface{}, error) {
-	remoteLunID, err := p.preExpandCheckRemoteCapacity(ctx, params, p.metroRemoteCli)
+	remoteLunID, remoteCapacity, err := p.preExpandCheckRemoteCapacity(ctx, params, p.metroRemoteCli)
 	if err != nil {
 		return nil, err
 	}
 
 	return map[string]interface{}{
-		"remoteLunID": remoteLunID,
+		"remoteLunID":       remoteLunID,
+		"remoteLunCapacity": remoteCapacity,
 	}, nil
 }
 
 func (p *SAN) preExpandReplicationCheckRemoteCapacity(ctx context.Context,
 	params, taskResult map[string]interface{}) (map[string]interface{}, error) {
-	remoteLunID, err := p.preExpandCheckRemoteCapacity(ctx, params, p.replicaRemoteCli)
+	remoteLunID, _, err := p.preExpandCheckRemoteCapacity(ctx, params, p.replicaRemoteCli)
 	if err != nil {
 		return nil, err
 	}
@@ -1447,6 +1568,13 @@ func (p *SAN) expandHyperMetroRemoteLun(ctx context.Context,
 	if !ok {
 		return nil, pkgUtils.Errorf(ctx, "format newSize to int64 failed, data: %v", params["size"])
 	}
+
+	// The remote lun may have been expanded by a previous attempt whose local expansion failed
+	if remoteCapacity, ok := taskResult["remoteLunCapacity"].(int64); ok && remoteCapacity >= newSize {
+		log.AddContext(ctx).Infof("Hypermetro remote lun %s is already expanded to %d", remoteLunID, remoteCapacity)
+		return nil, nil
+	}
+
 	err := p.metroRemoteCli.ExtendLun(ctx, remoteLunID, newSize)
 	if err != nil {
 		log.AddContext(ctx).Errorf("Extend hypermetro remote lun %s error: %v", remoteLunID, err)
@@ -1476,6 +1604,36 @@ func (p *SAN) syncHyperMetro(ctx context.Context,
 	return nil, nil
 }
 
+func (p *SAN) revertSuspendHyperMetro(ctx context.Context, taskResult map[string]interface{}) error {
+	pairID, ok := taskResult["hyperMetroPairID"].(string)
+	if !ok || pairID == "" {
+		return nil
+	}
+
+	err := p.cli.SyncHyperMetroPair(ctx, pairID)
+	if err != nil {
+		log.AddContext(ctx).Errorf("Resume san hypermetro pair %s error: %v", pairID, err)
+		return err
+	}
+
+	return nil
+}
+
+func (p *SAN) waitHyperMetroLunExpanded(ctx context.Context,
+	params, taskResult map[string]interface{}) (map[string]interface{}, error) {
+	name, ok := params["name"].(string)
+	if !ok {
+		return nil, pkgUtils.Errorf(ctx, "format name to string failed, data: %v", params["name"])
+	}
+	newSize, ok := params["size"].(int64)
+	if !ok {
+		return nil, pkgUtils.Errorf(ctx, "format newSize to int64 failed, data: %v", params["size"])
+	}
+
+	return nil, waitCapacityExpanded(ctx, "lun", p.cli.MakeLunName(name), newSize,
+		p.cli.GetLunByName, p.metroRemoteCli.GetLunByName)
+}
+
 func (p *SAN) expandLocalLun(ctx context.Context,
 	params, taskResult map[string]interface{}) (map[string]interface{}, error) {
 
@@ -1506,9 +1664,9 @@ func (p *SAN) CreateSnapshot(ctx context.Context,
 		return nil, err
 	}
 	if lun == nil {
-		msg := fmt.Sprintf("Lun %s to create snapshot does not exist", lunName)
-		log.AddContext(ctx).Errorln(msg)
-		return nil, errors.New(msg)
+		log.AddContext(ctx).Errorf("Lun %s to create snapshot does not exist", lunName)
+		return nil, fmt.Errorf("%w: lun %s to create snapshot does not exist", constants.ErrVolumeNotFound,
+			lunName)
 	}
 	lunId, ok := lun["ID"].(string)
 	if !ok {
@@ -0,0 +1,64 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"huawei-csi-driver/utils/log"
+)
+
+// ArrayTaskError is the failure of an asynchronous task of the storage, it carries the ID of the task and the
+// failure detail reported by the storage, so that the failure can be correlated with the logs of the storage
+type ArrayTaskError struct {
+	Task   string
+	TaskID string
+	Detail string
+}
+
+// NewArrayTaskError returns the failure of the asynchronous task of the storage
+func NewArrayTaskError(task, taskID, detail string) *ArrayTaskError {
+	return &ArrayTaskError{Task: task, TaskID: taskID, Detail: detail}
+}
+
+func (e *ArrayTaskError) Error() string {
+	return fmt.Sprintf("%s task %s of the storage failed: %s", e.Task, e.TaskID, e.Detail)
+}
+
+// WaitArrayTask polls the asynchronous task of the storage until it is done. The failure which is not reported
+// as ArrayTaskError by the poll, such as the timeout, is returned as the ArrayTaskError of the task.
+func WaitArrayTask(ctx context.Context, task, taskID string, poll func() (bool, error),
+	timeout, interval time.Duration) error {
+	start := time.Now()
+	log.AddContext(ctx).Debugf("Start to wait for %s task %s of the storage", task, taskID)
+
+	err := WaitUntil(poll, timeout, interval)
+	if err != nil {
+		var taskErr *ArrayTaskError
+		if !errors.As(err, &taskErr) {
+			taskErr = NewArrayTaskError(task, taskID, err.Error())
+		}
+		log.AddContext(ctx).Errorln(taskErr)
+		return taskErr
+	}
+
+	log.AddContext(ctx).Debugf("%s task %s of the storage is done in %v", task, taskID, time.Since(start))
+	return nil
+}
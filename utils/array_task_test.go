@@ -0,0 +1,58 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package utils
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWaitArrayTask(t *testing.T) {
+	tests := []struct {
+		name       string
+		poll       func() (bool, error)
+		wantErr    bool
+		wantTaskID string
+	}{
+		{"Done", func() (bool, error) { return true, nil }, false, ""},
+		{"Timeout", func() (bool, error) { return false, nil }, true, "1"},
+		{"PollError", func() (bool, error) { return false, errors.New("query failed") }, true, "1"},
+		{"TaskFailed", func() (bool, error) {
+			return false, NewArrayTaskError("LunCopy", "2", "fault")
+		}, true, "2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := WaitArrayTask(context.Background(), "LunCopy", "1", tt.poll, 20*time.Millisecond,
+				5*time.Millisecond)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("WaitArrayTask() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil {
+				return
+			}
+
+			var taskErr *ArrayTaskError
+			if !errors.As(err, &taskErr) || taskErr.TaskID != tt.wantTaskID {
+				t.Errorf("WaitArrayTask() error = %v, want task %s", err, tt.wantTaskID)
+			}
+		})
+	}
+}
@@ -0,0 +1,187 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+// Package health provides the liveness and readiness endpoints of the csi driver and its sidecars
+package health
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"huawei-csi-driver/utils/log"
+)
+
+const (
+	// LivenessPath is the path of the liveness endpoint
+	LivenessPath = "/healthz"
+	// ReadinessPath is the path of the readiness endpoint
+	ReadinessPath = "/readyz"
+
+	checkTimeout      = 5 * time.Second
+	readHeaderTimeout = 10 * time.Second
+)
+
+// Check returns the error if the component is not healthy, it must be cheap and must not call the storage
+type Check func(ctx context.Context) error
+
+type namedCheck struct {
+	name  string
+	check Check
+}
+
+// Server serves the liveness and readiness endpoints, the endpoint responds 200 if all of its checks pass,
+// otherwise it responds 503 with the failed checks
+type Server struct {
+	mutex     sync.RWMutex
+	liveness  []namedCheck
+	readiness []namedCheck
+}
+
+// NewServer returns a health server without any check
+func NewServer() *Server {
+	return &Server{}
+}
+
+// AddLivenessCheck adds the check to the liveness endpoint
+func (s *Server) AddLivenessCheck(name string, check Check) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.liveness = append(s.liveness, namedCheck{name: name, check: check})
+}
+
+// AddReadinessCheck adds the check to the readiness endpoint
+func (s *Server) AddReadinessCheck(name string, check Check) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.readiness = append(s.readiness, namedCheck{name: name, check: check})
+}
+
+// Handler returns the http handler of the liveness and readiness endpoints
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(LivenessPath, func(w http.ResponseWriter, r *http.Request) {
+		s.mutex.RLock()
+		checks := s.liveness
+		s.mutex.RUnlock()
+		serveChecks(w, r, checks)
+	})
+	mux.HandleFunc(ReadinessPath, func(w http.ResponseWriter, r *http.Request) {
+		s.mutex.RLock()
+		checks := s.readiness
+		s.mutex.RUnlock()
+		serveChecks(w, r, checks)
+	})
+	return mux
+}
+
+// Serve serves the endpoints at the address, it blocks until the server fails
+func (s *Server) Serve(ctx context.Context, address string) {
+	server := &http.Server{
+		Addr:              address,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: readHeaderTimeout,
+	}
+
+	log.AddContext(ctx).Infof("Start to serve health probes on %s", address)
+	if err := server.ListenAndServe(); err != nil {
+		log.AddContext(ctx).Errorf("Serve health probes on %s failed, error: %v", address, err)
+	}
+}
+
+func serveChecks(w http.ResponseWriter, r *http.Request, checks []namedCheck) {
+	ctx, cancel := context.WithTimeout(r.Context(), checkTimeout)
+	defer cancel()
+
+	var failures []string
+	for _, c := range checks {
+		if err := c.check(ctx); err != nil {
+			log.AddContext(ctx).Warningf("Health check %s of %s failed, error: %v", c.name, r.URL.Path, err)
+			failures = append(failures, fmt.Sprintf("%s: %v", c.name, err))
+		}
+	}
+
+	if len(failures) != 0 {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = fmt.Fprintln(w, strings.Join(failures, "\n"))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	_, _ = fmt.Fprintln(w, "ok")
+}
+
+// Gate is a check which fails until it is opened, such as the warm-up of a component
+type Gate struct {
+	mutex sync.RWMutex
+	open  bool
+	name  string
+}
+
+// NewGate returns a closed gate, the name is used in the error of the check
+func NewGate(name string) *Gate {
+	return &Gate{name: name}
+}
+
+// Open opens the gate, so that the check passes
+func (g *Gate) Open() {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	g.open = true
+}
+
+// Check returns the error if the gate is not opened
+func (g *Gate) Check(context.Context) error {
+	g.mutex.RLock()
+	defer g.mutex.RUnlock()
+	if !g.open {
+		return fmt.Errorf("%s is not completed", g.name)
+	}
+
+	return nil
+}
+
+// Heartbeat records the time of the last beat, which is used to check whether a periodic task is stale
+type Heartbeat struct {
+	mutex sync.RWMutex
+	last  time.Time
+}
+
+// NewHeartbeat returns a heartbeat which beats at the creation time
+func NewHeartbeat() *Heartbeat {
+	return &Heartbeat{last: time.Now()}
+}
+
+// Beat records the current time as the last beat
+func (h *Heartbeat) Beat() {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.last = time.Now()
+}
+
+// CheckAge returns the error if the last beat is older than the max age
+func (h *Heartbeat) CheckAge(maxAge time.Duration) error {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+	if age := time.Since(h.last); age > maxAge {
+		return fmt.Errorf("the last beat is %s ago, exceeds %s", age.Round(time.Second), maxAge)
+	}
+
+	return nil
+}
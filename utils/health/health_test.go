@@ -0,0 +1,128 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package health
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"huawei-csi-driver/utils/log"
+)
+
+const logName = "health_test.log"
+
+func TestMain(m *testing.M) {
+	log.MockInitLogging(logName)
+	defer log.MockStopLogging(logName)
+
+	m.Run()
+}
+
+func passCheck(context.Context) error {
+	return nil
+}
+
+func failCheck(context.Context) error {
+	return errors.New("mock failure")
+}
+
+func TestServerHandler(t *testing.T) {
+	tests := []struct {
+		name      string
+		liveness  []Check
+		readiness []Check
+		path      string
+		want      int
+	}{
+		{name: "NoCheck", path: LivenessPath, want: http.StatusOK},
+		{name: "LivenessPass", liveness: []Check{passCheck, passCheck}, path: LivenessPath, want: http.StatusOK},
+		{name: "LivenessFail", liveness: []Check{passCheck, failCheck}, path: LivenessPath,
+			want: http.StatusServiceUnavailable},
+		{name: "ReadinessFail", readiness: []Check{failCheck}, path: ReadinessPath,
+			want: http.StatusServiceUnavailable},
+		{name: "ReadinessNotAffectLiveness", readiness: []Check{failCheck}, path: LivenessPath,
+			want: http.StatusOK},
+		{name: "UnknownPath", path: "/unknown", want: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := NewServer()
+			for _, check := range tt.liveness {
+				server.AddLivenessCheck(tt.name, check)
+			}
+			for _, check := range tt.readiness {
+				server.AddReadinessCheck(tt.name, check)
+			}
+
+			recorder := httptest.NewRecorder()
+			server.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if recorder.Code != tt.want {
+				t.Errorf("ServeHTTP() code = %d, want %d, body: %s", recorder.Code, tt.want, recorder.Body)
+			}
+		})
+	}
+}
+
+func TestGateAndHeartbeat(t *testing.T) {
+	server := NewServer()
+	gate := NewGate("warm-up")
+	heartbeat := NewHeartbeat()
+	server.AddReadinessCheck("warm-up", gate.Check)
+	server.AddLivenessCheck("heartbeat", func(context.Context) error {
+		return heartbeat.CheckAge(time.Minute)
+	})
+
+	testServer := httptest.NewServer(server.Handler())
+	defer testServer.Close()
+
+	getCode := func(path string) int {
+		resp, err := http.Get(testServer.URL + path)
+		if err != nil {
+			t.Fatalf("get %s failed, error: %v", path, err)
+		}
+		defer resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	if code := getCode(ReadinessPath); code != http.StatusServiceUnavailable {
+		t.Errorf("readiness before opening the gate = %d, want %d", code, http.StatusServiceUnavailable)
+	}
+
+	gate.Open()
+	if code := getCode(ReadinessPath); code != http.StatusOK {
+		t.Errorf("readiness after opening the gate = %d, want %d", code, http.StatusOK)
+	}
+
+	if code := getCode(LivenessPath); code != http.StatusOK {
+		t.Errorf("liveness with fresh heartbeat = %d, want %d", code, http.StatusOK)
+	}
+
+	heartbeat.last = time.Now().Add(-2 * time.Minute)
+	if code := getCode(LivenessPath); code != http.StatusServiceUnavailable {
+		t.Errorf("liveness with stale heartbeat = %d, want %d", code, http.StatusServiceUnavailable)
+	}
+
+	heartbeat.Beat()
+	if code := getCode(LivenessPath); code != http.StatusOK {
+		t.Errorf("liveness after beating = %d, want %d", code, http.StatusOK)
+	}
+}
@@ -0,0 +1,56 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+// Package k8sutils provides Kubernetes utilities
+package k8sutils
+
+import (
+	"context"
+
+	authenticationV1 "k8s.io/api/authentication/v1"
+	authorizationV1 "k8s.io/api/authorization/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// AuthorizationOps defines interfaces required by the authorization of the requesting users
+type AuthorizationOps interface {
+	// IsClusterAdmin checks whether the user is allowed to do everything in the cluster like the cluster-admin
+	IsClusterAdmin(ctx context.Context, user authenticationV1.UserInfo) (bool, error)
+}
+
+// IsClusterAdmin checks whether the user is allowed to do everything in the cluster like the cluster-admin
+func (k *KubeClient) IsClusterAdmin(ctx context.Context, user authenticationV1.UserInfo) (bool, error) {
+	extra := make(map[string]authorizationV1.ExtraValue, len(user.Extra))
+	for key, value := range user.Extra {
+		extra[key] = authorizationV1.ExtraValue(value)
+	}
+
+	review := &authorizationV1.SubjectAccessReview{
+		Spec: authorizationV1.SubjectAccessReviewSpec{
+			ResourceAttributes: &authorizationV1.ResourceAttributes{Verb: "*", Group: "*", Resource: "*"},
+			User:               user.Username,
+			Groups:             user.Groups,
+			UID:                user.UID,
+			Extra:              extra,
+		},
+	}
+	result, err := k.clientSet.AuthorizationV1().SubjectAccessReviews().Create(ctx, review, metaV1.CreateOptions{})
+	if err != nil {
+		return false, err
+	}
+
+	return result.Status.Allowed, nil
+}
@@ -0,0 +1,161 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package k8sutils
+
+import (
+	"context"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/ghodss/yaml"
+	authenticationV1 "k8s.io/api/authentication/v1"
+	authorizationV1 "k8s.io/api/authorization/v1"
+	rbacV1 "k8s.io/api/rbac/v1"
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+const controllerServiceAccount = "huawei-csi-controller"
+
+var (
+	templateControlLine = regexp.MustCompile(`^\s*\{\{.*\}\}\s*$`)
+	templateAction      = regexp.MustCompile(`\{\{[^}]*\}\}`)
+)
+
+// loadClusterRules returns the rules of the ClusterRoles bound to the service account of the controller in the
+// manifest, the actions of the helm templates are replaced so that the manifest is parsed as plain yaml
+func loadClusterRules(t *testing.T, path string) []rbacV1.PolicyRule {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read manifest %s failed, error: %v", path, err)
+	}
+
+	var lines []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if templateControlLine.MatchString(line) {
+			continue
+		}
+		lines = append(lines, templateAction.ReplaceAllString(line, "huawei-csi"))
+	}
+
+	roles := map[string][]rbacV1.PolicyRule{}
+	var boundRoles []string
+	for _, doc := range strings.Split(strings.Join(lines, "\n"), "\n---") {
+		if !strings.Contains(doc, "rbac.authorization.k8s.io/v1") {
+			continue
+		}
+
+		var typeMeta metaV1.TypeMeta
+		if err := yaml.Unmarshal([]byte(doc), &typeMeta); err != nil {
+			t.Fatalf("parse manifest %s failed, error: %v", path, err)
+		}
+		switch typeMeta.Kind {
+		case "ClusterRole":
+			var role rbacV1.ClusterRole
+			if err := yaml.Unmarshal([]byte(doc), &role); err != nil {
+				t.Fatalf("parse ClusterRole of manifest %s failed, error: %v", path, err)
+			}
+			roles[role.Name] = append(roles[role.Name], role.Rules...)
+		case "ClusterRoleBinding":
+			var binding rbacV1.ClusterRoleBinding
+			if err := yaml.Unmarshal([]byte(doc), &binding); err != nil {
+				t.Fatalf("parse ClusterRoleBinding of manifest %s failed, error: %v", path, err)
+			}
+			for _, subject := range binding.Subjects {
+				if subject.Kind == rbacV1.ServiceAccountKind && subject.Name == controllerServiceAccount {
+					boundRoles = append(boundRoles, binding.RoleRef.Name)
+				}
+			}
+		}
+	}
+
+	var rules []rbacV1.PolicyRule
+	for _, name := range boundRoles {
+		rules = append(rules, roles[name]...)
+	}
+	return rules
+}
+
+func matchesRule(values []string, value string) bool {
+	for _, v := range values {
+		if v == rbacV1.ResourceAll || v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func rulesAllow(rules []rbacV1.PolicyRule, verb, group, resource string) bool {
+	for _, rule := range rules {
+		if matchesRule(rule.Verbs, verb) && matchesRule(rule.APIGroups, group) &&
+			matchesRule(rule.Resources, resource) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestIsClusterAdminWithShippedRBAC(t *testing.T) {
+	manifests := []string{
+		"../../helm/esdk/templates/huawei-csi-controller.yaml",
+		"../../manual/esdk/deploy/huawei-csi-controller.yaml",
+		"../../manual/esdk/deploy/huawei-csi-controller-multi.yaml",
+	}
+
+	for _, manifest := range manifests {
+		t.Run(manifest, func(t *testing.T) {
+			rules := loadClusterRules(t, manifest)
+			if len(rules) == 0 {
+				t.Fatalf("no ClusterRole is bound to service account %s in %s", controllerServiceAccount, manifest)
+			}
+
+			// the fake api server authorizes the review request by the shipped rules of the controller, and
+			// answers the review by the groups of the reviewed user
+			clientSet := fake.NewSimpleClientset()
+			clientSet.PrependReactor("create", "subjectaccessreviews",
+				func(action k8stesting.Action) (bool, runtime.Object, error) {
+					if !rulesAllow(rules, "create", authorizationV1.GroupName, "subjectaccessreviews") {
+						return true, nil, apiErrors.NewForbidden(schema.GroupResource{
+							Group: authorizationV1.GroupName, Resource: "subjectaccessreviews"}, "",
+							nil)
+					}
+					review := action.(k8stesting.CreateAction).GetObject().(*authorizationV1.SubjectAccessReview)
+					review.Status.Allowed = matchesRule(review.Spec.Groups, "system:masters")
+					return true, review, nil
+				})
+			helper := &KubeClient{clientSet: clientSet}
+
+			admin, err := helper.IsClusterAdmin(context.Background(),
+				authenticationV1.UserInfo{Username: "admin", Groups: []string{"system:masters"}})
+			if err != nil || !admin {
+				t.Errorf("IsClusterAdmin() of cluster admin = %v, error = %v, want true", admin, err)
+			}
+
+			admin, err = helper.IsClusterAdmin(context.Background(),
+				authenticationV1.UserInfo{Username: "user", Groups: []string{"system:authenticated"}})
+			if err != nil || admin {
+				t.Errorf("IsClusterAdmin() of user = %v, error = %v, want false", admin, err)
+			}
+		})
+	}
+}
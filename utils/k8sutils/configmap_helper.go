@@ -19,9 +19,16 @@ package k8sutils
 
 import (
 	"context"
+	"reflect"
 
 	coreV1 "k8s.io/api/core/v1"
 	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+
+	"huawei-csi-driver/utils/log"
 )
 
 // ConfigmapOps defines interfaces required by configmap
@@ -34,6 +41,13 @@ type ConfigmapOps interface {
 	UpdateConfigmap(context.Context, *coreV1.ConfigMap) (*coreV1.ConfigMap, error)
 	// DeleteConfigmap delete the configmap object given its name and namespace
 	DeleteConfigmap(context.Context, *coreV1.ConfigMap) error
+	// WatchConfigmap calls the handler when the data of the configmap is changed, until the stop channel is closed
+	WatchConfigmap(ctx context.Context, namespace, name string, handler func(configmap *coreV1.ConfigMap),
+		stopCh <-chan struct{})
+	// RecordConfigmapEvent records an event on the configmap
+	RecordConfigmapEvent(ctx context.Context, configmap *coreV1.ConfigMap, eventType, reason, message string) error
+	// ListConfigmapsByLabel lists the configmaps of the namespace which match the label selector
+	ListConfigmapsByLabel(ctx context.Context, namespace, labelSelector string) ([]coreV1.ConfigMap, error)
 }
 
 // CreateConfigmap creates the given configmap
@@ -56,3 +70,68 @@ func (k *KubeClient) UpdateConfigmap(ctx context.Context, configmap *coreV1.Conf
 func (k *KubeClient) DeleteConfigmap(ctx context.Context, configmap *coreV1.ConfigMap) error {
 	return k.clientSet.CoreV1().ConfigMaps(configmap.Namespace).Delete(ctx, configmap.Name, metaV1.DeleteOptions{})
 }
+
+// ListConfigmapsByLabel lists the configmaps of the namespace which match the label selector
+func (k *KubeClient) ListConfigmapsByLabel(ctx context.Context, namespace,
+	labelSelector string) ([]coreV1.ConfigMap, error) {
+	configmaps, err := k.clientSet.CoreV1().ConfigMaps(namespace).List(ctx,
+		metaV1.ListOptions{LabelSelector: labelSelector})
+	if err != nil {
+		return nil, err
+	}
+
+	return configmaps.Items, nil
+}
+
+// RecordConfigmapEvent records an event on the configmap, so that the users are able to see it by describing
+// the configmap
+func (k *KubeClient) RecordConfigmapEvent(ctx context.Context, configmap *coreV1.ConfigMap,
+	eventType, reason, message string) error {
+	k.recordEvent(configmap, eventType, reason, message)
+	return nil
+}
+
+// WatchConfigmap calls the handler when the data of the configmap is changed, until the stop channel is closed
+func (k *KubeClient) WatchConfigmap(ctx context.Context, namespace, name string,
+	handler func(configmap *coreV1.ConfigMap), stopCh <-chan struct{}) {
+	fieldSelector := fields.OneTermEqualSelector("metadata.name", name).String()
+	source := &cache.ListWatch{
+		ListFunc: func(options metaV1.ListOptions) (runtime.Object, error) {
+			options.FieldSelector = fieldSelector
+			return k.clientSet.CoreV1().ConfigMaps(namespace).List(ctx, options)
+		},
+		WatchFunc: func(options metaV1.ListOptions) (watch.Interface, error) {
+			options.FieldSelector = fieldSelector
+			return k.clientSet.CoreV1().ConfigMaps(namespace).Watch(ctx, options)
+		},
+	}
+
+	informer := cache.NewSharedIndexInformer(source, &coreV1.ConfigMap{}, cacheSyncPeriod, cache.Indexers{})
+	_, err := informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			oldConfigmap, ok := oldObj.(*coreV1.ConfigMap)
+			if !ok {
+				log.AddContext(ctx).Errorf("K8S helper expected ConfigMap; got %v", oldObj)
+				return
+			}
+
+			newConfigmap, ok := newObj.(*coreV1.ConfigMap)
+			if !ok {
+				log.AddContext(ctx).Errorf("K8S helper expected ConfigMap; got %v", newObj)
+				return
+			}
+
+			// the periodic resync also triggers the update event, skip it if nothing is changed
+			if reflect.DeepEqual(oldConfigmap.Data, newConfigmap.Data) {
+				return
+			}
+			handler(newConfigmap)
+		},
+	})
+	if err != nil {
+		log.AddContext(ctx).Errorf("Add configmap event handler failed, error %v", err)
+		return
+	}
+
+	informer.Run(stopCh)
+}
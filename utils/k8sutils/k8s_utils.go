@@ -23,14 +23,17 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+	"sync"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/record"
 
 	"huawei-csi-driver/utils/log"
 )
@@ -40,7 +43,9 @@ const (
 	TopologyPrefix = "topology.kubernetes.io"
 	// ProtocolTopologyPrefix supported by CSI plugin
 	ProtocolTopologyPrefix = TopologyPrefix + "/protocol."
-	topologyRegx           = TopologyPrefix + "/.*"
+	// BackendTopologyPrefix is the prefix of the labels of the nodes which are allowed to use a backend
+	BackendTopologyPrefix = TopologyPrefix + "/backend."
+	topologyRegx          = TopologyPrefix + "/.*"
 	// Interval (in miliseconds) between pod get retry with k8s
 	podRetryInterval = 10
 )
@@ -73,11 +78,17 @@ type Interface interface {
 	secretOps
 	ConfigmapOps
 	persistentVolumeClaimOps
+	NodeOps
+	PersistentVolumeOps
+	VolumeSnapshotOps
+	AuthorizationOps
+	StorageCapacityOps
 }
 
 // KubeClient provides a wrapper for kubernetes client interface.
 type KubeClient struct {
-	clientSet kubernetes.Interface
+	clientSet     kubernetes.Interface
+	dynamicClient dynamic.Interface
 
 	// pvc resources cache
 	pvcIndexer            cache.Indexer
@@ -85,6 +96,14 @@ type KubeClient struct {
 	pvcControllerStopChan chan struct{}
 	pvcSource             cache.ListerWatcher
 
+	// pv resources cache indexed by the volume handles, started by the first lookup
+	pvInformer     cache.SharedIndexInformer
+	pvInformerOnce sync.Once
+
+	// event recorder which aggregates the repeated events, started by the first event
+	recorder     record.EventRecorder
+	recorderOnce sync.Once
+
 	volumeNamePrefix string
 	volumeLabels     map[string]string
 }
@@ -114,8 +133,14 @@ func NewK8SUtils(kubeConfig string, volumeNamePrefix string, volumeLabels map[st
 		return nil, err
 	}
 
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
 	helper := &KubeClient{
 		clientSet:             clientset,
+		dynamicClient:         dynamicClient,
 		pvcControllerStopChan: make(chan struct{}),
 		volumeNamePrefix:      volumeNamePrefix,
 		volumeLabels:          volumeLabels,
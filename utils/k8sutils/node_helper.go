@@ -0,0 +1,300 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+// Package k8sutils provides Kubernetes utilities
+package k8sutils
+
+import (
+	"context"
+	"encoding/json"
+
+	coreV1 "k8s.io/api/core/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+
+	"huawei-csi-driver/utils/log"
+)
+
+// NodeOps defines interfaces required by node
+type NodeOps interface {
+	// GetNodeLabels gets the labels of the node given its name
+	GetNodeLabels(ctx context.Context, nodeName string) (map[string]string, error)
+	// UpdateNodeLabels merges the given labels into the node and removes the labels of the removed keys
+	UpdateNodeLabels(ctx context.Context, nodeName string, labels map[string]string, removed []string) error
+	// WatchNodes calls the handler when a node is added or updated and on each resync, until the stop channel
+	// is closed
+	WatchNodes(ctx context.Context, handler func(node *coreV1.Node), stopCh <-chan struct{})
+	// ListNodes lists all the nodes of the cluster
+	ListNodes(ctx context.Context) ([]coreV1.Node, error)
+	// GetVolumeAttachedNodes gets the names of nodes which the volume is attached to
+	GetVolumeAttachedNodes(ctx context.Context, driverName, volumeHandle string) ([]string, error)
+	// GetVolumeAttachingNodes gets the names of nodes which the volume is attached or being attached to
+	GetVolumeAttachingNodes(ctx context.Context, driverName, volumeHandle string) ([]string, error)
+	// GetVolumeAttachmentMetadata gets the metadata recorded when the volume is attached to the node
+	GetVolumeAttachmentMetadata(ctx context.Context, driverName, volumeHandle, nodeName string) (
+		map[string]string, error)
+	// GetNodeAttachedVolumes gets the handles of volumes which are attached to the node
+	GetNodeAttachedVolumes(ctx context.Context, driverName, nodeName string) ([]string, error)
+	// WatchNodeDeletion calls the handler when a node is deleted, until the stop channel is closed
+	WatchNodeDeletion(ctx context.Context, handler func(node *coreV1.Node), stopCh <-chan struct{})
+	// RecordNodeEvent records an event on the node, so that the users are able to see it by describing the node
+	RecordNodeEvent(ctx context.Context, nodeName, eventType, reason, message string) error
+}
+
+// GetNodeLabels gets the labels of the node given its name
+func (k *KubeClient) GetNodeLabels(ctx context.Context, nodeName string) (map[string]string, error) {
+	node, err := k.getNode(ctx, nodeName)
+	if err != nil {
+		return nil, err
+	}
+
+	return node.Labels, nil
+}
+
+// UpdateNodeLabels merges the given labels into the node and removes the labels of the removed keys
+func (k *KubeClient) UpdateNodeLabels(ctx context.Context, nodeName string, labels map[string]string,
+	removed []string) error {
+	patchLabels := make(map[string]interface{}, len(labels)+len(removed))
+	for key, value := range labels {
+		patchLabels[key] = value
+	}
+	for _, key := range removed {
+		// a null value removes the label in the merge patch
+		patchLabels[key] = nil
+	}
+
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"labels": patchLabels,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = k.clientSet.CoreV1().Nodes().Patch(ctx, nodeName, types.MergePatchType, patch, metaV1.PatchOptions{})
+	return err
+}
+
+// RecordNodeEvent records an event on the node, so that the users are able to see it by describing the node
+func (k *KubeClient) RecordNodeEvent(ctx context.Context, nodeName, eventType, reason, message string) error {
+	node, err := k.getNode(ctx, nodeName)
+	if err != nil {
+		return err
+	}
+
+	k.recordEvent(node, eventType, reason, message)
+	return nil
+}
+
+// GetVolumeAttachedNodes gets the names of nodes which the volume is attached to
+func (k *KubeClient) GetVolumeAttachedNodes(ctx context.Context, driverName, volumeHandle string) ([]string, error) {
+	return k.getVolumeAttachmentNodes(ctx, driverName, volumeHandle, false)
+}
+
+// GetVolumeAttachingNodes gets the names of nodes which the volume is attached or being attached to, the volume
+// attachment exists before the volume is published, so the volume which is being published is also counted
+func (k *KubeClient) GetVolumeAttachingNodes(ctx context.Context, driverName, volumeHandle string) ([]string, error) {
+	return k.getVolumeAttachmentNodes(ctx, driverName, volumeHandle, true)
+}
+
+func (k *KubeClient) getVolumeAttachmentNodes(ctx context.Context, driverName, volumeHandle string,
+	includeAttaching bool) ([]string, error) {
+	pvs, err := k.ListDriverPersistentVolumes(ctx, driverName)
+	if err != nil {
+		return nil, err
+	}
+
+	pvNames := make(map[string]bool)
+	for _, pv := range pvs {
+		if pv.Spec.CSI.VolumeHandle == volumeHandle {
+			pvNames[pv.Name] = true
+		}
+	}
+	if len(pvNames) == 0 {
+		return nil, nil
+	}
+
+	attachments, err := k.clientSet.StorageV1().VolumeAttachments().List(ctx, metaV1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	var nodes []string
+	for _, attachment := range attachments.Items {
+		pvName := attachment.Spec.Source.PersistentVolumeName
+		if attachment.Spec.Attacher != driverName || pvName == nil ||
+			(!attachment.Status.Attached && !includeAttaching) {
+			continue
+		}
+
+		if pvNames[*pvName] {
+			nodes = append(nodes, attachment.Spec.NodeName)
+		}
+	}
+
+	return nodes, nil
+}
+
+// GetVolumeAttachmentMetadata gets the metadata which the attacher records in the volume attachment from the
+// publish context when the volume is attached to the node, nil is returned if the volume is not attached to it
+func (k *KubeClient) GetVolumeAttachmentMetadata(ctx context.Context, driverName, volumeHandle,
+	nodeName string) (map[string]string, error) {
+	pvs, err := k.ListDriverPersistentVolumes(ctx, driverName)
+	if err != nil {
+		return nil, err
+	}
+
+	pvNames := make(map[string]bool)
+	for _, pv := range pvs {
+		if pv.Spec.CSI.VolumeHandle == volumeHandle {
+			pvNames[pv.Name] = true
+		}
+	}
+	if len(pvNames) == 0 {
+		return nil, nil
+	}
+
+	attachments, err := k.clientSet.StorageV1().VolumeAttachments().List(ctx, metaV1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, attachment := range attachments.Items {
+		pvName := attachment.Spec.Source.PersistentVolumeName
+		if attachment.Spec.Attacher == driverName && attachment.Spec.NodeName == nodeName && pvName != nil &&
+			pvNames[*pvName] {
+			return attachment.Status.AttachmentMetadata, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// GetNodeAttachedVolumes gets the handles of volumes which are attached to the node
+func (k *KubeClient) GetNodeAttachedVolumes(ctx context.Context, driverName, nodeName string) ([]string, error) {
+	attachments, err := k.clientSet.StorageV1().VolumeAttachments().List(ctx, metaV1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	pvNames := make(map[string]bool)
+	for _, attachment := range attachments.Items {
+		pvName := attachment.Spec.Source.PersistentVolumeName
+		if attachment.Spec.Attacher == driverName && attachment.Spec.NodeName == nodeName && pvName != nil {
+			pvNames[*pvName] = true
+		}
+	}
+	if len(pvNames) == 0 {
+		return nil, nil
+	}
+
+	pvs, err := k.ListDriverPersistentVolumes(ctx, driverName)
+	if err != nil {
+		return nil, err
+	}
+
+	var volumes []string
+	for _, pv := range pvs {
+		if pvNames[pv.Name] {
+			volumes = append(volumes, pv.Spec.CSI.VolumeHandle)
+		}
+	}
+
+	return volumes, nil
+}
+
+// WatchNodeDeletion calls the handler when a node is deleted, until the stop channel is closed
+func (k *KubeClient) WatchNodeDeletion(ctx context.Context, handler func(node *coreV1.Node),
+	stopCh <-chan struct{}) {
+	source := &cache.ListWatch{
+		ListFunc: func(options metaV1.ListOptions) (runtime.Object, error) {
+			return k.clientSet.CoreV1().Nodes().List(ctx, options)
+		},
+		WatchFunc: func(options metaV1.ListOptions) (watch.Interface, error) {
+			return k.clientSet.CoreV1().Nodes().Watch(ctx, options)
+		},
+	}
+
+	informer := cache.NewSharedIndexInformer(source, &coreV1.Node{}, cacheSyncPeriod, cache.Indexers{})
+	_, err := informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+
+			node, ok := obj.(*coreV1.Node)
+			if !ok {
+				log.AddContext(ctx).Errorf("K8S helper expected Node; got %v", obj)
+				return
+			}
+			handler(node)
+		},
+	})
+	if err != nil {
+		log.AddContext(ctx).Errorf("Add node event handler failed, error %v", err)
+		return
+	}
+
+	informer.Run(stopCh)
+}
+
+// ListNodes lists all the nodes of the cluster
+func (k *KubeClient) ListNodes(ctx context.Context) ([]coreV1.Node, error) {
+	nodes, err := k.clientSet.CoreV1().Nodes().List(ctx, metaV1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return nodes.Items, nil
+}
+
+// WatchNodes calls the handler when a node is added or updated and on each resync, until the stop channel is closed
+func (k *KubeClient) WatchNodes(ctx context.Context, handler func(node *coreV1.Node), stopCh <-chan struct{}) {
+	source := &cache.ListWatch{
+		ListFunc: func(options metaV1.ListOptions) (runtime.Object, error) {
+			return k.clientSet.CoreV1().Nodes().List(ctx, options)
+		},
+		WatchFunc: func(options metaV1.ListOptions) (watch.Interface, error) {
+			return k.clientSet.CoreV1().Nodes().Watch(ctx, options)
+		},
+	}
+
+	handleNode := func(obj interface{}) {
+		node, ok := obj.(*coreV1.Node)
+		if !ok {
+			log.AddContext(ctx).Errorf("K8S helper expected Node; got %v", obj)
+			return
+		}
+		handler(node)
+	}
+
+	informer := cache.NewSharedIndexInformer(source, &coreV1.Node{}, cacheSyncPeriod, cache.Indexers{})
+	_, err := informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: handleNode,
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			handleNode(newObj)
+		},
+	})
+	if err != nil {
+		log.AddContext(ctx).Errorf("Add node event handler failed, error %v", err)
+		return
+	}
+
+	informer.Run(stopCh)
+}
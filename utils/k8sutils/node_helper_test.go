@@ -0,0 +1,126 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package k8sutils
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	coreV1 "k8s.io/api/core/v1"
+	storageV1 "k8s.io/api/storage/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+const testDriverName = "csi.huawei.com"
+
+func newTestPV(name, handle string) *coreV1.PersistentVolume {
+	return &coreV1.PersistentVolume{
+		ObjectMeta: metaV1.ObjectMeta{Name: name},
+		Spec: coreV1.PersistentVolumeSpec{PersistentVolumeSource: coreV1.PersistentVolumeSource{
+			CSI: &coreV1.CSIPersistentVolumeSource{Driver: testDriverName, VolumeHandle: handle},
+		}},
+	}
+}
+
+func newTestAttachment(name, pvName, nodeName string, attached bool) *storageV1.VolumeAttachment {
+	return &storageV1.VolumeAttachment{
+		ObjectMeta: metaV1.ObjectMeta{Name: name},
+		Spec: storageV1.VolumeAttachmentSpec{
+			Attacher: testDriverName,
+			NodeName: nodeName,
+			Source:   storageV1.VolumeAttachmentSource{PersistentVolumeName: &pvName},
+		},
+		Status: storageV1.VolumeAttachmentStatus{Attached: attached},
+	}
+}
+
+func TestGetVolumeAttachedNodes(t *testing.T) {
+	clientSet := fake.NewSimpleClientset(
+		newTestPV("pv-1", "backend.pvc_1"),
+		newTestPV("pv-2", "backend.pvc_2"),
+		newTestAttachment("va-1", "pv-1", "node-1", true),
+		newTestAttachment("va-2", "pv-1", "node-2", false),
+		newTestAttachment("va-3", "pv-2", "node-3", true),
+	)
+
+	var pvGets int
+	clientSet.PrependReactor("get", "persistentvolumes",
+		func(k8stesting.Action) (bool, runtime.Object, error) {
+			pvGets++
+			return false, nil, nil
+		})
+
+	k := &KubeClient{clientSet: clientSet}
+	nodes, err := k.GetVolumeAttachedNodes(context.Background(), testDriverName, "backend.pvc_1")
+	if err != nil {
+		t.Fatalf("GetVolumeAttachedNodes() error = %v", err)
+	}
+
+	if !reflect.DeepEqual(nodes, []string{"node-1"}) {
+		t.Errorf("GetVolumeAttachedNodes() = %v, want [node-1]", nodes)
+	}
+	if pvGets != 0 {
+		t.Errorf("GetVolumeAttachedNodes() gets the persistent volumes %d times, want listing them once", pvGets)
+	}
+}
+
+func TestGetNodeAttachedVolumes(t *testing.T) {
+	clientSet := fake.NewSimpleClientset(
+		newTestPV("pv-1", "backend.pvc_1"),
+		newTestPV("pv-2", "backend.pvc_2"),
+		newTestAttachment("va-1", "pv-1", "node-1", true),
+		newTestAttachment("va-2", "pv-2", "node-2", true),
+	)
+
+	k := &KubeClient{clientSet: clientSet}
+	volumes, err := k.GetNodeAttachedVolumes(context.Background(), testDriverName, "node-2")
+	if err != nil {
+		t.Fatalf("GetNodeAttachedVolumes() error = %v", err)
+	}
+
+	if !reflect.DeepEqual(volumes, []string{"backend.pvc_2"}) {
+		t.Errorf("GetNodeAttachedVolumes() = %v, want [backend.pvc_2]", volumes)
+	}
+}
+
+func TestGetVolumeAttachmentMetadata(t *testing.T) {
+	attachment := newTestAttachment("va-1", "pv-1", "node-1", true)
+	attachment.Status.AttachmentMetadata = map[string]string{"publishInfo": `{"hostID":"1"}`}
+	clientSet := fake.NewSimpleClientset(
+		newTestPV("pv-1", "backend.pvc_1"),
+		attachment,
+		newTestAttachment("va-2", "pv-1", "node-2", true),
+	)
+
+	k := &KubeClient{clientSet: clientSet}
+	metadata, err := k.GetVolumeAttachmentMetadata(context.Background(), testDriverName, "backend.pvc_1", "node-1")
+	if err != nil {
+		t.Fatalf("GetVolumeAttachmentMetadata() error = %v", err)
+	}
+	if !reflect.DeepEqual(metadata, attachment.Status.AttachmentMetadata) {
+		t.Errorf("GetVolumeAttachmentMetadata() = %v, want %v", metadata, attachment.Status.AttachmentMetadata)
+	}
+
+	metadata, err = k.GetVolumeAttachmentMetadata(context.Background(), testDriverName, "backend.pvc_1", "node-3")
+	if err != nil || metadata != nil {
+		t.Errorf("GetVolumeAttachmentMetadata() of the node not attached = %v, error = %v, want nil", metadata, err)
+	}
+}
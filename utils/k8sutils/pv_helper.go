@@ -0,0 +1,279 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+// Package k8sutils provides Kubernetes utilities
+package k8sutils
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	coreV1 "k8s.io/api/core/v1"
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+
+	"huawei-csi-driver/utils/log"
+)
+
+const volumeHandleIndex = "volumeHandle"
+
+// PersistentVolumeOps defines interfaces required by persistent volume
+type PersistentVolumeOps interface {
+	// UpdatePVAnnotations merges the given annotations into the persistent volume
+	UpdatePVAnnotations(ctx context.Context, pvName string, annotations map[string]string) error
+	// RemovePVAnnotations removes the annotations of the given keys from the persistent volume
+	RemovePVAnnotations(ctx context.Context, pvName string, keys []string) error
+	// GetPVByVolumeHandle gets the persistent volume of the CSI volume from the cache of the persistent volumes,
+	// nil is returned if no persistent volume has the volume handle
+	GetPVByVolumeHandle(ctx context.Context, driverName, volumeHandle string) (*coreV1.PersistentVolume, error)
+	// WatchPersistentVolumes calls the handler when a persistent volume is added or updated,
+	// until the stop channel is closed
+	WatchPersistentVolumes(ctx context.Context, handler func(pv *coreV1.PersistentVolume), stopCh <-chan struct{})
+	// RecordPVEvent records an event on the persistent volume
+	RecordPVEvent(ctx context.Context, pvName, eventType, reason, message string) error
+	// GetVolumeStorageClassParameters gets the parameters of the StorageClass which the volume is provisioned by,
+	// the nil parameters are returned if the volume is not provisioned by any StorageClass
+	GetVolumeStorageClassParameters(ctx context.Context, driverName, volumeHandle string) (map[string]string, error)
+	// ReplacePersistentVolume replaces the persistent volume of the same name with the given one,
+	// false is returned if the old persistent volume is still being deleted
+	ReplacePersistentVolume(ctx context.Context, pv *coreV1.PersistentVolume) (bool, error)
+	// ListDriverPersistentVolumes lists the persistent volumes provisioned by the given CSI driver
+	ListDriverPersistentVolumes(ctx context.Context, driverName string) ([]coreV1.PersistentVolume, error)
+}
+
+// UpdatePVAnnotations merges the given annotations into the persistent volume
+func (k *KubeClient) UpdatePVAnnotations(ctx context.Context, pvName string, annotations map[string]string) error {
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": annotations,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = k.clientSet.CoreV1().PersistentVolumes().Patch(ctx, pvName, types.MergePatchType, patch,
+		metaV1.PatchOptions{})
+	return err
+}
+
+// RemovePVAnnotations removes the annotations of the given keys from the persistent volume
+func (k *KubeClient) RemovePVAnnotations(ctx context.Context, pvName string, keys []string) error {
+	annotations := make(map[string]interface{}, len(keys))
+	for _, key := range keys {
+		annotations[key] = nil
+	}
+
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": annotations,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = k.clientSet.CoreV1().PersistentVolumes().Patch(ctx, pvName, types.MergePatchType, patch,
+		metaV1.PatchOptions{})
+	return err
+}
+
+// RecordPVEvent records an event on the persistent volume, so that the users are able to see it by describing the PV
+func (k *KubeClient) RecordPVEvent(ctx context.Context, pvName, eventType, reason, message string) error {
+	pv, err := k.clientSet.CoreV1().PersistentVolumes().Get(ctx, pvName, metaV1.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	k.recordEvent(pv, eventType, reason, message)
+	return nil
+}
+
+// GetVolumeStorageClassParameters gets the parameters of the StorageClass which the volume is provisioned by,
+// the nil parameters are returned if the volume is not provisioned by any StorageClass
+func (k *KubeClient) GetVolumeStorageClassParameters(ctx context.Context, driverName, volumeHandle string) (
+	map[string]string, error) {
+	pv, err := k.GetPVByVolumeHandle(ctx, driverName, volumeHandle)
+	if err != nil || pv == nil || pv.Spec.StorageClassName == "" {
+		return nil, err
+	}
+
+	storageClass, err := k.clientSet.StorageV1().StorageClasses().Get(ctx, pv.Spec.StorageClassName,
+		metaV1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return storageClass.Parameters, nil
+}
+
+// GetPVByVolumeHandle gets the persistent volume of the CSI volume from the cache of the persistent volumes,
+// the cache is started by the first lookup, nil is returned if no persistent volume has the volume handle
+func (k *KubeClient) GetPVByVolumeHandle(ctx context.Context, driverName, volumeHandle string) (
+	*coreV1.PersistentVolume, error) {
+	k.pvInformerOnce.Do(func() {
+		source := &cache.ListWatch{
+			ListFunc: func(options metaV1.ListOptions) (runtime.Object, error) {
+				return k.clientSet.CoreV1().PersistentVolumes().List(context.Background(), options)
+			},
+			WatchFunc: func(options metaV1.ListOptions) (watch.Interface, error) {
+				return k.clientSet.CoreV1().PersistentVolumes().Watch(context.Background(), options)
+			},
+		}
+		k.pvInformer = cache.NewSharedIndexInformer(source, &coreV1.PersistentVolume{}, cacheSyncPeriod,
+			cache.Indexers{volumeHandleIndex: volumeHandleKeyFunc})
+		go k.pvInformer.Run(k.pvcControllerStopChan)
+	})
+
+	if !cache.WaitForCacheSync(ctx.Done(), k.pvInformer.HasSynced) {
+		return nil, fmt.Errorf("wait for the cache of persistent volumes to sync failed, error: %v", ctx.Err())
+	}
+
+	objs, err := k.pvInformer.GetIndexer().ByIndex(volumeHandleIndex, driverName+"/"+volumeHandle)
+	if err != nil || len(objs) == 0 {
+		return nil, err
+	}
+
+	pv, ok := objs[0].(*coreV1.PersistentVolume)
+	if !ok {
+		return nil, fmt.Errorf("K8S helper expected PersistentVolume; got %v", objs[0])
+	}
+	return pv, nil
+}
+
+// volumeHandleKeyFunc indexes the persistent volumes of the CSI volumes by their drivers and volume handles
+func volumeHandleKeyFunc(obj interface{}) ([]string, error) {
+	pv, ok := obj.(*coreV1.PersistentVolume)
+	if !ok || pv.Spec.CSI == nil {
+		return nil, nil
+	}
+	return []string{pv.Spec.CSI.Driver + "/" + pv.Spec.CSI.VolumeHandle}, nil
+}
+
+// ListDriverPersistentVolumes lists the persistent volumes provisioned by the given CSI driver
+func (k *KubeClient) ListDriverPersistentVolumes(ctx context.Context, driverName string) (
+	[]coreV1.PersistentVolume, error) {
+	pvs, err := k.clientSet.CoreV1().PersistentVolumes().List(ctx, metaV1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	var driverPVs []coreV1.PersistentVolume
+	for _, pv := range pvs.Items {
+		if pv.Spec.CSI != nil && pv.Spec.CSI.Driver == driverName {
+			driverPVs = append(driverPVs, pv)
+		}
+	}
+	return driverPVs, nil
+}
+
+// WatchPersistentVolumes calls the handler when a persistent volume is added or updated,
+// until the stop channel is closed
+func (k *KubeClient) WatchPersistentVolumes(ctx context.Context, handler func(pv *coreV1.PersistentVolume),
+	stopCh <-chan struct{}) {
+	source := &cache.ListWatch{
+		ListFunc: func(options metaV1.ListOptions) (runtime.Object, error) {
+			return k.clientSet.CoreV1().PersistentVolumes().List(ctx, options)
+		},
+		WatchFunc: func(options metaV1.ListOptions) (watch.Interface, error) {
+			return k.clientSet.CoreV1().PersistentVolumes().Watch(ctx, options)
+		},
+	}
+
+	handle := func(obj interface{}) {
+		pv, ok := obj.(*coreV1.PersistentVolume)
+		if !ok {
+			log.AddContext(ctx).Errorf("K8S helper expected PersistentVolume; got %v", obj)
+			return
+		}
+		handler(pv)
+	}
+
+	informer := cache.NewSharedIndexInformer(source, &coreV1.PersistentVolume{}, cacheSyncPeriod, cache.Indexers{})
+	_, err := informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: handle,
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			handle(newObj)
+		},
+	})
+	if err != nil {
+		log.AddContext(ctx).Errorf("Add persistent volume event handler failed, error %v", err)
+		return
+	}
+
+	informer.Run(stopCh)
+}
+
+// ReplacePersistentVolume replaces the persistent volume of the same name with the given one. The old persistent
+// volume is retained so that its volume is not deleted, and the given one keeps the claim reference so that the
+// claim is bound to it again, false is returned if the old persistent volume is still being deleted
+func (k *KubeClient) ReplacePersistentVolume(ctx context.Context, pv *coreV1.PersistentVolume) (bool, error) {
+	old, err := k.clientSet.CoreV1().PersistentVolumes().Get(ctx, pv.Name, metaV1.GetOptions{})
+	if apiErrors.IsNotFound(err) {
+		created := pv.DeepCopy()
+		created.ResourceVersion = ""
+		created.UID = ""
+		created.Status = coreV1.PersistentVolumeStatus{}
+		_, err = k.clientSet.CoreV1().PersistentVolumes().Create(ctx, created, metaV1.CreateOptions{})
+		if apiErrors.IsAlreadyExists(err) {
+			return false, nil
+		}
+		return err == nil, err
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if old.Spec.CSI != nil && pv.Spec.CSI != nil && old.Spec.CSI.VolumeHandle == pv.Spec.CSI.VolumeHandle {
+		return true, nil
+	}
+
+	if old.Spec.PersistentVolumeReclaimPolicy != coreV1.PersistentVolumeReclaimRetain {
+		old.Spec.PersistentVolumeReclaimPolicy = coreV1.PersistentVolumeReclaimRetain
+		old, err = k.clientSet.CoreV1().PersistentVolumes().Update(ctx, old, metaV1.UpdateOptions{})
+		if err != nil {
+			return false, err
+		}
+	}
+
+	if old.DeletionTimestamp == nil {
+		err = k.clientSet.CoreV1().PersistentVolumes().Delete(ctx, old.Name, metaV1.DeleteOptions{})
+		if err != nil && !apiErrors.IsNotFound(err) {
+			return false, err
+		}
+	}
+
+	// the protection finalizer blocks the deletion while the claim is bound to the persistent volume
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"finalizers": nil,
+		},
+	})
+	if err != nil {
+		return false, err
+	}
+	_, err = k.clientSet.CoreV1().PersistentVolumes().Patch(ctx, old.Name, types.MergePatchType, patch,
+		metaV1.PatchOptions{})
+	if err != nil && !apiErrors.IsNotFound(err) {
+		return false, err
+	}
+
+	return false, nil
+}
@@ -0,0 +1,69 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package k8sutils
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	storageV1 "k8s.io/api/storage/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestGetVolumeStorageClassParameters(t *testing.T) {
+	pv := &v1.PersistentVolume{
+		ObjectMeta: metaV1.ObjectMeta{Name: "pvc-1"},
+		Spec: v1.PersistentVolumeSpec{
+			StorageClassName: "sc-1",
+			PersistentVolumeSource: v1.PersistentVolumeSource{
+				CSI: &v1.CSIPersistentVolumeSource{Driver: "csi.huawei.com", VolumeHandle: "backend.pvc-1"},
+			},
+		},
+	}
+	storageClass := &storageV1.StorageClass{
+		ObjectMeta: metaV1.ObjectMeta{Name: "sc-1"},
+		Parameters: map[string]string{"maxSize": "10Gi"},
+	}
+	helper := &KubeClient{
+		clientSet:             fake.NewSimpleClientset(pv, storageClass),
+		pvcControllerStopChan: make(chan struct{}),
+	}
+	defer close(helper.pvcControllerStopChan)
+
+	tests := []struct {
+		name         string
+		driverName   string
+		volumeHandle string
+		want         map[string]string
+	}{
+		{"Found", "csi.huawei.com", "backend.pvc-1", storageClass.Parameters},
+		{"OtherDriver", "other.csi.com", "backend.pvc-1", nil},
+		{"NotFound", "csi.huawei.com", "backend.pvc-2", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := helper.GetVolumeStorageClassParameters(context.TODO(), tt.driverName, tt.volumeHandle)
+			if err != nil || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetVolumeStorageClassParameters() = %v, %v, want %v", got, err, tt.want)
+			}
+		})
+	}
+}
@@ -29,7 +29,10 @@ import (
 	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes/scheme"
+	typedCoreV1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
 
 	"huawei-csi-driver/utils/log"
 )
@@ -40,6 +43,8 @@ const (
 	eventAdd    = "add"
 	eventUpdate = "update"
 	eventDelete = "delete"
+
+	eventSourceComponent = "huawei-csi-driver"
 )
 
 var (
@@ -51,6 +56,18 @@ var (
 type persistentVolumeClaimOps interface {
 	// GetVolumeConfiguration returns PVC's volume info
 	GetVolumeConfiguration(ctx context.Context, pvName string) (map[string]string, error)
+	// RecordPVCEvent records an event on the PVC which the volume is provisioned for
+	RecordPVCEvent(ctx context.Context, pvName, eventType, reason, message string) error
+	// GetVolumeClaimCreationTime returns the creation time of the PVC which the volume is provisioned for
+	GetVolumeClaimCreationTime(ctx context.Context, pvName string) (time.Time, error)
+	// GetVolumeClaimDataSourceRef returns the namespace and the data source ref of the PVC
+	// which the volume is provisioned for
+	GetVolumeClaimDataSourceRef(ctx context.Context, pvName string) (string, *v1.TypedObjectReference, error)
+	// GetVolumeClaimNamespace returns the namespace of the PVC which the volume is provisioned for
+	GetVolumeClaimNamespace(ctx context.Context, pvName string) (string, error)
+	// GetPodByPVCName returns a pod which uses the PVC the volume is provisioned for,
+	// nil is returned if no pod uses the PVC yet
+	GetPodByPVCName(ctx context.Context, pvName string) (*v1.Pod, error)
 }
 
 func initPVCWatcher(ctx context.Context, helper *KubeClient) {
@@ -157,6 +174,86 @@ func (k *KubeClient) GetVolumeConfiguration(ctx context.Context, pvName string)
 	return pvc.Annotations, nil
 }
 
+// RecordPVCEvent records an event on the PVC, so that the users are able to see it by describing the PVC
+func (k *KubeClient) RecordPVCEvent(ctx context.Context, pvName, eventType, reason, message string) error {
+	pvc, err := k.getPVC(ctx, pvName)
+	if err != nil {
+		return err
+	}
+
+	k.recordEvent(pvc, eventType, reason, message)
+	return nil
+}
+
+// recordEvent records the event by the event recorder, which aggregates the repeated events and limits the rate
+// of them, the events of the cluster scoped objects are recorded in the default namespace
+func (k *KubeClient) recordEvent(object runtime.Object, eventType, reason, message string) {
+	k.recorderOnce.Do(func() {
+		eventBroadcaster := record.NewBroadcaster()
+		eventBroadcaster.StartRecordingToSink(&typedCoreV1.EventSinkImpl{
+			Interface: k.clientSet.CoreV1().Events(metaV1.NamespaceAll)})
+		k.recorder = eventBroadcaster.NewRecorder(scheme.Scheme, v1.EventSource{Component: eventSourceComponent})
+	})
+
+	k.recorder.Event(object, eventType, reason, message)
+}
+
+// GetVolumeClaimCreationTime returns the creation time of the PVC which the volume is provisioned for
+func (k *KubeClient) GetVolumeClaimCreationTime(ctx context.Context, pvName string) (time.Time, error) {
+	pvc, err := k.getPVC(ctx, pvName)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return pvc.CreationTimestamp.Time, nil
+}
+
+// GetVolumeClaimDataSourceRef returns the namespace and the data source ref of the PVC
+// which the volume is provisioned for
+func (k *KubeClient) GetVolumeClaimDataSourceRef(ctx context.Context,
+	pvName string) (string, *v1.TypedObjectReference, error) {
+	pvc, err := k.getPVC(ctx, pvName)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return pvc.Namespace, pvc.Spec.DataSourceRef, nil
+}
+
+// GetVolumeClaimNamespace returns the namespace of the PVC which the volume is provisioned for
+func (k *KubeClient) GetVolumeClaimNamespace(ctx context.Context, pvName string) (string, error) {
+	pvc, err := k.getPVC(ctx, pvName)
+	if err != nil {
+		return "", err
+	}
+
+	return pvc.Namespace, nil
+}
+
+// GetPodByPVCName returns a pod which uses the PVC the volume is provisioned for,
+// nil is returned if no pod uses the PVC yet
+func (k *KubeClient) GetPodByPVCName(ctx context.Context, pvName string) (*v1.Pod, error) {
+	pvc, err := k.getPVC(ctx, pvName)
+	if err != nil {
+		return nil, err
+	}
+
+	pods, err := k.clientSet.CoreV1().Pods(pvc.Namespace).List(ctx, metaV1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	for i, pod := range pods.Items {
+		for _, volume := range pod.Spec.Volumes {
+			if volume.PersistentVolumeClaim != nil && volume.PersistentVolumeClaim.ClaimName == pvc.Name {
+				return &pods.Items[i], nil
+			}
+		}
+	}
+
+	return nil, nil
+}
+
 func (k *KubeClient) getPVC(ctx context.Context, pvName string) (*v1.PersistentVolumeClaim, error) {
 	pvcUID := strings.TrimPrefix(pvName, fmt.Sprintf("%s-", k.volumeNamePrefix))
 	pvc, err := k.getCachedPVCByUID(pvcUID)
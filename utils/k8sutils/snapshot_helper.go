@@ -0,0 +1,139 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package k8sutils
+
+import (
+	"context"
+	"fmt"
+
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	// VolumeSnapshotGroup is the api group of VolumeSnapshot
+	VolumeSnapshotGroup = "snapshot.storage.k8s.io"
+	// VolumeSnapshotKind is the kind of VolumeSnapshot
+	VolumeSnapshotKind = "VolumeSnapshot"
+
+	persistentVolumeClaimKind = "PersistentVolumeClaim"
+)
+
+var (
+	volumeSnapshotResource = schema.GroupVersionResource{
+		Group:    VolumeSnapshotGroup,
+		Version:  "v1",
+		Resource: "volumesnapshots",
+	}
+	volumeSnapshotContentResource = schema.GroupVersionResource{
+		Group:    VolumeSnapshotGroup,
+		Version:  "v1",
+		Resource: "volumesnapshotcontents",
+	}
+	referenceGrantResource = schema.GroupVersionResource{
+		Group:    "gateway.networking.k8s.io",
+		Version:  "v1beta1",
+		Resource: "referencegrants",
+	}
+)
+
+// VolumeSnapshotOps defines interfaces required by volume snapshots
+type VolumeSnapshotOps interface {
+	// GetVolumeSnapshotHandle returns the snapshot handle of the VolumeSnapshot,
+	// the empty handle means that the VolumeSnapshot is not ready yet
+	GetVolumeSnapshotHandle(ctx context.Context, namespace, name string) (string, error)
+	// IsVolumeSnapshotReferenceGranted checks whether a ReferenceGrant in the namespace of the VolumeSnapshot
+	// allows the PVCs of another namespace to use it as the data source
+	IsVolumeSnapshotReferenceGranted(ctx context.Context, pvcNamespace, snapshotNamespace,
+		snapshotName string) (bool, error)
+}
+
+// GetVolumeSnapshotHandle returns the snapshot handle of the VolumeSnapshot,
+// the empty handle means that the VolumeSnapshot is not ready yet
+func (k *KubeClient) GetVolumeSnapshotHandle(ctx context.Context, namespace, name string) (string, error) {
+	snapshot, err := k.dynamicClient.Resource(volumeSnapshotResource).Namespace(namespace).
+		Get(ctx, name, metaV1.GetOptions{})
+	if err != nil {
+		return "", err
+	}
+
+	contentName, _, err := unstructured.NestedString(snapshot.Object, "status", "boundVolumeSnapshotContentName")
+	if err != nil || contentName == "" {
+		return "", err
+	}
+
+	content, err := k.dynamicClient.Resource(volumeSnapshotContentResource).Get(ctx, contentName,
+		metaV1.GetOptions{})
+	if err != nil {
+		return "", err
+	}
+
+	snapshotHandle, _, err := unstructured.NestedString(content.Object, "status", "snapshotHandle")
+	return snapshotHandle, err
+}
+
+// IsVolumeSnapshotReferenceGranted checks whether a ReferenceGrant in the namespace of the VolumeSnapshot
+// allows the PVCs of another namespace to use it as the data source
+func (k *KubeClient) IsVolumeSnapshotReferenceGranted(ctx context.Context, pvcNamespace, snapshotNamespace,
+	snapshotName string) (bool, error) {
+	grants, err := k.dynamicClient.Resource(referenceGrantResource).Namespace(snapshotNamespace).
+		List(ctx, metaV1.ListOptions{})
+	if err != nil {
+		return false, fmt.Errorf("list ReferenceGrants in namespace %s failed, error: %v", snapshotNamespace, err)
+	}
+
+	for _, grant := range grants.Items {
+		if isReferenceGranted(grant.Object, pvcNamespace, snapshotName) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func isReferenceGranted(grant map[string]interface{}, pvcNamespace, snapshotName string) bool {
+	froms, _, _ := unstructured.NestedSlice(grant, "spec", "from")
+	tos, _, _ := unstructured.NestedSlice(grant, "spec", "to")
+
+	fromGranted := false
+	for _, from := range froms {
+		ref, ok := from.(map[string]interface{})
+		if ok && ref["group"] == "" && ref["kind"] == persistentVolumeClaimKind && ref["namespace"] == pvcNamespace {
+			fromGranted = true
+			break
+		}
+	}
+
+	if !fromGranted {
+		return false
+	}
+
+	for _, to := range tos {
+		ref, ok := to.(map[string]interface{})
+		if !ok || ref["group"] != VolumeSnapshotGroup || ref["kind"] != VolumeSnapshotKind {
+			continue
+		}
+
+		// the ReferenceGrant without name allows all VolumeSnapshots in the namespace
+		if name, _ := ref["name"].(string); name == "" || name == snapshotName {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,63 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package k8sutils
+
+import (
+	"testing"
+)
+
+func newReferenceGrant(fromNamespace, toName string) map[string]interface{} {
+	return map[string]interface{}{
+		"spec": map[string]interface{}{
+			"from": []interface{}{
+				map[string]interface{}{"group": "", "kind": "PersistentVolumeClaim", "namespace": fromNamespace},
+			},
+			"to": []interface{}{
+				map[string]interface{}{"group": VolumeSnapshotGroup, "kind": VolumeSnapshotKind, "name": toName},
+			},
+		},
+	}
+}
+
+func TestIsReferenceGranted(t *testing.T) {
+	tests := []struct {
+		name         string
+		grant        map[string]interface{}
+		pvcNamespace string
+		snapshotName string
+		want         bool
+	}{
+		{name: "GrantedByName", grant: newReferenceGrant("dev", "snap1"),
+			pvcNamespace: "dev", snapshotName: "snap1", want: true},
+		{name: "GrantedAllSnapshots", grant: newReferenceGrant("dev", ""),
+			pvcNamespace: "dev", snapshotName: "snap1", want: true},
+		{name: "OtherNamespace", grant: newReferenceGrant("test", "snap1"),
+			pvcNamespace: "dev", snapshotName: "snap1", want: false},
+		{name: "OtherSnapshot", grant: newReferenceGrant("dev", "snap2"),
+			pvcNamespace: "dev", snapshotName: "snap1", want: false},
+		{name: "EmptyGrant", grant: map[string]interface{}{},
+			pvcNamespace: "dev", snapshotName: "snap1", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isReferenceGranted(tt.grant, tt.pvcNamespace, tt.snapshotName); got != tt.want {
+				t.Errorf("isReferenceGranted() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package k8sutils
+
+import (
+	"context"
+
+	storageV1 "k8s.io/api/storage/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// StorageCapacityOps defines interfaces required by the CSIStorageCapacity objects
+type StorageCapacityOps interface {
+	// ListStorageClassesByProvisioner lists the StorageClasses provisioned by the given driver
+	ListStorageClassesByProvisioner(ctx context.Context, provisioner string) ([]storageV1.StorageClass, error)
+	// ListStorageCapacities lists the CSIStorageCapacity objects of the namespace which match the label selector
+	ListStorageCapacities(ctx context.Context, namespace, labelSelector string) ([]storageV1.CSIStorageCapacity,
+		error)
+	// CreateStorageCapacity creates the given CSIStorageCapacity object
+	CreateStorageCapacity(ctx context.Context, capacity *storageV1.CSIStorageCapacity) (
+		*storageV1.CSIStorageCapacity, error)
+	// UpdateStorageCapacity updates the given CSIStorageCapacity object
+	UpdateStorageCapacity(ctx context.Context, capacity *storageV1.CSIStorageCapacity) (
+		*storageV1.CSIStorageCapacity, error)
+	// DeleteStorageCapacity deletes the given CSIStorageCapacity object
+	DeleteStorageCapacity(ctx context.Context, capacity *storageV1.CSIStorageCapacity) error
+}
+
+// ListStorageClassesByProvisioner lists the StorageClasses provisioned by the given driver
+func (k *KubeClient) ListStorageClassesByProvisioner(ctx context.Context,
+	provisioner string) ([]storageV1.StorageClass, error) {
+	storageClasses, err := k.clientSet.StorageV1().StorageClasses().List(ctx, metaV1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	var result []storageV1.StorageClass
+	for _, storageClass := range storageClasses.Items {
+		if storageClass.Provisioner == provisioner {
+			result = append(result, storageClass)
+		}
+	}
+	return result, nil
+}
+
+// ListStorageCapacities lists the CSIStorageCapacity objects of the namespace which match the label selector
+func (k *KubeClient) ListStorageCapacities(ctx context.Context, namespace,
+	labelSelector string) ([]storageV1.CSIStorageCapacity, error) {
+	capacities, err := k.clientSet.StorageV1().CSIStorageCapacities(namespace).List(ctx,
+		metaV1.ListOptions{LabelSelector: labelSelector})
+	if err != nil {
+		return nil, err
+	}
+
+	return capacities.Items, nil
+}
+
+// CreateStorageCapacity creates the given CSIStorageCapacity object
+func (k *KubeClient) CreateStorageCapacity(ctx context.Context,
+	capacity *storageV1.CSIStorageCapacity) (*storageV1.CSIStorageCapacity, error) {
+	return k.clientSet.StorageV1().CSIStorageCapacities(capacity.Namespace).Create(ctx, capacity,
+		metaV1.CreateOptions{})
+}
+
+// UpdateStorageCapacity updates the given CSIStorageCapacity object
+func (k *KubeClient) UpdateStorageCapacity(ctx context.Context,
+	capacity *storageV1.CSIStorageCapacity) (*storageV1.CSIStorageCapacity, error) {
+	return k.clientSet.StorageV1().CSIStorageCapacities(capacity.Namespace).Update(ctx, capacity,
+		metaV1.UpdateOptions{})
+}
+
+// DeleteStorageCapacity deletes the given CSIStorageCapacity object
+func (k *KubeClient) DeleteStorageCapacity(ctx context.Context, capacity *storageV1.CSIStorageCapacity) error {
+	return k.clientSet.StorageV1().CSIStorageCapacities(capacity.Namespace).Delete(ctx, capacity.Name,
+		metaV1.DeleteOptions{})
+}
@@ -0,0 +1,81 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+// Package metrics provides the prometheus metrics of the csi driver
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"huawei-csi-driver/utils/log"
+)
+
+const (
+	metricsNamespace  = "huawei_csi"
+	readHeaderTimeout = 10 * time.Second
+)
+
+var (
+	// SnapshotOperationQueueDepth is the number of snapshot operations waiting for the concurrency limit
+	SnapshotOperationQueueDepth = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "snapshot_operation_queue_depth",
+		Help:      "The number of snapshot operations waiting for the concurrency limit of the backend.",
+	}, []string{"backend", "operation"})
+
+	// SnapshotOperationInFlight is the number of snapshot operations running on the storage
+	SnapshotOperationInFlight = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "snapshot_operation_in_flight",
+		Help:      "The number of snapshot operations running on the storage of the backend.",
+	}, []string{"backend", "operation"})
+
+	// OrphanedMultipathMapsCleaned is the number of the orphaned DM-multipath maps cleaned up on detach
+	OrphanedMultipathMapsCleaned = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "orphaned_multipath_maps_cleaned_total",
+		Help:      "The number of the DM-multipath maps without any path cleaned up on detach.",
+	})
+)
+
+func init() {
+	prometheus.MustRegister(SnapshotOperationQueueDepth, SnapshotOperationInFlight, OrphanedMultipathMapsCleaned)
+}
+
+// Serve exposes the metrics at /metrics of the address together with the extra handlers keyed by
+// their paths, it blocks until the server fails
+func Serve(ctx context.Context, address string, handlers map[string]http.Handler) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	for path, h := range handlers {
+		mux.Handle(path, h)
+	}
+	server := &http.Server{
+		Addr:              address,
+		Handler:           mux,
+		ReadHeaderTimeout: readHeaderTimeout,
+	}
+
+	log.AddContext(ctx).Infof("Start to serve metrics on %s", address)
+	if err := server.ListenAndServe(); err != nil {
+		log.AddContext(ctx).Errorf("Serve metrics on %s failed, error: %v", address, err)
+	}
+}
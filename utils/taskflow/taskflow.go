@@ -19,6 +19,8 @@ package taskflow
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	"huawei-csi-driver/utils"
 	"huawei-csi-driver/utils/log"
@@ -73,6 +75,11 @@ func (p *TaskFlow) Run(params map[string]interface{}) (map[string]interface{}, e
 	log.AddContext(p.ctx).Debugf("Start to run taskflow %s", p.name)
 
 	for _, task := range p.tasks {
+		if err := p.ctx.Err(); err != nil {
+			log.AddContext(p.ctx).Warningf("Taskflow %s is aborted before task %s: %v", p.name, task.name, err)
+			return nil, fmt.Errorf("taskflow %s is aborted: %w", p.name, err)
+		}
+
 		result, err := task.run(p.ctx, params, p.result)
 		if err != nil {
 			log.AddContext(p.ctx).Errorf("Run task %s of taskflow %s error: %v", task.name, p.name, err)
@@ -95,22 +102,45 @@ func (p *TaskFlow) GetResult() map[string]interface{} {
 	return p.result
 }
 
-// Revert revert tasks in the task flow with revert function
+// Revert revert tasks in the task flow with revert function.
+// The revert functions still run when the context of the task flow is canceled,
+// otherwise the objects created before the cancellation would be left on the storage.
 func (p *TaskFlow) Revert() {
-	log.AddContext(p.ctx).Infof("Start to revert taskflow %s", p.name)
+	ctx := detachedContext{Context: p.ctx}
+	log.AddContext(ctx).Infof("Start to revert taskflow %s", p.name)
 
 	for i := len(p.tasks) - 1; i >= 0; i-- {
 		task := p.tasks[i]
 
 		if task.finish && task.revert != nil {
-			err := task.revert(p.ctx, p.result)
+			err := task.revert(ctx, p.result)
 			if err != nil {
-				log.AddContext(p.ctx).Warningf("Revert task %s of taskflow %s error: %v", task.name, p.name, err)
+				log.AddContext(ctx).Warningf("Revert task %s of taskflow %s error: %v", task.name, p.name, err)
 			}
 		}
 	}
 
-	log.AddContext(p.ctx).Infof("Taskflow %s is reverted", p.name)
+	log.AddContext(ctx).Infof("Taskflow %s is reverted", p.name)
+}
+
+// detachedContext keeps the values of the parent context but is never canceled
+type detachedContext struct {
+	context.Context
+}
+
+// Deadline returns no deadline
+func (detachedContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+// Done returns nil, so the context is never canceled
+func (detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+// Err always returns nil
+func (detachedContext) Err() error {
+	return nil
 }
 
 // AddTaskWithOutRevert be used when the task does not need revert function
@@ -86,3 +86,42 @@ func TestRunTaskFail(t *testing.T) {
 		t.Error("got an unexpected error while run TestRunTaskFail()")
 	}
 }
+
+func TestRevertAfterCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	lunExists := false
+	createLun := func(ctx context.Context, _ map[string]interface{},
+		_ map[string]interface{}) (map[string]interface{}, error) {
+		lunExists = true
+		cancel()
+		return map[string]interface{}{"lunID": "1"}, nil
+	}
+	revertLun := func(ctx context.Context, result map[string]interface{}) error {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		lunExists = false
+		return nil
+	}
+	createQoS := func(ctx context.Context, _ map[string]interface{},
+		_ map[string]interface{}) (map[string]interface{}, error) {
+		t.Error("task should not run after the context is canceled")
+		return nil, nil
+	}
+
+	flow := NewTaskFlow(ctx, "test_revert_after_cancel")
+	flow.AddTask("Create-Local-LUN", createLun, revertLun)
+	flow.AddTask("Create-Local-QoS", createQoS, nil)
+
+	_, err := flow.Run(map[string]interface{}{})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("TestRevertAfterCancel() want error %v, got %v", context.Canceled, err)
+	}
+
+	flow.Revert()
+	if lunExists {
+		t.Error("TestRevertAfterCancel() the LUN should be removed after revert")
+	}
+}
@@ -0,0 +1,69 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+// Package tracing provides the export of the spans of the requests to the storage
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
+)
+
+// Init sets the global tracer provider which exports the spans to the OTLP/HTTP collector at the endpoint, such as
+// "http://otel-collector:4318". The OTEL_EXPORTER_OTLP_* environment variables are used if the endpoint is empty.
+// The returned function flushes the buffered spans and stops the export.
+func Init(ctx context.Context, endpoint, serviceName string) (func(context.Context) error, error) {
+	var opts []otlptracehttp.Option
+	if endpoint != "" {
+		collector, err := url.Parse(endpoint)
+		if err != nil || collector.Host == "" {
+			return nil, fmt.Errorf("invalid tracing endpoint %s, it must be a URL such as "+
+				"http://otel-collector:4318", endpoint)
+		}
+
+		opts = append(opts, otlptracehttp.WithEndpoint(collector.Host))
+		if collector.Scheme == "http" {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+	}
+
+	exporter, err := otlptracehttp.New(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("create OTLP trace exporter error: %v", err)
+	}
+
+	provider := NewTracerProvider(exporter, serviceName)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	return provider.Shutdown, nil
+}
+
+// NewTracerProvider returns the tracer provider which exports the spans of the service in batches
+func NewTracerProvider(exporter sdktrace.SpanExporter, serviceName string) *sdktrace.TracerProvider {
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL,
+			semconv.ServiceNameKey.String(serviceName))),
+	)
+}
@@ -0,0 +1,58 @@
+/*
+ *  Copyright (c) Huawei Technologies Co., Ltd. 2023-2023. All rights reserved.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *       http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
+)
+
+func TestNewTracerProvider(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := NewTracerProvider(exporter, "huawei-csi-controller")
+
+	_, span := provider.Tracer("test").Start(context.Background(), "GET /lun")
+	span.End()
+	// the exporter drops the spans on shutdown, so the batched spans are flushed instead
+	if err := provider.ForceFlush(context.Background()); err != nil {
+		t.Fatalf("ForceFlush() error = %v", err)
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 || spans[0].Name != "GET /lun" {
+		t.Fatalf("exported spans = %v, want one span GET /lun", spans)
+	}
+
+	var serviceName string
+	for _, attr := range spans[0].Resource.Attributes() {
+		if attr.Key == semconv.ServiceNameKey {
+			serviceName = attr.Value.AsString()
+		}
+	}
+	if serviceName != "huawei-csi-controller" {
+		t.Errorf("service name = %s, want huawei-csi-controller", serviceName)
+	}
+}
+
+func TestInitInvalidEndpoint(t *testing.T) {
+	if _, err := Init(context.Background(), "otel-collector:4318", "huawei-csi-controller"); err == nil {
+		t.Errorf("Init() with an endpoint without scheme expects an error")
+	}
+}
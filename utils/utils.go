@@ -74,6 +74,7 @@ const (
 	dmMultiPath     string = "DM-multipath"
 	hwUltraPath     string = "HW-UltraPath"
 	hwUltraPathNVMe string = "HW-UltraPath-NVMe"
+	nativeNVMe      string = "native"
 
 	oceantorSan      string = "oceanstor-san"
 	oceantorNas      string = "oceanstor-nas"
@@ -307,6 +308,25 @@ func SplitSnapshotId(snapshotId string) (string, string, string) {
 	return helper.GetBackendName(splits[0]), "", ""
 }
 
+// metroSnapshotParentSeparator separates the parent IDs of the snapshots taken on both sites of a hyperMetro pair
+const metroSnapshotParentSeparator = "+"
+
+// JoinMetroSnapshotParentId joins the parent IDs of the local and the remote snapshots of a hyperMetro pair,
+// so that both snapshots are identified by one snapshot ID
+func JoinMetroSnapshotParentId(localParentId, remoteParentId string) string {
+	return localParentId + metroSnapshotParentSeparator + remoteParentId
+}
+
+// SplitMetroSnapshotParentId splits the parent ID in the snapshot ID into the local and the remote parent IDs,
+// the remote parent ID is empty if the snapshot isn't taken on both sites of a hyperMetro pair
+func SplitMetroSnapshotParentId(parentId string) (string, string) {
+	splits := strings.SplitN(parentId, metroSnapshotParentSeparator, 2)
+	if len(splits) == 2 {
+		return splits[0], splits[1]
+	}
+	return parentId, ""
+}
+
 func MergeMap(args ...map[string]interface{}) map[string]interface{} {
 	newMap := make(map[string]interface{})
 	for _, arg := range args {
@@ -671,7 +691,7 @@ func GetRequiredMultipath(ctx context.Context,
 	multipathConfig map[string]interface{},
 	backendConfigs []map[string]interface{}) ([]string, error) {
 	serviceMap := map[string][]string{dmMultiPath: {dmMultipathService}, hwUltraPath: {nxupService},
-		hwUltraPathNVMe: {upudevService, upPlusService}}
+		hwUltraPathNVMe: {upudevService, upPlusService}, nativeNVMe: {}}
 	var requiredServices []string
 
 	if !multipathConfig["volumeUseMultiPath"].(bool) {
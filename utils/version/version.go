@@ -33,6 +33,12 @@ const (
 
 var mutex sync.Mutex
 
+// The build metadata of the binary, which is set by -ldflags "-X" at build time
+var (
+	GitCommit = "unknown"
+	BuildDate = "unknown"
+)
+
 // InitVersion used for init the version of the service
 func InitVersion(versionFile, version string) error {
 	mutex.Lock()
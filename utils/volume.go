@@ -29,6 +29,12 @@ type Volume interface {
 	GetDTreeParentName() string
 	GetFilesystemMode() string
 	SetFilesystemMode(string)
+	IsEncrypted() bool
+	SetEncrypted(bool)
+	GetOwningControllers() (string, string)
+	SetOwningControllers(string, string)
+	GetSharePath() string
+	SetSharePath(string)
 }
 type volume struct {
 	name            string
@@ -36,6 +42,10 @@ type volume struct {
 	size            int64
 	dTreeParentName string
 	filesystemMode  string
+	encrypted       bool
+	localOwner      string
+	remoteOwner     string
+	sharePath       string
 }
 
 // NewVolume creates volume object for the name
@@ -93,3 +103,34 @@ func (vol *volume) GetFilesystemMode() string {
 func (vol *volume) SetFilesystemMode(filesystemMode string) {
 	vol.filesystemMode = filesystemMode
 }
+
+// IsEncrypted returns whether the volume is encrypted by the storage
+func (vol *volume) IsEncrypted() bool {
+	return vol.encrypted
+}
+
+// SetEncrypted sets whether the volume is encrypted by the storage
+func (vol *volume) SetEncrypted(encrypted bool) {
+	vol.encrypted = encrypted
+}
+
+// GetOwningControllers returns the controllers owning the lun on the local and the remote storage
+func (vol *volume) GetOwningControllers() (string, string) {
+	return vol.localOwner, vol.remoteOwner
+}
+
+// SetOwningControllers sets the controllers owning the lun on the local and the remote storage
+func (vol *volume) SetOwningControllers(localOwner, remoteOwner string) {
+	vol.localOwner = localOwner
+	vol.remoteOwner = remoteOwner
+}
+
+// GetSharePath returns the path of the nfs share of the volume
+func (vol *volume) GetSharePath() string {
+	return vol.sharePath
+}
+
+// SetSharePath sets the path of the nfs share of the volume
+func (vol *volume) SetSharePath(sharePath string) {
+	vol.sharePath = sharePath
+}
@@ -95,6 +95,44 @@ func RemoveWwnFile(ctx context.Context, volumeId string) error {
 	return nil
 }
 
+// WritePortalFile write the last successful iSCSI portal of the volume, so that the portal is preferred
+// when the volume is staged again.
+func WritePortalFile(ctx context.Context, portal, volumeId string) error {
+	if err := createWwnDir(ctx); err != nil {
+		return err
+	}
+
+	portalFileName := buildPortalFilePath(buildWwnFileName(volumeId))
+	err := ioutil.WriteFile(portalFileName, []byte(portal), defaultWwnFilePermission)
+	if err != nil {
+		log.AddContext(ctx).Errorf("write portal file error, fileName: %s, error: %v", portalFileName, err)
+		return err
+	}
+	return nil
+}
+
+// ReadPortalFile read the last successful iSCSI portal of the volume, the empty portal means it is unknown.
+func ReadPortalFile(ctx context.Context, volumeId string) string {
+	portalBytes, err := ioutil.ReadFile(buildPortalFilePath(buildWwnFileName(volumeId)))
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.AddContext(ctx).Warningf("read portal file failed, volumeId: %s, error: %v", volumeId, err)
+		}
+		return ""
+	}
+	return strings.TrimSpace(string(portalBytes))
+}
+
+// RemovePortalFile remove the last successful iSCSI portal file of the volume.
+func RemovePortalFile(ctx context.Context, volumeId string) error {
+	err := os.Remove(buildPortalFilePath(buildWwnFileName(volumeId)))
+	if err != nil && !os.IsNotExist(err) {
+		log.AddContext(ctx).Errorf("remove portal file error, volumeId: %s, error: %v", volumeId, err)
+		return err
+	}
+	return nil
+}
+
 func createWwnDir(ctx context.Context) error {
 	dir, err := os.Lstat(defaultWwnFileDir)
 	if os.IsNotExist(err) {
@@ -115,6 +153,10 @@ func buildWwnFilePath(volumeId string) string {
 	return fmt.Sprintf("%s/%s.wwn", defaultWwnFileDir, volumeId)
 }
 
+func buildPortalFilePath(volumeId string) string {
+	return fmt.Sprintf("%s/%s.portal", defaultWwnFileDir, volumeId)
+}
+
 func buildWwnFileName(volumeId string) string {
 	if len(volumeId) > defaultWwnFileLength {
 		volumeId = volumeId[len(volumeId)-64:]
@@ -175,3 +175,27 @@ func cleanMockFile() {
 		return
 	}
 }
+
+func TestPortalFile(t *testing.T) {
+	defer cleanMockFile()
+
+	if portal := ReadPortalFile(context.Background(), testVolumeId); portal != "" {
+		t.Errorf("TestPortalFile() want an empty portal before written, got: %s", portal)
+	}
+
+	if err := WritePortalFile(context.Background(), "192.168.1.1:3260", testVolumeId); err != nil {
+		t.Errorf("TestPortalFile() write portal file error: %v", err)
+	}
+
+	if portal := ReadPortalFile(context.Background(), testVolumeId); portal != "192.168.1.1:3260" {
+		t.Errorf("TestPortalFile() want: 192.168.1.1:3260, got: %s", portal)
+	}
+
+	if err := RemovePortalFile(context.Background(), testVolumeId); err != nil {
+		t.Errorf("TestPortalFile() remove portal file error: %v", err)
+	}
+
+	if err := RemovePortalFile(context.Background(), testVolumeId); err != nil {
+		t.Errorf("TestPortalFile() remove a removed portal file error: %v", err)
+	}
+}